@@ -80,6 +80,10 @@ type AddonSpec struct {
 
 	// PruneSpec specifies how old objects should be removed (pruned).
 	Prune *PruneSpec `json:"prune,omitempty"`
+
+	// DependsOn lists the names of addons that must be installed before this addon.
+	// Addons in the same channel are applied in dependency order; an addon from another channel must already be installed.
+	DependsOn []string `json:"dependsOn,omitempty"`
 }
 
 // PruneSpec specifies how old objects should be removed (pruned).
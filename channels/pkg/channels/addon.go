@@ -45,6 +45,14 @@ type Applier interface {
 	Apply(ctx context.Context, data []byte) error
 }
 
+// HealthChecker checks the health of the objects in a manifest after they have been applied.
+type HealthChecker interface {
+	// WaitForHealthy waits for the objects to become healthy.
+	WaitForHealthy(ctx context.Context, data []byte) error
+	// CheckHealthy checks once that the objects are healthy, without waiting.
+	CheckHealthy(ctx context.Context, data []byte) error
+}
+
 // Addon is a wrapper around a single version of an addon
 type Addon struct {
 	Name            string
@@ -129,6 +137,17 @@ func (a *Addon) GetRequiredUpdates(ctx context.Context, k8sClient kubernetes.Int
 		newVersion = nil
 	}
 
+	if existingVersion != nil && newVersion != nil {
+		failedVersion, err := channel.GetFailedVersion(ctx, k8sClient)
+		if err != nil {
+			return nil, err
+		}
+		if failedVersion != nil && !newVersion.replaces(a.Name, failedVersion) {
+			klog.Warningf("addon %q version %v was rolled back because it did not become healthy; not retrying", a.Name, newVersion)
+			newVersion = nil
+		}
+	}
+
 	if pkiInstalled && newVersion == nil {
 		return nil, nil
 	}
@@ -157,7 +176,10 @@ func (a *Addon) GetManifestFullUrl() (*url.URL, error) {
 	return manifestURL, nil
 }
 
-func (a *Addon) EnsureUpdated(ctx context.Context, vfsContext *vfs.VFSContext, k8sClient kubernetes.Interface, cmClient certmanager.Interface, pruner *Pruner, applier Applier, existingVersion *ChannelVersion) (*AddonUpdate, error) {
+// EnsureUpdated applies the addon if it needs to be updated.
+// If healthChecker is not nil, an update of an already-installed addon must become healthy before we record the
+// new version; otherwise the previously applied manifest is re-applied.
+func (a *Addon) EnsureUpdated(ctx context.Context, vfsContext *vfs.VFSContext, k8sClient kubernetes.Interface, cmClient certmanager.Interface, pruner *Pruner, applier Applier, healthChecker HealthChecker, existingVersion *ChannelVersion) (*AddonUpdate, error) {
 	required, err := a.GetRequiredUpdates(ctx, k8sClient, cmClient, existingVersion)
 	if err != nil {
 		return nil, err
@@ -169,7 +191,7 @@ func (a *Addon) EnsureUpdated(ctx context.Context, vfsContext *vfs.VFSContext, k
 	var merr error
 
 	if required.NewVersion != nil {
-		err := a.updateAddon(ctx, k8sClient, vfsContext, pruner, applier, required)
+		err := a.updateAddon(ctx, k8sClient, vfsContext, pruner, applier, healthChecker, required)
 		if err != nil {
 			merr = multierr.Append(merr, err)
 		}
@@ -183,7 +205,37 @@ func (a *Addon) EnsureUpdated(ctx context.Context, vfsContext *vfs.VFSContext, k
 	return required, merr
 }
 
-func (a *Addon) updateAddon(ctx context.Context, k8sClient kubernetes.Interface, vfsContext *vfs.VFSContext, pruner *Pruner, applier Applier, required *AddonUpdate) error {
+// RecordInstalledManifest records the manifest of an installed, up-to-date addon if none is recorded yet.
+// Addons installed before health gating was introduced have no recorded manifest, so without this their
+// first gated update would have nothing to roll back to.
+func (a *Addon) RecordInstalledManifest(ctx context.Context, vfsContext *vfs.VFSContext, k8sClient kubernetes.Interface, existingVersion *ChannelVersion) error {
+	if existingVersion == nil || existingVersion.ManifestHash == "" || existingVersion.ManifestHash != a.Spec.ManifestHash {
+		return nil
+	}
+
+	channel := a.buildChannel()
+	existingManifest, err := channel.GetAppliedManifest(ctx, k8sClient)
+	if err != nil {
+		return err
+	}
+	if existingManifest != nil {
+		return nil
+	}
+
+	manifestURL, err := a.GetManifestFullUrl()
+	if err != nil {
+		return err
+	}
+	data, err := vfsContext.ReadFile(manifestURL.String())
+	if err != nil {
+		return fmt.Errorf("error reading manifest: %w", err)
+	}
+
+	klog.Infof("recording installed manifest for addon %q", a.Name)
+	return channel.SetAppliedManifest(ctx, k8sClient, data)
+}
+
+func (a *Addon) updateAddon(ctx context.Context, k8sClient kubernetes.Interface, vfsContext *vfs.VFSContext, pruner *Pruner, applier Applier, healthChecker HealthChecker, required *AddonUpdate) error {
 	manifestURL, err := a.GetManifestFullUrl()
 	if err != nil {
 		return err
@@ -197,6 +249,55 @@ func (a *Addon) updateAddon(ctx context.Context, k8sClient kubernetes.Interface,
 		return fmt.Errorf("error reading manifest: %w", err)
 	}
 
+	channel := a.buildChannel()
+
+	// We only gate updates of installed addons on health; a new install has nothing to roll back to,
+	// and during cluster bootstrap addons often can't become healthy until other addons are installed.
+	checkHealth := healthChecker != nil && required.ExistingVersion != nil
+
+	var previousManifest []byte
+	if checkHealth {
+		previousManifest, err = channel.GetAppliedManifest(ctx, k8sClient)
+		if err != nil {
+			return fmt.Errorf("error reading previously applied manifest: %w", err)
+		}
+	}
+
+	if err := a.applyManifest(ctx, pruner, applier, data); err != nil {
+		channel.recordEvent(ctx, k8sClient, corev1.EventTypeWarning, "AddonUpdateFailed", fmt.Sprintf("Failed to apply addon %s: %v", a.Name, err))
+		return fmt.Errorf("error updating addon from %q: %w", manifestURL, err)
+	}
+
+	if checkHealth {
+		if err := healthChecker.WaitForHealthy(ctx, data); err != nil {
+			return a.rollback(ctx, k8sClient, pruner, applier, required, previousManifest, err)
+		}
+	} else if healthChecker != nil {
+		// We don't wait for a new install, but we only record it once it is healthy, so that it is applied again until it is
+		if err := healthChecker.CheckHealthy(ctx, data); err != nil {
+			return fmt.Errorf("addon %q is not healthy yet: %w", a.Name, err)
+		}
+	}
+
+	if err := a.AddNeedsUpdateLabel(ctx, k8sClient, required); err != nil {
+		return fmt.Errorf("error adding needs-update label: %v", err)
+	}
+
+	if err := channel.SetAppliedManifest(ctx, k8sClient, data); err != nil {
+		return fmt.Errorf("error recording applied manifest: %w", err)
+	}
+
+	err = channel.SetInstalledVersion(ctx, k8sClient, a.ChannelVersion())
+	if err != nil {
+		return fmt.Errorf("error applying annotation to record addon installation: %v", err)
+	}
+
+	channel.recordEvent(ctx, k8sClient, corev1.EventTypeNormal, "AddonUpdated", fmt.Sprintf("Applied addon %s version %v", a.Name, required.NewVersion))
+	return nil
+}
+
+// applyManifest applies and prunes the objects in the manifest.
+func (a *Addon) applyManifest(ctx context.Context, pruner *Pruner, applier Applier, data []byte) error {
 	var merr error
 	var applyError, pruneError error
 
@@ -218,20 +319,31 @@ func (a *Addon) updateAddon(ctx context.Context, k8sClient kubernetes.Interface,
 		}
 	}
 
-	if merr != nil {
-		return fmt.Errorf("error updating addon from %q: %w", manifestURL, merr)
+	return merr
+}
+
+// rollback re-applies the previously applied manifest after an update did not become healthy.
+// The version annotation is left unchanged, and the failed version is recorded so we do not retry it in a loop.
+func (a *Addon) rollback(ctx context.Context, k8sClient kubernetes.Interface, pruner *Pruner, applier Applier, required *AddonUpdate, previousManifest []byte, healthError error) error {
+	channel := a.buildChannel()
+
+	if err := channel.SetFailedVersion(ctx, k8sClient, required.NewVersion); err != nil {
+		klog.Warningf("error recording failed version for addon %q: %v", a.Name, err)
 	}
 
-	if err := a.AddNeedsUpdateLabel(ctx, k8sClient, required); err != nil {
-		return fmt.Errorf("error adding needs-update label: %v", err)
+	if previousManifest == nil {
+		channel.recordEvent(ctx, k8sClient, corev1.EventTypeWarning, "AddonUnhealthy", fmt.Sprintf("Addon %s version %v did not become healthy, and no previous manifest is recorded to roll back to: %v", a.Name, required.NewVersion, healthError))
+		return fmt.Errorf("addon did not become healthy: %w", healthError)
 	}
 
-	channel := a.buildChannel()
-	err = channel.SetInstalledVersion(ctx, k8sClient, a.ChannelVersion())
-	if err != nil {
-		return fmt.Errorf("error applying annotation to record addon installation: %v", err)
+	klog.Warningf("addon %q did not become healthy, rolling back: %v", a.Name, healthError)
+	if err := a.applyManifest(ctx, pruner, applier, previousManifest); err != nil {
+		channel.recordEvent(ctx, k8sClient, corev1.EventTypeWarning, "AddonRollbackFailed", fmt.Sprintf("Addon %s version %v did not become healthy, and rolling back failed: %v", a.Name, required.NewVersion, err))
+		return fmt.Errorf("addon did not become healthy (%v), and rolling back failed: %w", healthError, err)
 	}
-	return nil
+
+	channel.recordEvent(ctx, k8sClient, corev1.EventTypeWarning, "AddonRolledBack", fmt.Sprintf("Addon %s version %v did not become healthy, rolled back to version %v: %v", a.Name, required.NewVersion, required.ExistingVersion, healthError))
+	return fmt.Errorf("addon did not become healthy, rolled back to previous manifest: %w", healthError)
 }
 
 func (a *Addon) AddNeedsUpdateLabel(ctx context.Context, k8sClient kubernetes.Interface, required *AddonUpdate) error {
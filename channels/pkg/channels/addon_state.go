@@ -0,0 +1,190 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package channels
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// addonStateSecretType is the type of the Secret in which we record the state of an addon.
+	// We use a Secret rather than a ConfigMap because manifests may contain sensitive values.
+	addonStateSecretType corev1.SecretType = "kops.k8s.io/addon-state"
+
+	// addonStateManifestKey holds the gzipped manifest that was last applied and found healthy.
+	addonStateManifestKey = "manifest.yaml.gz"
+	// addonStateFailedVersionKey holds the version that last failed health checks and was rolled back.
+	addonStateFailedVersionKey = "failedVersion"
+)
+
+// StateSecretName is the name of the Secret holding the applied manifest and rollback state for the addon.
+func (c *Channel) StateSecretName() string {
+	return "kops-addon-" + c.Name
+}
+
+func (c *Channel) getStateSecret(ctx context.Context, k8sClient kubernetes.Interface) (*corev1.Secret, error) {
+	secret, err := k8sClient.CoreV1().Secrets(c.Namespace).Get(ctx, c.StateSecretName(), metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error querying secret %s/%s: %w", c.Namespace, c.StateSecretName(), err)
+	}
+	return secret, nil
+}
+
+// updateStateSecret applies mutator to the state secret, creating it if it does not exist.
+func (c *Channel) updateStateSecret(ctx context.Context, k8sClient kubernetes.Interface, mutator func(data map[string][]byte)) error {
+	secret, err := c.getStateSecret(ctx, k8sClient)
+	if err != nil {
+		return err
+	}
+
+	secrets := k8sClient.CoreV1().Secrets(c.Namespace)
+	if secret == nil {
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      c.StateSecretName(),
+				Namespace: c.Namespace,
+			},
+			Type: addonStateSecretType,
+			Data: make(map[string][]byte),
+		}
+		mutator(secret.Data)
+		if _, err := secrets.Create(ctx, secret, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("error creating secret %s/%s: %w", c.Namespace, c.StateSecretName(), err)
+		}
+		return nil
+	}
+
+	if secret.Data == nil {
+		secret.Data = make(map[string][]byte)
+	}
+	mutator(secret.Data)
+	if _, err := secrets.Update(ctx, secret, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("error updating secret %s/%s: %w", c.Namespace, c.StateSecretName(), err)
+	}
+	return nil
+}
+
+// GetAppliedManifest returns the manifest that was last applied successfully, or nil if none is recorded.
+func (c *Channel) GetAppliedManifest(ctx context.Context, k8sClient kubernetes.Interface) ([]byte, error) {
+	secret, err := c.getStateSecret(ctx, k8sClient)
+	if err != nil {
+		return nil, err
+	}
+	if secret == nil || len(secret.Data[addonStateManifestKey]) == 0 {
+		return nil, nil
+	}
+
+	r, err := gzip.NewReader(bytes.NewReader(secret.Data[addonStateManifestKey]))
+	if err != nil {
+		return nil, fmt.Errorf("error decompressing applied manifest: %w", err)
+	}
+	defer r.Close()
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("error decompressing applied manifest: %w", err)
+	}
+	return data, nil
+}
+
+// SetAppliedManifest records the manifest that was applied successfully, and clears any failed version.
+func (c *Channel) SetAppliedManifest(ctx context.Context, k8sClient kubernetes.Interface, manifest []byte) error {
+	var b bytes.Buffer
+	w := gzip.NewWriter(&b)
+	if _, err := w.Write(manifest); err != nil {
+		return fmt.Errorf("error compressing manifest: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("error compressing manifest: %w", err)
+	}
+
+	return c.updateStateSecret(ctx, k8sClient, func(data map[string][]byte) {
+		data[addonStateManifestKey] = b.Bytes()
+		delete(data, addonStateFailedVersionKey)
+	})
+}
+
+// GetFailedVersion returns the version that was last rolled back because it did not become healthy, or nil.
+func (c *Channel) GetFailedVersion(ctx context.Context, k8sClient kubernetes.Interface) (*ChannelVersion, error) {
+	secret, err := c.getStateSecret(ctx, k8sClient)
+	if err != nil {
+		return nil, err
+	}
+	if secret == nil || len(secret.Data[addonStateFailedVersionKey]) == 0 {
+		return nil, nil
+	}
+
+	version, err := ParseChannelVersion(string(secret.Data[addonStateFailedVersionKey]))
+	if err != nil {
+		klog.Warningf("ignoring unparseable failed version for addon %q: %v", c.Name, err)
+		return nil, nil
+	}
+	return version, nil
+}
+
+// SetFailedVersion records that version did not become healthy, so that we do not repeatedly retry it.
+func (c *Channel) SetFailedVersion(ctx context.Context, k8sClient kubernetes.Interface, version *ChannelVersion) error {
+	value, err := version.Encode()
+	if err != nil {
+		return err
+	}
+
+	return c.updateStateSecret(ctx, k8sClient, func(data map[string][]byte) {
+		data[addonStateFailedVersionKey] = []byte(value)
+	})
+}
+
+// recordEvent records a Kubernetes event against the namespace of the channel, so the outcome of an addon update
+// can be observed with kubectl. Failures are logged but otherwise ignored.
+func (c *Channel) recordEvent(ctx context.Context, k8sClient kubernetes.Interface, eventType, reason, message string) {
+	now := metav1.NewTime(time.Now())
+	event := &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: c.Name + ".",
+			Namespace:    c.Namespace,
+		},
+		InvolvedObject: corev1.ObjectReference{
+			APIVersion: "v1",
+			Kind:       "Namespace",
+			Name:       c.Namespace,
+		},
+		Reason:         reason,
+		Message:        message,
+		Type:           eventType,
+		Source:         corev1.EventSource{Component: "channels"},
+		FirstTimestamp: now,
+		LastTimestamp:  now,
+		Count:          1,
+	}
+	if _, err := k8sClient.CoreV1().Events(c.Namespace).Create(ctx, event, metav1.CreateOptions{}); err != nil {
+		klog.Warningf("error recording event %q for addon %q: %v", reason, c.Name, err)
+	}
+}
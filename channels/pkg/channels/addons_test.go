@@ -17,7 +17,11 @@ limitations under the License.
 package channels
 
 import (
+	"bytes"
 	"context"
+	"fmt"
+	"net/url"
+	"strings"
 	"testing"
 
 	"github.com/blang/semver/v4"
@@ -28,6 +32,7 @@ import (
 	fakekubernetes "k8s.io/client-go/kubernetes/fake"
 	"k8s.io/kops/channels/pkg/api"
 	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/util/pkg/vfs"
 )
 
 func Test_Filtering(t *testing.T) {
@@ -363,3 +368,341 @@ func Test_InstallPKI(t *testing.T) {
 		t.Errorf("unexpected error: %v", err)
 	}
 }
+
+type fakeApplier struct {
+	applied [][]byte
+}
+
+func (f *fakeApplier) Apply(ctx context.Context, data []byte) error {
+	f.applied = append(f.applied, data)
+	return nil
+}
+
+type fakeHealthChecker struct {
+	err error
+}
+
+func (f *fakeHealthChecker) WaitForHealthy(ctx context.Context, data []byte) error {
+	return f.err
+}
+
+func (f *fakeHealthChecker) CheckHealthy(ctx context.Context, data []byte) error {
+	return f.err
+}
+
+func Test_EnsureUpdatedHealthGating(t *testing.T) {
+	previousManifest := []byte("previous")
+	newManifest := []byte("new")
+
+	grid := []struct {
+		name               string
+		healthError        error
+		expectedAnnotation string
+		expectedApplied    [][]byte
+	}{
+		{
+			name:               "healthy",
+			expectedAnnotation: "newHash",
+			expectedApplied:    [][]byte{newManifest},
+		},
+		{
+			name:               "unhealthy",
+			healthError:        fmt.Errorf("DaemonSet:kube-system/test not healthy"),
+			expectedAnnotation: "originalHash",
+			expectedApplied:    [][]byte{newManifest, previousManifest},
+		},
+	}
+
+	for _, g := range grid {
+		t.Run(g.name, func(t *testing.T) {
+			ctx := context.Background()
+
+			vfsContext := vfs.NewTestingVFSContext()
+			manifestPath, err := vfsContext.BuildVfsPath("memfs://tests/addons/test.yaml")
+			if err != nil {
+				t.Fatalf("error building vfs path: %v", err)
+			}
+			if err := manifestPath.WriteFile(ctx, bytes.NewReader(newManifest), nil); err != nil {
+				t.Fatalf("error writing manifest: %v", err)
+			}
+			channelLocation, err := url.Parse("memfs://tests/addons/channel.yaml")
+			if err != nil {
+				t.Fatalf("error parsing url: %v", err)
+			}
+
+			kubeSystem := &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "kube-system",
+					Annotations: map[string]string{
+						"addons.k8s.io/test": "{\"manifestHash\":\"originalHash\",\"systemGeneration\":1}",
+					},
+				},
+			}
+			fakek8s := fakekubernetes.NewSimpleClientset(kubeSystem)
+			fakecm := fakecertmanager.NewSimpleClientset()
+
+			addon := &Addon{
+				Name:            "test",
+				ChannelLocation: *channelLocation,
+				Spec: &api.AddonSpec{
+					Name:         fi.PtrTo("test"),
+					Manifest:     fi.PtrTo("test.yaml"),
+					ManifestHash: "newHash",
+				},
+			}
+			channel := addon.buildChannel()
+			if err := channel.SetAppliedManifest(ctx, fakek8s, previousManifest); err != nil {
+				t.Fatalf("error recording previous manifest: %v", err)
+			}
+
+			existingVersion := FindChannelVersions(kubeSystem)["test"]
+			applier := &fakeApplier{}
+			healthChecker := &fakeHealthChecker{err: g.healthError}
+			_, err = addon.EnsureUpdated(ctx, vfsContext, fakek8s, fakecm, &Pruner{}, applier, healthChecker, existingVersion)
+			if g.healthError == nil && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if g.healthError != nil && err == nil {
+				t.Errorf("expected error from unhealthy addon")
+			}
+
+			if len(applier.applied) != len(g.expectedApplied) {
+				t.Fatalf("expected %d applies, got %d", len(g.expectedApplied), len(applier.applied))
+			}
+			for i := range g.expectedApplied {
+				if !bytes.Equal(applier.applied[i], g.expectedApplied[i]) {
+					t.Errorf("expected apply %d to be %q, got %q", i, g.expectedApplied[i], applier.applied[i])
+				}
+			}
+
+			installedVersion, err := channel.GetInstalledVersion(ctx, fakek8s)
+			if err != nil {
+				t.Fatalf("error getting installed version: %v", err)
+			}
+			if installedVersion.ManifestHash != g.expectedAnnotation {
+				t.Errorf("expected installed version %q, got %q", g.expectedAnnotation, installedVersion.ManifestHash)
+			}
+
+			events, err := fakek8s.CoreV1().Events("kube-system").List(ctx, metav1.ListOptions{})
+			if err != nil {
+				t.Fatalf("error listing events: %v", err)
+			}
+			if len(events.Items) != 1 {
+				t.Errorf("expected 1 event, got %d", len(events.Items))
+			}
+
+			// A rolled back version should not be retried
+			required, err := addon.GetRequiredUpdates(ctx, fakek8s, fakecm, existingVersion)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if g.healthError != nil && required != nil {
+				t.Errorf("expected rolled back version not to be retried")
+			}
+		})
+	}
+}
+
+func Test_SortedAddons(t *testing.T) {
+	grid := []struct {
+		name          string
+		dependencies  map[string][]string
+		expectedOrder []string
+		expectedError bool
+	}{
+		{
+			name: "no dependencies",
+			dependencies: map[string][]string{
+				"c": nil,
+				"a": nil,
+				"b": nil,
+			},
+			expectedOrder: []string{"a", "b", "c"},
+		},
+		{
+			name: "chain",
+			dependencies: map[string][]string{
+				"a": {"b"},
+				"b": {"c"},
+				"c": nil,
+			},
+			expectedOrder: []string{"c", "b", "a"},
+		},
+		{
+			name: "dependency outside menu",
+			dependencies: map[string][]string{
+				"webhook": {"certmanager.io"},
+				"a":       nil,
+			},
+			expectedOrder: []string{"a", "webhook"},
+		},
+		{
+			name: "cycle",
+			dependencies: map[string][]string{
+				"a": {"b"},
+				"b": {"a"},
+				"c": {"a"},
+				"d": nil,
+			},
+			expectedOrder: []string{"d"},
+			expectedError: true,
+		},
+		{
+			name: "depends on itself",
+			dependencies: map[string][]string{
+				"a": {"a"},
+				"b": nil,
+			},
+			expectedOrder: []string{"b"},
+			expectedError: true,
+		},
+	}
+
+	for _, g := range grid {
+		t.Run(g.name, func(t *testing.T) {
+			menu := NewAddonMenu()
+			for name, dependsOn := range g.dependencies {
+				menu.Addons[name] = &Addon{
+					Name: name,
+					Spec: &api.AddonSpec{
+						Name:      fi.PtrTo(name),
+						DependsOn: dependsOn,
+					},
+				}
+			}
+
+			sorted, err := menu.SortedAddons()
+			if g.expectedError && err == nil {
+				t.Errorf("expected error from dependency cycle")
+			}
+			if !g.expectedError && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			var order []string
+			for _, addon := range sorted {
+				order = append(order, addon.Name)
+			}
+			if strings.Join(order, ",") != strings.Join(g.expectedOrder, ",") {
+				t.Errorf("expected order %v, got %v", g.expectedOrder, order)
+			}
+		})
+	}
+}
+
+func Test_CheckDependencies(t *testing.T) {
+	menu := NewAddonMenu()
+	menu.Addons["certmanager.io"] = &Addon{Name: "certmanager.io", Spec: &api.AddonSpec{}}
+
+	grid := []struct {
+		dependsOn     []string
+		installed     map[string]bool
+		failed        map[string]bool
+		expectedError bool
+	}{
+		{
+			dependsOn: []string{"certmanager.io"},
+		},
+		{
+			dependsOn:     []string{"certmanager.io"},
+			failed:        map[string]bool{"certmanager.io": true},
+			expectedError: true,
+		},
+		{
+			dependsOn: []string{"coredns.addons.k8s.io"},
+			installed: map[string]bool{"coredns.addons.k8s.io": true},
+		},
+		{
+			dependsOn:     []string{"coredns.addons.k8s.io"},
+			expectedError: true,
+		},
+	}
+
+	for _, g := range grid {
+		addon := &Addon{Name: "webhook", Spec: &api.AddonSpec{DependsOn: g.dependsOn}}
+		err := addon.CheckDependencies(menu, g.installed, g.failed)
+		if g.expectedError && err == nil {
+			t.Errorf("expected error for dependencies %v", g.dependsOn)
+		}
+		if !g.expectedError && err != nil {
+			t.Errorf("unexpected error for dependencies %v: %v", g.dependsOn, err)
+		}
+	}
+}
+
+func Test_EnsureUpdatedNewInstallHealth(t *testing.T) {
+	grid := []struct {
+		name               string
+		healthError        error
+		expectedAnnotation string
+	}{
+		{
+			name:               "healthy",
+			expectedAnnotation: "newHash",
+		},
+		{
+			name:        "unhealthy",
+			healthError: fmt.Errorf("DaemonSet:kube-system/test not healthy"),
+		},
+	}
+
+	for _, g := range grid {
+		t.Run(g.name, func(t *testing.T) {
+			ctx := context.Background()
+
+			vfsContext := vfs.NewTestingVFSContext()
+			manifestPath, err := vfsContext.BuildVfsPath("memfs://tests/addons/test.yaml")
+			if err != nil {
+				t.Fatalf("error building vfs path: %v", err)
+			}
+			if err := manifestPath.WriteFile(ctx, bytes.NewReader([]byte("new")), nil); err != nil {
+				t.Fatalf("error writing manifest: %v", err)
+			}
+			channelLocation, err := url.Parse("memfs://tests/addons/channel.yaml")
+			if err != nil {
+				t.Fatalf("error parsing url: %v", err)
+			}
+
+			kubeSystem := &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "kube-system",
+				},
+			}
+			fakek8s := fakekubernetes.NewSimpleClientset(kubeSystem)
+
+			addon := &Addon{
+				Name:            "test",
+				ChannelLocation: *channelLocation,
+				Spec: &api.AddonSpec{
+					Name:         fi.PtrTo("test"),
+					Manifest:     fi.PtrTo("test.yaml"),
+					ManifestHash: "newHash",
+				},
+			}
+
+			applier := &fakeApplier{}
+			_, err = addon.EnsureUpdated(ctx, vfsContext, fakek8s, fakecertmanager.NewSimpleClientset(), &Pruner{}, applier, &fakeHealthChecker{err: g.healthError}, nil)
+			if g.healthError == nil && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if g.healthError != nil && err == nil {
+				t.Errorf("expected error from unhealthy addon")
+			}
+			if len(applier.applied) != 1 {
+				t.Errorf("expected 1 apply, got %d", len(applier.applied))
+			}
+
+			// An unhealthy new install is not recorded, so that it is applied again
+			installedVersion, err := addon.buildChannel().GetInstalledVersion(ctx, fakek8s)
+			if err != nil {
+				t.Fatalf("error getting installed version: %v", err)
+			}
+			if g.expectedAnnotation == "" && installedVersion != nil {
+				t.Errorf("expected no installed version, got %v", installedVersion)
+			}
+			if g.expectedAnnotation != "" && (installedVersion == nil || installedVersion.ManifestHash != g.expectedAnnotation) {
+				t.Errorf("expected installed version %q, got %v", g.expectedAnnotation, installedVersion)
+			}
+		})
+	}
+}
@@ -30,6 +30,10 @@ import (
 type ClientApplier struct {
 	Client     dynamic.Interface
 	RESTMapper *restmapper.DeferredDiscoveryRESTMapper
+
+	// SkipHealthCheck skips checking the health of the applied objects,
+	// for callers that check it afterwards with a HealthChecker.
+	SkipHealthCheck bool
 }
 
 // Apply applies the manifest to the cluster.
@@ -80,8 +84,9 @@ func (p *ClientApplier) Apply(ctx context.Context, manifest []byte) error {
 		return fmt.Errorf("not all objects were applied")
 	}
 
-	// TODO: Check object health status
-	if !results.AllHealthy() {
+	// When a HealthChecker is used, health is checked after applying instead;
+	// the status of objects won't have been updated yet.
+	if !p.SkipHealthCheck && !results.AllHealthy() {
 		return fmt.Errorf("not all objects were healthy")
 	}
 
@@ -0,0 +1,111 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package channels
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/restmapper"
+	"k8s.io/klog/v2"
+	"k8s.io/kops/pkg/applylib/applyset"
+	"k8s.io/kops/pkg/kubemanifest"
+)
+
+// healthCheckInterval is how often we poll the objects while waiting for them to become healthy.
+const healthCheckInterval = 10 * time.Second
+
+type ClientHealthChecker struct {
+	Client     dynamic.Interface
+	RESTMapper *restmapper.DeferredDiscoveryRESTMapper
+
+	// Timeout is how long we wait for the objects to become healthy.
+	Timeout time.Duration
+}
+
+// WaitForHealthy polls the objects in the manifest until they are all healthy, or Timeout elapses.
+func (h *ClientHealthChecker) WaitForHealthy(ctx context.Context, manifest []byte) error {
+	s, err := h.buildApplySet(manifest)
+	if err != nil {
+		return err
+	}
+
+	var unhealthy []string
+	err = wait.PollUntilContextTimeout(ctx, healthCheckInterval, h.Timeout, true, func(ctx context.Context) (bool, error) {
+		results, err := s.HealthCheckOnce(ctx)
+		if err != nil {
+			klog.Warningf("error checking health of objects: %v", err)
+			return false, nil
+		}
+		unhealthy = results.Unhealthy()
+		return results.AllHealthy(), nil
+	})
+	if err != nil {
+		if wait.Interrupted(err) && len(unhealthy) != 0 {
+			return fmt.Errorf("objects not healthy after %v: %s", h.Timeout, strings.Join(unhealthy, ", "))
+		}
+		return fmt.Errorf("error waiting for objects to become healthy: %w", err)
+	}
+
+	return nil
+}
+
+// CheckHealthy checks once that the objects in the manifest are all healthy.
+func (h *ClientHealthChecker) CheckHealthy(ctx context.Context, manifest []byte) error {
+	s, err := h.buildApplySet(manifest)
+	if err != nil {
+		return err
+	}
+
+	results, err := s.HealthCheckOnce(ctx)
+	if err != nil {
+		return fmt.Errorf("error checking health of objects: %w", err)
+	}
+	if !results.AllHealthy() {
+		return fmt.Errorf("objects not healthy: %s", strings.Join(results.Unhealthy(), ", "))
+	}
+	return nil
+}
+
+// buildApplySet builds the applyset of the objects in the manifest, which we use to check their health.
+func (h *ClientHealthChecker) buildApplySet(manifest []byte) (*applyset.ApplySet, error) {
+	objects, err := kubemanifest.LoadObjectsFrom(manifest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse objects: %w", err)
+	}
+
+	s, err := applyset.New(applyset.Options{
+		RESTMapper: h.RESTMapper,
+		Client:     h.Client,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var applyableObjects []applyset.ApplyableObject
+	for _, object := range objects {
+		applyableObjects = append(applyableObjects, object)
+	}
+	if err := s.SetDesiredObjects(applyableObjects); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
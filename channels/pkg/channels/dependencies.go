@@ -0,0 +1,104 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package channels
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SortedAddons returns the addons in the menu ordered so that every addon comes after the addons it depends on.
+// Addons that are otherwise unordered are sorted by name, so the order is stable.
+// Addons that are part of a dependency cycle (including depending on themselves), or depend on one, are omitted from the result, and reported in the error;
+// the remaining addons are still returned so they can be applied.
+func (m *AddonMenu) SortedAddons() ([]*Addon, error) {
+	var names []string
+	for name := range m.Addons {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	// inDegree counts the dependencies of each addon that are in the menu and not yet ordered.
+	inDegree := make(map[string]int)
+	// dependents maps from an addon to the addons that depend on it.
+	dependents := make(map[string][]string)
+	for _, name := range names {
+		inDegree[name] = 0
+		for _, dependency := range m.Addons[name].Spec.DependsOn {
+			if _, found := m.Addons[dependency]; !found {
+				// Dependencies outside the menu are checked when applying
+				continue
+			}
+			inDegree[name]++
+			dependents[dependency] = append(dependents[dependency], name)
+		}
+	}
+
+	var queue []string
+	for _, name := range names {
+		if inDegree[name] == 0 {
+			queue = append(queue, name)
+		}
+	}
+
+	var sorted []*Addon
+	for len(queue) != 0 {
+		name := queue[0]
+		queue = queue[1:]
+		sorted = append(sorted, m.Addons[name])
+
+		next := dependents[name]
+		sort.Strings(next)
+		for _, dependent := range next {
+			inDegree[dependent]--
+			if inDegree[dependent] == 0 {
+				queue = append(queue, dependent)
+			}
+		}
+	}
+
+	if len(sorted) != len(names) {
+		var cyclic []string
+		for _, name := range names {
+			if inDegree[name] != 0 {
+				cyclic = append(cyclic, name)
+			}
+		}
+		return sorted, fmt.Errorf("dependency cycle detected, not applying addons: %s", strings.Join(cyclic, ", "))
+	}
+
+	return sorted, nil
+}
+
+// CheckDependencies returns an error if any of the addon's dependencies is not available.
+// A dependency in the menu is available unless it failed to update; any other dependency must already be installed,
+// where installed holds the names of the addons recorded as installed in the cluster.
+func (a *Addon) CheckDependencies(menu *AddonMenu, installed map[string]bool, failed map[string]bool) error {
+	for _, dependency := range a.Spec.DependsOn {
+		if _, found := menu.Addons[dependency]; found {
+			if failed[dependency] {
+				return fmt.Errorf("dependency %q was not updated", dependency)
+			}
+			continue
+		}
+		if !installed[dependency] {
+			return fmt.Errorf("dependency %q is not installed", dependency)
+		}
+	}
+	return nil
+}
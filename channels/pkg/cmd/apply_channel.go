@@ -22,6 +22,8 @@ import (
 	"io"
 	"net/url"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/blang/semver/v4"
 	"github.com/cert-manager/cert-manager/pkg/client/clientset/versioned"
@@ -31,6 +33,7 @@ import (
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/restmapper"
+	"k8s.io/klog/v2"
 	"k8s.io/kops/channels/pkg/channels"
 	"k8s.io/kops/util/pkg/tables"
 	"k8s.io/kops/util/pkg/vfs"
@@ -38,10 +41,16 @@ import (
 
 type ApplyChannelOptions struct {
 	Yes bool
+
+	// HealthTimeout is how long we wait for updated addons to become healthy before rolling them back.
+	// A value of zero disables health checking.
+	HealthTimeout time.Duration
 }
 
 func NewCmdApplyChannel(f Factory, out io.Writer) *cobra.Command {
-	var options ApplyChannelOptions
+	options := ApplyChannelOptions{
+		HealthTimeout: 10 * time.Minute,
+	}
 
 	cmd := &cobra.Command{
 		Use:   "channel CHANNEL",
@@ -53,6 +62,7 @@ func NewCmdApplyChannel(f Factory, out io.Writer) *cobra.Command {
 	}
 
 	cmd.Flags().BoolVar(&options.Yes, "yes", false, "Apply update")
+	cmd.Flags().DurationVar(&options.HealthTimeout, "health-timeout", options.HealthTimeout, "Time to wait for updated addons to become healthy before rolling back; 0 disables health checks")
 
 	return cmd
 }
@@ -103,10 +113,10 @@ func RunApplyChannel(ctx context.Context, f Factory, out io.Writer, options *App
 		return fmt.Errorf("cannot build the addon menu from args: %w", err)
 	}
 
-	return applyMenu(ctx, menu, f.VFSContext(), k8sClient, cmClient, dynamicClient, restMapper, options.Yes)
+	return applyMenu(ctx, menu, f.VFSContext(), k8sClient, cmClient, dynamicClient, restMapper, options.Yes, options.HealthTimeout)
 }
 
-func applyMenu(ctx context.Context, menu *channels.AddonMenu, vfsContext *vfs.VFSContext, k8sClient kubernetes.Interface, cmClient versioned.Interface, dynamicClient dynamic.Interface, restMapper *restmapper.DeferredDiscoveryRESTMapper, apply bool) error {
+func applyMenu(ctx context.Context, menu *channels.AddonMenu, vfsContext *vfs.VFSContext, k8sClient kubernetes.Interface, cmClient versioned.Interface, dynamicClient dynamic.Interface, restMapper *restmapper.DeferredDiscoveryRESTMapper, apply bool, healthTimeout time.Duration) error {
 	// channelVersions is the list of installed addons in the cluster.
 	// It is keyed by <namespace>:<addon name>.
 	channelVersions, err := getChannelVersions(ctx, k8sClient)
@@ -114,14 +124,26 @@ func applyMenu(ctx context.Context, menu *channels.AddonMenu, vfsContext *vfs.VF
 		return fmt.Errorf("cannot fetch channel versions from namespaces: %w", err)
 	}
 
-	updates, needUpdates, err := getUpdates(ctx, menu, k8sClient, cmClient, channelVersions)
+	var merr error
+
+	// Addons that are part of a dependency cycle are reported, but don't block other addons.
+	sortedAddons, err := menu.SortedAddons()
+	if err != nil {
+		merr = multierr.Append(merr, err)
+	}
+
+	updates, needUpdates, err := getUpdates(ctx, sortedAddons, k8sClient, cmClient, channelVersions)
 	if err != nil {
 		return fmt.Errorf("failed to get updates: %w", err)
 	}
 
+	if apply && healthTimeout != 0 {
+		recordInstalledManifests(ctx, sortedAddons, needUpdates, vfsContext, k8sClient, channelVersions)
+	}
+
 	if len(updates) == 0 {
 		fmt.Printf("No update required\n")
-		return nil
+		return merr
 	}
 
 	{
@@ -157,7 +179,7 @@ func applyMenu(ctx context.Context, menu *channels.AddonMenu, vfsContext *vfs.VF
 
 	if !apply {
 		fmt.Printf("\nMust specify --yes to update\n")
-		return nil
+		return merr
 	}
 
 	pruner := &channels.Pruner{
@@ -166,16 +188,38 @@ func applyMenu(ctx context.Context, menu *channels.AddonMenu, vfsContext *vfs.VF
 	}
 
 	applier := &channels.ClientApplier{
-		Client:     dynamicClient,
-		RESTMapper: restMapper,
+		Client:          dynamicClient,
+		RESTMapper:      restMapper,
+		SkipHealthCheck: healthTimeout != 0,
 	}
 
-	var merr error
+	var healthChecker channels.HealthChecker
+	if healthTimeout != 0 {
+		healthChecker = &channels.ClientHealthChecker{
+			Client:     dynamicClient,
+			RESTMapper: restMapper,
+			Timeout:    healthTimeout,
+		}
+	}
+
+	installed := make(map[string]bool)
+	for key := range channelVersions {
+		_, name, _ := strings.Cut(key, ":")
+		installed[name] = true
+	}
+	failed := make(map[string]bool)
 
 	for _, needUpdate := range needUpdates {
-		update, err := needUpdate.EnsureUpdated(ctx, vfsContext, k8sClient, cmClient, pruner, applier, channelVersions[needUpdate.GetNamespace()+":"+needUpdate.Name])
+		if err := needUpdate.CheckDependencies(menu, installed, failed); err != nil {
+			merr = multierr.Append(merr, fmt.Errorf("not updating %q: %w", needUpdate.Name, err))
+			failed[needUpdate.Name] = true
+			continue
+		}
+
+		update, err := needUpdate.EnsureUpdated(ctx, vfsContext, k8sClient, cmClient, pruner, applier, healthChecker, channelVersions[needUpdate.GetNamespace()+":"+needUpdate.Name])
 		if err != nil {
 			merr = multierr.Append(merr, fmt.Errorf("updating %q: %w", needUpdate.Name, err))
+			failed[needUpdate.Name] = true
 		} else if update != nil {
 			fmt.Printf("Updated %q\n", update.Name)
 		}
@@ -184,10 +228,10 @@ func applyMenu(ctx context.Context, menu *channels.AddonMenu, vfsContext *vfs.VF
 	return merr
 }
 
-func getUpdates(ctx context.Context, menu *channels.AddonMenu, k8sClient kubernetes.Interface, cmClient versioned.Interface, channelVersions map[string]*channels.ChannelVersion) ([]*channels.AddonUpdate, []*channels.Addon, error) {
+func getUpdates(ctx context.Context, addons []*channels.Addon, k8sClient kubernetes.Interface, cmClient versioned.Interface, channelVersions map[string]*channels.ChannelVersion) ([]*channels.AddonUpdate, []*channels.Addon, error) {
 	var updates []*channels.AddonUpdate
 	var needUpdates []*channels.Addon
-	for _, addon := range menu.Addons {
+	for _, addon := range addons {
 		update, err := addon.GetRequiredUpdates(ctx, k8sClient, cmClient, channelVersions[addon.GetNamespace()+":"+addon.Name])
 		if err != nil {
 			return nil, nil, fmt.Errorf("error checking for required update: %v", err)
@@ -200,6 +244,23 @@ func getUpdates(ctx context.Context, menu *channels.AddonMenu, k8sClient kuberne
 	return updates, needUpdates, nil
 }
 
+// recordInstalledManifests records the manifests of the addons that are up to date, so that their next update
+// can be rolled back if it doesn't become healthy.
+func recordInstalledManifests(ctx context.Context, addons []*channels.Addon, needUpdates []*channels.Addon, vfsContext *vfs.VFSContext, k8sClient kubernetes.Interface, channelVersions map[string]*channels.ChannelVersion) {
+	updating := make(map[*channels.Addon]bool)
+	for _, addon := range needUpdates {
+		updating[addon] = true
+	}
+	for _, addon := range addons {
+		if updating[addon] {
+			continue
+		}
+		if err := addon.RecordInstalledManifest(ctx, vfsContext, k8sClient, channelVersions[addon.GetNamespace()+":"+addon.Name]); err != nil {
+			klog.Warningf("error recording installed manifest for addon %q: %v", addon.Name, err)
+		}
+	}
+}
+
 func getChannelVersions(ctx context.Context, k8sClient kubernetes.Interface) (map[string]*channels.ChannelVersion, error) {
 	namespaces, err := k8sClient.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
 	if err != nil {
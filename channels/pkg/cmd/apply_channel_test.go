@@ -17,8 +17,11 @@ limitations under the License.
 package cmd
 
 import (
+	"bytes"
 	"context"
+	"net/url"
 	"testing"
+	"time"
 
 	cmfake "github.com/cert-manager/cert-manager/pkg/client/clientset/versioned/fake"
 	corev1 "k8s.io/api/core/v1"
@@ -27,6 +30,7 @@ import (
 	"k8s.io/kops/channels/pkg/api"
 	"k8s.io/kops/channels/pkg/channels"
 	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/util/pkg/vfs"
 )
 
 func TestGetUpdates(t *testing.T) {
@@ -65,7 +69,11 @@ func TestGetUpdates(t *testing.T) {
 			},
 		},
 	}
-	_, needUpdates, err := getUpdates(ctx, menu, k8sClient, cmfake.NewSimpleClientset(), channelVersions)
+	sortedAddons, err := menu.SortedAddons()
+	if err != nil {
+		t.Fatalf("failed to sort addons: %v", err)
+	}
+	_, needUpdates, err := getUpdates(ctx, sortedAddons, k8sClient, cmfake.NewSimpleClientset(), channelVersions)
 	if err != nil {
 		t.Errorf("failed to get updates: %v", err)
 	}
@@ -77,3 +85,59 @@ func TestGetUpdates(t *testing.T) {
 		t.Errorf("expected update in kube-system, but update applied to %q", needUpdates[0].GetNamespace())
 	}
 }
+
+func TestApplyMenuRecordsInstalledManifest(t *testing.T) {
+	// This test checks that the manifest of an up-to-date addon is recorded, so that its next update can be rolled back.
+	ctx := context.Background()
+	manifest := []byte("installed")
+
+	vfsContext := vfs.NewTestingVFSContext()
+	manifestPath, err := vfsContext.BuildVfsPath("memfs://tests/addons/test.yaml")
+	if err != nil {
+		t.Fatalf("error building vfs path: %v", err)
+	}
+	if err := manifestPath.WriteFile(ctx, bytes.NewReader(manifest), nil); err != nil {
+		t.Fatalf("error writing manifest: %v", err)
+	}
+	channelLocation, err := url.Parse("memfs://tests/addons/channel.yaml")
+	if err != nil {
+		t.Fatalf("error parsing url: %v", err)
+	}
+
+	kubeSystemNS := corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "kube-system",
+			Annotations: map[string]string{
+				"addons.k8s.io/test": "{\"manifestHash\":\"installedHash\",\"systemGeneration\":1}",
+			},
+		},
+	}
+	k8sClient := fakek8s.NewSimpleClientset(&kubeSystemNS)
+
+	menu := channels.NewAddonMenu()
+	menu.Addons = map[string]*channels.Addon{
+		"test": {
+			Name:            "test",
+			ChannelLocation: *channelLocation,
+			Spec: &api.AddonSpec{
+				Name:         fi.PtrTo("test"),
+				Manifest:     fi.PtrTo("test.yaml"),
+				ManifestHash: "installedHash",
+			},
+		},
+	}
+
+	// The addon is up to date, so nothing is applied and the dynamic client isn't needed
+	if err := applyMenu(ctx, menu, vfsContext, k8sClient, cmfake.NewSimpleClientset(), nil, nil, true, time.Minute); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	channel := &channels.Channel{Namespace: "kube-system", Name: "test"}
+	recorded, err := channel.GetAppliedManifest(ctx, k8sClient)
+	if err != nil {
+		t.Fatalf("error getting applied manifest: %v", err)
+	}
+	if !bytes.Equal(recorded, manifest) {
+		t.Errorf("expected recorded manifest %q, got %q", manifest, recorded)
+	}
+}
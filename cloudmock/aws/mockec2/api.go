@@ -62,6 +62,9 @@ type MockEC2 struct {
 
 	NatGateways map[string]*ec2types.NatGateway
 
+	// InstanceTypes is the catalog of instance types returned by DescribeInstanceTypes.
+	InstanceTypes []ec2types.InstanceTypeInfo
+
 	idsMutex sync.Mutex
 	ids      map[string]*idAllocator
 }
@@ -18,6 +18,9 @@ package mockec2
 
 import (
 	"context"
+	"fmt"
+	"slices"
+	"strconv"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
@@ -31,8 +34,35 @@ func (m *MockEC2) DescribeInstances(ctx context.Context, request *ec2.DescribeIn
 }
 
 func (m *MockEC2) DescribeInstanceTypes(ctx context.Context, request *ec2.DescribeInstanceTypesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeInstanceTypesOutput, error) {
-	klog.Warningf("MockEc2::DescribeInstanceTypes is stub-implemented")
-	return &ec2.DescribeInstanceTypesOutput{}, nil
+	if len(m.InstanceTypes) == 0 {
+		klog.Warningf("MockEc2::DescribeInstanceTypes is stub-implemented")
+		return &ec2.DescribeInstanceTypesOutput{}, nil
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	response := &ec2.DescribeInstanceTypesOutput{}
+	for _, info := range m.InstanceTypes {
+		if len(request.InstanceTypes) != 0 && !slices.Contains(request.InstanceTypes, info.InstanceType) {
+			continue
+		}
+		match := true
+		for _, filter := range request.Filters {
+			switch aws.ToString(filter.Name) {
+			case "current-generation":
+				if !slices.Contains(filter.Values, strconv.FormatBool(aws.ToBool(info.CurrentGeneration))) {
+					match = false
+				}
+			default:
+				return nil, fmt.Errorf("unknown filter name: %q", aws.ToString(filter.Name))
+			}
+		}
+		if match {
+			response.InstanceTypes = append(response.InstanceTypes, info)
+		}
+	}
+	return response, nil
 }
 
 func (m *MockEC2) GetInstanceTypesFromInstanceRequirements(ctx context.Context, request *ec2.GetInstanceTypesFromInstanceRequirementsInput, optFns ...func(*ec2.Options)) (*ec2.GetInstanceTypesFromInstanceRequirementsOutput, error) {
@@ -19,6 +19,7 @@ package mockroute53
 import (
 	"context"
 	"fmt"
+	"sort"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/route53"
@@ -26,6 +27,9 @@ import (
 	"k8s.io/klog/v2"
 )
 
+// DefaultMaxItems is the page size of ListResourceRecordSets, matching route53
+const DefaultMaxItems = 300
+
 func (m *MockRoute53) ListResourceRecordSets(ctx context.Context, request *route53.ListResourceRecordSetsInput, optFns ...func(*route53.Options)) (*route53.ListResourceRecordSetsOutput, error) {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
@@ -37,7 +41,7 @@ func (m *MockRoute53) ListResourceRecordSets(ctx context.Context, request *route
 		return nil, fmt.Errorf("HostedZoneId required")
 	}
 
-	if request.StartRecordIdentifier != nil || request.StartRecordName != nil || len(request.StartRecordType) > 0 || request.MaxItems != nil {
+	if request.StartRecordIdentifier != nil {
 		klog.Fatalf("Unsupported options: %v", request)
 	}
 
@@ -48,15 +52,50 @@ func (m *MockRoute53) ListResourceRecordSets(ctx context.Context, request *route
 		return nil, fmt.Errorf("NOT FOUND")
 	}
 
-	page := &route53.ListResourceRecordSetsOutput{}
-	for _, r := range zone.records {
-		copy := *r
-		page.ResourceRecordSets = append(page.ResourceRecordSets, copy)
+	maxItems := int32(DefaultMaxItems)
+	if request.MaxItems != nil && *request.MaxItems < maxItems {
+		maxItems = *request.MaxItems
+	}
+
+	// Like route53, we return records ordered by name and type, starting from StartRecordName and StartRecordType
+	records := make([]*route53types.ResourceRecordSet, len(zone.records))
+	copy(records, zone.records)
+	sort.SliceStable(records, func(i, j int) bool {
+		return recordLess(records[i], records[j])
+	})
+
+	page := &route53.ListResourceRecordSetsOutput{
+		MaxItems: aws.Int32(maxItems),
+	}
+	for _, r := range records {
+		if request.StartRecordName != nil {
+			start := &route53types.ResourceRecordSet{Name: request.StartRecordName, Type: request.StartRecordType}
+			if recordLess(r, start) {
+				continue
+			}
+		}
+		if len(page.ResourceRecordSets) >= int(maxItems) {
+			page.IsTruncated = true
+			page.NextRecordName = r.Name
+			page.NextRecordType = r.Type
+			break
+		}
+		page.ResourceRecordSets = append(page.ResourceRecordSets, *r)
 	}
 
 	return page, nil
 }
 
+// recordLess orders records by name and then type
+func recordLess(l, r *route53types.ResourceRecordSet) bool {
+	lName := aws.ToString(l.Name)
+	rName := aws.ToString(r.Name)
+	if lName != rName {
+		return lName < rName
+	}
+	return l.Type < r.Type
+}
+
 func (m *MockRoute53) ChangeResourceRecordSets(ctx context.Context, request *route53.ChangeResourceRecordSetsInput, optFns ...func(*route53.Options)) (*route53.ChangeResourceRecordSetsOutput, error) {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
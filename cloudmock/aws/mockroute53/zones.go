@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/route53"
 	route53types "github.com/aws/aws-sdk-go-v2/service/route53/types"
 	"k8s.io/klog/v2"
@@ -83,3 +84,68 @@ func (m *MockRoute53) ListHostedZonesByName(ctx context.Context, request *route5
 		HostedZones: zones,
 	}, nil
 }
+
+func (m *MockRoute53) CreateHostedZone(ctx context.Context, request *route53.CreateHostedZoneInput, optFns ...func(*route53.Options)) (*route53.CreateHostedZoneOutput, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	klog.Infof("CreateHostedZone %v", request)
+
+	name := aws.ToString(request.Name)
+	for _, z := range m.Zones {
+		if aws.ToString(z.hostedZone.Name) == name {
+			// TODO: Use correct error
+			return nil, fmt.Errorf("HostedZoneAlreadyExists: %s", name)
+		}
+	}
+
+	id := fmt.Sprintf("/hostedzone/Z%d", len(m.Zones)+1)
+	hostedZone := &route53types.HostedZone{
+		Id:              aws.String(id),
+		Name:            aws.String(name),
+		CallerReference: request.CallerReference,
+	}
+	m.Zones = append(m.Zones, &zoneInfo{
+		ID:         id,
+		hostedZone: hostedZone,
+	})
+
+	copy := *hostedZone
+	return &route53.CreateHostedZoneOutput{
+		HostedZone: &copy,
+		ChangeInfo: &route53types.ChangeInfo{},
+	}, nil
+}
+
+func (m *MockRoute53) DeleteHostedZone(ctx context.Context, request *route53.DeleteHostedZoneInput, optFns ...func(*route53.Options)) (*route53.DeleteHostedZoneOutput, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	klog.Infof("DeleteHostedZone %v", request)
+
+	if request.Id == nil {
+		// TODO: Use correct error
+		return nil, fmt.Errorf("Id is required")
+	}
+	zone := m.findZone(*request.Id)
+	if zone == nil {
+		// TODO: Use correct error
+		return nil, fmt.Errorf("NOT FOUND")
+	}
+	if len(zone.records) != 0 {
+		// TODO: Use correct error
+		return nil, fmt.Errorf("HostedZoneNotEmpty: %s", *request.Id)
+	}
+
+	var zones []*zoneInfo
+	for _, z := range m.Zones {
+		if z != zone {
+			zones = append(zones, z)
+		}
+	}
+	m.Zones = zones
+
+	return &route53.DeleteHostedZoneOutput{
+		ChangeInfo: &route53types.ChangeInfo{},
+	}, nil
+}
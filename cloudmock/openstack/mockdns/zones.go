@@ -31,10 +31,6 @@ type zoneListResponse struct {
 	Zones []zones.Zone `json:"zones"`
 }
 
-type zoneGetResponse struct {
-	Zone zones.Zone `json:"zone"`
-}
-
 type recordSetsListResponse struct {
 	RecordSets []recordsets.RecordSet `json:"recordsets"`
 }
@@ -62,8 +58,8 @@ func (m *MockClient) mockZones() {
 				// /zones
 				m.listZones(w)
 			} else if len(parts) == 3 && parts[2] == "recordsets" {
-				// /zones/<zoneid>/recordsets
-				m.listRecordSets(w, zoneID)
+				// /zones/<zoneid>/recordsets?name=<name>&type=<type>
+				m.listRecordSets(w, zoneID, r.Form.Get("name"), r.Form.Get("type"))
 			} else if len(parts) == 4 && parts[2] == "recordsets" {
 				// /zones/<zoneid>/recordsets/<recordsetid>
 				m.getRecordSet(w, zoneID, parts[3])
@@ -72,9 +68,26 @@ func (m *MockClient) mockZones() {
 				m.getZone(w, zoneName)
 			}
 		case http.MethodPost:
-			m.createZone(w, r)
+			if len(parts) == 3 && parts[2] == "recordsets" {
+				// /zones/<zoneid>/recordsets
+				m.createRecordSet(w, r, zoneID)
+			} else {
+				m.createZone(w, r)
+			}
+		case http.MethodPut:
+			if len(parts) == 4 && parts[2] == "recordsets" {
+				// /zones/<zoneid>/recordsets/<recordsetid>
+				m.updateRecordSet(w, r, zoneID, parts[3])
+			} else {
+				w.WriteHeader(http.StatusBadRequest)
+			}
 		case http.MethodDelete:
-			m.deleteZone(w, zoneID)
+			if len(parts) == 4 && parts[2] == "recordsets" {
+				// /zones/<zoneid>/recordsets/<recordsetid>
+				m.deleteRecordSet(w, zoneID, parts[3])
+			} else {
+				m.deleteZone(w, zoneID)
+			}
 		default:
 			w.WriteHeader(http.StatusBadRequest)
 		}
@@ -126,11 +139,26 @@ func (m *MockClient) getZone(w http.ResponseWriter, zoneName string) {
 }
 
 func (m *MockClient) deleteZone(w http.ResponseWriter, zoneID string) {
-	if _, ok := m.zones[zoneID]; ok {
-		delete(m.zones, zoneID)
-		w.WriteHeader(http.StatusOK)
-	} else {
+	zone, ok := m.zones[zoneID]
+	if !ok {
 		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	delete(m.zones, zoneID)
+	for id, r := range m.recordSets {
+		if r.ZoneID == zoneID {
+			delete(m.recordSets, id)
+		}
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+	respB, err := json.Marshal(zone)
+	if err != nil {
+		panic(fmt.Sprintf("failed to marshal %+v", zone))
+	}
+	_, err = w.Write(respB)
+	if err != nil {
+		panic("failed to write body")
 	}
 }
 
@@ -149,12 +177,10 @@ func (m *MockClient) createZone(w http.ResponseWriter, r *http.Request) {
 	}
 	m.zones[z.ID] = z
 
-	resp := zoneGetResponse{
-		Zone: z,
-	}
-	respB, err := json.Marshal(resp)
+	// Like designate, the created zone is returned at the top level
+	respB, err := json.Marshal(z)
 	if err != nil {
-		panic(fmt.Sprintf("failed to marshal %+v", resp))
+		panic(fmt.Sprintf("failed to marshal %+v", z))
 	}
 	_, err = w.Write(respB)
 	if err != nil {
@@ -162,7 +188,7 @@ func (m *MockClient) createZone(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func (m *MockClient) listRecordSets(w http.ResponseWriter, zoneID string) {
+func (m *MockClient) listRecordSets(w http.ResponseWriter, zoneID, name, recordType string) {
 	w.WriteHeader(http.StatusOK)
 
 	records := make([]recordsets.RecordSet, 0)
@@ -170,6 +196,12 @@ func (m *MockClient) listRecordSets(w http.ResponseWriter, zoneID string) {
 		if r.ZoneID != zoneID {
 			continue
 		}
+		if name != "" && r.Name != ensureDotSuffix(name) {
+			continue
+		}
+		if recordType != "" && r.Type != recordType {
+			continue
+		}
 		records = append(records, r)
 	}
 
@@ -205,3 +237,90 @@ func (m *MockClient) getRecordSet(w http.ResponseWriter, zoneID, recordSetID str
 		w.WriteHeader(http.StatusNotFound)
 	}
 }
+
+func (m *MockClient) createRecordSet(w http.ResponseWriter, r *http.Request, zoneID string) {
+	if _, ok := m.zones[zoneID]; !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	var create recordsets.CreateOpts
+	err := json.NewDecoder(r.Body).Decode(&create)
+	if err != nil {
+		panic("error decoding create recordset request")
+	}
+
+	// Like designate, we reject duplicate recordsets
+	name := ensureDotSuffix(create.Name)
+	for _, rs := range m.recordSets {
+		if rs.ZoneID == zoneID && rs.Name == name && rs.Type == create.Type {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+	}
+
+	rs := recordsets.RecordSet{
+		ID:      uuid.New().String(),
+		ZoneID:  zoneID,
+		Name:    name,
+		Type:    create.Type,
+		TTL:     create.TTL,
+		Records: create.Records,
+	}
+	m.recordSets[rs.ID] = rs
+
+	m.writeRecordSet(w, http.StatusAccepted, rs)
+}
+
+func (m *MockClient) updateRecordSet(w http.ResponseWriter, r *http.Request, zoneID, recordSetID string) {
+	rs, ok := m.recordSets[recordSetID]
+	if !ok || rs.ZoneID != zoneID {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	var update recordsets.UpdateOpts
+	err := json.NewDecoder(r.Body).Decode(&update)
+	if err != nil {
+		panic("error decoding update recordset request")
+	}
+	if update.TTL != nil {
+		rs.TTL = *update.TTL
+	}
+	if update.Records != nil {
+		rs.Records = update.Records
+	}
+	m.recordSets[recordSetID] = rs
+
+	m.writeRecordSet(w, http.StatusAccepted, rs)
+}
+
+func (m *MockClient) deleteRecordSet(w http.ResponseWriter, zoneID, recordSetID string) {
+	rs, ok := m.recordSets[recordSetID]
+	if !ok || rs.ZoneID != zoneID {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	delete(m.recordSets, recordSetID)
+
+	m.writeRecordSet(w, http.StatusAccepted, rs)
+}
+
+func (m *MockClient) writeRecordSet(w http.ResponseWriter, status int, rs recordsets.RecordSet) {
+	w.WriteHeader(status)
+	respB, err := json.Marshal(rs)
+	if err != nil {
+		panic(fmt.Sprintf("failed to marshal %+v", rs))
+	}
+	_, err = w.Write(respB)
+	if err != nil {
+		panic("failed to write body")
+	}
+}
+
+func ensureDotSuffix(name string) string {
+	if !strings.HasSuffix(name, ".") {
+		name += "."
+	}
+	return name
+}
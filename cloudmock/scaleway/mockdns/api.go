@@ -79,42 +79,68 @@ func (f *FakeDomainAPI) ListDNSZoneRecords(req *domain.ListDNSZoneRecordsRequest
 }
 
 func (f *FakeDomainAPI) UpdateDNSZoneRecords(req *domain.UpdateDNSZoneRecordsRequest, opts ...scw.RequestOption) (*domain.UpdateDNSZoneRecordsResponse, error) {
+	// Like the real API, changes are applied atomically, so we work on a copy of the records
+	records := make(map[string]*domain.Record, len(f.Records))
+	for k, v := range f.Records {
+		records[k] = v
+	}
+
+	// Like the real API, only one edit of each record name is allowed in a request
+	edited := make(map[string]bool)
+	for _, change := range req.Changes {
+		name, err := changeRecordName(records, change)
+		if err != nil {
+			return nil, err
+		}
+		if edited[name] {
+			return nil, fmt.Errorf("more than one edit of record %q in a single request", name)
+		}
+		edited[name] = true
+	}
+
 	for _, change := range req.Changes {
 
 		if change.Add != nil {
 			for _, toAdd := range change.Add.Records {
 				toAdd.ID = uuid.New().String()
-				f.Records[toAdd.Name] = toAdd
+				records[toAdd.ID] = toAdd
 			}
 
 		} else if change.Set != nil {
-			if len(change.Set.Records) != 1 {
-				fmt.Printf("only 1 record change will be applied from %d changes requested", len(change.Set.Records))
+			keys := findRecords(records, change.Set.ID, change.Set.IDFields)
+			if len(keys) == 0 {
+				return nil, fmt.Errorf("could not find record to set")
 			}
-			for _, toUpsert := range change.Set.Records {
-				if _, ok := f.Records[toUpsert.Name]; !ok {
-					return nil, fmt.Errorf("could not find record %s to upsert", toUpsert.Name)
+			if change.Set.ID != nil {
+				if len(change.Set.Records) != 1 {
+					return nil, fmt.Errorf("expected 1 record when setting record %s, got %d", *change.Set.ID, len(change.Set.Records))
 				}
+				toUpsert := change.Set.Records[0]
 				toUpsert.ID = *change.Set.ID
-				f.Records[toUpsert.Name] = toUpsert
+				records[keys[0]] = toUpsert
+			} else {
+				for _, key := range keys {
+					delete(records, key)
+				}
+				for _, toUpsert := range change.Set.Records {
+					toUpsert.ID = uuid.New().String()
+					records[toUpsert.ID] = toUpsert
+				}
 			}
 		} else if change.Delete != nil {
-			found := false
-			for name, record := range f.Records {
-				if record.ID == *change.Delete.ID {
-					delete(f.Records, name)
-					found = true
-					break
-				}
+			keys := findRecords(records, change.Delete.ID, change.Delete.IDFields)
+			if len(keys) == 0 {
+				return nil, fmt.Errorf("could not find record to delete")
 			}
-			if found == false {
-				return nil, fmt.Errorf("could not find record %s to delete", *change.Delete.ID)
+			for _, key := range keys {
+				delete(records, key)
 			}
 
 		} else {
 			return nil, fmt.Errorf("mock DNS not implemented for this method")
 		}
 	}
+	f.Records = records
 
 	var recordsList []*domain.Record
 	for _, record := range f.Records {
@@ -122,3 +148,54 @@ func (f *FakeDomainAPI) UpdateDNSZoneRecords(req *domain.UpdateDNSZoneRecordsReq
 	}
 	return &domain.UpdateDNSZoneRecordsResponse{Records: recordsList}, nil
 }
+
+// changeRecordName returns the name of the records edited by a change
+func changeRecordName(records map[string]*domain.Record, change *domain.RecordChange) (string, error) {
+	var id *string
+	var idFields *domain.RecordIdentifier
+	switch {
+	case change.Add != nil:
+		if len(change.Add.Records) == 0 {
+			return "", fmt.Errorf("no records to add")
+		}
+		return change.Add.Records[0].Name, nil
+	case change.Set != nil:
+		id, idFields = change.Set.ID, change.Set.IDFields
+	case change.Delete != nil:
+		id, idFields = change.Delete.ID, change.Delete.IDFields
+	default:
+		return "", fmt.Errorf("mock DNS not implemented for this method")
+	}
+	if idFields != nil {
+		return idFields.Name, nil
+	}
+	keys := findRecords(records, id, idFields)
+	if len(keys) == 0 {
+		return "", fmt.Errorf("could not find record to edit")
+	}
+	return records[keys[0]].Name, nil
+}
+
+// findRecords returns the keys of the records matching either the record ID or the identifying fields
+func findRecords(records map[string]*domain.Record, id *string, idFields *domain.RecordIdentifier) []string {
+	var keys []string
+	for key, record := range records {
+		if id != nil {
+			if record.ID == *id {
+				keys = append(keys, key)
+			}
+			continue
+		}
+		if idFields == nil || record.Name != idFields.Name || record.Type != idFields.Type {
+			continue
+		}
+		if idFields.Data != nil && record.Data != *idFields.Data {
+			continue
+		}
+		if idFields.TTL != nil && record.TTL != *idFields.TTL {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	return keys
+}
@@ -26,6 +26,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
 	"k8s.io/klog/v2"
 	"k8s.io/klog/v2/klogr"
@@ -41,6 +42,7 @@ import (
 	nodeidentitydo "k8s.io/kops/pkg/nodeidentity/do"
 	nodeidentitygce "k8s.io/kops/pkg/nodeidentity/gce"
 	nodeidentityhetzner "k8s.io/kops/pkg/nodeidentity/hetzner"
+	nodeidentitymetal "k8s.io/kops/pkg/nodeidentity/metal"
 	nodeidentityos "k8s.io/kops/pkg/nodeidentity/openstack"
 	nodeidentityscw "k8s.io/kops/pkg/nodeidentity/scaleway"
 	"k8s.io/kops/upup/pkg/fi/cloudup/awsup"
@@ -72,6 +74,14 @@ func main() {
 
 	klog.InitFlags(nil)
 
+	// The etcd backup verification CronJob runs the kops-controller image
+	if len(os.Args) > 1 && os.Args[1] == "verify-etcd-backup" {
+		if err := runVerifyEtcdBackup(ctx, os.Args[2:]); err != nil {
+			klog.Fatalf("%v", err)
+		}
+		os.Exit(0)
+	}
+
 	// Disable metrics by default (avoid port conflicts, also risky because we are host network)
 	metricsAddress := ":0"
 	// flag.StringVar(&metricsAddr, "metrics-addr", metricsAddress, "The address the metric endpoint binds to.")
@@ -99,6 +109,10 @@ func main() {
 		}
 	}
 
+	if opt.MetricsBindAddress != "" {
+		metricsAddress = opt.MetricsBindAddress
+	}
+
 	ctrl.SetLogger(klogr.New())
 
 	scheme, err := buildScheme()
@@ -208,9 +222,19 @@ func main() {
 			os.Exit(1)
 		}
 
+		// Nodes that update in place are drained by kops-controller
+		var kubeClient kubernetes.Interface
+		if opt.Server.InPlaceUpdates {
+			kubeClient, err = kubernetes.NewForConfig(mgr.GetConfig())
+			if err != nil {
+				setupLog.Error(err, "error creating kube client")
+				os.Exit(1)
+			}
+		}
+
 		verifier := bootstrap.NewChainVerifier(verifiers...)
 
-		srv, err := server.NewServer(vfsContext, &opt, verifier, uncachedClient)
+		srv, err := server.NewServer(vfsContext, &opt, verifier, uncachedClient, kubeClient)
 		if err != nil {
 			setupLog.Error(err, "unable to create server")
 			os.Exit(1)
@@ -307,6 +331,12 @@ func addNodeController(ctx context.Context, mgr manager.Manager, vfsContext *vfs
 			return fmt.Errorf("error building identifier: %w", err)
 		}
 
+	case "metal":
+		legacyIdentifier, err = nodeidentitymetal.New(mgr.GetClient())
+		if err != nil {
+			return fmt.Errorf("error building identifier: %w", err)
+		}
+
 	case "":
 		return fmt.Errorf("must specify cloud")
 
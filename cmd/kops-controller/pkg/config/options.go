@@ -40,6 +40,9 @@ type Options struct {
 
 	// Discovery configures options relating to discovery, particularly for gossip mode.
 	Discovery *DiscoveryOptions `json:"discovery,omitempty"`
+
+	// MetricsBindAddress is the address the Prometheus metrics are served on; metrics are disabled if empty.
+	MetricsBindAddress string `json:"metricsBindAddress,omitempty"`
 }
 
 func (o *Options) PopulateDefaults() {
@@ -66,6 +69,36 @@ type ServerOptions struct {
 	SigningCAs []string `json:"signingCAs"`
 	// CertNames is the list of active certificate names.
 	CertNames []string `json:"certNames"`
+
+	// InPlaceUpdates enables the endpoint used by nodes that update their configuration in place.
+	InPlaceUpdates bool `json:"inPlaceUpdates,omitempty"`
+
+	// NodeAudit enables the endpoint used by nodes that audit their configuration, without the in-place update actions.
+	NodeAudit bool `json:"nodeAudit,omitempty"`
+
+	// VolumeEncryptionKeys enables the endpoint that returns the keys of the volumes encrypted by nodes.
+	VolumeEncryptionKeys bool `json:"volumeEncryptionKeys,omitempty"`
+
+	// AdminCredentialDenyList enables the authorization webhook that denies the requests of revoked admin credentials.
+	AdminCredentialDenyList bool `json:"adminCredentialDenyList,omitempty"`
+
+	// CertificateRenewal enables the endpoint nodes renew their certificates with, authenticated with their kubelet client certificate.
+	CertificateRenewal bool `json:"certificateRenewal,omitempty"`
+
+	// BootstrapRateLimit limits the rate of bootstrap requests; defaults are used if not set.
+	BootstrapRateLimit *RateLimitOptions `json:"bootstrapRateLimit,omitempty"`
+}
+
+// RateLimitOptions configures the rate of requests allowed, per source address and globally.
+type RateLimitOptions struct {
+	// PerSourceQPS is the sustained rate of requests allowed from a single source address.
+	PerSourceQPS float64 `json:"perSourceQPS,omitempty"`
+	// PerSourceBurst is the number of requests a single source address can make at once.
+	PerSourceBurst int `json:"perSourceBurst,omitempty"`
+	// GlobalQPS is the sustained rate of requests allowed from all sources.
+	GlobalQPS float64 `json:"globalQPS,omitempty"`
+	// GlobalBurst is the number of requests all sources can make at once.
+	GlobalBurst int `json:"globalBurst,omitempty"`
 }
 
 type ServerProviderOptions struct {
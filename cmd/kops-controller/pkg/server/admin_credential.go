@@ -0,0 +1,127 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/kops/pkg/admincredentials"
+	"k8s.io/kops/pkg/rbac"
+)
+
+// adminCredentialCacheTTL is how long the records of admin credentials are cached,
+// bounding both the reads of the state store and the delay before a revocation is enforced.
+const adminCredentialCacheTTL = 30 * time.Second
+
+type adminCredentialCacheEntry struct {
+	credential *admincredentials.AdminCredential
+	fetched    time.Time
+}
+
+// adminCredentialCache caches the records of admin credentials read from the state store.
+type adminCredentialCache struct {
+	mutex   sync.Mutex
+	entries map[string]adminCredentialCacheEntry
+}
+
+// adminCredentialReview is the authorization webhook called by kube-apiserver for the requests of admin credentials.
+// It denies the requests of revoked or unknown admin credentials, and has no opinion on the others,
+// leaving the decision to the authorizers that follow it.
+// Only kube-apiserver, authenticated with its admin credential reviewer certificate, can call it.
+func (s *Server) adminCredentialReview(w http.ResponseWriter, r *http.Request) {
+	clientCert, err := s.verifyClientCertificate(r)
+	if err != nil {
+		klog.Infof("admin-credential-review %s client certificate verify err: %v", r.RemoteAddr, err)
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte("failed to verify client certificate"))
+		return
+	}
+	if clientCert.Subject.CommonName != rbac.KopsAdminCredentialReviewer {
+		klog.Infof("admin-credential-review %s client certificate %q is not allowed to review", r.RemoteAddr, clientCert.Subject.CommonName)
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte("not allowed to review admin credentials"))
+		return
+	}
+
+	if r.Body == nil {
+		klog.Infof("admin-credential-review %s no body", r.RemoteAddr)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	review := &authorizationv1.SubjectAccessReview{}
+	if err := json.NewDecoder(r.Body).Decode(review); err != nil {
+		klog.Infof("admin-credential-review %s decode err: %v", r.RemoteAddr, err)
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(fmt.Sprintf("failed to decode: %v", err)))
+		return
+	}
+
+	review.Status = authorizationv1.SubjectAccessReviewStatus{}
+	if serial, ok := admincredentials.SerialFromGroups(review.Spec.Groups); ok {
+		credential, err := s.readAdminCredential(r, serial)
+		if err != nil {
+			klog.Warningf("admin-credential-review %s error reading admin credential %q: %v", r.RemoteAddr, serial, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = w.Write([]byte("internal error"))
+			return
+		}
+		switch {
+		case credential == nil:
+			review.Status.Denied = true
+			review.Status.Reason = fmt.Sprintf("admin credential %s was not issued by kops", serial)
+		case credential.RevokedAt != nil:
+			review.Status.Denied = true
+			review.Status.Reason = fmt.Sprintf("admin credential %s was revoked at %s", serial, credential.RevokedAt.Format(time.RFC3339))
+		}
+		if review.Status.Denied {
+			klog.Infof("admin-credential-review denied request of user %q: %s", review.Spec.User, review.Status.Reason)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(review)
+}
+
+// readAdminCredential returns the record of an admin credential, from the cache if it is recent enough.
+func (s *Server) readAdminCredential(r *http.Request, serial string) (*admincredentials.AdminCredential, error) {
+	s.adminCredentials.mutex.Lock()
+	entry, found := s.adminCredentials.entries[serial]
+	s.adminCredentials.mutex.Unlock()
+	if found && time.Since(entry.fetched) < adminCredentialCacheTTL {
+		return entry.credential, nil
+	}
+
+	credential, err := admincredentials.Read(r.Context(), s.configBase, serial)
+	if err != nil {
+		return nil, err
+	}
+
+	s.adminCredentials.mutex.Lock()
+	defer s.adminCredentials.mutex.Unlock()
+	if s.adminCredentials.entries == nil {
+		s.adminCredentials.entries = make(map[string]adminCredentialCacheEntry)
+	}
+	s.adminCredentials.entries[serial] = adminCredentialCacheEntry{credential: credential, fetched: time.Now()}
+	return credential, nil
+}
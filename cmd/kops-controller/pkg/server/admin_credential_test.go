@@ -0,0 +1,215 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/kops/pkg/admincredentials"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/rbac"
+	"k8s.io/kops/util/pkg/vfs"
+)
+
+// testCA is a CA issuing the client certificates of tests.
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("error generating key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "kubernetes-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		t.Fatalf("error creating CA certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("error parsing CA certificate: %v", err)
+	}
+	return &testCA{cert: cert, key: key}
+}
+
+func (ca *testCA) pool() *x509.CertPool {
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.cert)
+	return pool
+}
+
+// issueClientCert issues a client certificate with the given subject.
+func (ca *testCA) issueClientCert(t *testing.T, subject pkix.Name) *x509.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("error generating key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      subject,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, key.Public(), ca.key)
+	if err != nil {
+		t.Fatalf("error creating client certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("error parsing client certificate: %v", err)
+	}
+	return cert
+}
+
+func TestAdminCredentialReview(t *testing.T) {
+	ctx := context.TODO()
+
+	vfsContext := vfs.NewVFSContext()
+	vfsContext.ResetMemfsContext(true)
+	configBase, err := vfsContext.BuildVfsPath("memfs://state/cluster.example.com")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	revokedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, credential := range []*admincredentials.AdminCredential{
+		{Serial: "100", CommonName: "kubecfg-user"},
+		{Serial: "200", CommonName: "kubecfg-user", RevokedAt: &revokedAt},
+	} {
+		if err := admincredentials.Write(ctx, configBase, &kops.Cluster{}, credential); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	ca := newTestCA(t)
+	otherCA := newTestCA(t)
+	reviewer := pkix.Name{CommonName: rbac.KopsAdminCredentialReviewer}
+
+	grid := []struct {
+		name       string
+		clientCert *x509.Certificate
+		serial     string
+		statusCode int
+		denied     bool
+	}{
+		{
+			name:       "no client certificate",
+			serial:     "200",
+			statusCode: http.StatusUnauthorized,
+		},
+		{
+			name:       "client certificate of another CA",
+			clientCert: otherCA.issueClientCert(t, reviewer),
+			serial:     "200",
+			statusCode: http.StatusUnauthorized,
+		},
+		{
+			name:       "client certificate of another user",
+			clientCert: ca.issueClientCert(t, pkix.Name{CommonName: "system:node:node-a", Organization: []string{rbac.NodesGroup}}),
+			serial:     "200",
+			statusCode: http.StatusForbidden,
+		},
+		{
+			name:       "recorded credential",
+			clientCert: ca.issueClientCert(t, reviewer),
+			serial:     "100",
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "revoked credential",
+			clientCert: ca.issueClientCert(t, reviewer),
+			serial:     "200",
+			statusCode: http.StatusOK,
+			denied:     true,
+		},
+		{
+			name:       "unrecorded credential",
+			clientCert: ca.issueClientCert(t, reviewer),
+			serial:     "300",
+			statusCode: http.StatusOK,
+			denied:     true,
+		},
+	}
+	for _, g := range grid {
+		t.Run(g.name, func(t *testing.T) {
+			s := &Server{
+				configBase: configBase,
+				clientCAs:  ca.pool(),
+			}
+
+			body, err := json.Marshal(&authorizationv1.SubjectAccessReview{
+				Spec: authorizationv1.SubjectAccessReviewSpec{
+					User:   "kubecfg-user",
+					Groups: []string{rbac.KopsAdminsGroup, admincredentials.SerialGroup(g.serial)},
+				},
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			req := httptest.NewRequest(http.MethodPost, "/admin-credential-review", bytes.NewReader(body))
+			if g.clientCert != nil {
+				req.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{g.clientCert}}
+			}
+			rec := httptest.NewRecorder()
+			s.adminCredentialReview(rec, req)
+
+			if rec.Code != g.statusCode {
+				t.Fatalf("expected status %d, got %d: %s", g.statusCode, rec.Code, rec.Body.String())
+			}
+			if rec.Code != http.StatusOK {
+				return
+			}
+			review := &authorizationv1.SubjectAccessReview{}
+			if err := json.Unmarshal(rec.Body.Bytes(), review); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if review.Status.Denied != g.denied {
+				t.Errorf("expected denied %v, got %v (%s)", g.denied, review.Status.Denied, review.Status.Reason)
+			}
+			if review.Status.Allowed {
+				t.Errorf("expected no opinion on allowed requests")
+			}
+		})
+	}
+}
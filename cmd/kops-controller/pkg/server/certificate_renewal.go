@@ -0,0 +1,202 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/apis/nodeup"
+	"k8s.io/kops/pkg/bootstrap"
+	"k8s.io/kops/pkg/pki"
+	"k8s.io/kops/pkg/rbac"
+)
+
+// certificateRenewalVerifier is the verifier recorded in the audit log for renewed certificates.
+const certificateRenewalVerifier = "kubelet-client-certificate"
+
+// kubeletServerCertificateNames returns the names of the current serving certificate of a node's kubelet,
+// once verified to be issued by the cluster CA to the node.
+func (s *Server) kubeletServerCertificateNames(current string, nodeName string) ([]string, error) {
+	if current == "" {
+		return nil, fmt.Errorf("no current certificate")
+	}
+	cert, err := pki.ParsePEMCertificate([]byte(current))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := cert.Certificate.Verify(x509.VerifyOptions{
+		Roots:     s.clientCAs,
+		KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}); err != nil {
+		return nil, err
+	}
+	if cert.Subject.CommonName != nodeName {
+		return nil, fmt.Errorf("certificate %q is not issued to node %q", cert.Subject.CommonName, nodeName)
+	}
+
+	var names []string
+	names = append(names, cert.Certificate.DNSNames...)
+	for _, ip := range cert.Certificate.IPAddresses {
+		names = append(names, ip.String())
+	}
+	return names, nil
+}
+
+// renewCertificates issues new certificates to a node, authenticated with its current kubelet client certificate.
+// Nodes are not bootstrapped again once registered, so this lets long-lived nodes replace their certificates before they expire.
+func (s *Server) renewCertificates(w http.ResponseWriter, r *http.Request) {
+	if ok, reason := s.bootstrapRateLimiter.allow(r.RemoteAddr, time.Now()); !ok {
+		klog.Infof("renew-certificates %s %s", r.RemoteAddr, reason)
+		certificateRenewals.WithLabelValues(reason).Inc()
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte("too many requests"))
+		return
+	}
+
+	clientCert, err := s.verifyClientCertificate(r)
+	if errors.Is(err, errNoClientCertificate) {
+		klog.Infof("renew-certificates %s no client certificate", r.RemoteAddr)
+		certificateRenewals.WithLabelValues("no_client_certificate").Inc()
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte("client certificate required"))
+		return
+	}
+	if err != nil {
+		klog.Infof("renew-certificates %s client certificate verify err: %v", r.RemoteAddr, err)
+		certificateRenewals.WithLabelValues("verify_failed").Inc()
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte("failed to verify client certificate"))
+		return
+	}
+
+	nodeName, isNode := strings.CutPrefix(clientCert.Subject.CommonName, "system:node:")
+	if !isNode || nodeName == "" || !slices.Contains(clientCert.Subject.Organization, rbac.NodesGroup) {
+		klog.Infof("renew-certificates %s client certificate %q is not a node certificate", r.RemoteAddr, clientCert.Subject.CommonName)
+		certificateRenewals.WithLabelValues("not_a_node").Inc()
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte("not a node certificate"))
+		return
+	}
+
+	ctx := r.Context()
+
+	// Only nodes still part of the cluster can renew their certificates.
+	node := &corev1.Node{}
+	if err := s.uncachedClient.Get(ctx, types.NamespacedName{Name: nodeName}, node); err != nil {
+		klog.Infof("renew-certificates %s error getting node %q: %v", r.RemoteAddr, nodeName, err)
+		certificateRenewals.WithLabelValues("node_lookup_error").Inc()
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte("node not found"))
+		return
+	}
+	if node.DeletionTimestamp != nil {
+		klog.Infof("renew-certificates %s node %q is being deleted", r.RemoteAddr, nodeName)
+		certificateRenewals.WithLabelValues("node_deleted").Inc()
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte("node is being deleted"))
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		klog.Infof("renew-certificates %s read err: %v", r.RemoteAddr, err)
+		certificateRenewals.WithLabelValues("read_error").Inc()
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	req := &nodeup.CertificateRenewalRequest{}
+	if err := json.Unmarshal(body, req); err != nil {
+		klog.Infof("renew-certificates %s decode err: %v", r.RemoteAddr, err)
+		certificateRenewals.WithLabelValues("decode_error").Inc()
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(fmt.Sprintf("failed to decode: %v", err)))
+		return
+	}
+	if req.APIVersion != nodeup.BootstrapAPIVersion {
+		klog.Infof("renew-certificates %s wrong APIVersion", r.RemoteAddr)
+		certificateRenewals.WithLabelValues("wrong_api_version").Inc()
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte("unexpected APIVersion"))
+		return
+	}
+
+	id := &bootstrap.VerifyResult{
+		NodeName:          nodeName,
+		InstanceGroupName: node.Labels[kops.NodeLabelInstanceGroup],
+		Verifier:          certificateRenewalVerifier,
+	}
+	if _, found := req.Certs["kubelet-server"]; found {
+		// The cloud is not asked again who the node is, and the node writes the addresses of its Node status itself,
+		// so the serving certificate of the kubelet keeps the names of the current one.
+		id.CertificateNames, err = s.kubeletServerCertificateNames(req.CurrentCerts["kubelet-server"], nodeName)
+		if err != nil {
+			klog.Infof("renew-certificates %s current kubelet-server certificate err: %v", r.RemoteAddr, err)
+			certificateRenewals.WithLabelValues("invalid_current_certificate").Inc()
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte("invalid current kubelet-server certificate"))
+			return
+		}
+	}
+
+	// Skew the certificate lifetime like when bootstrapping.
+	hash := fnv.New32()
+	_, _ = hash.Write([]byte(nodeName))
+	validHours := (455 * 24) + (hash.Sum32() % (30 * 24))
+
+	resp := &nodeup.CertificateRenewalResponse{
+		Certs: map[string]string{},
+	}
+	for name, pubKey := range req.Certs {
+		cert, err := s.issueCert(ctx, name, pubKey, id, validHours, nil)
+		if err != nil {
+			klog.Infof("renew-certificates %s cert %q issue err: %v", r.RemoteAddr, name, err)
+			certificateRenewals.WithLabelValues("issue_error").Inc()
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(fmt.Sprintf("failed to issue %q: %v", name, err)))
+			return
+		}
+		certString, err := cert.AsString()
+		if err != nil {
+			klog.Infof("renew-certificates %s cert %q encode err: %v", r.RemoteAddr, name, err)
+			certificateRenewals.WithLabelValues("issue_error").Inc()
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = w.Write([]byte("internal error"))
+			return
+		}
+		resp.Certs[name] = certString
+		auditIssuedCertificate("audit: renewed node certificate", r, id, name, cert)
+		renewedCertificates.WithLabelValues(name).Inc()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+	certificateRenewals.WithLabelValues("success").Inc()
+	klog.Infof("renew-certificates %s %s success", r.RemoteAddr, nodeName)
+}
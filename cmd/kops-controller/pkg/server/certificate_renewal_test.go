@@ -0,0 +1,106 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// issueServerCert issues a serving certificate with the given common name and names, encoded as PEM.
+func (ca *testCA) issueServerCert(t *testing.T, commonName string, dnsNames []string, ips []net.IP) string {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("error generating key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(3),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		DNSNames:     dnsNames,
+		IPAddresses:  ips,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, key.Public(), ca.key)
+	if err != nil {
+		t.Fatalf("error creating server certificate: %v", err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+}
+
+func TestKubeletServerCertificateNames(t *testing.T) {
+	ca := newTestCA(t)
+	otherCA := newTestCA(t)
+	dnsNames := []string{"node-a.ec2.internal"}
+	ips := []net.IP{net.ParseIP("10.0.0.1")}
+
+	grid := []struct {
+		name     string
+		current  string
+		expected []string
+	}{
+		{
+			name:     "current certificate",
+			current:  ca.issueServerCert(t, "node-a", dnsNames, ips),
+			expected: []string{"node-a.ec2.internal", "10.0.0.1"},
+		},
+		{
+			name: "no current certificate",
+		},
+		{
+			name:    "certificate of another CA",
+			current: otherCA.issueServerCert(t, "node-a", dnsNames, ips),
+		},
+		{
+			name:    "certificate of another node",
+			current: ca.issueServerCert(t, "node-b", dnsNames, ips),
+		},
+	}
+	for _, g := range grid {
+		t.Run(g.name, func(t *testing.T) {
+			s := &Server{
+				clientCAs: ca.pool(),
+			}
+			names, err := s.kubeletServerCertificateNames(g.current, "node-a")
+			if g.expected == nil {
+				if err == nil {
+					t.Errorf("expected error, got names %v", names)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(g.expected, names); diff != "" {
+				t.Errorf("unexpected names; diff=%s", diff)
+			}
+		})
+	}
+}
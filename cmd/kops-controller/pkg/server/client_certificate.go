@@ -0,0 +1,66 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+
+	"k8s.io/kops/upup/pkg/fi"
+)
+
+// newClientCAPool returns the CAs that signed the client certificates presented to kops-controller.
+func newClientCAPool(basePath string) (*x509.CertPool, error) {
+	p := path.Join(basePath, fi.CertificateIDCA+".crt")
+	certBytes, err := os.ReadFile(p)
+	if err != nil {
+		return nil, fmt.Errorf("reading %q: %w", p, err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(certBytes) {
+		return nil, fmt.Errorf("no certificates found in %q", p)
+	}
+	return pool, nil
+}
+
+// errNoClientCertificate is returned by verifyClientCertificate for requests without a client certificate.
+var errNoClientCertificate = errors.New("no client certificate")
+
+// verifyClientCertificate returns the client certificate of a request, once verified against the cluster CA.
+func (s *Server) verifyClientCertificate(r *http.Request) (*x509.Certificate, error) {
+	if r.TLS == nil || len(r.TLS.PeerCertificates) == 0 {
+		return nil, errNoClientCertificate
+	}
+
+	clientCert := r.TLS.PeerCertificates[0]
+	intermediates := x509.NewCertPool()
+	for _, cert := range r.TLS.PeerCertificates[1:] {
+		intermediates.AddCert(cert)
+	}
+	if _, err := clientCert.Verify(x509.VerifyOptions{
+		Roots:         s.clientCAs,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}); err != nil {
+		return nil, err
+	}
+	return clientCert, nil
+}
@@ -0,0 +1,64 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	// bootstrapRequests counts the bootstrap requests, by result: success or the reason of the failure.
+	bootstrapRequests = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kops_controller_bootstrap_requests_total",
+			Help: "Number of node bootstrap requests, by result: success or the reason of the failure.",
+		},
+		[]string{"result"},
+	)
+
+	// bootstrapCertificates counts the certificates issued to bootstrapping nodes, by certificate name.
+	bootstrapCertificates = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kops_controller_bootstrap_certificates_issued_total",
+			Help: "Number of certificates issued to bootstrapping nodes, by certificate name.",
+		},
+		[]string{"certificate"},
+	)
+
+	// certificateRenewals counts the certificate renewal requests, by result: success or the reason of the failure.
+	certificateRenewals = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kops_controller_certificate_renewal_requests_total",
+			Help: "Number of node certificate renewal requests, by result: success or the reason of the failure.",
+		},
+		[]string{"result"},
+	)
+
+	// renewedCertificates counts the certificates renewed for nodes, by certificate name.
+	renewedCertificates = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kops_controller_renewed_certificates_issued_total",
+			Help: "Number of certificates renewed for nodes, by certificate name.",
+		},
+		[]string{"certificate"},
+	)
+)
+
+func init() {
+	metrics.Registry.MustRegister(bootstrapRequests, bootstrapCertificates, certificateRenewals, renewedCertificates)
+}
@@ -23,27 +23,18 @@ import (
 	"k8s.io/klog/v2"
 	"k8s.io/kops/pkg/apis/nodeup"
 	"k8s.io/kops/pkg/bootstrap"
+	"k8s.io/kops/upup/pkg/fi/utils"
 )
 
-func (s *Server) getNodeConfig(ctx context.Context, req *nodeup.BootstrapRequest, identity *bootstrap.VerifyResult) (*nodeup.NodeConfig, error) {
-	klog.Infof("getting node config for %+v", req)
-
-	instanceGroupName := identity.InstanceGroupName
-	if instanceGroupName == "" {
-		return nil, fmt.Errorf("did not find InstanceGroup for node %q", identity.NodeName)
-	}
+func (s *Server) getNodeConfig(ctx context.Context, identity *bootstrap.VerifyResult) (*nodeup.NodeConfig, error) {
+	klog.Infof("getting node config for %q", identity.NodeName)
 
 	nodeConfig := &nodeup.NodeConfig{}
 
-	// Note: For now, we're assuming there is only a single cluster, and it is ours.
-	// We therefore use the configured base path
-
 	{
-		p := s.configBase.Join("igconfig", "node", instanceGroupName, "nodeupconfig.yaml")
-
-		b, err := p.ReadFile(ctx)
+		b, err := s.readNodeupConfig(ctx, identity)
 		if err != nil {
-			return nil, fmt.Errorf("error loading NodeupConfig %q: %v", p, err)
+			return nil, err
 		}
 		nodeConfig.NodeupConfig = string(b)
 	}
@@ -52,6 +43,13 @@ func (s *Server) getNodeConfig(ctx context.Context, req *nodeup.BootstrapRequest
 		secretIDs := []string{
 			"dockerconfig",
 		}
+
+		// Nodes also need the secrets of the containerd registries they are configured to use
+		var nodeupConfig nodeup.Config
+		if err := utils.YamlUnmarshal([]byte(nodeConfig.NodeupConfig), &nodeupConfig); err != nil {
+			return nil, fmt.Errorf("error parsing NodeupConfig: %w", err)
+		}
+		secretIDs = append(secretIDs, nodeupConfig.ContainerdRegistrySecretIDs()...)
 		nodeConfig.NodeSecrets = make(map[string][]byte)
 		for _, id := range secretIDs {
 			secret, err := s.secretStore.FindSecret(id)
@@ -66,3 +64,21 @@ func (s *Server) getNodeConfig(ctx context.Context, req *nodeup.BootstrapRequest
 
 	return nodeConfig, nil
 }
+
+// readNodeupConfig reads the NodeupConfig of the node's instance group.
+func (s *Server) readNodeupConfig(ctx context.Context, identity *bootstrap.VerifyResult) ([]byte, error) {
+	instanceGroupName := identity.InstanceGroupName
+	if instanceGroupName == "" {
+		return nil, fmt.Errorf("did not find InstanceGroup for node %q", identity.NodeName)
+	}
+
+	// Note: For now, we're assuming there is only a single cluster, and it is ours.
+	// We therefore use the configured base path
+	p := s.configBase.Join("igconfig", "node", instanceGroupName, "nodeupconfig.yaml")
+
+	b, err := p.ReadFile(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error loading NodeupConfig %q: %v", p, err)
+	}
+	return b, nil
+}
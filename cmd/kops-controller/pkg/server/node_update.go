@@ -0,0 +1,412 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/klog/v2"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/apis/kops/model"
+	"k8s.io/kops/pkg/apis/nodeup"
+	"k8s.io/kops/pkg/bootstrap"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kubectl/pkg/drain"
+)
+
+const (
+	// nodeUpdateLeaseName is the name of the Lease held by the node that is drained for an in-place update.
+	nodeUpdateLeaseName = "kops-node-update"
+	// nodeUpdateLeaseDuration is how long the lease is held without being renewed.
+	// Nodes renew it with each drain request, and must apply their configuration and report within this duration.
+	nodeUpdateLeaseDuration = 15 * time.Minute
+)
+
+// drainRequestTimeout is how long a drain request waits for the evicted pods to terminate.
+// It is shorter than the client timeout; nodes repeat the request until they are drained.
+const drainRequestTimeout = 10 * time.Second
+
+// nodeUpdate serves the nodes that update their configuration in place.
+func (s *Server) nodeUpdate(w http.ResponseWriter, r *http.Request) {
+	if r.Body == nil {
+		klog.Infof("node-update %s no body", r.RemoteAddr)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		klog.Infof("node-update %s read err: %v", r.RemoteAddr, err)
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(fmt.Sprintf("node-update %s failed to read body: %v", r.RemoteAddr, err)))
+		return
+	}
+
+	ctx := r.Context()
+
+	id, err := s.verifier.VerifyToken(ctx, r, r.Header.Get("Authorization"), body)
+	if err != nil {
+		klog.Infof("node-update %s verify err: %v", r.RemoteAddr, err)
+		w.WriteHeader(http.StatusForbidden)
+		// don't return the error; this allows us to have richer errors without security implications
+		_, _ = w.Write([]byte("failed to verify token"))
+		return
+	}
+
+	req := &nodeup.NodeUpdateRequest{}
+	if err := json.Unmarshal(body, req); err != nil {
+		klog.Infof("node-update %s decode err: %v", r.RemoteAddr, err)
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(fmt.Sprintf("failed to decode: %v", err)))
+		return
+	}
+
+	if req.APIVersion != nodeup.BootstrapAPIVersion {
+		klog.Infof("node-update %s wrong APIVersion", r.RemoteAddr)
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte("unexpected APIVersion"))
+		return
+	}
+
+	if model.UseChallengeCallback(kops.CloudProviderID(s.opt.Cloud)) {
+		if err := s.challengeClient.DoCallbackChallenge(ctx, s.opt.ClusterName, id.ChallengeEndpoint, req.Challenge); err != nil {
+			klog.Infof("node-update %s callback challenge failed: %v", r.RemoteAddr, err)
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte("callback failed"))
+			return
+		}
+	}
+
+	if !s.nodeUpdateActionAllowed(req.Action) {
+		klog.Infof("node-update %s action %q requires in-place updates", r.RemoteAddr, req.Action)
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte("in-place updates are not enabled"))
+		return
+	}
+
+	resp := &nodeup.NodeUpdateResponse{}
+
+	switch req.Action {
+	case nodeup.NodeUpdateActionGetConfig:
+		err = s.getNodeUpdateConfig(ctx, req, id, resp)
+	case nodeup.NodeUpdateActionDrain:
+		resp.Drained, err = s.drainNode(ctx, id.NodeName)
+	case nodeup.NodeUpdateActionReport:
+		err = s.reportNodeUpdate(ctx, id.NodeName, req.Status)
+	default:
+		klog.Infof("node-update %s unknown action %q", r.RemoteAddr, req.Action)
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte("unknown action"))
+		return
+	}
+	if err != nil {
+		klog.Infof("node-update %s %s %s failed: %v", r.RemoteAddr, id.NodeName, req.Action, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte(fmt.Sprintf("failed to run %s", req.Action)))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+	klog.V(2).Infof("node-update %s %s %s success", r.RemoteAddr, id.NodeName, req.Action)
+}
+
+// nodeUpdateActionAllowed returns true if the action may be requested with the current configuration.
+// The endpoint can be enabled for other features that only read the node configuration,
+// so the actions that drain and update nodes are only allowed when in-place updates are enabled.
+func (s *Server) nodeUpdateActionAllowed(action nodeup.NodeUpdateAction) bool {
+	if action == nodeup.NodeUpdateActionGetConfig {
+		return true
+	}
+	return s.opt.Server.InPlaceUpdates
+}
+
+// getNodeUpdateConfig returns the hash of the node configuration, and the configuration itself if requested.
+func (s *Server) getNodeUpdateConfig(ctx context.Context, req *nodeup.NodeUpdateRequest, identity *bootstrap.VerifyResult, resp *nodeup.NodeUpdateResponse) error {
+	var nodeupConfig string
+	if req.IncludeNodeConfig {
+		nodeConfig, err := s.getNodeConfig(ctx, identity)
+		if err != nil {
+			return err
+		}
+		nodeupConfig = nodeConfig.NodeupConfig
+		resp.NodeConfig = nodeConfig
+	} else {
+		b, err := s.readNodeupConfig(ctx, identity)
+		if err != nil {
+			return err
+		}
+		nodeupConfig = string(b)
+	}
+
+	sum256 := sha256.Sum256([]byte(nodeupConfig))
+	resp.NodeupConfigHash = base64.StdEncoding.EncodeToString(sum256[:])
+	return nil
+}
+
+// drainNode cordons the node and evicts its pods, returning true once there are no pods left to evict.
+// Only one node is drained at a time: the node must hold the node update lease, which it keeps until it
+// reports the result of its update, or until the lease expires.
+func (s *Server) drainNode(ctx context.Context, nodeName string) (bool, error) {
+	node, err := s.kubeClient.CoreV1().Nodes().Get(ctx, nodeName, metav1.GetOptions{})
+	if err != nil {
+		return false, fmt.Errorf("error getting node %q: %w", nodeName, err)
+	}
+
+	acquired, err := s.acquireNodeUpdateLease(ctx, nodeName)
+	if err != nil {
+		return false, err
+	}
+	if !acquired {
+		return false, nil
+	}
+
+	if err := s.setNodeUpdateCondition(ctx, node, corev1.ConditionFalse, nodeup.NodeUpdateReasonDraining, "draining node for configuration update"); err != nil {
+		return false, err
+	}
+
+	helper := &drain.Helper{
+		Ctx:                 ctx,
+		Client:              s.kubeClient,
+		Force:               true,
+		GracePeriodSeconds:  -1,
+		IgnoreAllDaemonSets: true,
+		Out:                 os.Stdout,
+		ErrOut:              os.Stderr,
+		Timeout:             drainRequestTimeout,
+
+		// We want to proceed even when pods are using emptyDir volumes
+		DeleteEmptyDirData: true,
+	}
+
+	if err := drain.RunCordonOrUncordon(helper, node, true); err != nil {
+		return false, fmt.Errorf("error cordoning node: %w", err)
+	}
+
+	list, errs := helper.GetPodsForDeletion(nodeName)
+	if len(errs) > 0 {
+		return false, utilerrors.NewAggregate(errs)
+	}
+	if len(list.Pods()) == 0 {
+		return true, nil
+	}
+
+	if err := helper.DeleteOrEvictPods(list.Pods()); err != nil {
+		klog.Infof("node %q is not drained yet: %v", nodeName, err)
+		return false, nil
+	}
+	return true, nil
+}
+
+// reportNodeUpdate records the result of an in-place update in a condition of the Node object,
+// uncordons the node if requested, and releases the node update lease.
+func (s *Server) reportNodeUpdate(ctx context.Context, nodeName string, status *nodeup.NodeUpdateStatus) error {
+	if status == nil {
+		return fmt.Errorf("status not set")
+	}
+
+	node, err := s.kubeClient.CoreV1().Nodes().Get(ctx, nodeName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("error getting node %q: %w", nodeName, err)
+	}
+
+	conditionStatus := corev1.ConditionTrue
+	reason := nodeup.NodeUpdateReasonSucceeded
+	if !status.Succeeded {
+		conditionStatus = corev1.ConditionFalse
+		reason = nodeup.NodeUpdateReasonFailed
+	}
+	klog.Infof("node %q in-place update %s: %s", nodeName, reason, status.Message)
+	if err := s.setNodeUpdateCondition(ctx, node, conditionStatus, reason, status.Message); err != nil {
+		return err
+	}
+
+	patch := map[string]interface{}{}
+	if status.Succeeded {
+		patch["metadata"] = map[string]interface{}{
+			"annotations": map[string]string{
+				nodeup.NodeupConfigHashAnnotation: status.NodeupConfigHash,
+			},
+		}
+	}
+	if status.Uncordon {
+		patch["spec"] = map[string]interface{}{
+			"unschedulable": nil,
+		}
+	}
+	if len(patch) != 0 {
+		if err := s.patchNode(ctx, nodeName, patch); err != nil {
+			return err
+		}
+	}
+
+	return s.releaseNodeUpdateLease(ctx, nodeName)
+}
+
+// setNodeUpdateCondition sets the in-place update condition in the status of the Node object.
+func (s *Server) setNodeUpdateCondition(ctx context.Context, node *corev1.Node, status corev1.ConditionStatus, reason, message string) error {
+	now := metav1.Now()
+	condition := corev1.NodeCondition{
+		Type:               nodeup.NodeUpdateConditionType,
+		Status:             status,
+		LastHeartbeatTime:  now,
+		LastTransitionTime: now,
+		Reason:             reason,
+		Message:            message,
+	}
+	for _, existing := range node.Status.Conditions {
+		if existing.Type != condition.Type {
+			continue
+		}
+		if existing.Status == condition.Status && existing.Reason == condition.Reason && existing.Message == condition.Message {
+			return nil
+		}
+		if existing.Status == condition.Status {
+			condition.LastTransitionTime = existing.LastTransitionTime
+		}
+	}
+
+	patchBytes, err := json.Marshal(map[string]interface{}{
+		"status": map[string]interface{}{
+			"conditions": []corev1.NodeCondition{condition},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("error building patch: %w", err)
+	}
+	if _, err := s.kubeClient.CoreV1().Nodes().PatchStatus(ctx, node.Name, patchBytes); err != nil {
+		return fmt.Errorf("error patching node status: %w", err)
+	}
+	return nil
+}
+
+// patchNode applies a merge patch to the Node object.
+func (s *Server) patchNode(ctx context.Context, nodeName string, patch map[string]interface{}) error {
+	patchBytes, err := json.Marshal(patch)
+	if err != nil {
+		return fmt.Errorf("error building patch: %w", err)
+	}
+
+	if _, err := s.kubeClient.CoreV1().Nodes().Patch(ctx, nodeName, types.MergePatchType, patchBytes, metav1.PatchOptions{}); err != nil {
+		return fmt.Errorf("error patching node: %w", err)
+	}
+	return nil
+}
+
+// acquireNodeUpdateLease acquires or renews the lease serializing the drains of in-place updates, returning true if
+// the node holds it. A lease held by another node is taken over once it expires, or if that node no longer exists,
+// so that a node that fails in the middle of its update does not block the other nodes.
+func (s *Server) acquireNodeUpdateLease(ctx context.Context, nodeName string) (bool, error) {
+	leases := s.kubeClient.CoordinationV1().Leases(metav1.NamespaceSystem)
+	now := metav1.NewMicroTime(time.Now())
+
+	lease, err := leases.Get(ctx, nodeUpdateLeaseName, metav1.GetOptions{})
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return false, fmt.Errorf("error getting lease %q: %w", nodeUpdateLeaseName, err)
+		}
+		lease = &coordinationv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      nodeUpdateLeaseName,
+				Namespace: metav1.NamespaceSystem,
+			},
+			Spec: coordinationv1.LeaseSpec{
+				HolderIdentity:       &nodeName,
+				LeaseDurationSeconds: fi.PtrTo(int32(nodeUpdateLeaseDuration.Seconds())),
+				AcquireTime:          &now,
+				RenewTime:            &now,
+			},
+		}
+		if _, err := leases.Create(ctx, lease, metav1.CreateOptions{}); err != nil {
+			if apierrors.IsAlreadyExists(err) {
+				return false, nil
+			}
+			return false, fmt.Errorf("error creating lease %q: %w", nodeUpdateLeaseName, err)
+		}
+		klog.Infof("node %q acquired the node update lease", nodeName)
+		return true, nil
+	}
+
+	holder := fi.ValueOf(lease.Spec.HolderIdentity)
+	if holder != "" && holder != nodeName {
+		expired := lease.Spec.RenewTime == nil || lease.Spec.RenewTime.Add(nodeUpdateLeaseDuration).Before(now.Time)
+		if !expired {
+			_, err := s.kubeClient.CoreV1().Nodes().Get(ctx, holder, metav1.GetOptions{})
+			if err == nil {
+				klog.Infof("node %q waits for node %q to be updated", nodeName, holder)
+				return false, nil
+			}
+			if !apierrors.IsNotFound(err) {
+				return false, fmt.Errorf("error getting node %q: %w", holder, err)
+			}
+		}
+		klog.Warningf("node %q takes over the node update lease from node %q, which expired or no longer exists", nodeName, holder)
+	}
+
+	if holder != nodeName {
+		lease.Spec.HolderIdentity = &nodeName
+		lease.Spec.AcquireTime = &now
+	}
+	lease.Spec.LeaseDurationSeconds = fi.PtrTo(int32(nodeUpdateLeaseDuration.Seconds()))
+	lease.Spec.RenewTime = &now
+	if _, err := leases.Update(ctx, lease, metav1.UpdateOptions{}); err != nil {
+		if apierrors.IsConflict(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("error updating lease %q: %w", nodeUpdateLeaseName, err)
+	}
+	return true, nil
+}
+
+// releaseNodeUpdateLease releases the node update lease if it is held by the node.
+func (s *Server) releaseNodeUpdateLease(ctx context.Context, nodeName string) error {
+	leases := s.kubeClient.CoordinationV1().Leases(metav1.NamespaceSystem)
+
+	lease, err := leases.Get(ctx, nodeUpdateLeaseName, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("error getting lease %q: %w", nodeUpdateLeaseName, err)
+	}
+	if fi.ValueOf(lease.Spec.HolderIdentity) != nodeName {
+		return nil
+	}
+
+	lease.Spec.HolderIdentity = nil
+	lease.Spec.AcquireTime = nil
+	lease.Spec.RenewTime = nil
+	if _, err := leases.Update(ctx, lease, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("error releasing lease %q: %w", nodeUpdateLeaseName, err)
+	}
+	klog.Infof("node %q released the node update lease", nodeName)
+	return nil
+}
@@ -0,0 +1,194 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/kops/cmd/kops-controller/pkg/config"
+	"k8s.io/kops/pkg/apis/nodeup"
+	"k8s.io/kops/upup/pkg/fi"
+)
+
+func newNodeUpdateTestServer(objects ...runtime.Object) *Server {
+	return &Server{
+		opt: &config.Options{
+			Server: &config.ServerOptions{
+				InPlaceUpdates: true,
+			},
+		},
+		kubeClient: fake.NewSimpleClientset(objects...),
+	}
+}
+
+func testNode(name string) *corev1.Node {
+	return &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name}}
+}
+
+func testNodeUpdateLease(holder string, renewTime time.Time) *coordinationv1.Lease {
+	return &coordinationv1.Lease{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      nodeUpdateLeaseName,
+			Namespace: metav1.NamespaceSystem,
+		},
+		Spec: coordinationv1.LeaseSpec{
+			HolderIdentity: fi.PtrTo(holder),
+			RenewTime:      &metav1.MicroTime{Time: renewTime},
+		},
+	}
+}
+
+func TestAcquireNodeUpdateLease(t *testing.T) {
+	grid := []struct {
+		name     string
+		objects  []runtime.Object
+		acquired bool
+	}{
+		{
+			name:     "no lease",
+			objects:  []runtime.Object{testNode("node-a")},
+			acquired: true,
+		},
+		{
+			name:     "held by node",
+			objects:  []runtime.Object{testNode("node-a"), testNodeUpdateLease("node-a", time.Now())},
+			acquired: true,
+		},
+		{
+			name:     "released",
+			objects:  []runtime.Object{testNode("node-a"), testNodeUpdateLease("", time.Now())},
+			acquired: true,
+		},
+		{
+			name:     "held by other node",
+			objects:  []runtime.Object{testNode("node-a"), testNode("node-b"), testNodeUpdateLease("node-b", time.Now())},
+			acquired: false,
+		},
+		{
+			name:     "expired",
+			objects:  []runtime.Object{testNode("node-a"), testNode("node-b"), testNodeUpdateLease("node-b", time.Now().Add(-2*nodeUpdateLeaseDuration))},
+			acquired: true,
+		},
+		{
+			name:     "holder deleted",
+			objects:  []runtime.Object{testNode("node-a"), testNodeUpdateLease("node-b", time.Now())},
+			acquired: true,
+		},
+	}
+
+	for _, g := range grid {
+		t.Run(g.name, func(t *testing.T) {
+			ctx := context.Background()
+			s := newNodeUpdateTestServer(g.objects...)
+
+			acquired, err := s.acquireNodeUpdateLease(ctx, "node-a")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if acquired != g.acquired {
+				t.Fatalf("expected acquired=%v, got %v", g.acquired, acquired)
+			}
+
+			lease, err := s.kubeClient.CoordinationV1().Leases(metav1.NamespaceSystem).Get(ctx, nodeUpdateLeaseName, metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("error getting lease: %v", err)
+			}
+			holder := fi.ValueOf(lease.Spec.HolderIdentity)
+			if g.acquired && holder != "node-a" {
+				t.Errorf("expected lease to be held by node-a, got %q", holder)
+			}
+			if !g.acquired && holder == "node-a" {
+				t.Errorf("expected lease not to be held by node-a")
+			}
+		})
+	}
+}
+
+func TestReportNodeUpdate(t *testing.T) {
+	ctx := context.Background()
+	node := testNode("node-a")
+	node.Spec.Unschedulable = true
+	s := newNodeUpdateTestServer(node, testNodeUpdateLease("node-a", time.Now()))
+
+	if err := s.reportNodeUpdate(ctx, "node-a", &nodeup.NodeUpdateStatus{
+		NodeupConfigHash: "hash",
+		Succeeded:        true,
+		Message:          "updated kubelet",
+		Uncordon:         true,
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	node, err := s.kubeClient.CoreV1().Nodes().Get(ctx, "node-a", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("error getting node: %v", err)
+	}
+	var condition *corev1.NodeCondition
+	for i := range node.Status.Conditions {
+		if node.Status.Conditions[i].Type == nodeup.NodeUpdateConditionType {
+			condition = &node.Status.Conditions[i]
+		}
+	}
+	if condition == nil {
+		t.Fatalf("expected node condition %q to be set", nodeup.NodeUpdateConditionType)
+	}
+	if condition.Status != corev1.ConditionTrue || condition.Reason != nodeup.NodeUpdateReasonSucceeded || condition.Message != "updated kubelet" {
+		t.Errorf("unexpected node condition: %+v", condition)
+	}
+	if node.Annotations[nodeup.NodeupConfigHashAnnotation] != "hash" {
+		t.Errorf("expected config hash annotation to be set, got %v", node.Annotations)
+	}
+	if node.Spec.Unschedulable {
+		t.Errorf("expected node to be uncordoned")
+	}
+
+	lease, err := s.kubeClient.CoordinationV1().Leases(metav1.NamespaceSystem).Get(ctx, nodeUpdateLeaseName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("error getting lease: %v", err)
+	}
+	if lease.Spec.HolderIdentity != nil {
+		t.Errorf("expected lease to be released, held by %q", *lease.Spec.HolderIdentity)
+	}
+}
+
+func TestNodeUpdateActionAllowed(t *testing.T) {
+	s := &Server{
+		opt: &config.Options{
+			Server: &config.ServerOptions{},
+		},
+	}
+	for _, action := range []nodeup.NodeUpdateAction{nodeup.NodeUpdateActionDrain, nodeup.NodeUpdateActionReport} {
+		if s.nodeUpdateActionAllowed(action) {
+			t.Errorf("expected action %q not to be allowed without in-place updates", action)
+		}
+	}
+	if !s.nodeUpdateActionAllowed(nodeup.NodeUpdateActionGetConfig) {
+		t.Errorf("expected action %q to be allowed", nodeup.NodeUpdateActionGetConfig)
+	}
+
+	s.opt.Server.InPlaceUpdates = true
+	if !s.nodeUpdateActionAllowed(nodeup.NodeUpdateActionDrain) {
+		t.Errorf("expected action %q to be allowed with in-place updates", nodeup.NodeUpdateActionDrain)
+	}
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"net"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+	"k8s.io/kops/cmd/kops-controller/pkg/config"
+)
+
+const (
+	// sourceIdleTimeout is how long the limiter of a source is kept after its last request.
+	sourceIdleTimeout = 10 * time.Minute
+)
+
+// defaultBootstrapRateLimit lets a node retry its bootstrap a few times,
+// and many nodes join at once when scaling up.
+var defaultBootstrapRateLimit = config.RateLimitOptions{
+	PerSourceQPS:   0.1,
+	PerSourceBurst: 5,
+	GlobalQPS:      20,
+	GlobalBurst:    100,
+}
+
+// rateLimiter limits the rate of requests, per source address and globally.
+type rateLimiter struct {
+	global *rate.Limiter
+
+	perSourceLimit rate.Limit
+	perSourceBurst int
+
+	mutex     sync.Mutex
+	sources   map[string]*sourceLimiter
+	lastSweep time.Time
+}
+
+type sourceLimiter struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+func newRateLimiter(opt *config.RateLimitOptions) *rateLimiter {
+	return &rateLimiter{
+		global:         rate.NewLimiter(rate.Limit(opt.GlobalQPS), opt.GlobalBurst),
+		perSourceLimit: rate.Limit(opt.PerSourceQPS),
+		perSourceBurst: opt.PerSourceBurst,
+		sources:        make(map[string]*sourceLimiter),
+	}
+}
+
+// allow returns whether a request from remoteAddr is allowed, and else the reason why not.
+func (l *rateLimiter) allow(remoteAddr string, now time.Time) (bool, string) {
+	source, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		source = remoteAddr
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if now.Sub(l.lastSweep) > sourceIdleTimeout {
+		for k, s := range l.sources {
+			if now.Sub(s.lastSeen) > sourceIdleTimeout {
+				delete(l.sources, k)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	s := l.sources[source]
+	if s == nil {
+		s = &sourceLimiter{limiter: rate.NewLimiter(l.perSourceLimit, l.perSourceBurst)}
+		l.sources[source] = s
+	}
+	s.lastSeen = now
+
+	// A limited source must not use up the global budget, so it is checked first.
+	if !s.limiter.AllowN(now, 1) {
+		return false, "source_rate_limited"
+	}
+	if !l.global.AllowN(now, 1) {
+		return false, "global_rate_limited"
+	}
+	return true, ""
+}
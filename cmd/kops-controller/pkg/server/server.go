@@ -28,12 +28,14 @@ import (
 	"io"
 	"net/http"
 	"runtime/debug"
+	"strings"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/klog/v2"
 	"k8s.io/kops/cmd/kops-controller/pkg/config"
 	"k8s.io/kops/pkg/apis/kops"
@@ -64,13 +66,26 @@ type Server struct {
 	// uncachedClient is an uncached client for the kube apiserver
 	uncachedClient client.Client
 
+	// kubeClient is a client for the kube apiserver, used to drain and report on nodes that update in place
+	kubeClient kubernetes.Interface
+
 	// challengeClient performs our callback-challenge into the node
 	challengeClient *bootstrap.ChallengeClient
+
+	// adminCredentials caches the records of admin credentials, for the admin credential deny list
+	adminCredentials adminCredentialCache
+
+	// bootstrapRateLimiter limits the rate of bootstrap and certificate renewal requests
+	bootstrapRateLimiter *rateLimiter
+
+	// clientCAs are the CAs of the client certificates nodes renew their certificates with,
+	// and kube-apiserver reviews the requests of admin credentials with
+	clientCAs *x509.CertPool
 }
 
 var _ manager.LeaderElectionRunnable = &Server{}
 
-func NewServer(vfsContext *vfs.VFSContext, opt *config.Options, verifier bootstrap.Verifier, uncachedClient client.Client) (*Server, error) {
+func NewServer(vfsContext *vfs.VFSContext, opt *config.Options, verifier bootstrap.Verifier, uncachedClient client.Client, kubeClient kubernetes.Interface) (*Server, error) {
 	server := &http.Server{
 		Addr: opt.Server.Listen,
 		TLSConfig: &tls.Config{
@@ -84,6 +99,7 @@ func NewServer(vfsContext *vfs.VFSContext, opt *config.Options, verifier bootstr
 		server:         server,
 		verifier:       verifier,
 		uncachedClient: uncachedClient,
+		kubeClient:     kubeClient,
 	}
 
 	configBase, err := vfsContext.BuildVfsPath(opt.ConfigBase)
@@ -109,8 +125,35 @@ func NewServer(vfsContext *vfs.VFSContext, opt *config.Options, verifier bootstr
 	}
 	s.challengeClient = challengeClient
 
+	bootstrapRateLimit := opt.Server.BootstrapRateLimit
+	if bootstrapRateLimit == nil {
+		bootstrapRateLimit = &defaultBootstrapRateLimit
+	}
+	s.bootstrapRateLimiter = newRateLimiter(bootstrapRateLimit)
+
+	if opt.Server.CertificateRenewal || opt.Server.AdminCredentialDenyList {
+		s.clientCAs, err = newClientCAPool(opt.Server.CABasePath)
+		if err != nil {
+			return nil, err
+		}
+		// Client certificates are only verified by the endpoints that need them; other clients don't present one
+		server.TLSConfig.ClientAuth = tls.RequestClientCert
+	}
+
 	r := http.NewServeMux()
 	r.Handle("/bootstrap", http.HandlerFunc(s.bootstrap))
+	if opt.Server.InPlaceUpdates || opt.Server.NodeAudit {
+		r.Handle("/node-update", http.HandlerFunc(s.nodeUpdate))
+	}
+	if opt.Server.VolumeEncryptionKeys {
+		r.Handle("/volume-key", http.HandlerFunc(s.volumeKey))
+	}
+	if opt.Server.AdminCredentialDenyList {
+		r.Handle("/admin-credential-review", http.HandlerFunc(s.adminCredentialReview))
+	}
+	if opt.Server.CertificateRenewal {
+		r.Handle("/renew-certificates", http.HandlerFunc(s.renewCertificates))
+	}
 	server.Handler = recovery(r)
 
 	return s, nil
@@ -141,8 +184,17 @@ func (s *Server) Start(ctx context.Context) error {
 }
 
 func (s *Server) bootstrap(w http.ResponseWriter, r *http.Request) {
+	if ok, reason := s.bootstrapRateLimiter.allow(r.RemoteAddr, time.Now()); !ok {
+		klog.Infof("bootstrap %s %s", r.RemoteAddr, reason)
+		bootstrapRequests.WithLabelValues(reason).Inc()
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte("too many requests"))
+		return
+	}
+
 	if r.Body == nil {
 		klog.Infof("bootstrap %s no body", r.RemoteAddr)
+		bootstrapRequests.WithLabelValues("no_body").Inc()
 		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
@@ -150,6 +202,7 @@ func (s *Server) bootstrap(w http.ResponseWriter, r *http.Request) {
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
 		klog.Infof("bootstrap %s read err: %v", r.RemoteAddr, err)
+		bootstrapRequests.WithLabelValues("read_error").Inc()
 		w.WriteHeader(http.StatusBadRequest)
 		_, _ = w.Write([]byte(fmt.Sprintf("bootstrap %s failed to read body: %v", r.RemoteAddr, err)))
 		return
@@ -163,9 +216,11 @@ func (s *Server) bootstrap(w http.ResponseWriter, r *http.Request) {
 		if err == bootstrap.ErrAlreadyExists {
 			w.WriteHeader(http.StatusConflict)
 			klog.Infof("%s: %v", r.RemoteAddr, err)
+			bootstrapRequests.WithLabelValues("already_exists").Inc()
 			return
 		}
 		klog.Infof("bootstrap %s verify err: %v", r.RemoteAddr, err)
+		bootstrapRequests.WithLabelValues("verify_failed").Inc()
 		w.WriteHeader(http.StatusForbidden)
 		// don't return the error; this allows us to have richer errors without security implications
 		_, _ = w.Write([]byte("failed to verify token"))
@@ -180,6 +235,7 @@ func (s *Server) bootstrap(w http.ResponseWriter, r *http.Request) {
 			for _, condition := range node.Status.Conditions {
 				if condition.Type == corev1.NodeReady && condition.Status == corev1.ConditionTrue {
 					klog.Infof("bootstrap %s node %q already exists; denying to avoid node-impersonation attacks", r.RemoteAddr, id.NodeName)
+					bootstrapRequests.WithLabelValues("node_registered").Inc()
 					w.WriteHeader(http.StatusConflict)
 					_, _ = w.Write([]byte("node already registered"))
 					return
@@ -188,6 +244,7 @@ func (s *Server) bootstrap(w http.ResponseWriter, r *http.Request) {
 		}
 		if err != nil && !errors.IsNotFound(err) {
 			klog.Infof("bootstrap %s error querying for node %q: %v", r.RemoteAddr, id.NodeName, err)
+			bootstrapRequests.WithLabelValues("node_lookup_error").Inc()
 			w.WriteHeader(http.StatusInternalServerError)
 			_, _ = w.Write([]byte("internal error"))
 			return
@@ -197,6 +254,7 @@ func (s *Server) bootstrap(w http.ResponseWriter, r *http.Request) {
 	req := &nodeup.BootstrapRequest{}
 	if err := json.Unmarshal(body, req); err != nil {
 		klog.Infof("bootstrap %s decode err: %v", r.RemoteAddr, err)
+		bootstrapRequests.WithLabelValues("decode_error").Inc()
 		w.WriteHeader(http.StatusBadRequest)
 		_, _ = w.Write([]byte(fmt.Sprintf("failed to decode: %v", err)))
 		return
@@ -204,14 +262,16 @@ func (s *Server) bootstrap(w http.ResponseWriter, r *http.Request) {
 
 	if req.APIVersion != nodeup.BootstrapAPIVersion {
 		klog.Infof("bootstrap %s wrong APIVersion", r.RemoteAddr)
+		bootstrapRequests.WithLabelValues("wrong_api_version").Inc()
 		w.WriteHeader(http.StatusBadRequest)
 		_, _ = w.Write([]byte("unexpected APIVersion"))
 		return
 	}
 
 	if model.UseChallengeCallback(kops.CloudProviderID(s.opt.Cloud)) {
-		if err := s.challengeClient.DoCallbackChallenge(ctx, s.opt.ClusterName, id.ChallengeEndpoint, req); err != nil {
+		if err := s.challengeClient.DoCallbackChallenge(ctx, s.opt.ClusterName, id.ChallengeEndpoint, req.Challenge); err != nil {
 			klog.Infof("bootstrap %s callback challenge failed: %v", r.RemoteAddr, err)
+			bootstrapRequests.WithLabelValues("challenge_failed").Inc()
 			w.WriteHeader(http.StatusBadRequest)
 			_, _ = w.Write([]byte("callback failed"))
 			return
@@ -226,9 +286,10 @@ func (s *Server) bootstrap(w http.ResponseWriter, r *http.Request) {
 
 	// Support for nodes that have no access to the state store
 	if req.IncludeNodeConfig {
-		nodeConfig, err := s.getNodeConfig(r.Context(), req, id)
+		nodeConfig, err := s.getNodeConfig(r.Context(), id)
 		if err != nil {
 			klog.Infof("bootstrap failed to build node config: %v", err)
+			bootstrapRequests.WithLabelValues("node_config_error").Inc()
 			w.WriteHeader(http.StatusBadRequest)
 			_, _ = w.Write([]byte("failed to build node config"))
 			return
@@ -243,30 +304,47 @@ func (s *Server) bootstrap(w http.ResponseWriter, r *http.Request) {
 	_, _ = hash.Write([]byte(r.RemoteAddr))
 	validHours := (455 * 24) + (hash.Sum32() % (30 * 24))
 
+	if id.Verifier == "" {
+		id.Verifier = strings.TrimPrefix(fmt.Sprintf("%T", s.verifier), "*")
+	}
+
 	for name, pubKey := range req.Certs {
 		cert, err := s.issueCert(ctx, name, pubKey, id, validHours, req.KeypairIDs)
 		if err != nil {
 			klog.Infof("bootstrap %s cert %q issue err: %v", r.RemoteAddr, name, err)
+			bootstrapRequests.WithLabelValues("issue_error").Inc()
 			w.WriteHeader(http.StatusBadRequest)
 			_, _ = w.Write([]byte(fmt.Sprintf("failed to issue %q: %v", name, err)))
 			return
 		}
-		resp.Certs[name] = cert
+		certString, err := cert.AsString()
+		if err != nil {
+			klog.Infof("bootstrap %s cert %q encode err: %v", r.RemoteAddr, name, err)
+			bootstrapRequests.WithLabelValues("issue_error").Inc()
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = w.Write([]byte("internal error"))
+			return
+		}
+		resp.Certs[name] = certString
+
+		auditIssuedCertificate("audit: issued bootstrap certificate", r, id, name, cert)
+		bootstrapCertificates.WithLabelValues(name).Inc()
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(resp)
+	bootstrapRequests.WithLabelValues("success").Inc()
 	klog.Infof("bootstrap %s %s success", r.RemoteAddr, id.NodeName)
 }
 
-func (s *Server) issueCert(ctx context.Context, name string, pubKey string, id *bootstrap.VerifyResult, validHours uint32, keypairIDs map[string]string) (string, error) {
+func (s *Server) issueCert(ctx context.Context, name string, pubKey string, id *bootstrap.VerifyResult, validHours uint32, keypairIDs map[string]string) (*pki.Certificate, error) {
 	block, _ := pem.Decode([]byte(pubKey))
 	if block.Type != "RSA PUBLIC KEY" {
-		return "", fmt.Errorf("unexpected key type %q", block.Type)
+		return nil, fmt.Errorf("unexpected key type %q", block.Type)
 	}
 	key, err := x509.ParsePKIXPublicKey(block.Bytes)
 	if err != nil {
-		return "", fmt.Errorf("parsing key: %v", err)
+		return nil, fmt.Errorf("parsing key: %v", err)
 	}
 
 	issueReq := &pki.IssueCertRequest{
@@ -277,7 +355,7 @@ func (s *Server) issueCert(ctx context.Context, name string, pubKey string, id *
 	}
 
 	if !s.certNames.Has(name) {
-		return "", fmt.Errorf("key name not enabled")
+		return nil, fmt.Errorf("key name not enabled")
 	}
 	switch name {
 	case "etcd-client-cilium":
@@ -305,22 +383,41 @@ func (s *Server) issueCert(ctx context.Context, name string, pubKey string, id *
 			CommonName: rbac.KubeRouter,
 		}
 	default:
-		return "", fmt.Errorf("unexpected key name")
+		return nil, fmt.Errorf("unexpected key name")
 	}
 
 	// This field was added to the protocol in kOps 1.22.
 	if len(keypairIDs) > 0 {
 		if keypairIDs[issueReq.Signer] != s.keypairIDs[issueReq.Signer] {
-			return "", fmt.Errorf("request's keypair ID %q for %s didn't match server's %q", keypairIDs[issueReq.Signer], issueReq.Signer, s.keypairIDs[issueReq.Signer])
+			return nil, fmt.Errorf("request's keypair ID %q for %s didn't match server's %q", keypairIDs[issueReq.Signer], issueReq.Signer, s.keypairIDs[issueReq.Signer])
 		}
 	}
 
 	cert, _, _, err := pki.IssueCert(ctx, issueReq, s.keystore)
 	if err != nil {
-		return "", fmt.Errorf("issuing certificate: %v", err)
+		return nil, fmt.Errorf("issuing certificate: %v", err)
 	}
 
-	return cert.AsString()
+	return cert, nil
+}
+
+// auditIssuedCertificate logs a structured line for a certificate issued to a node.
+func auditIssuedCertificate(msg string, r *http.Request, id *bootstrap.VerifyResult, name string, cert *pki.Certificate) {
+	var sans []string
+	sans = append(sans, cert.Certificate.DNSNames...)
+	for _, ip := range cert.Certificate.IPAddresses {
+		sans = append(sans, ip.String())
+	}
+	klog.InfoS(msg,
+		"remoteAddr", r.RemoteAddr,
+		"node", id.NodeName,
+		"instanceGroup", id.InstanceGroupName,
+		"verifier", id.Verifier,
+		"certificate", name,
+		"subject", cert.Subject.String(),
+		"sans", sans,
+		"serial", cert.Certificate.SerialNumber.String(),
+		"notAfter", cert.Certificate.NotAfter.UTC().Format(time.RFC3339))
 }
 
 // recovery is responsible for ensuring we don't exit on a panic.
@@ -0,0 +1,118 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"k8s.io/klog/v2"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/apis/kops/model"
+	"k8s.io/kops/pkg/apis/nodeup"
+)
+
+// volumeKey returns the key of a volume encrypted by a node.
+// Keys are derived from a cluster secret and the identity of the node, so they don't need to be stored.
+func (s *Server) volumeKey(w http.ResponseWriter, r *http.Request) {
+	if r.Body == nil {
+		klog.Infof("volume-key %s no body", r.RemoteAddr)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		klog.Infof("volume-key %s read err: %v", r.RemoteAddr, err)
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(fmt.Sprintf("volume-key %s failed to read body: %v", r.RemoteAddr, err)))
+		return
+	}
+
+	ctx := r.Context()
+
+	id, err := s.verifier.VerifyToken(ctx, r, r.Header.Get("Authorization"), body)
+	if err != nil {
+		klog.Infof("volume-key %s verify err: %v", r.RemoteAddr, err)
+		w.WriteHeader(http.StatusForbidden)
+		// don't return the error; this allows us to have richer errors without security implications
+		_, _ = w.Write([]byte("failed to verify token"))
+		return
+	}
+
+	req := &nodeup.VolumeKeyRequest{}
+	if err := json.Unmarshal(body, req); err != nil {
+		klog.Infof("volume-key %s decode err: %v", r.RemoteAddr, err)
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(fmt.Sprintf("failed to decode: %v", err)))
+		return
+	}
+
+	if req.APIVersion != nodeup.BootstrapAPIVersion {
+		klog.Infof("volume-key %s wrong APIVersion", r.RemoteAddr)
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte("unexpected APIVersion"))
+		return
+	}
+
+	if req.Path == "" {
+		klog.Infof("volume-key %s no path", r.RemoteAddr)
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte("path is required"))
+		return
+	}
+
+	if model.UseChallengeCallback(kops.CloudProviderID(s.opt.Cloud)) {
+		if err := s.challengeClient.DoCallbackChallenge(ctx, s.opt.ClusterName, id.ChallengeEndpoint, req.Challenge); err != nil {
+			klog.Infof("volume-key %s callback challenge failed: %v", r.RemoteAddr, err)
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte("callback failed"))
+			return
+		}
+	}
+
+	secret, err := s.secretStore.FindSecret(nodeup.VolumeEncryptionSecretName)
+	if err != nil || secret == nil || len(secret.Data) == 0 {
+		klog.Infof("volume-key %s error loading secret %q: %v", r.RemoteAddr, nodeup.VolumeEncryptionSecretName, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte("internal error"))
+		return
+	}
+
+	resp := &nodeup.VolumeKeyResponse{
+		Key: deriveVolumeKey(secret.Data, s.opt.ClusterName, id.NodeName, req.Path),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+	klog.Infof("volume-key %s returned key of volume %q to node %q", r.RemoteAddr, req.Path, id.NodeName)
+}
+
+// deriveVolumeKey derives the key of a volume from the cluster secret, the node name and the mount path of the volume.
+func deriveVolumeKey(secret []byte, clusterName, nodeName, path string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	for _, s := range []string{clusterName, nodeName, path} {
+		// A separator keeps the inputs unambiguous
+		mac.Write([]byte(s))
+		mac.Write([]byte{0})
+	}
+	return mac.Sum(nil)
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"time"
+
+	"k8s.io/klog/v2"
+	"k8s.io/kops/pkg/etcdbackup"
+	"k8s.io/kops/util/pkg/vfs"
+)
+
+// verifyEtcdBackupJob is the name of the job the outcome of verifications is pushed under.
+const verifyEtcdBackupJob = "kops-etcd-backup-verify"
+
+// runVerifyEtcdBackup verifies the latest backup of an etcd cluster, for the etcd backup verification CronJob.
+func runVerifyEtcdBackup(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("verify-etcd-backup", flag.ExitOnError)
+	backupStore := flags.String("backup-store", "", "Location of the backup store of the etcd cluster")
+	etcdCluster := flags.String("etcd-cluster", "", "Name of the etcd cluster")
+	etcdBinDir := flags.String("etcd-bin-dir", "", "Directory holding the etcd binaries")
+	pushgatewayURL := flags.String("pushgateway-url", "", "URL of the Prometheus Pushgateway to push the outcome to")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *backupStore == "" || *etcdCluster == "" {
+		return fmt.Errorf("--backup-store and --etcd-cluster are required")
+	}
+
+	verification := etcdbackup.Verification{EtcdCluster: *etcdCluster}
+	verification.Result, verification.Err = verifyLatestEtcdBackup(ctx, *backupStore, *etcdBinDir)
+	if verification.Err != nil {
+		klog.Errorf("failed to verify the backup of etcd cluster %s: %v", *etcdCluster, verification.Err)
+	} else {
+		result := verification.Result
+		klog.Infof("verified backup %s of etcd cluster %s: %d keys at revision %d, %d bytes, restored in %v",
+			result.Backup, *etcdCluster, result.Keys, result.Revision, result.Size, result.Duration.Round(time.Second))
+	}
+
+	if *pushgatewayURL != "" {
+		var metrics bytes.Buffer
+		if err := etcdbackup.WriteMetrics(&metrics, []etcdbackup.Verification{verification}, time.Now()); err != nil {
+			return err
+		}
+		if err := etcdbackup.PushMetrics(ctx, *pushgatewayURL, verifyEtcdBackupJob, *etcdCluster, metrics.Bytes()); err != nil {
+			return err
+		}
+	}
+
+	return verification.Err
+}
+
+func verifyLatestEtcdBackup(ctx context.Context, backupStore string, etcdBinDir string) (*etcdbackup.VerifyResult, error) {
+	store, err := etcdbackup.NewStore(vfs.NewVFSContext(), backupStore)
+	if err != nil {
+		return nil, err
+	}
+	backup, err := store.LatestBackup(ctx)
+	if err != nil {
+		return nil, err
+	}
+	verifier := &etcdbackup.Verifier{EtcdBinDir: etcdBinDir}
+	return verifier.Verify(ctx, store, backup)
+}
@@ -357,6 +357,12 @@ func NewCmdCreateCluster(f *util.Factory, out io.Writer) *cobra.Command {
 		return []string{cloudup.AuthorizationFlagAlwaysAllow, cloudup.AuthorizationFlagRBAC}, cobra.ShellCompDirectiveNoFileComp
 	})
 
+	// Authentication
+	cmd.Flags().StringVar(&options.OIDCIssuerURL, "oidc-issuer-url", options.OIDCIssuerURL, "URL of the OpenID issuer authenticating users of the cluster")
+	cmd.Flags().StringVar(&options.OIDCClientID, "oidc-client-id", options.OIDCClientID, "Client ID of the cluster at the OpenID issuer")
+	cmd.Flags().StringVar(&options.OIDCUsernameClaim, "oidc-username-claim", options.OIDCUsernameClaim, "OpenID claim to use as the username")
+	cmd.Flags().StringSliceVar(&options.OIDCGroupsClaims, "oidc-groups-claim", options.OIDCGroupsClaims, "OpenID claims to use as the groups of the user")
+
 	// DNS
 	supportedDnsTypes := []string{"public", "private", "none"}
 	cmd.Flags().StringVar(&options.DNSType, "dns", options.DNSType, "DNS type to use: "+strings.Join(supportedDnsTypes, ", "))
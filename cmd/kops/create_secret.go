@@ -34,8 +34,10 @@ func NewCmdCreateSecret(f *util.Factory, out io.Writer) *cobra.Command {
 
 	// create subcommands
 	cmd.AddCommand(NewCmdCreateSecretCiliumPassword(f, out))
+	cmd.AddCommand(NewCmdCreateSecretContainerdRegistry(f, out))
 	cmd.AddCommand(NewCmdCreateSecretDockerConfig(f, out))
 	cmd.AddCommand(NewCmdCreateSecretEncryptionConfig(f, out))
+	cmd.AddCommand(NewCmdCreateSecretGossip(f, out))
 
 	sshPublicKey := NewCmdCreateSSHPublicKey(f, out)
 	sshPublicKey.Hidden = true
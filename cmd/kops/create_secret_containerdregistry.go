@@ -0,0 +1,188 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/kops/cmd/kops/util"
+	"k8s.io/kops/pkg/apis/nodeup"
+	"k8s.io/kops/pkg/commands/commandutils"
+	"k8s.io/kops/pkg/pki"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+var (
+	createSecretContainerdRegistryLong = templates.LongDesc(i18n.T(`
+	Create the credentials and client certificate for a containerd registry and store them in the state store.
+	The secret is referenced by name from the registries in the containerd configuration
+	of the cluster or instance group, and is written to the hosts.toml of those registries
+	on each node.`))
+
+	createSecretContainerdRegistryExample = templates.Examples(i18n.T(`
+	# Create the credentials for a registry.
+	kops create secret containerdregistry --secret-name harbor --username robot --password-file /path/to/password \
+		--name k8s-cluster.example.com --state s3://my-state-store
+
+	# Create the credentials for a registry, reading the password from stdin.
+	get-registry-password.sh | kops create secret containerdregistry --secret-name harbor --username robot --password-file - \
+		--name k8s-cluster.example.com --state s3://my-state-store
+
+	# Create a client certificate for a registry.
+	kops create secret containerdregistry --secret-name harbor --client-cert /path/to/tls.crt --client-key /path/to/tls.key \
+		--name k8s-cluster.example.com --state s3://my-state-store
+	`))
+
+	createSecretContainerdRegistryShort = i18n.T(`Create the credentials for a containerd registry.`)
+)
+
+type CreateSecretContainerdRegistryOptions struct {
+	ClusterName           string
+	SecretName            string
+	Username              string
+	PasswordFilePath      string
+	ClientCertificatePath string
+	ClientKeyPath         string
+	Force                 bool
+}
+
+func NewCmdCreateSecretContainerdRegistry(f *util.Factory, out io.Writer) *cobra.Command {
+	options := &CreateSecretContainerdRegistryOptions{}
+
+	cmd := &cobra.Command{
+		Use:               "containerdregistry [CLUSTER] --secret-name SECRET_NAME",
+		Short:             createSecretContainerdRegistryShort,
+		Long:              createSecretContainerdRegistryLong,
+		Example:           createSecretContainerdRegistryExample,
+		Args:              rootCommand.clusterNameArgs(&options.ClusterName),
+		ValidArgsFunction: commandutils.CompleteClusterName(f, true, false),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return RunCreateSecretContainerdRegistry(cmd.Context(), f, out, options)
+		},
+	}
+
+	cmd.Flags().StringVar(&options.SecretName, "secret-name", "", "Name of the secret, as referenced by the containerd registries configuration")
+	cmd.MarkFlagRequired("secret-name")
+	cmd.Flags().StringVar(&options.Username, "username", "", "User name used to authenticate to the registry")
+	cmd.Flags().StringVar(&options.PasswordFilePath, "password-file", "", "Path to a file containing the password used to authenticate to the registry, or - to read it from stdin")
+	cmd.Flags().StringVar(&options.ClientCertificatePath, "client-cert", "", "Path to the PEM encoded client certificate presented to the registry")
+	cmd.Flags().StringVar(&options.ClientKeyPath, "client-key", "", "Path to the PEM encoded private key of the client certificate")
+	cmd.Flags().BoolVar(&options.Force, "force", options.Force, "Force replace the secret if it already exists")
+
+	return cmd
+}
+
+func RunCreateSecretContainerdRegistry(ctx context.Context, f commandutils.Factory, out io.Writer, options *CreateSecretContainerdRegistryOptions) error {
+	if errs := validation.IsDNS1123Label(options.SecretName); len(errs) != 0 {
+		return fmt.Errorf("invalid secret name %q: %s", options.SecretName, strings.Join(errs, "; "))
+	}
+	if (options.Username == "") != (options.PasswordFilePath == "") {
+		return fmt.Errorf("--username and --password-file must be specified together")
+	}
+	if (options.ClientCertificatePath == "") != (options.ClientKeyPath == "") {
+		return fmt.Errorf("--client-cert and --client-key must be specified together")
+	}
+	if options.Username == "" && options.ClientCertificatePath == "" {
+		return fmt.Errorf("either --username or --client-cert must be specified")
+	}
+
+	registrySecret := &nodeup.ContainerdRegistrySecret{
+		Username: options.Username,
+	}
+	if options.PasswordFilePath == "-" {
+		data, err := ConsumeStdin()
+		if err != nil {
+			return fmt.Errorf("reading password from stdin: %v", err)
+		}
+		registrySecret.Password = strings.TrimSpace(string(data))
+	} else if options.PasswordFilePath != "" {
+		data, err := os.ReadFile(options.PasswordFilePath)
+		if err != nil {
+			return fmt.Errorf("reading password %v: %v", options.PasswordFilePath, err)
+		}
+		registrySecret.Password = strings.TrimSpace(string(data))
+	}
+	if options.ClientCertificatePath != "" {
+		certificate, err := os.ReadFile(options.ClientCertificatePath)
+		if err != nil {
+			return fmt.Errorf("reading client certificate %v: %v", options.ClientCertificatePath, err)
+		}
+		key, err := os.ReadFile(options.ClientKeyPath)
+		if err != nil {
+			return fmt.Errorf("reading client key %v: %v", options.ClientKeyPath, err)
+		}
+		if _, err := pki.ParsePEMCertificate(certificate); err != nil {
+			return fmt.Errorf("parsing client certificate %v: %v", options.ClientCertificatePath, err)
+		}
+		if _, err := pki.ParsePEMPrivateKey(key); err != nil {
+			return fmt.Errorf("parsing client key %v: %v", options.ClientKeyPath, err)
+		}
+		registrySecret.ClientCertificate = string(certificate)
+		registrySecret.ClientKey = string(key)
+	}
+
+	data, err := json.Marshal(registrySecret)
+	if err != nil {
+		return fmt.Errorf("serializing registry secret: %v", err)
+	}
+
+	cluster, err := GetCluster(ctx, f, options.ClusterName)
+	if err != nil {
+		return err
+	}
+
+	clientset, err := f.KopsClient()
+	if err != nil {
+		return err
+	}
+
+	secretStore, err := clientset.SecretStore(cluster)
+	if err != nil {
+		return err
+	}
+
+	secret := &fi.Secret{
+		Data: data,
+	}
+
+	id := nodeup.ContainerdRegistrySecretID(options.SecretName)
+	if !options.Force {
+		_, created, err := secretStore.GetOrCreateSecret(ctx, id, secret)
+		if err != nil {
+			return fmt.Errorf("adding %s secret: %v", id, err)
+		}
+		if !created {
+			return fmt.Errorf("failed to create the %s secret as it already exists. Pass the `--force` flag to replace an existing secret", id)
+		}
+	} else {
+		_, err := secretStore.ReplaceSecret(id, secret)
+		if err != nil {
+			return fmt.Errorf("updating %s secret: %v", id, err)
+		}
+	}
+
+	return nil
+}
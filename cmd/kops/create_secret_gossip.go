@@ -0,0 +1,156 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+	"k8s.io/kops/cmd/kops/util"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/apis/nodeup"
+	"k8s.io/kops/pkg/commands/commandutils"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+var (
+	createSecretGossipLong = templates.LongDesc(i18n.T(`
+	Create a new gossip secret and store it in the state store.
+
+	The new secret is accepted on incoming gossip, but not used for outgoing
+	gossip until it is promoted with "kops promote secret gossip". This is the
+	first step of rotating the secret securing gossip DNS, which
+	"kops rotate secret gossip" runs from start to end:
+
+	1. Create a new secret and roll all the instances, so that they accept it.
+	2. Promote the new secret and roll all the instances, so that they use it.
+	3. Distrust the previous secret and roll all the instances, so that they reject it.
+
+	When the secret isn't stored in the state store yet, gossip is still sent
+	unsealed until the new secret is promoted. Only clusters that gossip over
+	memberlist can rotate their gossip secret.`))
+
+	createSecretGossipExample = templates.Examples(i18n.T(`
+	# Create a new gossip secret.
+	kops create secret gossip \
+		--name k8s-cluster.k8s.local --state s3://my-state-store
+	`))
+
+	createSecretGossipShort = i18n.T(`Create a new gossip secret.`)
+)
+
+type CreateSecretGossipOptions struct {
+	ClusterName string
+}
+
+func NewCmdCreateSecretGossip(f *util.Factory, out io.Writer) *cobra.Command {
+	options := &CreateSecretGossipOptions{}
+
+	cmd := &cobra.Command{
+		Use:               "gossip [CLUSTER]",
+		Short:             createSecretGossipShort,
+		Long:              createSecretGossipLong,
+		Example:           createSecretGossipExample,
+		Args:              rootCommand.clusterNameArgs(&options.ClusterName),
+		ValidArgsFunction: commandutils.CompleteClusterName(f, true, false),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return RunCreateSecretGossip(cmd.Context(), f, out, options)
+		},
+	}
+
+	return cmd
+}
+
+func RunCreateSecretGossip(ctx context.Context, f commandutils.Factory, out io.Writer, options *CreateSecretGossipOptions) error {
+	_, secretStore, keyset, err := getGossipKeyset(ctx, f, options.ClusterName)
+	if err != nil {
+		return err
+	}
+
+	if keyset == nil {
+		// Instances without a gossip keyset gossip plaintext state over memberlist, so the first keyset keeps
+		// sending plaintext state until the new secret is promoted, and instances can be updated one at a time.
+		keyset = &nodeup.GossipKeyset{}
+	}
+
+	secret, err := fi.CreateSecret()
+	if err != nil {
+		return err
+	}
+	keyset.AddSecondary(string(secret.Data))
+
+	if err := storeGossipKeyset(secretStore, keyset); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "Created a new gossip secret, it will be accepted once all the instances are updated\n")
+	return nil
+}
+
+// getGossipKeyset returns the cluster, its secret store and the gossip keyset, if it exists.
+func getGossipKeyset(ctx context.Context, f commandutils.Factory, clusterName string) (*kops.Cluster, fi.SecretStore, *nodeup.GossipKeyset, error) {
+	cluster, err := GetCluster(ctx, f, clusterName)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	if !cluster.UsesLegacyGossip() {
+		return nil, nil, nil, fmt.Errorf("cluster %q does not use gossip DNS", cluster.Name)
+	}
+	if err := nodeup.CheckGossipKeysetProtocols(cluster); err != nil {
+		return nil, nil, nil, err
+	}
+
+	clientset, err := f.KopsClient()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	secretStore, err := clientset.SecretStore(cluster)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	secret, err := secretStore.FindSecret(nodeup.GossipSecretName)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("reading gossip secret: %w", err)
+	}
+	if secret == nil {
+		return cluster, secretStore, nil, nil
+	}
+
+	keyset, err := nodeup.ParseGossipKeyset(secret.Data)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return cluster, secretStore, keyset, nil
+}
+
+func storeGossipKeyset(secretStore fi.SecretStore, keyset *nodeup.GossipKeyset) error {
+	data, err := keyset.Marshal()
+	if err != nil {
+		return err
+	}
+	if _, err := secretStore.ReplaceSecret(nodeup.GossipSecretName, &fi.Secret{Data: data}); err != nil {
+		return fmt.Errorf("writing gossip secret: %w", err)
+	}
+	return nil
+}
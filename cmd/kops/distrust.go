@@ -24,7 +24,7 @@ import (
 	"k8s.io/kubectl/pkg/util/i18n"
 )
 
-var distrustShort = i18n.T("Distrust keypairs.")
+var distrustShort = i18n.T("Distrust keypairs, secrets and admin credentials.")
 
 func NewCmdDistrust(f *util.Factory, out io.Writer) *cobra.Command {
 	cmd := &cobra.Command{
@@ -33,7 +33,9 @@ func NewCmdDistrust(f *util.Factory, out io.Writer) *cobra.Command {
 	}
 
 	// create subcommands
+	cmd.AddCommand(NewCmdDistrustAdminCredential(f, out))
 	cmd.AddCommand(NewCmdDistrustKeypair(f, out))
+	cmd.AddCommand(NewCmdDistrustSecret(f, out))
 
 	return cmd
 }
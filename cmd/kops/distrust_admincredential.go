@@ -0,0 +1,128 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/kops/cmd/kops/util"
+	"k8s.io/kops/pkg/admincredentials"
+	"k8s.io/kops/pkg/commands/commandutils"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+var (
+	distrustAdminCredentialLong = templates.LongDesc(i18n.T(`
+	Revoke one or more admin credentials before they expire.
+
+	The requests of revoked admin credentials are denied by kops-controller when
+	spec.authentication.adminCredentialDenyList is enabled. Admin credentials
+	issued before it was enabled are members of system:masters, and can't be denied;
+	rotate the kubernetes-ca keypair to revoke them.`))
+
+	distrustAdminCredentialExample = templates.Examples(i18n.T(`
+	# Revoke an admin credential, as listed by "kops get admin-credentials".
+	kops distrust admin-credential 1704067200000000000 --name k8s-cluster.example.com
+	`))
+
+	distrustAdminCredentialShort = i18n.T(`Revoke admin credentials.`)
+)
+
+type DistrustAdminCredentialOptions struct {
+	ClusterName string
+	Serials     []string
+}
+
+func NewCmdDistrustAdminCredential(f *util.Factory, out io.Writer) *cobra.Command {
+	options := &DistrustAdminCredentialOptions{}
+
+	cmd := &cobra.Command{
+		Use:     "admin-credential SERIAL...",
+		Short:   distrustAdminCredentialShort,
+		Long:    distrustAdminCredentialLong,
+		Example: distrustAdminCredentialExample,
+		Args: func(cmd *cobra.Command, args []string) error {
+			options.ClusterName = rootCommand.ClusterName(true)
+			if options.ClusterName == "" {
+				return fmt.Errorf("--name is required")
+			}
+
+			if len(args) == 0 {
+				return fmt.Errorf("must specify the serial of the admin credential to revoke")
+			}
+			options.Serials = args
+
+			return nil
+		},
+		ValidArgsFunction: cobra.NoFileCompletions,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return RunDistrustAdminCredential(cmd.Context(), f, out, options)
+		},
+	}
+
+	return cmd
+}
+
+func RunDistrustAdminCredential(ctx context.Context, f commandutils.Factory, out io.Writer, options *DistrustAdminCredentialOptions) error {
+	clientset, err := f.KopsClient()
+	if err != nil {
+		return err
+	}
+
+	cluster, err := clientset.GetCluster(ctx, options.ClusterName)
+	if err != nil {
+		return err
+	}
+
+	configBase, err := clientset.ConfigBaseFor(cluster)
+	if err != nil {
+		return fmt.Errorf("error building ConfigBase for cluster: %w", err)
+	}
+
+	for _, serial := range options.Serials {
+		credential, err := admincredentials.Read(ctx, configBase, serial)
+		if err != nil {
+			return err
+		}
+		if credential == nil {
+			return fmt.Errorf("admin credential %q not found", serial)
+		}
+		if credential.RevokedAt != nil {
+			fmt.Fprintf(out, "Admin credential %s was already revoked\n", serial)
+			continue
+		}
+
+		now := time.Now().UTC()
+		credential.RevokedAt = &now
+		if err := admincredentials.Write(ctx, configBase, cluster, credential); err != nil {
+			return err
+		}
+
+		if credential.Revocable() {
+			fmt.Fprintf(out, "Revoked admin credential %s\n", serial)
+		} else {
+			fmt.Fprintf(out, "Recorded the revocation of admin credential %s, but it is a member of system:masters and can't be denied; rotate the kubernetes-ca keypair to revoke it\n", serial)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+	"k8s.io/kops/cmd/kops/util"
+	"k8s.io/kops/pkg/commands/commandutils"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+var (
+	distrustSecretGossipLong = templates.LongDesc(i18n.T(`
+	Distrust all gossip secrets other than the primary.
+
+	Once all the instances have been updated, gossip secured with a distrusted
+	secret is rejected. Only distrust the previous secrets once all the instances
+	have been updated to use the primary secret.`))
+
+	distrustSecretGossipExample = templates.Examples(i18n.T(`
+	# Distrust the gossip secrets other than the primary.
+	kops distrust secret gossip \
+		--name k8s-cluster.k8s.local --state s3://my-state-store
+	`))
+
+	distrustSecretGossipShort = i18n.T(`Distrust gossip secrets other than the primary.`)
+)
+
+type DistrustSecretGossipOptions struct {
+	ClusterName string
+}
+
+func NewCmdDistrustSecretGossip(f *util.Factory, out io.Writer) *cobra.Command {
+	options := &DistrustSecretGossipOptions{}
+
+	cmd := &cobra.Command{
+		Use:               "gossip [CLUSTER]",
+		Short:             distrustSecretGossipShort,
+		Long:              distrustSecretGossipLong,
+		Example:           distrustSecretGossipExample,
+		Args:              rootCommand.clusterNameArgs(&options.ClusterName),
+		ValidArgsFunction: commandutils.CompleteClusterName(f, true, false),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return RunDistrustSecretGossip(cmd.Context(), f, out, options)
+		},
+	}
+
+	return cmd
+}
+
+func RunDistrustSecretGossip(ctx context.Context, f commandutils.Factory, out io.Writer, options *DistrustSecretGossipOptions) error {
+	_, secretStore, keyset, err := getGossipKeyset(ctx, f, options.ClusterName)
+	if err != nil {
+		return err
+	}
+	if keyset == nil {
+		return fmt.Errorf("gossip secret not found, create one with \"kops create secret gossip\"")
+	}
+
+	n := keyset.Distrust()
+	if n == 0 {
+		fmt.Fprintf(out, "No gossip secrets to distrust\n")
+		return nil
+	}
+
+	if err := storeGossipKeyset(secretStore, keyset); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "Distrusted %d gossip secret(s), they will be rejected once all the instances are updated\n", n)
+	return nil
+}
@@ -25,8 +25,11 @@ import (
 	"github.com/spf13/cobra"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/klog/v2"
 	"k8s.io/kops/cmd/kops/util"
+	"k8s.io/kops/pkg/admincredentials"
 	kopsapi "k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/client/simple"
 	"k8s.io/kops/pkg/commands/commandutils"
 	"k8s.io/kops/pkg/kubeconfig"
 	"k8s.io/kops/upup/pkg/fi/cloudup"
@@ -49,6 +52,9 @@ var (
 
 	# export using the internal DNS name, bypassing the cloud load balancer
 	kops export kubeconfig k8s-cluster.example.com --internal
+
+	# export a user authenticating with the OIDC issuer of the cluster, through the kubelogin kubectl plugin
+	kops export kubeconfig k8s-cluster.example.com --oidc --oidc-extra-scope groups
 	`))
 
 	exportKubeconfigShort = i18n.T(`Export kubeconfig.`)
@@ -64,6 +70,11 @@ type ExportKubeconfigOptions struct {
 
 	// UseKopsAuthenticationPlugin controls whether we should use the kOps auth helper instead of a static credential
 	UseKopsAuthenticationPlugin bool
+
+	// UseOIDC controls whether we should authenticate with the OIDC issuer of the cluster instead of a static credential
+	UseOIDC bool
+	// OIDCExtraScopes are the scopes requested from the OIDC issuer in addition to "openid"
+	OIDCExtraScopes []string
 }
 
 func NewCmdExportKubeconfig(f *util.Factory, out io.Writer) *cobra.Command {
@@ -79,6 +90,12 @@ func NewCmdExportKubeconfig(f *util.Factory, out io.Writer) *cobra.Command {
 			if options.admin != 0 && options.user != "" {
 				return fmt.Errorf("cannot use both --admin and --user")
 			}
+			if options.UseOIDC && (options.admin != 0 || options.user != "" || options.UseKopsAuthenticationPlugin) {
+				return fmt.Errorf("cannot use --oidc with --admin, --user or --auth-plugin")
+			}
+			if len(options.OIDCExtraScopes) != 0 && !options.UseOIDC {
+				return fmt.Errorf("--oidc-extra-scope can only be used with --oidc")
+			}
 			if options.all {
 				if len(args) != 0 {
 					return fmt.Errorf("cannot use both --all flag and positional arguments")
@@ -102,6 +119,8 @@ func NewCmdExportKubeconfig(f *util.Factory, out io.Writer) *cobra.Command {
 	cmd.RegisterFlagCompletionFunc("user", completeKubecfgUser)
 	cmd.Flags().BoolVar(&options.internal, "internal", options.internal, "Use the cluster's internal DNS name")
 	cmd.Flags().BoolVar(&options.UseKopsAuthenticationPlugin, "auth-plugin", options.UseKopsAuthenticationPlugin, "Use the kOps authentication plugin")
+	cmd.Flags().BoolVar(&options.UseOIDC, "oidc", options.UseOIDC, "Authenticate with the OIDC issuer of the cluster, using the kubelogin kubectl plugin")
+	cmd.Flags().StringSliceVar(&options.OIDCExtraScopes, "oidc-extra-scope", options.OIDCExtraScopes, "Additional scopes to request from the OIDC issuer")
 
 	return cmd
 }
@@ -129,6 +148,13 @@ func RunExportKubeconfig(ctx context.Context, f *util.Factory, out io.Writer, op
 		clusterList = append(clusterList, cluster)
 	}
 
+	var oidc *kubeconfig.OIDCOptions
+	if options.UseOIDC {
+		oidc = &kubeconfig.OIDCOptions{
+			ExtraScopes: options.OIDCExtraScopes,
+		}
+	}
+
 	for _, cluster := range clusterList {
 		keyStore, err := clientset.KeyStore(cluster)
 		if err != nil {
@@ -154,11 +180,16 @@ func RunExportKubeconfig(ctx context.Context, f *util.Factory, out io.Writer, op
 			options.user,
 			options.internal,
 			f.KopsStateStore(),
-			options.UseKopsAuthenticationPlugin)
+			options.UseKopsAuthenticationPlugin,
+			oidc)
 		if err != nil {
 			return err
 		}
 
+		if err := recordAdminCredential(ctx, clientset, cluster, conf); err != nil {
+			return err
+		}
+
 		if err := conf.WriteKubecfg(buildPathOptions(options)); err != nil {
 			return err
 		}
@@ -167,6 +198,23 @@ func RunExportKubeconfig(ctx context.Context, f *util.Factory, out io.Writer, op
 	return nil
 }
 
+// recordAdminCredential records the admin credential issued for the kubeconfig, if any, in the state store.
+func recordAdminCredential(ctx context.Context, clientset simple.Clientset, cluster *kopsapi.Cluster, conf *kubeconfig.KubeconfigBuilder) error {
+	if conf.AdminCredential == nil {
+		return nil
+	}
+
+	configBase, err := clientset.ConfigBaseFor(cluster)
+	if err != nil {
+		return fmt.Errorf("error building ConfigBase for cluster: %w", err)
+	}
+	if err := admincredentials.Write(ctx, configBase, cluster, conf.AdminCredential); err != nil {
+		return fmt.Errorf("error recording admin credential: %w", err)
+	}
+	klog.Infof("recorded admin credential %s for %q, expiring at %s", conf.AdminCredential.Serial, conf.AdminCredential.CommonName, conf.AdminCredential.ExpiresAt.Format(time.RFC3339))
+	return nil
+}
+
 func buildPathOptions(options *ExportKubeconfigOptions) *clientcmd.PathOptions {
 	pathOptions := clientcmd.NewDefaultPathOptions()
 
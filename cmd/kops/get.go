@@ -62,6 +62,7 @@ func NewCmdGet(f *util.Factory, out io.Writer) *cobra.Command {
 	})
 
 	// create subcommands
+	cmd.AddCommand(NewCmdGetAdminCredentials(f, out, options))
 	cmd.AddCommand(NewCmdGetAll(f, out, options))
 	cmd.AddCommand(NewCmdGetAssets(f, out, options))
 	cmd.AddCommand(NewCmdGetCluster(f, out, options))
@@ -0,0 +1,156 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/kops/cmd/kops/util"
+	"k8s.io/kops/pkg/admincredentials"
+	"k8s.io/kops/pkg/commands/commandutils"
+	"k8s.io/kops/util/pkg/tables"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+	"sigs.k8s.io/yaml"
+)
+
+var (
+	getAdminCredentialsLong = templates.LongDesc(i18n.T(`
+	List the admin credentials issued for a cluster by "kops export kubeconfig --admin"
+	and "kops update cluster --admin", as recorded in the state store.`))
+
+	getAdminCredentialsExample = templates.Examples(i18n.T(`
+	# List the admin credentials issued for a cluster
+	kops get admin-credentials --name k8s-cluster.example.com`))
+
+	getAdminCredentialsShort = i18n.T(`Get the issued admin credentials.`)
+)
+
+type GetAdminCredentialsOptions struct {
+	*GetOptions
+}
+
+func NewCmdGetAdminCredentials(f *util.Factory, out io.Writer, getOptions *GetOptions) *cobra.Command {
+	options := GetAdminCredentialsOptions{
+		GetOptions: getOptions,
+	}
+	cmd := &cobra.Command{
+		Use:               "admin-credentials [CLUSTER]",
+		Aliases:           []string{"admin-credential"},
+		Short:             getAdminCredentialsShort,
+		Long:              getAdminCredentialsLong,
+		Example:           getAdminCredentialsExample,
+		Args:              rootCommand.clusterNameArgs(&options.ClusterName),
+		ValidArgsFunction: commandutils.CompleteClusterName(f, true, false),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return RunGetAdminCredentials(cmd.Context(), f, out, &options)
+		},
+	}
+
+	return cmd
+}
+
+func RunGetAdminCredentials(ctx context.Context, f commandutils.Factory, out io.Writer, options *GetAdminCredentialsOptions) error {
+	clientset, err := f.KopsClient()
+	if err != nil {
+		return err
+	}
+
+	cluster, err := clientset.GetCluster(ctx, options.ClusterName)
+	if err != nil {
+		return err
+	}
+
+	configBase, err := clientset.ConfigBaseFor(cluster)
+	if err != nil {
+		return fmt.Errorf("error building ConfigBase for cluster: %w", err)
+	}
+
+	items, err := admincredentials.List(ctx, configBase)
+	if err != nil {
+		return err
+	}
+
+	switch options.Output {
+
+	case OutputTable:
+		if len(items) == 0 {
+			return fmt.Errorf("no admin credentials found")
+		}
+		now := time.Now()
+		t := &tables.Table{}
+		t.AddColumn("SERIAL", func(i *admincredentials.AdminCredential) string {
+			return i.Serial
+		})
+		t.AddColumn("USER", func(i *admincredentials.AdminCredential) string {
+			return i.CommonName
+		})
+		t.AddColumn("GROUPS", func(i *admincredentials.AdminCredential) string {
+			return strings.Join(i.Groups, ",")
+		})
+		t.AddColumn("ISSUEDBY", func(i *admincredentials.AdminCredential) string {
+			return i.IssuedBy
+		})
+		t.AddColumn("HOSTNAME", func(i *admincredentials.AdminCredential) string {
+			return i.Hostname
+		})
+		t.AddColumn("ISSUED", func(i *admincredentials.AdminCredential) string {
+			return i.IssuedAt.Format(time.RFC3339)
+		})
+		t.AddColumn("EXPIRES", func(i *admincredentials.AdminCredential) string {
+			return i.ExpiresAt.Format(time.RFC3339)
+		})
+		t.AddColumn("STATUS", func(i *admincredentials.AdminCredential) string {
+			switch {
+			case i.RevokedAt != nil:
+				return "Revoked"
+			case i.ExpiresAt.Before(now):
+				return "Expired"
+			default:
+				return "Valid"
+			}
+		})
+		return t.Render(items, out, "SERIAL", "USER", "ISSUEDBY", "HOSTNAME", "ISSUED", "EXPIRES", "STATUS")
+
+	case OutputYaml:
+		y, err := yaml.Marshal(items)
+		if err != nil {
+			return fmt.Errorf("unable to marshal YAML: %v", err)
+		}
+		if _, err := out.Write(y); err != nil {
+			return fmt.Errorf("error writing to output: %v", err)
+		}
+	case OutputJSON:
+		j, err := json.Marshal(items)
+		if err != nil {
+			return fmt.Errorf("unable to marshal JSON: %v", err)
+		}
+		if _, err := out.Write(j); err != nil {
+			return fmt.Errorf("error writing to output: %v", err)
+		}
+	default:
+		return fmt.Errorf("unknown output format: %q", options.Output)
+	}
+
+	return nil
+}
@@ -34,6 +34,7 @@ func NewCmdPromote(f *util.Factory, out io.Writer) *cobra.Command {
 
 	// create subcommands
 	cmd.AddCommand(NewCmdPromoteKeypair(f, out))
+	cmd.AddCommand(NewCmdPromoteSecret(f, out))
 
 	return cmd
 }
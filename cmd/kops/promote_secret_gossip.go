@@ -0,0 +1,89 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+	"k8s.io/kops/cmd/kops/util"
+	"k8s.io/kops/pkg/commands/commandutils"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+var (
+	promoteSecretGossipLong = templates.LongDesc(i18n.T(`
+	Promote the most recently created gossip secret to be the primary, used for outgoing gossip.
+
+	The previous primary secret stays accepted on incoming gossip until it is
+	distrusted with "kops distrust secret gossip". Only promote a secret once
+	all the instances have been updated to accept it.`))
+
+	promoteSecretGossipExample = templates.Examples(i18n.T(`
+	# Promote the newest gossip secret to be the primary.
+	kops promote secret gossip \
+		--name k8s-cluster.k8s.local --state s3://my-state-store
+	`))
+
+	promoteSecretGossipShort = i18n.T(`Promote a gossip secret to be the primary.`)
+)
+
+type PromoteSecretGossipOptions struct {
+	ClusterName string
+}
+
+func NewCmdPromoteSecretGossip(f *util.Factory, out io.Writer) *cobra.Command {
+	options := &PromoteSecretGossipOptions{}
+
+	cmd := &cobra.Command{
+		Use:               "gossip [CLUSTER]",
+		Short:             promoteSecretGossipShort,
+		Long:              promoteSecretGossipLong,
+		Example:           promoteSecretGossipExample,
+		Args:              rootCommand.clusterNameArgs(&options.ClusterName),
+		ValidArgsFunction: commandutils.CompleteClusterName(f, true, false),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return RunPromoteSecretGossip(cmd.Context(), f, out, options)
+		},
+	}
+
+	return cmd
+}
+
+func RunPromoteSecretGossip(ctx context.Context, f commandutils.Factory, out io.Writer, options *PromoteSecretGossipOptions) error {
+	_, secretStore, keyset, err := getGossipKeyset(ctx, f, options.ClusterName)
+	if err != nil {
+		return err
+	}
+	if keyset == nil {
+		return fmt.Errorf("gossip secret not found, create one with \"kops create secret gossip\"")
+	}
+
+	if err := keyset.Promote(); err != nil {
+		return err
+	}
+
+	if err := storeGossipKeyset(secretStore, keyset); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "Promoted the newest gossip secret, it will be used once all the instances are updated\n")
+	return nil
+}
@@ -238,6 +238,11 @@ func RunRollingUpdateCluster(ctx context.Context, f *util.Factory, out io.Writer
 		return err
 	}
 
+	if cluster.Spec.GetCloudProvider() == kopsapi.CloudProviderMetal {
+		// Bare-metal hosts are not in cloud groups whose instances can be replaced
+		return fmt.Errorf("rolling updates are not supported on bare metal, reinstall the hosts with \"kops toolbox enroll --reinstall\" instead")
+	}
+
 	contextName := cluster.ObjectMeta.Name
 	clientGetter := genericclioptions.NewConfigFlags(true)
 	clientGetter.Context = &contextName
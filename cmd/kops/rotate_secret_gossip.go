@@ -0,0 +1,146 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+	"k8s.io/kops/cmd/kops/util"
+	"k8s.io/kops/pkg/commands/commandutils"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+var (
+	rotateSecretGossipLong = templates.LongDesc(i18n.T(`
+	Rotate the secret securing gossip DNS.
+
+	A new gossip secret is created, promoted to be the primary secret, and the
+	previous secrets are distrusted. Each step updates the cluster and rolls all
+	the instances, so that all of them accept the new secret before it is used,
+	and use it before the previous secrets are rejected.
+
+	If the rotation is interrupted, it can be completed with the individual steps:
+	"kops create secret gossip", "kops promote secret gossip" and
+	"kops distrust secret gossip", each followed by "kops update cluster" and
+	"kops rolling-update cluster".`))
+
+	rotateSecretGossipExample = templates.Examples(i18n.T(`
+	# Rotate the gossip secret.
+	kops rotate secret gossip \
+		--name k8s-cluster.k8s.local --state s3://my-state-store --yes
+	`))
+
+	rotateSecretGossipShort = i18n.T(`Rotate the gossip secret.`)
+)
+
+type RotateSecretGossipOptions struct {
+	ClusterName string
+	Yes         bool
+}
+
+func NewCmdRotateSecretGossip(f *util.Factory, out io.Writer) *cobra.Command {
+	options := &RotateSecretGossipOptions{}
+
+	cmd := &cobra.Command{
+		Use:               "gossip [CLUSTER]",
+		Short:             rotateSecretGossipShort,
+		Long:              rotateSecretGossipLong,
+		Example:           rotateSecretGossipExample,
+		Args:              rootCommand.clusterNameArgs(&options.ClusterName),
+		ValidArgsFunction: commandutils.CompleteClusterName(f, true, false),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return RunRotateSecretGossip(cmd.Context(), f, out, options)
+		},
+	}
+
+	cmd.Flags().BoolVarP(&options.Yes, "yes", "y", options.Yes, "Rotate the secret, updating the cluster and rolling all the instances")
+
+	return cmd
+}
+
+func RunRotateSecretGossip(ctx context.Context, f *util.Factory, out io.Writer, options *RotateSecretGossipOptions) error {
+	// Fail early, before any of the steps, if the gossip secret can't be rotated
+	if _, _, _, err := getGossipKeyset(ctx, f, options.ClusterName); err != nil {
+		return err
+	}
+
+	if !options.Yes {
+		fmt.Fprintf(out, "Will create a new gossip secret, promote it and distrust the previous secrets, updating the cluster and rolling all the instances after each step.\n")
+		fmt.Fprintf(out, "\nMust specify --yes to rotate the gossip secret\n")
+		return nil
+	}
+
+	steps := []struct {
+		name string
+		run  func() error
+	}{
+		{
+			name: "create",
+			run: func() error {
+				return RunCreateSecretGossip(ctx, f, out, &CreateSecretGossipOptions{ClusterName: options.ClusterName})
+			},
+		},
+		{
+			name: "promote",
+			run: func() error {
+				return RunPromoteSecretGossip(ctx, f, out, &PromoteSecretGossipOptions{ClusterName: options.ClusterName})
+			},
+		},
+		{
+			name: "distrust",
+			run: func() error {
+				return RunDistrustSecretGossip(ctx, f, out, &DistrustSecretGossipOptions{ClusterName: options.ClusterName})
+			},
+		},
+	}
+
+	for _, step := range steps {
+		if err := step.run(); err != nil {
+			return fmt.Errorf("error running the %s step of the gossip secret rotation: %w", step.name, err)
+		}
+		if err := rollGossipSecret(ctx, f, out, options.ClusterName); err != nil {
+			return fmt.Errorf("error rolling out the %s step of the gossip secret rotation: %w", step.name, err)
+		}
+	}
+
+	fmt.Fprintf(out, "Rotated the gossip secret\n")
+	return nil
+}
+
+// rollGossipSecret updates the cluster and rolls all the instances, so that they pick up the gossip keyset
+func rollGossipSecret(ctx context.Context, f *util.Factory, out io.Writer, clusterName string) error {
+	updateClusterOptions := &UpdateClusterOptions{}
+	updateClusterOptions.InitDefaults()
+	updateClusterOptions.ClusterName = clusterName
+	updateClusterOptions.Yes = true
+	updateClusterOptions.CreateKubecfg = false
+	if _, err := RunUpdateCluster(ctx, f, out, updateClusterOptions); err != nil {
+		return err
+	}
+
+	rollingUpdateOptions := &RollingUpdateOptions{}
+	rollingUpdateOptions.InitDefaults()
+	rollingUpdateOptions.ClusterName = clusterName
+	rollingUpdateOptions.Yes = true
+	// Every instance gets the new keyset, so all of them are replaced
+	rollingUpdateOptions.Force = true
+	return RunRollingUpdateCluster(ctx, f, out, rollingUpdateOptions)
+}
@@ -0,0 +1,57 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"io"
+
+	"github.com/spf13/cobra"
+	"k8s.io/kops/pkg/commands"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+func NewCmdToolboxAuditNode(out io.Writer) *cobra.Command {
+	options := &commands.ToolboxAuditNodeOptions{}
+	options.InitDefaults()
+
+	cmd := &cobra.Command{
+		Use:   "audit-node",
+		Short: i18n.T(`Compare a node with its configuration`),
+		Long: templates.LongDesc(i18n.T(`
+			Runs nodeup on a node without changing it, and reports every file, package,
+			service and sysctl that differs from the configuration of the node, as JSON.`)),
+		Example: templates.Examples(i18n.T(`
+			# Audit a node, connecting as the ubuntu user
+			kops toolbox audit-node --host 10.0.0.10 --ssh-user ubuntu
+
+			# Audit a node and set its KopsConfigurationDrift condition
+			kops toolbox audit-node --host 10.0.0.10 --update-node
+		`)),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return commands.RunToolboxAuditNode(cmd.Context(), out, options)
+		},
+	}
+
+	cmd.Flags().StringVar(&options.Host, "host", options.Host, "IP/hostname of the node to audit")
+	cmd.Flags().StringVar(&options.SSHUser, "ssh-user", options.SSHUser, "user for ssh")
+	cmd.Flags().IntVar(&options.SSHPort, "ssh-port", options.SSHPort, "port for ssh")
+	cmd.Flags().BoolVar(&options.UpdateNode, "update-node", options.UpdateNode, "Set the KopsConfigurationDrift condition of the node")
+
+	return cmd
+}
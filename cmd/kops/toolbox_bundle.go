@@ -0,0 +1,166 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/spf13/cobra"
+	"k8s.io/kops/cmd/kops/util"
+	"k8s.io/kops/pkg/assets"
+	"k8s.io/kops/pkg/commands/commandutils"
+	"k8s.io/kops/upup/pkg/fi/cloudup"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+var (
+	toolboxBundleShort = i18n.T(`Carry the assets of a cluster into an air gap.`)
+
+	toolboxBundleCreateLong = templates.LongDesc(i18n.T(`
+	Download the file assets, container images and channel manifests used by a cluster,
+	and write them to a single archive.
+
+	The archive can then be carried into an air gap, and imported with
+	"kops toolbox bundle import".`))
+
+	toolboxBundleCreateExample = templates.Examples(i18n.T(`
+	# Write the assets of a cluster to bundle.tar
+	kops toolbox bundle create --filename bundle.tar \
+		--name k8s-cluster.example.com --state s3://my-state-store
+	`))
+
+	toolboxBundleCreateShort = i18n.T(`Write the assets of a cluster to an archive.`)
+
+	toolboxBundleImportLong = templates.LongDesc(i18n.T(`
+	Push the contents of an archive written by "kops toolbox bundle create" to the
+	file repository and container registry configured in the assets of the cluster.`))
+
+	toolboxBundleImportExample = templates.Examples(i18n.T(`
+	# Push the assets in bundle.tar to the repositories of a cluster
+	kops toolbox bundle import --filename bundle.tar \
+		--name k8s-cluster.example.com --state s3://my-state-store
+	`))
+
+	toolboxBundleImportShort = i18n.T(`Push the assets in an archive to the repositories of a cluster.`)
+)
+
+type ToolboxBundleOptions struct {
+	ClusterName string
+	Filename    string
+}
+
+func NewCmdToolboxBundle(f *util.Factory, out io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "bundle",
+		Short: toolboxBundleShort,
+	}
+
+	cmd.AddCommand(NewCmdToolboxBundleCreate(f, out))
+	cmd.AddCommand(NewCmdToolboxBundleImport(f, out))
+
+	return cmd
+}
+
+func NewCmdToolboxBundleCreate(f *util.Factory, out io.Writer) *cobra.Command {
+	options := &ToolboxBundleOptions{}
+
+	cmd := &cobra.Command{
+		Use:               "create [CLUSTER] --filename FILENAME",
+		Short:             toolboxBundleCreateShort,
+		Long:              toolboxBundleCreateLong,
+		Example:           toolboxBundleCreateExample,
+		Args:              rootCommand.clusterNameArgs(&options.ClusterName),
+		ValidArgsFunction: commandutils.CompleteClusterName(f, true, false),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return RunToolboxBundleCreate(cmd.Context(), f, out, options)
+		},
+	}
+
+	cmd.Flags().StringVarP(&options.Filename, "filename", "f", "", "Path of the archive to write")
+	cmd.MarkFlagRequired("filename")
+
+	return cmd
+}
+
+func RunToolboxBundleCreate(ctx context.Context, f *util.Factory, out io.Writer, options *ToolboxBundleOptions) error {
+	updateClusterResults, err := RunUpdateCluster(ctx, f, out, &UpdateClusterOptions{
+		Target:      cloudup.TargetDryRun,
+		GetAssets:   true,
+		ClusterName: options.ClusterName,
+	})
+	if err != nil {
+		return err
+	}
+
+	manifest, err := assets.CreateBundle(ctx, f.VFSContext(), updateClusterResults.Cluster, updateClusterResults.ImageAssets, updateClusterResults.FileAssets, options.Filename)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "Wrote %d files, %d images and %d channels to %s\n", len(manifest.Files), len(manifest.Images), len(manifest.Channels), options.Filename)
+	return nil
+}
+
+func NewCmdToolboxBundleImport(f *util.Factory, out io.Writer) *cobra.Command {
+	options := &ToolboxBundleOptions{}
+
+	cmd := &cobra.Command{
+		Use:               "import [CLUSTER] --filename FILENAME",
+		Short:             toolboxBundleImportShort,
+		Long:              toolboxBundleImportLong,
+		Example:           toolboxBundleImportExample,
+		Args:              rootCommand.clusterNameArgs(&options.ClusterName),
+		ValidArgsFunction: commandutils.CompleteClusterName(f, true, false),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return RunToolboxBundleImport(cmd.Context(), f, out, options)
+		},
+	}
+
+	cmd.Flags().StringVarP(&options.Filename, "filename", "f", "", "Path of the archive to import")
+	cmd.MarkFlagRequired("filename")
+
+	return cmd
+}
+
+func RunToolboxBundleImport(ctx context.Context, f *util.Factory, out io.Writer, options *ToolboxBundleOptions) error {
+	cluster, err := GetCluster(ctx, f, options.ClusterName)
+	if err != nil {
+		return err
+	}
+
+	channels, err := assets.ImportBundle(ctx, f.VFSContext(), cluster, options.Filename)
+	if err != nil {
+		return err
+	}
+
+	locations := make([]string, 0, len(channels))
+	for location := range channels {
+		locations = append(locations, location)
+	}
+	sort.Strings(locations)
+	for _, location := range locations {
+		fmt.Fprintf(out, "Imported channel %s to %s\n", location, channels[location])
+	}
+	if len(locations) != 0 {
+		fmt.Fprintf(out, "Point spec.channel and spec.addons of the cluster to the imported channels to use them\n")
+	}
+	return nil
+}
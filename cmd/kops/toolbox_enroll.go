@@ -34,9 +34,17 @@ func NewCmdToolboxEnroll(f commandutils.Factory, out io.Writer) *cobra.Command {
 		Use:   "enroll [CLUSTER]",
 		Short: i18n.T(`Add machine to cluster`),
 		Long: templates.LongDesc(i18n.T(`
-			Adds an individual machine to the cluster.`)),
+			Adds an individual machine to the cluster, or all the machines listed in an inventory file.
+
+			With --reinstall, the machines that are already nodes are drained and reinstalled one at a time.`)),
 		Example: templates.Examples(i18n.T(`
-			kops toolbox enroll --name k8s-cluster.example.com
+			kops toolbox enroll --cluster k8s-cluster.example.com --instance-group nodes --host 192.168.1.20
+
+			# Enroll all the machines of an inventory
+			kops toolbox enroll --cluster k8s-cluster.example.com --inventory hosts.yaml
+
+			# Reinstall the machines of an inventory, one at a time
+			kops toolbox enroll --cluster k8s-cluster.example.com --inventory hosts.yaml --reinstall
 		`)),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return commands.RunToolboxEnroll(cmd.Context(), f, out, options)
@@ -47,6 +55,10 @@ func NewCmdToolboxEnroll(f commandutils.Factory, out io.Writer) *cobra.Command {
 	cmd.Flags().StringVar(&options.InstanceGroup, "instance-group", options.InstanceGroup, "Name of instance-group to join")
 
 	cmd.Flags().StringVar(&options.Host, "host", options.Host, "IP/hostname for machine to add")
+	cmd.Flags().StringVar(&options.Inventory, "inventory", options.Inventory, "File listing the machines to add, by instance group")
+	cmd.Flags().BoolVar(&options.Reinstall, "reinstall", options.Reinstall, "Drain and reinstall the machines that are already nodes, one at a time")
+	cmd.Flags().DurationVar(&options.DrainTimeout, "drain-timeout", options.DrainTimeout, "Maximum time to wait for a node to drain")
+	cmd.Flags().DurationVar(&options.ReadyTimeout, "ready-timeout", options.ReadyTimeout, "Maximum time to wait for a reinstalled node to be ready")
 	cmd.Flags().StringVar(&options.SSHUser, "ssh-user", options.SSHUser, "user for ssh")
 	cmd.Flags().IntVar(&options.SSHPort, "ssh-port", options.SSHPort, "port for ssh")
 
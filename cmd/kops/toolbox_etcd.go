@@ -0,0 +1,452 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/kops/cmd/kops/util"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/commands/commandutils"
+	"k8s.io/kops/pkg/etcdbackup"
+	"k8s.io/kops/util/pkg/tables"
+	"k8s.io/kops/util/pkg/vfs"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+var (
+	toolboxEtcdShort = i18n.T(`Manage the etcd backups of a cluster.`)
+
+	toolboxEtcdListBackupsLong = templates.LongDesc(i18n.T(`
+	List the etcd backups of a cluster, as found in the backup stores of its etcd clusters.`))
+
+	toolboxEtcdListBackupsExample = templates.Examples(i18n.T(`
+	# List the backups of all the etcd clusters of a cluster
+	kops toolbox etcd list-backups --name k8s-cluster.example.com --state s3://my-state-store
+
+	# List the backups of the main etcd cluster
+	kops toolbox etcd list-backups --name k8s-cluster.example.com --state s3://my-state-store --etcd-cluster main
+	`))
+
+	toolboxEtcdListBackupsShort = i18n.T(`List the etcd backups of a cluster.`)
+
+	toolboxEtcdRestoreLong = templates.LongDesc(i18n.T(`
+	Restore an etcd cluster from one of its backups.
+
+	A restore command is added to the backup store of the etcd cluster, then the
+	control plane nodes are replaced so etcd-manager picks it up, and the cluster
+	is validated. A restore cannot be undone, except by restoring another backup.`))
+
+	toolboxEtcdRestoreExample = templates.Examples(i18n.T(`
+	# Preview the restore of the main etcd cluster
+	kops toolbox etcd restore --name k8s-cluster.example.com --state s3://my-state-store \
+		--backup 2024-01-01T00:00:00Z-000001
+
+	# Restore both etcd clusters, restarting the control plane once
+	kops toolbox etcd restore --name k8s-cluster.example.com --state s3://my-state-store \
+		--etcd-cluster events --backup 2024-01-01T00:00:00Z-000002 --restart=false --yes
+	kops toolbox etcd restore --name k8s-cluster.example.com --state s3://my-state-store \
+		--etcd-cluster main --backup 2024-01-01T00:00:00Z-000001 --yes
+	`))
+
+	toolboxEtcdRestoreShort = i18n.T(`Restore an etcd cluster from a backup.`)
+
+	toolboxEtcdVerifyBackupLong = templates.LongDesc(i18n.T(`
+	Download an etcd backup from the backup store, restore it into a throwaway
+	etcd listening on localhost, and report its number of keys and revision.
+
+	The etcd, and etcdutl or etcdctl binaries must be available locally, in the PATH
+	or in the directory given by --etcd-bin-dir, in a version able to read the backup.`))
+
+	toolboxEtcdVerifyBackupExample = templates.Examples(i18n.T(`
+	# Verify the latest backups of all the etcd clusters of a cluster
+	kops toolbox etcd verify-backup --name k8s-cluster.example.com --state s3://my-state-store
+
+	# Verify a given backup of the main etcd cluster
+	kops toolbox etcd verify-backup --name k8s-cluster.example.com --state s3://my-state-store \
+		--etcd-cluster main --backup 2024-01-01T00:00:00Z-000001
+	`))
+
+	toolboxEtcdVerifyBackupShort = i18n.T(`Verify that etcd backups can be restored.`)
+)
+
+func NewCmdToolboxEtcd(f *util.Factory, out io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "etcd",
+		Short: toolboxEtcdShort,
+	}
+
+	cmd.AddCommand(NewCmdToolboxEtcdListBackups(f, out))
+	cmd.AddCommand(NewCmdToolboxEtcdRestore(f, out))
+	cmd.AddCommand(NewCmdToolboxEtcdVerifyBackup(f, out))
+
+	return cmd
+}
+
+type ToolboxEtcdListBackupsOptions struct {
+	ClusterName string
+
+	// EtcdClusters are the names of the etcd clusters whose backups are listed, all of them if empty.
+	EtcdClusters []string
+}
+
+func NewCmdToolboxEtcdListBackups(f *util.Factory, out io.Writer) *cobra.Command {
+	options := &ToolboxEtcdListBackupsOptions{}
+
+	cmd := &cobra.Command{
+		Use:               "list-backups [CLUSTER]",
+		Short:             toolboxEtcdListBackupsShort,
+		Long:              toolboxEtcdListBackupsLong,
+		Example:           toolboxEtcdListBackupsExample,
+		Args:              rootCommand.clusterNameArgs(&options.ClusterName),
+		ValidArgsFunction: commandutils.CompleteClusterName(f, true, false),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return RunToolboxEtcdListBackups(cmd.Context(), f, out, options)
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&options.EtcdClusters, "etcd-cluster", options.EtcdClusters, "Names of the etcd clusters whose backups to list; all of them if not set")
+
+	return cmd
+}
+
+type etcdBackup struct {
+	EtcdCluster string
+	Name        string
+}
+
+func RunToolboxEtcdListBackups(ctx context.Context, f *util.Factory, out io.Writer, options *ToolboxEtcdListBackupsOptions) error {
+	clientset, err := f.KopsClient()
+	if err != nil {
+		return err
+	}
+
+	cluster, err := GetCluster(ctx, f, options.ClusterName)
+	if err != nil {
+		return err
+	}
+
+	configBase, err := clientset.ConfigBaseFor(cluster)
+	if err != nil {
+		return fmt.Errorf("error building ConfigBase for cluster: %w", err)
+	}
+
+	etcdClusters := options.EtcdClusters
+	if len(etcdClusters) == 0 {
+		for _, etcdCluster := range cluster.Spec.EtcdClusters {
+			etcdClusters = append(etcdClusters, etcdCluster.Name)
+		}
+	}
+
+	var backups []*etcdBackup
+	for _, etcdCluster := range etcdClusters {
+		store, err := etcdBackupStore(f, cluster, configBase, etcdCluster)
+		if err != nil {
+			return err
+		}
+		names, err := store.ListBackups(ctx)
+		if err != nil {
+			return fmt.Errorf("error listing the backups of etcd cluster %s: %w", etcdCluster, err)
+		}
+		for _, name := range names {
+			backups = append(backups, &etcdBackup{EtcdCluster: etcdCluster, Name: name})
+		}
+	}
+
+	if len(backups) == 0 {
+		return fmt.Errorf("no etcd backups found")
+	}
+
+	t := &tables.Table{}
+	t.AddColumn("ETCD-CLUSTER", func(b *etcdBackup) string {
+		return b.EtcdCluster
+	})
+	t.AddColumn("BACKUP", func(b *etcdBackup) string {
+		return b.Name
+	})
+	return t.Render(backups, out, "ETCD-CLUSTER", "BACKUP")
+}
+
+type ToolboxEtcdRestoreOptions struct {
+	ClusterName string
+
+	// EtcdCluster is the name of the etcd cluster to restore.
+	EtcdCluster string
+	// Backup is the name of the backup to restore.
+	Backup string
+	// Yes actually restores the backup, instead of previewing the restore.
+	Yes bool
+	// Restart replaces the control plane nodes once the restore command is written, so it takes effect.
+	Restart bool
+	// ValidationTimeout is how long to wait for the cluster to validate after the restore.
+	ValidationTimeout time.Duration
+}
+
+func (o *ToolboxEtcdRestoreOptions) InitDefaults() {
+	o.EtcdCluster = "main"
+	o.Restart = true
+	o.ValidationTimeout = 15 * time.Minute
+}
+
+func NewCmdToolboxEtcdRestore(f *util.Factory, out io.Writer) *cobra.Command {
+	options := &ToolboxEtcdRestoreOptions{}
+	options.InitDefaults()
+
+	cmd := &cobra.Command{
+		Use:               "restore [CLUSTER]",
+		Short:             toolboxEtcdRestoreShort,
+		Long:              toolboxEtcdRestoreLong,
+		Example:           toolboxEtcdRestoreExample,
+		Args:              rootCommand.clusterNameArgs(&options.ClusterName),
+		ValidArgsFunction: commandutils.CompleteClusterName(f, true, false),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return RunToolboxEtcdRestore(cmd.Context(), f, out, options)
+		},
+	}
+
+	cmd.Flags().StringVar(&options.EtcdCluster, "etcd-cluster", options.EtcdCluster, "Name of the etcd cluster to restore")
+	cmd.Flags().StringVar(&options.Backup, "backup", options.Backup, "Name of the backup to restore, as listed by list-backups")
+	cmd.MarkFlagRequired("backup")
+	cmd.Flags().BoolVarP(&options.Yes, "yes", "y", options.Yes, "Restore the backup without confirmation")
+	cmd.Flags().BoolVar(&options.Restart, "restart", options.Restart, "Replace the control plane nodes and validate the cluster once the restore command is written")
+	cmd.Flags().DurationVar(&options.ValidationTimeout, "validation-timeout", options.ValidationTimeout, "Maximum time to wait for the cluster to validate after the restore")
+
+	return cmd
+}
+
+func RunToolboxEtcdRestore(ctx context.Context, f *util.Factory, out io.Writer, options *ToolboxEtcdRestoreOptions) error {
+	clientset, err := f.KopsClient()
+	if err != nil {
+		return err
+	}
+
+	cluster, err := GetCluster(ctx, f, options.ClusterName)
+	if err != nil {
+		return err
+	}
+
+	var etcdClusterSpec *kops.EtcdClusterSpec
+	for i := range cluster.Spec.EtcdClusters {
+		if cluster.Spec.EtcdClusters[i].Name == options.EtcdCluster {
+			etcdClusterSpec = &cluster.Spec.EtcdClusters[i]
+		}
+	}
+	if etcdClusterSpec == nil {
+		return fmt.Errorf("etcd cluster %q not found in cluster %q", options.EtcdCluster, cluster.ObjectMeta.Name)
+	}
+
+	configBase, err := clientset.ConfigBaseFor(cluster)
+	if err != nil {
+		return fmt.Errorf("error building ConfigBase for cluster: %w", err)
+	}
+
+	store, err := etcdBackupStore(f, cluster, configBase, options.EtcdCluster)
+	if err != nil {
+		return err
+	}
+	names, err := store.ListBackups(ctx)
+	if err != nil {
+		return fmt.Errorf("error listing the backups of etcd cluster %s: %w", options.EtcdCluster, err)
+	}
+	if !slices.Contains(names, options.Backup) {
+		return fmt.Errorf("backup %q not found in %s", options.Backup, store.Path())
+	}
+
+	spec, err := store.ReadClusterSpec(ctx)
+	if err != nil {
+		return err
+	}
+	if spec == nil {
+		spec = &etcdbackup.ClusterSpec{
+			MemberCount: int32(len(etcdClusterSpec.Members)),
+			EtcdVersion: etcdClusterSpec.Version,
+		}
+	}
+
+	if !options.Yes {
+		fmt.Fprintf(out, "Will restore etcd cluster %s of cluster %s from backup %s, with %d members running etcd %s.\n",
+			options.EtcdCluster, cluster.ObjectMeta.Name, options.Backup, spec.MemberCount, spec.EtcdVersion)
+		if options.Restart {
+			fmt.Fprintf(out, "The control plane nodes will then be replaced.\n")
+		}
+		fmt.Fprintf(out, "\nMust specify --yes to restore the backup\n")
+		return nil
+	}
+
+	p, err := store.AddRestoreCommand(ctx, options.Backup, spec, time.Now())
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Added restore command %s\n", p)
+
+	if !options.Restart {
+		fmt.Fprintf(out, "The backup will be restored once etcd-manager restarts on the control plane nodes.\n")
+		return nil
+	}
+
+	rollingUpdateOptions := &RollingUpdateOptions{}
+	rollingUpdateOptions.InitDefaults()
+	rollingUpdateOptions.ClusterName = cluster.ObjectMeta.Name
+	rollingUpdateOptions.InstanceGroupRoles = []string{kops.InstanceGroupRoleControlPlane.ToLowerString()}
+	rollingUpdateOptions.Yes = true
+	rollingUpdateOptions.Force = true
+	// The cluster does not validate while etcd is being restored.
+	rollingUpdateOptions.CloudOnly = true
+	if err := RunRollingUpdateCluster(ctx, f, out, rollingUpdateOptions); err != nil {
+		return fmt.Errorf("error restarting the control plane: %w", err)
+	}
+
+	validateOptions := &ValidateClusterOptions{}
+	validateOptions.InitDefaults()
+	validateOptions.ClusterName = cluster.ObjectMeta.Name
+	validateOptions.wait = options.ValidationTimeout
+	validateOptions.count = 2
+	result, err := RunValidateCluster(ctx, f, out, validateOptions)
+	if err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+	if len(result.Failures) != 0 {
+		return fmt.Errorf("cluster did not validate after restoring backup %s", options.Backup)
+	}
+	return nil
+}
+
+type ToolboxEtcdVerifyBackupOptions struct {
+	ClusterName string
+
+	// EtcdClusters are the names of the etcd clusters whose backups are verified, all of them if empty.
+	EtcdClusters []string
+	// Backup is the name of the backup to verify, the latest one if empty.
+	Backup string
+	// EtcdBinDir is the directory holding the etcd binaries, the PATH if empty.
+	EtcdBinDir string
+	// MetricsFile is the file to write metrics to, in the Prometheus text format.
+	MetricsFile string
+}
+
+func NewCmdToolboxEtcdVerifyBackup(f *util.Factory, out io.Writer) *cobra.Command {
+	options := &ToolboxEtcdVerifyBackupOptions{}
+
+	cmd := &cobra.Command{
+		Use:               "verify-backup [CLUSTER]",
+		Short:             toolboxEtcdVerifyBackupShort,
+		Long:              toolboxEtcdVerifyBackupLong,
+		Example:           toolboxEtcdVerifyBackupExample,
+		Args:              rootCommand.clusterNameArgs(&options.ClusterName),
+		ValidArgsFunction: commandutils.CompleteClusterName(f, true, false),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return RunToolboxEtcdVerifyBackup(cmd.Context(), f, out, options)
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&options.EtcdClusters, "etcd-cluster", options.EtcdClusters, "Names of the etcd clusters whose backups to verify; all of them if not set")
+	cmd.Flags().StringVar(&options.Backup, "backup", options.Backup, "Name of the backup to verify; the latest one if not set")
+	cmd.Flags().StringVar(&options.EtcdBinDir, "etcd-bin-dir", options.EtcdBinDir, "Directory holding the etcd binaries; the PATH if not set")
+	cmd.Flags().StringVar(&options.MetricsFile, "metrics-file", options.MetricsFile, "File to write the outcome to, in the Prometheus text format")
+
+	return cmd
+}
+
+func RunToolboxEtcdVerifyBackup(ctx context.Context, f *util.Factory, out io.Writer, options *ToolboxEtcdVerifyBackupOptions) error {
+	clientset, err := f.KopsClient()
+	if err != nil {
+		return err
+	}
+
+	cluster, err := GetCluster(ctx, f, options.ClusterName)
+	if err != nil {
+		return err
+	}
+
+	configBase, err := clientset.ConfigBaseFor(cluster)
+	if err != nil {
+		return fmt.Errorf("error building ConfigBase for cluster: %w", err)
+	}
+
+	etcdClusters := options.EtcdClusters
+	if len(etcdClusters) == 0 {
+		for _, etcdCluster := range cluster.Spec.EtcdClusters {
+			etcdClusters = append(etcdClusters, etcdCluster.Name)
+		}
+	}
+	if options.Backup != "" && len(etcdClusters) != 1 {
+		return fmt.Errorf("--backup requires a single --etcd-cluster")
+	}
+
+	verifier := &etcdbackup.Verifier{EtcdBinDir: options.EtcdBinDir}
+
+	var verifications []etcdbackup.Verification
+	var failed []string
+	for _, etcdCluster := range etcdClusters {
+		verification := etcdbackup.Verification{EtcdCluster: etcdCluster}
+		verification.Result, verification.Err = verifyEtcdBackup(ctx, f, cluster, configBase, etcdCluster, options.Backup, verifier)
+		if verification.Err != nil {
+			fmt.Fprintf(out, "Failed to verify the backup of etcd cluster %s: %v\n", etcdCluster, verification.Err)
+			failed = append(failed, etcdCluster)
+		} else {
+			result := verification.Result
+			fmt.Fprintf(out, "Verified backup %s of etcd cluster %s: %d keys at revision %d, %d bytes, restored in %v\n",
+				result.Backup, etcdCluster, result.Keys, result.Revision, result.Size, result.Duration.Round(time.Second))
+		}
+		verifications = append(verifications, verification)
+	}
+
+	if options.MetricsFile != "" {
+		var metrics bytes.Buffer
+		if err := etcdbackup.WriteMetrics(&metrics, verifications, time.Now()); err != nil {
+			return err
+		}
+		if err := os.WriteFile(options.MetricsFile, metrics.Bytes(), 0o644); err != nil {
+			return fmt.Errorf("error writing metrics: %w", err)
+		}
+	}
+
+	if len(failed) != 0 {
+		return fmt.Errorf("failed to verify the backups of etcd clusters %v", failed)
+	}
+	return nil
+}
+
+func verifyEtcdBackup(ctx context.Context, f *util.Factory, cluster *kops.Cluster, configBase vfs.Path, etcdCluster string, backup string, verifier *etcdbackup.Verifier) (*etcdbackup.VerifyResult, error) {
+	store, err := etcdBackupStore(f, cluster, configBase, etcdCluster)
+	if err != nil {
+		return nil, err
+	}
+	if backup == "" {
+		backup, err = store.LatestBackup(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return verifier.Verify(ctx, store, backup)
+}
+
+func etcdBackupStore(f *util.Factory, cluster *kops.Cluster, configBase vfs.Path, etcdCluster string) (*etcdbackup.Store, error) {
+	backupStore, err := etcdbackup.BackupStoreFor(cluster, configBase, etcdCluster)
+	if err != nil {
+		return nil, err
+	}
+	return etcdbackup.NewStore(f.VFSContext(), backupStore)
+}
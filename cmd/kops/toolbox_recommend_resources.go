@@ -0,0 +1,257 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/kops/cmd/kops/util"
+	"k8s.io/kops/pkg/commands"
+	"k8s.io/kops/pkg/commands/commandutils"
+	"k8s.io/kops/pkg/sizing"
+	"k8s.io/kops/upup/pkg/fi/cloudup"
+	"k8s.io/kops/util/pkg/tables"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+var (
+	toolboxRecommendResourcesLong = templates.LongDesc(i18n.T(`
+	Recommend the resource requests of the control plane components, and the size of the
+	control plane nodes, from their usage reported by metrics-server.
+
+	Usage is sampled every --interval for --samples times, 30 minutes by default, and the highest
+	samples are kept. These are point-in-time samples, not true peaks: metrics-server reports CPU
+	usage averaged over its resolution and the current memory working set, so spikes between
+	samples are missed. Run the command over a window covering the busiest times of the cluster.
+	Components that were killed for running out of memory get at least twice their current memory request.
+
+	With --apply, the recommended requests of kube-apiserver and etcd are set in the cluster spec.
+	Requests are only raised, unless --allow-decrease is also set. Machine types are not changed.
+
+	When the control plane nodes are too small, the smallest machine type that fits is suggested, among
+	the machine types of the cloud provider on AWS and Scaleway, and among those of the control plane
+	on other clouds.`))
+
+	toolboxRecommendResourcesExample = templates.Examples(i18n.T(`
+	# Recommend resources from a day of usage
+	kops toolbox recommend-resources --name k8s-cluster.example.com --state s3://my-state-store \
+		--samples 288 --interval 5m
+
+	# Raise the requests in the cluster spec to the recommended ones
+	kops toolbox recommend-resources --name k8s-cluster.example.com --state s3://my-state-store --apply
+
+	# Also lower the requests above the recommended ones
+	kops toolbox recommend-resources --name k8s-cluster.example.com --state s3://my-state-store --apply --allow-decrease
+	`))
+
+	toolboxRecommendResourcesShort = i18n.T(`Recommend control plane resources from observed usage.`)
+)
+
+type ToolboxRecommendResourcesOptions struct {
+	ClusterName string
+
+	// Samples is the number of times usage is sampled.
+	Samples int
+	// Interval is the time between samples.
+	Interval time.Duration
+	// Apply sets the recommended requests in the cluster spec.
+	Apply bool
+	// AllowDecrease lets Apply lower requests.
+	AllowDecrease bool
+}
+
+func (o *ToolboxRecommendResourcesOptions) InitDefaults() {
+	o.Samples = 60
+	o.Interval = 30 * time.Second
+}
+
+func NewCmdToolboxRecommendResources(f *util.Factory, out io.Writer) *cobra.Command {
+	options := &ToolboxRecommendResourcesOptions{}
+	options.InitDefaults()
+
+	cmd := &cobra.Command{
+		Use:               "recommend-resources [CLUSTER]",
+		Short:             toolboxRecommendResourcesShort,
+		Long:              toolboxRecommendResourcesLong,
+		Example:           toolboxRecommendResourcesExample,
+		Args:              rootCommand.clusterNameArgs(&options.ClusterName),
+		ValidArgsFunction: commandutils.CompleteClusterName(f, true, false),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return RunToolboxRecommendResources(cmd.Context(), f, out, options)
+		},
+	}
+
+	cmd.Flags().IntVar(&options.Samples, "samples", options.Samples, "Number of times to sample usage")
+	cmd.Flags().DurationVar(&options.Interval, "interval", options.Interval, "Time between samples")
+	cmd.Flags().BoolVar(&options.Apply, "apply", options.Apply, "Set the recommended requests in the cluster spec")
+	cmd.Flags().BoolVar(&options.AllowDecrease, "allow-decrease", options.AllowDecrease, "With --apply, also lower requests above the recommended ones")
+
+	return cmd
+}
+
+func RunToolboxRecommendResources(ctx context.Context, f *util.Factory, out io.Writer, options *ToolboxRecommendResourcesOptions) error {
+	if options.Samples < 1 {
+		return fmt.Errorf("--samples must be at least 1")
+	}
+	if options.AllowDecrease && !options.Apply {
+		return fmt.Errorf("--allow-decrease requires --apply")
+	}
+
+	clientset, err := f.KopsClient()
+	if err != nil {
+		return err
+	}
+
+	cluster, err := GetCluster(ctx, f, options.ClusterName)
+	if err != nil {
+		return err
+	}
+
+	// The machine types are listed before sampling, so that missing cloud credentials fail fast
+	cloud, err := cloudup.BuildCloud(cluster)
+	if err != nil {
+		return err
+	}
+	machineTypes, err := sizing.ListMachineTypes(ctx, cloud)
+	if err != nil {
+		return err
+	}
+
+	contextName := cluster.ObjectMeta.Name
+	clientGetter := genericclioptions.NewConfigFlags(true)
+	clientGetter.Context = &contextName
+
+	config, err := clientGetter.ToRESTConfig()
+	if err != nil {
+		return fmt.Errorf("cannot load kubecfg settings for %q: %w", contextName, err)
+	}
+	k8sClient, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return fmt.Errorf("cannot build kube client for %q: %w", contextName, err)
+	}
+
+	collector := &sizing.Collector{
+		Client:   k8sClient,
+		Samples:  options.Samples,
+		Interval: options.Interval,
+	}
+	usage, err := collector.Collect(ctx)
+	if err != nil {
+		return err
+	}
+
+	recommendation := sizing.Recommend(cluster, usage, machineTypes)
+
+	fmt.Fprintf(out, "Usage sampled %d times over %s; peaks are the highest point-in-time samples.\n\n",
+		options.Samples, time.Duration(options.Samples-1)*options.Interval)
+
+	{
+		t := &tables.Table{}
+		t.AddColumn("COMPONENT", func(r *sizing.ComponentRecommendation) string {
+			return r.Component
+		})
+		t.AddColumn("PEAK CPU", func(r *sizing.ComponentRecommendation) string {
+			return r.PeakCPU.String()
+		})
+		t.AddColumn("PEAK MEMORY", func(r *sizing.ComponentRecommendation) string {
+			return r.PeakMemory.String()
+		})
+		t.AddColumn("OOMKILLED", func(r *sizing.ComponentRecommendation) string {
+			return fmt.Sprintf("%t", r.OOMKilled)
+		})
+		t.AddColumn("CPU REQUEST", func(r *sizing.ComponentRecommendation) string {
+			return quantityChange(r.CurrentCPURequest, r.CPURequest.String(), r.Configurable, r.LowersCPURequest && !options.AllowDecrease)
+		})
+		t.AddColumn("MEMORY REQUEST", func(r *sizing.ComponentRecommendation) string {
+			return quantityChange(r.CurrentMemoryRequest, r.MemoryRequest.String(), r.Configurable, r.LowersMemoryRequest && !options.AllowDecrease)
+		})
+		if err := t.Render(recommendation.Components, out, "COMPONENT", "PEAK CPU", "PEAK MEMORY", "OOMKILLED", "CPU REQUEST", "MEMORY REQUEST"); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintf(out, "\n")
+
+	{
+		t := &tables.Table{}
+		t.AddColumn("INSTANCE GROUP", func(r *sizing.InstanceGroupRecommendation) string {
+			return r.InstanceGroup
+		})
+		t.AddColumn("MACHINE TYPE", func(r *sizing.InstanceGroupRecommendation) string {
+			return r.MachineType
+		})
+		t.AddColumn("ALLOCATABLE", func(r *sizing.InstanceGroupRecommendation) string {
+			return fmt.Sprintf("%s/%s", r.AllocatableCPU.String(), r.AllocatableMemory.String())
+		})
+		t.AddColumn("REQUIRED", func(r *sizing.InstanceGroupRecommendation) string {
+			return fmt.Sprintf("%s/%s", r.RequiredCPU.String(), r.RequiredMemory.String())
+		})
+		t.AddColumn("RECOMMENDATION", func(r *sizing.InstanceGroupRecommendation) string {
+			switch {
+			case r.Fits:
+				return "keep"
+			case r.SuggestedMachineType != "":
+				return "use " + r.SuggestedMachineType
+			default:
+				return "use a larger machine type"
+			}
+		})
+		if err := t.Render(recommendation.InstanceGroups, out, "INSTANCE GROUP", "MACHINE TYPE", "ALLOCATABLE", "REQUIRED", "RECOMMENDATION"); err != nil {
+			return err
+		}
+	}
+
+	if !options.Apply {
+		return nil
+	}
+
+	recommendation.Apply(cluster, options.AllowDecrease)
+
+	instanceGroups, err := commands.ReadAllInstanceGroups(ctx, clientset, cluster)
+	if err != nil {
+		return err
+	}
+	if err := commands.UpdateCluster(ctx, clientset, cluster, instanceGroups); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "\nUpdated the requests in the cluster spec; run \"kops update cluster --yes\" and \"kops rolling-update cluster --yes\" to apply them.\n")
+	return nil
+}
+
+// quantityChange describes the change of a request from its current value to the recommended one.
+func quantityChange(current *resource.Quantity, recommended string, configurable bool, kept bool) string {
+	if !configurable {
+		return recommended + " (not configurable)"
+	}
+	change := "default -> " + recommended
+	if current != nil {
+		change = current.String() + " -> " + recommended
+	}
+	if kept {
+		change += " (not lowered)"
+	}
+	return change
+}
@@ -349,18 +349,23 @@ func RunUpdateCluster(ctx context.Context, f *util.Factory, out io.Writer, c *Up
 			c.user,
 			c.internal,
 			f.KopsStateStore(),
-			useKopsAuthenticationPlugin)
+			useKopsAuthenticationPlugin,
+			nil)
 		if err != nil {
 			return nil, err
 		}
 
+		if err := recordAdminCredential(ctx, clientset, cluster, conf); err != nil {
+			return nil, err
+		}
+
 		err = conf.WriteKubecfg(clientcmd.NewDefaultPathOptions())
 		if err != nil {
 			return nil, err
 		}
 
 		if c.admin == 0 && c.user == "" {
-			klog.Warningf("Exported kubeconfig with no user authentication; use --admin, --user or --auth-plugin flags with `kops export kubeconfig`")
+			klog.Warningf("Exported kubeconfig with no user authentication; use --admin, --user, --auth-plugin or --oidc flags with `kops export kubeconfig`")
 		}
 	}
 
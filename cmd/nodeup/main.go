@@ -38,7 +38,8 @@ func main() {
 
 	var flagConf, flagCacheDir, gitVersion string
 	var flagRetries int
-	var dryrun, installSystemdUnit bool
+	var flagAuditOutput string
+	var dryrun, installSystemdUnit, inPlaceUpdate, audit, auditUpdateNode, renewCertificates bool
 	target := "direct"
 
 	if kops.GitVersion != "" {
@@ -51,6 +52,11 @@ func main() {
 	flag.BoolVar(&dryrun, "dryrun", false, "Don't create cloud resources; just show what would be done")
 	flag.StringVar(&target, "target", target, "Target - direct, dryrun")
 	flag.BoolVar(&installSystemdUnit, "install-systemd-unit", installSystemdUnit, "If true, will install a systemd unit instead of running directly")
+	flag.BoolVar(&inPlaceUpdate, "in-place-update", inPlaceUpdate, "If true, will apply configuration changes to a running node")
+	flag.BoolVar(&audit, "audit", audit, "If true, will report the differences between the node and its configuration, without changing the node")
+	flag.StringVar(&flagAuditOutput, "audit-output", "-", "the file the audit report is written to: - means stdout")
+	flag.BoolVar(&auditUpdateNode, "audit-update-node", auditUpdateNode, "If true, will set the KopsConfigurationDrift condition of the Node from the audit report")
+	flag.BoolVar(&renewCertificates, "renew-certificates", renewCertificates, "If true, will renew the node certificates issued by kops-controller that expire soon")
 
 	if dryrun {
 		target = "dryrun"
@@ -110,9 +116,14 @@ func main() {
 			}
 		} else {
 			cmd := &nodeup.NodeUpCommand{
-				ConfigLocation: flagConf,
-				Target:         target,
-				CacheDir:       flagCacheDir,
+				ConfigLocation:    flagConf,
+				Target:            target,
+				CacheDir:          flagCacheDir,
+				InPlaceUpdate:     inPlaceUpdate,
+				Audit:             audit,
+				AuditOutput:       flagAuditOutput,
+				AuditUpdateNode:   auditUpdateNode,
+				RenewCertificates: renewCertificates,
 			}
 			err = cmd.Run(os.Stdout)
 			if err == nil {
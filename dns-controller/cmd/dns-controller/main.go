@@ -26,6 +26,7 @@ import (
 
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/spf13/pflag"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	_ "k8s.io/component-base/metrics/prometheus/restclient" // for client metric registration
@@ -55,9 +56,11 @@ var (
 func main() {
 	fmt.Printf("dns-controller version %s\n", BuildVersion)
 	var dnsServer, dnsProviderID, gossipListen, gossipSecret, watchNamespace, metricsListen, gossipProtocol, gossipSecretSecondary, gossipListenSecondary, gossipProtocolSecondary string
-	var gossipSeeds, gossipSeedsSecondary, zones []string
-	var internalIpv4, internalIpv6 bool
-	var watchIngress bool
+	var gossipSeeds, gossipSeedsSecondary, zones, gossipSecretsAdditional []string
+	var internalIpv4, internalIpv6, gossipKeyset bool
+	var watchIngress, watchGatewayAPI bool
+	var txtOwnerID, txtPrefix string
+	var txtAdoptExisting bool
 	var updateInterval int
 
 	// Be sure to get the glog flags
@@ -66,6 +69,7 @@ func main() {
 
 	flag.StringVar(&dnsServer, "dns-server", "", "DNS Server")
 	flags.BoolVar(&watchIngress, "watch-ingress", true, "Configure hostnames found in ingress resources")
+	flags.BoolVar(&watchGatewayAPI, "watch-gateway-api", false, "Configure hostnames found in Gateway API resources (Gateways, HTTPRoutes and GRPCRoutes)")
 	flags.StringSliceVar(&gossipSeeds, "gossip-seed", gossipSeeds, "If set, will enable gossip zones and seed using the provided addresses")
 	flags.StringSliceVarP(&zones, "zone", "z", []string{}, "Configure permitted zones and their mappings")
 	flags.StringVar(&dnsProviderID, "dns", "aws-route53", "DNS provider we should use (aws-route53, google-clouddns, digitalocean, gossip, openstack-designate, scaleway)")
@@ -75,6 +79,8 @@ func main() {
 	flag.StringVar(&gossipProtocolSecondary, "gossip-protocol-secondary", "", "mesh/memberlist")
 	flag.StringVar(&gossipListenSecondary, "gossip-listen-secondary", fmt.Sprintf("0.0.0.0:%d", wellknownports.DNSControllerGossipMemberlist), "address:port on which to bind for gossip")
 	flags.StringVar(&gossipSecretSecondary, "gossip-secret-secondary", gossipSecret, "Secret to use to secure gossip")
+	flags.StringSliceVar(&gossipSecretsAdditional, "gossip-secret-additional", gossipSecretsAdditional, "Additional secrets accepted on incoming gossip, while rotating the gossip secret")
+	flags.BoolVar(&gossipKeyset, "gossip-keyset", gossipKeyset, "Set if the gossip secrets are managed as a gossip keyset, which seals memberlist gossip")
 	flags.StringSliceVar(&gossipSeedsSecondary, "gossip-seed-secondary", gossipSeedsSecondary, "If set, will enable gossip zones and seed using the provided addresses")
 	flags.BoolVar(&internalIpv4, "internal-ipv4", internalIpv4, "Internal network has IPv4")
 	flags.BoolVar(&internalIpv6, "internal-ipv6", internalIpv6, "Internal network has IPv6")
@@ -82,6 +88,9 @@ func main() {
 	flag.IntVar(&route53.MaxBatchSize, "route53-batch-size", route53.MaxBatchSize, "Maximum number of operations performed per changeset batch")
 	flag.StringVar(&metricsListen, "metrics-listen", "", "The address on which to listen for Prometheus metrics.")
 	flags.IntVar(&updateInterval, "update-interval", 5, "Configure interval at which to update DNS records.")
+	flags.StringVar(&txtOwnerID, "txt-owner-id", "", "If set, record ownership of managed records in TXT records with this owner id, and never change records owned by others")
+	flags.StringVar(&txtPrefix, "txt-prefix", "", "Prefix for the names of TXT ownership records")
+	flags.BoolVar(&txtAdoptExisting, "txt-adopt-existing", false, "Take ownership of existing records that do not have a TXT ownership record")
 
 	// Trick to avoid 'logging before flag.Parse' warning
 	flag.CommandLine.Parse([]string{})
@@ -126,6 +135,14 @@ func main() {
 		klog.Fatalf("error building REST client: %v", err)
 	}
 
+	var dynamicClient dynamic.Interface
+	if watchGatewayAPI {
+		dynamicClient, err = dynamic.NewForConfig(config)
+		if err != nil {
+			klog.Fatalf("error building dynamic client: %v", err)
+		}
+	}
+
 	var dnsProviders []dnsprovider.Interface
 	if dnsProviderID != "gossip" {
 		var file io.Reader
@@ -154,7 +171,7 @@ func main() {
 		channelName := "dns"
 		var gossipState gossip.GossipState
 
-		gossipState, err = gossip.GetGossipState(gossipProtocol, gossipListen, channelName, gossipName, []byte(gossipSecret), gossipSeeds)
+		gossipState, err = gossip.GetGossipState(gossipProtocol, gossipListen, channelName, gossipName, gossip.Secrets(gossipProtocol, gossipKeyset, gossipSecret, gossipSecretsAdditional), gossipSeeds)
 		if err != nil {
 			klog.Errorf("Error initializing gossip: %v", err)
 			os.Exit(1)
@@ -162,7 +179,7 @@ func main() {
 
 		if gossipProtocolSecondary != "" {
 
-			secondaryGossipState, err := gossip.GetGossipState(gossipProtocolSecondary, gossipListenSecondary, channelName, gossipName, []byte(gossipSecretSecondary), gossip.NewStaticSeedProvider(gossipSeedsSecondary))
+			secondaryGossipState, err := gossip.GetGossipState(gossipProtocolSecondary, gossipListenSecondary, channelName, gossipName, gossip.Secrets(gossipProtocolSecondary, gossipKeyset, gossipSecretSecondary, gossipSecretsAdditional), gossip.NewStaticSeedProvider(gossipSeedsSecondary))
 			if err != nil {
 				klog.Errorf("Error initializing secondary gossip: %v", err)
 				os.Exit(1)
@@ -195,14 +212,23 @@ func main() {
 		dnsProviders = append(dnsProviders, dnsProvider)
 	}
 
-	dnsController, err := dns.NewDNSController(dnsProviders, zoneRules, updateInterval)
+	var registry *dns.TXTRegistry
+	if txtOwnerID != "" {
+		registry = &dns.TXTRegistry{
+			OwnerID:       txtOwnerID,
+			Prefix:        txtPrefix,
+			AdoptExisting: txtAdoptExisting,
+		}
+	}
+
+	dnsController, err := dns.NewDNSController(dnsProviders, zoneRules, updateInterval, registry)
 	if err != nil {
 		klog.Errorf("Error building DNS controller: %v", err)
 		os.Exit(1)
 	}
 
 	// @step: initialize the watchers
-	if err := initializeWatchers(client, dnsController, watchNamespace, watchIngress, internalRecordTypes); err != nil {
+	if err := initializeWatchers(client, dynamicClient, dnsController, watchNamespace, watchIngress, watchGatewayAPI, internalRecordTypes); err != nil {
 		klog.Errorf("%s", err)
 		os.Exit(1)
 	}
@@ -212,7 +238,7 @@ func main() {
 }
 
 // initializeWatchers is responsible for creating the watchers
-func initializeWatchers(client kubernetes.Interface, dnsctl *dns.DNSController, namespace string, watchIngress bool, internalRecordTypes []dns.RecordType) error {
+func initializeWatchers(client kubernetes.Interface, dynamicClient dynamic.Interface, dnsctl *dns.DNSController, namespace string, watchIngress, watchGatewayAPI bool, internalRecordTypes []dns.RecordType) error {
 	klog.V(1).Infof("initializing the watch controllers, namespace: %q", namespace)
 
 	nodeController, err := watchers.NewNodeController(client, dnsctl, internalRecordTypes)
@@ -240,6 +266,27 @@ func initializeWatchers(client kubernetes.Interface, dnsctl *dns.DNSController,
 		klog.Infof("Ingress controller disabled")
 	}
 
+	// The Gateway API watchers skip resources whose CRDs are not installed, but watching them
+	// costs a list/watch per resource, so they must be explicitly enabled.
+	var gatewayController *watchers.GatewayController
+	var httpRouteController, grpcRouteController *watchers.GatewayRouteController
+	if watchGatewayAPI {
+		gatewayController, err = watchers.NewGatewayController(dynamicClient, dnsctl, namespace)
+		if err != nil {
+			return fmt.Errorf("failed to initialize the gateway controller, error: %v", err)
+		}
+		httpRouteController, err = watchers.NewHTTPRouteController(dynamicClient, dnsctl, namespace)
+		if err != nil {
+			return fmt.Errorf("failed to initialize the httproute controller, error: %v", err)
+		}
+		grpcRouteController, err = watchers.NewGRPCRouteController(dynamicClient, dnsctl, namespace)
+		if err != nil {
+			return fmt.Errorf("failed to initialize the grpcroute controller, error: %v", err)
+		}
+	} else {
+		klog.Infof("Gateway API controllers disabled")
+	}
+
 	go nodeController.Run()
 	go podController.Run()
 	go serviceController.Run()
@@ -248,5 +295,11 @@ func initializeWatchers(client kubernetes.Interface, dnsctl *dns.DNSController,
 		go ingressController.Run()
 	}
 
+	if watchGatewayAPI {
+		go gatewayController.Run()
+		go httpRouteController.Run()
+		go grpcRouteController.Run()
+	}
+
 	return nil
 }
@@ -62,6 +62,9 @@ type DNSController struct {
 	failCount uint64
 	// update loop frequency (seconds)
 	updateInterval time.Duration
+
+	// registry records ownership of managed records, if set
+	registry *TXTRegistry
 }
 
 // DNSController is a Context
@@ -88,7 +91,8 @@ type DNSControllerScope struct {
 var _ Scope = &DNSControllerScope{}
 
 // NewDNSController creates a DnsController
-func NewDNSController(dnsProviders []dnsprovider.Interface, zoneRules *ZoneRules, updateInterval int) (*DNSController, error) {
+// If registry is nil, ownership of records is not tracked, and all records with a matching name are managed.
+func NewDNSController(dnsProviders []dnsprovider.Interface, zoneRules *ZoneRules, updateInterval int, registry *TXTRegistry) (*DNSController, error) {
 	dnsCache, err := newDNSCache(dnsProviders)
 	if err != nil {
 		return nil, fmt.Errorf("error initializing DNS cache: %v", err)
@@ -99,6 +103,7 @@ func NewDNSController(dnsProviders []dnsprovider.Interface, zoneRules *ZoneRules
 		zoneRules:      zoneRules,
 		dnsCache:       dnsCache,
 		updateInterval: time.Duration(updateInterval) * time.Second,
+		registry:       registry,
 	}
 
 	return c, nil
@@ -251,7 +256,7 @@ func (c *DNSController) runOnce() error {
 		oldValueMap = c.lastSuccessfulSnapshot.recordValues
 	}
 
-	op, err := newDNSOp(c.zoneRules, c.dnsCache)
+	op, err := newDNSOp(c.zoneRules, c.dnsCache, c.registry)
 	if err != nil {
 		return err
 	}
@@ -342,7 +347,7 @@ func (c *DNSController) runOnce() error {
 func (c *DNSController) RemoveRecordsImmediate(records []Record) error {
 	ctx := context.TODO()
 
-	op, err := newDNSOp(c.zoneRules, c.dnsCache)
+	op, err := newDNSOp(c.zoneRules, c.dnsCache, c.registry)
 	if err != nil {
 		return err
 	}
@@ -381,13 +386,14 @@ func (c *DNSController) RemoveRecordsImmediate(records []Record) error {
 // dnsOp manages a single dns change; we cache results and state for the duration of the operation
 type dnsOp struct {
 	dnsCache     *dnsCache
+	registry     *TXTRegistry
 	zones        map[string]dnsprovider.Zone
 	recordsCache map[string][]dnsprovider.ResourceRecordSet
 
 	changesets map[string]dnsprovider.ResourceRecordChangeset
 }
 
-func newDNSOp(zoneRules *ZoneRules, dnsCache *dnsCache) (*dnsOp, error) {
+func newDNSOp(zoneRules *ZoneRules, dnsCache *dnsCache, registry *TXTRegistry) (*dnsOp, error) {
 	zones, err := dnsCache.ListZones(zoneListCacheValidity)
 	if err != nil {
 		return nil, fmt.Errorf("error querying for zones: %v", err)
@@ -424,6 +430,7 @@ func newDNSOp(zoneRules *ZoneRules, dnsCache *dnsCache) (*dnsOp, error) {
 
 	o := &dnsOp{
 		dnsCache:     dnsCache,
+		registry:     registry,
 		zones:        zoneMap,
 		changesets:   make(map[string]dnsprovider.ResourceRecordChangeset),
 		recordsCache: make(map[string][]dnsprovider.ResourceRecordSet),
@@ -515,6 +522,7 @@ func (o *dnsOp) deleteRecords(k recordKey) error {
 		return err
 	}
 
+	var matches []dnsprovider.ResourceRecordSet
 	for _, rr := range rrs {
 		rrName := EnsureDotSuffix(rr.Name())
 		if rrName != fqdn {
@@ -525,8 +533,25 @@ func (o *dnsOp) deleteRecords(k recordKey) error {
 			klog.V(8).Infof("Skipping delete of record %q (type %s != %s)", rrName, rr.Type(), k.RecordType)
 			continue
 		}
+		matches = append(matches, rr)
+	}
+
+	if o.registry != nil {
+		ownership, err := o.findOwnershipRecord(zone, k)
+		if err != nil {
+			return err
+		}
+		if !o.isOwned(k, ownership, len(matches) != 0) {
+			return nil
+		}
+		if ownership != nil {
+			klog.V(2).Infof("Deleting ownership record %s %s", ownership.Name(), ownership.Type())
+			cs.Remove(ownership)
+		}
+	}
 
-		klog.V(2).Infof("Deleting resource record %s %s", rrName, rr.Type())
+	for _, rr := range matches {
+		klog.V(2).Infof("Deleting resource record %s %s", rr.Name(), rr.Type())
 		cs.Remove(rr)
 	}
 
@@ -583,6 +608,20 @@ func (o *dnsOp) updateRecords(k recordKey, newRecords []string, ttl int64) error
 		return err
 	}
 
+	if o.registry != nil {
+		ownership, err := o.findOwnershipRecord(zone, k)
+		if err != nil {
+			return err
+		}
+		if !o.isOwned(k, ownership, existing != nil) {
+			return nil
+		}
+		if ownership == nil {
+			klog.V(2).Infof("Adding ownership record for %s to batch", k)
+			cs.Upsert(o.registry.newOwnershipRecord(rrsProvider, k, ttl))
+		}
+	}
+
 	klog.V(2).Infof("Adding DNS changes to batch %s %s", k, newRecords)
 	rr := rrsProvider.New(fqdn, newRecords, ttl, rrstype.RrsType(k.RecordType))
 	cs.Upsert(rr)
@@ -590,6 +629,42 @@ func (o *dnsOp) updateRecords(k recordKey, newRecords []string, ttl int64) error
 	return nil
 }
 
+// findOwnershipRecord returns the TXT record holding ownership of the specified record, or nil if there is none
+func (o *dnsOp) findOwnershipRecord(zone dnsprovider.Zone, k recordKey) (dnsprovider.ResourceRecordSet, error) {
+	rrs, err := o.listRecords(zone)
+	if err != nil {
+		return nil, fmt.Errorf("error querying resource records for zone %q: %v", zone.Name(), err)
+	}
+
+	name := o.registry.ownershipName(k)
+	for _, rr := range rrs {
+		if EnsureDotSuffix(rr.Name()) == name && rr.Type() == rrstype.TXT {
+			return rr, nil
+		}
+	}
+	return nil, nil
+}
+
+// isOwned checks whether we may change the specified record, given its ownership record (if any)
+// and whether the record itself exists. Records we may not change are logged and skipped, rather
+// than reported as errors, because retrying will not resolve the conflict.
+func (o *dnsOp) isOwned(k recordKey, ownership dnsprovider.ResourceRecordSet, exists bool) bool {
+	if ownership != nil {
+		owner := parseOwner(ownership)
+		if owner != o.registry.OwnerID {
+			klog.Warningf("Not changing records for %s: ownership record %s has owner %q, not %q", k, ownership.Name(), owner, o.registry.OwnerID)
+			return false
+		}
+		return true
+	}
+
+	if exists && !o.registry.AdoptExisting {
+		klog.Warningf("Not changing records for %s: records exist without an ownership record (set --txt-adopt-existing to take ownership)", k)
+		return false
+	}
+	return true
+}
+
 func (c *DNSController) recordChange() {
 	atomic.AddUint64(&c.changeCount, 1)
 }
@@ -0,0 +1,103 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dns
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/kops/dnsprovider/pkg/dnsprovider"
+	"k8s.io/kops/dnsprovider/pkg/dnsprovider/rrstype"
+)
+
+const (
+	// ownershipHeritage is the heritage label written into ownership records.
+	// We use the same labels as the external-dns TXT registry, so that ownership is preserved
+	// when a cluster switches between dns-controller and external-dns with the same owner id.
+	ownershipHeritage = "external-dns"
+
+	ownershipLabelHeritage = "heritage"
+	ownershipLabelOwner    = "external-dns/owner"
+)
+
+// TXTRegistry records ownership of the records managed by dns-controller in TXT records.
+// Each managed A/AAAA/CNAME record has a TXT record alongside it, named <prefix><type>-<name>,
+// that identifies the owner. Records that are owned by someone else are never changed or deleted.
+type TXTRegistry struct {
+	// OwnerID identifies this dns-controller; it must be unique amongst the users of a zone
+	OwnerID string
+	// Prefix is prepended to the names of ownership records
+	Prefix string
+	// AdoptExisting takes ownership of existing records that have no ownership record.
+	// This is intended for migrating records created before ownership records were in use.
+	AdoptExisting bool
+}
+
+// ownershipName returns the name of the TXT record holding ownership of the specified record
+func (r *TXTRegistry) ownershipName(k recordKey) string {
+	fqdn := EnsureDotSuffix(k.FQDN)
+
+	// A TXT record cannot usefully be named "a-*.example.com", so we replace the wildcard label
+	if strings.HasPrefix(fqdn, "*.") {
+		fqdn = "wildcard" + fqdn[1:]
+	}
+	return r.Prefix + strings.ToLower(string(k.RecordType)) + "-" + fqdn
+}
+
+// ownershipValue returns the value of the TXT record identifying us as owner
+func (r *TXTRegistry) ownershipValue() string {
+	return fmt.Sprintf("%q", ownershipLabelHeritage+"="+ownershipHeritage+","+ownershipLabelOwner+"="+r.OwnerID)
+}
+
+// newOwnershipRecord builds the TXT record identifying us as owner of the specified record
+func (r *TXTRegistry) newOwnershipRecord(rrsProvider dnsprovider.ResourceRecordSets, k recordKey, ttl int64) dnsprovider.ResourceRecordSet {
+	return rrsProvider.New(r.ownershipName(k), []string{r.ownershipValue()}, ttl, rrstype.TXT)
+}
+
+// OwnershipName returns the name of the TXT record holding ownership of the record with the specified name and type
+func (r *TXTRegistry) OwnershipName(fqdn string, recordType RecordType) string {
+	return r.ownershipName(recordKey{RecordType: recordType, FQDN: fqdn})
+}
+
+// NewOwnershipRecord builds the TXT record identifying us as owner of the record with the specified name and type.
+// kops uses it to claim the records it pre-creates for the dns-controller of the cluster.
+func (r *TXTRegistry) NewOwnershipRecord(rrsProvider dnsprovider.ResourceRecordSets, fqdn string, recordType RecordType, ttl int64) dnsprovider.ResourceRecordSet {
+	return r.newOwnershipRecord(rrsProvider, recordKey{RecordType: recordType, FQDN: fqdn}, ttl)
+}
+
+// parseOwner returns the owner recorded in an ownership TXT record, or "" if it is not an ownership record
+func parseOwner(rr dnsprovider.ResourceRecordSet) string {
+	for _, value := range rr.Rrdatas() {
+		value = strings.Trim(value, "\"")
+
+		labels := make(map[string]string)
+		for _, token := range strings.Split(value, ",") {
+			k, v, found := strings.Cut(token, "=")
+			if !found {
+				continue
+			}
+			labels[k] = v
+		}
+		if labels[ownershipLabelHeritage] != ownershipHeritage {
+			continue
+		}
+		if owner := labels[ownershipLabelOwner]; owner != "" {
+			return owner
+		}
+	}
+	return ""
+}
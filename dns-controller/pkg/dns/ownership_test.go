@@ -0,0 +1,257 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dns
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsroute53 "github.com/aws/aws-sdk-go-v2/service/route53"
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/kops/dnsprovider/pkg/dnsprovider"
+	"k8s.io/kops/dnsprovider/pkg/dnsprovider/providers/aws/route53"
+	route53testing "k8s.io/kops/dnsprovider/pkg/dnsprovider/providers/aws/route53/stubs"
+	"k8s.io/kops/dnsprovider/pkg/dnsprovider/rrstype"
+)
+
+const (
+	ourOwnership   = `"heritage=external-dns,external-dns/owner=kops-a"`
+	otherOwnership = `"heritage=external-dns,external-dns/owner=kops-b"`
+)
+
+type testRecord struct {
+	Name    string
+	Type    rrstype.RrsType
+	Rrdatas string
+}
+
+// buildTestZone builds a fake route53 zone for example.com, populated with the specified records
+func buildTestZone(t *testing.T, records []testRecord) (dnsprovider.Interface, dnsprovider.ResourceRecordSets) {
+	ctx := context.Background()
+
+	service := route53testing.NewRoute53APIStub()
+	if _, err := service.CreateHostedZone(ctx, &awsroute53.CreateHostedZoneInput{
+		CallerReference: aws.String("Nonce"),
+		Name:            aws.String("example.com."),
+	}); err != nil {
+		t.Fatalf("error creating zone: %v", err)
+	}
+	provider := route53.New(service)
+
+	zonesProvider, _ := provider.Zones()
+	zones, err := zonesProvider.List()
+	if err != nil {
+		t.Fatalf("error listing zones: %v", err)
+	}
+	rrsProvider, _ := zones[0].ResourceRecordSets()
+
+	if len(records) != 0 {
+		cs := rrsProvider.StartChangeset()
+		for _, r := range records {
+			cs.Add(rrsProvider.New(r.Name, []string{r.Rrdatas}, 60, r.Type))
+		}
+		if err := cs.Apply(ctx); err != nil {
+			t.Fatalf("error creating records: %v", err)
+		}
+	}
+
+	return provider, rrsProvider
+}
+
+func listTestZone(t *testing.T, rrsProvider dnsprovider.ResourceRecordSets) []testRecord {
+	rrs, err := rrsProvider.List()
+	if err != nil {
+		t.Fatalf("error listing records: %v", err)
+	}
+	var records []testRecord
+	for _, rr := range rrs {
+		records = append(records, testRecord{
+			Name:    EnsureDotSuffix(rr.Name()),
+			Type:    rr.Type(),
+			Rrdatas: strings.Join(rr.Rrdatas(), ","),
+		})
+	}
+	sort.Slice(records, func(i, j int) bool {
+		if records[i].Name != records[j].Name {
+			return records[i].Name < records[j].Name
+		}
+		return records[i].Type < records[j].Type
+	})
+	return records
+}
+
+func TestTXTRegistry(t *testing.T) {
+	grid := []struct {
+		name          string
+		adoptExisting bool
+		existing      []testRecord
+		desired       []Record
+		want          []testRecord
+		// wantAfterDelete is the zone contents after the desired records are removed
+		wantAfterDelete []testRecord
+	}{
+		{
+			name: "new records are created with an ownership record",
+			desired: []Record{
+				{RecordType: RecordTypeA, FQDN: "api.example.com.", Value: "10.0.0.1"},
+			},
+			want: []testRecord{
+				{Name: "a-api.example.com.", Type: rrstype.TXT, Rrdatas: ourOwnership},
+				{Name: "api.example.com.", Type: rrstype.A, Rrdatas: "10.0.0.1"},
+			},
+		},
+		{
+			name: "owned records are updated",
+			existing: []testRecord{
+				{Name: "a-api.example.com.", Type: rrstype.TXT, Rrdatas: ourOwnership},
+				{Name: "api.example.com.", Type: rrstype.A, Rrdatas: "10.0.0.2"},
+			},
+			desired: []Record{
+				{RecordType: RecordTypeA, FQDN: "api.example.com.", Value: "10.0.0.1"},
+			},
+			want: []testRecord{
+				{Name: "a-api.example.com.", Type: rrstype.TXT, Rrdatas: ourOwnership},
+				{Name: "api.example.com.", Type: rrstype.A, Rrdatas: "10.0.0.1"},
+			},
+		},
+		{
+			name: "records owned by others are not changed",
+			existing: []testRecord{
+				{Name: "a-api.example.com.", Type: rrstype.TXT, Rrdatas: otherOwnership},
+				{Name: "api.example.com.", Type: rrstype.A, Rrdatas: "10.0.0.2"},
+			},
+			desired: []Record{
+				{RecordType: RecordTypeA, FQDN: "api.example.com.", Value: "10.0.0.1"},
+			},
+			want: []testRecord{
+				{Name: "a-api.example.com.", Type: rrstype.TXT, Rrdatas: otherOwnership},
+				{Name: "api.example.com.", Type: rrstype.A, Rrdatas: "10.0.0.2"},
+			},
+			wantAfterDelete: []testRecord{
+				{Name: "a-api.example.com.", Type: rrstype.TXT, Rrdatas: otherOwnership},
+				{Name: "api.example.com.", Type: rrstype.A, Rrdatas: "10.0.0.2"},
+			},
+		},
+		{
+			name: "unowned records are not changed",
+			existing: []testRecord{
+				{Name: "api.example.com.", Type: rrstype.A, Rrdatas: "10.0.0.2"},
+			},
+			desired: []Record{
+				{RecordType: RecordTypeA, FQDN: "api.example.com.", Value: "10.0.0.1"},
+			},
+			want: []testRecord{
+				{Name: "api.example.com.", Type: rrstype.A, Rrdatas: "10.0.0.2"},
+			},
+			wantAfterDelete: []testRecord{
+				{Name: "api.example.com.", Type: rrstype.A, Rrdatas: "10.0.0.2"},
+			},
+		},
+		{
+			name:          "unowned records are adopted",
+			adoptExisting: true,
+			existing: []testRecord{
+				{Name: "api.example.com.", Type: rrstype.A, Rrdatas: "10.0.0.2"},
+			},
+			desired: []Record{
+				{RecordType: RecordTypeA, FQDN: "api.example.com.", Value: "10.0.0.1"},
+			},
+			want: []testRecord{
+				{Name: "a-api.example.com.", Type: rrstype.TXT, Rrdatas: ourOwnership},
+				{Name: "api.example.com.", Type: rrstype.A, Rrdatas: "10.0.0.1"},
+			},
+		},
+		{
+			name:          "records owned by others are not adopted",
+			adoptExisting: true,
+			existing: []testRecord{
+				{Name: "a-api.example.com.", Type: rrstype.TXT, Rrdatas: otherOwnership},
+				{Name: "api.example.com.", Type: rrstype.A, Rrdatas: "10.0.0.2"},
+			},
+			desired: []Record{
+				{RecordType: RecordTypeA, FQDN: "api.example.com.", Value: "10.0.0.1"},
+			},
+			want: []testRecord{
+				{Name: "a-api.example.com.", Type: rrstype.TXT, Rrdatas: otherOwnership},
+				{Name: "api.example.com.", Type: rrstype.A, Rrdatas: "10.0.0.2"},
+			},
+			wantAfterDelete: []testRecord{
+				{Name: "a-api.example.com.", Type: rrstype.TXT, Rrdatas: otherOwnership},
+				{Name: "api.example.com.", Type: rrstype.A, Rrdatas: "10.0.0.2"},
+			},
+		},
+		{
+			name: "wildcard records",
+			desired: []Record{
+				{RecordType: RecordTypeCNAME, FQDN: "*.apps.example.com.", Value: "lb.example.net"},
+			},
+			want: []testRecord{
+				{Name: "*.apps.example.com.", Type: rrstype.CNAME, Rrdatas: "lb.example.net"},
+				{Name: "cname-wildcard.apps.example.com.", Type: rrstype.TXT, Rrdatas: ourOwnership},
+			},
+		},
+	}
+
+	for _, g := range grid {
+		t.Run(g.name, func(t *testing.T) {
+			provider, rrsProvider := buildTestZone(t, g.existing)
+
+			zoneRules, err := ParseZoneRules([]string{"*"})
+			if err != nil {
+				t.Fatalf("error parsing zone rules: %v", err)
+			}
+			registry := &TXTRegistry{
+				OwnerID:       "kops-a",
+				AdoptExisting: g.adoptExisting,
+			}
+			c, err := NewDNSController([]dnsprovider.Interface{provider}, zoneRules, 1, registry)
+			if err != nil {
+				t.Fatalf("error building controller: %v", err)
+			}
+
+			scope, err := c.CreateScope("test")
+			if err != nil {
+				t.Fatalf("error creating scope: %v", err)
+			}
+			scope.Replace("test", g.desired)
+			scope.MarkReady()
+
+			if err := c.runOnce(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			got := listTestZone(t, rrsProvider)
+			if diff := cmp.Diff(g.want, got); diff != "" {
+				t.Errorf("unexpected records; diff=%s", diff)
+			}
+
+			// Removing the desired records should remove only the records we own
+			scope.Replace("test", nil)
+			if err := c.runOnce(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			got = listTestZone(t, rrsProvider)
+			if diff := cmp.Diff(g.wantAfterDelete, got); diff != "" {
+				t.Errorf("unexpected records after delete; diff=%s", diff)
+			}
+		})
+	}
+}
@@ -46,6 +46,11 @@ func AliasForNodesInRole(role, roleType string) string {
 	return "node/role=" + role + "/" + roleType
 }
 
+// AliasForGateway returns the alias for the addresses of a Gateway API Gateway
+func AliasForGateway(namespace, name string) string {
+	return "gateway/" + namespace + "/" + name
+}
+
 func (r *Record) String() string {
 	s := "Record:[Type=" + string(r.RecordType) + ",FQDN=" + r.FQDN + ",Value=" + r.Value
 
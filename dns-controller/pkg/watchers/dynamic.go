@@ -0,0 +1,153 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package watchers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/klog/v2"
+	"k8s.io/kops/dns-controller/pkg/dns"
+)
+
+// notServedRetryInterval is how often we check whether a resource that is not served
+// (typically because its CRD is not installed) has become available.
+var notServedRetryInterval = time.Minute
+
+// dynamicWatcher lists and watches a resource through the dynamic client, keeping a dns scope in sync.
+type dynamicWatcher struct {
+	client    dynamic.Interface
+	resource  schema.GroupVersionResource
+	namespace string
+	scope     dns.Scope
+
+	// update applies the records for the object to the scope, and returns the key that was set.
+	update func(u *unstructured.Unstructured) string
+}
+
+// run lists and watches the resource until stopCh is closed.
+// If the resource is not served by the apiserver, the scope is emptied and marked ready,
+// so that a missing CRD does not block the other scopes, and we periodically check again.
+func (w *dynamicWatcher) run(stopCh <-chan struct{}) {
+	notServedLogged := false
+
+	runOnce := func() (bool, error) {
+		ctx := context.TODO()
+
+		var listOpts metav1.ListOptions
+		klog.V(4).Infof("querying without label filter")
+
+		allKeys := w.scope.AllKeys()
+		list, err := w.client.Resource(w.resource).Namespace(w.namespace).List(ctx, listOpts)
+		if err != nil {
+			if !apierrors.IsNotFound(err) {
+				return false, fmt.Errorf("error listing %s: %v", w.resource.Resource, err)
+			}
+
+			if !notServedLogged {
+				klog.Infof("%s are not served by the apiserver (%v); will check again every %v", w.resource.GroupResource(), err, notServedRetryInterval)
+				notServedLogged = true
+			}
+			for _, key := range allKeys {
+				w.scope.Replace(key, nil)
+			}
+			w.scope.MarkReady()
+
+			select {
+			case <-stopCh:
+				klog.Infof("Got stop signal")
+				return true, nil
+			case <-time.After(notServedRetryInterval):
+				return false, nil
+			}
+		}
+		notServedLogged = false
+
+		foundKeys := make(map[string]bool)
+		for i := range list.Items {
+			u := &list.Items[i]
+			klog.V(4).Infof("found %s: %v", w.resource.Resource, u.GetName())
+			key := w.update(u)
+			foundKeys[key] = true
+		}
+		for _, key := range allKeys {
+			if !foundKeys[key] {
+				// The object previously existed, but no longer exists; delete it from the scope
+				klog.V(2).Infof("removing %s not found in list: %s", w.resource.Resource, key)
+				w.scope.Replace(key, nil)
+			}
+		}
+		w.scope.MarkReady()
+
+		listOpts.Watch = true
+		listOpts.ResourceVersion = list.GetResourceVersion()
+		watcher, err := w.client.Resource(w.resource).Namespace(w.namespace).Watch(ctx, listOpts)
+		if err != nil {
+			return false, fmt.Errorf("error watching %s: %v", w.resource.Resource, err)
+		}
+		ch := watcher.ResultChan()
+		for {
+			select {
+			case <-stopCh:
+				klog.Infof("Got stop signal")
+				return true, nil
+			case event, ok := <-ch:
+				if !ok {
+					klog.Infof("%s watch channel closed", w.resource.Resource)
+					return false, nil
+				}
+
+				if event.Type == watch.Error {
+					return false, fmt.Errorf("error from %s watch: %v", w.resource.Resource, event.Object)
+				}
+
+				u := event.Object.(*unstructured.Unstructured)
+				klog.V(4).Infof("%s changed: %s %v", w.resource.Resource, event.Type, u.GetName())
+
+				switch event.Type {
+				case watch.Added, watch.Modified:
+					w.update(u)
+
+				case watch.Deleted:
+					w.scope.Replace(u.GetNamespace()+"/"+u.GetName(), nil)
+
+				default:
+					klog.Warningf("Unknown event type: %v", event.Type)
+				}
+			}
+		}
+	}
+
+	for {
+		stop, err := runOnce()
+		if stop {
+			return
+		}
+
+		if err != nil {
+			klog.Warningf("Unexpected error in event watch, will retry: %v", err)
+			time.Sleep(10 * time.Second)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package watchers
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/klog/v2"
+	"k8s.io/kops/dns-controller/pkg/dns"
+	"k8s.io/kops/dns-controller/pkg/util"
+	"k8s.io/kops/upup/pkg/fi/utils"
+	gatewayapi "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// GatewayResource is the Gateway API resource for Gateways
+var GatewayResource = schema.GroupVersionResource{Group: gatewayapi.GroupName, Version: "v1", Resource: "gateways"}
+
+// GatewayController watches for Gateway API Gateways.
+// It publishes the addresses of each Gateway as an alias target for routes attached to it,
+// and creates records for the dns annotations on the Gateway.
+type GatewayController struct {
+	util.Stoppable
+	client    dynamic.Interface
+	namespace string
+	scope     dns.Scope
+}
+
+// NewGatewayController creates a GatewayController
+func NewGatewayController(client dynamic.Interface, dns dns.Context, namespace string) (*GatewayController, error) {
+	scope, err := dns.CreateScope("gateway")
+	if err != nil {
+		return nil, fmt.Errorf("error building dns scope: %v", err)
+	}
+	c := &GatewayController{
+		client:    client,
+		namespace: namespace,
+		scope:     scope,
+	}
+
+	return c, nil
+}
+
+// Run starts the GatewayController.
+func (c *GatewayController) Run() {
+	klog.Infof("starting gateway controller")
+
+	stopCh := c.StopChannel()
+	go c.runWatcher(stopCh)
+
+	<-stopCh
+	klog.Infof("shutting down gateway controller")
+}
+
+func (c *GatewayController) runWatcher(stopCh <-chan struct{}) {
+	w := &dynamicWatcher{
+		client:    c.client,
+		resource:  GatewayResource,
+		namespace: c.namespace,
+		scope:     c.scope,
+		update:    c.updateGatewayRecords,
+	}
+	w.run(stopCh)
+}
+
+// updateGatewayRecords will apply the records for the specified gateway.  It returns the key that was set.
+func (c *GatewayController) updateGatewayRecords(u *unstructured.Unstructured) string {
+	key := u.GetNamespace() + "/" + u.GetName()
+
+	gateway := &gatewayapi.Gateway{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.UnstructuredContent(), gateway); err != nil {
+		klog.Warningf("error parsing gateway %s: %v", key, err)
+		c.scope.Replace(key, nil)
+		return key
+	}
+
+	var records []dns.Record
+
+	alias := dns.AliasForGateway(gateway.Namespace, gateway.Name)
+	for _, address := range gateway.Status.Addresses {
+		addressType := gatewayapi.IPAddressType
+		if address.Type != nil {
+			addressType = *address.Type
+		}
+
+		switch addressType {
+		case gatewayapi.IPAddressType:
+			var recordType dns.RecordType = dns.RecordTypeA
+			if utils.IsIPv6IP(address.Value) {
+				recordType = dns.RecordTypeAAAA
+			}
+			records = append(records, dns.Record{
+				RecordType:  recordType,
+				FQDN:        alias,
+				Value:       address.Value,
+				AliasTarget: true,
+			})
+		case gatewayapi.HostnameAddressType:
+			records = append(records, dns.Record{
+				RecordType:  dns.RecordTypeCNAME,
+				FQDN:        alias,
+				Value:       address.Value,
+				AliasTarget: true,
+			})
+		default:
+			klog.V(2).Infof("ignoring address of type %q for gateway %s", addressType, key)
+		}
+	}
+
+	for _, token := range annotationTokens(gateway.Annotations) {
+		records = append(records, dns.Record{
+			RecordType: dns.RecordTypeAlias,
+			FQDN:       dns.EnsureDotSuffix(token),
+			Value:      alias,
+		})
+	}
+
+	c.scope.Replace(key, records)
+	return key
+}
+
+// annotationTokens returns the names listed in the external and internal dns annotations.
+func annotationTokens(annotations map[string]string) []string {
+	var tokens []string
+	for _, annotation := range []string{AnnotationNameDNSExternal, AnnotationNameDNSInternal} {
+		spec := annotations[annotation]
+		if spec == "" {
+			continue
+		}
+		for _, token := range strings.Split(spec, ",") {
+			token = strings.TrimSpace(token)
+			if token != "" {
+				tokens = append(tokens, token)
+			}
+		}
+	}
+	return tokens
+}
@@ -0,0 +1,236 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package watchers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/kops/dns-controller/pkg/dns"
+	"sigs.k8s.io/yaml"
+)
+
+// newFakeDynamicClient builds a fake dynamic client holding the given objects.
+// Objects are created through the client, because the resource names guessed by the fake
+// object tracker do not match the Gateway API resources (e.g. "gatewaies").
+func newFakeDynamicClient(t *testing.T, resource schema.GroupVersionResource, objects ...string) *fake.FakeDynamicClient {
+	ctx := context.Background()
+
+	listKinds := map[schema.GroupVersionResource]string{
+		GatewayResource:   "GatewayList",
+		HTTPRouteResource: "HTTPRouteList",
+		GRPCRouteResource: "GRPCRouteList",
+	}
+	client := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds)
+
+	for _, s := range objects {
+		u := &unstructured.Unstructured{}
+		if err := yaml.Unmarshal([]byte(s), &u.Object); err != nil {
+			t.Fatalf("error parsing object: %v", err)
+		}
+		if _, err := client.Resource(resource).Namespace(u.GetNamespace()).Create(ctx, u, metav1.CreateOptions{}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	return client
+}
+
+func runUntilReady(t *testing.T, c interface {
+	Run()
+	Stop() error
+}, ch chan struct{}) {
+	go c.Run()
+
+	select {
+	case <-ch:
+	case <-time.After(time.Second):
+		t.Fatalf("update was not marked as complete")
+	}
+
+	c.Stop()
+}
+
+func TestGatewayController(t *testing.T) {
+	client := newFakeDynamicClient(t, GatewayResource, `
+apiVersion: gateway.networking.k8s.io/v1
+kind: Gateway
+metadata:
+  name: gw
+  namespace: infra
+  annotations:
+    dns.alpha.kubernetes.io/external: gw.foo.com
+spec:
+  gatewayClassName: example
+  listeners:
+  - name: http
+    port: 80
+    protocol: HTTP
+status:
+  addresses:
+  - value: 10.0.0.1
+  - type: IPAddress
+    value: 2001:db8::1
+  - type: Hostname
+    value: lb.example.com
+  - type: NamedAddress
+    value: some-name
+`)
+
+	ch := make(chan struct{})
+	scope := &fakeScope{
+		readyCh: ch,
+		records: make(map[string][]dns.Record),
+	}
+
+	c, err := NewGatewayController(client, &fakeDNSContext{scope: scope}, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	runUntilReady(t, c, ch)
+
+	want := map[string][]dns.Record{
+		"infra/gw": {
+			{RecordType: "A", FQDN: "gateway/infra/gw", Value: "10.0.0.1", AliasTarget: true},
+			{RecordType: "AAAA", FQDN: "gateway/infra/gw", Value: "2001:db8::1", AliasTarget: true},
+			{RecordType: "CNAME", FQDN: "gateway/infra/gw", Value: "lb.example.com", AliasTarget: true},
+			{RecordType: "_alias", FQDN: "gw.foo.com.", Value: "gateway/infra/gw"},
+		},
+	}
+	if diff := cmp.Diff(scope.records, want); diff != "" {
+		t.Fatalf("generated records did not match expected; diff=%s", diff)
+	}
+}
+
+func TestHTTPRouteController(t *testing.T) {
+	client := newFakeDynamicClient(t, HTTPRouteResource, `
+apiVersion: gateway.networking.k8s.io/v1
+kind: HTTPRoute
+metadata:
+  name: accepted
+  namespace: app
+  annotations:
+    dns.alpha.kubernetes.io/internal: internal.foo.com
+spec:
+  parentRefs:
+  - name: gw
+    namespace: infra
+  hostnames:
+  - www.foo.com
+  - "*.foo.com"
+status:
+  parents:
+  - controllerName: example.com/gateway
+    parentRef:
+      group: gateway.networking.k8s.io
+      kind: Gateway
+      name: gw
+      namespace: infra
+    conditions:
+    - type: Accepted
+      status: "True"
+      reason: Accepted
+      message: ""
+      lastTransitionTime: "2024-01-01T00:00:00Z"
+  - controllerName: example.com/gateway
+    parentRef:
+      name: other
+    conditions:
+    - type: Accepted
+      status: "False"
+      reason: NotAllowedByListeners
+      message: ""
+      lastTransitionTime: "2024-01-01T00:00:00Z"
+  - controllerName: example.com/mesh
+    parentRef:
+      group: ""
+      kind: Service
+      name: svc
+    conditions:
+    - type: Accepted
+      status: "True"
+      reason: Accepted
+      message: ""
+      lastTransitionTime: "2024-01-01T00:00:00Z"
+`, `
+apiVersion: gateway.networking.k8s.io/v1
+kind: HTTPRoute
+metadata:
+  name: pending
+  namespace: app
+spec:
+  parentRefs:
+  - name: gw
+  hostnames:
+  - pending.foo.com
+`)
+
+	ch := make(chan struct{})
+	scope := &fakeScope{
+		readyCh: ch,
+		records: make(map[string][]dns.Record),
+	}
+
+	c, err := NewHTTPRouteController(client, &fakeDNSContext{scope: scope}, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	runUntilReady(t, c, ch)
+
+	want := map[string][]dns.Record{
+		"app/accepted": {
+			{RecordType: "_alias", FQDN: "internal.foo.com.", Value: "gateway/infra/gw"},
+			{RecordType: "_alias", FQDN: "www.foo.com.", Value: "gateway/infra/gw"},
+		},
+		"app/pending": nil,
+	}
+	if diff := cmp.Diff(scope.records, want); diff != "" {
+		t.Fatalf("generated records did not match expected; diff=%s", diff)
+	}
+}
+
+func TestGatewayRouteControllerNotServed(t *testing.T) {
+	client := newFakeDynamicClient(t, GRPCRouteResource)
+	client.PrependReactor("list", "grpcroutes", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewNotFound(GRPCRouteResource.GroupResource(), "")
+	})
+
+	ch := make(chan struct{})
+	scope := &fakeScope{
+		readyCh: ch,
+		records: make(map[string][]dns.Record),
+	}
+
+	c, err := NewGRPCRouteController(client, &fakeDNSContext{scope: scope}, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	runUntilReady(t, c, ch)
+
+	if len(scope.records) != 0 {
+		t.Fatalf("expected no records, got %v", scope.records)
+	}
+}
@@ -0,0 +1,181 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package watchers
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/klog/v2"
+	"k8s.io/kops/dns-controller/pkg/dns"
+	"k8s.io/kops/dns-controller/pkg/util"
+	gatewayapi "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+var (
+	// HTTPRouteResource is the Gateway API resource for HTTPRoutes
+	HTTPRouteResource = schema.GroupVersionResource{Group: gatewayapi.GroupName, Version: "v1", Resource: "httproutes"}
+	// GRPCRouteResource is the Gateway API resource for GRPCRoutes.
+	// GRPCRoute is only served as v1alpha2 (in the experimental channel) by the Gateway API version we support.
+	GRPCRouteResource = schema.GroupVersionResource{Group: gatewayapi.GroupName, Version: "v1alpha2", Resource: "grpcroutes"}
+)
+
+// gatewayRoute holds the fields that HTTPRoute and GRPCRoute have in common, and that we need to create records.
+type gatewayRoute struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec struct {
+		gatewayapi.CommonRouteSpec `json:",inline"`
+		Hostnames                  []gatewayapi.Hostname `json:"hostnames,omitempty"`
+	} `json:"spec"`
+
+	Status gatewayapi.RouteStatus `json:"status,omitempty"`
+}
+
+// GatewayRouteController watches for Gateway API routes (HTTPRoutes or GRPCRoutes).
+// It creates records for the route hostnames and dns annotations, pointing to the addresses of the
+// Gateways that have accepted the route.
+type GatewayRouteController struct {
+	util.Stoppable
+	client    dynamic.Interface
+	namespace string
+	scope     dns.Scope
+	resource  schema.GroupVersionResource
+}
+
+// NewHTTPRouteController creates a GatewayRouteController for HTTPRoutes
+func NewHTTPRouteController(client dynamic.Interface, dns dns.Context, namespace string) (*GatewayRouteController, error) {
+	return newGatewayRouteController(client, dns, namespace, HTTPRouteResource)
+}
+
+// NewGRPCRouteController creates a GatewayRouteController for GRPCRoutes
+func NewGRPCRouteController(client dynamic.Interface, dns dns.Context, namespace string) (*GatewayRouteController, error) {
+	return newGatewayRouteController(client, dns, namespace, GRPCRouteResource)
+}
+
+func newGatewayRouteController(client dynamic.Interface, dns dns.Context, namespace string, resource schema.GroupVersionResource) (*GatewayRouteController, error) {
+	scope, err := dns.CreateScope(resource.Resource)
+	if err != nil {
+		return nil, fmt.Errorf("error building dns scope: %v", err)
+	}
+	c := &GatewayRouteController{
+		client:    client,
+		namespace: namespace,
+		scope:     scope,
+		resource:  resource,
+	}
+
+	return c, nil
+}
+
+// Run starts the GatewayRouteController.
+func (c *GatewayRouteController) Run() {
+	klog.Infof("starting %s controller", c.resource.Resource)
+
+	stopCh := c.StopChannel()
+	go c.runWatcher(stopCh)
+
+	<-stopCh
+	klog.Infof("shutting down %s controller", c.resource.Resource)
+}
+
+func (c *GatewayRouteController) runWatcher(stopCh <-chan struct{}) {
+	w := &dynamicWatcher{
+		client:    c.client,
+		resource:  c.resource,
+		namespace: c.namespace,
+		scope:     c.scope,
+		update:    c.updateRouteRecords,
+	}
+	w.run(stopCh)
+}
+
+// updateRouteRecords will apply the records for the specified route.  It returns the key that was set.
+func (c *GatewayRouteController) updateRouteRecords(u *unstructured.Unstructured) string {
+	key := u.GetNamespace() + "/" + u.GetName()
+
+	route := &gatewayRoute{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.UnstructuredContent(), route); err != nil {
+		klog.Warningf("error parsing %s %s: %v", c.resource.Resource, key, err)
+		c.scope.Replace(key, nil)
+		return key
+	}
+
+	tokens := annotationTokens(route.Annotations)
+	for _, hostname := range route.Spec.Hostnames {
+		if strings.HasPrefix(string(hostname), "*") {
+			klog.V(2).Infof("skipping wildcard hostname %q for %s %s", hostname, c.resource.Resource, key)
+			continue
+		}
+		tokens = append(tokens, string(hostname))
+	}
+
+	var records []dns.Record
+	for _, alias := range acceptedGatewayAliases(route) {
+		for _, token := range tokens {
+			records = append(records, dns.Record{
+				RecordType: dns.RecordTypeAlias,
+				FQDN:       dns.EnsureDotSuffix(token),
+				Value:      alias,
+			})
+		}
+	}
+
+	c.scope.Replace(key, records)
+	return key
+}
+
+// acceptedGatewayAliases returns the aliases of the parent Gateways that have accepted the route.
+// We rely on the status reported by the Gateway implementation, so that a route cannot publish
+// names for a Gateway that does not allow it to attach.
+func acceptedGatewayAliases(route *gatewayRoute) []string {
+	var aliases []string
+	seen := make(map[string]bool)
+	for i := range route.Status.Parents {
+		parent := &route.Status.Parents[i]
+		parentRef := &parent.ParentRef
+
+		if parentRef.Group != nil && string(*parentRef.Group) != gatewayapi.GroupName {
+			continue
+		}
+		if parentRef.Kind != nil && string(*parentRef.Kind) != "Gateway" {
+			continue
+		}
+		if !meta.IsStatusConditionTrue(parent.Conditions, string(gatewayapi.RouteConditionAccepted)) {
+			klog.V(4).Infof("route %s/%s is not accepted by gateway %s", route.Namespace, route.Name, parentRef.Name)
+			continue
+		}
+
+		namespace := route.Namespace
+		if parentRef.Namespace != nil {
+			namespace = string(*parentRef.Namespace)
+		}
+		alias := dns.AliasForGateway(namespace, string(parentRef.Name))
+		if !seen[alias] {
+			seen[alias] = true
+			aliases = append(aliases, alias)
+		}
+	}
+	return aliases
+}
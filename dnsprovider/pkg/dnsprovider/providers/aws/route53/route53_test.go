@@ -23,12 +23,14 @@ import (
 	"os"
 	"testing"
 
+	"k8s.io/kops/cloudmock/aws/mockroute53"
 	"k8s.io/kops/dnsprovider/pkg/dnsprovider"
 	route53testing "k8s.io/kops/dnsprovider/pkg/dnsprovider/providers/aws/route53/stubs"
 	"k8s.io/kops/dnsprovider/pkg/dnsprovider/rrstype"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/route53"
+	route53types "github.com/aws/aws-sdk-go-v2/service/route53/types"
 	"k8s.io/kops/dnsprovider/pkg/dnsprovider/tests"
 )
 
@@ -302,3 +304,16 @@ func TestContract(t *testing.T) {
 
 	tests.TestContract(t, sets)
 }
+
+// TestConformance runs the dnsprovider conformance tests against the route53 cloudmock
+func TestConformance(t *testing.T) {
+	service := &mockroute53.MockRoute53{}
+	service.MockCreateZone(&route53types.HostedZone{
+		Id:   aws.String("/hostedzone/Z0"),
+		Name: aws.String("example.com."),
+	}, nil)
+
+	tests.TestConformance(t, New(service), tests.ConformanceOptions{
+		ZoneName: "example.com",
+	})
+}
@@ -89,7 +89,7 @@ func (r *Route53APIStub) ChangeResourceRecordSets(ctx context.Context, input *ro
 			}
 			delete(recordSets, key)
 		case route53types.ChangeActionUpsert:
-			// TODO - not used yet
+			recordSets[key] = []route53types.ResourceRecordSet{*change.ResourceRecordSet}
 		}
 	}
 	r.recordSets[*input.HostedZoneId] = recordSets
@@ -20,13 +20,17 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
+	"sort"
+	"strings"
 	"testing"
 
 	"github.com/digitalocean/godo"
 
 	"k8s.io/kops/dnsprovider/pkg/dnsprovider/rrstype"
+	"k8s.io/kops/dnsprovider/pkg/dnsprovider/tests"
 )
 
 type fakeDomainService struct {
@@ -487,3 +491,175 @@ func TestResourceRecordChangeset(t *testing.T) {
 		t.Errorf("error applying changeset: %v", err)
 	}
 }
+
+// memoryDomainService is an in-memory implementation of godo.DomainsService, used for the conformance tests.
+// Like the DigitalOcean API, it stores individual records rather than record sets, with names relative to the domain,
+// and returns records in pages.
+type memoryDomainService struct {
+	domains map[string][]godo.DomainRecord
+	nextID  int
+}
+
+var _ godo.DomainsService = &memoryDomainService{}
+
+// memoryPageSize is smaller than the page size of the DigitalOcean API, so that the conformance tests exercise pagination
+const memoryPageSize = 20
+
+func newMemoryDomainService(domains ...string) *memoryDomainService {
+	f := &memoryDomainService{
+		domains: make(map[string][]godo.DomainRecord),
+	}
+	for _, domain := range domains {
+		f.domains[domain] = nil
+	}
+	return f
+}
+
+// relativeName returns the name of a record relative to the domain, as stored by the DigitalOcean API
+func (f *memoryDomainService) relativeName(domain, name string) string {
+	name = strings.TrimSuffix(name, ".")
+	if name == domain {
+		return "@"
+	}
+	return strings.TrimSuffix(name, "."+domain)
+}
+
+func (f *memoryDomainService) List(ctx context.Context, listOpt *godo.ListOptions) ([]godo.Domain, *godo.Response, error) {
+	var domains []godo.Domain
+	for name := range f.domains {
+		domains = append(domains, godo.Domain{Name: name})
+	}
+	sort.Slice(domains, func(i, j int) bool { return domains[i].Name < domains[j].Name })
+	return domains, &godo.Response{Links: &godo.Links{}}, nil
+}
+
+func (f *memoryDomainService) Get(ctx context.Context, name string) (*godo.Domain, *godo.Response, error) {
+	if _, found := f.domains[name]; !found {
+		return nil, nil, fmt.Errorf("domain %q not found", name)
+	}
+	return &godo.Domain{Name: name}, &godo.Response{}, nil
+}
+
+func (f *memoryDomainService) Create(ctx context.Context, domainCreateRequest *godo.DomainCreateRequest) (*godo.Domain, *godo.Response, error) {
+	if _, found := f.domains[domainCreateRequest.Name]; found {
+		return nil, nil, fmt.Errorf("domain %q already exists", domainCreateRequest.Name)
+	}
+	f.domains[domainCreateRequest.Name] = nil
+	return &godo.Domain{Name: domainCreateRequest.Name}, &godo.Response{}, nil
+}
+
+func (f *memoryDomainService) Delete(ctx context.Context, name string) (*godo.Response, error) {
+	if _, found := f.domains[name]; !found {
+		return nil, fmt.Errorf("domain %q not found", name)
+	}
+	delete(f.domains, name)
+	return &godo.Response{}, nil
+}
+
+func (f *memoryDomainService) Records(ctx context.Context, domain string, listOpts *godo.ListOptions) ([]godo.DomainRecord, *godo.Response, error) {
+	records, found := f.domains[domain]
+	if !found {
+		return nil, nil, fmt.Errorf("domain %q not found", domain)
+	}
+
+	page := 1
+	if listOpts != nil && listOpts.Page > 0 {
+		page = listOpts.Page
+	}
+	start := (page - 1) * memoryPageSize
+	if start > len(records) {
+		start = len(records)
+	}
+	end := start + memoryPageSize
+	if end > len(records) {
+		end = len(records)
+	}
+
+	pages := &godo.Pages{}
+	if page > 1 {
+		pages.Prev = fmt.Sprintf("https://api.digitalocean.com/v2/domains/%s/records?page=%d", domain, page-1)
+	}
+	if end < len(records) {
+		pages.Next = fmt.Sprintf("https://api.digitalocean.com/v2/domains/%s/records?page=%d", domain, page+1)
+	}
+	return append([]godo.DomainRecord(nil), records[start:end]...), &godo.Response{Links: &godo.Links{Pages: pages}}, nil
+}
+
+func (f *memoryDomainService) RecordsByType(ctx context.Context, domain string, ofType string, listOpts *godo.ListOptions) ([]godo.DomainRecord, *godo.Response, error) {
+	return nil, nil, errors.New("not implemented")
+}
+
+func (f *memoryDomainService) RecordsByName(ctx context.Context, domain string, name string, listOpts *godo.ListOptions) ([]godo.DomainRecord, *godo.Response, error) {
+	return nil, nil, errors.New("not implemented")
+}
+
+func (f *memoryDomainService) RecordsByTypeAndName(ctx context.Context, domain string, ofType string, name string, listOpts *godo.ListOptions) ([]godo.DomainRecord, *godo.Response, error) {
+	return nil, nil, errors.New("not implemented")
+}
+
+func (f *memoryDomainService) Record(ctx context.Context, domain string, id int) (*godo.DomainRecord, *godo.Response, error) {
+	for _, record := range f.domains[domain] {
+		if record.ID == id {
+			return &record, &godo.Response{}, nil
+		}
+	}
+	return nil, nil, fmt.Errorf("record %d not found in domain %q", id, domain)
+}
+
+func (f *memoryDomainService) DeleteRecord(ctx context.Context, domain string, id int) (*godo.Response, error) {
+	records := f.domains[domain]
+	for i, record := range records {
+		if record.ID == id {
+			f.domains[domain] = append(records[:i:i], records[i+1:]...)
+			return &godo.Response{}, nil
+		}
+	}
+	return nil, fmt.Errorf("record %d not found in domain %q", id, domain)
+}
+
+func (f *memoryDomainService) EditRecord(ctx context.Context, domain string, id int, editRequest *godo.DomainRecordEditRequest) (*godo.DomainRecord, *godo.Response, error) {
+	records := f.domains[domain]
+	for i := range records {
+		if records[i].ID == id {
+			records[i].Name = f.relativeName(domain, editRequest.Name)
+			records[i].Type = editRequest.Type
+			records[i].Data = editRequest.Data
+			records[i].TTL = editRequest.TTL
+			record := records[i]
+			return &record, &godo.Response{}, nil
+		}
+	}
+	return nil, nil, fmt.Errorf("record %d not found in domain %q", id, domain)
+}
+
+func (f *memoryDomainService) CreateRecord(ctx context.Context, domain string, createRequest *godo.DomainRecordEditRequest) (*godo.DomainRecord, *godo.Response, error) {
+	if _, found := f.domains[domain]; !found {
+		return nil, nil, fmt.Errorf("domain %q not found", domain)
+	}
+	f.nextID++
+	record := godo.DomainRecord{
+		ID:   f.nextID,
+		Name: f.relativeName(domain, createRequest.Name),
+		Type: createRequest.Type,
+		Data: createRequest.Data,
+		TTL:  createRequest.TTL,
+	}
+	f.domains[domain] = append(f.domains[domain], record)
+	return &record, &godo.Response{}, nil
+}
+
+// TestConformance runs the dnsprovider conformance tests against an in-memory DigitalOcean API
+func TestConformance(t *testing.T) {
+	client := godo.NewClient(nil)
+	client.Domains = newMemoryDomainService("example.com")
+
+	tests.TestConformance(t, NewProvider(client), tests.ConformanceOptions{
+		ZoneName: "example.com",
+		KnownFailures: map[string]string{
+			"AddAndList":         "List merges records with the same name but different types into one record set",
+			"Get":                "List merges records with the same name but different types into one record set",
+			"AddDuplicateFails":  "Add replaces the existing records with the same name instead of failing",
+			"RemoveMissingFails": "Remove ignores records that do not exist",
+		},
+	})
+}
@@ -279,3 +279,14 @@ func TestContract(t *testing.T) {
 
 	tests.TestContract(t, sets)
 }
+
+// TestConformance runs the dnsprovider conformance tests against the clouddns stubs
+func TestConformance(t *testing.T) {
+	provider, err := NewFakeInterface()
+	if err != nil {
+		t.Fatalf("error building fake interface: %v", err)
+	}
+	tests.TestConformance(t, provider, tests.ConformanceOptions{
+		ZoneName: "example.com",
+	})
+}
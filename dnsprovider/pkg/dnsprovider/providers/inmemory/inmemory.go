@@ -0,0 +1,350 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package inmemory is an in-memory implementation of dnsprovider.Interface.
+// It is the reference implementation for the conformance tests in dnsprovider/pkg/dnsprovider/tests,
+// and can be used in unit tests that need a DNS provider.
+package inmemory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"k8s.io/kops/dnsprovider/pkg/dnsprovider"
+	"k8s.io/kops/dnsprovider/pkg/dnsprovider/rrstype"
+)
+
+// Interface is an in-memory implementation of dnsprovider.Interface
+type Interface struct {
+	// mutex protects the following mutable state
+	mutex sync.Mutex
+	// zones holds the records of each zone, keyed by zone name
+	zones map[string]map[recordKey]*ResourceRecordSet
+}
+
+var _ dnsprovider.Interface = &Interface{}
+
+type recordKey struct {
+	Name string
+	Type rrstype.RrsType
+}
+
+// New returns a new in-memory DNS provider, with the specified (empty) zones
+func New(zoneNames ...string) *Interface {
+	i := &Interface{
+		zones: make(map[string]map[recordKey]*ResourceRecordSet),
+	}
+	for _, zoneName := range zoneNames {
+		i.zones[zoneName] = make(map[recordKey]*ResourceRecordSet)
+	}
+	return i
+}
+
+// Zones implements dnsprovider.Interface
+func (i *Interface) Zones() (dnsprovider.Zones, bool) {
+	return &Zones{iface: i}, true
+}
+
+// Zones implements dnsprovider.Zones
+type Zones struct {
+	iface *Interface
+}
+
+var _ dnsprovider.Zones = &Zones{}
+
+// List implements dnsprovider.Zones
+func (z *Zones) List() ([]dnsprovider.Zone, error) {
+	z.iface.mutex.Lock()
+	defer z.iface.mutex.Unlock()
+
+	var names []string
+	for name := range z.iface.zones {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var zones []dnsprovider.Zone
+	for _, name := range names {
+		zones = append(zones, &Zone{name: name, zones: z})
+	}
+	return zones, nil
+}
+
+// Add implements dnsprovider.Zones
+func (z *Zones) Add(zone dnsprovider.Zone) (dnsprovider.Zone, error) {
+	z.iface.mutex.Lock()
+	defer z.iface.mutex.Unlock()
+
+	name := zone.Name()
+	if _, found := z.iface.zones[name]; found {
+		return nil, fmt.Errorf("zone %q already exists", name)
+	}
+	z.iface.zones[name] = make(map[recordKey]*ResourceRecordSet)
+	return &Zone{name: name, zones: z}, nil
+}
+
+// Remove implements dnsprovider.Zones
+func (z *Zones) Remove(zone dnsprovider.Zone) error {
+	z.iface.mutex.Lock()
+	defer z.iface.mutex.Unlock()
+
+	name := zone.Name()
+	if _, found := z.iface.zones[name]; !found {
+		return fmt.Errorf("zone %q not found", name)
+	}
+	delete(z.iface.zones, name)
+	return nil
+}
+
+// New implements dnsprovider.Zones
+func (z *Zones) New(name string) (dnsprovider.Zone, error) {
+	return &Zone{name: name, zones: z}, nil
+}
+
+// Zone implements dnsprovider.Zone
+type Zone struct {
+	name  string
+	zones *Zones
+}
+
+var _ dnsprovider.Zone = &Zone{}
+
+// Name implements dnsprovider.Zone
+func (z *Zone) Name() string {
+	return z.name
+}
+
+// ID implements dnsprovider.Zone; zones are identified by name
+func (z *Zone) ID() string {
+	return z.name
+}
+
+// ResourceRecordSets implements dnsprovider.Zone
+func (z *Zone) ResourceRecordSets() (dnsprovider.ResourceRecordSets, bool) {
+	return &ResourceRecordSets{zone: z}, true
+}
+
+// records returns the records of the zone; the caller must hold the mutex
+func (z *Zone) records() (map[recordKey]*ResourceRecordSet, error) {
+	records, found := z.zones.iface.zones[z.name]
+	if !found {
+		return nil, fmt.Errorf("zone %q not found", z.name)
+	}
+	return records, nil
+}
+
+// ResourceRecordSets implements dnsprovider.ResourceRecordSets
+type ResourceRecordSets struct {
+	zone *Zone
+}
+
+var _ dnsprovider.ResourceRecordSets = &ResourceRecordSets{}
+
+// List implements dnsprovider.ResourceRecordSets
+func (r *ResourceRecordSets) List() ([]dnsprovider.ResourceRecordSet, error) {
+	return r.list(func(*ResourceRecordSet) bool { return true })
+}
+
+// Get implements dnsprovider.ResourceRecordSets
+func (r *ResourceRecordSets) Get(name string) ([]dnsprovider.ResourceRecordSet, error) {
+	return r.list(func(rrs *ResourceRecordSet) bool { return rrs.name == name })
+}
+
+func (r *ResourceRecordSets) list(filter func(*ResourceRecordSet) bool) ([]dnsprovider.ResourceRecordSet, error) {
+	iface := r.zone.zones.iface
+	iface.mutex.Lock()
+	defer iface.mutex.Unlock()
+
+	records, err := r.zone.records()
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []*ResourceRecordSet
+	for _, rrs := range records {
+		if filter(rrs) {
+			matches = append(matches, rrs)
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].name != matches[j].name {
+			return matches[i].name < matches[j].name
+		}
+		return matches[i].rrsType < matches[j].rrsType
+	})
+
+	var list []dnsprovider.ResourceRecordSet
+	for _, rrs := range matches {
+		list = append(list, rrs.copy())
+	}
+	return list, nil
+}
+
+// New implements dnsprovider.ResourceRecordSets
+func (r *ResourceRecordSets) New(name string, rrdatas []string, ttl int64, rrsType rrstype.RrsType) dnsprovider.ResourceRecordSet {
+	return &ResourceRecordSet{
+		name:    name,
+		rrdatas: append([]string(nil), rrdatas...),
+		ttl:     ttl,
+		rrsType: rrsType,
+	}
+}
+
+// StartChangeset implements dnsprovider.ResourceRecordSets
+func (r *ResourceRecordSets) StartChangeset() dnsprovider.ResourceRecordChangeset {
+	return &ResourceRecordChangeset{rrsets: r}
+}
+
+// Zone implements dnsprovider.ResourceRecordSets
+func (r *ResourceRecordSets) Zone() dnsprovider.Zone {
+	return r.zone
+}
+
+// ResourceRecordSet implements dnsprovider.ResourceRecordSet
+type ResourceRecordSet struct {
+	name    string
+	rrdatas []string
+	ttl     int64
+	rrsType rrstype.RrsType
+}
+
+var _ dnsprovider.ResourceRecordSet = &ResourceRecordSet{}
+
+// Name implements dnsprovider.ResourceRecordSet
+func (r *ResourceRecordSet) Name() string {
+	return r.name
+}
+
+// Rrdatas implements dnsprovider.ResourceRecordSet
+func (r *ResourceRecordSet) Rrdatas() []string {
+	return r.rrdatas
+}
+
+// Ttl implements dnsprovider.ResourceRecordSet
+func (r *ResourceRecordSet) Ttl() int64 {
+	return r.ttl
+}
+
+// Type implements dnsprovider.ResourceRecordSet
+func (r *ResourceRecordSet) Type() rrstype.RrsType {
+	return r.rrsType
+}
+
+func (r *ResourceRecordSet) key() recordKey {
+	return recordKey{Name: r.name, Type: r.rrsType}
+}
+
+func (r *ResourceRecordSet) copy() *ResourceRecordSet {
+	c := *r
+	c.rrdatas = append([]string(nil), r.rrdatas...)
+	return &c
+}
+
+func copyFrom(rrs dnsprovider.ResourceRecordSet) *ResourceRecordSet {
+	return &ResourceRecordSet{
+		name:    rrs.Name(),
+		rrdatas: append([]string(nil), rrs.Rrdatas()...),
+		ttl:     rrs.Ttl(),
+		rrsType: rrs.Type(),
+	}
+}
+
+// ResourceRecordChangeset implements dnsprovider.ResourceRecordChangeset
+type ResourceRecordChangeset struct {
+	rrsets *ResourceRecordSets
+
+	additions []dnsprovider.ResourceRecordSet
+	removals  []dnsprovider.ResourceRecordSet
+	upserts   []dnsprovider.ResourceRecordSet
+}
+
+var _ dnsprovider.ResourceRecordChangeset = &ResourceRecordChangeset{}
+
+// Add implements dnsprovider.ResourceRecordChangeset
+func (c *ResourceRecordChangeset) Add(rrs dnsprovider.ResourceRecordSet) dnsprovider.ResourceRecordChangeset {
+	c.additions = append(c.additions, rrs)
+	return c
+}
+
+// Remove implements dnsprovider.ResourceRecordChangeset
+func (c *ResourceRecordChangeset) Remove(rrs dnsprovider.ResourceRecordSet) dnsprovider.ResourceRecordChangeset {
+	c.removals = append(c.removals, rrs)
+	return c
+}
+
+// Upsert implements dnsprovider.ResourceRecordChangeset
+func (c *ResourceRecordChangeset) Upsert(rrs dnsprovider.ResourceRecordSet) dnsprovider.ResourceRecordChangeset {
+	c.upserts = append(c.upserts, rrs)
+	return c
+}
+
+// Apply implements dnsprovider.ResourceRecordChangeset.
+// Changes are applied atomically: removals first, then additions, then upserts.
+// Removing a record that does not exist, or adding a record that already exists, fails the whole changeset.
+func (c *ResourceRecordChangeset) Apply(ctx context.Context) error {
+	if c.IsEmpty() {
+		return nil
+	}
+
+	iface := c.rrsets.zone.zones.iface
+	iface.mutex.Lock()
+	defer iface.mutex.Unlock()
+
+	records, err := c.rrsets.zone.records()
+	if err != nil {
+		return err
+	}
+
+	// Apply the changes to a copy, so that we do not make partial changes on error
+	updated := make(map[recordKey]*ResourceRecordSet, len(records))
+	for k, v := range records {
+		updated[k] = v
+	}
+
+	for _, removal := range c.removals {
+		rrs := copyFrom(removal)
+		if _, found := updated[rrs.key()]; !found {
+			return fmt.Errorf("cannot remove record %s %q: not found", rrs.rrsType, rrs.name)
+		}
+		delete(updated, rrs.key())
+	}
+	for _, addition := range c.additions {
+		rrs := copyFrom(addition)
+		if _, found := updated[rrs.key()]; found {
+			return fmt.Errorf("cannot add record %s %q: already exists", rrs.rrsType, rrs.name)
+		}
+		updated[rrs.key()] = rrs
+	}
+	for _, upsert := range c.upserts {
+		rrs := copyFrom(upsert)
+		updated[rrs.key()] = rrs
+	}
+
+	iface.zones[c.rrsets.zone.name] = updated
+	return nil
+}
+
+// IsEmpty implements dnsprovider.ResourceRecordChangeset
+func (c *ResourceRecordChangeset) IsEmpty() bool {
+	return len(c.removals) == 0 && len(c.additions) == 0 && len(c.upserts) == 0
+}
+
+// ResourceRecordSets implements dnsprovider.ResourceRecordChangeset
+func (c *ResourceRecordChangeset) ResourceRecordSets() dnsprovider.ResourceRecordSets {
+	return c.rrsets
+}
@@ -0,0 +1,30 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inmemory
+
+import (
+	"testing"
+
+	"k8s.io/kops/dnsprovider/pkg/dnsprovider/tests"
+)
+
+// TestConformance verifies that the reference implementation passes the conformance tests
+func TestConformance(t *testing.T) {
+	tests.TestConformance(t, New("example.com"), tests.ConformanceOptions{
+		ZoneName: "example.com",
+	})
+}
@@ -17,8 +17,11 @@ limitations under the License.
 package designate
 
 import (
+	"context"
 	"testing"
 
+	"k8s.io/kops/cloudmock/openstack/mockdns"
+	"k8s.io/kops/dnsprovider/pkg/dnsprovider/rrstype"
 	"k8s.io/kops/dnsprovider/pkg/dnsprovider/tests"
 )
 
@@ -28,3 +31,76 @@ func TestContract(t *testing.T) {
 
 	tests.TestContract(t, sets)
 }
+
+// TestConformance runs the dnsprovider conformance tests against the designate cloudmock
+func TestConformance(t *testing.T) {
+	client := mockdns.CreateClient()
+	defer client.TeardownHTTP()
+
+	provider := New(client.ServiceClient())
+	zones, _ := provider.Zones()
+	zone, err := zones.New("example.com.")
+	if err != nil {
+		t.Fatalf("error building zone: %v", err)
+	}
+	if _, err := zones.Add(zone); err != nil {
+		t.Fatalf("error adding zone: %v", err)
+	}
+
+	tests.TestConformance(t, provider, tests.ConformanceOptions{
+		ZoneName: "example.com",
+	})
+}
+
+// TestChangesetRecordSetTypes checks that record sets are looked up by name and type,
+// as a name commonly has both A and AAAA record sets in dual-stack clusters
+func TestChangesetRecordSetTypes(t *testing.T) {
+	ctx := context.Background()
+	client := mockdns.CreateClient()
+	defer client.TeardownHTTP()
+
+	provider := New(client.ServiceClient())
+	zones, _ := provider.Zones()
+	zone, err := zones.New("example.com.")
+	if err != nil {
+		t.Fatalf("error building zone: %v", err)
+	}
+	if zone, err = zones.Add(zone); err != nil {
+		t.Fatalf("error adding zone: %v", err)
+	}
+	sets, _ := zone.ResourceRecordSets()
+
+	a := sets.New("api.example.com.", []string{"192.0.2.1"}, 60, rrstype.A)
+	aaaa := sets.New("api.example.com.", []string{"2001:db8::1"}, 60, rrstype.AAAA)
+	if err := sets.StartChangeset().Add(a).Add(aaaa).Apply(ctx); err != nil {
+		t.Fatalf("error adding records: %v", err)
+	}
+
+	updated := sets.New("api.example.com.", []string{"2001:db8::2"}, 120, rrstype.AAAA)
+	created := sets.New("new.example.com.", []string{"192.0.2.2"}, 60, rrstype.A)
+	if err := sets.StartChangeset().Remove(a).Upsert(updated).Upsert(created).Apply(ctx); err != nil {
+		t.Fatalf("error applying changeset: %v", err)
+	}
+
+	list, err := sets.List()
+	if err != nil {
+		t.Fatalf("error listing records: %v", err)
+	}
+	got := make(map[string]string)
+	for _, r := range list {
+		got[r.Name()+" "+string(r.Type())] = r.Rrdatas()[0]
+	}
+	want := map[string]string{
+		"api.example.com. AAAA": "2001:db8::2",
+		"new.example.com. A":    "192.0.2.2",
+	}
+	if len(got) != len(want) {
+		t.Errorf("unexpected records: got %v, want %v", got, want)
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("unexpected records: got %v, want %v", got, want)
+			break
+		}
+	}
+}
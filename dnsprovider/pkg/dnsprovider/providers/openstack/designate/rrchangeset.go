@@ -23,6 +23,7 @@ import (
 	"github.com/gophercloud/gophercloud/openstack/dns/v2/recordsets"
 
 	"k8s.io/kops/dnsprovider/pkg/dnsprovider"
+	"k8s.io/kops/dnsprovider/pkg/dnsprovider/rrstype"
 )
 
 var _ dnsprovider.ResourceRecordChangeset = &ResourceRecordChangeset{}
@@ -59,11 +60,16 @@ func (c *ResourceRecordChangeset) Apply(ctx context.Context) error {
 
 	zoneID := c.zone.impl.ID
 
+	// Designate has no batch API: each change is sent in its own request, removals first,
+	// so a record set can be removed and added again in the same changeset
 	for _, removal := range c.removals {
-		rrID, err := c.nameToID(removal.Name())
+		rrID, err := c.findRecordSetID(removal.Name(), removal.Type())
 		if err != nil {
 			return err
 		}
+		if rrID == "" {
+			return fmt.Errorf("couldn't find recordset with name: %s and type: %s to remove", removal.Name(), removal.Type())
+		}
 		err = recordsets.Delete(c.zone.zones.iface.sc, zoneID, rrID).ExtractErr()
 		if err != nil {
 			return err
@@ -84,17 +90,11 @@ func (c *ResourceRecordChangeset) Apply(ctx context.Context) error {
 	}
 
 	for _, upsert := range c.upserts {
-		rrID, err := c.nameToID(upsert.Name())
+		rrID, err := c.findRecordSetID(upsert.Name(), upsert.Type())
 		if err != nil {
 			return err
 		}
-		ttl := int(upsert.Ttl())
-		uopts := recordsets.UpdateOpts{
-			TTL:     &ttl,
-			Records: upsert.Rrdatas(),
-		}
-		_, err = recordsets.Update(c.zone.zones.iface.sc, zoneID, rrID, uopts).Extract()
-		if err != nil {
+		if rrID == "" {
 			copts := recordsets.CreateOpts{
 				Name:    upsert.Name(),
 				TTL:     int(upsert.Ttl()),
@@ -105,6 +105,16 @@ func (c *ResourceRecordChangeset) Apply(ctx context.Context) error {
 			if err != nil {
 				return err
 			}
+			continue
+		}
+		ttl := int(upsert.Ttl())
+		uopts := recordsets.UpdateOpts{
+			TTL:     &ttl,
+			Records: upsert.Rrdatas(),
+		}
+		_, err = recordsets.Update(c.zone.zones.iface.sc, zoneID, rrID, uopts).Extract()
+		if err != nil {
+			return err
 		}
 	}
 
@@ -120,9 +130,11 @@ func (c *ResourceRecordChangeset) ResourceRecordSets() dnsprovider.ResourceRecor
 	return c.rrsets
 }
 
-func (c *ResourceRecordChangeset) nameToID(name string) (string, error) {
+// findRecordSetID returns the ID of the recordset with the given name and type, or "" if there is no such recordset
+func (c *ResourceRecordChangeset) findRecordSetID(name string, rrsType rrstype.RrsType) (string, error) {
 	opts := recordsets.ListOpts{
 		Name: name,
+		Type: string(rrsType),
 	}
 	allPages, err := recordsets.ListByZone(c.zone.zones.iface.sc, c.zone.impl.ID, opts).AllPages()
 	if err != nil {
@@ -134,10 +146,10 @@ func (c *ResourceRecordChangeset) nameToID(name string) (string, error) {
 	}
 	switch len(rrs) {
 	case 0:
-		return "", fmt.Errorf("couldn't find recordset with name: %s, expected 1", name)
+		return "", nil
 	case 1:
 		return rrs[0].ID, nil
 	default:
-		return "", fmt.Errorf("found multiple recordsets with name: %s, expected 1", name)
+		return "", fmt.Errorf("found multiple recordsets with name: %s and type: %s, expected 1", name, rrsType)
 	}
 }
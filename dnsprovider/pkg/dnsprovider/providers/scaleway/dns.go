@@ -344,7 +344,6 @@ func (r *resourceRecordChangeset) Apply(ctx context.Context) error {
 		return nil
 	}
 
-	changeBatch := []*domain.RecordChange(nil)
 	klog.V(8).Infof("applying changes in record change set : [ %d additions | %d upserts | %d removals ]",
 		len(r.additions), len(r.upserts), len(r.removals))
 
@@ -353,39 +352,69 @@ func (r *resourceRecordChangeset) Apply(ctx context.Context) error {
 		return err
 	}
 
+	// Removals are validated first, so that a record can be removed and added again in the same changeset
+	removed := make(map[string]bool)
+	for _, rrset := range r.removals {
+		if !hasRecord(rrset, r.zone.Name(), records) {
+			return fmt.Errorf("cannot remove record %s %q: not found", rrset.Type(), rrset.Name())
+		}
+		removed[recordSetKey(rrset)] = true
+	}
+	for _, rrset := range r.additions {
+		if hasRecord(rrset, r.zone.Name(), records) && !removed[recordSetKey(rrset)] {
+			return fmt.Errorf("cannot add record %s %q: already exists", rrset.Type(), rrset.Name())
+		}
+	}
+
 	// Scaleway's Domain API doesn't allow more than one edit with the same record name in one request, which happens
-	// when there are several control-planes, so we have to check for duplicates in the upsert category and if there are,
-	// treat them as additions instead
-	if len(r.upserts) > 0 {
-		for _, rrset := range r.upserts {
-			for i, rrdata := range rrset.Rrdatas() {
-				if i == 0 {
-					changeBatch = putRecordToUpdateInChangeBatch(changeBatch, rrset, r.zone.Name(), records, rrdata)
-				} else {
-					rrsetFromIndex1 := r.rrsets.New(rrset.Name(), rrset.Rrdatas()[1:], rrset.Ttl(), rrset.Type())
-					changeBatch = putRecordToAddInChangeBatch(changeBatch, rrsetFromIndex1, r.zone.Name())
-					break
-				}
-			}
+	// when a record set is removed and added again, or when a name has records of several types (e.g. A and AAAA),
+	// so we merge the changes of each record set into a single edit
+	var keys []string
+	changes := make(map[string]*domain.RecordChange)
+	names := make(map[string]string)
+	for _, rrset := range r.removals {
+		key := recordSetKey(rrset)
+		if _, found := changes[key]; !found {
+			keys = append(keys, key)
 		}
+		changes[key] = buildDeleteChange(rrset, r.zone.Name())
+		names[key] = recordName(rrset, r.zone.Name())
 	}
-	if len(r.additions) > 0 {
-		for _, rrset := range r.additions {
-			changeBatch = putRecordToAddInChangeBatch(changeBatch, rrset, r.zone.Name())
+	for _, rrsets := range [][]dnsprovider.ResourceRecordSet{r.additions, r.upserts} {
+		for _, rrset := range rrsets {
+			key := recordSetKey(rrset)
+			if _, found := changes[key]; !found {
+				keys = append(keys, key)
+			}
+			if hasRecord(rrset, r.zone.Name(), records) {
+				changes[key] = buildSetChange(rrset, r.zone.Name())
+			} else {
+				changes[key] = buildAddChange(rrset, r.zone.Name())
+			}
+			names[key] = recordName(rrset, r.zone.Name())
 		}
 	}
-	if len(r.removals) > 0 {
-		for _, rrset := range r.removals {
-			changeBatch = putRecordToDeleteInChangeBatch(changeBatch, rrset, r.zone.Name(), records)
+
+	// and send the edits of record sets with the same name in separate requests
+	var changeBatches [][]*domain.RecordChange
+	edits := make(map[string]int)
+	for _, key := range keys {
+		i := edits[names[key]]
+		edits[names[key]]++
+		if i == len(changeBatches) {
+			changeBatches = append(changeBatches, nil)
 		}
+		changeBatches[i] = append(changeBatches[i], changes[key])
 	}
 
-	_, err = r.domainAPI.UpdateDNSZoneRecords(&domain.UpdateDNSZoneRecordsRequest{
-		DNSZone: r.zone.Name(),
-		Changes: changeBatch,
-	}, scw.WithContext(ctx))
-	if err != nil {
-		return fmt.Errorf("failed to apply resource record set: %w", err)
+	for _, changeBatch := range changeBatches {
+		_, err = r.domainAPI.UpdateDNSZoneRecords(&domain.UpdateDNSZoneRecordsRequest{
+			DNSZone: r.zone.Name(),
+			Changes: changeBatch,
+		}, scw.WithContext(ctx))
+		if err != nil {
+			return fmt.Errorf("failed to apply resource record set: %w", err)
+		}
 	}
 
 	klog.V(2).Info("record change sets successfully applied")
@@ -418,61 +447,74 @@ func listRecords(api DomainAPI, zoneName string) ([]*domain.Record, error) {
 	return records.Records, err
 }
 
-func putRecordToAddInChangeBatch(changeBatch []*domain.RecordChange, rrset dnsprovider.ResourceRecordSet, zoneName string) []*domain.RecordChange {
-	recordsToAdd := []*domain.Record(nil)
-	recordName := strings.TrimSuffix(rrset.Name(), ".")
-	recordName = strings.TrimSuffix(recordName, "."+zoneName)
+// recordName returns the name of a record set in the scaleway API, which doesn't include the zone
+func recordName(rrset dnsprovider.ResourceRecordSet, zoneName string) string {
+	name := strings.TrimSuffix(rrset.Name(), ".")
+	return strings.TrimSuffix(name, "."+zoneName)
+}
+
+// recordSetKey identifies a record set by name and type
+func recordSetKey(rrset dnsprovider.ResourceRecordSet) string {
+	return dns.EnsureDotSuffix(rrset.Name()) + "_" + string(rrset.Type())
+}
+
+// hasRecord returns true if records contains at least one record of the record set
+func hasRecord(rrset dnsprovider.ResourceRecordSet, zoneName string, records []*domain.Record) bool {
+	for _, record := range records {
+		recordNameWithZone := fmt.Sprintf("%s.%s.", record.Name, zoneName)
+		if recordNameWithZone == dns.EnsureDotSuffix(rrset.Name()) && rrset.Type() == rrstype.RrsType(record.Type) {
+			return true
+		}
+	}
+	return false
+}
+
+func buildRecords(rrset dnsprovider.ResourceRecordSet, zoneName string) []*domain.Record {
+	records := []*domain.Record(nil)
 	for _, rrdata := range rrset.Rrdatas() {
-		recordsToAdd = append(recordsToAdd, &domain.Record{
-			Name: recordName,
+		records = append(records, &domain.Record{
+			Name: recordName(rrset, zoneName),
 			Data: rrdata,
 			TTL:  uint32(rrset.Ttl()),
 			Type: domain.RecordType(rrset.Type()),
 		})
 	}
-	klog.V(8).Infof("adding new DNS record %q to zone %q", recordName, zoneName)
-	return append(changeBatch, &domain.RecordChange{
+	return records
+}
+
+// buildAddChange adds the records of the record set
+func buildAddChange(rrset dnsprovider.ResourceRecordSet, zoneName string) *domain.RecordChange {
+	klog.V(8).Infof("adding new DNS record %q to zone %q", recordName(rrset, zoneName), zoneName)
+	return &domain.RecordChange{
 		Add: &domain.RecordChangeAdd{
-			Records: recordsToAdd,
+			Records: buildRecords(rrset, zoneName),
 		},
-	})
+	}
 }
 
-func putRecordToUpdateInChangeBatch(changeBatch []*domain.RecordChange, rrset dnsprovider.ResourceRecordSet, zoneName string, records []*domain.Record, rrdata string) []*domain.RecordChange {
-	for _, record := range records {
-		recordNameWithZone := fmt.Sprintf("%s.%s.", record.Name, zoneName)
-		if recordNameWithZone == dns.EnsureDotSuffix(rrset.Name()) && rrset.Type() == rrstype.RrsType(record.Type) {
-			klog.V(8).Infof("changing DNS record %q of zone %q", record.Name, zoneName)
-			return append(changeBatch, &domain.RecordChange{
-				Set: &domain.RecordChangeSet{
-					ID: &record.ID,
-					Records: []*domain.Record{
-						{
-							Name: record.Name,
-							Data: rrdata,
-							TTL:  uint32(rrset.Ttl()),
-							Type: domain.RecordType(rrset.Type()),
-						},
-					},
-				},
-			})
-		}
+// buildSetChange replaces all the records with the name and type of the record set
+func buildSetChange(rrset dnsprovider.ResourceRecordSet, zoneName string) *domain.RecordChange {
+	klog.V(8).Infof("changing DNS record %q of zone %q", recordName(rrset, zoneName), zoneName)
+	return &domain.RecordChange{
+		Set: &domain.RecordChangeSet{
+			IDFields: &domain.RecordIdentifier{
+				Name: recordName(rrset, zoneName),
+				Type: domain.RecordType(rrset.Type()),
+			},
+			Records: buildRecords(rrset, zoneName),
+		},
 	}
-	return changeBatch
 }
 
-func putRecordToDeleteInChangeBatch(changeBatch []*domain.RecordChange, rrset dnsprovider.ResourceRecordSet, zoneName string, records []*domain.Record) []*domain.RecordChange {
-	for _, record := range records {
-		recordNameWithZone := fmt.Sprintf("%s.%s.", record.Name, zoneName)
-		if recordNameWithZone == dns.EnsureDotSuffix(rrset.Name()) && record.Data == rrset.Rrdatas()[0] &&
-			rrset.Type() == rrstype.RrsType(record.Type) {
-			klog.V(8).Infof("removing DNS record %q of zone %q", record.Name, zoneName)
-			return append(changeBatch, &domain.RecordChange{
-				Delete: &domain.RecordChangeDelete{
-					ID: &record.ID,
-				},
-			})
-		}
+// buildDeleteChange deletes all the records with the name and type of the record set
+func buildDeleteChange(rrset dnsprovider.ResourceRecordSet, zoneName string) *domain.RecordChange {
+	klog.V(8).Infof("removing DNS record %q of zone %q", recordName(rrset, zoneName), zoneName)
+	return &domain.RecordChange{
+		Delete: &domain.RecordChangeDelete{
+			IDFields: &domain.RecordIdentifier{
+				Name: recordName(rrset, zoneName),
+				Type: domain.RecordType(rrset.Type()),
+			},
+		},
 	}
-	return changeBatch
 }
@@ -19,6 +19,8 @@ package dns
 import (
 	"context"
 	"fmt"
+	"reflect"
+	"sort"
 	"strings"
 	"testing"
 
@@ -27,6 +29,7 @@ import (
 	"k8s.io/kops/cloudmock/scaleway/mockdns"
 	"k8s.io/kops/dnsprovider/pkg/dnsprovider"
 	"k8s.io/kops/dnsprovider/pkg/dnsprovider/rrstype"
+	"k8s.io/kops/dnsprovider/pkg/dnsprovider/tests"
 )
 
 func setUpFakeZones() *mockdns.FakeDomainAPI {
@@ -299,3 +302,75 @@ func TestResourceRecordChangeset(t *testing.T) {
 		t.Errorf("unexpected record TTL: %d, expected 3601", recordsUpsert[0].Ttl())
 	}
 }
+
+// TestResourceRecordChangesetRecordSets checks that changes apply to all the records of a record set,
+// as happens for the API records of clusters with several control-plane nodes
+func TestResourceRecordChangesetRecordSets(t *testing.T) {
+	ctx := context.Background()
+	domainAPI := &mockdns.FakeDomainAPI{
+		DNSZones: []*domain.DNSZone{
+			{
+				Domain: "example.com",
+			},
+		},
+		Records: map[string]*domain.Record{
+			"api-1": {ID: "api-1", Name: "api", Data: "192.0.2.1", TTL: 60, Type: domain.RecordTypeA},
+			"api-2": {ID: "api-2", Name: "api", Data: "192.0.2.2", TTL: 60, Type: domain.RecordTypeA},
+			"old-1": {ID: "old-1", Name: "old", Data: "192.0.2.1", TTL: 60, Type: domain.RecordTypeA},
+			"old-2": {ID: "old-2", Name: "old", Data: "192.0.2.2", TTL: 60, Type: domain.RecordTypeA},
+		},
+	}
+	zone := zone{
+		domainAPI: domainAPI,
+		name:      "example.com",
+	}
+	rrsets, _ := zone.ResourceRecordSets()
+
+	changeset := rrsets.StartChangeset()
+	changeset.Upsert(rrsets.New("api.example.com", []string{"192.0.2.3", "192.0.2.4", "192.0.2.5"}, 120, rrstype.A))
+	changeset.Upsert(rrsets.New("new.example.com", []string{"192.0.2.6"}, 60, rrstype.A))
+	changeset.Remove(rrsets.New("old.example.com", []string{"192.0.2.1", "192.0.2.2"}, 60, rrstype.A))
+	if err := changeset.Apply(ctx); err != nil {
+		t.Fatalf("error applying changeset: %v", err)
+	}
+
+	got := make(map[string][]string)
+	for _, record := range domainAPI.Records {
+		if record.TTL != 60 && record.Name != "api" {
+			t.Errorf("unexpected TTL %d for record %q", record.TTL, record.Name)
+		}
+		if record.TTL != 120 && record.Name == "api" {
+			t.Errorf("expected TTL 120 for record %q, got %d", record.Name, record.TTL)
+		}
+		got[record.Name] = append(got[record.Name], record.Data)
+	}
+	for name := range got {
+		sort.Strings(got[name])
+	}
+
+	want := map[string][]string{
+		"api": {"192.0.2.3", "192.0.2.4", "192.0.2.5"},
+		"new": {"192.0.2.6"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected records after applying changeset: got %v, want %v", got, want)
+	}
+}
+
+// TestConformance runs the dnsprovider conformance tests against the scaleway mock
+func TestConformance(t *testing.T) {
+	domainAPI := &mockdns.FakeDomainAPI{
+		DNSZones: []*domain.DNSZone{
+			{
+				Domain: "example.com",
+			},
+		},
+		Records: map[string]*domain.Record{},
+	}
+
+	tests.TestConformance(t, NewProvider(domainAPI), tests.ConformanceOptions{
+		ZoneName: "example.com",
+		// Zones are named after their domain, so zones added under a domain can't be listed back
+		SkipZoneManagement: true,
+	})
+}
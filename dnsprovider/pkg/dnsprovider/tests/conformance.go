@@ -0,0 +1,446 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"k8s.io/kops/dnsprovider/pkg/dnsprovider"
+	"k8s.io/kops/dnsprovider/pkg/dnsprovider/rrstype"
+)
+
+// defaultPaginationRecordCount is larger than the page size of the providers we support
+const defaultPaginationRecordCount = 320
+
+// ConformanceOptions configures TestConformance for a provider
+type ConformanceOptions struct {
+	// ZoneName is the name of an existing zone that the record tests run against.
+	// The tests only create and remove records below this zone, with names starting with "conformance-".
+	ZoneName string
+
+	// SkipZoneManagement skips the tests of Zones.Add and Zones.Remove, for providers that cannot manage zones
+	SkipZoneManagement bool
+
+	// PaginationRecordCount is the number of records created to exercise pagination of List.
+	// It should be larger than the page size of the provider; if zero a default is used.
+	PaginationRecordCount int
+
+	// KnownFailures maps the names of subtests that are known to fail for the provider to the reason they fail.
+	// These subtests are skipped, so that the deviations of a provider are recorded rather than hidden.
+	KnownFailures map[string]string
+}
+
+// TestConformance verifies that a provider implements the behaviour expected of a dnsprovider.Interface.
+// Names are compared without any trailing dot, because providers differ in whether they return one.
+func TestConformance(t *testing.T, provider dnsprovider.Interface, options ConformanceOptions) {
+	zone := findZoneOrFail(t, provider, options.ZoneName)
+
+	run := func(name string, f func(t *testing.T)) {
+		t.Run(name, func(t *testing.T) {
+			if reason, found := options.KnownFailures[name]; found {
+				t.Skipf("known failure: %s", reason)
+			}
+			f(t)
+		})
+	}
+
+	run("ZonesList", func(t *testing.T) {
+		testZonesList(t, provider, options)
+	})
+	run("ZonesAddRemove", func(t *testing.T) {
+		if options.SkipZoneManagement {
+			t.Skip("zone management not supported")
+		}
+		testZonesAddRemove(t, provider, options)
+	})
+	run("Contract", func(t *testing.T) {
+		TestContract(t, rrs(t, zone))
+	})
+	run("EmptyChangeset", func(t *testing.T) {
+		testEmptyChangeset(t, zone)
+	})
+	run("AddAndList", func(t *testing.T) {
+		testAddAndList(t, zone)
+	})
+	run("Get", func(t *testing.T) {
+		testGet(t, zone)
+	})
+	run("Remove", func(t *testing.T) {
+		testRemove(t, zone)
+	})
+	run("Replace", func(t *testing.T) {
+		testReplace(t, zone)
+	})
+	run("UpsertCreates", func(t *testing.T) {
+		testUpsertCreates(t, zone)
+	})
+	run("UpsertReplaces", func(t *testing.T) {
+		testUpsertReplaces(t, zone)
+	})
+	run("AddDuplicateFails", func(t *testing.T) {
+		testAddDuplicateFails(t, zone)
+	})
+	run("RemoveMissingFails", func(t *testing.T) {
+		testRemoveMissingFails(t, zone)
+	})
+	run("Pagination", func(t *testing.T) {
+		count := options.PaginationRecordCount
+		if count == 0 {
+			count = defaultPaginationRecordCount
+		}
+		testPagination(t, zone, count)
+	})
+}
+
+func testZonesList(t *testing.T, provider dnsprovider.Interface, options ConformanceOptions) {
+	zone := findZoneOrFail(t, provider, options.ZoneName)
+	if zone.ID() == "" {
+		t.Errorf("zone %q has an empty ID", zone.Name())
+	}
+}
+
+func testZonesAddRemove(t *testing.T, provider dnsprovider.Interface, options ConformanceOptions) {
+	zones, _ := provider.Zones()
+
+	name := "conformance." + trimDot(options.ZoneName)
+	newZone, err := zones.New(name)
+	if err != nil {
+		t.Fatalf("error building zone %q: %v", name, err)
+	}
+	added, err := zones.Add(newZone)
+	if err != nil {
+		t.Fatalf("error adding zone %q: %v", name, err)
+	}
+	if found := findZone(t, provider, name); found == nil {
+		t.Fatalf("added zone %q not found in list", name)
+	}
+
+	if err := zones.Remove(added); err != nil {
+		t.Fatalf("error removing zone %q: %v", name, err)
+	}
+	if found := findZone(t, provider, name); found != nil {
+		t.Errorf("removed zone %q still found in list", name)
+	}
+}
+
+func testEmptyChangeset(t *testing.T, zone dnsprovider.Zone) {
+	changeset := rrs(t, zone).StartChangeset()
+	if !changeset.IsEmpty() {
+		t.Fatalf("expected new changeset to be empty")
+	}
+	if err := changeset.Apply(context.Background()); err != nil {
+		t.Errorf("error applying empty changeset: %v", err)
+	}
+}
+
+func testAddAndList(t *testing.T, zone dnsprovider.Zone) {
+	sets := rrs(t, zone)
+	records := []dnsprovider.ResourceRecordSet{
+		sets.New(recordName(zone, "conformance-add"), []string{"192.0.2.1", "192.0.2.2"}, 60, rrstype.A),
+		sets.New(recordName(zone, "conformance-add"), []string{"2001:db8::1"}, 60, rrstype.AAAA),
+		sets.New(recordName(zone, "conformance-add-cname"), []string{"target.example.org"}, 300, rrstype.CNAME),
+		sets.New(recordName(zone, "conformance-add-txt"), []string{`"conformance"`}, 300, rrstype.TXT),
+	}
+	cleanupRecords(t, sets, records...)
+
+	changeset := sets.StartChangeset()
+	for _, r := range records {
+		changeset.Add(r)
+	}
+	applyOrFail(t, changeset)
+
+	for _, r := range records {
+		assertHasEquivalentRecord(t, sets, r)
+	}
+}
+
+func testGet(t *testing.T, zone dnsprovider.Zone) {
+	sets := rrs(t, zone)
+	a := sets.New(recordName(zone, "conformance-get"), []string{"192.0.2.1"}, 60, rrstype.A)
+	aaaa := sets.New(recordName(zone, "conformance-get"), []string{"2001:db8::1"}, 60, rrstype.AAAA)
+	other := sets.New(recordName(zone, "conformance-get-other"), []string{"192.0.2.2"}, 60, rrstype.A)
+	cleanupRecords(t, sets, a, aaaa, other)
+	applyOrFail(t, sets.StartChangeset().Add(a).Add(aaaa).Add(other))
+
+	got, err := sets.Get(a.Name())
+	if err != nil {
+		t.Fatalf("error getting %q: %v", a.Name(), err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("expected Get(%q) to return the A and AAAA records, got %v", a.Name(), describeRecords(got))
+	}
+	for _, want := range []dnsprovider.ResourceRecordSet{a, aaaa} {
+		found := false
+		for _, r := range got {
+			if r.Type() == want.Type() {
+				assertEquivalentRecords(t, r, want)
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("Get(%q) did not return the %s record", a.Name(), want.Type())
+		}
+	}
+
+	missing := recordName(zone, "conformance-get-missing")
+	got, err = sets.Get(missing)
+	if err != nil {
+		t.Errorf("expected Get(%q) of missing name to succeed, got error: %v", missing, err)
+	}
+	if len(got) != 0 {
+		t.Errorf("expected Get(%q) of missing name to return no records, got %v", missing, describeRecords(got))
+	}
+}
+
+func testRemove(t *testing.T, zone dnsprovider.Zone) {
+	sets := rrs(t, zone)
+	a := sets.New(recordName(zone, "conformance-remove"), []string{"192.0.2.1"}, 60, rrstype.A)
+	aaaa := sets.New(recordName(zone, "conformance-remove"), []string{"2001:db8::1"}, 60, rrstype.AAAA)
+	cleanupRecords(t, sets, a, aaaa)
+	applyOrFail(t, sets.StartChangeset().Add(a).Add(aaaa))
+
+	applyOrFail(t, sets.StartChangeset().Remove(a))
+
+	assertHasNoRecord(t, sets, a.Name(), a.Type())
+	assertHasEquivalentRecord(t, sets, aaaa)
+}
+
+func testReplace(t *testing.T, zone dnsprovider.Zone) {
+	sets := rrs(t, zone)
+	original := sets.New(recordName(zone, "conformance-replace"), []string{"192.0.2.1"}, 60, rrstype.A)
+	cleanupRecords(t, sets, original)
+	applyOrFail(t, sets.StartChangeset().Add(original))
+
+	replacement := sets.New(original.Name(), []string{"192.0.2.2", "192.0.2.3"}, 120, rrstype.A)
+	applyOrFail(t, sets.StartChangeset().Remove(original).Add(replacement))
+
+	assertHasEquivalentRecord(t, sets, replacement)
+}
+
+func testUpsertCreates(t *testing.T, zone dnsprovider.Zone) {
+	sets := rrs(t, zone)
+	record := sets.New(recordName(zone, "conformance-upsert-create"), []string{"192.0.2.1"}, 60, rrstype.A)
+	cleanupRecords(t, sets, record)
+
+	applyOrFail(t, sets.StartChangeset().Upsert(record))
+
+	assertHasEquivalentRecord(t, sets, record)
+}
+
+func testUpsertReplaces(t *testing.T, zone dnsprovider.Zone) {
+	sets := rrs(t, zone)
+	original := sets.New(recordName(zone, "conformance-upsert-replace"), []string{"192.0.2.1", "192.0.2.2"}, 60, rrstype.A)
+	cleanupRecords(t, sets, original)
+	applyOrFail(t, sets.StartChangeset().Add(original))
+
+	replacement := sets.New(original.Name(), []string{"192.0.2.3"}, 120, rrstype.A)
+	applyOrFail(t, sets.StartChangeset().Upsert(replacement))
+
+	assertHasEquivalentRecord(t, sets, replacement)
+}
+
+func testAddDuplicateFails(t *testing.T, zone dnsprovider.Zone) {
+	sets := rrs(t, zone)
+	original := sets.New(recordName(zone, "conformance-duplicate"), []string{"192.0.2.1"}, 60, rrstype.A)
+	cleanupRecords(t, sets, original)
+	applyOrFail(t, sets.StartChangeset().Add(original))
+
+	duplicate := sets.New(original.Name(), []string{"192.0.2.2"}, 60, rrstype.A)
+	if err := sets.StartChangeset().Add(duplicate).Apply(context.Background()); err == nil {
+		t.Errorf("expected adding duplicate record %v to fail", describeRecord(duplicate))
+	}
+
+	assertHasEquivalentRecord(t, sets, original)
+}
+
+func testRemoveMissingFails(t *testing.T, zone dnsprovider.Zone) {
+	sets := rrs(t, zone)
+	missing := sets.New(recordName(zone, "conformance-remove-missing"), []string{"192.0.2.1"}, 60, rrstype.A)
+
+	if err := sets.StartChangeset().Remove(missing).Apply(context.Background()); err == nil {
+		t.Errorf("expected removing missing record %v to fail", describeRecord(missing))
+	}
+}
+
+func testPagination(t *testing.T, zone dnsprovider.Zone, count int) {
+	sets := rrs(t, zone)
+
+	var records []dnsprovider.ResourceRecordSet
+	changeset := sets.StartChangeset()
+	for i := 0; i < count; i++ {
+		r := sets.New(recordName(zone, fmt.Sprintf("conformance-page-%04d", i)), []string{fmt.Sprintf("192.0.2.%d", i%250+1)}, 60, rrstype.A)
+		records = append(records, r)
+		changeset.Add(r)
+	}
+	cleanupRecords(t, sets, records...)
+	applyOrFail(t, changeset)
+
+	list, err := sets.List()
+	if err != nil {
+		t.Fatalf("error listing records: %v", err)
+	}
+	found := make(map[string]int)
+	for _, r := range list {
+		found[trimDot(r.Name())]++
+	}
+	for _, r := range records {
+		switch found[trimDot(r.Name())] {
+		case 0:
+			t.Errorf("record %v not found in list of %d records", describeRecord(r), len(list))
+		case 1:
+			// expected
+		default:
+			t.Errorf("record %v found %d times in list", describeRecord(r), found[trimDot(r.Name())])
+		}
+	}
+}
+
+// findZone returns the zone with the specified name, or nil if it is not found
+func findZone(t *testing.T, provider dnsprovider.Interface, name string) dnsprovider.Zone {
+	zones, supported := provider.Zones()
+	if !supported {
+		t.Fatalf("Zones interface not supported by provider %v", provider)
+	}
+	list, err := zones.List()
+	if err != nil {
+		t.Fatalf("error listing zones: %v", err)
+	}
+
+	var found dnsprovider.Zone
+	for _, zone := range list {
+		if trimDot(zone.Name()) != trimDot(name) {
+			continue
+		}
+		if found != nil {
+			t.Fatalf("found multiple zones named %q", name)
+		}
+		found = zone
+	}
+	return found
+}
+
+func findZoneOrFail(t *testing.T, provider dnsprovider.Interface, name string) dnsprovider.Zone {
+	zone := findZone(t, provider, name)
+	if zone == nil {
+		t.Fatalf("zone %q not found", name)
+	}
+	return zone
+}
+
+// recordName returns the fully qualified name of a record in the zone
+func recordName(zone dnsprovider.Zone, prefix string) string {
+	return prefix + "." + trimDot(zone.Name())
+}
+
+func trimDot(name string) string {
+	return strings.TrimSuffix(name, ".")
+}
+
+func applyOrFail(t *testing.T, changeset dnsprovider.ResourceRecordChangeset) {
+	if err := changeset.Apply(context.Background()); err != nil {
+		t.Fatalf("error applying changeset: %v", err)
+	}
+}
+
+// cleanupRecords registers a cleanup function that removes any records with the names and types of the specified records
+func cleanupRecords(t *testing.T, sets dnsprovider.ResourceRecordSets, records ...dnsprovider.ResourceRecordSet) {
+	t.Cleanup(func() {
+		list, err := sets.List()
+		if err != nil {
+			t.Errorf("error listing records for cleanup: %v", err)
+			return
+		}
+
+		changeset := sets.StartChangeset()
+		for _, existing := range list {
+			for _, r := range records {
+				if trimDot(existing.Name()) == trimDot(r.Name()) && existing.Type() == r.Type() {
+					changeset.Remove(existing)
+					break
+				}
+			}
+		}
+		if err := changeset.Apply(context.Background()); err != nil {
+			t.Errorf("error removing records during cleanup: %v", err)
+		}
+	})
+}
+
+// findRecord returns the record with the specified name and type, or nil if it is not found
+func findRecord(t *testing.T, sets dnsprovider.ResourceRecordSets, name string, rrsType rrstype.RrsType) dnsprovider.ResourceRecordSet {
+	list, err := sets.List()
+	if err != nil {
+		t.Fatalf("error listing records: %v", err)
+	}
+
+	var found dnsprovider.ResourceRecordSet
+	for _, r := range list {
+		if trimDot(r.Name()) != trimDot(name) || r.Type() != rrsType {
+			continue
+		}
+		if found != nil {
+			t.Errorf("found duplicate records %v and %v", describeRecord(found), describeRecord(r))
+		}
+		found = r
+	}
+	return found
+}
+
+func assertHasEquivalentRecord(t *testing.T, sets dnsprovider.ResourceRecordSets, want dnsprovider.ResourceRecordSet) {
+	found := findRecord(t, sets, want.Name(), want.Type())
+	if found == nil {
+		t.Errorf("record %v not found", describeRecord(want))
+		return
+	}
+	assertEquivalentRecords(t, found, want)
+}
+
+func assertHasNoRecord(t *testing.T, sets dnsprovider.ResourceRecordSets, name string, rrsType rrstype.RrsType) {
+	if found := findRecord(t, sets, name, rrsType); found != nil {
+		t.Errorf("record %v found unexpectedly", describeRecord(found))
+	}
+}
+
+// assertEquivalentRecords checks that the records are equal, ignoring trailing dots and the order of rrdatas
+func assertEquivalentRecords(t *testing.T, got, want dnsprovider.ResourceRecordSet) {
+	if trimDot(got.Name()) != trimDot(want.Name()) || got.Type() != want.Type() || got.Ttl() != want.Ttl() || !reflect.DeepEqual(sortedRrdatas(got), sortedRrdatas(want)) {
+		t.Errorf("records not equivalent: got %v, want %v", describeRecord(got), describeRecord(want))
+	}
+}
+
+func sortedRrdatas(r dnsprovider.ResourceRecordSet) []string {
+	rrdatas := append([]string(nil), r.Rrdatas()...)
+	sort.Strings(rrdatas)
+	return rrdatas
+}
+
+func describeRecord(r dnsprovider.ResourceRecordSet) string {
+	return fmt.Sprintf("%s %s ttl=%d %v", r.Name(), r.Type(), r.Ttl(), r.Rrdatas())
+}
+
+func describeRecords(records []dnsprovider.ResourceRecordSet) []string {
+	var descriptions []string
+	for _, r := range records {
+		descriptions = append(descriptions, describeRecord(r))
+	}
+	return descriptions
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package model
 
 import (
+	"context"
 	"fmt"
 	"net"
 	"net/url"
@@ -48,90 +49,103 @@ func (b BootstrapClientBuilder) Build(c *fi.NodeupModelBuilderContext) error {
 		return nil
 	}
 
+	authenticator, err := b.newAuthenticator(c.Context())
+	if err != nil {
+		return err
+	}
+
+	bootstrapClient := &kopscontrollerclient.Client{
+		Authenticator: authenticator,
+		CAs:           []byte(b.NodeupConfig.CAs[fi.CertificateIDCA]),
+		BaseURL:       b.kopsControllerURL(),
+	}
+
+	bootstrapClientTask := &nodetasks.BootstrapClientTask{
+		Client:     bootstrapClient,
+		Certs:      b.bootstrapCerts,
+		KeypairIDs: b.bootstrapKeypairIDs,
+	}
+	bootstrapClientTask.UseChallengeCallback = b.UseChallengeCallback(b.CloudProvider())
+	bootstrapClientTask.ClusterName = b.NodeupConfig.ClusterName
+
+	for _, cert := range b.bootstrapCerts {
+		cert.Cert.Task = bootstrapClientTask
+		cert.Key.Task = bootstrapClientTask
+	}
+
+	c.AddTask(bootstrapClientTask)
+	return nil
+}
+
+var _ fi.NodeupModelBuilder = &BootstrapClientBuilder{}
+
+// newAuthenticator returns the authenticator for the requests of the node to kops-controller.
+func (b *NodeupModelContext) newAuthenticator(ctx context.Context) (bootstrap.Authenticator, error) {
 	var authenticator bootstrap.Authenticator
 
 	switch b.CloudProvider() {
 	case kops.CloudProviderAWS:
-		a, err := awsup.NewAWSAuthenticator(c.Context(), b.Cloud.Region())
+		a, err := awsup.NewAWSAuthenticator(ctx, b.Cloud.Region())
 		if err != nil {
-			return err
+			return nil, err
 		}
 		authenticator = a
 	case kops.CloudProviderGCE:
 		a, err := gcetpmsigner.NewTPMAuthenticator()
 		if err != nil {
-			return err
+			return nil, err
 		}
 		authenticator = a
 	case kops.CloudProviderHetzner:
 		a, err := hetzner.NewHetznerAuthenticator()
 		if err != nil {
-			return err
+			return nil, err
 		}
 		authenticator = a
 	case kops.CloudProviderOpenstack:
 		a, err := openstack.NewOpenstackAuthenticator()
 		if err != nil {
-			return err
+			return nil, err
 		}
 		authenticator = a
 	case kops.CloudProviderDO:
 		a, err := do.NewAuthenticator()
 		if err != nil {
-			return err
+			return nil, err
 		}
 		authenticator = a
 	case kops.CloudProviderScaleway:
 		a, err := scaleway.NewScalewayAuthenticator()
 		if err != nil {
-			return err
+			return nil, err
 		}
 		authenticator = a
 	case kops.CloudProviderAzure:
 		a, err := azure.NewAzureAuthenticator()
 		if err != nil {
-			return err
+			return nil, err
 		}
 		authenticator = a
 
-	case "metal":
+	case kops.CloudProviderMetal:
 		a, err := pkibootstrap.NewAuthenticatorFromFile("/etc/kubernetes/kops/pki/machine/private.pem")
 		if err != nil {
-			return err
+			return nil, err
 		}
 		authenticator = a
 
 	default:
-		return fmt.Errorf("unsupported cloud provider for authenticator %q", b.CloudProvider())
+		return nil, fmt.Errorf("unsupported cloud provider for authenticator %q", b.CloudProvider())
 	}
 
-	baseURL := url.URL{
+	return authenticator, nil
+}
+
+// kopsControllerURL returns the base URL of kops-controller.
+func (b *NodeupModelContext) kopsControllerURL() url.URL {
+	return url.URL{
 		Scheme: "https",
 		Host:   net.JoinHostPort("kops-controller.internal."+b.NodeupConfig.ClusterName, strconv.Itoa(wellknownports.KopsControllerPort)),
 		Path:   "/",
 	}
-
-	bootstrapClient := &kopscontrollerclient.Client{
-		Authenticator: authenticator,
-		CAs:           []byte(b.NodeupConfig.CAs[fi.CertificateIDCA]),
-		BaseURL:       baseURL,
-	}
-
-	bootstrapClientTask := &nodetasks.BootstrapClientTask{
-		Client:     bootstrapClient,
-		Certs:      b.bootstrapCerts,
-		KeypairIDs: b.bootstrapKeypairIDs,
-	}
-	bootstrapClientTask.UseChallengeCallback = b.UseChallengeCallback(b.CloudProvider())
-	bootstrapClientTask.ClusterName = b.NodeupConfig.ClusterName
-
-	for _, cert := range b.bootstrapCerts {
-		cert.Cert.Task = bootstrapClientTask
-		cert.Key.Task = bootstrapClientTask
-	}
-
-	c.AddTask(bootstrapClientTask)
-	return nil
 }
-
-var _ fi.NodeupModelBuilder = &BootstrapClientBuilder{}
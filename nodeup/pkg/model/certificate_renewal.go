@@ -0,0 +1,132 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+
+	"k8s.io/kops/pkg/apis/nodeup"
+	"k8s.io/kops/pkg/systemd"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/nodeup/nodetasks"
+)
+
+const certificateRenewalServiceName = "kops-certificate-renewal"
+
+// CertificateRenewalBuilder installs a timer that renews the certificates issued by kops-controller before they expire.
+type CertificateRenewalBuilder struct {
+	*NodeupModelContext
+}
+
+var _ fi.NodeupModelBuilder = &CertificateRenewalBuilder{}
+
+// Build is responsible for configuring the certificate renewal service and timer.
+func (b *CertificateRenewalBuilder) Build(c *fi.NodeupModelBuilderContext) error {
+	if b.NodeupConfig.CertificateRenewBefore == nil || b.HasAPIServer {
+		return nil
+	}
+
+	config := &nodeup.CertificateRenewalConfig{
+		ClusterName: b.NodeupConfig.ClusterName,
+		RenewBefore: *b.NodeupConfig.CertificateRenewBefore,
+		Crictl:      filepath.Join((&CrictlBuilder{NodeupModelContext: b.NodeupModelContext}).binaryPath(), "crictl"),
+		Certificates: []nodeup.RenewedCertificate{
+			{
+				Name:       "kubelet",
+				Kubeconfig: b.KubeletKubeConfig(),
+				Services:   []string{kubeletService},
+			},
+			{
+				Name:     "kubelet-server",
+				CertPath: filepath.Join(b.PathSrvKubernetes(), "kubelet-server.crt"),
+				KeyPath:  filepath.Join(b.PathSrvKubernetes(), "kubelet-server.key"),
+				Services: []string{kubeletService},
+			},
+		},
+	}
+	if b.NodeupConfig.KubeProxy != nil {
+		config.Certificates = append(config.Certificates, nodeup.RenewedCertificate{
+			Name:       "kube-proxy",
+			Kubeconfig: "/var/lib/kube-proxy/kubeconfig",
+			Containers: []string{"kube-proxy"},
+		})
+	}
+
+	configYAML, err := yaml.Marshal(config)
+	if err != nil {
+		return fmt.Errorf("error marshaling certificate renewal config: %w", err)
+	}
+	c.AddTask(&nodetasks.File{
+		Path:     nodeup.CertificateRenewalConfigPath,
+		Contents: fi.NewBytesResource(configYAML),
+		Type:     nodetasks.FileType_File,
+		Mode:     s("0644"),
+	})
+
+	installDir := b.NodeupInstallDir()
+
+	command := []string{
+		installDir + "/bin/nodeup",
+		"--conf=" + installDir + "/conf/kube_env.yaml",
+		"--renew-certificates",
+		"--retries=0",
+		"--v=2",
+	}
+
+	{
+		manifest := &systemd.Manifest{}
+		manifest.Set("Unit", "Description", "Renew kOps node certificates (nodeup)")
+		manifest.Set("Unit", "Documentation", "https://github.com/kubernetes/kops")
+		manifest.Set("Unit", "After", "kops-configuration.service")
+
+		manifest.Set("Service", "EnvironmentFile", "/etc/sysconfig/kops-configuration")
+		manifest.Set("Service", "EnvironmentFile", "/etc/environment")
+		manifest.Set("Service", "ExecStart", strings.Join(command, " "))
+		manifest.Set("Service", "Type", "oneshot")
+
+		// The timer starts the service
+		service := &nodetasks.Service{
+			Name:        certificateRenewalServiceName + ".service",
+			Definition:  s(manifest.Render()),
+			ManageState: fi.PtrTo(false),
+		}
+		service.InitDefaults()
+		c.AddTask(service)
+	}
+
+	{
+		// Checking twice a day leaves plenty of retries within the minimum renewal window of 24h
+		manifest := &systemd.Manifest{}
+		manifest.Set("Unit", "Description", "Periodically renew kOps node certificates")
+		manifest.Set("Timer", "OnActiveSec", "1h")
+		manifest.Set("Timer", "OnUnitInactiveSec", "12h")
+		manifest.Set("Timer", "RandomizedDelaySec", "1h")
+
+		service := &nodetasks.Service{
+			Name:       certificateRenewalServiceName + ".timer", // Started by nodeup on every boot
+			Definition: s(manifest.Render()),
+		}
+		service.InitDefaults()
+		c.AddTask(service)
+	}
+
+	return nil
+}
@@ -17,11 +17,13 @@ limitations under the License.
 package model
 
 import (
+	"encoding/base64"
 	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/blang/semver/v4"
@@ -30,6 +32,7 @@ import (
 	"k8s.io/klog/v2"
 	"k8s.io/kops/nodeup/pkg/model/resources"
 	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/apis/nodeup"
 	"k8s.io/kops/pkg/flagbuilder"
 	"k8s.io/kops/pkg/systemd"
 	"k8s.io/kops/upup/pkg/fi"
@@ -37,7 +40,18 @@ import (
 	"k8s.io/kops/util/pkg/distributions"
 )
 
-const containerdConfigFilePath = "/etc/containerd/config.toml"
+const (
+	containerdConfigFilePath = "/etc/containerd/config.toml"
+	// containerdCertsDir holds the hosts.toml configuration of each registry
+	containerdCertsDir = "/etc/containerd/certs.d"
+
+	// containerdCRIPluginV2 is the CRI plugin of the version 2 config layout, used by containerd 1.x
+	containerdCRIPluginV2 = "io.containerd.grpc.v1.cri"
+	// containerdCRIRuntimePluginV3 holds the runtime settings of the CRI plugin in the version 3 config layout, used by containerd 2.x
+	containerdCRIRuntimePluginV3 = "io.containerd.cri.v1.runtime"
+	// containerdCRIImagesPluginV3 holds the image settings of the CRI plugin in the version 3 config layout, used by containerd 2.x
+	containerdCRIImagesPluginV3 = "io.containerd.cri.v1.images"
+)
 
 // ContainerdBuilder install containerd (just the packages at the moment)
 type ContainerdBuilder struct {
@@ -65,6 +79,9 @@ func (b *ContainerdBuilder) Build(c *fi.NodeupModelBuilderContext) error {
 		klog.Infof("Detected ContainerOS; won't install containerd")
 		installContainerd = false
 		b.buildSystemdServiceOverrideContainerOS(c)
+	case distributions.DistributionFedoraCoreOS:
+		// The /usr directory is read-only for Fedora CoreOS, so containerd is installed under /usr/local
+		klog.Infof("Detected Fedora CoreOS; will install containerd under /usr/local")
 	}
 
 	// Using containerd with Kubenet requires special configuration.
@@ -84,6 +101,14 @@ func (b *ContainerdBuilder) Build(c *fi.NodeupModelBuilderContext) error {
 		return err
 	}
 
+	if err := b.buildRegistryHostsFiles(c); err != nil {
+		return err
+	}
+
+	if err := b.installSandboxRuntimes(c); err != nil {
+		return err
+	}
+
 	if installContainerd {
 		if err := b.installContainerd(c); err != nil {
 			return err
@@ -99,7 +124,7 @@ func (b *ContainerdBuilder) installContainerd(c *fi.NodeupModelBuilderContext) e
 	// Add Apache2 license
 	{
 		t := &nodetasks.File{
-			Path:     "/usr/share/doc/containerd/apache.txt",
+			Path:     filepath.Join(b.prefixPath(), "share/doc/containerd/apache.txt"),
 			Contents: fi.NewStringResource(resources.ContainerdApache2License),
 			Type:     nodetasks.FileType_File,
 		}
@@ -122,7 +147,7 @@ func (b *ContainerdBuilder) installContainerd(c *fi.NodeupModelBuilderContext) e
 	}
 	for k, v := range f {
 		fileTask := &nodetasks.File{
-			Path:     filepath.Join("/usr/bin", k),
+			Path:     filepath.Join(b.prefixPath(), "bin", k),
 			Contents: v,
 			Type:     nodetasks.FileType_File,
 			Mode:     fi.PtrTo("0755"),
@@ -147,7 +172,7 @@ func (b *ContainerdBuilder) installContainerd(c *fi.NodeupModelBuilderContext) e
 	}
 	for _, v := range f {
 		fileTask := &nodetasks.File{
-			Path:     "/usr/sbin/runc",
+			Path:     filepath.Join(b.prefixPath(), "sbin/runc"),
 			Contents: v,
 			Type:     nodetasks.FileType_File,
 			Mode:     fi.PtrTo("0755"),
@@ -188,7 +213,7 @@ func (b *ContainerdBuilder) buildSystemdService(sv semver.Version) *nodetasks.Se
 	manifest.Set("Service", "EnvironmentFile", "/etc/sysconfig/containerd")
 	manifest.Set("Service", "EnvironmentFile", "/etc/environment")
 	manifest.Set("Service", "ExecStartPre", "-/sbin/modprobe overlay")
-	manifest.Set("Service", "ExecStart", "/usr/bin/containerd -c "+containerdConfigFilePath+" \"$CONTAINERD_OPTS\"")
+	manifest.Set("Service", "ExecStart", filepath.Join(b.prefixPath(), "bin/containerd")+" -c "+containerdConfigFilePath+" \"$CONTAINERD_OPTS\"")
 
 	// notify the daemon's readiness to systemd
 	manifest.Set("Service", "Type", "notify")
@@ -257,6 +282,14 @@ func (b *ContainerdBuilder) buildSystemdServiceOverrideContainerOS(c *fi.NodeupM
 	})
 }
 
+// prefixPath returns the directory under which the containerd binaries and docs are installed
+func (b *ContainerdBuilder) prefixPath() string {
+	if b.Distribution == distributions.DistributionFedoraCoreOS {
+		return "/usr/local"
+	}
+	return "/usr"
+}
+
 // buildSystemdServiceOverrideFlatcar is responsible for overriding the containerd service for Flatcar
 func (b *ContainerdBuilder) buildSystemdServiceOverrideFlatcar(c *fi.NodeupModelBuilderContext) {
 	lines := []string{
@@ -470,6 +503,33 @@ func (b *ContainerdBuilder) buildCNIConfigTemplateFile(c *fi.NodeupModelBuilderC
 	return nil
 }
 
+// usesContainerdV2 returns true if the node runs containerd 2.x, which only supports the version 3 configuration layout
+func (b *ContainerdBuilder) usesContainerdV2() bool {
+	containerd := b.NodeupConfig.ContainerdConfig
+	if containerd == nil || fi.ValueOf(containerd.Version) == "" {
+		return false
+	}
+	sv, err := semver.ParseTolerant(fi.ValueOf(containerd.Version))
+	if err != nil {
+		klog.Warningf("error parsing containerd version %q: %v", fi.ValueOf(containerd.Version), err)
+		return false
+	}
+	return sv.Major >= 2
+}
+
+// usesRegistryHostsFiles returns true if the registries are configured with hosts.toml files instead of the CRI plugin config
+func (b *ContainerdBuilder) usesRegistryHostsFiles() bool {
+	containerd := b.NodeupConfig.ContainerdConfig
+	if containerd == nil {
+		return false
+	}
+	if len(containerd.Registries) > 0 {
+		return true
+	}
+	// containerd 2.x no longer supports mirrors in the CRI plugin config
+	return len(containerd.RegistryMirrors) > 0 && b.usesContainerdV2()
+}
+
 func (b *ContainerdBuilder) buildContainerdConfig() (string, error) {
 	containerd := b.NodeupConfig.ContainerdConfig
 	if fi.ValueOf(containerd.ConfigOverride) != "" {
@@ -477,9 +537,22 @@ func (b *ContainerdBuilder) buildContainerdConfig() (string, error) {
 	}
 
 	// Build config file for containerd running in CRI mode
+	// containerd 2.x splits the CRI plugin into separate plugins for images and runtimes, in the version 3 layout.
+	// See https://github.com/containerd/containerd/blob/main/docs/cri/config.md
+	v3 := b.usesContainerdV2()
+	runtimePlugin := containerdCRIPluginV2
+	imagesPlugin := containerdCRIPluginV2
+	sandboxImagePath := []string{"plugins", containerdCRIPluginV2, "sandbox_image"}
 
 	config, _ := toml.Load("")
-	config.SetPath([]string{"version"}, int64(2))
+	if v3 {
+		config.SetPath([]string{"version"}, int64(3))
+		runtimePlugin = containerdCRIRuntimePluginV3
+		imagesPlugin = containerdCRIImagesPluginV3
+		sandboxImagePath = []string{"plugins", containerdCRIImagesPluginV3, "pinned_images", "sandbox"}
+	} else {
+		config.SetPath([]string{"version"}, int64(2))
+	}
 
 	if containerd.NRI != nil && (containerd.NRI.Enabled == nil || fi.ValueOf(containerd.NRI.Enabled)) {
 		config.SetPath([]string{"plugins", "io.containerd.nri.v1.nri", "disable"}, false)
@@ -491,30 +564,45 @@ func (b *ContainerdBuilder) buildContainerdConfig() (string, error) {
 		}
 	}
 	if containerd.SeLinuxEnabled {
-		config.SetPath([]string{"plugins", "io.containerd.grpc.v1.cri", "enable_selinux"}, true)
+		config.SetPath([]string{"plugins", runtimePlugin, "enable_selinux"}, true)
 	}
 	if b.NodeupConfig.KubeletConfig.PodInfraContainerImage != "" {
-		config.SetPath([]string{"plugins", "io.containerd.grpc.v1.cri", "sandbox_image"}, b.NodeupConfig.KubeletConfig.PodInfraContainerImage)
+		config.SetPath(sandboxImagePath, b.NodeupConfig.KubeletConfig.PodInfraContainerImage)
 	}
-	for name, endpoints := range containerd.RegistryMirrors {
-		config.SetPath([]string{"plugins", "io.containerd.grpc.v1.cri", "registry", "mirrors", name, "endpoint"}, endpoints)
+	if b.usesRegistryHostsFiles() {
+		// The registry mirrors are written to hosts.toml files too, as containerd doesn't allow both
+		config.SetPath([]string{"plugins", imagesPlugin, "registry", "config_path"}, containerdCertsDir)
+	} else {
+		for name, endpoints := range containerd.RegistryMirrors {
+			config.SetPath([]string{"plugins", imagesPlugin, "registry", "mirrors", name, "endpoint"}, endpoints)
+		}
 	}
-	config.SetPath([]string{"plugins", "io.containerd.grpc.v1.cri", "containerd", "runtimes", "runc", "runtime_type"}, "io.containerd.runc.v2")
+	config.SetPath([]string{"plugins", runtimePlugin, "containerd", "runtimes", "runc", "runtime_type"}, "io.containerd.runc.v2")
 	// only enable systemd cgroups for kubernetes >= 1.20
-	config.SetPath([]string{"plugins", "io.containerd.grpc.v1.cri", "containerd", "runtimes", "runc", "options", "SystemdCgroup"}, true)
+	config.SetPath([]string{"plugins", runtimePlugin, "containerd", "runtimes", "runc", "options", "SystemdCgroup"}, true)
 	if b.NodeupConfig.UsesKubenet {
 		// Using containerd with Kubenet requires special configuration.
 		// This is a temporary backwards-compatible solution for kubenet users and will be deprecated when Kubenet is deprecated:
 		// https://github.com/containerd/containerd/blob/master/docs/cri/config.md#cni-config-template
-		config.SetPath([]string{"plugins", "io.containerd.grpc.v1.cri", "cni", "conf_template"}, "/etc/containerd/config-cni.template")
+		config.SetPath([]string{"plugins", runtimePlugin, "cni", "conf_template"}, "/etc/containerd/config-cni.template")
 	}
 
 	if b.InstallNvidiaRuntime() {
-		if err := appendNvidiaGPURuntimeConfig(config); err != nil {
+		var err error
+		if v3 {
+			err = appendNvidiaGPURuntimeConfigV3(config)
+		} else {
+			err = appendNvidiaGPURuntimeConfig(config)
+		}
+		if err != nil {
 			return "", err
 		}
 	}
 
+	if err := b.appendSandboxRuntimesConfig(config, runtimePlugin); err != nil {
+		return "", err
+	}
+
 	for k, v := range containerd.ConfigAdditions {
 		r := csv.NewReader(strings.NewReader(k))
 		r.Comma = '.'
@@ -522,6 +610,9 @@ func (b *ContainerdBuilder) buildContainerdConfig() (string, error) {
 		if err != nil {
 			return "", fmt.Errorf("parsing additional containerd config entry: %w", err)
 		}
+		if v3 {
+			path = migrateContainerdConfigPathV3(path)
+		}
 
 		if v.Type == intstr.Int {
 			config.SetPath(path, int64(v.IntValue()))
@@ -539,6 +630,155 @@ func (b *ContainerdBuilder) buildContainerdConfig() (string, error) {
 	return config.String(), nil
 }
 
+// migrateContainerdConfigPathV3 moves a path of the CRI plugin from the version 2 layout to the version 3 layout,
+// so that configAdditions written for containerd 1.x keep working with containerd 2.x.
+// Paths that don't belong to the CRI plugin, or already use the version 3 layout, are returned unchanged.
+func migrateContainerdConfigPathV3(path []string) []string {
+	if len(path) < 3 || path[0] != "plugins" || path[1] != containerdCRIPluginV2 {
+		return path
+	}
+
+	key := path[2]
+	rest := path[3:]
+	migrated := func(elems ...string) []string {
+		return append(elems, rest...)
+	}
+
+	switch key {
+	case "sandbox_image":
+		return migrated("plugins", containerdCRIImagesPluginV3, "pinned_images", "sandbox")
+	case "registry", "image_decryption", "max_concurrent_downloads", "image_pull_progress_timeout", "image_pull_with_sync_fs", "stats_collect_period":
+		return migrated("plugins", containerdCRIImagesPluginV3, key)
+	case "containerd":
+		if len(rest) > 0 && (rest[0] == "snapshotter" || rest[0] == "discard_unpacked_layers") {
+			return append([]string{"plugins", containerdCRIImagesPluginV3}, rest...)
+		}
+		return migrated("plugins", containerdCRIRuntimePluginV3, key)
+	case "disable_tcp_service", "stream_server_address", "stream_server_port", "stream_idle_timeout", "enable_tls_streaming", "x509_key_pair_streaming":
+		// The streaming server stays in the CRI plugin
+		return path
+	default:
+		return migrated("plugins", containerdCRIRuntimePluginV3, key)
+	}
+}
+
+// buildRegistryHostsFiles writes the hosts.toml file of each registry, along with its CA and client certificate.
+// See https://github.com/containerd/containerd/blob/main/docs/hosts.md for the format.
+func (b *ContainerdBuilder) buildRegistryHostsFiles(c *fi.NodeupModelBuilderContext) error {
+	containerd := b.NodeupConfig.ContainerdConfig
+	if !b.usesRegistryHostsFiles() {
+		return nil
+	}
+
+	registries := append([]kops.ContainerdRegistryConfig(nil), containerd.Registries...)
+	for name, endpoints := range containerd.RegistryMirrors {
+		host := name
+		if host == "*" {
+			host = "_default"
+		}
+		registries = append(registries, kops.ContainerdRegistryConfig{
+			Host:    host,
+			Mirrors: endpoints,
+		})
+	}
+	sort.Slice(registries, func(i, j int) bool {
+		return registries[i].Host < registries[j].Host
+	})
+
+	for _, registry := range registries {
+		dir := filepath.Join(containerdCertsDir, registry.Host)
+
+		caPath := ""
+		if registry.CA != "" {
+			caPath = filepath.Join(dir, "ca.crt")
+			c.AddTask(&nodetasks.File{
+				Path:     caPath,
+				Contents: fi.NewStringResource(registry.CA),
+				Type:     nodetasks.FileType_File,
+				Mode:     s("0644"),
+			})
+		}
+
+		secret := &nodeup.ContainerdRegistrySecret{}
+		if registry.Secret != "" {
+			if b.SecretStore == nil {
+				return fmt.Errorf("secret store is required for the secret of registry %q", registry.Host)
+			}
+			id := nodeup.ContainerdRegistrySecretID(registry.Secret)
+			data, err := b.SecretStore.Secret(id)
+			if err != nil {
+				return fmt.Errorf("error loading secret for registry %q: %w", registry.Host, err)
+			}
+			if err := json.Unmarshal(data.Data, secret); err != nil {
+				return fmt.Errorf("error parsing secret %q: %w", id, err)
+			}
+		}
+
+		clientPath := ""
+		if secret.ClientCertificate != "" {
+			clientPath = filepath.Join(dir, "client.pem")
+			c.AddTask(&nodetasks.File{
+				Path:     clientPath,
+				Contents: fi.NewStringResource(strings.TrimSpace(secret.ClientCertificate) + "\n" + strings.TrimSpace(secret.ClientKey) + "\n"),
+				Type:     nodetasks.FileType_File,
+				Mode:     s("0600"),
+			})
+		}
+
+		// The settings at the top level apply to the server, which is tried after the mirrors.
+		// Credentials and the client certificate are only sent to the server, as mirrors are often run by someone else.
+		var hosts strings.Builder
+		if registry.Server != nil {
+			fmt.Fprintf(&hosts, "server = %q\n", fi.ValueOf(registry.Server))
+		}
+		if caPath != "" {
+			fmt.Fprintf(&hosts, "ca = %q\n", caPath)
+		}
+		if registry.SkipVerify {
+			fmt.Fprintf(&hosts, "skip_verify = true\n")
+		}
+		if clientPath != "" {
+			fmt.Fprintf(&hosts, "client = %q\n", clientPath)
+		}
+		if secret.Username != "" {
+			auth := base64.StdEncoding.EncodeToString([]byte(secret.Username + ":" + secret.Password))
+			writeTableSeparator(&hosts)
+			fmt.Fprintf(&hosts, "[header]\n  authorization = %q\n", "Basic "+auth)
+		}
+		for _, mirror := range registry.Mirrors {
+			writeTableSeparator(&hosts)
+			fmt.Fprintf(&hosts, "[host.%q]\n", mirror)
+			fmt.Fprintf(&hosts, "  capabilities = [\"pull\", \"resolve\"]\n")
+			if caPath != "" {
+				fmt.Fprintf(&hosts, "  ca = %q\n", caPath)
+			}
+			if registry.SkipVerify {
+				fmt.Fprintf(&hosts, "  skip_verify = true\n")
+			}
+		}
+
+		mode := "0644"
+		if secret.Username != "" {
+			mode = "0600"
+		}
+		c.AddTask(&nodetasks.File{
+			Path:     filepath.Join(dir, "hosts.toml"),
+			Contents: fi.NewStringResource(hosts.String()),
+			Type:     nodetasks.FileType_File,
+			Mode:     s(mode),
+		})
+	}
+
+	return nil
+}
+
+// writeTableSeparator separates a TOML table from the preceding content, if any
+func writeTableSeparator(b *strings.Builder) {
+	if b.Len() > 0 {
+		b.WriteString("\n")
+	}
+}
+
 func appendNvidiaGPURuntimeConfig(config *toml.Tree) error {
 	gpuConfig, err := toml.TreeFromMap(
 		map[string]interface{}{
@@ -556,8 +796,30 @@ func appendNvidiaGPURuntimeConfig(config *toml.Tree) error {
 		return err
 	}
 
-	config.SetPath([]string{"plugins", "io.containerd.grpc.v1.cri", "containerd", "runtimes", "nvidia"}, gpuConfig)
-	config.SetPath([]string{"plugins", "io.containerd.grpc.v1.cri", "containerd", "default_runtime_name"}, "runc")
+	config.SetPath([]string{"plugins", containerdCRIPluginV2, "containerd", "runtimes", "nvidia"}, gpuConfig)
+	config.SetPath([]string{"plugins", containerdCRIPluginV2, "containerd", "default_runtime_name"}, "runc")
+
+	return nil
+}
+
+// appendNvidiaGPURuntimeConfigV3 adds the nvidia runtime to a version 3 config, where the runc v1 shim is no longer available
+func appendNvidiaGPURuntimeConfigV3(config *toml.Tree) error {
+	gpuConfig, err := toml.TreeFromMap(
+		map[string]interface{}{
+			"privileged_without_host_devices": false,
+			"runtime_type":                    "io.containerd.runc.v2",
+			"options": map[string]interface{}{
+				"SystemdCgroup": true,
+				"BinaryName":    "/usr/bin/nvidia-container-runtime",
+			},
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	config.SetPath([]string{"plugins", containerdCRIRuntimePluginV3, "containerd", "runtimes", "nvidia"}, gpuConfig)
+	config.SetPath([]string{"plugins", containerdCRIRuntimePluginV3, "containerd", "default_runtime_name"}, "runc")
 
 	return nil
 }
@@ -0,0 +1,139 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/pelletier/go-toml"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/nodeup/nodetasks"
+)
+
+const (
+	// gVisorBinDir holds the gVisor binaries; /usr/bin is read-only on some distributions
+	gVisorBinDir = "/usr/local/bin"
+	// gVisorConfigFilePath is the configuration of the gVisor containerd shim
+	gVisorConfigFilePath = "/etc/containerd/runsc.toml"
+
+	// kataDir is where the Kata Containers static release is installed, which is the location it expects
+	kataDir = "/opt/kata"
+)
+
+// installSandboxRuntimes installs the sandboxed runtimes enabled on the instance group.
+func (b *ContainerdBuilder) installSandboxRuntimes(c *fi.NodeupModelBuilderContext) error {
+	containerd := b.NodeupConfig.ContainerdConfig
+	if containerd == nil {
+		return nil
+	}
+
+	if containerd.GVisor != nil && fi.ValueOf(containerd.GVisor.Enabled) {
+		for _, binary := range []string{"runsc", "containerd-shim-runsc-v1"} {
+			_, asset, err := b.Assets.FindMatch(regexp.MustCompile(`/` + regexp.QuoteMeta(binary) + `$`))
+			if err != nil {
+				return fmt.Errorf("unable to find gVisor asset %q: %w", binary, err)
+			}
+			c.AddTask(&nodetasks.File{
+				Path:     filepath.Join(gVisorBinDir, binary),
+				Contents: asset,
+				Type:     nodetasks.FileType_File,
+				Mode:     s("0755"),
+			})
+		}
+
+		// The shim runs runsc with the same cgroup driver as runc
+		var conf strings.Builder
+		fmt.Fprintf(&conf, "binary_name = %q\n", filepath.Join(gVisorBinDir, "runsc"))
+		writeTableSeparator(&conf)
+		fmt.Fprintf(&conf, "[runsc_config]\n  systemd-cgroup = \"true\"\n")
+		c.AddTask(&nodetasks.File{
+			Path:     gVisorConfigFilePath,
+			Contents: fi.NewStringResource(conf.String()),
+			Type:     nodetasks.FileType_File,
+		})
+	}
+
+	if containerd.Kata != nil && fi.ValueOf(containerd.Kata.Enabled) {
+		assetName, res, err := b.Assets.FindMatch(regexp.MustCompile(`kata-static-.*\.tar\.xz$`))
+		if err != nil {
+			return err
+		}
+
+		// The static release holds ./opt/kata/..., which we expand in place, keeping its symlinks
+		archive := &nodetasks.Archive{
+			Name:            "kata",
+			Source:          assetName,
+			Contents:        res,
+			TargetDir:       filepath.Dir(kataDir),
+			StripComponents: 2,
+		}
+		if hs, ok := res.(fi.HasSource); ok && hs.GetSource() != nil {
+			source := hs.GetSource()
+			archive.Source = source.URL
+			if source.Hash != nil {
+				archive.Hash = source.Hash.String()
+			}
+		}
+		c.AddTask(archive)
+	}
+
+	return nil
+}
+
+// appendSandboxRuntimesConfig registers the handlers of the sandboxed runtimes enabled on the instance group.
+// The handlers are named after the RuntimeClasses created by kOps.
+func (b *ContainerdBuilder) appendSandboxRuntimesConfig(config *toml.Tree, runtimePlugin string) error {
+	containerd := b.NodeupConfig.ContainerdConfig
+
+	for _, runtime := range containerd.SandboxRuntimes() {
+		var runtimeConfig map[string]interface{}
+		switch runtime {
+		case kops.SandboxRuntimeGVisor:
+			runtimeConfig = map[string]interface{}{
+				"runtime_type": "io.containerd.runsc.v1",
+				"options": map[string]interface{}{
+					"TypeUrl":    "io.containerd.runsc.v1.options",
+					"ConfigPath": gVisorConfigFilePath,
+				},
+			}
+		case kops.SandboxRuntimeKata:
+			runtimeConfig = map[string]interface{}{
+				"runtime_type":                    "io.containerd.kata.v2",
+				"runtime_path":                    kataDir + "/bin/containerd-shim-kata-v2",
+				"privileged_without_host_devices": true,
+				"pod_annotations":                 []string{"io.katacontainers.*"},
+				"options": map[string]interface{}{
+					"ConfigPath": kataDir + "/share/defaults/kata-containers/configuration.toml",
+				},
+			}
+		default:
+			return fmt.Errorf("unknown sandboxed runtime %q", runtime)
+		}
+
+		tree, err := toml.TreeFromMap(runtimeConfig)
+		if err != nil {
+			return err
+		}
+		config.SetPath([]string{"plugins", runtimePlugin, "containerd", "runtimes", runtime}, tree)
+	}
+
+	return nil
+}
@@ -20,11 +20,13 @@ import (
 	"fmt"
 	"path"
 	"path/filepath"
+	"reflect"
 	"testing"
 
 	"github.com/pelletier/go-toml"
 	"k8s.io/kops/pkg/apis/kops"
 	"k8s.io/kops/pkg/apis/nodeup"
+	"k8s.io/kops/pkg/configserver"
 	"k8s.io/kops/pkg/diff"
 	"k8s.io/kops/pkg/flagbuilder"
 	"k8s.io/kops/pkg/testutils"
@@ -40,6 +42,10 @@ func TestContainerdBuilder_Flatcar(t *testing.T) {
 	runContainerdBuilderTest(t, "flatcar", distributions.DistributionFlatcar)
 }
 
+func TestContainerdBuilder_FedoraCoreOS(t *testing.T) {
+	runContainerdBuilderTest(t, "fedoracoreos", distributions.DistributionFedoraCoreOS)
+}
+
 func TestContainerdBuilder_SkipInstall(t *testing.T) {
 	runContainerdBuilderTest(t, "skipinstall", distributions.DistributionUbuntu2004)
 }
@@ -48,6 +54,18 @@ func TestContainerdBuilder_Complex(t *testing.T) {
 	runContainerdBuilderTest(t, "complex", distributions.DistributionUbuntu2004)
 }
 
+func TestContainerdBuilder_Registries(t *testing.T) {
+	runContainerdBuilderTest(t, "registries", distributions.DistributionUbuntu2004)
+}
+
+func TestContainerdBuilder_Containerd2(t *testing.T) {
+	runContainerdBuilderTest(t, "containerd2", distributions.DistributionUbuntu2004)
+}
+
+func TestContainerdBuilder_SandboxRuntimes(t *testing.T) {
+	runContainerdBuilderTest(t, "sandboxruntimes", distributions.DistributionUbuntu2004)
+}
+
 func TestContainerdBuilder_BuildFlags(t *testing.T) {
 	grid := []struct {
 		config   kops.ContainerdConfig
@@ -149,6 +167,10 @@ func runContainerdBuilderTest(t *testing.T, key string, distro distributions.Dis
 
 	nodeUpModelContext.Distribution = distro
 
+	nodeUpModelContext.SecretStore = configserver.NewSecretStore(map[string][]byte{
+		nodeup.ContainerdRegistrySecretID("harbor"): []byte(`{"username":"robot","password":"secret","clientCertificate":"test certificate","clientKey":"test key"}`),
+	})
+
 	nodeUpModelContext.Assets = fi.NewAssetStore("")
 	nodeUpModelContext.Assets.AddForTest("containerd", "bin/containerd", "testing containerd content")
 	nodeUpModelContext.Assets.AddForTest("containerd-shim", "bin/containerd-shim", "testing containerd content")
@@ -157,6 +179,9 @@ func runContainerdBuilderTest(t *testing.T, key string, distro distributions.Dis
 	nodeUpModelContext.Assets.AddForTest("containerd-stress", "bin/containerd-stress", "testing containerd content")
 	nodeUpModelContext.Assets.AddForTest("ctr", "bin/ctr", "testing containerd content")
 	nodeUpModelContext.Assets.AddForTest("runc.amd64", "https://github.com/opencontainers/runc/releases/download/v1.1.0/runc.amd64", "testing runc content")
+	nodeUpModelContext.Assets.AddForTest("runsc", "https://storage.googleapis.com/gvisor/releases/release/20240401.0/x86_64/runsc", "testing gvisor content")
+	nodeUpModelContext.Assets.AddForTest("containerd-shim-runsc-v1", "https://storage.googleapis.com/gvisor/releases/release/20240401.0/x86_64/containerd-shim-runsc-v1", "testing gvisor content")
+	nodeUpModelContext.Assets.AddForTest("kata-static-3.3.0-amd64.tar.xz", "https://github.com/kata-containers/kata-containers/releases/download/3.3.0/kata-static-3.3.0-amd64.tar.xz", "testing kata content")
 
 	if err := nodeUpModelContext.Init(); err != nil {
 		t.Fatalf("error from nodeupModelContext.Init(): %v", err)
@@ -254,3 +279,107 @@ func TestAppendGPURuntimeContainerdConfig(t *testing.T) {
 		t.Error("new config did not match expected new config")
 	}
 }
+
+func TestAppendGPURuntimeContainerdConfigV3(t *testing.T) {
+	originalConfig := `version = 3
+[plugins]
+  [plugins."io.containerd.cri.v1.runtime"]
+	[plugins."io.containerd.cri.v1.runtime".containerd]
+	  [plugins."io.containerd.cri.v1.runtime".containerd.runtimes]
+		[plugins."io.containerd.cri.v1.runtime".containerd.runtimes.runc]
+		  runtime_type = "io.containerd.runc.v2"
+		  [plugins."io.containerd.cri.v1.runtime".containerd.runtimes.runc.options]
+			SystemdCgroup = true
+`
+
+	expectedNewConfig := `version = 3
+
+[plugins]
+
+  [plugins."io.containerd.cri.v1.runtime"]
+
+    [plugins."io.containerd.cri.v1.runtime".containerd]
+      default_runtime_name = "runc"
+
+      [plugins."io.containerd.cri.v1.runtime".containerd.runtimes]
+
+        [plugins."io.containerd.cri.v1.runtime".containerd.runtimes.nvidia]
+          privileged_without_host_devices = false
+          runtime_type = "io.containerd.runc.v2"
+
+          [plugins."io.containerd.cri.v1.runtime".containerd.runtimes.nvidia.options]
+            BinaryName = "/usr/bin/nvidia-container-runtime"
+            SystemdCgroup = true
+
+        [plugins."io.containerd.cri.v1.runtime".containerd.runtimes.runc]
+          runtime_type = "io.containerd.runc.v2"
+
+          [plugins."io.containerd.cri.v1.runtime".containerd.runtimes.runc.options]
+            SystemdCgroup = true
+`
+	config, err := toml.Load(originalConfig)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if err := appendNvidiaGPURuntimeConfigV3(config); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	newConfig, err := config.ToTomlString()
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	if newConfig != expectedNewConfig {
+		fmt.Println(diff.FormatDiff(expectedNewConfig, newConfig))
+		t.Error("new config did not match expected new config")
+	}
+}
+
+func TestMigrateContainerdConfigPathV3(t *testing.T) {
+	grid := []struct {
+		path     []string
+		expected []string
+	}{
+		{
+			path:     []string{"plugins", "io.containerd.grpc.v1.cri", "containerd", "runtimes", "runc", "options", "BinaryName"},
+			expected: []string{"plugins", "io.containerd.cri.v1.runtime", "containerd", "runtimes", "runc", "options", "BinaryName"},
+		},
+		{
+			path:     []string{"plugins", "io.containerd.grpc.v1.cri", "containerd", "snapshotter"},
+			expected: []string{"plugins", "io.containerd.cri.v1.images", "snapshotter"},
+		},
+		{
+			path:     []string{"plugins", "io.containerd.grpc.v1.cri", "sandbox_image"},
+			expected: []string{"plugins", "io.containerd.cri.v1.images", "pinned_images", "sandbox"},
+		},
+		{
+			path:     []string{"plugins", "io.containerd.grpc.v1.cri", "registry", "config_path"},
+			expected: []string{"plugins", "io.containerd.cri.v1.images", "registry", "config_path"},
+		},
+		{
+			path:     []string{"plugins", "io.containerd.grpc.v1.cri", "enable_cdi"},
+			expected: []string{"plugins", "io.containerd.cri.v1.runtime", "enable_cdi"},
+		},
+		{
+			path:     []string{"plugins", "io.containerd.grpc.v1.cri", "stream_server_port"},
+			expected: []string{"plugins", "io.containerd.grpc.v1.cri", "stream_server_port"},
+		},
+		{
+			path:     []string{"plugins", "io.containerd.cri.v1.images", "max_concurrent_downloads"},
+			expected: []string{"plugins", "io.containerd.cri.v1.images", "max_concurrent_downloads"},
+		},
+		{
+			path:     []string{"plugins", "io.containerd.nri.v1.nri", "disable"},
+			expected: []string{"plugins", "io.containerd.nri.v1.nri", "disable"},
+		},
+	}
+
+	for _, g := range grid {
+		actual := migrateContainerdConfigPathV3(g.path)
+		if !reflect.DeepEqual(actual, g.expected) {
+			t.Errorf("unexpected migrated path for %q: actual=%q expected=%q", g.path, actual, g.expected)
+		}
+	}
+}
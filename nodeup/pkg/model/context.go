@@ -133,6 +133,8 @@ func (c *NodeupModelContext) SSLHostPaths() []string {
 		paths = append(paths, "/usr/share/ca-certificates")
 	case distributions.DistributionContainerOS:
 		paths = append(paths, "/usr/share/ca-certificates")
+	case distributions.DistributionFedoraCoreOS:
+		// The /usr directory is read-only for Fedora CoreOS; the CA bundle is under /etc/pki
 	default:
 		paths = append(paths, "/usr/share/ssl", "/usr/ssl", "/usr/lib/ssl", "/usr/local/openssl", "/var/ssl", "/etc/openssl")
 	}
@@ -217,6 +219,15 @@ func (c *NodeupModelContext) PathSrvSshproxy() string {
 	}
 }
 
+// NodeupInstallDir is the directory the bootstrap script installs nodeup and its configuration in
+func (c *NodeupModelContext) NodeupInstallDir() string {
+	// On ContainerOS, /opt is ro and noexec
+	if c.Distribution == distributions.DistributionContainerOS {
+		return "/var/lib/toolbox/kops"
+	}
+	return "/opt/kops"
+}
+
 // KubeletKubeConfig is the path of the kubelet kubeconfig file
 func (c *NodeupModelContext) KubeletKubeConfig() string {
 	return "/var/lib/kubelet/kubeconfig"
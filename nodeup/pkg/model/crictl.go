@@ -60,6 +60,10 @@ func (b *CrictlBuilder) binaryPath() string {
 	if b.Distribution == distributions.DistributionFlatcar {
 		path = "/opt/kops/bin"
 	}
+	if b.Distribution == distributions.DistributionFedoraCoreOS {
+		// Next to containerd, as /usr/bin is read-only
+		path = "/usr/local/bin"
+	}
 	if b.Distribution == distributions.DistributionContainerOS {
 		path = "/home/kubernetes/bin"
 	}
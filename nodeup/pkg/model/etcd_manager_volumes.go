@@ -0,0 +1,48 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/cloudup/metal"
+	"k8s.io/kops/upup/pkg/fi/nodeup/nodetasks"
+)
+
+// EtcdManagerVolumesBuilder creates the local-disk volumes of etcd-manager on bare-metal hosts
+type EtcdManagerVolumesBuilder struct {
+	*NodeupModelContext
+}
+
+var _ fi.NodeupModelBuilder = &EtcdManagerVolumesBuilder{}
+
+// Build is responsible for creating the directories etcd-manager finds with its external volume provider
+func (b *EtcdManagerVolumesBuilder) Build(c *fi.NodeupModelBuilderContext) error {
+	if !b.IsMaster || b.CloudProvider() != kops.CloudProviderMetal {
+		return nil
+	}
+
+	for _, etcdClusterName := range b.NodeupConfig.EtcdClusterNames {
+		c.AddTask(&nodetasks.File{
+			Path: metal.EtcdVolumePath(b.NodeupConfig.ClusterName, etcdClusterName, b.BootConfig.InstanceGroupName),
+			Type: nodetasks.FileType_Directory,
+			Mode: s("0700"),
+		})
+	}
+
+	return nil
+}
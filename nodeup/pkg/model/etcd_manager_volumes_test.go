@@ -0,0 +1,98 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/apis/nodeup"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/nodeup/nodetasks"
+)
+
+func TestEtcdManagerVolumesBuilder(t *testing.T) {
+	grid := []struct {
+		Description   string
+		CloudProvider kops.CloudProviderID
+		IsMaster      bool
+		Expected      []string
+	}{
+		{
+			Description:   "bare-metal control plane",
+			CloudProvider: kops.CloudProviderMetal,
+			IsMaster:      true,
+			Expected: []string{
+				"/mnt/disks/metal.example.com--events--control-plane/mnt",
+				"/mnt/disks/metal.example.com--main--control-plane/mnt",
+			},
+		},
+		{
+			Description:   "bare-metal node",
+			CloudProvider: kops.CloudProviderMetal,
+		},
+		{
+			Description:   "cloud control plane",
+			CloudProvider: kops.CloudProviderAWS,
+			IsMaster:      true,
+		},
+	}
+	for _, g := range grid {
+		t.Run(g.Description, func(t *testing.T) {
+			builder := EtcdManagerVolumesBuilder{
+				NodeupModelContext: &NodeupModelContext{
+					BootConfig: &nodeup.BootConfig{
+						CloudProvider:     g.CloudProvider,
+						InstanceGroupName: "control-plane",
+					},
+					NodeupConfig: &nodeup.Config{
+						ClusterName:      "metal.example.com",
+						EtcdClusterNames: []string{"main", "events"},
+					},
+					IsMaster: g.IsMaster,
+				},
+			}
+			c := &fi.NodeupModelBuilderContext{
+				Tasks: make(map[string]fi.NodeupTask),
+			}
+			c = c.WithContext(context.TODO())
+			if err := builder.Build(c); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var paths []string
+			for _, task := range c.Tasks {
+				file := task.(*nodetasks.File)
+				if file.Type != nodetasks.FileType_Directory {
+					t.Errorf("expected directory %q, got %q", file.Path, file.Type)
+				}
+				paths = append(paths, file.Path)
+			}
+			sort.Strings(paths)
+			if len(paths) != len(g.Expected) {
+				t.Fatalf("expected directories %v, got %v", g.Expected, paths)
+			}
+			for i := range paths {
+				if paths[i] != g.Expected[i] {
+					t.Errorf("expected directories %v, got %v", g.Expected, paths)
+				}
+			}
+		})
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"k8s.io/kops/pkg/systemd"
 	"k8s.io/kops/upup/pkg/fi"
 	"k8s.io/kops/upup/pkg/fi/nodeup/nodetasks"
+	"k8s.io/kops/util/pkg/distributions"
 )
 
 // FirewallBuilder configures the firewall (iptables)
@@ -36,9 +37,40 @@ func (b *FirewallBuilder) Build(c *fi.NodeupModelBuilderContext) error {
 	c.AddTask(b.buildFirewallScript())
 	c.AddTask(b.buildSystemdService())
 
+	// firewalld replaces the rules of kube-proxy and the CNI plugins when it reloads.
+	// We only mask it on the distros where it is enabled in the images we support, and
+	// leave the existing RHEL 8/9 and Rocky 8 nodes as they were.
+	switch b.Distribution {
+	case distributions.DistributionRocky9, distributions.DistributionAlmaLinux8, distributions.DistributionAlmaLinux9:
+		c.AddTask(b.buildDisableFirewalldService())
+	}
+
 	return nil
 }
 
+func (b *FirewallBuilder) buildDisableFirewalldService() *nodetasks.Service {
+	manifest := &systemd.Manifest{}
+	manifest.Set("Unit", "Description", "Disable firewalld for kubernetes")
+	manifest.Set("Unit", "Documentation", "https://github.com/kubernetes/kops")
+	manifest.Set("Unit", "Before", "kubernetes-iptables-setup.service")
+	manifest.Set("Service", "Type", "oneshot")
+	manifest.Set("Service", "RemainAfterExit", "yes")
+	manifest.Set("Service", "ExecStart", "/usr/bin/systemctl mask --now firewalld.service")
+	manifest.Set("Install", "WantedBy", "basic.target")
+
+	manifestString := manifest.Render()
+	klog.V(8).Infof("Built service manifest %q\n%s", "kops-disable-firewalld", manifestString)
+
+	service := &nodetasks.Service{
+		Name:       "kops-disable-firewalld.service",
+		Definition: s(manifestString),
+	}
+
+	service.InitDefaults()
+
+	return service
+}
+
 func (b *FirewallBuilder) buildSystemdService() *nodetasks.Service {
 	manifest := &systemd.Manifest{}
 	manifest.Set("Unit", "Description", "Configure iptables for kubernetes")
@@ -0,0 +1,41 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"testing"
+
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/util/pkg/distributions"
+)
+
+func TestFirewallBuilderRocky9(t *testing.T) {
+	RunGoldenTest(t, "tests/firewallbuilder/minimal", "firewall-rocky9", func(nodeupModelContext *NodeupModelContext, target *fi.NodeupModelBuilderContext) error {
+		nodeupModelContext.Distribution = distributions.DistributionRocky9
+		builder := FirewallBuilder{NodeupModelContext: nodeupModelContext}
+		return builder.Build(target)
+	})
+}
+
+func TestFirewallBuilderRhel8(t *testing.T) {
+	RunGoldenTest(t, "tests/firewallbuilder/minimal", "firewall-rhel8", func(nodeupModelContext *NodeupModelContext, target *fi.NodeupModelBuilderContext) error {
+		// firewalld is left alone on the distros that were supported before it was masked
+		nodeupModelContext.Distribution = distributions.DistributionRhel8
+		builder := FirewallBuilder{NodeupModelContext: nodeupModelContext}
+		return builder.Build(target)
+	})
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"strings"
+
+	"k8s.io/kops/pkg/systemd"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/nodeup/nodetasks"
+)
+
+const inPlaceUpdateServiceName = "kops-configuration-update"
+
+// InPlaceUpdateBuilder installs a timer that periodically applies configuration changes to the running node.
+type InPlaceUpdateBuilder struct {
+	*NodeupModelContext
+}
+
+var _ fi.NodeupModelBuilder = &InPlaceUpdateBuilder{}
+
+// Build is responsible for configuring the in-place update service and timer.
+func (b *InPlaceUpdateBuilder) Build(c *fi.NodeupModelBuilderContext) error {
+	if !b.NodeupConfig.InPlaceUpdates {
+		return nil
+	}
+
+	installDir := b.NodeupInstallDir()
+
+	command := []string{
+		installDir + "/bin/nodeup",
+		"--conf=" + installDir + "/conf/kube_env.yaml",
+		"--in-place-update",
+		"--retries=0",
+		"--v=2",
+	}
+
+	{
+		manifest := &systemd.Manifest{}
+		manifest.Set("Unit", "Description", "Apply kOps configuration changes (nodeup)")
+		manifest.Set("Unit", "Documentation", "https://github.com/kubernetes/kops")
+		manifest.Set("Unit", "After", "kops-configuration.service")
+
+		manifest.Set("Service", "EnvironmentFile", "/etc/sysconfig/kops-configuration")
+		manifest.Set("Service", "EnvironmentFile", "/etc/environment")
+		manifest.Set("Service", "ExecStart", strings.Join(command, " "))
+		manifest.Set("Service", "Type", "oneshot")
+
+		// The timer starts the service; we don't want a configuration change to run it right away
+		service := &nodetasks.Service{
+			Name:        inPlaceUpdateServiceName + ".service",
+			Definition:  s(manifest.Render()),
+			ManageState: fi.PtrTo(false),
+		}
+		service.InitDefaults()
+		c.AddTask(service)
+	}
+
+	{
+		manifest := &systemd.Manifest{}
+		manifest.Set("Unit", "Description", "Periodically apply kOps configuration changes")
+		manifest.Set("Timer", "OnActiveSec", "5min")
+		manifest.Set("Timer", "OnUnitInactiveSec", "5min")
+		manifest.Set("Timer", "RandomizedDelaySec", "1min")
+
+		service := &nodetasks.Service{
+			Name:       inPlaceUpdateServiceName + ".timer", // Started by nodeup on every boot
+			Definition: s(manifest.Render()),
+		}
+		service.InitDefaults()
+		c.AddTask(service)
+	}
+
+	return nil
+}
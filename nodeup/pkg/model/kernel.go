@@ -0,0 +1,165 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"path"
+	"strings"
+
+	"k8s.io/klog/v2"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/nodeup/nodetasks"
+	"k8s.io/kops/util/pkg/distributions"
+)
+
+const (
+	// RebootRequiredPath is created when a change to the kernel configuration only takes effect after a reboot.
+	RebootRequiredPath = "/run/kops-reboot-required"
+
+	kernelModulesLoadPath    = "/etc/modules-load.d/99-kops.conf"
+	kernelModulesOptionsPath = "/etc/modprobe.d/99-kops.conf"
+
+	// kernelBootParametersPath holds the boot parameters of the instance group,
+	// and kernelBootParametersAppliedPath the boot parameters that were last added to the boot configuration.
+	kernelBootParametersPath        = "/etc/kubernetes/kernel-boot-parameters"
+	kernelBootParametersAppliedPath = "/var/lib/kops/kernel-boot-parameters"
+)
+
+// KernelBuilder loads kernel modules and sets kernel boot parameters
+type KernelBuilder struct {
+	*NodeupModelContext
+}
+
+var _ fi.NodeupModelBuilder = &KernelBuilder{}
+
+// Build is responsible for configuring the kernel modules and boot parameters
+func (b *KernelBuilder) Build(c *fi.NodeupModelBuilderContext) error {
+	b.buildKernelModules(c)
+
+	if b.Distribution == distributions.DistributionContainerOS {
+		if len(b.NodeupConfig.KernelBootParameters) > 0 {
+			klog.Warningf("kernel boot parameters are not supported on ContainerOS, skipping: %v", b.NodeupConfig.KernelBootParameters)
+		}
+		return nil
+	}
+
+	// The file is written even without boot parameters, so that removing them updates the boot configuration
+	c.AddTask(&nodetasks.File{
+		Path:            kernelBootParametersPath,
+		Contents:        fi.NewStringResource(strings.Join(b.NodeupConfig.KernelBootParameters, " ") + "\n"),
+		Type:            nodetasks.FileType_File,
+		OnChangeExecute: [][]string{{"/bin/bash", "-c", b.bootParametersScript()}},
+	})
+
+	return nil
+}
+
+// buildKernelModules loads the kernel modules on boot, with their options.
+func (b *KernelBuilder) buildKernelModules(c *fi.NodeupModelBuilderContext) {
+	var names, options, loaded []string
+	for _, module := range b.NodeupConfig.KernelModules {
+		names = append(names, module.Name)
+		if len(module.Options) > 0 {
+			options = append(options, "options "+module.Name+" "+strings.Join(module.Options, " "))
+			// Options of a module that is already loaded only take effect after a reboot
+			loaded = append(loaded, "[[ -d /sys/module/"+module.Name+" ]]")
+		}
+	}
+
+	optionsFile := &nodetasks.File{
+		Path:     kernelModulesOptionsPath,
+		Contents: fi.NewStringResource(kernelModulesFile(options)),
+		Type:     nodetasks.FileType_File,
+	}
+	if len(loaded) > 0 {
+		optionsFile.OnChangeExecute = [][]string{{"/bin/bash", "-c", "if " + strings.Join(loaded, " || ") + "; then touch " + RebootRequiredPath + "; fi"}}
+	}
+	c.AddTask(optionsFile)
+
+	c.AddTask(&nodetasks.File{
+		Path:            kernelModulesLoadPath,
+		Contents:        fi.NewStringResource(kernelModulesFile(names)),
+		Type:            nodetasks.FileType_File,
+		AfterFiles:      []string{kernelModulesOptionsPath},
+		OnChangeExecute: [][]string{{"systemctl", "restart", "systemd-modules-load.service"}},
+	})
+}
+
+func kernelModulesFile(lines []string) string {
+	return "# Built by kOps - do NOT edit\n" + strings.Join(append(lines, ""), "\n")
+}
+
+// bootParametersScript returns the script that replaces the boot parameters applied by a previous run with the current ones.
+func (b *KernelBuilder) bootParametersScript() string {
+	var update string
+	switch {
+	case b.Distribution == distributions.DistributionFlatcar:
+		update = `cfg=/oem/grub.cfg
+if [[ ! -d /oem ]]; then
+  cfg=/usr/share/oem/grub.cfg
+fi
+touch "${cfg}"
+sed -i '/# kops$/d' "${cfg}"
+if [[ -n "${new}" ]]; then
+  echo "set linux_append=\"\$linux_append ${new}\" # kops" >> "${cfg}"
+fi
+`
+	case b.Distribution == distributions.DistributionFedoraCoreOS:
+		update = `args=()
+for arg in ${old}; do
+  args+=("--delete-if-present=${arg}")
+done
+for arg in ${new}; do
+  args+=("--append-if-missing=${arg}")
+done
+rpm-ostree kargs "${args[@]}"
+`
+	case b.Distribution.IsDebianFamily():
+		update = `mkdir -p /etc/default/grub.d
+if [[ -n "${new}" ]]; then
+  echo "GRUB_CMDLINE_LINUX=\"\${GRUB_CMDLINE_LINUX} ${new}\"" > /etc/default/grub.d/99-kops.cfg
+else
+  rm -f /etc/default/grub.d/99-kops.cfg
+fi
+update-grub
+`
+	default:
+		update = `if [[ -n "${old}" ]]; then
+  grubby --update-kernel=ALL --remove-args="${old}"
+fi
+if [[ -n "${new}" ]]; then
+  grubby --update-kernel=ALL --args="${new}"
+fi
+`
+	}
+
+	return `set -o errexit
+set -o nounset
+set -o pipefail
+
+new="$(cat ` + kernelBootParametersPath + `)"
+old="$(cat ` + kernelBootParametersAppliedPath + ` 2>/dev/null || true)"
+if [[ "${old}" == "${new}" ]]; then
+  exit 0
+fi
+
+` + update + `
+mkdir -p ` + path.Dir(kernelBootParametersAppliedPath) + `
+echo "${new}" > ` + kernelBootParametersAppliedPath + `
+touch ` + RebootRequiredPath + `
+`
+}
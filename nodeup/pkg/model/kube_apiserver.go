@@ -29,6 +29,7 @@ import (
 	"k8s.io/kops/pkg/kubeconfig"
 	"k8s.io/kops/pkg/kubemanifest"
 	"k8s.io/kops/pkg/model/components"
+	"k8s.io/kops/pkg/rbac"
 	"k8s.io/kops/pkg/tokens"
 	"k8s.io/kops/pkg/wellknownports"
 	"k8s.io/kops/pkg/wellknownusers"
@@ -81,6 +82,9 @@ func (b *KubeAPIServerBuilder) Build(c *fi.NodeupModelBuilderContext) error {
 	if err := b.writeAuthenticationConfig(c, &kubeAPIServer); err != nil {
 		return err
 	}
+	if err := b.writeAuthorizationConfig(c, &kubeAPIServer); err != nil {
+		return err
+	}
 
 	if b.NodeupConfig.APIServerConfig.EncryptionConfigSecretHash != "" {
 		encryptionConfigPath := fi.PtrTo(filepath.Join(pathSrvKAPI, "encryptionconfig.yaml"))
@@ -385,6 +389,169 @@ func (b *KubeAPIServerBuilder) writeAuthenticationConfig(c *fi.NodeupModelBuilde
 	return fmt.Errorf("unrecognized authentication config %v", b.NodeupConfig.APIServerConfig.Authentication)
 }
 
+// usesAdminCredentialDenyList returns whether the requests of admin credentials are authorized by kops-controller first.
+func (b *KubeAPIServerBuilder) usesAdminCredentialDenyList() bool {
+	return b.NodeupConfig.APIServerConfig.Authentication != nil && b.NodeupConfig.APIServerConfig.Authentication.AdminCredentialDenyList
+}
+
+// authorizationConfiguration is the structured authorization configuration of kube-apiserver.
+type authorizationConfiguration struct {
+	APIVersion  string                    `json:"apiVersion"`
+	Kind        string                    `json:"kind"`
+	Authorizers []authorizerConfiguration `json:"authorizers"`
+}
+
+type authorizerConfiguration struct {
+	Type    string                          `json:"type"`
+	Name    string                          `json:"name"`
+	Webhook *webhookAuthorizerConfiguration `json:"webhook,omitempty"`
+}
+
+type webhookAuthorizerConfiguration struct {
+	Timeout                                  string                      `json:"timeout"`
+	SubjectAccessReviewVersion               string                      `json:"subjectAccessReviewVersion"`
+	MatchConditionSubjectAccessReviewVersion string                      `json:"matchConditionSubjectAccessReviewVersion"`
+	FailurePolicy                            string                      `json:"failurePolicy"`
+	AuthorizedTTL                            string                      `json:"authorizedTTL"`
+	UnauthorizedTTL                          string                      `json:"unauthorizedTTL"`
+	ConnectionInfo                           webhookConnectionInfo       `json:"connectionInfo"`
+	MatchConditions                          []webhookMatchConditionInfo `json:"matchConditions,omitempty"`
+}
+
+type webhookConnectionInfo struct {
+	Type           string `json:"type"`
+	KubeConfigFile string `json:"kubeConfigFile,omitempty"`
+}
+
+type webhookMatchConditionInfo struct {
+	Expression string `json:"expression"`
+}
+
+// writeAuthorizationConfig writes the structured authorization configuration used by the admin credential deny list.
+// The requests of admin credentials are first reviewed by kops-controller, which denies those of revoked credentials,
+// then by the authorizers of the configured authorization modes.
+func (b *KubeAPIServerBuilder) writeAuthorizationConfig(c *fi.NodeupModelBuilderContext, kubeAPIServer *kops.KubeAPIServerConfig) error {
+	if !b.usesAdminCredentialDenyList() {
+		return nil
+	}
+
+	pathSrvKAPI := filepath.Join(b.PathSrvKubernetes(), "kube-apiserver")
+	webhookKubeconfigPath := filepath.Join(pathSrvKAPI, "admin-credential-webhook.kubeconfig")
+
+	{
+		// kops-controller runs on the control plane nodes
+		host := "127.0.0.1"
+		if !b.IsMaster {
+			host = "kops-controller.internal." + b.NodeupConfig.ClusterName
+		}
+		config := kubeconfig.KubectlConfig{
+			Kind:       "Config",
+			ApiVersion: "v1",
+		}
+		config.Clusters = append(config.Clusters, &kubeconfig.KubectlClusterWithName{
+			Name: "kops-controller",
+			Cluster: kubeconfig.KubectlCluster{
+				Server:                   fmt.Sprintf("https://%s:%d/admin-credential-review", host, wellknownports.KopsControllerPort),
+				CertificateAuthorityData: []byte(b.NodeupConfig.CAs[fi.CertificateIDCA]),
+				TLSServerName:            "kops-controller.internal." + b.NodeupConfig.ClusterName,
+			},
+		})
+		// kops-controller only answers reviews from kube-apiserver
+		issueCert := &nodetasks.IssueCert{
+			Name:      "admin-credential-reviewer",
+			Signer:    fi.CertificateIDCA,
+			KeypairID: b.NodeupConfig.KeypairIDs[fi.CertificateIDCA],
+			Type:      "client",
+			Subject:   nodetasks.PKIXName{CommonName: rbac.KopsAdminCredentialReviewer},
+		}
+		c.AddTask(issueCert)
+		if err := issueCert.AddFileTasks(c, pathSrvKAPI, issueCert.Name, "", nil); err != nil {
+			return err
+		}
+		config.Users = append(config.Users, &kubeconfig.KubectlUserWithName{
+			Name: "kube-apiserver",
+			User: kubeconfig.KubectlUser{
+				ClientCertificate: filepath.Join(pathSrvKAPI, issueCert.Name+".crt"),
+				ClientKey:         filepath.Join(pathSrvKAPI, issueCert.Name+".key"),
+			},
+		})
+		config.CurrentContext = "webhook"
+		config.Contexts = append(config.Contexts, &kubeconfig.KubectlContextWithName{
+			Name: "webhook",
+			Context: kubeconfig.KubectlContext{
+				Cluster: "kops-controller",
+				User:    "kube-apiserver",
+			},
+		})
+
+		manifest, err := kops.ToRawYaml(config)
+		if err != nil {
+			return fmt.Errorf("error marshaling admin credential webhook config to yaml: %v", err)
+		}
+
+		c.AddTask(&nodetasks.File{
+			Path:     webhookKubeconfigPath,
+			Contents: fi.NewBytesResource(manifest),
+			Type:     nodetasks.FileType_File,
+			Mode:     fi.PtrTo("600"),
+		})
+	}
+
+	config := authorizationConfiguration{
+		APIVersion: "apiserver.config.k8s.io/v1beta1",
+		Kind:       "AuthorizationConfiguration",
+	}
+	if b.IsKubernetesGTE("1.32") {
+		config.APIVersion = "apiserver.config.k8s.io/v1"
+	}
+	config.Authorizers = append(config.Authorizers, authorizerConfiguration{
+		Type: "Webhook",
+		Name: "admin-credential-deny-list",
+		Webhook: &webhookAuthorizerConfiguration{
+			Timeout:                                  "3s",
+			SubjectAccessReviewVersion:               "v1",
+			MatchConditionSubjectAccessReviewVersion: "v1",
+			// Requests are authorized by the following authorizers if kops-controller is unavailable,
+			// so that admin credentials can be used to recover the cluster.
+			// This means revoked admin credentials are not denied while kops-controller is down.
+			FailurePolicy:   "NoOpinion",
+			AuthorizedTTL:   "30s",
+			UnauthorizedTTL: "30s",
+			ConnectionInfo: webhookConnectionInfo{
+				Type:           "KubeConfigFile",
+				KubeConfigFile: webhookKubeconfigPath,
+			},
+			MatchConditions: []webhookMatchConditionInfo{
+				{Expression: fmt.Sprintf("'%s' in request.groups", rbac.KopsAdminsGroup)},
+			},
+		},
+	})
+	if kubeAPIServer.AuthorizationMode != nil {
+		for _, mode := range strings.Split(*kubeAPIServer.AuthorizationMode, ",") {
+			config.Authorizers = append(config.Authorizers, authorizerConfiguration{
+				Type: mode,
+				Name: strings.ToLower(mode),
+			})
+		}
+	}
+	// The authorization modes and the authorization configuration are mutually exclusive
+	kubeAPIServer.AuthorizationMode = nil
+
+	manifest, err := kops.ToRawYaml(config)
+	if err != nil {
+		return fmt.Errorf("error marshaling authorization config to yaml: %v", err)
+	}
+
+	c.AddTask(&nodetasks.File{
+		Path:     filepath.Join(pathSrvKAPI, "authorization-config.yaml"),
+		Contents: fi.NewBytesResource(manifest),
+		Type:     nodetasks.FileType_File,
+		Mode:     fi.PtrTo("600"),
+	})
+
+	return nil
+}
+
 func (b *KubeAPIServerBuilder) writeServerCertificate(c *fi.NodeupModelBuilderContext, kubeAPIServer *kops.KubeAPIServerConfig) error {
 	pathSrvKAPI := filepath.Join(b.PathSrvKubernetes(), "kube-apiserver")
 
@@ -574,6 +741,9 @@ func (b *KubeAPIServerBuilder) buildPod(ctx context.Context, kubeAPIServer *kops
 	}
 
 	flags = append(flags, fmt.Sprintf("--cloud-config=%s", InTreeCloudConfigFilePath))
+	if b.usesAdminCredentialDenyList() {
+		flags = append(flags, fmt.Sprintf("--authorization-config=%s", filepath.Join(b.PathSrvKubernetes(), "kube-apiserver", "authorization-config.yaml")))
+	}
 
 	pod := &v1.Pod{
 		TypeMeta: metav1.TypeMeta{
@@ -162,6 +162,13 @@ func TestOIDCConfigAPIServerBuilder(t *testing.T) {
 	})
 }
 
+func TestAdminCredentialDenyListAPIServerBuilder(t *testing.T) {
+	RunGoldenTest(t, "tests/golden/admin-credential-deny-list", "kube-apiserver", func(nodeupModelContext *NodeupModelContext, target *fi.NodeupModelBuilderContext) error {
+		builder := KubeAPIServerBuilder{NodeupModelContext: nodeupModelContext}
+		return builder.Build(target)
+	})
+}
+
 func TestDeddicatedAPIServerBuilder(t *testing.T) {
 	RunGoldenTest(t, "tests/golden/dedicated-apiserver", "kube-apiserver", func(nodeupModelContext *NodeupModelContext, target *fi.NodeupModelBuilderContext) error {
 		builder := KubeAPIServerBuilder{NodeupModelContext: nodeupModelContext}
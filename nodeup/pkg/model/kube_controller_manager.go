@@ -190,8 +190,8 @@ func (b *KubeControllerManagerBuilder) buildPod(kcm *kops.KubeControllerManagerC
 			// Default is different on ContainerOS, see https://github.com/kubernetes/kubernetes/pull/58171
 			volumePluginDir = "/home/kubernetes/flexvolume/"
 
-		case distributions.DistributionFlatcar:
-			// The /usr directory is read-only for Flatcar
+		case distributions.DistributionFlatcar, distributions.DistributionFedoraCoreOS:
+			// The /usr directory is read-only for Flatcar and Fedora CoreOS
 			volumePluginDir = "/var/lib/kubelet/volumeplugins/"
 
 		default:
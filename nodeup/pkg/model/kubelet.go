@@ -693,8 +693,8 @@ func (b *KubeletBuilder) buildKubeletConfigSpec(ctx context.Context) (*kops.Kube
 			// Default is different on ContainerOS, see https://github.com/kubernetes/kubernetes/pull/58171
 			c.VolumePluginDirectory = "/home/kubernetes/flexvolume/"
 
-		case distributions.DistributionFlatcar:
-			// The /usr directory is read-only for Flatcar
+		case distributions.DistributionFlatcar, distributions.DistributionFedoraCoreOS:
+			// The /usr directory is read-only for Flatcar and Fedora CoreOS
 			c.VolumePluginDirectory = "/var/lib/kubelet/volumeplugins/"
 
 		default:
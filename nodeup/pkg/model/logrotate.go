@@ -42,6 +42,8 @@ func (b *LogrotateBuilder) Build(c *fi.NodeupModelBuilderContext) error {
 		return nil
 	case distributions.DistributionFlatcar:
 		klog.Infof("Detected Flatcar; won't install logrotate")
+	case distributions.DistributionFedoraCoreOS:
+		klog.Infof("Detected Fedora CoreOS; won't install logrotate")
 	default:
 		c.AddTask(&nodetasks.Package{Name: "logrotate"})
 	}
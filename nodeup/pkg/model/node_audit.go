@@ -0,0 +1,95 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"strconv"
+	"strings"
+
+	"k8s.io/kops/pkg/apis/nodeup"
+	"k8s.io/kops/pkg/systemd"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/nodeup/nodetasks"
+)
+
+const nodeAuditServiceName = "kops-node-audit"
+
+// NodeAuditBuilder installs a timer that periodically compares the node with its configuration.
+type NodeAuditBuilder struct {
+	*NodeupModelContext
+}
+
+var _ fi.NodeupModelBuilder = &NodeAuditBuilder{}
+
+// Build is responsible for configuring the node audit service and timer.
+func (b *NodeAuditBuilder) Build(c *fi.NodeupModelBuilderContext) error {
+	if b.NodeupConfig.NodeAuditInterval == nil {
+		return nil
+	}
+
+	installDir := b.NodeupInstallDir()
+
+	command := []string{
+		installDir + "/bin/nodeup",
+		"--conf=" + installDir + "/conf/kube_env.yaml",
+		"--audit",
+		"--audit-output=" + nodeup.NodeAuditReportPath,
+		"--audit-update-node",
+		"--retries=0",
+		"--v=1",
+	}
+
+	{
+		manifest := &systemd.Manifest{}
+		manifest.Set("Unit", "Description", "Audit kOps node configuration (nodeup)")
+		manifest.Set("Unit", "Documentation", "https://github.com/kubernetes/kops")
+		manifest.Set("Unit", "After", "kops-configuration.service")
+
+		manifest.Set("Service", "EnvironmentFile", "/etc/sysconfig/kops-configuration")
+		manifest.Set("Service", "EnvironmentFile", "/etc/environment")
+		manifest.Set("Service", "ExecStart", strings.Join(command, " "))
+		manifest.Set("Service", "Type", "oneshot")
+
+		// The timer starts the service
+		service := &nodetasks.Service{
+			Name:        nodeAuditServiceName + ".service",
+			Definition:  s(manifest.Render()),
+			ManageState: fi.PtrTo(false),
+		}
+		service.InitDefaults()
+		c.AddTask(service)
+	}
+
+	{
+		interval := strconv.Itoa(int(b.NodeupConfig.NodeAuditInterval.Duration.Seconds())) + "s"
+
+		manifest := &systemd.Manifest{}
+		manifest.Set("Unit", "Description", "Periodically audit kOps node configuration")
+		manifest.Set("Timer", "OnActiveSec", interval)
+		manifest.Set("Timer", "OnUnitInactiveSec", interval)
+		manifest.Set("Timer", "RandomizedDelaySec", "5min")
+
+		service := &nodetasks.Service{
+			Name:       nodeAuditServiceName + ".timer", // Started by nodeup on every boot
+			Definition: s(manifest.Render()),
+		}
+		service.InitDefaults()
+		c.AddTask(service)
+	}
+
+	return nil
+}
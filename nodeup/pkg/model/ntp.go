@@ -76,6 +76,12 @@ func (b *NTPBuilder) Build(c *fi.NodeupModelBuilderContext) error {
 			c.AddTask(b.buildChronydConf("/etc/chrony.conf", ntpHost))
 		}
 		c.AddTask((&nodetasks.Service{Name: "chronyd"}).InitDefaults())
+	} else if b.Distribution == distributions.DistributionFedoraCoreOS {
+		// chrony is part of the Fedora CoreOS image
+		if ntpHost != "" {
+			c.AddTask(b.buildChronydConf("/etc/chrony.conf", ntpHost))
+		}
+		c.AddTask((&nodetasks.Service{Name: "chronyd"}).InitDefaults())
 	} else {
 		klog.Warningf("unknown distribution, skipping ntp install: %v", b.Distribution)
 		return nil
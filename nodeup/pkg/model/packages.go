@@ -76,14 +76,19 @@ func (b *PackagesBuilder) Build(c *fi.NodeupModelBuilderContext) error {
 			c.AddTask(&nodetasks.Package{Name: "container-selinux"})
 			c.AddTask(&nodetasks.Package{Name: "pigz"})
 		}
-		// RHEL9 does not have libcgroup
-		if b.Distribution != distributions.DistributionRhel9 {
+		// RHEL9 and its rebuilds do not have libcgroup
+		if !b.Distribution.IsEnterpriseLinux() || b.Distribution.Version() < 9 {
 			c.AddTask(&nodetasks.Package{Name: "libcgroup"})
 		}
 		// Additional packages
 		for _, additionalPackage := range b.NodeupConfig.Packages {
 			c.EnsureTask(&nodetasks.Package{Name: additionalPackage})
 		}
+	} else if b.Distribution == distributions.DistributionFedoraCoreOS {
+		// The Fedora CoreOS image already includes the packages we need, including container-selinux
+		if len(b.NodeupConfig.Packages) != 0 {
+			klog.Warningf("additional packages are not supported on Fedora CoreOS, skipping install: %v", b.NodeupConfig.Packages)
+		}
 	} else {
 		// Hopefully they are already installed
 		klog.Warningf("unknown distribution, skipping required packages install: %v", b.Distribution)
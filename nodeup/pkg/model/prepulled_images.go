@@ -0,0 +1,61 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"path"
+
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/nodeup/nodetasks"
+)
+
+// PrepulledImagesBuilder pulls or loads the container images of the instance group before the kubelet starts
+type PrepulledImagesBuilder struct {
+	*NodeupModelContext
+}
+
+var _ fi.NodeupModelBuilder = &PrepulledImagesBuilder{}
+
+// Build is responsible for pulling or loading the container images of the instance group
+func (b *PrepulledImagesBuilder) Build(c *fi.NodeupModelBuilderContext) error {
+	for _, image := range b.NodeupConfig.PrepulledImages {
+		if len(image.Sources) > 0 {
+			name := image.Name
+			if name == "" {
+				name = path.Base(image.Sources[0])
+			}
+			c.AddTask(&nodetasks.LoadImageTask{
+				Name:    "prepulled-" + name,
+				Sources: image.Sources,
+				Hash:    image.Hash,
+			})
+			continue
+		}
+
+		// The same image may also be pulled for the warm pool; the kubelet waits for it either way
+		if existing, found := c.Tasks["PullImageTask/"+image.Name]; found {
+			existing.(*nodetasks.PullImageTask).BeforeKubelet = true
+			continue
+		}
+		c.AddTask(&nodetasks.PullImageTask{
+			Name:          image.Name,
+			BeforeKubelet: true,
+		})
+	}
+
+	return nil
+}
@@ -0,0 +1,45 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"testing"
+
+	"k8s.io/kops/pkg/apis/nodeup"
+	"k8s.io/kops/upup/pkg/fi"
+)
+
+func TestPrepulledImagesBuilder(t *testing.T) {
+	RunGoldenTest(t, "tests/prepulledimages/minimal", "prepulledimages", func(nodeupModelContext *NodeupModelContext, target *fi.NodeupModelBuilderContext) error {
+		// The images are remapped to the assets repositories when the nodeup config is built, see TestBuildPrepulledImages
+		nodeupModelContext.NodeupConfig.PrepulledImages = []*nodeup.Image{
+			{
+				Name: "registry.example.com/ml/trainer:v1.2",
+			},
+			{
+				Name: "registry.example.com/ml/model:v3@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+			},
+			{
+				Name:    "registry.example.com/ml/dataset:v7",
+				Sources: []string{"https://assets.example.com/images/dataset.tar.gz"},
+				Hash:    "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+			},
+		}
+		builder := PrepulledImagesBuilder{NodeupModelContext: nodeupModelContext}
+		return builder.Build(target)
+	})
+}
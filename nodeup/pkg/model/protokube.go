@@ -170,6 +170,9 @@ type ProtokubeFlags struct {
 	GossipProtocolSecondary *string `json:"gossip-protocol-secondary" flag:"gossip-protocol-secondary" flag-include-empty:"true"`
 	GossipListenSecondary   *string `json:"gossip-listen-secondary" flag:"gossip-listen-secondary"`
 	GossipSecretSecondary   *string `json:"gossip-secret-secondary" flag:"gossip-secret-secondary"`
+
+	GossipSecretAdditional []string `json:"gossip-secret-additional,omitempty" flag:"gossip-secret-additional"`
+	GossipKeyset           *bool    `json:"gossip-keyset,omitempty" flag:"gossip-keyset"`
 }
 
 // ProtokubeFlags is responsible for building the command line flags for protokube
@@ -214,6 +217,13 @@ func (t *ProtokubeBuilder) ProtokubeFlags() (*ProtokubeFlags, error) {
 			}
 		}
 
+		if keyset := t.NodeupConfig.GossipKeyset; keyset != nil {
+			f.GossipSecret = fi.PtrTo(keyset.Primary)
+			f.GossipSecretSecondary = fi.PtrTo(keyset.Primary)
+			f.GossipSecretAdditional = keyset.Secondary
+			f.GossipKeyset = fi.PtrTo(true)
+		}
+
 		// @TODO: This is hacky, but we want it so that we can have a different internal & external name
 		internalSuffix := t.APIInternalName()
 		internalSuffix = strings.TrimPrefix(internalSuffix, "api.")
@@ -308,6 +318,9 @@ func (t *ProtokubeBuilder) buildEnvFile() (*nodetasks.File, error) {
 	switch t.Distribution {
 	case distributions.DistributionFlatcar:
 		envVars["PATH"] = fmt.Sprintf("/opt/kops/bin:%v", os.Getenv("PATH"))
+	case distributions.DistributionFedoraCoreOS:
+		// Prefer the containerd tools installed by kops over the ones shipped with the OS
+		envVars["PATH"] = fmt.Sprintf("/usr/local/bin:/usr/local/sbin:%v", os.Getenv("PATH"))
 	}
 
 	sysconfig := ""
@@ -34,6 +34,7 @@ type UpdateServiceBuilder struct {
 const (
 	flatcarServiceName = "update-service"
 	debianPackageName  = "unattended-upgrades"
+	zincatiConfigPath  = "/etc/zincati/config.d/90-kops-updates.toml"
 )
 
 var _ fi.NodeupModelBuilder = &UpdateServiceBuilder{}
@@ -42,6 +43,8 @@ var _ fi.NodeupModelBuilder = &UpdateServiceBuilder{}
 func (b *UpdateServiceBuilder) Build(c *fi.NodeupModelBuilderContext) error {
 	if b.Distribution == distributions.DistributionFlatcar {
 		b.buildFlatcarSystemdService(c)
+	} else if b.Distribution == distributions.DistributionFedoraCoreOS {
+		b.buildZincatiConfig(c)
 	} else if b.Distribution.IsDebianFamily() {
 		b.buildDebianPackage(c)
 	}
@@ -80,6 +83,22 @@ func (b *UpdateServiceBuilder) buildFlatcarSystemdService(c *fi.NodeupModelBuild
 	c.AddTask(service)
 }
 
+// buildZincatiConfig disables the Fedora CoreOS update agent, which would reboot nodes outside of a rolling update.
+func (b *UpdateServiceBuilder) buildZincatiConfig(c *fi.NodeupModelBuilderContext) {
+	klog.Infof("Detected OS %v; disabling zincati automatic updates", b.Distribution)
+
+	contents := `# Built by kOps - do NOT edit
+
+[updates]
+enabled = false
+`
+	c.AddTask(&nodetasks.File{
+		Path:     zincatiConfigPath,
+		Contents: fi.NewStringResource(contents),
+		Type:     nodetasks.FileType_File,
+	})
+}
+
 func (b *UpdateServiceBuilder) buildDebianPackage(c *fi.NodeupModelBuilderContext) {
 	contents := ""
 	if b.NodeupConfig.UpdatePolicy == kops.UpdatePolicyExternal {
@@ -54,18 +54,28 @@ func (b *VolumesBuilder) Build(c *fi.NodeupModelBuilderContext) error {
 			Interface: mount.New(""),
 		}
 
+		// @check if the device must be unlocked, the unlocked device is mounted instead
+		device := x.Device
+		if x.Encryption != nil {
+			d, err := b.openEncryptedDevice(c.Context(), x)
+			if err != nil {
+				return fmt.Errorf("failed to open encrypted device: %s, error: %w", x.Device, err)
+			}
+			device = d
+		}
+
 		// @check if the device is already mounted
-		if found, err := b.IsMounted(m, x.Device, x.Path); err != nil {
-			return fmt.Errorf("failed to check if device %q is mounted, error: %w", x.Device, err)
+		if found, err := b.IsMounted(m, device, x.Path); err != nil {
+			return fmt.Errorf("failed to check if device %q is mounted, error: %w", device, err)
 		} else if found {
-			klog.V(3).Infof("Skipping device: %s, path: %s as already mounted", x.Device, x.Path)
+			klog.V(3).Infof("Skipping device: %s, path: %s as already mounted", device, x.Path)
 			continue
 		}
 
-		klog.Infof("Attempting to format and mount device: %s, path: %s", x.Device, x.Path)
+		klog.Infof("Attempting to format and mount device: %s, path: %s", device, x.Path)
 
-		if err := m.FormatAndMount(x.Device, x.Path, x.Filesystem, x.MountOptions); err != nil {
-			klog.Errorf("failed to mount the device: %s on: %s, error: %s", x.Device, x.Path, err)
+		if err := m.FormatAndMount(device, x.Path, x.Filesystem, x.MountOptions); err != nil {
+			klog.Errorf("failed to mount the device: %s on: %s, error: %s", device, x.Path, err)
 
 			return err
 		}
@@ -0,0 +1,278 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"k8s.io/klog/v2"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/apis/nodeup"
+	"k8s.io/kops/pkg/bootstrap"
+	"k8s.io/kops/pkg/kopscontrollerclient"
+	"k8s.io/kops/pkg/wellknownports"
+	"k8s.io/kops/upup/pkg/fi"
+)
+
+const (
+	// defaultVolumeCipher is the cipher of encrypted devices, unless the volume mount specifies one
+	defaultVolumeCipher = "aes-xts-plain64"
+	// tpmVolumeKeySize is the size of the random keys that are sealed to the TPM
+	tpmVolumeKeySize = 64
+	// minSystemdCryptenrollVersion is the first systemd release whose systemd-cryptenroll supports --unlock-key-file
+	minSystemdCryptenrollVersion = 252
+)
+
+// systemdCryptsetupPaths are the locations of systemd-cryptsetup on the supported distributions
+var systemdCryptsetupPaths = []string{"/usr/bin/systemd-cryptsetup", "/usr/lib/systemd/systemd-cryptsetup", "/lib/systemd/systemd-cryptsetup"}
+
+// encryptedDeviceName returns the name of the device-mapper device for a volume mount
+func encryptedDeviceName(path string) string {
+	return "kops-" + strings.ReplaceAll(strings.Trim(path, "/"), "/", "-")
+}
+
+// openEncryptedDevice unlocks the LUKS device of a volume mount and returns the path of the unlocked device.
+// Devices without any data are encrypted first; they are formatted with a filesystem when they are mounted.
+func (b *VolumesBuilder) openEncryptedDevice(ctx context.Context, x kops.VolumeMountSpec) (string, error) {
+	name := encryptedDeviceName(x.Path)
+	mapped := "/dev/mapper/" + name
+	if _, err := os.Stat(mapped); err == nil {
+		klog.V(3).Infof("Encrypted device: %s is already open as %s", x.Device, mapped)
+		return mapped, nil
+	}
+
+	encrypted := exec.Command("cryptsetup", "isLuks", x.Device).Run() == nil
+	if encrypted && x.Encryption.KeySource == kops.VolumeEncryptionKeySourceTPM {
+		// A LUKS header without a TPM token was left behind by an interrupted enrollment; its key is lost, so we start over
+		if enrolled, err := hasTPMToken(x.Device); err != nil {
+			return "", err
+		} else if !enrolled {
+			klog.Warningf("Encrypted device: %s has no key sealed to the TPM, encrypting it again", x.Device)
+			if err := wipeEncryptedDevice(x.Device); err != nil {
+				return "", err
+			}
+			encrypted = false
+		}
+	}
+	if !encrypted {
+		// Like formatting, encrypting a device is only safe if it doesn't contain anything yet
+		if empty, err := isEmptyDevice(x.Device); err != nil {
+			return "", err
+		} else if !empty {
+			return "", fmt.Errorf("device %s is not encrypted and already contains data", x.Device)
+		}
+	}
+
+	switch x.Encryption.KeySource {
+	case kops.VolumeEncryptionKeySourceKopsController:
+		key, err := b.volumeKeyFromKopsController(ctx, x.Path)
+		if err != nil {
+			return "", err
+		}
+		if !encrypted {
+			if err := formatEncryptedDevice(x, key); err != nil {
+				return "", err
+			}
+		}
+		if err := runWithKey(key, "cryptsetup", "open", "--type", "luks2", "--key-file", "-", x.Device, name); err != nil {
+			return "", err
+		}
+
+	case kops.VolumeEncryptionKeySourceTPM:
+		if !encrypted {
+			if err := checkSystemdCryptenrollVersion(); err != nil {
+				return "", err
+			}
+
+			// The key is only kept in memory; afterwards the device is unlocked with the copy sealed to the TPM
+			key := make([]byte, tpmVolumeKeySize)
+			if _, err := rand.Read(key); err != nil {
+				return "", fmt.Errorf("error generating key: %w", err)
+			}
+			if err := formatEncryptedDevice(x, key); err != nil {
+				return "", err
+			}
+			if err := runWithKey(key, "systemd-cryptenroll", "--tpm2-device=auto", "--unlock-key-file=/dev/stdin", x.Device); err != nil {
+				// Nothing can unlock the device without the key, so we wipe it to be encrypted again on the next run
+				if wipeErr := wipeEncryptedDevice(x.Device); wipeErr != nil {
+					klog.Warningf("failed to wipe encrypted device %s: %v", x.Device, wipeErr)
+				}
+				return "", err
+			}
+			if err := runWithKey(key, "cryptsetup", "open", "--type", "luks2", "--key-file", "-", x.Device, name); err != nil {
+				return "", err
+			}
+		} else {
+			systemdCryptsetup, err := findSystemdCryptsetup()
+			if err != nil {
+				return "", err
+			}
+			if err := runWithKey(nil, systemdCryptsetup, "attach", name, x.Device, "-", "tpm2-device=auto"); err != nil {
+				return "", err
+			}
+		}
+
+	default:
+		return "", fmt.Errorf("unsupported key source %q", x.Encryption.KeySource)
+	}
+
+	klog.Infof("Opened encrypted device: %s as %s", x.Device, mapped)
+	return mapped, nil
+}
+
+// volumeKeyFromKopsController gets the key of the volume mounted at path from kops-controller
+func (b *VolumesBuilder) volumeKeyFromKopsController(ctx context.Context, path string) ([]byte, error) {
+	authenticator, err := b.newAuthenticator(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	client := &kopscontrollerclient.Client{
+		Authenticator: authenticator,
+		CAs:           []byte(b.NodeupConfig.CAs[fi.CertificateIDCA]),
+		BaseURL:       b.kopsControllerURL(),
+	}
+
+	req := &nodeup.VolumeKeyRequest{
+		APIVersion: nodeup.BootstrapAPIVersion,
+		Path:       path,
+	}
+
+	if b.UseChallengeCallback(b.CloudProvider()) {
+		challengeServer, err := bootstrap.NewChallengeServer(b.NodeupConfig.ClusterName, client.CAs)
+		if err != nil {
+			return nil, err
+		}
+		listener, err := challengeServer.NewListener(ctx, ":"+strconv.Itoa(wellknownports.NodeupChallenge))
+		if err != nil {
+			return nil, fmt.Errorf("error starting challenge listener: %w", err)
+		}
+		defer listener.Stop()
+
+		req.Challenge = listener.CreateChallenge()
+	}
+
+	var resp nodeup.VolumeKeyResponse
+	if err := client.VolumeKey(ctx, req, &resp); err != nil {
+		return nil, fmt.Errorf("error getting key of volume %s from kops-controller: %w", path, err)
+	}
+	if len(resp.Key) == 0 {
+		return nil, fmt.Errorf("kops-controller did not return the key of volume %s", path)
+	}
+	return resp.Key, nil
+}
+
+// formatEncryptedDevice creates the LUKS header of a device
+func formatEncryptedDevice(x kops.VolumeMountSpec, key []byte) error {
+	cipher := x.Encryption.Cipher
+	if cipher == "" {
+		cipher = defaultVolumeCipher
+	}
+
+	klog.Infof("Encrypting device: %s, cipher: %s", x.Device, cipher)
+	return runWithKey(key, "cryptsetup", "luksFormat", "--batch-mode", "--type", "luks2", "--cipher", cipher, "--key-file", "-", x.Device)
+}
+
+// hasTPMToken returns true if a key of the LUKS device is sealed to the TPM by systemd-cryptenroll
+func hasTPMToken(device string) (bool, error) {
+	out, err := exec.Command("cryptsetup", "luksDump", device).CombinedOutput()
+	if err != nil {
+		return false, fmt.Errorf("error reading LUKS header of %s: %w: %s", device, err, out)
+	}
+	return strings.Contains(string(out), "systemd-tpm2"), nil
+}
+
+// wipeEncryptedDevice erases the LUKS header of a device, leaving it without any data
+func wipeEncryptedDevice(device string) error {
+	klog.Infof("Wiping LUKS header of device: %s", device)
+	if out, err := exec.Command("wipefs", "--all", device).CombinedOutput(); err != nil {
+		return fmt.Errorf("error wiping device %s: %w: %s", device, err, out)
+	}
+	return nil
+}
+
+// isEmptyDevice returns true if blkid doesn't find any filesystem, partition table or other signature on the device
+func isEmptyDevice(device string) (bool, error) {
+	out, err := exec.Command("blkid", "-p", device).CombinedOutput()
+	if err == nil {
+		return false, nil
+	}
+	var exitErr *exec.ExitError
+	// blkid exits with 2 when it doesn't find anything
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 2 {
+		return true, nil
+	}
+	return false, fmt.Errorf("error probing device %s: %w: %s", device, err, out)
+}
+
+// runWithKey runs a command, passing the key on its standard input so it never touches the disk
+func runWithKey(key []byte, name string, args ...string) error {
+	cmd := exec.Command(name, args...)
+	cmd.Stdin = bytes.NewReader(key)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("error running %s %s: %w: %s", name, args[0], err, out)
+	}
+	return nil
+}
+
+// checkSystemdCryptenrollVersion fails if systemd-cryptenroll is too old to enroll a key passed on its standard input
+func checkSystemdCryptenrollVersion() error {
+	out, err := exec.Command("systemctl", "--version").Output()
+	if err != nil {
+		return fmt.Errorf("error getting systemd version: %w", err)
+	}
+	version, err := parseSystemdVersion(string(out))
+	if err != nil {
+		return err
+	}
+	if version < minSystemdCryptenrollVersion {
+		return fmt.Errorf("sealing volume keys to the TPM requires systemd %d or later, found systemd %d", minSystemdCryptenrollVersion, version)
+	}
+	return nil
+}
+
+// parseSystemdVersion parses the output of "systemctl --version", which starts with a line like "systemd 252 (252.22-1~deb12u1)"
+func parseSystemdVersion(out string) (int, error) {
+	line, _, _ := strings.Cut(out, "\n")
+	fields := strings.Fields(line)
+	if len(fields) < 2 || fields[0] != "systemd" {
+		return 0, fmt.Errorf("unexpected output from systemctl --version: %q", line)
+	}
+	version, _, _ := strings.Cut(fields[1], ".")
+	n, err := strconv.Atoi(version)
+	if err != nil {
+		return 0, fmt.Errorf("unexpected systemd version %q: %w", fields[1], err)
+	}
+	return n, nil
+}
+
+func findSystemdCryptsetup() (string, error) {
+	for _, p := range systemdCryptsetupPaths {
+		if _, err := os.Stat(p); err == nil {
+			return p, nil
+		}
+	}
+	return "", fmt.Errorf("systemd-cryptsetup not found in %v", systemdCryptsetupPaths)
+}
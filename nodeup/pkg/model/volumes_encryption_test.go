@@ -0,0 +1,67 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"testing"
+)
+
+func TestParseSystemdVersion(t *testing.T) {
+	grid := []struct {
+		out      string
+		expected int
+		invalid  bool
+	}{
+		{
+			out:      "systemd 252 (252.22-1~deb12u1)\n+PAM +AUDIT +SELINUX\n",
+			expected: 252,
+		},
+		{
+			out:      "systemd 249 (249.11-0ubuntu3.12)\n",
+			expected: 249,
+		},
+		{
+			out:      "systemd 255.4\n",
+			expected: 255,
+		},
+		{
+			out:     "",
+			invalid: true,
+		},
+		{
+			out:     "systemd abc\n",
+			invalid: true,
+		},
+	}
+
+	for _, g := range grid {
+		version, err := parseSystemdVersion(g.out)
+		if g.invalid {
+			if err == nil {
+				t.Errorf("expected error parsing %q", g.out)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("unexpected error parsing %q: %v", g.out, err)
+			continue
+		}
+		if version != g.expected {
+			t.Errorf("expected version %d parsing %q, got %d", g.expected, g.out, version)
+		}
+	}
+}
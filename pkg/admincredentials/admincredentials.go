@@ -0,0 +1,161 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package admincredentials records the admin credentials issued for a cluster in its state store,
+// so that they can be audited and revoked.
+package admincredentials
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/kops/pkg/acls"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/rbac"
+	"k8s.io/kops/util/pkg/vfs"
+	"sigs.k8s.io/yaml"
+)
+
+// Path is the path, relative to the config base of the cluster, under which the admin credentials are recorded.
+const Path = "admin-credentials"
+
+// AdminCredential records the issuance of an admin client certificate.
+type AdminCredential struct {
+	// Serial is the serial number of the certificate.
+	Serial string `json:"serial"`
+	// CommonName is the common name of the certificate, which the apiserver uses as the user name.
+	CommonName string `json:"commonName"`
+	// Groups are the organizations of the certificate, which the apiserver uses as the groups.
+	Groups []string `json:"groups,omitempty"`
+	// IssuedBy is the local user that requested the certificate.
+	IssuedBy string `json:"issuedBy,omitempty"`
+	// Hostname is the host from which the certificate was requested.
+	Hostname string `json:"hostname,omitempty"`
+	// IssuedAt is when the certificate was issued.
+	IssuedAt time.Time `json:"issuedAt"`
+	// ExpiresAt is when the certificate expires.
+	ExpiresAt time.Time `json:"expiresAt"`
+	// RevokedAt is when the certificate was revoked, if it was.
+	RevokedAt *time.Time `json:"revokedAt,omitempty"`
+}
+
+// Revocable returns whether requests made with the credential can be denied once it is revoked,
+// which is not the case of members of the system:masters group.
+func (c *AdminCredential) Revocable() bool {
+	for _, group := range c.Groups {
+		if group == rbac.SystemPrivilegedGroup {
+			return false
+		}
+	}
+	return true
+}
+
+// SerialGroup returns the group identifying the admin credential with the given serial.
+func SerialGroup(serial string) string {
+	return rbac.KopsAdminCredentialGroupPrefix + serial
+}
+
+// SerialFromGroups returns the serial of the admin credential identified by the groups, if any.
+func SerialFromGroups(groups []string) (string, bool) {
+	for _, group := range groups {
+		if serial, ok := strings.CutPrefix(group, rbac.KopsAdminCredentialGroupPrefix); ok && serial != "" {
+			return serial, true
+		}
+	}
+	return "", false
+}
+
+func credentialPath(configBase vfs.Path, serial string) (vfs.Path, error) {
+	if serial == "" || strings.ContainsAny(serial, "/.") {
+		return nil, fmt.Errorf("invalid admin credential serial %q", serial)
+	}
+	return configBase.Join(Path, serial), nil
+}
+
+// Write records the admin credential in the state store.
+func Write(ctx context.Context, configBase vfs.Path, cluster *kops.Cluster, credential *AdminCredential) error {
+	p, err := credentialPath(configBase, credential.Serial)
+	if err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(credential)
+	if err != nil {
+		return fmt.Errorf("error serializing admin credential: %w", err)
+	}
+
+	acl, err := acls.GetACL(ctx, p, cluster)
+	if err != nil {
+		return err
+	}
+	if err := p.WriteFile(ctx, bytes.NewReader(data), acl); err != nil {
+		return fmt.Errorf("error writing admin credential %q: %w", p, err)
+	}
+	return nil
+}
+
+// Read reads the record of the admin credential with the given serial, returning nil if there is none.
+func Read(ctx context.Context, configBase vfs.Path, serial string) (*AdminCredential, error) {
+	p, err := credentialPath(configBase, serial)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := p.ReadFile(ctx)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error reading admin credential %q: %w", p, err)
+	}
+
+	credential := &AdminCredential{}
+	if err := yaml.Unmarshal(data, credential); err != nil {
+		return nil, fmt.Errorf("error parsing admin credential %q: %w", p, err)
+	}
+	return credential, nil
+}
+
+// List returns the admin credentials recorded in the state store, by issuance time.
+func List(ctx context.Context, configBase vfs.Path) ([]*AdminCredential, error) {
+	files, err := configBase.Join(Path).ReadDir()
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error listing admin credentials: %w", err)
+	}
+
+	var credentials []*AdminCredential
+	for _, f := range files {
+		credential, err := Read(ctx, configBase, f.Base())
+		if err != nil {
+			return nil, err
+		}
+		if credential != nil {
+			credentials = append(credentials, credential)
+		}
+	}
+	sort.Slice(credentials, func(i, j int) bool {
+		return credentials[i].IssuedAt.Before(credentials[j].IssuedAt)
+	})
+	return credentials, nil
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package admincredentials
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/rbac"
+	"k8s.io/kops/util/pkg/vfs"
+)
+
+func TestWriteAndList(t *testing.T) {
+	ctx := context.TODO()
+
+	vfsContext := vfs.NewVFSContext()
+	vfsContext.ResetMemfsContext(true)
+	configBase, err := vfsContext.BuildVfsPath("memfs://state/cluster.example.com")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cluster := &kops.Cluster{}
+
+	credentials, err := List(ctx, configBase)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(credentials) != 0 {
+		t.Fatalf("expected no admin credentials, got %v", credentials)
+	}
+
+	issuedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, serial := range []string{"200", "100"} {
+		credential := &AdminCredential{
+			Serial:     serial,
+			CommonName: "kubecfg-user",
+			Groups:     []string{rbac.KopsAdminsGroup, SerialGroup(serial)},
+			IssuedAt:   issuedAt.Add(time.Duration(i) * time.Hour),
+			ExpiresAt:  issuedAt.Add(18 * time.Hour),
+		}
+		if err := Write(ctx, configBase, cluster, credential); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	credentials, err = List(ctx, configBase)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(credentials) != 2 || credentials[0].Serial != "200" || credentials[1].Serial != "100" {
+		t.Fatalf("expected admin credentials 200 and 100, got %v", credentials)
+	}
+	if !credentials[0].Revocable() {
+		t.Errorf("expected admin credential to be revocable")
+	}
+
+	credential, err := Read(ctx, configBase, "300")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if credential != nil {
+		t.Errorf("expected no admin credential 300, got %v", credential)
+	}
+
+	if _, err := Read(ctx, configBase, "../secrets"); err == nil {
+		t.Errorf("expected invalid serial to be rejected")
+	}
+}
+
+func TestSerialFromGroups(t *testing.T) {
+	serial, ok := SerialFromGroups([]string{rbac.KopsAdminsGroup, SerialGroup("1234"), rbac.AllAuthenticated})
+	if !ok || serial != "1234" {
+		t.Errorf("expected serial 1234, got %q", serial)
+	}
+
+	if _, ok := SerialFromGroups([]string{rbac.SystemPrivilegedGroup}); ok {
+		t.Errorf("expected no serial")
+	}
+}
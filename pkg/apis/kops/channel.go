@@ -290,6 +290,7 @@ const (
 	CloudProviderDO        CloudProviderID = "digitalocean"
 	CloudProviderGCE       CloudProviderID = "gce"
 	CloudProviderHetzner   CloudProviderID = "hetzner"
+	CloudProviderMetal     CloudProviderID = "metal"
 	CloudProviderOpenstack CloudProviderID = "openstack"
 	CloudProviderAzure     CloudProviderID = "azure"
 	CloudProviderScaleway  CloudProviderID = "scaleway"
@@ -154,6 +154,10 @@ type ClusterSpec struct {
 	SysctlParameters []string `json:"sysctlParameters,omitempty"`
 	// RollingUpdate defines the default rolling-update settings for instance groups.
 	RollingUpdate *RollingUpdate `json:"rollingUpdate,omitempty"`
+	// NodeAudit configures the periodic audit of the node configuration against the cluster spec.
+	NodeAudit *NodeAuditSpec `json:"nodeAudit,omitempty"`
+	// NodeCertificateRenewal configures the renewal of the node certificates issued by kops-controller before they expire.
+	NodeCertificateRenewal *NodeCertificateRenewalSpec `json:"nodeCertificateRenewal,omitempty"`
 	// ClusterAutoscaler defines the cluster autoscaler configuration.
 	ClusterAutoscaler *ClusterAutoscalerConfig `json:"clusterAutoscaler,omitempty"`
 	// ServiceAccountIssuerDiscovery configures the OIDC Issuer for ServiceAccounts.
@@ -195,6 +199,8 @@ type CloudProviderSpec struct {
 	GCE *GCESpec `json:"gce,omitempty"`
 	// Hetzner configures the Hetzner cloud provider.
 	Hetzner *HetznerSpec `json:"hetzner,omitempty"`
+	// Metal configures the cluster to run on hosts enrolled over SSH, rather than on cloud instances.
+	Metal *MetalSpec `json:"metal,omitempty"`
 	// Openstack configures the Openstack cloud provider.
 	Openstack *OpenstackSpec `json:"openstack,omitempty"`
 	// Scaleway configures the Scaleway cloud provider.
@@ -254,6 +260,9 @@ type GCESpec struct {
 // HetznerSpec configures the Hetzner cloud provider.
 type HetznerSpec struct{}
 
+// MetalSpec configures clusters of bare-metal hosts.
+type MetalSpec struct{}
+
 // ScalewaySpec configures the Scaleway cloud provider
 type ScalewaySpec struct {
 }
@@ -352,6 +361,19 @@ type AssetsSpec struct {
 	FileRepository *string `json:"fileRepository,omitempty"`
 	// ContainerProxy is a url for a pull-through proxy of a container registry.
 	ContainerProxy *string `json:"containerProxy,omitempty"`
+	// SignaturePolicy requires the image and file assets to be signed.
+	SignaturePolicy *AssetsSignaturePolicy `json:"signaturePolicy,omitempty"`
+}
+
+// AssetsSignaturePolicy requires the assets of the cluster to be signed with cosign, by one of the configured keys.
+// Keyless signatures, verified against Fulcio certificates and the Rekor transparency log, are not supported.
+type AssetsSignaturePolicy struct {
+	// PublicKeys are the PEM encoded ECDSA or RSA public keys, one of which must have signed each asset.
+	PublicKeys []string `json:"publicKeys,omitempty"`
+	// Images requires each container image to carry a cosign signature in its repository.
+	Images bool `json:"images,omitempty"`
+	// Files requires each file asset to have a cosign blob signature alongside it, with a .sig suffix.
+	Files bool `json:"files,omitempty"`
 }
 
 // IAMSpec adds control over the IAM security policies applied to resources
@@ -402,10 +424,16 @@ type AuthenticationSpec struct {
 	Kopeio *KopeioAuthenticationSpec `json:"kopeio,omitempty"`
 	AWS    *AWSAuthenticationSpec    `json:"aws,omitempty"`
 	OIDC   *OIDCAuthenticationSpec   `json:"oidc,omitempty"`
+	// AdminCredentialDenyList enables kops-controller to deny the requests of revoked admin credentials,
+	// as issued by `kops export kubeconfig --admin`. Admin credentials are then bound to the cluster-admin
+	// role instead of being members of the system:masters group.
+	// The review fails open: revoked admin credentials are not denied while kops-controller is unavailable.
+	// Admin credentials issued before it was enabled are members of the system:masters group and can't be revoked.
+	AdminCredentialDenyList bool `json:"adminCredentialDenyList,omitempty"`
 }
 
 func (s *AuthenticationSpec) IsEmpty() bool {
-	return s.Kopeio == nil && s.AWS == nil && s.OIDC == nil
+	return s.Kopeio == nil && s.AWS == nil && s.OIDC == nil && !s.AdminCredentialDenyList
 }
 
 type KopeioAuthenticationSpec struct{}
@@ -631,6 +659,17 @@ type ExternalDNSConfig struct {
 	// WatchIngress indicates you want the dns-controller to watch and create dns entries for ingress resources.
 	// Default: true if provider is 'external-dns', false otherwise.
 	WatchIngress *bool `json:"watchIngress,omitempty"`
+	// WatchGatewayAPI indicates you want the dns-controller to watch and create dns entries for Gateway API
+	// resources (Gateways, HTTPRoutes and GRPCRoutes). The Gateway API CRDs must be installed in the cluster.
+	// Default: false.
+	WatchGatewayAPI *bool `json:"watchGatewayAPI,omitempty"`
+	// TXTOwnership indicates you want the dns-controller to record ownership of the records it manages in TXT records,
+	// with the owner id "kops-<cluster name>", and to never change records that are owned by someone else.
+	// Default: false.
+	TXTOwnership *bool `json:"txtOwnership,omitempty"`
+	// TXTAdoptExisting indicates you want the dns-controller to take ownership of existing records that do not have
+	// an ownership record. This is intended for migrating records created before TXTOwnership was enabled.
+	TXTAdoptExisting *bool `json:"txtAdoptExisting,omitempty"`
 	// WatchNamespace is namespace to watch, defaults to all (use to control whom can creates dns entries)
 	WatchNamespace string `json:"watchNamespace,omitempty"`
 	// Provider determines which implementation of ExternalDNS to use.
@@ -679,6 +718,17 @@ type EtcdBackupSpec struct {
 	BackupStore string `json:"backupStore,omitempty"`
 	// Image is the etcd backup manager image to use.  Setting this will create a sidecar container in the etcd pod with the specified image.
 	Image string `json:"image,omitempty"`
+	// Verification configures a CronJob that regularly restores the latest backup into a throwaway etcd.
+	Verification *EtcdBackupVerificationSpec `json:"verification,omitempty"`
+}
+
+// EtcdBackupVerificationSpec configures the scheduled verification of etcd backups.
+type EtcdBackupVerificationSpec struct {
+	// Schedule is the cron schedule of the verification, in the time zone of kube-controller-manager.
+	// Defaults to "0 3 * * *", daily at 3:00.
+	Schedule string `json:"schedule,omitempty"`
+	// PushgatewayURL is the URL of a Prometheus Pushgateway the outcome of the verification is pushed to.
+	PushgatewayURL string `json:"pushgatewayURL,omitempty"`
 }
 
 // EtcdManagerSpec describes how we configure the etcd manager
@@ -955,6 +1005,8 @@ func (c *ClusterSpec) GetCloudProvider() CloudProviderID {
 		return CloudProviderGCE
 	} else if c.CloudProvider.Hetzner != nil {
 		return CloudProviderHetzner
+	} else if c.CloudProvider.Metal != nil {
+		return CloudProviderMetal
 	} else if c.CloudProvider.Openstack != nil {
 		return CloudProviderOpenstack
 	} else if c.CloudProvider.Scaleway != nil {
@@ -1037,6 +1089,26 @@ type RollingUpdate struct {
 	// nodes.
 	// +optional
 	MaxSurge *intstr.IntOrString `json:"maxSurge,omitempty"`
+	// InPlace applies changes to the node configuration on the running instances, instead of replacing them.
+	// The instances are only drained when the changes restart kubelet or containerd.
+	// Only supported on instance groups with role "Node".
+	InPlace *bool `json:"inPlace,omitempty"`
+}
+
+// NodeAuditSpec configures the periodic audit of the node configuration.
+type NodeAuditSpec struct {
+	// Enabled runs the audit periodically on every node, and sets the KopsConfigurationDrift condition of the Node.
+	Enabled *bool `json:"enabled,omitempty"`
+	// Interval is the time between two audits. Defaults to 1h.
+	Interval *metav1.Duration `json:"interval,omitempty"`
+}
+
+// NodeCertificateRenewalSpec configures the renewal of the node certificates.
+type NodeCertificateRenewalSpec struct {
+	// Enabled runs an agent on every node without an API server, which renews the kubelet and kube-proxy certificates through kops-controller.
+	Enabled *bool `json:"enabled,omitempty"`
+	// RenewBefore is how long before their expiration the certificates are renewed. Defaults to 720h.
+	RenewBefore *metav1.Duration `json:"renewBefore,omitempty"`
 }
 
 type PackagesConfig struct {
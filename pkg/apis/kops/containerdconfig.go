@@ -24,6 +24,24 @@ import (
 // NvidiaDefaultDriverPackage is the nvidia driver default version
 const NvidiaDefaultDriverPackage = "nvidia-headless-515-server"
 
+const (
+	// GVisorDefaultVersion is the gVisor release installed when no version is set
+	GVisorDefaultVersion = "20240401.0"
+	// KataDefaultVersion is the Kata Containers release installed when no version is set
+	KataDefaultVersion = "3.5.0"
+
+	// SandboxRuntimeGVisor is the name of the gVisor RuntimeClass and containerd runtime handler
+	SandboxRuntimeGVisor = "gvisor"
+	// SandboxRuntimeKata is the name of the Kata Containers RuntimeClass and containerd runtime handler
+	SandboxRuntimeKata = "kata"
+
+	// SandboxRuntimeLabelPrefix is the prefix of the node labels for sandboxed runtimes, e.g. "sandbox.kops.k8s.io/gvisor".
+	SandboxRuntimeLabelPrefix = "sandbox.kops.k8s.io/"
+	// SandboxRuntimeTaintKey is the key of the taint on nodes running sandboxed runtimes,
+	// which is tolerated by the RuntimeClasses of the sandboxed runtimes.
+	SandboxRuntimeTaintKey = "sandbox.kops.k8s.io/runtime"
+)
+
 // ContainerdConfig is the configuration for containerd
 type ContainerdConfig struct {
 	// Address of containerd's GRPC server (default "/run/containerd/containerd.sock").
@@ -38,6 +56,9 @@ type ContainerdConfig struct {
 	Packages *PackagesConfig `json:"packages,omitempty"`
 	// RegistryMirrors is list of image registries
 	RegistryMirrors map[string][]string `json:"registryMirrors,omitempty"`
+	// Registries configures the hosts containerd pulls images from, including their mirrors, TLS settings and credentials.
+	// Each registry is written to /etc/containerd/certs.d/<host>/hosts.toml.
+	Registries []ContainerdRegistryConfig `json:"registries,omitempty"`
 	// Root directory for persistent data (default "/var/lib/containerd").
 	Root *string `json:"root,omitempty" flag:"root"`
 	// SkipInstall prevents kOps from installing and modifying containerd in any way (default "false").
@@ -48,6 +69,12 @@ type ContainerdConfig struct {
 	Version *string `json:"version,omitempty"`
 	// NvidiaGPU configures the Nvidia GPU runtime.
 	NvidiaGPU *NvidiaGPUConfig `json:"nvidiaGPU,omitempty"`
+	// GVisor configures the gVisor sandboxed runtime, used by the "gvisor" RuntimeClass.
+	// It can only be enabled on instance groups.
+	GVisor *GVisorConfig `json:"gvisor,omitempty"`
+	// Kata configures the Kata Containers sandboxed runtime, used by the "kata" RuntimeClass.
+	// It can only be enabled on instance groups.
+	Kata *KataConfig `json:"kata,omitempty"`
 	// Runc configures the runc runtime.
 	Runc *Runc `json:"runc,omitempty"`
 	// SelinuxEnabled enables SELinux support
@@ -56,6 +83,24 @@ type ContainerdConfig struct {
 	NRI *NRIConfig `json:"nri,omitempty"`
 }
 
+// ContainerdRegistryConfig configures how containerd connects to a registry.
+type ContainerdRegistryConfig struct {
+	// Host is the name of the registry, optionally with a port (e.g. "registry.example.com:5000").
+	// Use "_default" to configure registries that have no configuration of their own.
+	Host string `json:"host"`
+	// Server is the URL of the registry (default "https://<host>", or "https://registry-1.docker.io" for "docker.io").
+	Server *string `json:"server,omitempty"`
+	// Mirrors are the URLs of the mirrors to try, in order, before the registry itself.
+	Mirrors []string `json:"mirrors,omitempty"`
+	// CA is the PEM encoded CA certificate used to verify the registry and its mirrors.
+	CA string `json:"ca,omitempty"`
+	// SkipVerify disables verification of the TLS certificates of the registry and its mirrors.
+	SkipVerify bool `json:"skipVerify,omitempty"`
+	// Secret is the name of the secret holding the credentials and client certificate for the registry,
+	// created with "kops create secret containerdregistry".
+	Secret string `json:"secret,omitempty"`
+}
+
 type NRIConfig struct {
 	// Enable NRI support in containerd
 	Enabled *bool `json:"enabled,omitempty"`
@@ -83,9 +128,45 @@ type DCGMExporterConfig struct {
 	Enabled bool `json:"enabled,omitempty"`
 }
 
+// GVisorConfig configures the gVisor (runsc) sandboxed runtime.
+// Nodes running it are labeled and tainted, so that only pods using the "gvisor" RuntimeClass are scheduled on them.
+type GVisorConfig struct {
+	// Enabled determines if kOps will install gVisor and register it with containerd.
+	Enabled *bool `json:"enabled,omitempty"`
+	// Version is the gVisor release to install (e.g. "20240401.0").
+	Version *string `json:"version,omitempty"`
+}
+
+// KataConfig configures the Kata Containers sandboxed runtime.
+// Nodes running it are labeled and tainted, so that only pods using the "kata" RuntimeClass are scheduled on them.
+// Kata Containers runs each pod in a virtual machine, so the instances must support (nested) virtualization.
+type KataConfig struct {
+	// Enabled determines if kOps will install Kata Containers and register it with containerd.
+	Enabled *bool `json:"enabled,omitempty"`
+	// Version is the Kata Containers release to install (e.g. "3.5.0").
+	Version *string `json:"version,omitempty"`
+	// Packages overrides the URL and hash for the static release archive.
+	Packages *PackagesConfig `json:"packages,omitempty"`
+}
+
 type Runc struct {
 	// Version used to pick the runc package.
 	Version *string `json:"version,omitempty"`
 	// Packages overrides the URL and hash for the packages.
 	Packages *PackagesConfig `json:"packages,omitempty"`
 }
+
+// SandboxRuntimes returns the names of the sandboxed runtimes enabled in the config.
+func (c *ContainerdConfig) SandboxRuntimes() []string {
+	var runtimes []string
+	if c == nil {
+		return runtimes
+	}
+	if c.GVisor != nil && c.GVisor.Enabled != nil && *c.GVisor.Enabled {
+		runtimes = append(runtimes, SandboxRuntimeGVisor)
+	}
+	if c.Kata != nil && c.Kata.Enabled != nil && *c.Kata.Enabled {
+		runtimes = append(runtimes, SandboxRuntimeKata)
+	}
+	return runtimes
+}
@@ -87,6 +87,16 @@ var SupportedFilesystems = []string{BtfsFilesystem, Ext4Filesystem, XFSFilesyste
 
 type InstanceManager string
 
+// UserDataFormat is the format of the user data of an InstanceGroup
+type UserDataFormat string
+
+const (
+	// UserDataFormatShellScript runs the bootstrap script with cloud-init or an equivalent
+	UserDataFormatShellScript UserDataFormat = "ShellScript"
+	// UserDataFormatIgnition runs the bootstrap script from a systemd unit defined in an Ignition config
+	UserDataFormatIgnition UserDataFormat = "Ignition"
+)
+
 const (
 	InstanceManagerCloudGroup InstanceManager = "CloudGroup"
 	InstanceManagerKarpenter  InstanceManager = "Karpenter"
@@ -167,6 +177,11 @@ type InstanceGroupSpec struct {
 	// specified, each parameter must follow the form variable=value, the way
 	// it would appear in sysctl.conf.
 	SysctlParameters []string `json:"sysctlParameters,omitempty"`
+	// KernelModules are loaded on boot, with their options.
+	KernelModules []KernelModuleSpec `json:"kernelModules,omitempty"`
+	// KernelBootParameters are added to the kernel command line, e.g. "hugepages=1024".
+	// Changing them requires a reboot, so the instances are replaced even when they are updated in place.
+	KernelBootParameters []string `json:"kernelBootParameters,omitempty"`
 	// RollingUpdate defines the rolling-update behavior
 	RollingUpdate *RollingUpdate `json:"rollingUpdate,omitempty"`
 	// InstanceInterruptionBehavior defines if a spot instance should be terminated, hibernated,
@@ -174,6 +189,9 @@ type InstanceGroupSpec struct {
 	InstanceInterruptionBehavior *string `json:"instanceInterruptionBehavior,omitempty"`
 	// CompressUserData compresses parts of the user data to save space
 	CompressUserData *bool `json:"compressUserData,omitempty"`
+	// UserDataFormat is the format of the user data that bootstraps the instances.
+	// Valid values are 'ShellScript' (default) and 'Ignition', which is needed by Fedora CoreOS.
+	UserDataFormat UserDataFormat `json:"userDataFormat,omitempty"`
 	// InstanceMetadata defines the EC2 instance metadata service options (AWS Only)
 	InstanceMetadata *InstanceMetadataOptions `json:"instanceMetadata,omitempty"`
 	// UpdatePolicy determines the policy for applying upgrades automatically.
@@ -188,6 +206,8 @@ type InstanceGroupSpec struct {
 	Containerd *ContainerdConfig `json:"containerd,omitempty"`
 	// Packages specifies additional packages to be installed.
 	Packages []string `json:"packages,omitempty"`
+	// PrepulledImages are container images that are pulled or loaded on the nodes before the kubelet starts.
+	PrepulledImages []PrepulledImageSpec `json:"prepulledImages,omitempty"`
 	// GuestAccelerators configures additional accelerators
 	GuestAccelerators []AcceleratorConfig `json:"guestAccelerators,omitempty"`
 	// MaxInstanceLifetime to the maximum amount of time, in seconds, that an instance can be in service.
@@ -296,6 +316,26 @@ type UserData struct {
 	Content string `json:"content,omitempty"`
 }
 
+// KernelModuleSpec is a kernel module that is loaded on boot
+type KernelModuleSpec struct {
+	// Name is the name of the module, e.g. "ip_vs".
+	Name string `json:"name"`
+	// Options are the parameters of the module, e.g. "hashsize=131072".
+	Options []string `json:"options,omitempty"`
+}
+
+// PrepulledImageSpec is a container image that is pulled or loaded on the nodes before the kubelet starts
+type PrepulledImageSpec struct {
+	// Image is the name of the image, e.g. "registry.example.com/ml/trainer:v1.2".
+	Image string `json:"image,omitempty"`
+	// Digest pins the pulled image to a digest, e.g. "sha256:4d3f...".
+	Digest string `json:"digest,omitempty"`
+	// Source is the URL of the image as a tarball, as created by "docker save", which is loaded instead of pulling the image.
+	Source string `json:"source,omitempty"`
+	// Hash is the SHA256 hash of the tarball in Source.
+	Hash string `json:"hash,omitempty"`
+}
+
 // VolumeSpec defined the spec for an additional volume attached to the instance group
 type VolumeSpec struct {
 	// DeleteOnTermination configures volume retention policy upon instance termination.
@@ -329,8 +369,28 @@ type VolumeMountSpec struct {
 	MountOptions []string `json:"mountOptions,omitempty"`
 	// Path is the location to mount the device
 	Path string `json:"path,omitempty"`
+	// Encryption encrypts the device with dm-crypt/LUKS before it is formatted and mounted
+	Encryption *VolumeEncryptionSpec `json:"encryption,omitempty"`
+}
+
+// VolumeEncryptionSpec defines the dm-crypt/LUKS encryption of a mounted device
+type VolumeEncryptionSpec struct {
+	// KeySource is where the key that unlocks the device comes from: KopsController or TPM
+	KeySource VolumeEncryptionKeySource `json:"keySource,omitempty"`
+	// Cipher is the cipher used to encrypt the device, defaults to aes-xts-plain64
+	Cipher string `json:"cipher,omitempty"`
 }
 
+// VolumeEncryptionKeySource is where the key of an encrypted device comes from
+type VolumeEncryptionKeySource string
+
+const (
+	// VolumeEncryptionKeySourceKopsController derives the key in kops-controller, from a cluster secret and the identity of the node
+	VolumeEncryptionKeySourceKopsController VolumeEncryptionKeySource = "KopsController"
+	// VolumeEncryptionKeySourceTPM seals a random key to the TPM of the instance, using systemd-cryptenroll
+	VolumeEncryptionKeySourceTPM VolumeEncryptionKeySource = "TPM"
+)
+
 // IAMProfileSpec is the AWS IAM Profile to attach to instances in this instance
 // group. Specify the ARN for the IAM instance profile (AWS only).
 type IAMProfileSpec struct {
@@ -374,6 +434,20 @@ func (g *InstanceGroup) IsBastion() bool {
 	}
 }
 
+// UsesInPlaceUpdates checks if changes to the node configuration of the instanceGroup are applied on the running instances.
+func (g *InstanceGroup) UsesInPlaceUpdates(cluster *Cluster) bool {
+	if g.Spec.Role != InstanceGroupRoleNode {
+		return false
+	}
+	if g.Spec.RollingUpdate != nil && g.Spec.RollingUpdate.InPlace != nil {
+		return *g.Spec.RollingUpdate.InPlace
+	}
+	if cluster.Spec.RollingUpdate != nil && cluster.Spec.RollingUpdate.InPlace != nil {
+		return *cluster.Spec.RollingUpdate.InPlace
+	}
+	return false
+}
+
 func (g *InstanceGroup) AddInstanceGroupNodeLabel() {
 	if g.Spec.NodeLabels == nil {
 		g.Spec.NodeLabels = make(map[string]string)
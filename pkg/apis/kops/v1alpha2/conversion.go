@@ -162,6 +162,8 @@ func Convert_v1alpha2_ClusterSpec_To_kops_ClusterSpec(in *ClusterSpec, out *kops
 		}
 	case kops.CloudProviderHetzner:
 		out.CloudProvider.Hetzner = &kops.HetznerSpec{}
+	case kops.CloudProviderMetal:
+		out.CloudProvider.Metal = &kops.MetalSpec{}
 	case kops.CloudProviderOpenstack:
 		out.CloudProvider.Openstack = &kops.OpenstackSpec{}
 		if in.CloudConfig != nil && in.CloudConfig.Openstack != nil {
@@ -179,6 +181,7 @@ func Convert_v1alpha2_ClusterSpec_To_kops_ClusterSpec(in *ClusterSpec, out *kops
 			string(kops.CloudProviderAzure),
 			string(kops.CloudProviderAWS),
 			string(kops.CloudProviderHetzner),
+			string(kops.CloudProviderMetal),
 			string(kops.CloudProviderOpenstack),
 			string(kops.CloudProviderScaleway),
 		})
@@ -124,6 +124,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*AssetsSignaturePolicy)(nil), (*kops.AssetsSignaturePolicy)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_AssetsSignaturePolicy_To_kops_AssetsSignaturePolicy(a.(*AssetsSignaturePolicy), b.(*kops.AssetsSignaturePolicy), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*kops.AssetsSignaturePolicy)(nil), (*AssetsSignaturePolicy)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_kops_AssetsSignaturePolicy_To_v1alpha2_AssetsSignaturePolicy(a.(*kops.AssetsSignaturePolicy), b.(*AssetsSignaturePolicy), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*AssetsSpec)(nil), (*kops.AssetsSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha2_AssetsSpec_To_kops_AssetsSpec(a.(*AssetsSpec), b.(*kops.AssetsSpec), scope)
 	}); err != nil {
@@ -304,6 +314,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*ContainerdRegistryConfig)(nil), (*kops.ContainerdRegistryConfig)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_ContainerdRegistryConfig_To_kops_ContainerdRegistryConfig(a.(*ContainerdRegistryConfig), b.(*kops.ContainerdRegistryConfig), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*kops.ContainerdRegistryConfig)(nil), (*ContainerdRegistryConfig)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_kops_ContainerdRegistryConfig_To_v1alpha2_ContainerdRegistryConfig(a.(*kops.ContainerdRegistryConfig), b.(*ContainerdRegistryConfig), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*DCGMExporterConfig)(nil), (*kops.DCGMExporterConfig)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha2_DCGMExporterConfig_To_kops_DCGMExporterConfig(a.(*DCGMExporterConfig), b.(*kops.DCGMExporterConfig), scope)
 	}); err != nil {
@@ -394,6 +414,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*EtcdBackupVerificationSpec)(nil), (*kops.EtcdBackupVerificationSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_EtcdBackupVerificationSpec_To_kops_EtcdBackupVerificationSpec(a.(*EtcdBackupVerificationSpec), b.(*kops.EtcdBackupVerificationSpec), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*kops.EtcdBackupVerificationSpec)(nil), (*EtcdBackupVerificationSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_kops_EtcdBackupVerificationSpec_To_v1alpha2_EtcdBackupVerificationSpec(a.(*kops.EtcdBackupVerificationSpec), b.(*EtcdBackupVerificationSpec), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*EtcdClusterSpec)(nil), (*kops.EtcdClusterSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha2_EtcdClusterSpec_To_kops_EtcdClusterSpec(a.(*EtcdClusterSpec), b.(*kops.EtcdClusterSpec), scope)
 	}); err != nil {
@@ -474,6 +504,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*GVisorConfig)(nil), (*kops.GVisorConfig)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_GVisorConfig_To_kops_GVisorConfig(a.(*GVisorConfig), b.(*kops.GVisorConfig), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*kops.GVisorConfig)(nil), (*GVisorConfig)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_kops_GVisorConfig_To_v1alpha2_GVisorConfig(a.(*kops.GVisorConfig), b.(*GVisorConfig), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*GossipConfig)(nil), (*kops.GossipConfig)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha2_GossipConfig_To_kops_GossipConfig(a.(*GossipConfig), b.(*kops.GossipConfig), scope)
 	}); err != nil {
@@ -584,6 +624,26 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*KataConfig)(nil), (*kops.KataConfig)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_KataConfig_To_kops_KataConfig(a.(*KataConfig), b.(*kops.KataConfig), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*kops.KataConfig)(nil), (*KataConfig)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_kops_KataConfig_To_v1alpha2_KataConfig(a.(*kops.KataConfig), b.(*KataConfig), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*KernelModuleSpec)(nil), (*kops.KernelModuleSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_KernelModuleSpec_To_kops_KernelModuleSpec(a.(*KernelModuleSpec), b.(*kops.KernelModuleSpec), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*kops.KernelModuleSpec)(nil), (*KernelModuleSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_kops_KernelModuleSpec_To_v1alpha2_KernelModuleSpec(a.(*kops.KernelModuleSpec), b.(*KernelModuleSpec), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*Keyset)(nil), (*kops.Keyset)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha2_Keyset_To_kops_Keyset(a.(*Keyset), b.(*kops.Keyset), scope)
 	}); err != nil {
@@ -844,6 +904,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*NodeAuditSpec)(nil), (*kops.NodeAuditSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_NodeAuditSpec_To_kops_NodeAuditSpec(a.(*NodeAuditSpec), b.(*kops.NodeAuditSpec), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*kops.NodeAuditSpec)(nil), (*NodeAuditSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_kops_NodeAuditSpec_To_v1alpha2_NodeAuditSpec(a.(*kops.NodeAuditSpec), b.(*NodeAuditSpec), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*NodeAuthorizationSpec)(nil), (*kops.NodeAuthorizationSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha2_NodeAuthorizationSpec_To_kops_NodeAuthorizationSpec(a.(*NodeAuthorizationSpec), b.(*kops.NodeAuthorizationSpec), scope)
 	}); err != nil {
@@ -864,6 +934,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*NodeCertificateRenewalSpec)(nil), (*kops.NodeCertificateRenewalSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_NodeCertificateRenewalSpec_To_kops_NodeCertificateRenewalSpec(a.(*NodeCertificateRenewalSpec), b.(*kops.NodeCertificateRenewalSpec), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*kops.NodeCertificateRenewalSpec)(nil), (*NodeCertificateRenewalSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_kops_NodeCertificateRenewalSpec_To_v1alpha2_NodeCertificateRenewalSpec(a.(*kops.NodeCertificateRenewalSpec), b.(*NodeCertificateRenewalSpec), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*NodeLocalDNSConfig)(nil), (*kops.NodeLocalDNSConfig)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha2_NodeLocalDNSConfig_To_kops_NodeLocalDNSConfig(a.(*NodeLocalDNSConfig), b.(*kops.NodeLocalDNSConfig), scope)
 	}); err != nil {
@@ -1004,6 +1084,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*PrepulledImageSpec)(nil), (*kops.PrepulledImageSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_PrepulledImageSpec_To_kops_PrepulledImageSpec(a.(*PrepulledImageSpec), b.(*kops.PrepulledImageSpec), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*kops.PrepulledImageSpec)(nil), (*PrepulledImageSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_kops_PrepulledImageSpec_To_v1alpha2_PrepulledImageSpec(a.(*kops.PrepulledImageSpec), b.(*PrepulledImageSpec), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*RBACAuthorizationSpec)(nil), (*kops.RBACAuthorizationSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha2_RBACAuthorizationSpec_To_kops_RBACAuthorizationSpec(a.(*RBACAuthorizationSpec), b.(*kops.RBACAuthorizationSpec), scope)
 	}); err != nil {
@@ -1144,6 +1234,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*VolumeEncryptionSpec)(nil), (*kops.VolumeEncryptionSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_VolumeEncryptionSpec_To_kops_VolumeEncryptionSpec(a.(*VolumeEncryptionSpec), b.(*kops.VolumeEncryptionSpec), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*kops.VolumeEncryptionSpec)(nil), (*VolumeEncryptionSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_kops_VolumeEncryptionSpec_To_v1alpha2_VolumeEncryptionSpec(a.(*kops.VolumeEncryptionSpec), b.(*VolumeEncryptionSpec), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*VolumeMountSpec)(nil), (*kops.VolumeMountSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha2_VolumeMountSpec_To_kops_VolumeMountSpec(a.(*VolumeMountSpec), b.(*kops.VolumeMountSpec), scope)
 	}); err != nil {
@@ -1545,10 +1645,43 @@ func Convert_kops_AmazonVPCNetworkingSpec_To_v1alpha2_AmazonVPCNetworkingSpec(in
 	return autoConvert_kops_AmazonVPCNetworkingSpec_To_v1alpha2_AmazonVPCNetworkingSpec(in, out, s)
 }
 
+func autoConvert_v1alpha2_AssetsSignaturePolicy_To_kops_AssetsSignaturePolicy(in *AssetsSignaturePolicy, out *kops.AssetsSignaturePolicy, s conversion.Scope) error {
+	out.PublicKeys = in.PublicKeys
+	out.Images = in.Images
+	out.Files = in.Files
+	return nil
+}
+
+// Convert_v1alpha2_AssetsSignaturePolicy_To_kops_AssetsSignaturePolicy is an autogenerated conversion function.
+func Convert_v1alpha2_AssetsSignaturePolicy_To_kops_AssetsSignaturePolicy(in *AssetsSignaturePolicy, out *kops.AssetsSignaturePolicy, s conversion.Scope) error {
+	return autoConvert_v1alpha2_AssetsSignaturePolicy_To_kops_AssetsSignaturePolicy(in, out, s)
+}
+
+func autoConvert_kops_AssetsSignaturePolicy_To_v1alpha2_AssetsSignaturePolicy(in *kops.AssetsSignaturePolicy, out *AssetsSignaturePolicy, s conversion.Scope) error {
+	out.PublicKeys = in.PublicKeys
+	out.Images = in.Images
+	out.Files = in.Files
+	return nil
+}
+
+// Convert_kops_AssetsSignaturePolicy_To_v1alpha2_AssetsSignaturePolicy is an autogenerated conversion function.
+func Convert_kops_AssetsSignaturePolicy_To_v1alpha2_AssetsSignaturePolicy(in *kops.AssetsSignaturePolicy, out *AssetsSignaturePolicy, s conversion.Scope) error {
+	return autoConvert_kops_AssetsSignaturePolicy_To_v1alpha2_AssetsSignaturePolicy(in, out, s)
+}
+
 func autoConvert_v1alpha2_AssetsSpec_To_kops_AssetsSpec(in *AssetsSpec, out *kops.AssetsSpec, s conversion.Scope) error {
 	out.ContainerRegistry = in.ContainerRegistry
 	out.FileRepository = in.FileRepository
 	out.ContainerProxy = in.ContainerProxy
+	if in.SignaturePolicy != nil {
+		in, out := &in.SignaturePolicy, &out.SignaturePolicy
+		*out = new(kops.AssetsSignaturePolicy)
+		if err := Convert_v1alpha2_AssetsSignaturePolicy_To_kops_AssetsSignaturePolicy(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.SignaturePolicy = nil
+	}
 	return nil
 }
 
@@ -1561,6 +1694,15 @@ func autoConvert_kops_AssetsSpec_To_v1alpha2_AssetsSpec(in *kops.AssetsSpec, out
 	out.ContainerRegistry = in.ContainerRegistry
 	out.FileRepository = in.FileRepository
 	out.ContainerProxy = in.ContainerProxy
+	if in.SignaturePolicy != nil {
+		in, out := &in.SignaturePolicy, &out.SignaturePolicy
+		*out = new(AssetsSignaturePolicy)
+		if err := Convert_kops_AssetsSignaturePolicy_To_v1alpha2_AssetsSignaturePolicy(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.SignaturePolicy = nil
+	}
 	return nil
 }
 
@@ -1589,6 +1731,7 @@ func autoConvert_v1alpha2_AuthenticationSpec_To_kops_AuthenticationSpec(in *Auth
 		out.AWS = nil
 	}
 	out.OIDC = in.OIDC
+	out.AdminCredentialDenyList = in.AdminCredentialDenyList
 	return nil
 }
 
@@ -1617,6 +1760,7 @@ func autoConvert_kops_AuthenticationSpec_To_v1alpha2_AuthenticationSpec(in *kops
 		out.AWS = nil
 	}
 	out.OIDC = in.OIDC
+	out.AdminCredentialDenyList = in.AdminCredentialDenyList
 	return nil
 }
 
@@ -2728,6 +2872,24 @@ func autoConvert_v1alpha2_ClusterSpec_To_kops_ClusterSpec(in *ClusterSpec, out *
 	} else {
 		out.RollingUpdate = nil
 	}
+	if in.NodeAudit != nil {
+		in, out := &in.NodeAudit, &out.NodeAudit
+		*out = new(kops.NodeAuditSpec)
+		if err := Convert_v1alpha2_NodeAuditSpec_To_kops_NodeAuditSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.NodeAudit = nil
+	}
+	if in.NodeCertificateRenewal != nil {
+		in, out := &in.NodeCertificateRenewal, &out.NodeCertificateRenewal
+		*out = new(kops.NodeCertificateRenewalSpec)
+		if err := Convert_v1alpha2_NodeCertificateRenewalSpec_To_kops_NodeCertificateRenewalSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.NodeCertificateRenewal = nil
+	}
 	if in.ClusterAutoscaler != nil {
 		in, out := &in.ClusterAutoscaler, &out.ClusterAutoscaler
 		*out = new(kops.ClusterAutoscalerConfig)
@@ -3063,6 +3225,24 @@ func autoConvert_kops_ClusterSpec_To_v1alpha2_ClusterSpec(in *kops.ClusterSpec,
 	} else {
 		out.RollingUpdate = nil
 	}
+	if in.NodeAudit != nil {
+		in, out := &in.NodeAudit, &out.NodeAudit
+		*out = new(NodeAuditSpec)
+		if err := Convert_kops_NodeAuditSpec_To_v1alpha2_NodeAuditSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.NodeAudit = nil
+	}
+	if in.NodeCertificateRenewal != nil {
+		in, out := &in.NodeCertificateRenewal, &out.NodeCertificateRenewal
+		*out = new(NodeCertificateRenewalSpec)
+		if err := Convert_kops_NodeCertificateRenewalSpec_To_v1alpha2_NodeCertificateRenewalSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.NodeCertificateRenewal = nil
+	}
 	if in.ClusterAutoscaler != nil {
 		in, out := &in.ClusterAutoscaler, &out.ClusterAutoscaler
 		*out = new(ClusterAutoscalerConfig)
@@ -3175,6 +3355,17 @@ func autoConvert_v1alpha2_ContainerdConfig_To_kops_ContainerdConfig(in *Containe
 		out.Packages = nil
 	}
 	out.RegistryMirrors = in.RegistryMirrors
+	if in.Registries != nil {
+		in, out := &in.Registries, &out.Registries
+		*out = make([]kops.ContainerdRegistryConfig, len(*in))
+		for i := range *in {
+			if err := Convert_v1alpha2_ContainerdRegistryConfig_To_kops_ContainerdRegistryConfig(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Registries = nil
+	}
 	out.Root = in.Root
 	out.SkipInstall = in.SkipInstall
 	out.State = in.State
@@ -3188,6 +3379,24 @@ func autoConvert_v1alpha2_ContainerdConfig_To_kops_ContainerdConfig(in *Containe
 	} else {
 		out.NvidiaGPU = nil
 	}
+	if in.GVisor != nil {
+		in, out := &in.GVisor, &out.GVisor
+		*out = new(kops.GVisorConfig)
+		if err := Convert_v1alpha2_GVisorConfig_To_kops_GVisorConfig(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.GVisor = nil
+	}
+	if in.Kata != nil {
+		in, out := &in.Kata, &out.Kata
+		*out = new(kops.KataConfig)
+		if err := Convert_v1alpha2_KataConfig_To_kops_KataConfig(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.Kata = nil
+	}
 	if in.Runc != nil {
 		in, out := &in.Runc, &out.Runc
 		*out = new(kops.Runc)
@@ -3230,6 +3439,17 @@ func autoConvert_kops_ContainerdConfig_To_v1alpha2_ContainerdConfig(in *kops.Con
 		out.Packages = nil
 	}
 	out.RegistryMirrors = in.RegistryMirrors
+	if in.Registries != nil {
+		in, out := &in.Registries, &out.Registries
+		*out = make([]ContainerdRegistryConfig, len(*in))
+		for i := range *in {
+			if err := Convert_kops_ContainerdRegistryConfig_To_v1alpha2_ContainerdRegistryConfig(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Registries = nil
+	}
 	out.Root = in.Root
 	out.SkipInstall = in.SkipInstall
 	out.State = in.State
@@ -3243,6 +3463,24 @@ func autoConvert_kops_ContainerdConfig_To_v1alpha2_ContainerdConfig(in *kops.Con
 	} else {
 		out.NvidiaGPU = nil
 	}
+	if in.GVisor != nil {
+		in, out := &in.GVisor, &out.GVisor
+		*out = new(GVisorConfig)
+		if err := Convert_kops_GVisorConfig_To_v1alpha2_GVisorConfig(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.GVisor = nil
+	}
+	if in.Kata != nil {
+		in, out := &in.Kata, &out.Kata
+		*out = new(KataConfig)
+		if err := Convert_kops_KataConfig_To_v1alpha2_KataConfig(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.Kata = nil
+	}
 	if in.Runc != nil {
 		in, out := &in.Runc, &out.Runc
 		*out = new(Runc)
@@ -3270,6 +3508,36 @@ func Convert_kops_ContainerdConfig_To_v1alpha2_ContainerdConfig(in *kops.Contain
 	return autoConvert_kops_ContainerdConfig_To_v1alpha2_ContainerdConfig(in, out, s)
 }
 
+func autoConvert_v1alpha2_ContainerdRegistryConfig_To_kops_ContainerdRegistryConfig(in *ContainerdRegistryConfig, out *kops.ContainerdRegistryConfig, s conversion.Scope) error {
+	out.Host = in.Host
+	out.Server = in.Server
+	out.Mirrors = in.Mirrors
+	out.CA = in.CA
+	out.SkipVerify = in.SkipVerify
+	out.Secret = in.Secret
+	return nil
+}
+
+// Convert_v1alpha2_ContainerdRegistryConfig_To_kops_ContainerdRegistryConfig is an autogenerated conversion function.
+func Convert_v1alpha2_ContainerdRegistryConfig_To_kops_ContainerdRegistryConfig(in *ContainerdRegistryConfig, out *kops.ContainerdRegistryConfig, s conversion.Scope) error {
+	return autoConvert_v1alpha2_ContainerdRegistryConfig_To_kops_ContainerdRegistryConfig(in, out, s)
+}
+
+func autoConvert_kops_ContainerdRegistryConfig_To_v1alpha2_ContainerdRegistryConfig(in *kops.ContainerdRegistryConfig, out *ContainerdRegistryConfig, s conversion.Scope) error {
+	out.Host = in.Host
+	out.Server = in.Server
+	out.Mirrors = in.Mirrors
+	out.CA = in.CA
+	out.SkipVerify = in.SkipVerify
+	out.Secret = in.Secret
+	return nil
+}
+
+// Convert_kops_ContainerdRegistryConfig_To_v1alpha2_ContainerdRegistryConfig is an autogenerated conversion function.
+func Convert_kops_ContainerdRegistryConfig_To_v1alpha2_ContainerdRegistryConfig(in *kops.ContainerdRegistryConfig, out *ContainerdRegistryConfig, s conversion.Scope) error {
+	return autoConvert_kops_ContainerdRegistryConfig_To_v1alpha2_ContainerdRegistryConfig(in, out, s)
+}
+
 func autoConvert_v1alpha2_DCGMExporterConfig_To_kops_DCGMExporterConfig(in *DCGMExporterConfig, out *kops.DCGMExporterConfig, s conversion.Scope) error {
 	out.Enabled = in.Enabled
 	return nil
@@ -3565,6 +3833,15 @@ func Convert_kops_EnvVar_To_v1alpha2_EnvVar(in *kops.EnvVar, out *EnvVar, s conv
 func autoConvert_v1alpha2_EtcdBackupSpec_To_kops_EtcdBackupSpec(in *EtcdBackupSpec, out *kops.EtcdBackupSpec, s conversion.Scope) error {
 	out.BackupStore = in.BackupStore
 	out.Image = in.Image
+	if in.Verification != nil {
+		in, out := &in.Verification, &out.Verification
+		*out = new(kops.EtcdBackupVerificationSpec)
+		if err := Convert_v1alpha2_EtcdBackupVerificationSpec_To_kops_EtcdBackupVerificationSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.Verification = nil
+	}
 	return nil
 }
 
@@ -3576,6 +3853,15 @@ func Convert_v1alpha2_EtcdBackupSpec_To_kops_EtcdBackupSpec(in *EtcdBackupSpec,
 func autoConvert_kops_EtcdBackupSpec_To_v1alpha2_EtcdBackupSpec(in *kops.EtcdBackupSpec, out *EtcdBackupSpec, s conversion.Scope) error {
 	out.BackupStore = in.BackupStore
 	out.Image = in.Image
+	if in.Verification != nil {
+		in, out := &in.Verification, &out.Verification
+		*out = new(EtcdBackupVerificationSpec)
+		if err := Convert_kops_EtcdBackupVerificationSpec_To_v1alpha2_EtcdBackupVerificationSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.Verification = nil
+	}
 	return nil
 }
 
@@ -3584,6 +3870,28 @@ func Convert_kops_EtcdBackupSpec_To_v1alpha2_EtcdBackupSpec(in *kops.EtcdBackupS
 	return autoConvert_kops_EtcdBackupSpec_To_v1alpha2_EtcdBackupSpec(in, out, s)
 }
 
+func autoConvert_v1alpha2_EtcdBackupVerificationSpec_To_kops_EtcdBackupVerificationSpec(in *EtcdBackupVerificationSpec, out *kops.EtcdBackupVerificationSpec, s conversion.Scope) error {
+	out.Schedule = in.Schedule
+	out.PushgatewayURL = in.PushgatewayURL
+	return nil
+}
+
+// Convert_v1alpha2_EtcdBackupVerificationSpec_To_kops_EtcdBackupVerificationSpec is an autogenerated conversion function.
+func Convert_v1alpha2_EtcdBackupVerificationSpec_To_kops_EtcdBackupVerificationSpec(in *EtcdBackupVerificationSpec, out *kops.EtcdBackupVerificationSpec, s conversion.Scope) error {
+	return autoConvert_v1alpha2_EtcdBackupVerificationSpec_To_kops_EtcdBackupVerificationSpec(in, out, s)
+}
+
+func autoConvert_kops_EtcdBackupVerificationSpec_To_v1alpha2_EtcdBackupVerificationSpec(in *kops.EtcdBackupVerificationSpec, out *EtcdBackupVerificationSpec, s conversion.Scope) error {
+	out.Schedule = in.Schedule
+	out.PushgatewayURL = in.PushgatewayURL
+	return nil
+}
+
+// Convert_kops_EtcdBackupVerificationSpec_To_v1alpha2_EtcdBackupVerificationSpec is an autogenerated conversion function.
+func Convert_kops_EtcdBackupVerificationSpec_To_v1alpha2_EtcdBackupVerificationSpec(in *kops.EtcdBackupVerificationSpec, out *EtcdBackupVerificationSpec, s conversion.Scope) error {
+	return autoConvert_kops_EtcdBackupVerificationSpec_To_v1alpha2_EtcdBackupVerificationSpec(in, out, s)
+}
+
 func autoConvert_v1alpha2_EtcdClusterSpec_To_kops_EtcdClusterSpec(in *EtcdClusterSpec, out *kops.EtcdClusterSpec, s conversion.Scope) error {
 	out.Name = in.Name
 	out.Provider = kops.EtcdProviderType(in.Provider)
@@ -3791,6 +4099,9 @@ func Convert_kops_ExecContainerAction_To_v1alpha2_ExecContainerAction(in *kops.E
 func autoConvert_v1alpha2_ExternalDNSConfig_To_kops_ExternalDNSConfig(in *ExternalDNSConfig, out *kops.ExternalDNSConfig, s conversion.Scope) error {
 	// INFO: in.Disable opted out of conversion generation
 	out.WatchIngress = in.WatchIngress
+	out.WatchGatewayAPI = in.WatchGatewayAPI
+	out.TXTOwnership = in.TXTOwnership
+	out.TXTAdoptExisting = in.TXTAdoptExisting
 	out.WatchNamespace = in.WatchNamespace
 	out.Provider = kops.ExternalDNSProvider(in.Provider)
 	return nil
@@ -3798,6 +4109,9 @@ func autoConvert_v1alpha2_ExternalDNSConfig_To_kops_ExternalDNSConfig(in *Extern
 
 func autoConvert_kops_ExternalDNSConfig_To_v1alpha2_ExternalDNSConfig(in *kops.ExternalDNSConfig, out *ExternalDNSConfig, s conversion.Scope) error {
 	out.WatchIngress = in.WatchIngress
+	out.WatchGatewayAPI = in.WatchGatewayAPI
+	out.TXTOwnership = in.TXTOwnership
+	out.TXTAdoptExisting = in.TXTAdoptExisting
 	out.WatchNamespace = in.WatchNamespace
 	out.Provider = ExternalDNSProvider(in.Provider)
 	return nil
@@ -3908,6 +4222,28 @@ func Convert_kops_GCPNetworkingSpec_To_v1alpha2_GCPNetworkingSpec(in *kops.GCPNe
 	return autoConvert_kops_GCPNetworkingSpec_To_v1alpha2_GCPNetworkingSpec(in, out, s)
 }
 
+func autoConvert_v1alpha2_GVisorConfig_To_kops_GVisorConfig(in *GVisorConfig, out *kops.GVisorConfig, s conversion.Scope) error {
+	out.Enabled = in.Enabled
+	out.Version = in.Version
+	return nil
+}
+
+// Convert_v1alpha2_GVisorConfig_To_kops_GVisorConfig is an autogenerated conversion function.
+func Convert_v1alpha2_GVisorConfig_To_kops_GVisorConfig(in *GVisorConfig, out *kops.GVisorConfig, s conversion.Scope) error {
+	return autoConvert_v1alpha2_GVisorConfig_To_kops_GVisorConfig(in, out, s)
+}
+
+func autoConvert_kops_GVisorConfig_To_v1alpha2_GVisorConfig(in *kops.GVisorConfig, out *GVisorConfig, s conversion.Scope) error {
+	out.Enabled = in.Enabled
+	out.Version = in.Version
+	return nil
+}
+
+// Convert_kops_GVisorConfig_To_v1alpha2_GVisorConfig is an autogenerated conversion function.
+func Convert_kops_GVisorConfig_To_v1alpha2_GVisorConfig(in *kops.GVisorConfig, out *GVisorConfig, s conversion.Scope) error {
+	return autoConvert_kops_GVisorConfig_To_v1alpha2_GVisorConfig(in, out, s)
+}
+
 func autoConvert_v1alpha2_GossipConfig_To_kops_GossipConfig(in *GossipConfig, out *kops.GossipConfig, s conversion.Scope) error {
 	out.Protocol = in.Protocol
 	out.Listen = in.Listen
@@ -4338,6 +4674,18 @@ func autoConvert_v1alpha2_InstanceGroupSpec_To_kops_InstanceGroupSpec(in *Instan
 	out.SecurityGroupOverride = in.SecurityGroupOverride
 	out.InstanceProtection = in.InstanceProtection
 	out.SysctlParameters = in.SysctlParameters
+	if in.KernelModules != nil {
+		in, out := &in.KernelModules, &out.KernelModules
+		*out = make([]kops.KernelModuleSpec, len(*in))
+		for i := range *in {
+			if err := Convert_v1alpha2_KernelModuleSpec_To_kops_KernelModuleSpec(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.KernelModules = nil
+	}
+	out.KernelBootParameters = in.KernelBootParameters
 	if in.RollingUpdate != nil {
 		in, out := &in.RollingUpdate, &out.RollingUpdate
 		*out = new(kops.RollingUpdate)
@@ -4349,6 +4697,7 @@ func autoConvert_v1alpha2_InstanceGroupSpec_To_kops_InstanceGroupSpec(in *Instan
 	}
 	out.InstanceInterruptionBehavior = in.InstanceInterruptionBehavior
 	out.CompressUserData = in.CompressUserData
+	out.UserDataFormat = kops.UserDataFormat(in.UserDataFormat)
 	if in.InstanceMetadata != nil {
 		in, out := &in.InstanceMetadata, &out.InstanceMetadata
 		*out = new(kops.InstanceMetadataOptions)
@@ -4378,6 +4727,17 @@ func autoConvert_v1alpha2_InstanceGroupSpec_To_kops_InstanceGroupSpec(in *Instan
 		out.Containerd = nil
 	}
 	out.Packages = in.Packages
+	if in.PrepulledImages != nil {
+		in, out := &in.PrepulledImages, &out.PrepulledImages
+		*out = make([]kops.PrepulledImageSpec, len(*in))
+		for i := range *in {
+			if err := Convert_v1alpha2_PrepulledImageSpec_To_kops_PrepulledImageSpec(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.PrepulledImages = nil
+	}
 	if in.GuestAccelerators != nil {
 		in, out := &in.GuestAccelerators, &out.GuestAccelerators
 		*out = make([]kops.AcceleratorConfig, len(*in))
@@ -4514,6 +4874,18 @@ func autoConvert_kops_InstanceGroupSpec_To_v1alpha2_InstanceGroupSpec(in *kops.I
 	out.SecurityGroupOverride = in.SecurityGroupOverride
 	out.InstanceProtection = in.InstanceProtection
 	out.SysctlParameters = in.SysctlParameters
+	if in.KernelModules != nil {
+		in, out := &in.KernelModules, &out.KernelModules
+		*out = make([]KernelModuleSpec, len(*in))
+		for i := range *in {
+			if err := Convert_kops_KernelModuleSpec_To_v1alpha2_KernelModuleSpec(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.KernelModules = nil
+	}
+	out.KernelBootParameters = in.KernelBootParameters
 	if in.RollingUpdate != nil {
 		in, out := &in.RollingUpdate, &out.RollingUpdate
 		*out = new(RollingUpdate)
@@ -4525,6 +4897,7 @@ func autoConvert_kops_InstanceGroupSpec_To_v1alpha2_InstanceGroupSpec(in *kops.I
 	}
 	out.InstanceInterruptionBehavior = in.InstanceInterruptionBehavior
 	out.CompressUserData = in.CompressUserData
+	out.UserDataFormat = UserDataFormat(in.UserDataFormat)
 	if in.InstanceMetadata != nil {
 		in, out := &in.InstanceMetadata, &out.InstanceMetadata
 		*out = new(InstanceMetadataOptions)
@@ -4554,6 +4927,17 @@ func autoConvert_kops_InstanceGroupSpec_To_v1alpha2_InstanceGroupSpec(in *kops.I
 		out.Containerd = nil
 	}
 	out.Packages = in.Packages
+	if in.PrepulledImages != nil {
+		in, out := &in.PrepulledImages, &out.PrepulledImages
+		*out = make([]PrepulledImageSpec, len(*in))
+		for i := range *in {
+			if err := Convert_kops_PrepulledImageSpec_To_v1alpha2_PrepulledImageSpec(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.PrepulledImages = nil
+	}
 	if in.GuestAccelerators != nil {
 		in, out := &in.GuestAccelerators, &out.GuestAccelerators
 		*out = make([]AcceleratorConfig, len(*in))
@@ -4678,6 +5062,68 @@ func Convert_kops_KarpenterConfig_To_v1alpha2_KarpenterConfig(in *kops.Karpenter
 	return autoConvert_kops_KarpenterConfig_To_v1alpha2_KarpenterConfig(in, out, s)
 }
 
+func autoConvert_v1alpha2_KataConfig_To_kops_KataConfig(in *KataConfig, out *kops.KataConfig, s conversion.Scope) error {
+	out.Enabled = in.Enabled
+	out.Version = in.Version
+	if in.Packages != nil {
+		in, out := &in.Packages, &out.Packages
+		*out = new(kops.PackagesConfig)
+		if err := Convert_v1alpha2_PackagesConfig_To_kops_PackagesConfig(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.Packages = nil
+	}
+	return nil
+}
+
+// Convert_v1alpha2_KataConfig_To_kops_KataConfig is an autogenerated conversion function.
+func Convert_v1alpha2_KataConfig_To_kops_KataConfig(in *KataConfig, out *kops.KataConfig, s conversion.Scope) error {
+	return autoConvert_v1alpha2_KataConfig_To_kops_KataConfig(in, out, s)
+}
+
+func autoConvert_kops_KataConfig_To_v1alpha2_KataConfig(in *kops.KataConfig, out *KataConfig, s conversion.Scope) error {
+	out.Enabled = in.Enabled
+	out.Version = in.Version
+	if in.Packages != nil {
+		in, out := &in.Packages, &out.Packages
+		*out = new(PackagesConfig)
+		if err := Convert_kops_PackagesConfig_To_v1alpha2_PackagesConfig(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.Packages = nil
+	}
+	return nil
+}
+
+// Convert_kops_KataConfig_To_v1alpha2_KataConfig is an autogenerated conversion function.
+func Convert_kops_KataConfig_To_v1alpha2_KataConfig(in *kops.KataConfig, out *KataConfig, s conversion.Scope) error {
+	return autoConvert_kops_KataConfig_To_v1alpha2_KataConfig(in, out, s)
+}
+
+func autoConvert_v1alpha2_KernelModuleSpec_To_kops_KernelModuleSpec(in *KernelModuleSpec, out *kops.KernelModuleSpec, s conversion.Scope) error {
+	out.Name = in.Name
+	out.Options = in.Options
+	return nil
+}
+
+// Convert_v1alpha2_KernelModuleSpec_To_kops_KernelModuleSpec is an autogenerated conversion function.
+func Convert_v1alpha2_KernelModuleSpec_To_kops_KernelModuleSpec(in *KernelModuleSpec, out *kops.KernelModuleSpec, s conversion.Scope) error {
+	return autoConvert_v1alpha2_KernelModuleSpec_To_kops_KernelModuleSpec(in, out, s)
+}
+
+func autoConvert_kops_KernelModuleSpec_To_v1alpha2_KernelModuleSpec(in *kops.KernelModuleSpec, out *KernelModuleSpec, s conversion.Scope) error {
+	out.Name = in.Name
+	out.Options = in.Options
+	return nil
+}
+
+// Convert_kops_KernelModuleSpec_To_v1alpha2_KernelModuleSpec is an autogenerated conversion function.
+func Convert_kops_KernelModuleSpec_To_v1alpha2_KernelModuleSpec(in *kops.KernelModuleSpec, out *KernelModuleSpec, s conversion.Scope) error {
+	return autoConvert_kops_KernelModuleSpec_To_v1alpha2_KernelModuleSpec(in, out, s)
+}
+
 func autoConvert_v1alpha2_Keyset_To_kops_Keyset(in *Keyset, out *kops.Keyset, s conversion.Scope) error {
 	out.ObjectMeta = in.ObjectMeta
 	if err := Convert_v1alpha2_KeysetSpec_To_kops_KeysetSpec(&in.Spec, &out.Spec, s); err != nil {
@@ -6386,6 +6832,28 @@ func Convert_kops_NetworkingSpec_To_v1alpha2_NetworkingSpec(in *kops.NetworkingS
 	return autoConvert_kops_NetworkingSpec_To_v1alpha2_NetworkingSpec(in, out, s)
 }
 
+func autoConvert_v1alpha2_NodeAuditSpec_To_kops_NodeAuditSpec(in *NodeAuditSpec, out *kops.NodeAuditSpec, s conversion.Scope) error {
+	out.Enabled = in.Enabled
+	out.Interval = in.Interval
+	return nil
+}
+
+// Convert_v1alpha2_NodeAuditSpec_To_kops_NodeAuditSpec is an autogenerated conversion function.
+func Convert_v1alpha2_NodeAuditSpec_To_kops_NodeAuditSpec(in *NodeAuditSpec, out *kops.NodeAuditSpec, s conversion.Scope) error {
+	return autoConvert_v1alpha2_NodeAuditSpec_To_kops_NodeAuditSpec(in, out, s)
+}
+
+func autoConvert_kops_NodeAuditSpec_To_v1alpha2_NodeAuditSpec(in *kops.NodeAuditSpec, out *NodeAuditSpec, s conversion.Scope) error {
+	out.Enabled = in.Enabled
+	out.Interval = in.Interval
+	return nil
+}
+
+// Convert_kops_NodeAuditSpec_To_v1alpha2_NodeAuditSpec is an autogenerated conversion function.
+func Convert_kops_NodeAuditSpec_To_v1alpha2_NodeAuditSpec(in *kops.NodeAuditSpec, out *NodeAuditSpec, s conversion.Scope) error {
+	return autoConvert_kops_NodeAuditSpec_To_v1alpha2_NodeAuditSpec(in, out, s)
+}
+
 func autoConvert_v1alpha2_NodeAuthorizationSpec_To_kops_NodeAuthorizationSpec(in *NodeAuthorizationSpec, out *kops.NodeAuthorizationSpec, s conversion.Scope) error {
 	if in.NodeAuthorizer != nil {
 		in, out := &in.NodeAuthorizer, &out.NodeAuthorizer
@@ -6456,6 +6924,28 @@ func Convert_kops_NodeAuthorizerSpec_To_v1alpha2_NodeAuthorizerSpec(in *kops.Nod
 	return autoConvert_kops_NodeAuthorizerSpec_To_v1alpha2_NodeAuthorizerSpec(in, out, s)
 }
 
+func autoConvert_v1alpha2_NodeCertificateRenewalSpec_To_kops_NodeCertificateRenewalSpec(in *NodeCertificateRenewalSpec, out *kops.NodeCertificateRenewalSpec, s conversion.Scope) error {
+	out.Enabled = in.Enabled
+	out.RenewBefore = in.RenewBefore
+	return nil
+}
+
+// Convert_v1alpha2_NodeCertificateRenewalSpec_To_kops_NodeCertificateRenewalSpec is an autogenerated conversion function.
+func Convert_v1alpha2_NodeCertificateRenewalSpec_To_kops_NodeCertificateRenewalSpec(in *NodeCertificateRenewalSpec, out *kops.NodeCertificateRenewalSpec, s conversion.Scope) error {
+	return autoConvert_v1alpha2_NodeCertificateRenewalSpec_To_kops_NodeCertificateRenewalSpec(in, out, s)
+}
+
+func autoConvert_kops_NodeCertificateRenewalSpec_To_v1alpha2_NodeCertificateRenewalSpec(in *kops.NodeCertificateRenewalSpec, out *NodeCertificateRenewalSpec, s conversion.Scope) error {
+	out.Enabled = in.Enabled
+	out.RenewBefore = in.RenewBefore
+	return nil
+}
+
+// Convert_kops_NodeCertificateRenewalSpec_To_v1alpha2_NodeCertificateRenewalSpec is an autogenerated conversion function.
+func Convert_kops_NodeCertificateRenewalSpec_To_v1alpha2_NodeCertificateRenewalSpec(in *kops.NodeCertificateRenewalSpec, out *NodeCertificateRenewalSpec, s conversion.Scope) error {
+	return autoConvert_kops_NodeCertificateRenewalSpec_To_v1alpha2_NodeCertificateRenewalSpec(in, out, s)
+}
+
 func autoConvert_v1alpha2_NodeLocalDNSConfig_To_kops_NodeLocalDNSConfig(in *NodeLocalDNSConfig, out *kops.NodeLocalDNSConfig, s conversion.Scope) error {
 	out.Enabled = in.Enabled
 	out.ExternalCoreFile = in.ExternalCoreFile
@@ -6986,6 +7476,32 @@ func Convert_kops_PodIdentityWebhookSpec_To_v1alpha2_PodIdentityWebhookSpec(in *
 	return autoConvert_kops_PodIdentityWebhookSpec_To_v1alpha2_PodIdentityWebhookSpec(in, out, s)
 }
 
+func autoConvert_v1alpha2_PrepulledImageSpec_To_kops_PrepulledImageSpec(in *PrepulledImageSpec, out *kops.PrepulledImageSpec, s conversion.Scope) error {
+	out.Image = in.Image
+	out.Digest = in.Digest
+	out.Source = in.Source
+	out.Hash = in.Hash
+	return nil
+}
+
+// Convert_v1alpha2_PrepulledImageSpec_To_kops_PrepulledImageSpec is an autogenerated conversion function.
+func Convert_v1alpha2_PrepulledImageSpec_To_kops_PrepulledImageSpec(in *PrepulledImageSpec, out *kops.PrepulledImageSpec, s conversion.Scope) error {
+	return autoConvert_v1alpha2_PrepulledImageSpec_To_kops_PrepulledImageSpec(in, out, s)
+}
+
+func autoConvert_kops_PrepulledImageSpec_To_v1alpha2_PrepulledImageSpec(in *kops.PrepulledImageSpec, out *PrepulledImageSpec, s conversion.Scope) error {
+	out.Image = in.Image
+	out.Digest = in.Digest
+	out.Source = in.Source
+	out.Hash = in.Hash
+	return nil
+}
+
+// Convert_kops_PrepulledImageSpec_To_v1alpha2_PrepulledImageSpec is an autogenerated conversion function.
+func Convert_kops_PrepulledImageSpec_To_v1alpha2_PrepulledImageSpec(in *kops.PrepulledImageSpec, out *PrepulledImageSpec, s conversion.Scope) error {
+	return autoConvert_kops_PrepulledImageSpec_To_v1alpha2_PrepulledImageSpec(in, out, s)
+}
+
 func autoConvert_v1alpha2_RBACAuthorizationSpec_To_kops_RBACAuthorizationSpec(in *RBACAuthorizationSpec, out *kops.RBACAuthorizationSpec, s conversion.Scope) error {
 	return nil
 }
@@ -7008,6 +7524,7 @@ func autoConvert_v1alpha2_RollingUpdate_To_kops_RollingUpdate(in *RollingUpdate,
 	out.DrainAndTerminate = in.DrainAndTerminate
 	out.MaxUnavailable = in.MaxUnavailable
 	out.MaxSurge = in.MaxSurge
+	out.InPlace = in.InPlace
 	return nil
 }
 
@@ -7020,6 +7537,7 @@ func autoConvert_kops_RollingUpdate_To_v1alpha2_RollingUpdate(in *kops.RollingUp
 	out.DrainAndTerminate = in.DrainAndTerminate
 	out.MaxUnavailable = in.MaxUnavailable
 	out.MaxSurge = in.MaxSurge
+	out.InPlace = in.InPlace
 	return nil
 }
 
@@ -7397,12 +7915,43 @@ func Convert_kops_UserData_To_v1alpha2_UserData(in *kops.UserData, out *UserData
 	return autoConvert_kops_UserData_To_v1alpha2_UserData(in, out, s)
 }
 
+func autoConvert_v1alpha2_VolumeEncryptionSpec_To_kops_VolumeEncryptionSpec(in *VolumeEncryptionSpec, out *kops.VolumeEncryptionSpec, s conversion.Scope) error {
+	out.KeySource = kops.VolumeEncryptionKeySource(in.KeySource)
+	out.Cipher = in.Cipher
+	return nil
+}
+
+// Convert_v1alpha2_VolumeEncryptionSpec_To_kops_VolumeEncryptionSpec is an autogenerated conversion function.
+func Convert_v1alpha2_VolumeEncryptionSpec_To_kops_VolumeEncryptionSpec(in *VolumeEncryptionSpec, out *kops.VolumeEncryptionSpec, s conversion.Scope) error {
+	return autoConvert_v1alpha2_VolumeEncryptionSpec_To_kops_VolumeEncryptionSpec(in, out, s)
+}
+
+func autoConvert_kops_VolumeEncryptionSpec_To_v1alpha2_VolumeEncryptionSpec(in *kops.VolumeEncryptionSpec, out *VolumeEncryptionSpec, s conversion.Scope) error {
+	out.KeySource = VolumeEncryptionKeySource(in.KeySource)
+	out.Cipher = in.Cipher
+	return nil
+}
+
+// Convert_kops_VolumeEncryptionSpec_To_v1alpha2_VolumeEncryptionSpec is an autogenerated conversion function.
+func Convert_kops_VolumeEncryptionSpec_To_v1alpha2_VolumeEncryptionSpec(in *kops.VolumeEncryptionSpec, out *VolumeEncryptionSpec, s conversion.Scope) error {
+	return autoConvert_kops_VolumeEncryptionSpec_To_v1alpha2_VolumeEncryptionSpec(in, out, s)
+}
+
 func autoConvert_v1alpha2_VolumeMountSpec_To_kops_VolumeMountSpec(in *VolumeMountSpec, out *kops.VolumeMountSpec, s conversion.Scope) error {
 	out.Device = in.Device
 	out.Filesystem = in.Filesystem
 	out.FormatOptions = in.FormatOptions
 	out.MountOptions = in.MountOptions
 	out.Path = in.Path
+	if in.Encryption != nil {
+		in, out := &in.Encryption, &out.Encryption
+		*out = new(kops.VolumeEncryptionSpec)
+		if err := Convert_v1alpha2_VolumeEncryptionSpec_To_kops_VolumeEncryptionSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.Encryption = nil
+	}
 	return nil
 }
 
@@ -7417,6 +7966,15 @@ func autoConvert_kops_VolumeMountSpec_To_v1alpha2_VolumeMountSpec(in *kops.Volum
 	out.FormatOptions = in.FormatOptions
 	out.MountOptions = in.MountOptions
 	out.Path = in.Path
+	if in.Encryption != nil {
+		in, out := &in.Encryption, &out.Encryption
+		*out = new(VolumeEncryptionSpec)
+		if err := Convert_kops_VolumeEncryptionSpec_To_v1alpha2_VolumeEncryptionSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.Encryption = nil
+	}
 	return nil
 }
 
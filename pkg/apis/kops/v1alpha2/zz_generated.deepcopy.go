@@ -245,6 +245,27 @@ func (in *AmazonVPCNetworkingSpec) DeepCopy() *AmazonVPCNetworkingSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AssetsSignaturePolicy) DeepCopyInto(out *AssetsSignaturePolicy) {
+	*out = *in
+	if in.PublicKeys != nil {
+		in, out := &in.PublicKeys, &out.PublicKeys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AssetsSignaturePolicy.
+func (in *AssetsSignaturePolicy) DeepCopy() *AssetsSignaturePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(AssetsSignaturePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AssetsSpec) DeepCopyInto(out *AssetsSpec) {
 	*out = *in
@@ -263,6 +284,11 @@ func (in *AssetsSpec) DeepCopyInto(out *AssetsSpec) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.SignaturePolicy != nil {
+		in, out := &in.SignaturePolicy, &out.SignaturePolicy
+		*out = new(AssetsSignaturePolicy)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -1367,6 +1393,16 @@ func (in *ClusterSpec) DeepCopyInto(out *ClusterSpec) {
 		*out = new(RollingUpdate)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.NodeAudit != nil {
+		in, out := &in.NodeAudit, &out.NodeAudit
+		*out = new(NodeAuditSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NodeCertificateRenewal != nil {
+		in, out := &in.NodeCertificateRenewal, &out.NodeCertificateRenewal
+		*out = new(NodeCertificateRenewalSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.ClusterAutoscaler != nil {
 		in, out := &in.ClusterAutoscaler, &out.ClusterAutoscaler
 		*out = new(ClusterAutoscalerConfig)
@@ -1476,6 +1512,13 @@ func (in *ContainerdConfig) DeepCopyInto(out *ContainerdConfig) {
 			(*out)[key] = outVal
 		}
 	}
+	if in.Registries != nil {
+		in, out := &in.Registries, &out.Registries
+		*out = make([]ContainerdRegistryConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.Root != nil {
 		in, out := &in.Root, &out.Root
 		*out = new(string)
@@ -1496,6 +1539,16 @@ func (in *ContainerdConfig) DeepCopyInto(out *ContainerdConfig) {
 		*out = new(NvidiaGPUConfig)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.GVisor != nil {
+		in, out := &in.GVisor, &out.GVisor
+		*out = new(GVisorConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Kata != nil {
+		in, out := &in.Kata, &out.Kata
+		*out = new(KataConfig)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Runc != nil {
 		in, out := &in.Runc, &out.Runc
 		*out = new(Runc)
@@ -1519,6 +1572,32 @@ func (in *ContainerdConfig) DeepCopy() *ContainerdConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ContainerdRegistryConfig) DeepCopyInto(out *ContainerdRegistryConfig) {
+	*out = *in
+	if in.Server != nil {
+		in, out := &in.Server, &out.Server
+		*out = new(string)
+		**out = **in
+	}
+	if in.Mirrors != nil {
+		in, out := &in.Mirrors, &out.Mirrors
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContainerdRegistryConfig.
+func (in *ContainerdRegistryConfig) DeepCopy() *ContainerdRegistryConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ContainerdRegistryConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DCGMExporterConfig) DeepCopyInto(out *DCGMExporterConfig) {
 	*out = *in
@@ -1904,6 +1983,11 @@ func (in *EnvVar) DeepCopy() *EnvVar {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EtcdBackupSpec) DeepCopyInto(out *EtcdBackupSpec) {
 	*out = *in
+	if in.Verification != nil {
+		in, out := &in.Verification, &out.Verification
+		*out = new(EtcdBackupVerificationSpec)
+		**out = **in
+	}
 	return
 }
 
@@ -1917,6 +2001,22 @@ func (in *EtcdBackupSpec) DeepCopy() *EtcdBackupSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EtcdBackupVerificationSpec) DeepCopyInto(out *EtcdBackupVerificationSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EtcdBackupVerificationSpec.
+func (in *EtcdBackupVerificationSpec) DeepCopy() *EtcdBackupVerificationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(EtcdBackupVerificationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EtcdClusterSpec) DeepCopyInto(out *EtcdClusterSpec) {
 	*out = *in
@@ -1940,7 +2040,7 @@ func (in *EtcdClusterSpec) DeepCopyInto(out *EtcdClusterSpec) {
 	if in.Backups != nil {
 		in, out := &in.Backups, &out.Backups
 		*out = new(EtcdBackupSpec)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 	if in.Manager != nil {
 		in, out := &in.Manager, &out.Manager
@@ -2103,6 +2203,21 @@ func (in *ExternalDNSConfig) DeepCopyInto(out *ExternalDNSConfig) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.WatchGatewayAPI != nil {
+		in, out := &in.WatchGatewayAPI, &out.WatchGatewayAPI
+		*out = new(bool)
+		**out = **in
+	}
+	if in.TXTOwnership != nil {
+		in, out := &in.TXTOwnership, &out.TXTOwnership
+		*out = new(bool)
+		**out = **in
+	}
+	if in.TXTAdoptExisting != nil {
+		in, out := &in.TXTAdoptExisting, &out.TXTAdoptExisting
+		*out = new(bool)
+		**out = **in
+	}
 	return
 }
 
@@ -2190,6 +2305,32 @@ func (in *GCPNetworkingSpec) DeepCopy() *GCPNetworkingSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GVisorConfig) DeepCopyInto(out *GVisorConfig) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Version != nil {
+		in, out := &in.Version, &out.Version
+		*out = new(string)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GVisorConfig.
+func (in *GVisorConfig) DeepCopy() *GVisorConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(GVisorConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GossipConfig) DeepCopyInto(out *GossipConfig) {
 	*out = *in
@@ -2732,6 +2873,18 @@ func (in *InstanceGroupSpec) DeepCopyInto(out *InstanceGroupSpec) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.KernelModules != nil {
+		in, out := &in.KernelModules, &out.KernelModules
+		*out = make([]KernelModuleSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.KernelBootParameters != nil {
+		in, out := &in.KernelBootParameters, &out.KernelBootParameters
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	if in.RollingUpdate != nil {
 		in, out := &in.RollingUpdate, &out.RollingUpdate
 		*out = new(RollingUpdate)
@@ -2772,6 +2925,11 @@ func (in *InstanceGroupSpec) DeepCopyInto(out *InstanceGroupSpec) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.PrepulledImages != nil {
+		in, out := &in.PrepulledImages, &out.PrepulledImages
+		*out = make([]PrepulledImageSpec, len(*in))
+		copy(*out, *in)
+	}
 	if in.GuestAccelerators != nil {
 		in, out := &in.GuestAccelerators, &out.GuestAccelerators
 		*out = make([]AcceleratorConfig, len(*in))
@@ -2883,6 +3041,58 @@ func (in *KarpenterConfig) DeepCopy() *KarpenterConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KataConfig) DeepCopyInto(out *KataConfig) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Version != nil {
+		in, out := &in.Version, &out.Version
+		*out = new(string)
+		**out = **in
+	}
+	if in.Packages != nil {
+		in, out := &in.Packages, &out.Packages
+		*out = new(PackagesConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KataConfig.
+func (in *KataConfig) DeepCopy() *KataConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(KataConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KernelModuleSpec) DeepCopyInto(out *KernelModuleSpec) {
+	*out = *in
+	if in.Options != nil {
+		in, out := &in.Options, &out.Options
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KernelModuleSpec.
+func (in *KernelModuleSpec) DeepCopy() *KernelModuleSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KernelModuleSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Keyset) DeepCopyInto(out *Keyset) {
 	*out = *in
@@ -4724,6 +4934,32 @@ func (in *NetworkingSpec) DeepCopy() *NetworkingSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeAuditSpec) DeepCopyInto(out *NodeAuditSpec) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Interval != nil {
+		in, out := &in.Interval, &out.Interval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeAuditSpec.
+func (in *NodeAuditSpec) DeepCopy() *NodeAuditSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeAuditSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NodeAuthorizationSpec) DeepCopyInto(out *NodeAuthorizationSpec) {
 	*out = *in
@@ -4781,6 +5017,32 @@ func (in *NodeAuthorizerSpec) DeepCopy() *NodeAuthorizerSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeCertificateRenewalSpec) DeepCopyInto(out *NodeCertificateRenewalSpec) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.RenewBefore != nil {
+		in, out := &in.RenewBefore, &out.RenewBefore
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeCertificateRenewalSpec.
+func (in *NodeCertificateRenewalSpec) DeepCopy() *NodeCertificateRenewalSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeCertificateRenewalSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NodeLocalDNSConfig) DeepCopyInto(out *NodeLocalDNSConfig) {
 	*out = *in
@@ -5406,6 +5668,22 @@ func (in *PodIdentityWebhookSpec) DeepCopy() *PodIdentityWebhookSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PrepulledImageSpec) DeepCopyInto(out *PrepulledImageSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrepulledImageSpec.
+func (in *PrepulledImageSpec) DeepCopy() *PrepulledImageSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PrepulledImageSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RBACAuthorizationSpec) DeepCopyInto(out *RBACAuthorizationSpec) {
 	*out = *in
@@ -5440,6 +5718,11 @@ func (in *RollingUpdate) DeepCopyInto(out *RollingUpdate) {
 		*out = new(intstr.IntOrString)
 		**out = **in
 	}
+	if in.InPlace != nil {
+		in, out := &in.InPlace, &out.InPlace
+		*out = new(bool)
+		**out = **in
+	}
 	return
 }
 
@@ -5743,6 +6026,22 @@ func (in *UserData) DeepCopy() *UserData {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumeEncryptionSpec) DeepCopyInto(out *VolumeEncryptionSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeEncryptionSpec.
+func (in *VolumeEncryptionSpec) DeepCopy() *VolumeEncryptionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumeEncryptionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VolumeMountSpec) DeepCopyInto(out *VolumeMountSpec) {
 	*out = *in
@@ -5756,6 +6055,11 @@ func (in *VolumeMountSpec) DeepCopyInto(out *VolumeMountSpec) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.Encryption != nil {
+		in, out := &in.Encryption, &out.Encryption
+		*out = new(VolumeEncryptionSpec)
+		**out = **in
+	}
 	return
 }
 
@@ -18,6 +18,9 @@ package validation
 
 import (
 	"fmt"
+	"net/url"
+	"path"
+	"regexp"
 	"strings"
 
 	"k8s.io/kops/pkg/nodeidentity/aws"
@@ -32,10 +35,23 @@ import (
 	"k8s.io/kops/upup/pkg/fi"
 	"k8s.io/kops/upup/pkg/fi/cloudup/awsup"
 	"k8s.io/kops/upup/pkg/fi/cloudup/gce"
+	"k8s.io/kops/upup/pkg/fi/cloudup/metal"
+)
+
+var (
+	kernelModuleNameRegex = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+	kernelParameterRegex  = regexp.MustCompile(`^[^\s"'\\]+$`)
+	volumeCipherRegex     = regexp.MustCompile(`^[a-z0-9-]+$`)
+	imageDigestRegex      = regexp.MustCompile(`^sha256:[0-9a-f]{64}$`)
+	sha256HashRegex       = regexp.MustCompile(`^[0-9a-f]{64}$`)
 )
 
 // ValidateInstanceGroup is responsible for validating the configuration of a instancegroup
 func ValidateInstanceGroup(g *kops.InstanceGroup, cloud fi.Cloud, strict bool) field.ErrorList {
+	return validateInstanceGroup(g, cloud, strict, strict)
+}
+
+func validateInstanceGroup(g *kops.InstanceGroup, cloud fi.Cloud, strict bool, requireImage bool) field.ErrorList {
 	allErrs := field.ErrorList{}
 
 	if g.ObjectMeta.Name == "" {
@@ -86,7 +102,7 @@ func ValidateInstanceGroup(g *kops.InstanceGroup, cloud fi.Cloud, strict bool) f
 		}
 	}
 
-	if strict && g.Spec.Image == "" {
+	if requireImage && g.Spec.Image == "" {
 		allErrs = append(allErrs, field.Forbidden(field.NewPath("spec", "image"), "image must be specified."))
 	}
 
@@ -114,6 +130,13 @@ func ValidateInstanceGroup(g *kops.InstanceGroup, cloud fi.Cloud, strict bool) f
 		allErrs = append(allErrs, validateExtraUserData(&UserDataInfo)...)
 	}
 
+	if g.Spec.UserDataFormat != "" {
+		allErrs = append(allErrs, IsValidValue(field.NewPath("spec", "userDataFormat"), &g.Spec.UserDataFormat, []kops.UserDataFormat{kops.UserDataFormatShellScript, kops.UserDataFormatIgnition})...)
+		if g.Spec.UserDataFormat == kops.UserDataFormatIgnition && len(g.Spec.AdditionalUserData) > 0 {
+			allErrs = append(allErrs, field.Forbidden(field.NewPath("spec", "additionalUserData"), "additionalUserData is not supported with Ignition user data"))
+		}
+	}
+
 	// @step: iterate and check the volume specs
 	for i, x := range g.Spec.Volumes {
 		devices := make(map[string]bool)
@@ -135,6 +158,10 @@ func ValidateInstanceGroup(g *kops.InstanceGroup, cloud fi.Cloud, strict bool) f
 		path := field.NewPath("spec", "volumeMounts").Index(i)
 
 		allErrs = append(allErrs, validateVolumeMountSpec(path, x)...)
+		if x.Encryption != nil && x.Encryption.KeySource == kops.VolumeEncryptionKeySourceKopsController && g.IsControlPlane() {
+			// kops-controller runs on the control plane, so it isn't available when the control-plane nodes boot
+			allErrs = append(allErrs, field.Forbidden(path.Child("encryption", "keySource"), "control-plane nodes can't get their keys from kops-controller"))
+		}
 		if _, found := used[x.Device]; found {
 			allErrs = append(allErrs, field.Duplicate(path.Child("device"), x.Device))
 		}
@@ -151,8 +178,20 @@ func ValidateInstanceGroup(g *kops.InstanceGroup, cloud fi.Cloud, strict bool) f
 		}
 	}
 
+	allErrs = append(allErrs, validateKernelModules(g.Spec.KernelModules, field.NewPath("spec", "kernelModules"))...)
+	allErrs = append(allErrs, validatePrepulledImages(g.Spec.PrepulledImages, field.NewPath("spec", "prepulledImages"))...)
+
+	for i, parameter := range g.Spec.KernelBootParameters {
+		if !kernelParameterRegex.MatchString(parameter) {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "kernelBootParameters").Index(i), parameter, "must not be empty or contain spaces or quotes"))
+		}
+	}
+
 	if g.Spec.RollingUpdate != nil {
 		allErrs = append(allErrs, validateRollingUpdate(g.Spec.RollingUpdate, field.NewPath("spec", "rollingUpdate"), g.Spec.Role == kops.InstanceGroupRoleControlPlane)...)
+		if g.Spec.Role != kops.InstanceGroupRoleNode && g.Spec.RollingUpdate.InPlace != nil && *g.Spec.RollingUpdate.InPlace {
+			allErrs = append(allErrs, field.Forbidden(field.NewPath("spec", "rollingUpdate", "inPlace"), "In-place updates are only supported for instance groups with role \"Node\""))
+		}
 	}
 
 	if g.Spec.NodeLabels != nil {
@@ -226,18 +265,36 @@ func validateVolumeMountSpec(path *field.Path, spec kops.VolumeMountSpec) field.
 	}
 	allErrs = append(allErrs, IsValidValue(path.Child("filesystem"), &spec.Filesystem, kops.SupportedFilesystems)...)
 
+	if spec.Encryption != nil {
+		encryptionPath := path.Child("encryption")
+		if spec.Encryption.KeySource == "" {
+			allErrs = append(allErrs, field.Required(encryptionPath.Child("keySource"), "key source required"))
+		} else {
+			allErrs = append(allErrs, IsValidValue(encryptionPath.Child("keySource"), &spec.Encryption.KeySource, []kops.VolumeEncryptionKeySource{kops.VolumeEncryptionKeySourceKopsController, kops.VolumeEncryptionKeySourceTPM})...)
+		}
+		if spec.Encryption.Cipher != "" && !volumeCipherRegex.MatchString(spec.Encryption.Cipher) {
+			allErrs = append(allErrs, field.Invalid(encryptionPath.Child("cipher"), spec.Encryption.Cipher, "must be a cipher specification, like aes-xts-plain64"))
+		}
+	}
+
 	return allErrs
 }
 
 // CrossValidateInstanceGroup performs validation of the instance group, including that it is consistent with the Cluster
 // It calls ValidateInstanceGroup, so all that validation is included.
 func CrossValidateInstanceGroup(g *kops.InstanceGroup, cluster *kops.Cluster, cloud fi.Cloud, strict bool) field.ErrorList {
-	allErrs := ValidateInstanceGroup(g, cloud, strict)
+	// Bare-metal hosts are installed before they are enrolled
+	requireImage := strict && cluster.Spec.GetCloudProvider() != kops.CloudProviderMetal
+	allErrs := validateInstanceGroup(g, cloud, strict, requireImage)
 
 	if g.Spec.Role == kops.InstanceGroupRoleControlPlane {
 		allErrs = append(allErrs, ValidateControlPlaneInstanceGroup(g, cluster)...)
 	}
 
+	if g.UsesInPlaceUpdates(cluster) && cluster.Spec.GetCloudProvider() == kops.CloudProviderOpenstack {
+		allErrs = append(allErrs, field.Forbidden(field.NewPath("spec", "rollingUpdate", "inPlace"), "In-place updates are not supported on OpenStack"))
+	}
+
 	if g.Spec.Role == kops.InstanceGroupRoleAPIServer {
 		if cluster.Spec.GetCloudProvider() != kops.CloudProviderAWS {
 			allErrs = append(allErrs, field.Forbidden(field.NewPath("spec", "role"), "APIServer role only supported on AWS"))
@@ -301,20 +358,35 @@ func CrossValidateInstanceGroup(g *kops.InstanceGroup, cluster *kops.Cluster, cl
 func ValidateControlPlaneInstanceGroup(g *kops.InstanceGroup, cluster *kops.Cluster) field.ErrorList {
 	allErrs := field.ErrorList{}
 	for _, etcd := range cluster.Spec.EtcdClusters {
-		hasEtcd := false
-		for _, m := range etcd.Members {
+		var member *kops.EtcdMemberSpec
+		for i, m := range etcd.Members {
 			if fi.ValueOf(m.InstanceGroup) == g.ObjectMeta.Name {
-				hasEtcd = true
+				member = &etcd.Members[i]
 				break
 			}
 		}
-		if !hasEtcd {
+		if member == nil {
 			allErrs = append(allErrs, field.Forbidden(field.NewPath("spec", "metadata", "name"), fmt.Sprintf("InstanceGroup \"%s\" with role ControlPlane must have a member in etcd cluster \"%s\"", g.ObjectMeta.Name, etcd.Name)))
+			continue
+		}
+		if fi.ValueOf(member.EncryptedVolume) && cluster.Spec.GetCloudProvider() == kops.CloudProviderMetal && !hasEncryptedEtcdVolumesMount(g) {
+			// On bare metal the etcd volumes are directories on the host, so they are encrypted by the volume mount holding them
+			allErrs = append(allErrs, field.Required(field.NewPath("spec", "volumeMounts"), fmt.Sprintf("an encrypted volume mount on %s is required for the encrypted volume of etcd cluster %q", metal.EtcdVolumesDir, etcd.Name)))
 		}
 	}
 	return allErrs
 }
 
+// hasEncryptedEtcdVolumesMount returns true if the instance group mounts an encrypted volume where bare-metal hosts keep the etcd volumes
+func hasEncryptedEtcdVolumesMount(g *kops.InstanceGroup) bool {
+	for _, x := range g.Spec.VolumeMounts {
+		if x.Encryption != nil && path.Clean(x.Path) == metal.EtcdVolumesDir {
+			return true
+		}
+	}
+	return false
+}
+
 var validUserDataTypes = []string{
 	"text/x-include-once-url",
 	"text/x-include-url",
@@ -437,3 +509,64 @@ func validateExternalLoadBalancer(lb *kops.LoadBalancerSpec, fldPath *field.Path
 
 	return allErrs
 }
+
+func validateKernelModules(modules []kops.KernelModuleSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	names := sets.New[string]()
+	for i, module := range modules {
+		path := fldPath.Index(i)
+		if !kernelModuleNameRegex.MatchString(module.Name) {
+			allErrs = append(allErrs, field.Invalid(path.Child("name"), module.Name, "must be a kernel module name"))
+		} else if names.Has(module.Name) {
+			allErrs = append(allErrs, field.Duplicate(path.Child("name"), module.Name))
+		}
+		names.Insert(module.Name)
+
+		for j, option := range module.Options {
+			if !kernelParameterRegex.MatchString(option) {
+				allErrs = append(allErrs, field.Invalid(path.Child("options").Index(j), option, "must not be empty or contain spaces or quotes"))
+			}
+		}
+	}
+
+	return allErrs
+}
+
+func validatePrepulledImages(images []kops.PrepulledImageSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	for i, image := range images {
+		path := fldPath.Index(i)
+		if image.Image == "" && image.Source == "" {
+			allErrs = append(allErrs, field.Required(path.Child("image"), "image or source required"))
+		}
+		if strings.ContainsAny(image.Image, " \t\n") {
+			allErrs = append(allErrs, field.Invalid(path.Child("image"), image.Image, "must be an image name"))
+		}
+
+		if image.Source == "" {
+			if image.Hash != "" {
+				allErrs = append(allErrs, field.Forbidden(path.Child("hash"), "hash is only supported with source"))
+			}
+			if image.Digest != "" && !imageDigestRegex.MatchString(image.Digest) {
+				allErrs = append(allErrs, field.Invalid(path.Child("digest"), image.Digest, "must be a SHA256 digest, like \"sha256:<hex>\""))
+			}
+			continue
+		}
+
+		if image.Digest != "" {
+			allErrs = append(allErrs, field.Forbidden(path.Child("digest"), "digest is not supported with source, use hash instead"))
+		}
+		if u, err := url.Parse(image.Source); err != nil || u.Scheme == "" || u.Host == "" {
+			allErrs = append(allErrs, field.Invalid(path.Child("source"), image.Source, "must be a URL"))
+		}
+		if image.Hash == "" {
+			allErrs = append(allErrs, field.Required(path.Child("hash"), "hash required with source"))
+		} else if !sha256HashRegex.MatchString(image.Hash) {
+			allErrs = append(allErrs, field.Invalid(path.Child("hash"), image.Hash, "must be a SHA256 hash"))
+		}
+	}
+
+	return allErrs
+}
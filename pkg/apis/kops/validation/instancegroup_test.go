@@ -172,6 +172,33 @@ func TestValidMasterInstanceGroup(t *testing.T) {
 			ExpectedErrors: 1,
 			Description:    "Master IG without etcd member validated",
 		},
+		{
+			Cluster:        metalClusterWithEncryptedEtcd(),
+			IG:             metalControlPlaneInstanceGroup(nil),
+			ExpectedErrors: 1,
+			Description:    "Bare-metal IG with encrypted etcd volume but no encrypted volume mount validated",
+		},
+		{
+			Cluster: metalClusterWithEncryptedEtcd(),
+			IG: metalControlPlaneInstanceGroup(&kops.VolumeMountSpec{
+				Device:     "/dev/sdb",
+				Filesystem: "ext4",
+				Path:       "/mnt/disks",
+			}),
+			ExpectedErrors: 1,
+			Description:    "Bare-metal IG with encrypted etcd volume on a plain volume mount validated",
+		},
+		{
+			Cluster: metalClusterWithEncryptedEtcd(),
+			IG: metalControlPlaneInstanceGroup(&kops.VolumeMountSpec{
+				Device:     "/dev/sdb",
+				Filesystem: "ext4",
+				Path:       "/mnt/disks/",
+				Encryption: &kops.VolumeEncryptionSpec{KeySource: kops.VolumeEncryptionKeySourceTPM},
+			}),
+			ExpectedErrors: 0,
+			Description:    "Bare-metal IG with encrypted etcd volume on an encrypted volume mount failed to validate",
+		},
 	}
 
 	for _, g := range grid {
@@ -182,6 +209,43 @@ func TestValidMasterInstanceGroup(t *testing.T) {
 	}
 }
 
+func metalClusterWithEncryptedEtcd() *kops.Cluster {
+	return &kops.Cluster{
+		Spec: kops.ClusterSpec{
+			CloudProvider: kops.CloudProviderSpec{
+				Metal: &kops.MetalSpec{},
+			},
+			EtcdClusters: []kops.EtcdClusterSpec{
+				{
+					Name: "main",
+					Members: []kops.EtcdMemberSpec{
+						{
+							Name:            "a",
+							InstanceGroup:   fi.PtrTo("control-plane"),
+							EncryptedVolume: fi.PtrTo(true),
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func metalControlPlaneInstanceGroup(volumeMount *kops.VolumeMountSpec) *kops.InstanceGroup {
+	ig := &kops.InstanceGroup{
+		ObjectMeta: v1.ObjectMeta{
+			Name: "control-plane",
+		},
+		Spec: kops.InstanceGroupSpec{
+			Role: kops.InstanceGroupRoleControlPlane,
+		},
+	}
+	if volumeMount != nil {
+		ig.Spec.VolumeMounts = []kops.VolumeMountSpec{*volumeMount}
+	}
+	return ig
+}
+
 func TestValidBootDevice(t *testing.T) {
 	cluster := &kops.Cluster{
 		Spec: kops.ClusterSpec{
@@ -384,6 +448,264 @@ func TestIGUpdatePolicy(t *testing.T) {
 	}
 }
 
+func TestIGRollingUpdateInPlace(t *testing.T) {
+	const forbiddenError = "Forbidden::spec.rollingUpdate.inPlace"
+	for _, test := range []struct {
+		label    string
+		role     kops.InstanceGroupRole
+		inPlace  *bool
+		expected []string
+	}{
+		{
+			label:   "node",
+			role:    kops.InstanceGroupRoleNode,
+			inPlace: fi.PtrTo(true),
+		},
+		{
+			label:    "control-plane",
+			role:     kops.InstanceGroupRoleControlPlane,
+			inPlace:  fi.PtrTo(true),
+			expected: []string{forbiddenError},
+		},
+		{
+			label:    "apiserver",
+			role:     kops.InstanceGroupRoleAPIServer,
+			inPlace:  fi.PtrTo(true),
+			expected: []string{forbiddenError},
+		},
+		{
+			label:   "control-plane disabled",
+			role:    kops.InstanceGroupRoleControlPlane,
+			inPlace: fi.PtrTo(false),
+		},
+	} {
+		ig := createMinimalInstanceGroup()
+
+		t.Run(test.label, func(t *testing.T) {
+			ig.Spec.Role = test.role
+			ig.Spec.Subnets = []string{"subnet1"}
+			ig.Spec.RollingUpdate = &kops.RollingUpdate{InPlace: test.inPlace}
+			errs := ValidateInstanceGroup(ig, nil, true)
+			testErrors(t, test.label, errs, test.expected)
+		})
+	}
+}
+
+func TestIGUserDataFormat(t *testing.T) {
+	for _, test := range []struct {
+		label              string
+		format             kops.UserDataFormat
+		additionalUserData []kops.UserData
+		expected           []string
+	}{
+		{
+			label: "missing",
+		},
+		{
+			label:  "shell script",
+			format: kops.UserDataFormatShellScript,
+		},
+		{
+			label:  "ignition",
+			format: kops.UserDataFormatIgnition,
+		},
+		{
+			label:    "unknown",
+			format:   "cloud-config",
+			expected: []string{"Unsupported value::spec.userDataFormat"},
+		},
+		{
+			label:  "ignition with additional user data",
+			format: kops.UserDataFormatIgnition,
+			additionalUserData: []kops.UserData{
+				{Name: "myscript.sh", Type: "text/x-shellscript", Content: "#!/bin/sh\necho hello"},
+			},
+			expected: []string{"Forbidden::spec.additionalUserData"},
+		},
+	} {
+		ig := createMinimalInstanceGroup()
+
+		t.Run(test.label, func(t *testing.T) {
+			ig.Spec.UserDataFormat = test.format
+			ig.Spec.AdditionalUserData = test.additionalUserData
+			errs := ValidateInstanceGroup(ig, nil, true)
+			testErrors(t, test.label, errs, test.expected)
+		})
+	}
+}
+
+func TestIGKernelConfiguration(t *testing.T) {
+	for _, test := range []struct {
+		label      string
+		modules    []kops.KernelModuleSpec
+		parameters []string
+		expected   []string
+	}{
+		{
+			label: "missing",
+		},
+		{
+			label: "valid",
+			modules: []kops.KernelModuleSpec{
+				{Name: "ip_vs"},
+				{Name: "nf_conntrack", Options: []string{"hashsize=131072"}},
+			},
+			parameters: []string{"hugepages=1024", "cgroup_no_v1=all", "quiet"},
+		},
+		{
+			label:    "invalid module name",
+			modules:  []kops.KernelModuleSpec{{Name: "ip_vs; reboot"}},
+			expected: []string{"Invalid value::spec.kernelModules[0].name"},
+		},
+		{
+			label:    "duplicate module",
+			modules:  []kops.KernelModuleSpec{{Name: "ip_vs"}, {Name: "ip_vs"}},
+			expected: []string{"Duplicate value::spec.kernelModules[1].name"},
+		},
+		{
+			label:    "invalid module option",
+			modules:  []kops.KernelModuleSpec{{Name: "nf_conntrack", Options: []string{"hashsize = 131072"}}},
+			expected: []string{"Invalid value::spec.kernelModules[0].options[0]"},
+		},
+		{
+			label:      "invalid boot parameter",
+			parameters: []string{`console="ttyS0"`},
+			expected:   []string{"Invalid value::spec.kernelBootParameters[0]"},
+		},
+	} {
+		ig := createMinimalInstanceGroup()
+
+		t.Run(test.label, func(t *testing.T) {
+			ig.Spec.KernelModules = test.modules
+			ig.Spec.KernelBootParameters = test.parameters
+			errs := ValidateInstanceGroup(ig, nil, true)
+			testErrors(t, test.label, errs, test.expected)
+		})
+	}
+}
+
+func TestIGPrepulledImages(t *testing.T) {
+	hash := "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	for _, test := range []struct {
+		label    string
+		images   []kops.PrepulledImageSpec
+		expected []string
+	}{
+		{
+			label: "valid",
+			images: []kops.PrepulledImageSpec{
+				{Image: "registry.example.com/ml/trainer:v1.2"},
+				{Image: "registry.example.com/ml/model:v3", Digest: "sha256:" + hash},
+				{Source: "https://assets.example.com/images/trainer.tar.gz", Hash: hash},
+			},
+		},
+		{
+			label:    "missing image",
+			images:   []kops.PrepulledImageSpec{{}},
+			expected: []string{"Required value::spec.prepulledImages[0].image"},
+		},
+		{
+			label:    "invalid digest",
+			images:   []kops.PrepulledImageSpec{{Image: "trainer:v1.2", Digest: "sha256:abc"}},
+			expected: []string{"Invalid value::spec.prepulledImages[0].digest"},
+		},
+		{
+			label:    "hash without source",
+			images:   []kops.PrepulledImageSpec{{Image: "trainer:v1.2", Hash: hash}},
+			expected: []string{"Forbidden::spec.prepulledImages[0].hash"},
+		},
+		{
+			label:    "source without hash",
+			images:   []kops.PrepulledImageSpec{{Source: "https://assets.example.com/images/trainer.tar"}},
+			expected: []string{"Required value::spec.prepulledImages[0].hash"},
+		},
+		{
+			label:    "invalid source",
+			images:   []kops.PrepulledImageSpec{{Source: "trainer.tar", Hash: hash}},
+			expected: []string{"Invalid value::spec.prepulledImages[0].source"},
+		},
+		{
+			label:    "digest with source",
+			images:   []kops.PrepulledImageSpec{{Source: "https://assets.example.com/images/trainer.tar", Hash: hash, Digest: "sha256:" + hash}},
+			expected: []string{"Forbidden::spec.prepulledImages[0].digest"},
+		},
+	} {
+		ig := createMinimalInstanceGroup()
+
+		t.Run(test.label, func(t *testing.T) {
+			ig.Spec.PrepulledImages = test.images
+			errs := ValidateInstanceGroup(ig, nil, true)
+			testErrors(t, test.label, errs, test.expected)
+		})
+	}
+}
+
+func TestIGVolumeMountEncryption(t *testing.T) {
+	for _, test := range []struct {
+		label      string
+		role       kops.InstanceGroupRole
+		encryption *kops.VolumeEncryptionSpec
+		expected   []string
+	}{
+		{
+			label: "unencrypted",
+		},
+		{
+			label:      "kops-controller",
+			encryption: &kops.VolumeEncryptionSpec{KeySource: kops.VolumeEncryptionKeySourceKopsController},
+		},
+		{
+			label:      "tpm",
+			encryption: &kops.VolumeEncryptionSpec{KeySource: kops.VolumeEncryptionKeySourceTPM, Cipher: "aes-xts-plain64"},
+		},
+		{
+			label:      "missing key source",
+			encryption: &kops.VolumeEncryptionSpec{},
+			expected:   []string{"Required value::spec.volumeMounts[0].encryption.keySource"},
+		},
+		{
+			label:      "unsupported key source",
+			encryption: &kops.VolumeEncryptionSpec{KeySource: "Vault"},
+			expected:   []string{"Unsupported value::spec.volumeMounts[0].encryption.keySource"},
+		},
+		{
+			label:      "invalid cipher",
+			encryption: &kops.VolumeEncryptionSpec{KeySource: kops.VolumeEncryptionKeySourceTPM, Cipher: "aes xts"},
+			expected:   []string{"Invalid value::spec.volumeMounts[0].encryption.cipher"},
+		},
+		{
+			label:      "control plane with kops-controller",
+			role:       kops.InstanceGroupRoleControlPlane,
+			encryption: &kops.VolumeEncryptionSpec{KeySource: kops.VolumeEncryptionKeySourceKopsController},
+			expected:   []string{"Forbidden::spec.volumeMounts[0].encryption.keySource"},
+		},
+		{
+			label:      "control plane with tpm",
+			role:       kops.InstanceGroupRoleControlPlane,
+			encryption: &kops.VolumeEncryptionSpec{KeySource: kops.VolumeEncryptionKeySourceTPM},
+		},
+	} {
+		ig := createMinimalInstanceGroup()
+
+		t.Run(test.label, func(t *testing.T) {
+			if test.role != "" {
+				ig.Spec.Role = test.role
+				ig.Spec.Subnets = []string{"us-test-1a"}
+			}
+			ig.Spec.VolumeMounts = []kops.VolumeMountSpec{
+				{
+					Device:     "/dev/sdb",
+					Filesystem: "ext4",
+					Path:       "/var/lib/containerd",
+					Encryption: test.encryption,
+				},
+			}
+			errs := ValidateInstanceGroup(ig, nil, true)
+			testErrors(t, test.label, errs, test.expected)
+		})
+	}
+}
+
 func TestValidInstanceGroup(t *testing.T) {
 	grid := []struct {
 		IG             *kops.InstanceGroup
@@ -159,6 +159,9 @@ func ValidateCluster(c *kops.Cluster, strict bool, vfsContext *vfs.VFSContext) f
 			k8sCloudProvider = "external"
 		case kops.CloudProviderHetzner:
 			k8sCloudProvider = "external"
+		case kops.CloudProviderMetal:
+			// There is no cloud provider for kubelet to initialize bare-metal nodes with
+			k8sCloudProvider = ""
 		case kops.CloudProviderOpenstack:
 			k8sCloudProvider = "openstack"
 		case kops.CloudProviderAzure:
@@ -17,13 +17,18 @@ limitations under the License.
 package validation
 
 import (
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"encoding/pem"
 	"errors"
 	"fmt"
 	"net"
 	"net/url"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws/arn"
 	"github.com/blang/semver/v4"
@@ -38,8 +43,10 @@ import (
 	"k8s.io/kops/pkg/util/subnet"
 
 	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/featureflag"
 	"k8s.io/kops/pkg/model/components"
 	"k8s.io/kops/pkg/model/iam"
+	"k8s.io/kops/pkg/pki"
 	"k8s.io/kops/upup/pkg/fi"
 	"k8s.io/kops/upup/pkg/fi/utils"
 )
@@ -115,6 +122,19 @@ func validateClusterSpec(spec *kops.ClusterSpec, c *kops.Cluster, fieldPath *fie
 	// UpdatePolicy
 	allErrs = append(allErrs, IsValidValue(fieldPath.Child("updatePolicy"), spec.UpdatePolicy, []string{kops.UpdatePolicyAutomatic, kops.UpdatePolicyExternal})...)
 
+	// Addons
+	{
+		addonManifests := sets.NewString()
+		for i := range spec.Addons {
+			fieldAddon := fieldPath.Child("addons").Index(i)
+			allErrs = append(allErrs, validateAddonSpec(&spec.Addons[i], fieldAddon)...)
+			if addonManifests.Has(spec.Addons[i].Manifest) {
+				allErrs = append(allErrs, field.Duplicate(fieldAddon.Child("manifest"), spec.Addons[i].Manifest))
+			}
+			addonManifests.Insert(spec.Addons[i].Manifest)
+		}
+	}
+
 	// Hooks
 	for i := range spec.Hooks {
 		allErrs = append(allErrs, validateHookSpec(&spec.Hooks[i], fieldPath.Child("hooks").Index(i))...)
@@ -208,10 +228,20 @@ func validateClusterSpec(spec *kops.ClusterSpec, c *kops.Cluster, fieldPath *fie
 		allErrs = append(allErrs, field.Forbidden(fieldPath.Child("docker"), "Docker CRI support was removed in Kubernetes 1.24: https://kubernetes.io/blog/2020/12/02/dockershim-faq"))
 	}
 
+	if spec.Authentication != nil && spec.Authentication.OIDC != nil {
+		allErrs = append(allErrs, validateOIDCAuthentication(spec.Authentication.OIDC, fieldPath.Child("authentication", "oidc"))...)
+	}
+	if spec.Authentication != nil && spec.Authentication.AdminCredentialDenyList {
+		allErrs = append(allErrs, validateAdminCredentialDenyList(spec, c, fieldPath.Child("authentication", "adminCredentialDenyList"))...)
+	}
+
 	if spec.Assets != nil {
 		if spec.Assets.ContainerProxy != nil && spec.Assets.ContainerRegistry != nil {
 			allErrs = append(allErrs, field.Forbidden(fieldPath.Child("assets", "containerProxy"), "containerProxy cannot be used in conjunction with containerRegistry"))
 		}
+		if spec.Assets.SignaturePolicy != nil {
+			allErrs = append(allErrs, validateAssetsSignaturePolicy(spec.Assets.SignaturePolicy, fieldPath.Child("assets", "signaturePolicy"))...)
+		}
 	}
 
 	for i, sysctlParameter := range spec.SysctlParameters {
@@ -224,6 +254,18 @@ func validateClusterSpec(spec *kops.ClusterSpec, c *kops.Cluster, fieldPath *fie
 		allErrs = append(allErrs, validateRollingUpdate(spec.RollingUpdate, fieldPath.Child("rollingUpdate"), false)...)
 	}
 
+	if spec.NodeAudit != nil && spec.NodeAudit.Interval != nil && spec.NodeAudit.Interval.Duration < time.Minute {
+		allErrs = append(allErrs, field.Invalid(fieldPath.Child("nodeAudit", "interval"), spec.NodeAudit.Interval.Duration.String(), "must be at least 1m"))
+	}
+
+	if spec.NodeCertificateRenewal != nil && spec.NodeCertificateRenewal.RenewBefore != nil {
+		// The agent checks the certificates twice a day, and they are issued for at least 455 days
+		renewBefore := spec.NodeCertificateRenewal.RenewBefore.Duration
+		if renewBefore < 24*time.Hour || renewBefore > 365*24*time.Hour {
+			allErrs = append(allErrs, field.Invalid(fieldPath.Child("nodeCertificateRenewal", "renewBefore"), renewBefore.String(), "must be between 24h and 8760h"))
+		}
+	}
+
 	if spec.API.LoadBalancer != nil {
 		lbSpec := spec.API.LoadBalancer
 		lbPath := fieldPath.Child("api", "loadBalancer")
@@ -359,6 +401,30 @@ func validateCloudProvider(c *kops.Cluster, provider *kops.CloudProviderSpec, fi
 		constraints.requiresSubnets = false
 		constraints.requiresSubnetCIDR = false
 	}
+	if c.Spec.CloudProvider.Metal != nil {
+		if optionTaken {
+			allErrs = append(allErrs, field.Forbidden(fieldSpec.Child("metal"), "only one cloudProvider option permitted"))
+		}
+		optionTaken = true
+		if !featureflag.Metal.Enabled() {
+			allErrs = append(allErrs, field.Forbidden(fieldSpec.Child("metal"), "bare-metal support requires the Metal feature flag"))
+		}
+		if c.Spec.API.LoadBalancer != nil {
+			allErrs = append(allErrs, field.Forbidden(field.NewPath("spec", "api", "loadBalancer"), "load balancers are not supported on bare metal"))
+		}
+		if !c.UsesNoneDNS() {
+			allErrs = append(allErrs, field.Forbidden(field.NewPath("spec", "networking", "topology", "dns"), "bare-metal clusters must use DNS type None"))
+		}
+		// etcd-manager finds peers on local disks only through the host it runs on
+		for i, etcdCluster := range c.Spec.EtcdClusters {
+			if len(etcdCluster.Members) > 1 {
+				allErrs = append(allErrs, field.Forbidden(field.NewPath("spec", "etcdClusters").Index(i).Child("etcdMembers"), "bare-metal clusters support a single etcd member"))
+			}
+		}
+		constraints.requiresNetworkCIDR = false
+		constraints.requiresSubnets = false
+		constraints.requiresSubnetCIDR = false
+	}
 	if c.Spec.CloudProvider.Openstack != nil {
 		if optionTaken {
 			allErrs = append(allErrs, field.Forbidden(fieldSpec.Child("openstack"), "only one cloudProvider option permitted"))
@@ -684,6 +750,25 @@ func validateFileAssetSpec(v *kops.FileAssetSpec, fieldPath *field.Path) field.E
 	return allErrs
 }
 
+func validateAddonSpec(v *kops.AddonSpec, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if v.Manifest == "" {
+		allErrs = append(allErrs, field.Required(fieldPath.Child("manifest"), ""))
+		return allErrs
+	}
+
+	// The channels tool only accepts absolute locations, and protokube passes the channels as a comma-separated list.
+	u, err := url.Parse(v.Manifest)
+	if err != nil || !u.IsAbs() {
+		allErrs = append(allErrs, field.Invalid(fieldPath.Child("manifest"), v.Manifest, "must be an absolute location, such as s3://bucket/addons/channel.yaml"))
+	} else if strings.Contains(v.Manifest, ",") {
+		allErrs = append(allErrs, field.Invalid(fieldPath.Child("manifest"), v.Manifest, "must not contain a comma"))
+	}
+
+	return allErrs
+}
+
 func validateHookSpec(v *kops.HookSpec, fieldPath *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
 
@@ -1458,6 +1543,26 @@ func validateEtcdClusterSpec(spec kops.EtcdClusterSpec, c *kops.Cluster, fieldPa
 	for i, m := range spec.Members {
 		allErrs = append(allErrs, validateEtcdMemberSpec(m, fieldPath.Child("etcdMembers").Index(i))...)
 	}
+	if spec.Backups != nil && spec.Backups.Verification != nil {
+		allErrs = append(allErrs, validateEtcdBackupVerification(spec.Backups.Verification, fieldPath.Child("backups", "verification"))...)
+	}
+
+	return allErrs
+}
+
+func validateEtcdBackupVerification(spec *kops.EtcdBackupVerificationSpec, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if spec.Schedule != "" && !strings.HasPrefix(spec.Schedule, "@") && len(strings.Fields(spec.Schedule)) != 5 {
+		allErrs = append(allErrs, field.Invalid(fieldPath.Child("schedule"), spec.Schedule, "must be a cron schedule"))
+	}
+
+	if spec.PushgatewayURL != "" {
+		u, err := url.Parse(spec.PushgatewayURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			allErrs = append(allErrs, field.Invalid(fieldPath.Child("pushgatewayURL"), spec.PushgatewayURL, "must be an http or https URL"))
+		}
+	}
 
 	return allErrs
 }
@@ -1768,6 +1873,125 @@ func validateContainerdConfig(spec *kops.ClusterSpec, config *kops.ContainerdCon
 		allErrs = append(allErrs, validateNvidiaConfig(spec, config.NvidiaGPU, fldPath.Child("nvidia"), inClusterConfig)...)
 	}
 
+	if len(config.Registries) > 0 {
+		allErrs = append(allErrs, validateContainerdRegistries(config, fldPath.Child("registries"))...)
+	}
+
+	if config.GVisor != nil {
+		allErrs = append(allErrs, validateGVisorConfig(config, fldPath.Child("gvisor"), inClusterConfig)...)
+	}
+
+	if config.Kata != nil {
+		allErrs = append(allErrs, validateKataConfig(config, fldPath.Child("kata"), inClusterConfig)...)
+	}
+
+	return allErrs
+}
+
+var gVisorVersionRegex = regexp.MustCompile(`^[0-9]{8}\.[0-9]+$`)
+
+func validateGVisorConfig(containerd *kops.ContainerdConfig, fldPath *field.Path, inClusterConfig bool) (allErrs field.ErrorList) {
+	if !fi.ValueOf(containerd.GVisor.Enabled) {
+		return allErrs
+	}
+	allErrs = append(allErrs, validateSandboxRuntime(containerd, fldPath, inClusterConfig)...)
+	if version := fi.ValueOf(containerd.GVisor.Version); version != "" && !gVisorVersionRegex.MatchString(version) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("version"), version, "must be a gVisor release, e.g. \"20240401.0\""))
+	}
+	return allErrs
+}
+
+func validateKataConfig(containerd *kops.ContainerdConfig, fldPath *field.Path, inClusterConfig bool) (allErrs field.ErrorList) {
+	kata := containerd.Kata
+	if !fi.ValueOf(kata.Enabled) {
+		return allErrs
+	}
+	allErrs = append(allErrs, validateSandboxRuntime(containerd, fldPath, inClusterConfig)...)
+	if version := fi.ValueOf(kata.Version); version != "" {
+		if _, err := semver.ParseTolerant(version); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("version"), version, fmt.Sprintf("unable to parse version string: %v", err)))
+		}
+	}
+	if kata.Packages != nil {
+		if (kata.Packages.UrlAmd64 == nil) != (kata.Packages.HashAmd64 == nil) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("packages"), kata.Packages, "urlAmd64 and hashAmd64 must be set together"))
+		}
+		if (kata.Packages.UrlArm64 == nil) != (kata.Packages.HashArm64 == nil) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("packages"), kata.Packages, "urlArm64 and hashArm64 must be set together"))
+		}
+	}
+	return allErrs
+}
+
+// validateSandboxRuntime checks the settings that are common to all sandboxed runtimes
+func validateSandboxRuntime(containerd *kops.ContainerdConfig, fldPath *field.Path, inClusterConfig bool) (allErrs field.ErrorList) {
+	if inClusterConfig {
+		// The nodes running a sandboxed runtime are tainted, so it doesn't make sense for the whole cluster
+		allErrs = append(allErrs, field.Forbidden(fldPath, "sandboxed runtimes can only be enabled on instance groups"))
+	}
+	if containerd.SkipInstall {
+		allErrs = append(allErrs, field.Forbidden(fldPath, "sandboxed runtimes cannot be enabled when skipInstall is set"))
+	}
+	return allErrs
+}
+
+func validateContainerdRegistries(containerd *kops.ContainerdConfig, fldPath *field.Path) (allErrs field.ErrorList) {
+	hosts := sets.NewString()
+	for i, registry := range containerd.Registries {
+		fldPath := fldPath.Index(i)
+
+		if registry.Host == "" {
+			allErrs = append(allErrs, field.Required(fldPath.Child("host"), ""))
+		} else if strings.ContainsAny(registry.Host, "/ ") {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("host"), registry.Host, "must be a host name, optionally with a port"))
+		} else if hosts.Has(registry.Host) {
+			allErrs = append(allErrs, field.Duplicate(fldPath.Child("host"), registry.Host))
+		} else {
+			hosts.Insert(registry.Host)
+		}
+
+		// Registry mirrors are written to the same hosts.toml files, so the same host can't be configured twice
+		mirrorsHost := registry.Host
+		if mirrorsHost == "_default" {
+			mirrorsHost = "*"
+		}
+		if _, found := containerd.RegistryMirrors[mirrorsHost]; found {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("host"), fmt.Sprintf("registry %q is also configured in registryMirrors", registry.Host)))
+		}
+
+		if registry.Server != nil {
+			allErrs = append(allErrs, validateContainerdRegistryURL(fi.ValueOf(registry.Server), fldPath.Child("server"))...)
+		}
+		for j, mirror := range registry.Mirrors {
+			allErrs = append(allErrs, validateContainerdRegistryURL(mirror, fldPath.Child("mirrors").Index(j))...)
+		}
+
+		if registry.CA != "" {
+			if _, err := pki.ParsePEMCertificate([]byte(registry.CA)); err != nil {
+				allErrs = append(allErrs, field.Invalid(fldPath.Child("ca"), "...", fmt.Sprintf("must be a PEM encoded certificate: %v", err)))
+			}
+		}
+
+		if registry.Secret != "" {
+			for _, msg := range utilvalidation.IsDNS1123Label(registry.Secret) {
+				allErrs = append(allErrs, field.Invalid(fldPath.Child("secret"), registry.Secret, msg))
+			}
+		}
+	}
+	return allErrs
+}
+
+func validateContainerdRegistryURL(s string, fldPath *field.Path) (allErrs field.ErrorList) {
+	u, err := url.Parse(s)
+	if err != nil {
+		return append(allErrs, field.Invalid(fldPath, s, fmt.Sprintf("cannot parse URL: %v", err)))
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		allErrs = append(allErrs, field.Invalid(fldPath, s, "must be an http or https URL"))
+	}
+	if u.Host == "" {
+		allErrs = append(allErrs, field.Invalid(fldPath, s, "must include a host"))
+	}
 	return allErrs
 }
 
@@ -1804,6 +2028,78 @@ func validateNvidiaConfig(spec *kops.ClusterSpec, nvidia *kops.NvidiaGPUConfig,
 	return allErrs
 }
 
+func validateOIDCAuthentication(oidc *kops.OIDCAuthenticationSpec, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if oidc.IssuerURL != nil {
+		u, err := url.Parse(*oidc.IssuerURL)
+		if err != nil || u.Scheme != "https" || u.Host == "" {
+			allErrs = append(allErrs, field.Invalid(fieldPath.Child("issuerURL"), *oidc.IssuerURL, "must be an https URL"))
+		}
+		if fi.ValueOf(oidc.ClientID) == "" {
+			allErrs = append(allErrs, field.Required(fieldPath.Child("clientID"), "clientID must be set when issuerURL is set"))
+		}
+	}
+
+	return allErrs
+}
+
+func validateAdminCredentialDenyList(spec *kops.ClusterSpec, c *kops.Cluster, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	// The deny-list is consulted through a structured authorization configuration
+	if c.IsKubernetesLT("1.30") {
+		allErrs = append(allErrs, field.Forbidden(fieldPath, "adminCredentialDenyList requires Kubernetes 1.30 or later"))
+	}
+	if spec.Authorization == nil || spec.Authorization.RBAC == nil {
+		allErrs = append(allErrs, field.Forbidden(fieldPath, "adminCredentialDenyList requires RBAC authorization"))
+	}
+	if spec.KubeAPIServer != nil && spec.KubeAPIServer.AuthorizationWebhookConfigFile != nil {
+		allErrs = append(allErrs, field.Forbidden(fieldPath, "adminCredentialDenyList cannot be used in conjunction with kubeAPIServer.authorizationWebhookConfigFile"))
+	}
+	if spec.KubeAPIServer != nil && spec.KubeAPIServer.AuthorizationMode != nil {
+		// The authorization modes are translated to the authorizers of the structured authorization configuration
+		for _, mode := range strings.Split(*spec.KubeAPIServer.AuthorizationMode, ",") {
+			if !slices.Contains([]string{"Node", "RBAC", "AlwaysAllow", "AlwaysDeny"}, mode) {
+				allErrs = append(allErrs, field.Forbidden(fieldPath, fmt.Sprintf("adminCredentialDenyList cannot be used in conjunction with the %q authorization mode", mode)))
+			}
+		}
+	}
+
+	return allErrs
+}
+
+func validateAssetsSignaturePolicy(policy *kops.AssetsSignaturePolicy, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if !policy.Images && !policy.Files {
+		allErrs = append(allErrs, field.Required(fieldPath, "images or files must be set"))
+	}
+
+	if len(policy.PublicKeys) == 0 {
+		allErrs = append(allErrs, field.Required(fieldPath.Child("publicKeys"), "at least one public key must be set, keyless signatures are not supported"))
+	}
+	for i, publicKey := range policy.PublicKeys {
+		if block, _ := pem.Decode([]byte(publicKey)); block != nil && block.Type == "CERTIFICATE" {
+			// Keyless signatures would need the Fulcio certificate chain and the Rekor transparency log
+			allErrs = append(allErrs, field.Forbidden(fieldPath.Child("publicKeys").Index(i), "keyless signatures, verified against Fulcio certificates, are not supported; use the public key of a cosign key pair"))
+			continue
+		}
+		k, err := pki.ParsePEMPublicKey([]byte(publicKey))
+		if err != nil {
+			allErrs = append(allErrs, field.Invalid(fieldPath.Child("publicKeys").Index(i), publicKey, fmt.Sprintf("must be a PEM encoded public key: %v", err)))
+			continue
+		}
+		switch k.Key.(type) {
+		case *ecdsa.PublicKey, *rsa.PublicKey:
+		default:
+			allErrs = append(allErrs, field.Forbidden(fieldPath.Child("publicKeys").Index(i), "only ECDSA and RSA public keys are supported"))
+		}
+	}
+
+	return allErrs
+}
+
 func validateRollingUpdate(rollingUpdate *kops.RollingUpdate, fldpath *field.Path, onControlPlaneInstanceGroup bool) field.ErrorList {
 	allErrs := field.ErrorList{}
 	var err error
@@ -1889,6 +2185,19 @@ func validateExternalDNS(cluster *kops.Cluster, spec *kops.ExternalDNSConfig, fl
 		if cluster.UsesLegacyGossip() || cluster.UsesNoneDNS() {
 			allErrs = append(allErrs, field.Forbidden(fldPath.Child("provider"), "external-dns requires public or private DNS topology"))
 		}
+		if fi.ValueOf(spec.WatchGatewayAPI) {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("watchGatewayAPI"), "watchGatewayAPI is only supported by dns-controller"))
+		}
+		if fi.ValueOf(spec.TXTOwnership) {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("txtOwnership"), "external-dns always records ownership in TXT records"))
+		}
+	}
+
+	if fi.ValueOf(spec.TXTOwnership) && (cluster.UsesLegacyGossip() || cluster.UsesNoneDNS()) {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("txtOwnership"), "TXT ownership records require public or private DNS topology"))
+	}
+	if fi.ValueOf(spec.TXTAdoptExisting) && !fi.ValueOf(spec.TXTOwnership) {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("txtAdoptExisting"), "txtAdoptExisting requires txtOwnership"))
 	}
 
 	return allErrs
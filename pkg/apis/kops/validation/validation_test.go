@@ -27,6 +27,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/featureflag"
 	"k8s.io/kops/upup/pkg/fi"
 )
 
@@ -641,6 +642,311 @@ func Test_Validate_AdditionalPolicies(t *testing.T) {
 	}
 }
 
+func Test_Validate_NodeAudit(t *testing.T) {
+	grid := []struct {
+		Input          *kops.NodeAuditSpec
+		ExpectedErrors []string
+	}{
+		{
+			Input: &kops.NodeAuditSpec{Enabled: fi.PtrTo(true)},
+		},
+		{
+			Input: &kops.NodeAuditSpec{Enabled: fi.PtrTo(true), Interval: &metav1.Duration{Duration: 30 * time.Minute}},
+		},
+		{
+			Input:          &kops.NodeAuditSpec{Enabled: fi.PtrTo(true), Interval: &metav1.Duration{Duration: 10 * time.Second}},
+			ExpectedErrors: []string{"Invalid value::spec.nodeAudit.interval"},
+		},
+	}
+	for _, g := range grid {
+		clusterSpec := &kops.ClusterSpec{
+			KubernetesVersion: "1.27.0",
+			NodeAudit:         g.Input,
+			CloudProvider: kops.CloudProviderSpec{
+				AWS: &kops.AWSSpec{},
+			},
+			Networking: kops.NetworkingSpec{
+				NetworkCIDR:           "10.10.0.0/16",
+				NonMasqueradeCIDR:     "100.64.0.0/10",
+				PodCIDR:               "100.96.0.0/11",
+				ServiceClusterIPRange: "100.64.0.0/13",
+				Subnets: []kops.ClusterSubnetSpec{
+					{
+						Name: "subnet1",
+						Type: kops.SubnetTypePublic,
+						CIDR: "10.10.10.0/24",
+					},
+				},
+			},
+			EtcdClusters: []kops.EtcdClusterSpec{
+				{
+					Name: "main",
+					Members: []kops.EtcdMemberSpec{
+						{
+							Name:          "us-test-1a",
+							InstanceGroup: fi.PtrTo("master-us-test-1a"),
+						},
+					},
+				},
+			},
+		}
+		errs := validateClusterSpec(clusterSpec, &kops.Cluster{Spec: *clusterSpec}, field.NewPath("spec"), true)
+		testErrors(t, g.Input, errs, g.ExpectedErrors)
+	}
+}
+
+func Test_Validate_NodeCertificateRenewal(t *testing.T) {
+	grid := []struct {
+		Input          *kops.NodeCertificateRenewalSpec
+		ExpectedErrors []string
+	}{
+		{
+			Input: &kops.NodeCertificateRenewalSpec{Enabled: fi.PtrTo(true)},
+		},
+		{
+			Input: &kops.NodeCertificateRenewalSpec{Enabled: fi.PtrTo(true), RenewBefore: &metav1.Duration{Duration: 60 * 24 * time.Hour}},
+		},
+		{
+			Input:          &kops.NodeCertificateRenewalSpec{Enabled: fi.PtrTo(true), RenewBefore: &metav1.Duration{Duration: time.Hour}},
+			ExpectedErrors: []string{"Invalid value::spec.nodeCertificateRenewal.renewBefore"},
+		},
+		{
+			Input:          &kops.NodeCertificateRenewalSpec{Enabled: fi.PtrTo(true), RenewBefore: &metav1.Duration{Duration: 400 * 24 * time.Hour}},
+			ExpectedErrors: []string{"Invalid value::spec.nodeCertificateRenewal.renewBefore"},
+		},
+	}
+	for _, g := range grid {
+		clusterSpec := &kops.ClusterSpec{
+			KubernetesVersion:      "1.27.0",
+			NodeCertificateRenewal: g.Input,
+			CloudProvider: kops.CloudProviderSpec{
+				AWS: &kops.AWSSpec{},
+			},
+			Networking: kops.NetworkingSpec{
+				NetworkCIDR:           "10.10.0.0/16",
+				NonMasqueradeCIDR:     "100.64.0.0/10",
+				PodCIDR:               "100.96.0.0/11",
+				ServiceClusterIPRange: "100.64.0.0/13",
+				Subnets: []kops.ClusterSubnetSpec{
+					{
+						Name: "subnet1",
+						Type: kops.SubnetTypePublic,
+						CIDR: "10.10.10.0/24",
+					},
+				},
+			},
+			EtcdClusters: []kops.EtcdClusterSpec{
+				{
+					Name: "main",
+					Members: []kops.EtcdMemberSpec{
+						{
+							Name:          "us-test-1a",
+							InstanceGroup: fi.PtrTo("master-us-test-1a"),
+						},
+					},
+				},
+			},
+		}
+		errs := validateClusterSpec(clusterSpec, &kops.Cluster{Spec: *clusterSpec}, field.NewPath("spec"), true)
+		testErrors(t, g.Input, errs, g.ExpectedErrors)
+	}
+}
+
+func Test_Validate_OIDCAuthentication(t *testing.T) {
+	grid := []struct {
+		Input          *kops.OIDCAuthenticationSpec
+		ExpectedErrors []string
+	}{
+		{
+			Input: &kops.OIDCAuthenticationSpec{IssuerURL: fi.PtrTo("https://sso.example.com"), ClientID: fi.PtrTo("kubernetes")},
+		},
+		{
+			Input: &kops.OIDCAuthenticationSpec{UsernameClaim: fi.PtrTo("email")},
+		},
+		{
+			Input:          &kops.OIDCAuthenticationSpec{IssuerURL: fi.PtrTo("http://sso.example.com"), ClientID: fi.PtrTo("kubernetes")},
+			ExpectedErrors: []string{"Invalid value::spec.authentication.oidc.issuerURL"},
+		},
+		{
+			Input:          &kops.OIDCAuthenticationSpec{IssuerURL: fi.PtrTo("https://sso.example.com")},
+			ExpectedErrors: []string{"Required value::spec.authentication.oidc.clientID"},
+		},
+	}
+	for _, g := range grid {
+		errs := validateOIDCAuthentication(g.Input, field.NewPath("spec", "authentication", "oidc"))
+		testErrors(t, g.Input, errs, g.ExpectedErrors)
+	}
+}
+
+func Test_Validate_AdminCredentialDenyList(t *testing.T) {
+	grid := []struct {
+		KubernetesVersion string
+		Authorization     *kops.AuthorizationSpec
+		AuthorizationMode *string
+		ExpectedErrors    []string
+	}{
+		{
+			KubernetesVersion: "1.30.0",
+			Authorization:     &kops.AuthorizationSpec{RBAC: &kops.RBACAuthorizationSpec{}},
+		},
+		{
+			KubernetesVersion: "1.29.0",
+			Authorization:     &kops.AuthorizationSpec{RBAC: &kops.RBACAuthorizationSpec{}},
+			ExpectedErrors:    []string{"Forbidden::spec.authentication.adminCredentialDenyList"},
+		},
+		{
+			KubernetesVersion: "1.30.0",
+			Authorization:     &kops.AuthorizationSpec{AlwaysAllow: &kops.AlwaysAllowAuthorizationSpec{}},
+			ExpectedErrors:    []string{"Forbidden::spec.authentication.adminCredentialDenyList"},
+		},
+		{
+			KubernetesVersion: "1.30.0",
+			Authorization:     &kops.AuthorizationSpec{RBAC: &kops.RBACAuthorizationSpec{}},
+			AuthorizationMode: fi.PtrTo("Node,RBAC"),
+		},
+		{
+			KubernetesVersion: "1.30.0",
+			Authorization:     &kops.AuthorizationSpec{RBAC: &kops.RBACAuthorizationSpec{}},
+			AuthorizationMode: fi.PtrTo("RBAC,ABAC"),
+			ExpectedErrors:    []string{"Forbidden::spec.authentication.adminCredentialDenyList"},
+		},
+	}
+	for _, g := range grid {
+		cluster := &kops.Cluster{
+			Spec: kops.ClusterSpec{
+				KubernetesVersion: g.KubernetesVersion,
+				Authentication:    &kops.AuthenticationSpec{AdminCredentialDenyList: true},
+				Authorization:     g.Authorization,
+				KubeAPIServer:     &kops.KubeAPIServerConfig{AuthorizationMode: g.AuthorizationMode},
+			},
+		}
+		errs := validateAdminCredentialDenyList(&cluster.Spec, cluster, field.NewPath("spec", "authentication", "adminCredentialDenyList"))
+		testErrors(t, g, errs, g.ExpectedErrors)
+	}
+}
+
+func Test_Validate_EtcdBackupVerification(t *testing.T) {
+	grid := []struct {
+		Input          *kops.EtcdBackupVerificationSpec
+		ExpectedErrors []string
+	}{
+		{
+			Input: &kops.EtcdBackupVerificationSpec{},
+		},
+		{
+			Input: &kops.EtcdBackupVerificationSpec{Schedule: "0 3 * * *", PushgatewayURL: "http://pushgateway.monitoring:9091"},
+		},
+		{
+			Input: &kops.EtcdBackupVerificationSpec{Schedule: "@weekly"},
+		},
+		{
+			Input:          &kops.EtcdBackupVerificationSpec{Schedule: "daily"},
+			ExpectedErrors: []string{"Invalid value::spec.etcdClusters[0].backups.verification.schedule"},
+		},
+		{
+			Input:          &kops.EtcdBackupVerificationSpec{PushgatewayURL: "pushgateway:9091"},
+			ExpectedErrors: []string{"Invalid value::spec.etcdClusters[0].backups.verification.pushgatewayURL"},
+		},
+	}
+	for _, g := range grid {
+		errs := validateEtcdBackupVerification(g.Input, field.NewPath("spec", "etcdClusters").Index(0).Child("backups", "verification"))
+		testErrors(t, g.Input, errs, g.ExpectedErrors)
+	}
+}
+
+func Test_Validate_AssetsSignaturePolicy(t *testing.T) {
+	ecdsaPublicKey := "-----BEGIN PUBLIC KEY-----\nMFkwEwYHKoZIzj0CAQYIKoZIzj0DAQcDQgAEQJIhsvgXs3v2sYfhP21PR8ItLQMe\nUc+gw9O3sY8xIB3AxfaPJM51b20TvG2dPImrqAp8WL8kj3G45YkR1cEZZA==\n-----END PUBLIC KEY-----\n"
+	ed25519PublicKey := "-----BEGIN PUBLIC KEY-----\nMCowBQYDK2VwAyEAmEj2ufxUvnfHaVdgG1MR52uiyMllQq3vl7hHPPuvydQ=\n-----END PUBLIC KEY-----\n"
+	fulcioCertificate := "-----BEGIN CERTIFICATE-----\nMIIB\n-----END CERTIFICATE-----\n"
+
+	grid := []struct {
+		Input          *kops.AssetsSignaturePolicy
+		ExpectedErrors []string
+	}{
+		{
+			Input: &kops.AssetsSignaturePolicy{PublicKeys: []string{ecdsaPublicKey}, Images: true, Files: true},
+		},
+		{
+			Input:          &kops.AssetsSignaturePolicy{PublicKeys: []string{ecdsaPublicKey}},
+			ExpectedErrors: []string{"Required value::spec.assets.signaturePolicy"},
+		},
+		{
+			Input:          &kops.AssetsSignaturePolicy{Images: true},
+			ExpectedErrors: []string{"Required value::spec.assets.signaturePolicy.publicKeys"},
+		},
+		{
+			Input:          &kops.AssetsSignaturePolicy{PublicKeys: []string{"not a key"}, Files: true},
+			ExpectedErrors: []string{"Invalid value::spec.assets.signaturePolicy.publicKeys[0]"},
+		},
+		{
+			Input:          &kops.AssetsSignaturePolicy{PublicKeys: []string{ecdsaPublicKey, ed25519PublicKey}, Files: true},
+			ExpectedErrors: []string{"Forbidden::spec.assets.signaturePolicy.publicKeys[1]"},
+		},
+		{
+			Input:          &kops.AssetsSignaturePolicy{PublicKeys: []string{fulcioCertificate}, Images: true},
+			ExpectedErrors: []string{"Forbidden::spec.assets.signaturePolicy.publicKeys[0]"},
+		},
+	}
+	for _, g := range grid {
+		errs := validateAssetsSignaturePolicy(g.Input, field.NewPath("spec", "assets", "signaturePolicy"))
+		testErrors(t, g.Input, errs, g.ExpectedErrors)
+	}
+}
+
+func Test_Validate_Metal(t *testing.T) {
+	featureflag.ParseFlags("+Metal")
+	defer featureflag.ParseFlags("-Metal")
+
+	grid := []struct {
+		Description    string
+		Input          kops.ClusterSpec
+		ExpectedErrors []string
+	}{
+		{
+			Description: "single control-plane host",
+			Input: kops.ClusterSpec{
+				Networking: kops.NetworkingSpec{Topology: &kops.TopologySpec{DNS: kops.DNSTypeNone}},
+				EtcdClusters: []kops.EtcdClusterSpec{
+					{Name: "main", Members: []kops.EtcdMemberSpec{{Name: "control-plane"}}},
+				},
+			},
+		},
+		{
+			Description: "load balancer",
+			Input: kops.ClusterSpec{
+				API:        kops.APISpec{LoadBalancer: &kops.LoadBalancerAccessSpec{}},
+				Networking: kops.NetworkingSpec{Topology: &kops.TopologySpec{DNS: kops.DNSTypeNone}},
+			},
+			ExpectedErrors: []string{"Forbidden::spec.api.loadBalancer"},
+		},
+		{
+			Description: "public DNS",
+			Input: kops.ClusterSpec{
+				Networking: kops.NetworkingSpec{Topology: &kops.TopologySpec{DNS: kops.DNSTypePublic}},
+			},
+			ExpectedErrors: []string{"Forbidden::spec.networking.topology.dns"},
+		},
+		{
+			Description: "highly-available etcd",
+			Input: kops.ClusterSpec{
+				Networking: kops.NetworkingSpec{Topology: &kops.TopologySpec{DNS: kops.DNSTypeNone}},
+				EtcdClusters: []kops.EtcdClusterSpec{
+					{Name: "main", Members: []kops.EtcdMemberSpec{{Name: "a"}, {Name: "b"}, {Name: "c"}}},
+				},
+			},
+			ExpectedErrors: []string{"Forbidden::spec.etcdClusters[0].etcdMembers"},
+		},
+	}
+	for _, g := range grid {
+		t.Run(g.Description, func(t *testing.T) {
+			cluster := &kops.Cluster{Spec: g.Input}
+			cluster.Spec.CloudProvider.Metal = &kops.MetalSpec{}
+			errs, _ := validateCloudProvider(cluster, &cluster.Spec.CloudProvider, field.NewPath("spec", "cloudProvider"))
+			testErrors(t, g.Description, errs, g.ExpectedErrors)
+		})
+	}
+}
+
 type caliInput struct {
 	Cluster *kops.ClusterSpec
 	Calico  *kops.CalicoNetworkingSpec
@@ -1407,6 +1713,45 @@ func Test_Validate_NodeLocalDNS(t *testing.T) {
 	}
 }
 
+func Test_Validate_Addons(t *testing.T) {
+	grid := []struct {
+		Input          kops.AddonSpec
+		ExpectedErrors []string
+	}{
+		{
+			Input: kops.AddonSpec{
+				Manifest: "s3://my-kops-addons/addon.yaml",
+			},
+		},
+		{
+			Input: kops.AddonSpec{
+				Manifest: "https://example.com/addons/addon.yaml",
+			},
+		},
+		{
+			Input:          kops.AddonSpec{},
+			ExpectedErrors: []string{"Required value::spec.addons[0].manifest"},
+		},
+		{
+			Input: kops.AddonSpec{
+				Manifest: "my-addon/addon.yaml",
+			},
+			ExpectedErrors: []string{"Invalid value::spec.addons[0].manifest"},
+		},
+		{
+			Input: kops.AddonSpec{
+				Manifest: "s3://my-kops-addons/a,b.yaml",
+			},
+			ExpectedErrors: []string{"Invalid value::spec.addons[0].manifest"},
+		},
+	}
+
+	for _, g := range grid {
+		errs := validateAddonSpec(&g.Input, field.NewPath("spec", "addons").Index(0))
+		testErrors(t, g.Input, errs, g.ExpectedErrors)
+	}
+}
+
 func Test_Validate_CloudConfiguration(t *testing.T) {
 	grid := []struct {
 		Description    string
@@ -1772,3 +2117,171 @@ func Test_Validate_NriConfig(t *testing.T) {
 		testErrors(t, g.Input.Containerd, errs, g.ExpectedErrors)
 	}
 }
+
+func Test_Validate_ContainerdRegistries(t *testing.T) {
+	grid := []struct {
+		Input          kops.ContainerdConfig
+		ExpectedErrors []string
+	}{
+		{
+			Input: kops.ContainerdConfig{
+				Registries: []kops.ContainerdRegistryConfig{
+					{
+						Host:    "registry.example.com:5000",
+						Server:  fi.PtrTo("https://registry.example.com:5000"),
+						Mirrors: []string{"https://mirror.example.com", "http://10.0.0.1:5000"},
+						Secret:  "harbor",
+					},
+					{
+						Host:    "_default",
+						Mirrors: []string{"https://mirror.example.com"},
+					},
+				},
+				RegistryMirrors: map[string][]string{
+					"docker.io": {"https://mirror.example.com"},
+				},
+			},
+		},
+		{
+			Input: kops.ContainerdConfig{
+				Registries: []kops.ContainerdRegistryConfig{
+					{},
+					{Host: "https://registry.example.com"},
+				},
+			},
+			ExpectedErrors: []string{
+				"Required value::containerd.registries[0].host",
+				"Invalid value::containerd.registries[1].host",
+			},
+		},
+		{
+			Input: kops.ContainerdConfig{
+				Registries: []kops.ContainerdRegistryConfig{
+					{Host: "registry.example.com"},
+					{Host: "registry.example.com"},
+				},
+			},
+			ExpectedErrors: []string{"Duplicate value::containerd.registries[1].host"},
+		},
+		{
+			Input: kops.ContainerdConfig{
+				Registries: []kops.ContainerdRegistryConfig{
+					{Host: "docker.io"},
+					{Host: "_default"},
+				},
+				RegistryMirrors: map[string][]string{
+					"docker.io": {"https://mirror.example.com"},
+					"*":         {"https://mirror.example.com"},
+				},
+			},
+			ExpectedErrors: []string{
+				"Forbidden::containerd.registries[0].host",
+				"Forbidden::containerd.registries[1].host",
+			},
+		},
+		{
+			Input: kops.ContainerdConfig{
+				Registries: []kops.ContainerdRegistryConfig{
+					{
+						Host:    "registry.example.com",
+						Server:  fi.PtrTo("registry.example.com"),
+						Mirrors: []string{"ftp://mirror.example.com"},
+						CA:      "not a certificate",
+						Secret:  "Not_A_Name",
+					},
+				},
+			},
+			ExpectedErrors: []string{
+				"Invalid value::containerd.registries[0].server",
+				"Invalid value::containerd.registries[0].mirrors[0]",
+				"Invalid value::containerd.registries[0].ca",
+				"Invalid value::containerd.registries[0].secret",
+			},
+		},
+	}
+	for _, g := range grid {
+		errs := validateContainerdRegistries(&g.Input, field.NewPath("containerd", "registries"))
+		testErrors(t, g.Input, errs, g.ExpectedErrors)
+	}
+}
+
+func Test_Validate_ContainerdSandboxRuntimes(t *testing.T) {
+	grid := []struct {
+		Input           kops.ContainerdConfig
+		InClusterConfig bool
+		ExpectedErrors  []string
+	}{
+		{
+			Input: kops.ContainerdConfig{
+				GVisor: &kops.GVisorConfig{
+					Enabled: fi.PtrTo(true),
+					Version: fi.PtrTo("20240401.0"),
+				},
+				Kata: &kops.KataConfig{
+					Enabled: fi.PtrTo(true),
+					Version: fi.PtrTo("3.5.0"),
+					Packages: &kops.PackagesConfig{
+						UrlAmd64:  fi.PtrTo("https://example.com/kata-static-3.5.0-amd64.tar.xz"),
+						HashAmd64: fi.PtrTo("5994471abb01112afcc18159f6cc74b4f511b99806da59b3caf5a9c173cacfc5"),
+					},
+				},
+			},
+		},
+		{
+			Input: kops.ContainerdConfig{
+				GVisor: &kops.GVisorConfig{
+					Enabled: fi.PtrTo(false),
+				},
+			},
+			InClusterConfig: true,
+		},
+		{
+			Input: kops.ContainerdConfig{
+				GVisor: &kops.GVisorConfig{
+					Enabled: fi.PtrTo(true),
+				},
+				Kata: &kops.KataConfig{
+					Enabled: fi.PtrTo(true),
+				},
+			},
+			InClusterConfig: true,
+			ExpectedErrors: []string{
+				"Forbidden::containerd.gvisor",
+				"Forbidden::containerd.kata",
+			},
+		},
+		{
+			Input: kops.ContainerdConfig{
+				GVisor: &kops.GVisorConfig{
+					Enabled: fi.PtrTo(true),
+					Version: fi.PtrTo("v1.0.0"),
+				},
+				Kata: &kops.KataConfig{
+					Enabled: fi.PtrTo(true),
+					Version: fi.PtrTo("latest"),
+					Packages: &kops.PackagesConfig{
+						UrlArm64: fi.PtrTo("https://example.com/kata-static-3.5.0-arm64.tar.xz"),
+					},
+				},
+				SkipInstall: true,
+			},
+			ExpectedErrors: []string{
+				"Forbidden::containerd.gvisor",
+				"Invalid value::containerd.gvisor.version",
+				"Forbidden::containerd.kata",
+				"Invalid value::containerd.kata.version",
+				"Invalid value::containerd.kata.packages",
+			},
+		},
+	}
+	for _, g := range grid {
+		var errs field.ErrorList
+		if g.Input.GVisor != nil {
+			errs = append(errs, validateGVisorConfig(&g.Input, field.NewPath("containerd", "gvisor"), g.InClusterConfig)...)
+		}
+		if g.Input.Kata != nil {
+			errs = append(errs, validateKataConfig(&g.Input, field.NewPath("containerd", "kata"), g.InClusterConfig)...)
+		}
+		testErrors(t, g.Input, errs, g.ExpectedErrors)
+	}
+}
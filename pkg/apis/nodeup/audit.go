@@ -0,0 +1,72 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeup
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// NodeConfigurationDriftCondition is the type of the Node condition set when the node configuration has drifted.
+	NodeConfigurationDriftCondition = "KopsConfigurationDrift"
+	// NodeAuditReportPath is where the audit report is written on the node.
+	NodeAuditReportPath = "/var/log/kops-node-audit.json"
+)
+
+// NodeAuditKind is the kind of item that differs from the node configuration.
+type NodeAuditKind string
+
+const (
+	NodeAuditKindFile    NodeAuditKind = "File"
+	NodeAuditKindPackage NodeAuditKind = "Package"
+	NodeAuditKindService NodeAuditKind = "Service"
+	NodeAuditKindSysctl  NodeAuditKind = "Sysctl"
+)
+
+// NodeAuditReport is the result of comparing a node with the configuration nodeup would render.
+type NodeAuditReport struct {
+	// NodeName is the name of the audited node.
+	NodeName string `json:"nodeName,omitempty"`
+	// InstanceGroup is the instance group of the node.
+	InstanceGroup string `json:"instanceGroup,omitempty"`
+	// Time is when the audit ran.
+	Time metav1.Time `json:"time"`
+	// Drift is true if any item differs.
+	Drift bool `json:"drift"`
+	// Differences are the items that differ.
+	Differences []NodeAuditDifference `json:"differences,omitempty"`
+}
+
+// NodeAuditDifference is an item that differs from the node configuration.
+type NodeAuditDifference struct {
+	// Kind is the kind of item.
+	Kind NodeAuditKind `json:"kind"`
+	// Name identifies the item: the path of a file, or the name of a package, service or sysctl.
+	Name string `json:"name"`
+	// Missing is true if the item doesn't exist on the node.
+	Missing bool `json:"missing,omitempty"`
+	// Fields are the fields that differ, for an existing item.
+	Fields []NodeAuditField `json:"fields,omitempty"`
+}
+
+// NodeAuditField is a field of an item that differs from the node configuration.
+type NodeAuditField struct {
+	// Field is the name of the field.
+	Field string `json:"field"`
+	// Description describes the difference, as the actual and the expected values or a diff.
+	Description string `json:"description,omitempty"`
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeup
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CertificateRenewalConfigPath is where the configuration of the certificate renewal agent is written on the node.
+const CertificateRenewalConfigPath = "/etc/kubernetes/kops/certificate-renewal.yaml"
+
+// CertificateRenewalRequest is a request from nodeup to kops-controller for renewing the certificates of a node.
+// It is authenticated with the current kubelet client certificate of the node.
+type CertificateRenewalRequest struct {
+	// APIVersion defines the versioned schema of this representation of a request.
+	APIVersion string `json:"apiVersion"`
+	// Certs are the requested certificates and their respective public keys.
+	Certs map[string]string `json:"certs"`
+	// CurrentCerts are the current certificates being renewed whose names are kept, keyed by name.
+	// kops-controller issues the renewed kubelet-server certificate with the names of the current one.
+	CurrentCerts map[string]string `json:"currentCerts,omitempty"`
+}
+
+// CertificateRenewalResponse is a response to a CertificateRenewalRequest.
+type CertificateRenewalResponse struct {
+	// Certs are the issued certificates.
+	Certs map[string]string `json:"certs,omitempty"`
+}
+
+// CertificateRenewalConfig configures the agent that renews the certificates of a node before they expire.
+type CertificateRenewalConfig struct {
+	// ClusterName is the name of the cluster.
+	ClusterName string `json:"clusterName"`
+	// RenewBefore is how long before their expiration the certificates are renewed.
+	RenewBefore metav1.Duration `json:"renewBefore"`
+	// Certificates are the certificates of the node issued by kops-controller.
+	Certificates []RenewedCertificate `json:"certificates,omitempty"`
+	// Crictl is the path of crictl, for stopping the containers of static pods.
+	Crictl string `json:"crictl,omitempty"`
+}
+
+// RenewedCertificate is a certificate of the node renewed by the certificate renewal agent.
+type RenewedCertificate struct {
+	// Name is the name of the certificate in the requests to kops-controller.
+	Name string `json:"name"`
+	// Kubeconfig is the path of the kubeconfig embedding the certificate and its key.
+	Kubeconfig string `json:"kubeconfig,omitempty"`
+	// CertPath and KeyPath are the paths of the certificate and its key, for certificates outside a kubeconfig.
+	CertPath string `json:"certPath,omitempty"`
+	KeyPath  string `json:"keyPath,omitempty"`
+	// Services are the systemd services restarted once the certificate is renewed.
+	Services []string `json:"services,omitempty"`
+	// Containers are the names of the containers of static pods stopped once the certificate is renewed,
+	// for the kubelet to restart them.
+	Containers []string `json:"containers,omitempty"`
+}
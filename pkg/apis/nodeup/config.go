@@ -18,8 +18,10 @@ package nodeup
 
 import (
 	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/kops/pkg/apis/kops"
 	"k8s.io/kops/pkg/apis/kops/model"
 	"k8s.io/kops/util/pkg/architectures"
@@ -31,8 +33,12 @@ type Config struct {
 	// Assets are locations where we can find files to be installed
 	// TODO: Remove once everything is in containers?
 	Assets map[architectures.Architecture][]string `json:",omitempty"`
+	// AssetSignaturePublicKeys are the public keys trusted to sign the assets, if their signatures are verified.
+	AssetSignaturePublicKeys []string `json:"assetSignaturePublicKeys,omitempty"`
 	// Images are a list of images we should preload
 	Images map[architectures.Architecture][]*Image `json:"images,omitempty"`
+	// PrepulledImages are the container images of the instance group to pull or load before the kubelet starts
+	PrepulledImages []*Image `json:"prepulledImages,omitempty"`
 	// ClusterName is the name of the cluster
 	ClusterName string `json:",omitempty"`
 	// Channels is a list of channels that we should apply
@@ -79,8 +85,18 @@ type Config struct {
 	ServiceNodePortRange string `json:",omitempty"`
 	// SysctlParameters will configure kernel parameters using sysctl(8).
 	SysctlParameters []string `json:",omitempty"`
+	// KernelModules are loaded on boot, with their options.
+	KernelModules []kops.KernelModuleSpec `json:",omitempty"`
+	// KernelBootParameters are added to the kernel command line.
+	KernelBootParameters []string `json:",omitempty"`
 	// UpdatePolicy determines the policy for applying upgrades automatically.
 	UpdatePolicy string
+	// InPlaceUpdates is true if changes to this configuration are applied on the running node.
+	InPlaceUpdates bool `json:",omitempty"`
+	// NodeAuditInterval is the time between two audits of the node configuration, if the periodic audit is enabled.
+	NodeAuditInterval *metav1.Duration `json:",omitempty"`
+	// CertificateRenewBefore is how long before their expiration the certificates issued by kops-controller are renewed, if their renewal is enabled.
+	CertificateRenewBefore *metav1.Duration `json:",omitempty"`
 	// VolumeMounts are a collection of volume mounts.
 	VolumeMounts []kops.VolumeMountSpec `json:",omitempty"`
 
@@ -97,6 +113,8 @@ type Config struct {
 	ControlPlaneConfig *ControlPlaneConfig `json:",omitempty"`
 	// GossipConfig is configuration for gossip DNS.
 	GossipConfig *kops.GossipConfig `json:",omitempty"`
+	// GossipKeyset holds the secrets securing gossip DNS, overriding the secrets in GossipConfig.
+	GossipKeyset *GossipKeyset `json:",omitempty"`
 	// DNSZone is the DNS zone we should use when configuring DNS.
 	DNSZone string `json:",omitempty"`
 	// NvidiaGPU contains the configuration for nvidia
@@ -159,6 +177,9 @@ type BootConfig struct {
 	InstanceGroupRole kops.InstanceGroupRole
 	// NodeupConfigHash holds a secure hash of the nodeup.Config.
 	NodeupConfigHash string
+	// KernelConfigHash holds a hash of the kernel configuration, when changes to the nodeup.Config are applied in place.
+	// Changes to the kernel configuration need a reboot, so they replace the instances instead.
+	KernelConfigHash string `json:",omitempty"`
 }
 
 type ConfigServerOptions struct {
@@ -236,6 +257,10 @@ func NewConfig(cluster *kops.Cluster, instanceGroup *kops.InstanceGroup) (*Confi
 		UsesNoneDNS:          cluster.UsesNoneDNS(),
 	}
 
+	if cluster.Spec.Assets != nil && cluster.Spec.Assets.SignaturePolicy != nil && cluster.Spec.Assets.SignaturePolicy.Files {
+		config.AssetSignaturePublicKeys = cluster.Spec.Assets.SignaturePolicy.PublicKeys
+	}
+
 	bootConfig := BootConfig{
 		CloudProvider:     cluster.Spec.GetCloudProvider(),
 		ClusterName:       cluster.ObjectMeta.Name,
@@ -297,6 +322,23 @@ func NewConfig(cluster *kops.Cluster, instanceGroup *kops.InstanceGroup) (*Confi
 		config.UpdatePolicy = kops.UpdatePolicyAutomatic
 	}
 
+	config.InPlaceUpdates = instanceGroup.UsesInPlaceUpdates(cluster)
+
+	if cluster.Spec.NodeAudit != nil && cluster.Spec.NodeAudit.Enabled != nil && *cluster.Spec.NodeAudit.Enabled {
+		config.NodeAuditInterval = cluster.Spec.NodeAudit.Interval
+		if config.NodeAuditInterval == nil {
+			config.NodeAuditInterval = &metav1.Duration{Duration: time.Hour}
+		}
+	}
+
+	// Only nodes without an API server get their certificates from kops-controller
+	if cluster.Spec.NodeCertificateRenewal != nil && cluster.Spec.NodeCertificateRenewal.Enabled != nil && *cluster.Spec.NodeCertificateRenewal.Enabled && !instanceGroup.HasAPIServer() {
+		config.CertificateRenewBefore = cluster.Spec.NodeCertificateRenewal.RenewBefore
+		if config.CertificateRenewBefore == nil {
+			config.CertificateRenewBefore = &metav1.Duration{Duration: 30 * 24 * time.Hour}
+		}
+	}
+
 	if cluster.Spec.Networking.AmazonVPC != nil {
 		config.Networking.AmazonVPC = &kops.AmazonVPCNetworkingSpec{}
 		config.DefaultMachineType = aws.String(strings.Split(instanceGroup.Spec.MachineType, ",")[0])
@@ -389,6 +431,9 @@ func NewConfig(cluster *kops.Cluster, instanceGroup *kops.InstanceGroup) (*Confi
 		config.SysctlParameters = append(config.SysctlParameters, instanceGroup.Spec.SysctlParameters...)
 	}
 
+	config.KernelModules = instanceGroup.Spec.KernelModules
+	config.KernelBootParameters = instanceGroup.Spec.KernelBootParameters
+
 	if len(cluster.Spec.SysctlParameters) > 0 {
 		config.SysctlParameters = append(config.SysctlParameters,
 			"# Custom sysctl parameters from cluster spec",
@@ -0,0 +1,60 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeup
+
+import "k8s.io/kops/pkg/apis/kops"
+
+// ContainerdRegistrySecretPrefix is the prefix of the ids of the secrets holding containerd registry credentials.
+const ContainerdRegistrySecretPrefix = "containerdregistry-"
+
+// ContainerdRegistrySecret is the contents of a secret holding the credentials and client certificate for a registry.
+type ContainerdRegistrySecret struct {
+	// Username is the user name used to authenticate to the registry.
+	Username string `json:"username,omitempty"`
+	// Password is the password used to authenticate to the registry.
+	Password string `json:"password,omitempty"`
+	// ClientCertificate is the PEM encoded client certificate presented to the registry.
+	ClientCertificate string `json:"clientCertificate,omitempty"`
+	// ClientKey is the PEM encoded private key of the client certificate.
+	ClientKey string `json:"clientKey,omitempty"`
+}
+
+// ContainerdRegistrySecretID returns the id of the secret with the specified name, as referenced by ContainerdRegistryConfig.
+func ContainerdRegistrySecretID(name string) string {
+	return ContainerdRegistrySecretPrefix + name
+}
+
+// ContainerdRegistrySecretIDs returns the ids of the secrets referenced by the containerd registries configuration.
+func (c *Config) ContainerdRegistrySecretIDs() []string {
+	if c.ContainerdConfig == nil {
+		return nil
+	}
+	return containerdRegistrySecretIDs(c.ContainerdConfig.Registries)
+}
+
+func containerdRegistrySecretIDs(registries []kops.ContainerdRegistryConfig) []string {
+	var ids []string
+	seen := make(map[string]bool)
+	for _, registry := range registries {
+		if registry.Secret == "" || seen[registry.Secret] {
+			continue
+		}
+		seen[registry.Secret] = true
+		ids = append(ids, ContainerdRegistrySecretID(registry.Secret))
+	}
+	return ids
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeup
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"k8s.io/kops/pkg/apis/kops"
+)
+
+// GossipSecretName is the name of the secret holding the GossipKeyset.
+const GossipSecretName = "gossip"
+
+// GossipKeyset holds the secrets that secure gossip DNS.
+// It allows the secret to be rotated without partitioning the gossip mesh:
+// a new secret is first accepted as a secondary, then promoted, and the old one finally removed.
+type GossipKeyset struct {
+	// Primary is the secret used to secure outgoing gossip.
+	// An empty primary secret means gossip is not secured.
+	Primary string `json:"primary"`
+	// Secondary are additional secrets accepted on incoming gossip, oldest first.
+	Secondary []string `json:"secondary,omitempty"`
+}
+
+// CheckGossipKeysetProtocols returns an error if the cluster gossips over mesh, which can't be secured with a GossipKeyset.
+// mesh authenticates peers with a single password, so instances holding different secrets during a rotation would partition it.
+func CheckGossipKeysetProtocols(cluster *kops.Cluster) error {
+	// protokube and dns-controller gossip over mesh unless configured otherwise
+	gossipConfig := cluster.Spec.GossipConfig
+	if gossipConfig == nil || !isProtocol(gossipConfig.Protocol, "memberlist") || (gossipConfig.Secondary != nil && isProtocol(gossipConfig.Secondary.Protocol, "mesh")) {
+		return fmt.Errorf("the gossip secret can only be rotated when spec.gossipConfig only uses the memberlist protocol")
+	}
+	dnsControllerGossipConfig := cluster.Spec.DNSControllerGossipConfig
+	if dnsControllerGossipConfig == nil || !isProtocol(dnsControllerGossipConfig.Protocol, "memberlist") || (dnsControllerGossipConfig.Secondary != nil && isProtocol(dnsControllerGossipConfig.Secondary.Protocol, "mesh")) {
+		return fmt.Errorf("the gossip secret can only be rotated when spec.dnsControllerGossipConfig only uses the memberlist protocol")
+	}
+	return nil
+}
+
+func isProtocol(protocol *string, name string) bool {
+	return protocol != nil && *protocol == name
+}
+
+// ParseGossipKeyset parses the data of the gossip secret.
+func ParseGossipKeyset(data []byte) (*GossipKeyset, error) {
+	keyset := &GossipKeyset{}
+	if err := json.Unmarshal(data, keyset); err != nil {
+		return nil, fmt.Errorf("parsing gossip keyset: %w", err)
+	}
+	return keyset, nil
+}
+
+// Marshal returns the data to store in the gossip secret.
+func (k *GossipKeyset) Marshal() ([]byte, error) {
+	return json.Marshal(k)
+}
+
+// AddSecondary adds a secret that is accepted on incoming gossip, but not yet used.
+func (k *GossipKeyset) AddSecondary(secret string) {
+	k.Secondary = append(k.Secondary, secret)
+}
+
+// Promote makes the most recently added secondary secret the primary one.
+// The previous primary secret stays accepted as a secondary.
+func (k *GossipKeyset) Promote() error {
+	if len(k.Secondary) == 0 {
+		return fmt.Errorf("no secondary gossip secret to promote")
+	}
+	last := len(k.Secondary) - 1
+	previous := k.Primary
+	k.Primary = k.Secondary[last]
+	k.Secondary = append(k.Secondary[:last], previous)
+	return nil
+}
+
+// Distrust removes all secondary secrets, returning how many were removed.
+func (k *GossipKeyset) Distrust() int {
+	n := len(k.Secondary)
+	k.Secondary = nil
+	return n
+}
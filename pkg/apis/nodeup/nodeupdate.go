@@ -0,0 +1,87 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeup
+
+// NodeupConfigHashAnnotation is the annotation of the Node object with the hash of the configuration applied in place.
+const NodeupConfigHashAnnotation = "kops.k8s.io/nodeup-config-hash"
+
+// NodeUpdateConditionType is the type of the Node condition with the result of the last in-place update.
+// The condition is True once the last update succeeded, and False while the node is drained or after an update failed.
+const NodeUpdateConditionType = "KopsConfigurationUpdated"
+
+const (
+	// NodeUpdateReasonDraining is the reason of the Node condition while the node is drained for an in-place update.
+	NodeUpdateReasonDraining = "Draining"
+	// NodeUpdateReasonSucceeded is the reason of the Node condition when the last in-place update succeeded.
+	NodeUpdateReasonSucceeded = "Succeeded"
+	// NodeUpdateReasonFailed is the reason of the Node condition when the last in-place update failed.
+	NodeUpdateReasonFailed = "Failed"
+)
+
+// NodeUpdateAction is an action requested by a node that updates its configuration in place.
+type NodeUpdateAction string
+
+const (
+	// NodeUpdateActionGetConfig returns the hash of the current configuration of the node.
+	NodeUpdateActionGetConfig NodeUpdateAction = "GetConfig"
+	// NodeUpdateActionDrain cordons the node and evicts its pods.
+	NodeUpdateActionDrain NodeUpdateAction = "Drain"
+	// NodeUpdateActionReport records the result of the update in a condition of the Node object.
+	NodeUpdateActionReport NodeUpdateAction = "Report"
+)
+
+// NodeUpdateRequest is a request from nodeup to kops-controller for updating the configuration of a running node.
+type NodeUpdateRequest struct {
+	// APIVersion defines the versioned schema of this representation of a request.
+	APIVersion string `json:"apiVersion"`
+	// Action is the requested action.
+	Action NodeUpdateAction `json:"action"`
+
+	// IncludeNodeConfig controls whether the instance group configuration should be returned.
+	// This allows for nodes without access to the kops state store.
+	IncludeNodeConfig bool `json:"includeNodeConfig,omitempty"`
+
+	// Status is the result of the update, for the Report action.
+	Status *NodeUpdateStatus `json:"status,omitempty"`
+
+	// Challenge is for a callback challenge.
+	Challenge *ChallengeRequest `json:"challenge,omitempty"`
+}
+
+// NodeUpdateStatus is the result of an in-place update of a node.
+type NodeUpdateStatus struct {
+	// NodeupConfigHash is the hash of the configuration that was applied.
+	NodeupConfigHash string `json:"nodeupConfigHash,omitempty"`
+	// Succeeded is true if the configuration was applied.
+	Succeeded bool `json:"succeeded,omitempty"`
+	// Message describes the update, or why it failed.
+	Message string `json:"message,omitempty"`
+	// Uncordon is true if the node was drained for the update and should be schedulable again.
+	Uncordon bool `json:"uncordon,omitempty"`
+}
+
+// NodeUpdateResponse is a response to a NodeUpdateRequest.
+type NodeUpdateResponse struct {
+	// NodeupConfigHash is the hash of the current nodeup.Config of the node's instance group.
+	NodeupConfigHash string `json:"nodeupConfigHash,omitempty"`
+
+	// NodeConfig contains the node configuration, if IncludeNodeConfig is set.
+	NodeConfig *NodeConfig `json:"nodeConfig,omitempty"`
+
+	// Drained is true once the node is cordoned and has no pods left to evict, for the Drain action.
+	Drained bool `json:"drained,omitempty"`
+}
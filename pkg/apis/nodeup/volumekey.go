@@ -0,0 +1,37 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeup
+
+// VolumeEncryptionSecretName is the name of the secret from which kops-controller derives the keys of encrypted volumes.
+const VolumeEncryptionSecretName = "volume-encryption"
+
+// VolumeKeyRequest is a request from nodeup to kops-controller for the key of an encrypted volume.
+type VolumeKeyRequest struct {
+	// APIVersion defines the versioned schema of this representation of a request.
+	APIVersion string `json:"apiVersion"`
+	// Path is the mount path of the volume, which identifies the volume on the node.
+	Path string `json:"path"`
+
+	// Challenge is for a callback challenge.
+	Challenge *ChallengeRequest `json:"challenge,omitempty"`
+}
+
+// VolumeKeyResponse is a response to a VolumeKeyRequest.
+type VolumeKeyResponse struct {
+	// Key is the key that unlocks the volume.
+	Key []byte `json:"key,omitempty"`
+}
@@ -27,6 +27,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/dynamic"
+	"k8s.io/klog/v2"
 )
 
 // ApplySet is a set of objects that we want to apply to the cluster.
@@ -158,3 +159,41 @@ func (a *ApplySet) ApplyOnce(ctx context.Context) (*ApplyResults, error) {
 	}
 	return results, nil
 }
+
+// HealthCheckOnce will read the current state of all objects and report their health.
+// It does not apply any changes; objects that do not exist are reported as unhealthy.
+func (a *ApplySet) HealthCheckOnce(ctx context.Context) (*HealthResults, error) {
+	// snapshot the state
+	a.mutex.Lock()
+	trackers := a.trackers
+	a.mutex.Unlock()
+
+	client := &UnstructuredClient{
+		client:     a.client,
+		restMapper: a.restMapper,
+	}
+
+	results := &HealthResults{total: len(trackers.items)}
+
+	for i := range trackers.items {
+		tracker := &trackers.items[i]
+		expectedObject := tracker.desired
+
+		gvk := expectedObject.GroupVersionKind()
+		nn := types.NamespacedName{Namespace: expectedObject.GetNamespace(), Name: expectedObject.GetName()}
+
+		currentObj, err := client.Get(ctx, gvk, nn)
+		if err != nil {
+			if !apierrors.IsNotFound(err) {
+				return nil, fmt.Errorf("error getting %s %s: %w", gvk, nn, err)
+			}
+			klog.Infof("object %s %s not found", gvk, nn)
+			results.reportHealth(gvk, nn, false)
+			continue
+		}
+
+		tracker.isHealthy = isHealthy(currentObj)
+		results.reportHealth(gvk, nn, tracker.isHealthy)
+	}
+	return results, nil
+}
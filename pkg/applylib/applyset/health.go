@@ -57,6 +57,17 @@ func isHealthy(u *unstructured.Unstructured) bool {
 		return true
 	}
 
+	// Workloads don't reliably report readiness via status.conditions
+	// (DaemonSets have no conditions at all), so we check their rollout status.
+	switch gvk.GroupKind() {
+	case schema.GroupKind{Group: "apps", Kind: "Deployment"}:
+		return isDeploymentHealthy(u)
+	case schema.GroupKind{Group: "apps", Kind: "DaemonSet"}:
+		return isDaemonSetHealthy(u)
+	case schema.GroupKind{Group: "apps", Kind: "StatefulSet"}:
+		return isStatefulSetHealthy(u)
+	}
+
 	ready := true
 	statusConditions, found, err := unstructured.NestedFieldNoCopy(u.Object, "status", "conditions")
 	if err != nil || !found {
@@ -120,6 +131,102 @@ func isHealthy(u *unstructured.Unstructured) bool {
 	return ready
 }
 
+// isDeploymentHealthy reports whether all replicas of the Deployment have been updated and are available.
+func isDeploymentHealthy(u *unstructured.Unstructured) bool {
+	if !isObservedGenerationCurrent(u) {
+		return false
+	}
+
+	replicas := nestedInt64OrDefault(u, 1, "spec", "replicas")
+	updatedReplicas := nestedInt64OrDefault(u, 0, "status", "updatedReplicas")
+	totalReplicas := nestedInt64OrDefault(u, 0, "status", "replicas")
+	availableReplicas := nestedInt64OrDefault(u, 0, "status", "availableReplicas")
+
+	if updatedReplicas < replicas {
+		klog.Infof("object %s has %d of %d replicas updated", humanName(u), updatedReplicas, replicas)
+		return false
+	}
+	if totalReplicas > updatedReplicas {
+		klog.Infof("object %s has %d old replicas pending termination", humanName(u), totalReplicas-updatedReplicas)
+		return false
+	}
+	if availableReplicas < replicas {
+		klog.Infof("object %s has %d of %d replicas available", humanName(u), availableReplicas, replicas)
+		return false
+	}
+	return true
+}
+
+// isDaemonSetHealthy reports whether the DaemonSet pods have been updated and are available on all scheduled nodes.
+func isDaemonSetHealthy(u *unstructured.Unstructured) bool {
+	if !isObservedGenerationCurrent(u) {
+		return false
+	}
+
+	desired := nestedInt64OrDefault(u, 0, "status", "desiredNumberScheduled")
+	updated := nestedInt64OrDefault(u, 0, "status", "updatedNumberScheduled")
+	available := nestedInt64OrDefault(u, 0, "status", "numberAvailable")
+
+	if updated < desired {
+		klog.Infof("object %s has %d of %d pods updated", humanName(u), updated, desired)
+		return false
+	}
+	if available < desired {
+		klog.Infof("object %s has %d of %d pods available", humanName(u), available, desired)
+		return false
+	}
+	return true
+}
+
+// isStatefulSetHealthy reports whether all replicas of the StatefulSet are ready, and updated if the update strategy rolls them automatically.
+func isStatefulSetHealthy(u *unstructured.Unstructured) bool {
+	if !isObservedGenerationCurrent(u) {
+		return false
+	}
+
+	replicas := nestedInt64OrDefault(u, 1, "spec", "replicas")
+	readyReplicas := nestedInt64OrDefault(u, 0, "status", "readyReplicas")
+	updatedReplicas := nestedInt64OrDefault(u, 0, "status", "updatedReplicas")
+
+	updateStrategy, _, _ := unstructured.NestedString(u.Object, "spec", "updateStrategy", "type")
+	if updateStrategy != "OnDelete" && updatedReplicas < replicas {
+		klog.Infof("object %s has %d of %d replicas updated", humanName(u), updatedReplicas, replicas)
+		return false
+	}
+	if readyReplicas < replicas {
+		klog.Infof("object %s has %d of %d replicas ready", humanName(u), readyReplicas, replicas)
+		return false
+	}
+	return true
+}
+
+// isObservedGenerationCurrent reports whether the controller has observed the latest spec of the object.
+func isObservedGenerationCurrent(u *unstructured.Unstructured) bool {
+	observedGeneration, found, err := unstructured.NestedInt64(u.Object, "status", "observedGeneration")
+	if err != nil || !found {
+		klog.Infof("status.observedGeneration not found for %s", humanName(u))
+		return false
+	}
+	if observedGeneration < u.GetGeneration() {
+		klog.Infof("object %s has not yet been observed at generation %d (observed %d)", humanName(u), u.GetGeneration(), observedGeneration)
+		return false
+	}
+	return true
+}
+
+// nestedInt64OrDefault returns the integer value of the specified field, or defaultValue if it is not set.
+func nestedInt64OrDefault(u *unstructured.Unstructured, defaultValue int64, fields ...string) int64 {
+	v, found, err := unstructured.NestedInt64(u.Object, fields...)
+	if err != nil {
+		klog.Warningf("expected %s to be an integer in %s: %v", strings.Join(fields, "."), humanName(u), err)
+		return defaultValue
+	}
+	if !found {
+		return defaultValue
+	}
+	return v
+}
+
 // humanName returns an identifier for the object suitable for printing in log messages
 func humanName(u *unstructured.Unstructured) string {
 	gvk := u.GroupVersionKind()
@@ -0,0 +1,199 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package applyset
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+)
+
+func TestIsHealthy(t *testing.T) {
+	grid := []struct {
+		name     string
+		object   string
+		expected bool
+	}{
+		{
+			name: "configmap",
+			object: `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: foo
+`,
+			expected: true,
+		},
+		{
+			name: "deployment rolled out",
+			object: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: foo
+  generation: 2
+spec:
+  replicas: 2
+status:
+  observedGeneration: 2
+  replicas: 2
+  updatedReplicas: 2
+  availableReplicas: 2
+`,
+			expected: true,
+		},
+		{
+			name: "deployment not yet observed",
+			object: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: foo
+  generation: 3
+spec:
+  replicas: 2
+status:
+  observedGeneration: 2
+  replicas: 2
+  updatedReplicas: 2
+  availableReplicas: 2
+`,
+			expected: false,
+		},
+		{
+			name: "deployment with old replicas",
+			object: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: foo
+  generation: 2
+spec:
+  replicas: 2
+status:
+  observedGeneration: 2
+  replicas: 3
+  updatedReplicas: 2
+  availableReplicas: 2
+`,
+			expected: false,
+		},
+		{
+			name: "daemonset rolled out",
+			object: `
+apiVersion: apps/v1
+kind: DaemonSet
+metadata:
+  name: foo
+  generation: 5
+status:
+  observedGeneration: 5
+  desiredNumberScheduled: 3
+  updatedNumberScheduled: 3
+  numberAvailable: 3
+`,
+			expected: true,
+		},
+		{
+			name: "daemonset crashlooping",
+			object: `
+apiVersion: apps/v1
+kind: DaemonSet
+metadata:
+  name: foo
+  generation: 5
+status:
+  observedGeneration: 5
+  desiredNumberScheduled: 3
+  updatedNumberScheduled: 1
+  numberAvailable: 2
+`,
+			expected: false,
+		},
+		{
+			name: "statefulset ready",
+			object: `
+apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: foo
+  generation: 1
+spec:
+  replicas: 3
+status:
+  observedGeneration: 1
+  readyReplicas: 3
+  updatedReplicas: 3
+`,
+			expected: true,
+		},
+		{
+			name: "statefulset with OnDelete strategy",
+			object: `
+apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: foo
+  generation: 2
+spec:
+  replicas: 3
+  updateStrategy:
+    type: OnDelete
+status:
+  observedGeneration: 2
+  readyReplicas: 3
+  updatedReplicas: 0
+`,
+			expected: true,
+		},
+		{
+			name: "statefulset not ready",
+			object: `
+apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: foo
+  generation: 1
+spec:
+  replicas: 3
+status:
+  observedGeneration: 1
+  readyReplicas: 2
+  updatedReplicas: 3
+`,
+			expected: false,
+		},
+	}
+
+	for _, g := range grid {
+		t.Run(g.name, func(t *testing.T) {
+			j, err := yaml.YAMLToJSON([]byte(g.object))
+			if err != nil {
+				t.Fatalf("failed to convert object to JSON: %v", err)
+			}
+			u := &unstructured.Unstructured{}
+			if err := u.UnmarshalJSON(j); err != nil {
+				t.Fatalf("failed to parse object: %v", err)
+			}
+			actual := isHealthy(u)
+			if actual != g.expected {
+				t.Errorf("unexpected result from isHealthy; expected %v, got %v", g.expected, actual)
+			}
+		})
+	}
+}
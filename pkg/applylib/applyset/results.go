@@ -76,3 +76,29 @@ func (r *ApplyResults) reportHealth(gvk schema.GroupVersionKind, nn types.Namesp
 		r.unhealthyCount++
 	}
 }
+
+// HealthResults contains the results of a HealthCheck operation.
+type HealthResults struct {
+	total        int
+	healthyCount int
+	unhealthy    []string
+}
+
+// AllHealthy is true if all the objects exist and have converged to a "ready" state.
+func (r *HealthResults) AllHealthy() bool {
+	return r.healthyCount == r.total
+}
+
+// Unhealthy returns the human-readable names of the objects that were not healthy.
+func (r *HealthResults) Unhealthy() []string {
+	return r.unhealthy
+}
+
+// reportHealth records the health of an object.
+func (r *HealthResults) reportHealth(gvk schema.GroupVersionKind, nn types.NamespacedName, isHealthy bool) {
+	if isHealthy {
+		r.healthyCount++
+	} else {
+		r.unhealthy = append(r.unhealthy, gvk.Kind+":"+nn.String())
+	}
+}
@@ -28,6 +28,7 @@ import (
 	"github.com/blang/semver/v4"
 	"github.com/google/go-containerregistry/pkg/authn"
 	"github.com/google/go-containerregistry/pkg/crane"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/klog/v2"
 	"k8s.io/kops/pkg/apis/kops"
@@ -58,6 +59,11 @@ type AssetBuilder struct {
 	// StaticFiles records static files:
 	// * Configuration files supporting static pods
 	StaticFiles []*StaticFile
+
+	// signatureVerifier verifies the signatures of the images, when required by the signature policy
+	signatureVerifier *SignatureVerifier
+	// verifiedImages holds the images whose signatures were verified, pinned to the verified digest
+	verifiedImages map[string]string
 }
 
 type StaticFile struct {
@@ -219,6 +225,10 @@ func (a *AssetBuilder) RemapImage(image string) (string, error) {
 
 	a.ImageAssets = append(a.ImageAssets, asset)
 
+	if !a.GetAssets && a.AssetsLocation != nil && a.AssetsLocation.SignaturePolicy != nil && a.AssetsLocation.SignaturePolicy.Images {
+		return a.verifyImage(image)
+	}
+
 	if !featureflag.ImageDigest.Enabled() || os.Getenv("KOPS_BASE_URL") != "" {
 		return image, nil
 	}
@@ -236,6 +246,43 @@ func (a *AssetBuilder) RemapImage(image string) (string, error) {
 	return image + "@" + digest, nil
 }
 
+// verifyImage checks the signature of the image, returning the image pinned to the digest that was verified,
+// so that the cluster runs the image whose signature was checked even if the tag is moved afterwards.
+func (a *AssetBuilder) verifyImage(image string) (string, error) {
+	if pinned, found := a.verifiedImages[image]; found {
+		return pinned, nil
+	}
+
+	if a.signatureVerifier == nil {
+		verifier, err := NewSignatureVerifier(a.AssetsLocation.SignaturePolicy.PublicKeys)
+		if err != nil {
+			return "", fmt.Errorf("building signature verifier: %w", err)
+		}
+		a.signatureVerifier = verifier
+	}
+
+	digest, err := a.signatureVerifier.VerifyImage(image)
+	if err != nil {
+		return "", err
+	}
+
+	pinned := pinImage(image, digest)
+	if a.verifiedImages == nil {
+		a.verifiedImages = make(map[string]string)
+	}
+	a.verifiedImages[image] = pinned
+	a.verifiedImages[pinned] = pinned
+	return pinned, nil
+}
+
+// pinImage returns the image pinned to the digest, replacing any digest that the image was already pinned to.
+func pinImage(image string, digest v1.Hash) string {
+	if i := strings.Index(image, "@"); i != -1 {
+		image = image[:i]
+	}
+	return image + "@" + digest.String()
+}
+
 // RemapFile returns a remapped URL for the file, if AssetsLocation is defined.
 // It is returns in a FileAsset, alongside the SHA hash of the file.
 // The SHA hash is is knownHash is provided, and otherwise will be found first by
@@ -311,7 +358,7 @@ func (a *AssetBuilder) findHash(file *FileAsset) (*hashing.Hash, error) {
 
 	klog.Infof("asset %q is not well-known, downloading hash", file.CanonicalURL)
 
-	// We now prefer sha256 hashes
+	// We now prefer sha256 hashes, but some projects (e.g. gVisor) only publish sha512 hashes
 	for backoffSteps := 1; backoffSteps <= 3; backoffSteps++ {
 		// We try first with a short backoff, so we don't
 		// waste too much time looking for files that don't
@@ -322,7 +369,7 @@ func (a *AssetBuilder) findHash(file *FileAsset) (*hashing.Hash, error) {
 			Steps:    backoffSteps,
 		}
 
-		for _, ext := range []string{".sha256", ".sha256sum"} {
+		for _, ext := range []string{".sha256", ".sha256sum", ".sha512"} {
 			for _, mirror := range FindURLMirrors(u.String()) {
 				hashURL := mirror + ext
 				klog.V(3).Infof("Trying to read hash file: %q", hashURL)
@@ -17,10 +17,14 @@ limitations under the License.
 package assets
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
 	"os"
 	"path/filepath"
 	"testing"
 
+	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"k8s.io/kops/pkg/apis/kops"
 	"k8s.io/kops/pkg/apis/kops/util"
 	"k8s.io/kops/pkg/testutils/golden"
@@ -180,3 +184,97 @@ func TestRemapEmptySection(t *testing.T) {
 
 	golden.AssertMatchesFile(t, string(actual), expectedPath)
 }
+
+func TestRemapImage_SignaturePolicy_PinsVerifiedImages(t *testing.T) {
+	builder := buildAssetBuilder(t)
+	builder.AssetsLocation.SignaturePolicy = &kops.AssetsSignaturePolicy{Images: true}
+
+	image := "registry.k8s.io/kube-apiserver:v1.30.0"
+	expected := "registry.k8s.io/kube-apiserver:v1.30.0@sha256:9b2b22b1e0a0e6a8bd9bb1b0e2c9e4d2b1aa0b3c3d0f6c7a2e5e7f0a1b2c3d4e"
+	builder.verifiedImages = map[string]string{image: expected, expected: expected}
+
+	remapped := image
+	for i := 0; i < 2; i++ {
+		var err error
+		remapped, err = builder.RemapImage(remapped)
+		if err != nil {
+			t.Fatalf("Error remapping image (iteration %d): %s", i, err)
+		}
+		if remapped != expected {
+			t.Errorf("Error remapping image (Expecting: %s, got %s, iteration: %d)", expected, remapped, i)
+		}
+	}
+}
+
+func TestRemapImage_SignaturePolicy_VerifiesPinnedDigest(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	host := newTestRegistry(t)
+
+	// The tag is moved to a newer image after the first one was pinned
+	pinned := pushSignedImage(t, host+"/ml/model:v3", key)
+	latest := pushSignedImage(t, host+"/ml/model:v3", key)
+
+	builder := buildAssetBuilder(t)
+	builder.AssetsLocation.SignaturePolicy = &kops.AssetsSignaturePolicy{
+		PublicKeys: []string{encodePublicKey(t, &key.PublicKey)},
+		Images:     true,
+	}
+
+	grid := []struct {
+		image    string
+		expected string
+	}{
+		{
+			image:    host + "/ml/model:v3",
+			expected: host + "/ml/model:v3@" + latest.String(),
+		},
+		{
+			image:    host + "/ml/model:v3@" + pinned.String(),
+			expected: host + "/ml/model:v3@" + pinned.String(),
+		},
+	}
+	for _, g := range grid {
+		remapped, err := builder.RemapImage(g.image)
+		if err != nil {
+			t.Fatalf("unexpected error remapping %q: %v", g.image, err)
+		}
+		if remapped != g.expected {
+			t.Errorf("unexpected remapped image for %q (Expecting: %s, got %s)", g.image, g.expected, remapped)
+		}
+	}
+
+	unsigned := host + "/ml/model:v3@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	if _, err := builder.RemapImage(unsigned); err == nil {
+		t.Errorf("expected image without a signature to be rejected")
+	}
+}
+
+func TestPinImage(t *testing.T) {
+	digest := v1.Hash{Algorithm: "sha256", Hex: "9b2b22b1e0a0e6a8bd9bb1b0e2c9e4d2b1aa0b3c3d0f6c7a2e5e7f0a1b2c3d4e"}
+
+	grid := []struct {
+		image    string
+		expected string
+	}{
+		{
+			image:    "registry.k8s.io/kube-apiserver:v1.30.0",
+			expected: "registry.k8s.io/kube-apiserver:v1.30.0@sha256:9b2b22b1e0a0e6a8bd9bb1b0e2c9e4d2b1aa0b3c3d0f6c7a2e5e7f0a1b2c3d4e",
+		},
+		{
+			image:    "registry.k8s.io/kube-apiserver:v1.30.0@sha256:0000000000000000000000000000000000000000000000000000000000000000",
+			expected: "registry.k8s.io/kube-apiserver:v1.30.0@sha256:9b2b22b1e0a0e6a8bd9bb1b0e2c9e4d2b1aa0b3c3d0f6c7a2e5e7f0a1b2c3d4e",
+		},
+		{
+			image:    "example.com:5000/kube-apiserver",
+			expected: "example.com:5000/kube-apiserver@sha256:9b2b22b1e0a0e6a8bd9bb1b0e2c9e4d2b1aa0b3c3d0f6c7a2e5e7f0a1b2c3d4e",
+		},
+	}
+	for _, g := range grid {
+		if actual := pinImage(g.image, digest); actual != g.expected {
+			t.Errorf("pinImage(%q) = %q, expected %q", g.image, actual, g.expected)
+		}
+	}
+}
@@ -0,0 +1,502 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package assets
+
+import (
+	"archive/tar"
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"k8s.io/klog/v2"
+	channelsapi "k8s.io/kops/channels/pkg/api"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/util/pkg/hashing"
+	"k8s.io/kops/util/pkg/vfs"
+	"sigs.k8s.io/yaml"
+)
+
+// bundleManifestPath is the path of the BundleManifest inside a bundle.
+const bundleManifestPath = "bundle.yaml"
+
+// BundleManifest describes the contents of a bundle, an archive carrying the assets of a cluster into an air gap.
+type BundleManifest struct {
+	// Files are the file assets in the bundle.
+	Files []*BundleFile `json:"files,omitempty"`
+	// Images are the container images in the bundle.
+	Images []*BundleImage `json:"images,omitempty"`
+	// Channels are the channel manifests, and the manifests of the addon channels, in the bundle.
+	Channels []*BundleChannel `json:"channels,omitempty"`
+}
+
+// BundleFile is a file asset in a bundle.
+type BundleFile struct {
+	// Canonical is the canonical location of the file.
+	Canonical string `json:"canonical"`
+	// SHA is the hash of the file.
+	SHA string `json:"sha"`
+	// Path is the path of the file in the bundle.
+	// If the cluster requires signed files, the signature is next to it, with the SignatureExtension.
+	Path string `json:"path"`
+}
+
+// BundleImage is a container image in a bundle.
+type BundleImage struct {
+	// Canonical is the canonical location of the image.
+	Canonical string `json:"canonical"`
+	// Digest is the digest of the image.
+	Digest string `json:"digest"`
+	// Path is the path of the OCI image layout holding the image in the bundle.
+	Path string `json:"path"`
+	// Signatures is the path of the OCI image layout holding the cosign signatures of the image in the bundle,
+	// if the cluster requires signed images.
+	Signatures string `json:"signatures,omitempty"`
+}
+
+// BundleChannel is a channel manifest, or the manifest of an addon of an addon channel, in a bundle.
+type BundleChannel struct {
+	// Location is the canonical location of the channel.
+	Location string `json:"location"`
+	// Path is the path of the channel in the bundle.
+	Path string `json:"path"`
+}
+
+// CreateBundle downloads the assets and channel manifests of a cluster, and writes them to a single archive.
+// The manifests of the addons of the addon channels are bundled too, so they can be found relative to the imported channels.
+// If the cluster requires signed assets, their signatures are verified and bundled with them.
+func CreateBundle(ctx context.Context, vfsContext *vfs.VFSContext, cluster *kops.Cluster, imageAssets []*ImageAsset, fileAssets []*FileAsset, bundlePath string) (*BundleManifest, error) {
+	imageVerifier, fileVerifier, err := signatureVerifiersForCluster(cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	dir, err := os.MkdirTemp("", "kops-bundle")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	manifest := &BundleManifest{}
+	tasks := map[string]assetTask{}
+
+	var exports []*ExportImage
+	for _, imageAsset := range imageAssets {
+		canonical := imageAsset.CanonicalLocation
+		if _, found := tasks[canonical]; found {
+			continue
+		}
+		export := &ExportImage{
+			Name:        canonical,
+			SourceImage: canonical,
+			Layout:      filepath.Join(dir, "images", bundleName(canonical)),
+		}
+		if imageVerifier != nil {
+			export.Verifier = imageVerifier
+			export.SignatureLayout = filepath.Join(dir, "signatures", bundleName(canonical))
+		}
+		tasks[canonical] = export
+		exports = append(exports, export)
+	}
+
+	seen := map[string]bool{}
+	for _, fileAsset := range fileAssets {
+		canonical := fileAsset.CanonicalURL.String()
+		if seen[canonical] {
+			continue
+		}
+		seen[canonical] = true
+
+		sha := fileAsset.SHAValue.Hex()
+		p := path.Join("files", sha)
+		manifest.Files = append(manifest.Files, &BundleFile{
+			Canonical: canonical,
+			SHA:       sha,
+			Path:      p,
+		})
+		if _, found := tasks[p]; found {
+			continue
+		}
+		tasks[p] = &CopyFile{
+			Name:       canonical,
+			SourceFile: canonical,
+			TargetFile: filepath.Join(dir, p),
+			SHA:        sha,
+			VFSContext: vfsContext,
+			Cluster:    cluster,
+			Verifier:   fileVerifier,
+		}
+	}
+
+	if err := runTasks(tasks); err != nil {
+		return nil, err
+	}
+
+	for _, export := range exports {
+		image := &BundleImage{
+			Canonical: export.SourceImage,
+			Digest:    export.Digest,
+			Path:      path.Join("images", bundleName(export.SourceImage)),
+		}
+		if export.SignatureLayout != "" {
+			image.Signatures = path.Join("signatures", bundleName(export.SourceImage))
+		}
+		manifest.Images = append(manifest.Images, image)
+	}
+
+	bundled := map[string]bool{}
+	bundleChannel := func(u *url.URL) ([]byte, error) {
+		data, err := vfsContext.ReadFile(u.String())
+		if err != nil {
+			return nil, fmt.Errorf("reading channel %q: %w", u, err)
+		}
+		if bundled[u.String()] {
+			return data, nil
+		}
+		bundled[u.String()] = true
+
+		p := path.Join("channels", bundleName(u.String()))
+		if err := os.MkdirAll(filepath.Join(dir, "channels"), 0o755); err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(filepath.Join(dir, p), data, 0o644); err != nil {
+			return nil, err
+		}
+		manifest.Channels = append(manifest.Channels, &BundleChannel{
+			Location: u.String(),
+			Path:     p,
+		})
+		return data, nil
+	}
+
+	channelLocation := cluster.Spec.Channel
+	if channelLocation == "" {
+		channelLocation = kops.DefaultChannel
+	}
+	if u, err := kops.ResolveChannel(channelLocation); err != nil {
+		return nil, err
+	} else if u != nil {
+		if _, err := bundleChannel(u); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, addon := range cluster.Spec.Addons {
+		u, err := kops.ResolveChannel(addon.Manifest)
+		if err != nil {
+			return nil, err
+		}
+		if u == nil {
+			continue
+		}
+		data, err := bundleChannel(u)
+		if err != nil {
+			return nil, err
+		}
+
+		// The manifests of an addon channel are found relative to it, and are imported next to it
+		addons := &channelsapi.Addons{}
+		if err := yaml.Unmarshal(data, addons); err != nil {
+			return nil, fmt.Errorf("parsing addon channel %q: %w", u, err)
+		}
+		for _, spec := range addons.Spec.Addons {
+			if spec == nil || spec.Manifest == nil {
+				continue
+			}
+			manifestURL, err := url.Parse(*spec.Manifest)
+			if err != nil {
+				return nil, fmt.Errorf("parsing manifest %q of addon channel %q: %w", *spec.Manifest, u, err)
+			}
+			if _, err := bundleChannel(u.ResolveReference(manifestURL)); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	data, err := yaml.Marshal(manifest)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling bundle manifest: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, bundleManifestPath), data, 0o644); err != nil {
+		return nil, err
+	}
+
+	if err := writeTarball(dir, bundlePath); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// ImportBundle pushes the assets of a bundle to the file repository and container registry of the cluster.
+// The signatures of the assets are pushed with them, if the cluster requires signed assets.
+// It returns the locations the channel manifests were imported to, by canonical location.
+func ImportBundle(ctx context.Context, vfsContext *vfs.VFSContext, cluster *kops.Cluster, bundlePath string) (map[string]string, error) {
+	if cluster.Spec.Assets == nil || cluster.Spec.Assets.FileRepository == nil {
+		return nil, fmt.Errorf("spec.assets.fileRepository must be set to import a bundle")
+	}
+
+	imageVerifier, fileVerifier, err := signatureVerifiersForCluster(cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	dir, err := os.MkdirTemp("", "kops-bundle")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	if err := extractTarball(bundlePath, dir); err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, bundleManifestPath))
+	if err != nil {
+		return nil, fmt.Errorf("reading bundle manifest: %w", err)
+	}
+	manifest := &BundleManifest{}
+	if err := yaml.Unmarshal(data, manifest); err != nil {
+		return nil, fmt.Errorf("parsing bundle manifest: %w", err)
+	}
+
+	if len(manifest.Images) != 0 && cluster.Spec.Assets.ContainerRegistry == nil {
+		return nil, fmt.Errorf("spec.assets.containerRegistry must be set to import the images of a bundle")
+	}
+
+	assetBuilder := NewAssetBuilder(vfsContext, cluster.Spec.Assets, cluster.Spec.KubernetesVersion, true)
+	tasks := map[string]assetTask{}
+
+	for _, file := range manifest.Files {
+		canonicalURL, err := url.Parse(file.Canonical)
+		if err != nil {
+			return nil, fmt.Errorf("parsing location of file %q: %w", file.Canonical, err)
+		}
+		sha, err := hashing.FromString(file.SHA)
+		if err != nil {
+			return nil, fmt.Errorf("parsing hash of file %q: %w", file.Canonical, err)
+		}
+		fileAsset, err := assetBuilder.RemapFile(canonicalURL, sha)
+		if err != nil {
+			return nil, err
+		}
+		source, err := bundleFilePath(dir, file.Path)
+		if err != nil {
+			return nil, err
+		}
+		if fileVerifier != nil {
+			if _, err := os.Stat(source + SignatureExtension); err != nil {
+				return nil, fmt.Errorf("the bundle has no signature of file %q, create it with the signature policy of the cluster", file.Canonical)
+			}
+		}
+		tasks[file.Canonical] = &CopyFile{
+			Name:       file.Canonical,
+			SourceFile: source,
+			TargetFile: fileAsset.DownloadURL.String(),
+			SHA:        file.SHA,
+			VFSContext: vfsContext,
+			Cluster:    cluster,
+			Verifier:   fileVerifier,
+		}
+	}
+
+	for _, image := range manifest.Images {
+		target, err := assetBuilder.RemapImage(image.Canonical)
+		if err != nil {
+			return nil, err
+		}
+		if !strings.Contains(image.Canonical, "@") {
+			// The image is pushed by tag; a digest resolved while remapping doesn't exist in the registry yet
+			target, _, _ = strings.Cut(target, "@")
+		}
+		source, err := bundleFilePath(dir, image.Path)
+		if err != nil {
+			return nil, err
+		}
+		importImage := &ImportImage{
+			Name:        target,
+			Layout:      source,
+			TargetImage: target,
+		}
+		if image.Signatures != "" {
+			signatures, err := bundleFilePath(dir, image.Signatures)
+			if err != nil {
+				return nil, err
+			}
+			importImage.SignatureLayout = signatures
+		} else if imageVerifier != nil {
+			return nil, fmt.Errorf("the bundle has no signatures of image %q, create it with the signature policy of the cluster", image.Canonical)
+		}
+		tasks[target] = importImage
+	}
+
+	if err := runTasks(tasks); err != nil {
+		return nil, err
+	}
+
+	channels := make(map[string]string)
+	for _, channel := range manifest.Channels {
+		canonicalURL, err := url.Parse(channel.Location)
+		if err != nil {
+			return nil, fmt.Errorf("parsing location of channel %q: %w", channel.Location, err)
+		}
+		targetURL, err := assetBuilder.remapURL(canonicalURL)
+		if err != nil {
+			return nil, err
+		}
+		target, err := buildVFSPath(targetURL.String())
+		if err != nil {
+			return nil, err
+		}
+		p, err := vfsContext.BuildVfsPath(target)
+		if err != nil {
+			return nil, fmt.Errorf("error building path %q: %v", target, err)
+		}
+		source, err := bundleFilePath(dir, channel.Path)
+		if err != nil {
+			return nil, err
+		}
+		data, err := os.ReadFile(source)
+		if err != nil {
+			return nil, err
+		}
+		klog.Infof("uploading channel %q to %q", channel.Location, target)
+		if err := writeFile(ctx, cluster, p, data); err != nil {
+			return nil, err
+		}
+		channels[channel.Location] = targetURL.String()
+	}
+
+	return channels, nil
+}
+
+var bundleNameUnsafe = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// bundleName returns a name for the location that is safe to use as a file name.
+func bundleName(location string) string {
+	location = strings.TrimPrefix(location, "https://")
+	location = strings.TrimPrefix(location, "http://")
+	return bundleNameUnsafe.ReplaceAllString(location, "_")
+}
+
+// bundleFilePath returns the path of a file of a bundle extracted to dir, rejecting paths outside of it.
+func bundleFilePath(dir string, p string) (string, error) {
+	clean := path.Clean(p)
+	if path.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, "../") {
+		return "", fmt.Errorf("invalid path %q in bundle", p)
+	}
+	return filepath.Join(dir, filepath.FromSlash(clean)), nil
+}
+
+// writeTarball archives the contents of dir to the file at tarballPath.
+func writeTarball(dir string, tarballPath string) error {
+	f, err := os.Create(tarballPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := tar.NewWriter(f)
+	err = filepath.Walk(dir, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		if rel == "." {
+			return nil
+		}
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(rel)
+		if err := w.WriteHeader(header); err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		in, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer in.Close()
+		_, err = io.Copy(w, in)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("writing bundle %q: %w", tarballPath, err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("writing bundle %q: %w", tarballPath, err)
+	}
+	return f.Close()
+}
+
+// extractTarball extracts the archive at tarballPath to dir.
+func extractTarball(tarballPath string, dir string) error {
+	f, err := os.Open(tarballPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	r := tar.NewReader(f)
+	for {
+		header, err := r.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("reading bundle %q: %w", tarballPath, err)
+		}
+		p, err := bundleFilePath(dir, header.Name)
+		if err != nil {
+			return err
+		}
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(p, 0o755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+				return err
+			}
+			out, err := os.OpenFile(p, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+			if err != nil {
+				return err
+			}
+			if _, err := io.Copy(out, r); err != nil {
+				out.Close()
+				return err
+			}
+			if err := out.Close(); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("unexpected entry %q of type %v in bundle", header.Name, header.Typeflag)
+		}
+	}
+}
@@ -0,0 +1,240 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package assets
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"net/url"
+	"path/filepath"
+	"testing"
+
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/util/pkg/hashing"
+	"k8s.io/kops/util/pkg/vfs"
+)
+
+func TestBundle(t *testing.T) {
+	ctx := context.TODO()
+
+	vfsContext := vfs.NewVFSContext()
+	vfsContext.ResetMemfsContext(true)
+
+	data := []byte("kubelet binary")
+	source, err := vfsContext.BuildVfsPath("memfs://upstream/release/v1.30.0/bin/linux/amd64/kubelet")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := source.WriteFile(ctx, bytes.NewReader(data), nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sha, err := hashing.HashAlgorithmSHA256.Hash(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	canonicalURL, err := url.Parse(source.Path())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	addonChannel := []byte("spec:\n  addons:\n  - name: example\n    version: 1.0.0\n    manifest: example/v1.0.0.yaml\n")
+	addonManifest := []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: example\n")
+	for p, d := range map[string][]byte{
+		"memfs://upstream/addons/addons.yaml":         addonChannel,
+		"memfs://upstream/addons/example/v1.0.0.yaml": addonManifest,
+	} {
+		vfsPath, err := vfsContext.BuildVfsPath(p)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := vfsPath.WriteFile(ctx, bytes.NewReader(d), nil); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	cluster := &kops.Cluster{}
+	cluster.Spec.Channel = "none"
+	cluster.Spec.KubernetesVersion = "1.30.0"
+	cluster.Spec.Addons = []kops.AddonSpec{{Manifest: "memfs://upstream/addons/addons.yaml"}}
+
+	bundlePath := filepath.Join(t.TempDir(), "bundle.tar")
+	fileAssets := []*FileAsset{{CanonicalURL: canonicalURL, DownloadURL: canonicalURL, SHAValue: sha}}
+	manifest, err := CreateBundle(ctx, vfsContext, cluster, nil, fileAssets, bundlePath)
+	if err != nil {
+		t.Fatalf("unexpected error creating bundle: %v", err)
+	}
+	if len(manifest.Files) != 1 || manifest.Files[0].SHA != sha.Hex() {
+		t.Fatalf("unexpected bundle manifest: %+v", manifest)
+	}
+	if len(manifest.Channels) != 2 {
+		t.Fatalf("expected the addon channel and its manifest in the bundle, got %+v", manifest.Channels)
+	}
+
+	// Importing requires a file repository
+	if _, err := ImportBundle(ctx, vfsContext, cluster, bundlePath); err == nil {
+		t.Fatalf("expected error importing bundle without a file repository")
+	}
+
+	fileRepository := "memfs://mirror/kops"
+	cluster.Spec.Assets = &kops.AssetsSpec{FileRepository: &fileRepository}
+	if _, err := ImportBundle(ctx, vfsContext, cluster, bundlePath); err != nil {
+		t.Fatalf("unexpected error importing bundle: %v", err)
+	}
+
+	imported, err := vfsContext.ReadFile("memfs://mirror/kops/release/v1.30.0/bin/linux/amd64/kubelet")
+	if err != nil {
+		t.Fatalf("unexpected error reading imported file: %v", err)
+	}
+	if !bytes.Equal(imported, data) {
+		t.Errorf("unexpected imported file %q", imported)
+	}
+	importedSHA, err := vfsContext.ReadFile("memfs://mirror/kops/release/v1.30.0/bin/linux/amd64/kubelet.sha256")
+	if err != nil {
+		t.Fatalf("unexpected error reading imported hash: %v", err)
+	}
+	if string(importedSHA) != sha.Hex() {
+		t.Errorf("unexpected imported hash %q", importedSHA)
+	}
+
+	// The addon manifests are imported next to their channel, so they are found relative to it
+	importedManifest, err := vfsContext.ReadFile("memfs://mirror/kops/addons/example/v1.0.0.yaml")
+	if err != nil {
+		t.Fatalf("unexpected error reading imported addon manifest: %v", err)
+	}
+	if !bytes.Equal(importedManifest, addonManifest) {
+		t.Errorf("unexpected imported addon manifest %q", importedManifest)
+	}
+}
+
+func TestBundleSignatures(t *testing.T) {
+	ctx := context.TODO()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	publicKey := encodePublicKey(t, &key.PublicKey)
+
+	upstream := newTestRegistry(t)
+	mirror := newTestRegistry(t)
+	digest := pushSignedImage(t, upstream+"/ml/model:v3", key)
+
+	vfsContext := vfs.NewVFSContext()
+	vfsContext.ResetMemfsContext(true)
+
+	data := []byte("kubelet binary")
+	sha, err := hashing.HashAlgorithmSHA256.Hash(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sig, err := key.Sign(rand.Reader, sha.HashValue, crypto.SHA256)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	signature := []byte(base64.StdEncoding.EncodeToString(sig))
+	for p, d := range map[string][]byte{
+		"memfs://upstream/release/v1.30.0/bin/linux/amd64/kubelet":                      data,
+		"memfs://upstream/release/v1.30.0/bin/linux/amd64/kubelet" + SignatureExtension: signature,
+	} {
+		vfsPath, err := vfsContext.BuildVfsPath(p)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := vfsPath.WriteFile(ctx, bytes.NewReader(d), nil); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	canonicalURL, err := url.Parse("memfs://upstream/release/v1.30.0/bin/linux/amd64/kubelet")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	image := upstream + "/ml/model:v3"
+	imageAssets := []*ImageAsset{{CanonicalLocation: image, DownloadLocation: image}}
+	fileAssets := []*FileAsset{{CanonicalURL: canonicalURL, DownloadURL: canonicalURL, SHAValue: sha}}
+
+	signaturePolicy := &kops.AssetsSignaturePolicy{PublicKeys: []string{publicKey}, Images: true, Files: true}
+	cluster := &kops.Cluster{}
+	cluster.Spec.Channel = "none"
+	cluster.Spec.KubernetesVersion = "1.30.0"
+	cluster.Spec.Assets = &kops.AssetsSpec{SignaturePolicy: signaturePolicy}
+
+	bundlePath := filepath.Join(t.TempDir(), "bundle.tar")
+	manifest, err := CreateBundle(ctx, vfsContext, cluster, imageAssets, fileAssets, bundlePath)
+	if err != nil {
+		t.Fatalf("unexpected error creating bundle: %v", err)
+	}
+	if len(manifest.Images) != 1 || manifest.Images[0].Digest != digest.String() || manifest.Images[0].Signatures == "" {
+		t.Fatalf("expected the image and its signatures in the bundle, got %+v", manifest.Images)
+	}
+
+	// The proxy replaces the registry of the image, as the port of the mirror can't be part of a repository name
+	fileRepository := "memfs://mirror/kops"
+	cluster.Spec.Assets.FileRepository = &fileRepository
+	cluster.Spec.Assets.ContainerProxy = &mirror
+	cluster.Spec.Assets.ContainerRegistry = &mirror
+	if _, err := ImportBundle(ctx, vfsContext, cluster, bundlePath); err != nil {
+		t.Fatalf("unexpected error importing bundle: %v", err)
+	}
+
+	verifier, err := NewSignatureVerifier([]string{publicKey})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	importedDigest, err := verifier.VerifyImage(mirror + "/ml/model:v3")
+	if err != nil {
+		t.Fatalf("unexpected error verifying imported image: %v", err)
+	}
+	if importedDigest != digest {
+		t.Errorf("unexpected imported image digest %q", importedDigest)
+	}
+	if err := verifier.VerifyFile(vfsContext, "memfs://mirror/kops/release/v1.30.0/bin/linux/amd64/kubelet", sha); err != nil {
+		t.Errorf("unexpected error verifying imported file: %v", err)
+	}
+
+	// A bundle without signatures can't be imported into a cluster that requires them
+	cluster.Spec.Assets = &kops.AssetsSpec{}
+	unsignedBundlePath := filepath.Join(t.TempDir(), "unsigned.tar")
+	if _, err := CreateBundle(ctx, vfsContext, cluster, imageAssets, fileAssets, unsignedBundlePath); err != nil {
+		t.Fatalf("unexpected error creating bundle: %v", err)
+	}
+	cluster.Spec.Assets = &kops.AssetsSpec{
+		FileRepository:    &fileRepository,
+		ContainerProxy:    &mirror,
+		ContainerRegistry: &mirror,
+		SignaturePolicy:   signaturePolicy,
+	}
+	if _, err := ImportBundle(ctx, vfsContext, cluster, unsignedBundlePath); err == nil {
+		t.Fatalf("expected error importing bundle without signatures")
+	}
+}
+
+func TestBundleFilePath(t *testing.T) {
+	for _, p := range []string{"../etc/passwd", "/etc/passwd", "files/../../etc/passwd"} {
+		if _, err := bundleFilePath("/tmp/bundle", p); err == nil {
+			t.Errorf("expected error for path %q", p)
+		}
+	}
+	if p, err := bundleFilePath("/tmp/bundle", "files/abc"); err != nil || p != "/tmp/bundle/files/abc" {
+		t.Errorf("unexpected path %q: %v", p, err)
+	}
+}
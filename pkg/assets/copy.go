@@ -32,12 +32,18 @@ type assetTask interface {
 func Copy(imageAssets []*ImageAsset, fileAssets []*FileAsset, vfsContext *vfs.VFSContext, cluster *kops.Cluster) error {
 	tasks := map[string]assetTask{}
 
+	imageVerifier, fileVerifier, err := signatureVerifiersForCluster(cluster)
+	if err != nil {
+		return err
+	}
+
 	for _, imageAsset := range imageAssets {
 		if imageAsset.DownloadLocation != imageAsset.CanonicalLocation {
 			copyImageTask := &CopyImage{
 				Name:        imageAsset.DownloadLocation,
 				SourceImage: imageAsset.CanonicalLocation,
 				TargetImage: imageAsset.DownloadLocation,
+				Verifier:    imageVerifier,
 			}
 
 			if existing, ok := tasks[copyImageTask.Name]; ok {
@@ -59,6 +65,7 @@ func Copy(imageAssets []*ImageAsset, fileAssets []*FileAsset, vfsContext *vfs.VF
 				SHA:        fileAsset.SHAValue.Hex(),
 				VFSContext: vfsContext,
 				Cluster:    cluster,
+				Verifier:   fileVerifier,
 			}
 
 			if existing, ok := tasks[copyFileTask.Name]; ok {
@@ -78,6 +85,11 @@ func Copy(imageAssets []*ImageAsset, fileAssets []*FileAsset, vfsContext *vfs.VF
 		}
 	}
 
+	return runTasks(tasks)
+}
+
+// runTasks runs the tasks, a few at a time, in the order of their names.
+func runTasks(tasks map[string]assetTask) error {
 	ch := make(chan error, 5)
 	for i := 0; i < cap(ch); i++ {
 		ch <- nil
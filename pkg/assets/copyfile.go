@@ -40,6 +40,8 @@ type CopyFile struct {
 	SHA        string
 	VFSContext *vfs.VFSContext
 	Cluster    *kops.Cluster
+	// Verifier, if set, requires the source file to be signed, and copies the signature with the file.
+	Verifier *SignatureVerifier
 }
 
 // fileExtensionForSHA returns the expected extension for the given hash
@@ -50,6 +52,8 @@ func fileExtensionForSHA(sha string) (string, error) {
 		return ".sha1", nil
 	case 64:
 		return ".sha256", nil
+	case 128:
+		return ".sha512", nil
 	default:
 		return "", fmt.Errorf("unhandled sha length for %q", sha)
 	}
@@ -65,6 +69,12 @@ func (e *CopyFile) Run() error {
 		return err
 	}
 
+	if e.Verifier != nil {
+		if err := copySignature(ctx, e.VFSContext, e.Cluster, e.Verifier, e.SourceFile, e.TargetFile, expectedSHA); err != nil {
+			return err
+		}
+	}
+
 	targetSHAFile := e.TargetFile + shaExtension
 
 	targetSHABytes, err := e.VFSContext.ReadFile(targetSHAFile)
@@ -163,6 +173,37 @@ func transferFile(ctx context.Context, vfsContext *vfs.VFSContext, cluster *kops
 	return nil
 }
 
+// copySignature verifies the signature of the source file and uploads it next to the target file.
+func copySignature(ctx context.Context, vfsContext *vfs.VFSContext, cluster *kops.Cluster, verifier *SignatureVerifier, source string, target string, sha string) error {
+	shaHash, err := hashing.FromString(sha)
+	if err != nil {
+		return fmt.Errorf("unable to parse sha: %q, %v", sha, err)
+	}
+	if err := checkSignedHash(shaHash); err != nil {
+		return fmt.Errorf("verifying signature of %q: %v", source, err)
+	}
+
+	signature, err := vfsContext.ReadFile(source + SignatureExtension)
+	if err != nil {
+		return fmt.Errorf("error downloading signature of %q: %v", source, err)
+	}
+	if err := verifier.VerifyFileSignature(shaHash, signature); err != nil {
+		return fmt.Errorf("verifying signature of %q: %v", source, err)
+	}
+
+	objectStore, err := buildVFSPath(target)
+	if err != nil {
+		return err
+	}
+	sigTarget := objectStore + SignatureExtension
+	sigVFS, err := vfsContext.BuildVfsPath(sigTarget)
+	if err != nil {
+		return fmt.Errorf("error building path %q: %v", sigTarget, err)
+	}
+
+	return writeFile(ctx, cluster, sigVFS, signature)
+}
+
 func writeFile(ctx context.Context, cluster *kops.Cluster, p vfs.Path, data []byte) error {
 	acl, err := acls.GetACL(ctx, p, cluster)
 	if err != nil {
@@ -21,6 +21,9 @@ import (
 
 	"github.com/google/go-containerregistry/pkg/authn"
 	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
 	"github.com/google/go-containerregistry/pkg/v1/remote"
 	"github.com/google/go-containerregistry/pkg/v1/types"
 	"k8s.io/klog/v2"
@@ -32,6 +35,8 @@ type CopyImage struct {
 	Name        string
 	SourceImage string
 	TargetImage string
+	// Verifier, if set, requires the source image to be signed, and copies the signatures with the image.
+	Verifier *SignatureVerifier
 }
 
 func (e *CopyImage) Run() error {
@@ -55,6 +60,19 @@ func (e *CopyImage) Run() error {
 		return fmt.Errorf("fetching %q: %v", source, err)
 	}
 
+	if e.Verifier != nil {
+		digest, err := e.Verifier.VerifyImage(source)
+		if err != nil {
+			return err
+		}
+		if digest != desc.Digest {
+			return fmt.Errorf("image %q changed while verifying its signature", source)
+		}
+		if err := copySignatures(desc.Digest, sourceRef, targetRef, options...); err != nil {
+			return fmt.Errorf("failed to copy signatures: %v", err)
+		}
+	}
+
 	targetDesc, err := remote.Get(targetRef, options...)
 	if err == nil && desc.Digest.String() == targetDesc.Digest.String() {
 		klog.Infof("no need to copy image from %v to %v", sourceRef, targetRef)
@@ -96,3 +114,197 @@ func copyIndex(desc *remote.Descriptor, sourceRef name.Reference, targetRef name
 	}
 	return remote.WriteIndex(targetRef, idx, options...)
 }
+
+// copySignatures copies the cosign signatures of the image with the given digest,
+// so that they can still be verified once the image is copied.
+func copySignatures(digest v1.Hash, sourceRef name.Reference, targetRef name.Reference, options ...remote.Option) error {
+	sourceSigRef := signatureTag(sourceRef, digest)
+	targetSigRef := signatureTag(targetRef, digest)
+
+	klog.Infof("copying signatures from %v to %v", sourceSigRef, targetSigRef)
+
+	img, err := remote.Image(sourceSigRef, options...)
+	if err != nil {
+		return err
+	}
+	return remote.Write(targetSigRef, img, options...)
+}
+
+// ExportImage copies a docker image from a source registry to an OCI image layout,
+// typically used to carry the image into an air gap.
+type ExportImage struct {
+	Name        string
+	SourceImage string
+	// Layout is the directory of the OCI image layout holding only this image.
+	Layout string
+	// Verifier, if set, requires the source image to be signed, and exports the signatures to SignatureLayout.
+	Verifier *SignatureVerifier
+	// SignatureLayout is the directory of the OCI image layout holding the signatures of the image.
+	SignatureLayout string
+
+	// Digest is the digest of the exported image (output).
+	Digest string
+}
+
+func (e *ExportImage) Run() error {
+	sourceRef, err := name.ParseReference(e.SourceImage)
+	if err != nil {
+		return fmt.Errorf("parsing reference %q: %v", e.SourceImage, err)
+	}
+
+	options := []remote.Option{remote.WithAuthFromKeychain(authn.DefaultKeychain)}
+
+	desc, err := remote.Get(sourceRef, options...)
+	if err != nil {
+		return fmt.Errorf("fetching %q: %v", e.SourceImage, err)
+	}
+
+	if e.Verifier != nil {
+		digest, err := e.Verifier.VerifyImage(e.SourceImage)
+		if err != nil {
+			return err
+		}
+		if digest != desc.Digest {
+			return fmt.Errorf("image %q changed while verifying its signature", e.SourceImage)
+		}
+		if err := exportSignatures(desc.Digest, sourceRef, e.SignatureLayout, options...); err != nil {
+			return fmt.Errorf("failed to export signatures: %v", err)
+		}
+	}
+
+	p, err := layout.Write(e.Layout, empty.Index)
+	if err != nil {
+		return fmt.Errorf("creating image layout %q: %v", e.Layout, err)
+	}
+
+	klog.Infof("exporting image %v to %v", sourceRef, e.Layout)
+	switch desc.MediaType {
+	case types.OCIImageIndex, types.DockerManifestList:
+		idx, err := desc.ImageIndex()
+		if err != nil {
+			return err
+		}
+		if err := p.AppendIndex(idx); err != nil {
+			return fmt.Errorf("failed to export index: %v", err)
+		}
+	default:
+		img, err := desc.Image()
+		if err != nil {
+			return err
+		}
+		if err := p.AppendImage(img); err != nil {
+			return fmt.Errorf("failed to export image: %v", err)
+		}
+	}
+
+	e.Digest = desc.Digest.String()
+	return nil
+}
+
+// exportSignatures copies the cosign signatures of the image with the given digest to an OCI image layout.
+func exportSignatures(digest v1.Hash, sourceRef name.Reference, signatureLayout string, options ...remote.Option) error {
+	sourceSigRef := signatureTag(sourceRef, digest)
+
+	klog.Infof("exporting signatures %v to %v", sourceSigRef, signatureLayout)
+
+	img, err := remote.Image(sourceSigRef, options...)
+	if err != nil {
+		return err
+	}
+	p, err := layout.Write(signatureLayout, empty.Index)
+	if err != nil {
+		return fmt.Errorf("creating image layout %q: %v", signatureLayout, err)
+	}
+	return p.AppendImage(img)
+}
+
+// ImportImage copies a docker image from an OCI image layout written by ExportImage to a target registry.
+type ImportImage struct {
+	Name        string
+	Layout      string
+	TargetImage string
+	// SignatureLayout, if set, is the OCI image layout holding the signatures of the image,
+	// which are pushed to the target registry with the image.
+	SignatureLayout string
+}
+
+func (e *ImportImage) Run() error {
+	targetRef, err := name.ParseReference(e.TargetImage)
+	if err != nil {
+		return fmt.Errorf("parsing reference for %q: %v", e.TargetImage, err)
+	}
+
+	idx, err := layout.ImageIndexFromPath(e.Layout)
+	if err != nil {
+		return fmt.Errorf("reading image layout %q: %v", e.Layout, err)
+	}
+	manifest, err := idx.IndexManifest()
+	if err != nil {
+		return fmt.Errorf("reading image layout %q: %v", e.Layout, err)
+	}
+	if len(manifest.Manifests) != 1 {
+		return fmt.Errorf("expected a single image in layout %q, found %d", e.Layout, len(manifest.Manifests))
+	}
+	desc := manifest.Manifests[0]
+
+	options := []remote.Option{remote.WithAuthFromKeychain(authn.DefaultKeychain)}
+
+	if e.SignatureLayout != "" {
+		if err := importSignatures(desc.Digest, e.SignatureLayout, targetRef, options...); err != nil {
+			return fmt.Errorf("failed to import signatures: %v", err)
+		}
+	}
+
+	targetDesc, err := remote.Get(targetRef, options...)
+	if err == nil && desc.Digest.String() == targetDesc.Digest.String() {
+		klog.Infof("no need to import image from %v to %v", e.Layout, targetRef)
+		return nil
+	}
+
+	klog.Infof("importing image from %v to %v", e.Layout, targetRef)
+	switch desc.MediaType {
+	case types.OCIImageIndex, types.DockerManifestList:
+		imageIndex, err := idx.ImageIndex(desc.Digest)
+		if err != nil {
+			return err
+		}
+		if err := remote.WriteIndex(targetRef, imageIndex, options...); err != nil {
+			return fmt.Errorf("failed to import index: %v", err)
+		}
+	default:
+		img, err := idx.Image(desc.Digest)
+		if err != nil {
+			return err
+		}
+		if err := remote.Write(targetRef, img, options...); err != nil {
+			return fmt.Errorf("failed to import image: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// importSignatures pushes the cosign signatures of the image with the given digest from an OCI image layout
+// written by exportSignatures, so that they can still be verified once the image is imported.
+func importSignatures(digest v1.Hash, signatureLayout string, targetRef name.Reference, options ...remote.Option) error {
+	targetSigRef := signatureTag(targetRef, digest)
+
+	klog.Infof("importing signatures from %v to %v", signatureLayout, targetSigRef)
+
+	idx, err := layout.ImageIndexFromPath(signatureLayout)
+	if err != nil {
+		return fmt.Errorf("reading image layout %q: %v", signatureLayout, err)
+	}
+	manifest, err := idx.IndexManifest()
+	if err != nil {
+		return fmt.Errorf("reading image layout %q: %v", signatureLayout, err)
+	}
+	if len(manifest.Manifests) != 1 {
+		return fmt.Errorf("expected a single image in layout %q, found %d", signatureLayout, len(manifest.Manifests))
+	}
+	img, err := idx.Image(manifest.Manifests[0].Digest)
+	if err != nil {
+		return err
+	}
+	return remote.Write(targetSigRef, img, options...)
+}
@@ -0,0 +1,289 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package assets
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/pki"
+	"k8s.io/kops/util/pkg/hashing"
+	"k8s.io/kops/util/pkg/vfs"
+)
+
+const (
+	// SignatureExtension is the extension of the file holding the signature of a file asset,
+	// as written by "cosign sign-blob --output-signature".
+	SignatureExtension = ".sig"
+
+	// cosignSignatureAnnotation is the layer annotation holding the signature of a cosign payload.
+	cosignSignatureAnnotation = "dev.cosignproject.cosign/signature"
+)
+
+// SignatureVerifier verifies the cosign signatures of assets against a set of trusted public keys.
+type SignatureVerifier struct {
+	publicKeys []crypto.PublicKey
+
+	// Images is whether the signatures of image assets are verified.
+	Images bool
+	// Files is whether the signatures of file assets are verified.
+	Files bool
+}
+
+// NewSignatureVerifier builds a SignatureVerifier trusting the given PEM encoded public keys.
+func NewSignatureVerifier(publicKeys []string) (*SignatureVerifier, error) {
+	v := &SignatureVerifier{}
+	for _, publicKey := range publicKeys {
+		k, err := pki.ParsePEMPublicKey([]byte(publicKey))
+		if err != nil {
+			return nil, fmt.Errorf("parsing public key: %w", err)
+		}
+		if k == nil {
+			return nil, fmt.Errorf("public key not found")
+		}
+		switch k.Key.(type) {
+		case *ecdsa.PublicKey, *rsa.PublicKey:
+		default:
+			return nil, fmt.Errorf("unsupported public key type %T", k.Key)
+		}
+		v.publicKeys = append(v.publicKeys, k.Key)
+	}
+	if len(v.publicKeys) == 0 {
+		return nil, fmt.Errorf("no public keys to verify signatures")
+	}
+	return v, nil
+}
+
+// NewSignatureVerifierForCluster builds a SignatureVerifier from the signature policy of the cluster,
+// returning nil if the cluster does not require signed assets.
+func NewSignatureVerifierForCluster(cluster *kops.Cluster) (*SignatureVerifier, error) {
+	if cluster.Spec.Assets == nil || cluster.Spec.Assets.SignaturePolicy == nil {
+		return nil, nil
+	}
+	policy := cluster.Spec.Assets.SignaturePolicy
+	if !policy.Images && !policy.Files {
+		return nil, nil
+	}
+	v, err := NewSignatureVerifier(policy.PublicKeys)
+	if err != nil {
+		return nil, err
+	}
+	v.Images = policy.Images
+	v.Files = policy.Files
+	return v, nil
+}
+
+// signatureVerifiersForCluster returns the verifiers of the image and file assets of the cluster,
+// which are nil if the cluster does not require them to be signed.
+func signatureVerifiersForCluster(cluster *kops.Cluster) (imageVerifier *SignatureVerifier, fileVerifier *SignatureVerifier, err error) {
+	verifier, err := NewSignatureVerifierForCluster(cluster)
+	if err != nil {
+		return nil, nil, err
+	}
+	if verifier != nil && verifier.Images {
+		imageVerifier = verifier
+	}
+	if verifier != nil && verifier.Files {
+		fileVerifier = verifier
+	}
+	return imageVerifier, fileVerifier, nil
+}
+
+// verifyDigest checks that the signature of the SHA256 digest was made by one of the trusted keys.
+func (v *SignatureVerifier) verifyDigest(digest []byte, signature []byte) error {
+	for _, publicKey := range v.publicKeys {
+		switch k := publicKey.(type) {
+		case *ecdsa.PublicKey:
+			if ecdsa.VerifyASN1(k, digest, signature) {
+				return nil
+			}
+		case *rsa.PublicKey:
+			if rsa.VerifyPKCS1v15(k, crypto.SHA256, digest, signature) == nil {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("signature not made by any of the trusted keys")
+}
+
+// checkSignedHash checks that the signature of a file with the given hash can be verified.
+// Only SHA256 hashes can be verified, as that is what cosign signs; files whose publishers only
+// publish other hashes (e.g. the sha512 hashes of gVisor) can't be used with a signature policy.
+func checkSignedHash(hash *hashing.Hash) error {
+	if hash == nil {
+		return fmt.Errorf("a sha256 hash is required to verify the signature")
+	}
+	if hash.Algorithm != hashing.HashAlgorithmSHA256 {
+		return fmt.Errorf("a sha256 hash is required to verify the signature, but only a %s hash is known", hash.Algorithm)
+	}
+	return nil
+}
+
+// VerifyFileSignature checks the base64 encoded signature of a file with the given hash.
+// Only SHA256 hashes can be verified, as that is what cosign signs.
+func (v *SignatureVerifier) VerifyFileSignature(hash *hashing.Hash, signature []byte) error {
+	if err := checkSignedHash(hash); err != nil {
+		return err
+	}
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(signature)))
+	if err != nil {
+		return fmt.Errorf("decoding signature: %w", err)
+	}
+	return v.verifyDigest(hash.HashValue, sig)
+}
+
+// VerifyFile checks the signature stored next to the file, with the SignatureExtension.
+func (v *SignatureVerifier) VerifyFile(vfsContext *vfs.VFSContext, file string, hash *hashing.Hash) error {
+	if err := checkSignedHash(hash); err != nil {
+		return fmt.Errorf("verifying signature of %q: %w", file, err)
+	}
+	signature, err := vfsContext.ReadFile(file + SignatureExtension)
+	if err != nil {
+		return fmt.Errorf("reading signature of %q: %w", file, err)
+	}
+	if err := v.VerifyFileSignature(hash, signature); err != nil {
+		return fmt.Errorf("verifying signature of %q: %w", file, err)
+	}
+	return nil
+}
+
+// cosignPayload is the part of the cosign simple signing payload that we check.
+type cosignPayload struct {
+	Critical struct {
+		Image struct {
+			DockerManifestDigest string `json:"docker-manifest-digest"`
+		} `json:"image"`
+	} `json:"critical"`
+}
+
+// signatureTag returns the tag where cosign stores the signatures of the image with the given digest.
+func signatureTag(ref name.Reference, digest v1.Hash) name.Tag {
+	return ref.Context().Tag(digest.Algorithm + "-" + digest.Hex + SignatureExtension)
+}
+
+// VerifyImage checks that the image has a cosign signature made by one of the trusted keys,
+// returning the digest of the image.
+func (v *SignatureVerifier) VerifyImage(image string) (v1.Hash, error) {
+	ref, err := name.ParseReference(image)
+	if err != nil {
+		return v1.Hash{}, fmt.Errorf("parsing reference %q: %w", image, err)
+	}
+
+	options := []remote.Option{remote.WithAuthFromKeychain(authn.DefaultKeychain)}
+
+	desc, err := remote.Head(ref, options...)
+	if err != nil {
+		return v1.Hash{}, fmt.Errorf("fetching %q: %w", image, err)
+	}
+
+	sigRef := signatureTag(ref, desc.Digest)
+	sigImage, err := remote.Image(sigRef, options...)
+	if err != nil {
+		return v1.Hash{}, fmt.Errorf("fetching signatures of %q from %q: %w", image, sigRef, err)
+	}
+	manifest, err := sigImage.Manifest()
+	if err != nil {
+		return v1.Hash{}, fmt.Errorf("reading signatures of %q: %w", image, err)
+	}
+
+	for _, layer := range manifest.Layers {
+		signature, ok := layer.Annotations[cosignSignatureAnnotation]
+		if !ok {
+			continue
+		}
+		sig, err := base64.StdEncoding.DecodeString(signature)
+		if err != nil {
+			continue
+		}
+		l, err := sigImage.LayerByDigest(layer.Digest)
+		if err != nil {
+			return v1.Hash{}, fmt.Errorf("reading signatures of %q: %w", image, err)
+		}
+		payload, err := readLayer(l)
+		if err != nil {
+			return v1.Hash{}, fmt.Errorf("reading signatures of %q: %w", image, err)
+		}
+		digest := sha256.Sum256(payload)
+		if err := v.verifyDigest(digest[:], sig); err != nil {
+			continue
+		}
+		if err := checkCosignPayload(payload, desc.Digest); err != nil {
+			continue
+		}
+		return desc.Digest, nil
+	}
+
+	return v1.Hash{}, fmt.Errorf("no valid signature of %q (%s) found in %q", image, desc.Digest, sigRef)
+}
+
+// checkCosignPayload checks that a signed payload refers to the image with the given digest.
+func checkCosignPayload(payload []byte, digest v1.Hash) error {
+	var p cosignPayload
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return fmt.Errorf("parsing signature payload: %w", err)
+	}
+	if p.Critical.Image.DockerManifestDigest != digest.String() {
+		return fmt.Errorf("signature is for %q, not %q", p.Critical.Image.DockerManifestDigest, digest)
+	}
+	return nil
+}
+
+func readLayer(l v1.Layer) ([]byte, error) {
+	r, err := l.Compressed()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	var b bytes.Buffer
+	if _, err := io.Copy(&b, r); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// VerifyFileAssets checks the signatures of the file assets as they will be downloaded by the cluster.
+// Image assets are verified as they are remapped, so that they are pinned to the verified digest.
+func (v *SignatureVerifier) VerifyFileAssets(vfsContext *vfs.VFSContext, fileAssets []*FileAsset) error {
+	var errs []error
+	seen := map[string]bool{}
+	for _, fileAsset := range fileAssets {
+		u := fileAsset.DownloadURL.String()
+		if seen[u] {
+			continue
+		}
+		seen[u] = true
+		if err := v.VerifyFile(vfsContext, u, fileAsset.SHAValue); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,177 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package assets
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"log"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"k8s.io/kops/util/pkg/hashing"
+)
+
+func encodePublicKey(t *testing.T, key crypto.PublicKey) string {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+}
+
+// newTestRegistry starts an in-memory registry, returning its host.
+func newTestRegistry(t *testing.T) string {
+	server := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	t.Cleanup(server.Close)
+	return strings.TrimPrefix(server.URL, "http://")
+}
+
+// pushSignedImage pushes a random image to the tag, with a cosign signature made by the signer,
+// returning the digest of the image.
+func pushSignedImage(t *testing.T, tag string, signer crypto.Signer) v1.Hash {
+	ref, err := name.NewTag(tag)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	image, err := random.Image(64, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := remote.Write(ref, image); err != nil {
+		t.Fatalf("unexpected error pushing %q: %v", ref, err)
+	}
+	digest, err := image.Digest()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	payload := []byte(fmt.Sprintf(`{"critical":{"identity":{"docker-reference":%q},"image":{"docker-manifest-digest":%q},"type":"cosign container image signature"},"optional":null}`, ref.Context().Name(), digest))
+	payloadDigest := sha256.Sum256(payload)
+	sig, err := signer.Sign(rand.Reader, payloadDigest[:], crypto.SHA256)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sigImage, err := mutate.Append(empty.Image, mutate.Addendum{
+		Layer: static.NewLayer(payload, types.MediaType("application/vnd.dev.cosign.simplesigning.v1+json")),
+		Annotations: map[string]string{
+			cosignSignatureAnnotation: base64.StdEncoding.EncodeToString(sig),
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := remote.Write(signatureTag(ref, digest), sigImage); err != nil {
+		t.Fatalf("unexpected error pushing signature of %q: %v", ref, err)
+	}
+	return digest
+}
+
+func TestVerifyFileSignature(t *testing.T) {
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	untrustedKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	verifier, err := NewSignatureVerifier([]string{encodePublicKey(t, &ecdsaKey.PublicKey), encodePublicKey(t, &rsaKey.PublicKey)})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data := []byte("kubelet binary")
+	digest := sha256.Sum256(data)
+	hash := &hashing.Hash{Algorithm: hashing.HashAlgorithmSHA256, HashValue: digest[:]}
+
+	sign := func(signer crypto.Signer) []byte {
+		sig, err := signer.Sign(rand.Reader, digest[:], crypto.SHA256)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return []byte(base64.StdEncoding.EncodeToString(sig) + "\n")
+	}
+
+	if err := verifier.VerifyFileSignature(hash, sign(ecdsaKey)); err != nil {
+		t.Errorf("expected ECDSA signature to be valid: %v", err)
+	}
+	if err := verifier.VerifyFileSignature(hash, sign(rsaKey)); err != nil {
+		t.Errorf("expected RSA signature to be valid: %v", err)
+	}
+	if err := verifier.VerifyFileSignature(hash, sign(untrustedKey)); err == nil {
+		t.Errorf("expected signature by untrusted key to be rejected")
+	}
+
+	otherDigest := sha256.Sum256([]byte("other binary"))
+	otherHash := &hashing.Hash{Algorithm: hashing.HashAlgorithmSHA256, HashValue: otherDigest[:]}
+	if err := verifier.VerifyFileSignature(otherHash, sign(ecdsaKey)); err == nil {
+		t.Errorf("expected signature of other file to be rejected")
+	}
+
+	sha1Hash := &hashing.Hash{Algorithm: hashing.HashAlgorithmSHA1, HashValue: digest[:20]}
+	if err := verifier.VerifyFileSignature(sha1Hash, sign(ecdsaKey)); err == nil {
+		t.Errorf("expected sha1 hash to be rejected")
+	}
+
+	sha512Hash, err := hashing.HashAlgorithmSHA512.Hash(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := verifier.VerifyFileSignature(sha512Hash, sign(ecdsaKey)); err == nil || !strings.Contains(err.Error(), "sha512") {
+		t.Errorf("expected sha512 hash to be rejected, got %v", err)
+	}
+}
+
+func TestCheckCosignPayload(t *testing.T) {
+	digest := v1.Hash{Algorithm: "sha256", Hex: "9b2b22b1e0a0e6a8bd9bb1b0e2c9e4d2b1aa0b3c3d0f6c7a2e5e7f0a1b2c3d4e"}
+
+	payload := []byte(`{"critical":{"identity":{"docker-reference":"registry.k8s.io/kube-apiserver"},"image":{"docker-manifest-digest":"sha256:9b2b22b1e0a0e6a8bd9bb1b0e2c9e4d2b1aa0b3c3d0f6c7a2e5e7f0a1b2c3d4e"},"type":"cosign container image signature"},"optional":null}`)
+	if err := checkCosignPayload(payload, digest); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	other := []byte(`{"critical":{"image":{"docker-manifest-digest":"sha256:0000000000000000000000000000000000000000000000000000000000000000"}}}`)
+	if err := checkCosignPayload(other, digest); err == nil {
+		t.Errorf("expected payload for another image to be rejected")
+	}
+}
@@ -45,6 +45,9 @@ type VerifyResult struct {
 	// This should be sourced from e.g. the cloud, and acts as a cross-check
 	// that this is the correct instance.
 	ChallengeEndpoint string
+
+	// Verifier is the name of the verifier that verified the node.
+	Verifier string
 }
 
 // Verifier verifies authentication credentials for requests.
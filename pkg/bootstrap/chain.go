@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"strings"
 
 	"k8s.io/klog/v2"
 )
@@ -39,6 +40,9 @@ func (v *ChainVerifier) VerifyToken(ctx context.Context, rawRequest *http.Reques
 	for _, verifier := range v.chain {
 		result, err := verifier.VerifyToken(ctx, rawRequest, token, body)
 		if err == nil {
+			if result.Verifier == "" {
+				result.Verifier = strings.TrimPrefix(fmt.Sprintf("%T", verifier), "*")
+			}
 			return result, nil
 		}
 		if err == ErrNotThisVerifier {
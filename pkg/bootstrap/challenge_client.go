@@ -64,9 +64,7 @@ func (c *ChallengeClient) getClientCertificate(ctx context.Context, clusterName
 	return clientCertificate, nil
 }
 
-func (c *ChallengeClient) DoCallbackChallenge(ctx context.Context, clusterName string, targetEndpoint string, bootstrapRequest *nodeup.BootstrapRequest) error {
-	challenge := bootstrapRequest.Challenge
-
+func (c *ChallengeClient) DoCallbackChallenge(ctx context.Context, clusterName string, targetEndpoint string, challenge *nodeup.ChallengeRequest) error {
 	if challenge == nil {
 		return fmt.Errorf("challenge not set")
 	}
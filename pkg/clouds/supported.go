@@ -39,6 +39,9 @@ func SupportedClouds() []kops.CloudProviderID {
 	if featureflag.Scaleway.Enabled() {
 		clouds = append(clouds, kops.CloudProviderScaleway)
 	}
+	if featureflag.Metal.Enabled() {
+		clouds = append(clouds, kops.CloudProviderMetal)
+	}
 
 	return clouds
 }
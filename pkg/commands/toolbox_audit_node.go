@@ -0,0 +1,100 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"k8s.io/kops/pkg/apis/nodeup"
+)
+
+type ToolboxAuditNodeOptions struct {
+	Host string
+
+	SSHUser string
+	SSHPort int
+
+	// UpdateNode sets the KopsConfigurationDrift condition of the node.
+	UpdateNode bool
+}
+
+func (o *ToolboxAuditNodeOptions) InitDefaults() {
+	o.SSHUser = "root"
+	o.SSHPort = 22
+}
+
+// RunToolboxAuditNode runs nodeup on a node in audit mode, and prints the report.
+func RunToolboxAuditNode(ctx context.Context, out io.Writer, options *ToolboxAuditNodeOptions) error {
+	if options.Host == "" {
+		return fmt.Errorf("host is required")
+	}
+
+	sudo := true
+	if options.SSHUser == "root" {
+		sudo = false
+	}
+
+	host, err := NewSSHHost(ctx, options.Host, options.SSHPort, options.SSHUser, sudo)
+	if err != nil {
+		return err
+	}
+	defer host.Close()
+
+	script := scriptAuditNode
+	if options.UpdateNode {
+		script += " --audit-update-node"
+	}
+	script += "\n"
+
+	if _, err := host.runScript(ctx, script, ExecOptions{Sudo: sudo, Echo: false}); err != nil {
+		return fmt.Errorf("error auditing node: %w", err)
+	}
+
+	b, err := host.readFile(ctx, nodeup.NodeAuditReportPath)
+	if err != nil {
+		return fmt.Errorf("error reading audit report %q: %w", nodeup.NodeAuditReportPath, err)
+	}
+
+	report := &nodeup.NodeAuditReport{}
+	if err := json.Unmarshal(b, report); err != nil {
+		return fmt.Errorf("error parsing audit report: %w", err)
+	}
+
+	b, err = json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error serializing audit report: %w", err)
+	}
+	_, err = fmt.Fprintf(out, "%s\n", b)
+	return err
+}
+
+// scriptAuditNode runs nodeup from the location used by the bootstrap script.
+const scriptAuditNode = `#!/bin/bash
+set -o errexit
+set -o nounset
+set -o pipefail
+
+INSTALL_DIR="/opt/kops"
+# On ContainerOS, we install under /var/lib/toolbox; /opt is ro and noexec
+if [[ -d /var/lib/toolbox ]]; then
+  INSTALL_DIR="/var/lib/toolbox/kops"
+fi
+
+${INSTALL_DIR}/bin/nodeup --conf=${INSTALL_DIR}/conf/kube_env.yaml --audit --audit-output=` + nodeup.NodeAuditReportPath + ` --retries=0 --v=1`
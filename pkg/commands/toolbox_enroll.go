@@ -31,14 +31,21 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"golang.org/x/crypto/ssh"
 	"golang.org/x/crypto/ssh/agent"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/klog/v2"
+	"k8s.io/kubectl/pkg/drain"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"k8s.io/kops/pkg/apis/kops"
@@ -64,6 +71,16 @@ type ToolboxEnrollOptions struct {
 
 	Host string
 
+	// Inventory is a file listing the hosts to enroll, by instance group.
+	Inventory string
+
+	// Reinstall drains the hosts that are already nodes, and reinstalls them one at a time.
+	Reinstall bool
+	// DrainTimeout is the maximum time to wait while draining a node.
+	DrainTimeout time.Duration
+	// ReadyTimeout is the maximum time to wait for a reinstalled node to be ready.
+	ReadyTimeout time.Duration
+
 	SSHUser string
 	SSHPort int
 }
@@ -71,6 +88,8 @@ type ToolboxEnrollOptions struct {
 func (o *ToolboxEnrollOptions) InitDefaults() {
 	o.SSHUser = "root"
 	o.SSHPort = 22
+	o.DrainTimeout = 15 * time.Minute
+	o.ReadyTimeout = 15 * time.Minute
 }
 
 func RunToolboxEnroll(ctx context.Context, f commandutils.Factory, out io.Writer, options *ToolboxEnrollOptions) error {
@@ -80,9 +99,26 @@ func RunToolboxEnroll(ctx context.Context, f commandutils.Factory, out io.Writer
 	if options.ClusterName == "" {
 		return fmt.Errorf("cluster is required")
 	}
-	if options.InstanceGroup == "" {
+	if options.Inventory != "" {
+		if options.InstanceGroup != "" || options.Host != "" {
+			return fmt.Errorf("inventory cannot be combined with instance-group or host")
+		}
+	} else if options.InstanceGroup == "" {
 		return fmt.Errorf("instance-group is required")
 	}
+	if options.Reinstall && options.Inventory == "" && options.Host == "" {
+		return fmt.Errorf("reinstall requires host or inventory")
+	}
+
+	var inventory *HostInventory
+	if options.Inventory != "" {
+		var err error
+		inventory, err = LoadHostInventory(options.Inventory)
+		if err != nil {
+			return err
+		}
+	}
+
 	clientset, err := f.KopsClient()
 	if err != nil {
 		return err
@@ -97,9 +133,17 @@ func RunToolboxEnroll(ctx context.Context, f commandutils.Factory, out io.Writer
 		return fmt.Errorf("cluster not found %q", options.ClusterName)
 	}
 
-	ig, err := clientset.InstanceGroupsFor(cluster).Get(ctx, options.InstanceGroup, metav1.GetOptions{})
-	if err != nil {
-		return err
+	var restConfig *rest.Config
+	if options.Host != "" || inventory != nil {
+		// TODO: This is the pattern we use a lot, but should we try to access it directly?
+		contextName := cluster.ObjectMeta.Name
+		clientGetter := genericclioptions.NewConfigFlags(true)
+		clientGetter.Context = &contextName
+
+		restConfig, err = clientGetter.ToRESTConfig()
+		if err != nil {
+			return fmt.Errorf("cannot load kubecfg settings for %q: %w", contextName, err)
+		}
 	}
 
 	cloud, err := cloudup.BuildCloud(cluster)
@@ -107,58 +151,156 @@ func RunToolboxEnroll(ctx context.Context, f commandutils.Factory, out io.Writer
 		return err
 	}
 
+	// Find the instance groups first, so that a mistake is found before any host is changed
+	igs := make(map[string]*kops.InstanceGroup)
+	if inventory == nil {
+		ig, err := clientset.InstanceGroupsFor(cluster).Get(ctx, options.InstanceGroup, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		igs[ig.Name] = ig
+	} else {
+		for _, group := range inventory.InstanceGroups {
+			ig, err := clientset.InstanceGroupsFor(cluster).Get(ctx, group.Name, metav1.GetOptions{})
+			if err != nil {
+				return fmt.Errorf("getting instance group %q: %w", group.Name, err)
+			}
+			igs[ig.Name] = ig
+		}
+	}
+	for _, ig := range igs {
+		if err := checkEnrollable(cluster, ig); err != nil {
+			return err
+		}
+	}
+
 	wellKnownAddresses := make(model.WellKnownAddresses)
 
-	{
-		ingresses, err := cloud.GetApiIngressStatus(cluster)
+	var controlPlaneHosts []string
+	if inventory != nil {
+		controlPlaneHosts = inventory.controlPlaneAddresses(igs)
+	} else if options.Host != "" && igs[options.InstanceGroup].HasAPIServer() {
+		controlPlaneHosts = []string{options.Host}
+	}
+
+	apiServerAddresses, err := findAPIServerAddresses(ctx, cloud, cluster, restConfig, controlPlaneHosts)
+	if err != nil {
+		return err
+	}
+	wellKnownAddresses[wellknownservices.KubeAPIServer] = apiServerAddresses
+
+	if inventory == nil {
+		ig := igs[options.InstanceGroup]
+		scriptBytes, err := buildBootstrapData(ctx, clientset, cluster, ig, wellKnownAddresses)
 		if err != nil {
-			return fmt.Errorf("error getting ingress status: %v", err)
+			return err
 		}
 
-		for _, ingress := range ingresses {
-			// TODO: Do we need to support hostnames?
-			// if ingress.Hostname != "" {
-			// 	apiserverAdditionalIPs = append(apiserverAdditionalIPs, ingress.Hostname)
-			// }
-			if ingress.IP != "" {
-				wellKnownAddresses[wellknownservices.KubeAPIServer] = append(wellKnownAddresses[wellknownservices.KubeAPIServer], ingress.IP)
+		if options.Host != "" {
+			if err := enrollHost(ctx, options, ig, string(scriptBytes), restConfig); err != nil {
+				return err
 			}
 		}
+		return nil
 	}
 
-	if len(wellKnownAddresses[wellknownservices.KubeAPIServer]) == 0 {
-		// TODO: Should we support DNS?
-		return fmt.Errorf("unable to determine IP address for kube-apiserver")
+	// Build all the scripts first, so that a mistake in the inventory is found before any host is changed
+	scripts := make(map[string][]byte)
+	for _, group := range inventory.InstanceGroups {
+		scriptBytes, err := buildBootstrapData(ctx, clientset, cluster, igs[group.Name], wellKnownAddresses)
+		if err != nil {
+			return err
+		}
+		scripts[group.Name] = scriptBytes
+	}
+
+	// Hosts are enrolled one at a time, so that reinstalling them is a rolling update.
+	// The control plane is enrolled first, because nodes join through it.
+	for _, group := range inventory.enrollOrder(igs) {
+		for _, host := range group.Hosts {
+			hostOptions := *options
+			hostOptions.InstanceGroup = group.Name
+			hostOptions.Host = host.Address
+			hostOptions.SSHUser, hostOptions.SSHPort = group.sshOptions(host, options.SSHUser, options.SSHPort)
+
+			fmt.Fprintf(out, "Enrolling host %q in instance group %q\n", host.Address, group.Name)
+			if err := enrollHost(ctx, &hostOptions, igs[group.Name], string(scripts[group.Name]), restConfig); err != nil {
+				return fmt.Errorf("enrolling host %q: %w", host.Address, err)
+			}
+		}
 	}
+	return nil
+}
 
-	for k := range wellKnownAddresses {
-		sort.Strings(wellKnownAddresses[k])
+// checkEnrollable returns an error for the instance groups whose hosts cannot be enrolled.
+func checkEnrollable(cluster *kops.Cluster, ig *kops.InstanceGroup) error {
+	// Hosts with an API server read the state store, and control-plane hosts also need etcd volumes.
+	// Clusters on bare metal provide both, but hybrid clusters leave them to the cloud provider.
+	if ig.HasAPIServer() && cluster.Spec.GetCloudProvider() != kops.CloudProviderMetal {
+		return fmt.Errorf("cannot enroll hosts in instance group %q with role %q: only nodes can be enrolled in a cluster on cloud provider %q", ig.Name, ig.Spec.Role, cluster.Spec.GetCloudProvider())
 	}
+	return nil
+}
 
-	scriptBytes, err := buildBootstrapData(ctx, clientset, cluster, ig, wellKnownAddresses)
+// findAPIServerAddresses returns the addresses that enrolled hosts reach kube-apiserver on.
+// These are the addresses of the API load balancer or control-plane instances reported by the cloud,
+// or else those of the control-plane hosts being enrolled,
+// or else those of the api-internal endpoints that the hosts controller of kops-controller publishes.
+func findAPIServerAddresses(ctx context.Context, cloud fi.Cloud, cluster *kops.Cluster, restConfig *rest.Config, controlPlaneHosts []string) ([]string, error) {
+	var addresses []string
+
+	ingresses, err := cloud.GetApiIngressStatus(cluster)
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("error getting ingress status: %v", err)
 	}
 
-	if options.Host != "" {
-		// TODO: This is the pattern we use a lot, but should we try to access it directly?
-		contextName := cluster.ObjectMeta.Name
-		clientGetter := genericclioptions.NewConfigFlags(true)
-		clientGetter.Context = &contextName
+	for _, ingress := range ingresses {
+		// TODO: Do we need to support hostnames?
+		// if ingress.Hostname != "" {
+		// 	apiserverAdditionalIPs = append(apiserverAdditionalIPs, ingress.Hostname)
+		// }
+		if ingress.IP != "" {
+			addresses = append(addresses, ingress.IP)
+		}
+	}
 
-		restConfig, err := clientGetter.ToRESTConfig()
-		if err != nil {
-			return fmt.Errorf("cannot load kubecfg settings for %q: %w", contextName, err)
+	if len(addresses) == 0 {
+		// The API is not up before the first control-plane host is enrolled, so use the addresses we SSH to
+		for _, host := range controlPlaneHosts {
+			if net.ParseIP(host) != nil {
+				addresses = append(addresses, host)
+			}
 		}
+	}
 
-		if err := enrollHost(ctx, options, string(scriptBytes), restConfig); err != nil {
-			return err
+	if len(addresses) == 0 && restConfig != nil {
+		kubeClient, err := kubernetes.NewForConfig(restConfig)
+		if err != nil {
+			return nil, fmt.Errorf("building kubernetes client: %w", err)
+		}
+		endpoints, err := kubeClient.CoreV1().Endpoints("kube-system").Get(ctx, "api-internal", metav1.GetOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("getting api-internal endpoints: %w", err)
+		}
+		if err == nil {
+			for _, subset := range endpoints.Subsets {
+				for _, address := range subset.Addresses {
+					addresses = append(addresses, address.IP)
+				}
+			}
 		}
 	}
-	return nil
+
+	if len(addresses) == 0 {
+		// TODO: Should we support DNS?
+		return nil, fmt.Errorf("unable to determine IP address for kube-apiserver")
+	}
+
+	sort.Strings(addresses)
+	return addresses, nil
 }
 
-func enrollHost(ctx context.Context, options *ToolboxEnrollOptions, nodeupScript string, restConfig *rest.Config) error {
+func enrollHost(ctx context.Context, options *ToolboxEnrollOptions, ig *kops.InstanceGroup, nodeupScript string, restConfig *rest.Config) error {
 	scheme := runtime.NewScheme()
 	if err := v1alpha2.AddToScheme(scheme); err != nil {
 		return fmt.Errorf("building kubernetes scheme: %w", err)
@@ -169,6 +311,10 @@ func enrollHost(ctx context.Context, options *ToolboxEnrollOptions, nodeupScript
 	if err != nil {
 		return fmt.Errorf("building kubernetes client: %w", err)
 	}
+	k8sClient, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return fmt.Errorf("building kubernetes client: %w", err)
+	}
 
 	sudo := true
 	if options.SSHUser == "root" {
@@ -181,6 +327,10 @@ func enrollHost(ctx context.Context, options *ToolboxEnrollOptions, nodeupScript
 	}
 	defer host.Close()
 
+	if ig.HasAPIServer() {
+		return enrollControlPlaneHost(ctx, options, host, nodeupScript, k8sClient)
+	}
+
 	publicKeyPath := "/etc/kubernetes/kops/pki/machine/public.pem"
 
 	publicKeyBytes, err := host.readFile(ctx, publicKeyPath)
@@ -215,11 +365,128 @@ func enrollHost(ctx context.Context, options *ToolboxEnrollOptions, nodeupScript
 		return err
 	}
 
+	node, err := k8sClient.CoreV1().Nodes().Get(ctx, hostname, metav1.GetOptions{})
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return fmt.Errorf("getting node %q: %w", hostname, err)
+		}
+		node = nil
+	}
+	if node != nil {
+		if !options.Reinstall {
+			klog.Infof("host %q is already node %q; skipping", options.Host, hostname)
+			return nil
+		}
+		if err := removeNode(ctx, options, host, k8sClient, node); err != nil {
+			return err
+		}
+	}
+
 	if len(nodeupScript) != 0 {
 		if _, err := host.runScript(ctx, nodeupScript, ExecOptions{Sudo: sudo, Echo: true}); err != nil {
 			return err
 		}
 	}
+
+	if options.Reinstall {
+		return waitForNodeReady(ctx, k8sClient, hostname, options.ReadyTimeout)
+	}
+	return nil
+}
+
+// enrollControlPlaneHost installs a control-plane host.
+// Control-plane hosts read their configuration from the state store and are not verified by kops-controller,
+// so they need neither a machine key nor a Host; they may also be installing the API that would store it.
+func enrollControlPlaneHost(ctx context.Context, options *ToolboxEnrollOptions, host *SSHHost, nodeupScript string, k8sClient kubernetes.Interface) error {
+	manifestPath := "/etc/kubernetes/manifests/kube-apiserver.manifest"
+
+	installed := true
+	if _, err := host.readFile(ctx, manifestPath); err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("error reading %q: %w", manifestPath, err)
+		}
+		installed = false
+	}
+	if installed && !options.Reinstall {
+		klog.Infof("host %q already runs the control plane; skipping", options.Host)
+		return nil
+	}
+
+	hostname, err := host.getHostname(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Control-plane hosts are reinstalled in place: draining would evict the control plane they run
+	if len(nodeupScript) != 0 {
+		if _, err := host.runScript(ctx, nodeupScript, ExecOptions{Sudo: host.sudo, Echo: true}); err != nil {
+			return err
+		}
+	}
+
+	// Wait even on a first install, so that the hosts enrolled next can join through this one
+	return waitForNodeReady(ctx, k8sClient, hostname, options.ReadyTimeout)
+}
+
+// removeNode drains the node of a host and deletes it, so that kops-controller bootstraps the host again when it is reinstalled.
+func removeNode(ctx context.Context, options *ToolboxEnrollOptions, host *SSHHost, k8sClient kubernetes.Interface, node *corev1.Node) error {
+	helper := &drain.Helper{
+		Ctx:                 ctx,
+		Client:              k8sClient,
+		Force:               true,
+		GracePeriodSeconds:  -1,
+		IgnoreAllDaemonSets: true,
+		Out:                 os.Stdout,
+		ErrOut:              os.Stderr,
+		Timeout:             options.DrainTimeout,
+
+		// We want to proceed even when pods are using emptyDir volumes
+		DeleteEmptyDirData: true,
+	}
+
+	klog.Infof("draining node %q", node.Name)
+	if err := drain.RunCordonOrUncordon(helper, node, true); err != nil {
+		return fmt.Errorf("error cordoning node %q: %w", node.Name, err)
+	}
+	if err := drain.RunNodeDrain(helper, node.Name); err != nil {
+		return fmt.Errorf("error draining node %q: %w", node.Name, err)
+	}
+
+	// Stop the kubelet, so that it does not register the node again before it is reinstalled
+	if _, err := host.runCommand(ctx, "systemctl stop kubelet", ExecOptions{Sudo: host.sudo, Echo: true}); err != nil {
+		return err
+	}
+
+	klog.Infof("deleting node %q", node.Name)
+	if err := k8sClient.CoreV1().Nodes().Delete(ctx, node.Name, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("error deleting node %q: %w", node.Name, err)
+	}
+	return nil
+}
+
+// waitForNodeReady waits for a reinstalled host to register as a ready node.
+func waitForNodeReady(ctx context.Context, k8sClient kubernetes.Interface, nodeName string, timeout time.Duration) error {
+	klog.Infof("waiting for node %q to be ready", nodeName)
+	err := wait.PollUntilContextTimeout(ctx, 10*time.Second, timeout, true, func(ctx context.Context) (bool, error) {
+		node, err := k8sClient.CoreV1().Nodes().Get(ctx, nodeName, metav1.GetOptions{})
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				return false, nil
+			}
+			klog.Warningf("error getting node %q: %v", nodeName, err)
+			return false, nil
+		}
+		for _, condition := range node.Status.Conditions {
+			if condition.Type == corev1.NodeReady && condition.Status == corev1.ConditionTrue {
+				return true, nil
+			}
+		}
+		return false, nil
+	})
+	if err != nil {
+		return fmt.Errorf("node %q did not become ready within %s: %w", nodeName, timeout, err)
+	}
+	klog.Infof("node %q is ready", nodeName)
 	return nil
 }
 
@@ -231,7 +498,18 @@ func createHost(ctx context.Context, options *ToolboxEnrollOptions, nodeName str
 	host.Spec.PublicKey = string(publicKey)
 
 	if err := client.Create(ctx, host); err != nil {
-		return fmt.Errorf("failed to create host %s/%s: %w", host.Namespace, host.Name, err)
+		if !apierrors.IsAlreadyExists(err) {
+			return fmt.Errorf("failed to create host %s/%s: %w", host.Namespace, host.Name, err)
+		}
+
+		// Enrolling again is fine, as long as the host keeps its key and instance group
+		existing := &v1alpha2.Host{}
+		if err := client.Get(ctx, types.NamespacedName{Namespace: host.Namespace, Name: host.Name}, existing); err != nil {
+			return fmt.Errorf("failed to get host %s/%s: %w", host.Namespace, host.Name, err)
+		}
+		if existing.Spec.InstanceGroup != host.Spec.InstanceGroup || strings.TrimSpace(existing.Spec.PublicKey) != strings.TrimSpace(host.Spec.PublicKey) {
+			return fmt.Errorf("host %s/%s is already enrolled with another key or instance group; delete it first", host.Namespace, host.Name)
+		}
 	}
 
 	return nil
@@ -433,7 +711,7 @@ func buildBootstrapData(ctx context.Context, clientset simple.Clientset, cluster
 	}
 
 	assets := make(map[architectures.Architecture][]*assets.MirroredAsset)
-	configBuilder, err := nodemodel.NewNodeUpConfigBuilder(cluster, assetBuilder, assets, encryptionConfigSecretHash)
+	configBuilder, err := nodemodel.NewNodeUpConfigBuilder(cluster, assetBuilder, assets, encryptionConfigSecretHash, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -445,7 +723,20 @@ func buildBootstrapData(ctx context.Context, clientset simple.Clientset, cluster
 		return nil, err
 	}
 
-	for _, keyName := range []string{"kubernetes-ca"} {
+	keyNames := []string{"kubernetes-ca"}
+	if ig.HasAPIServer() {
+		// These are the CAs that BootstrapScriptBuilder passes to control-plane instances
+		keyNames = append(keyNames, "etcd-clients-ca", "apiserver-aggregator-ca", "service-account")
+		for _, etcdCluster := range cluster.Spec.EtcdClusters {
+			k := etcdCluster.Name
+			keyNames = append(keyNames, "etcd-manager-ca-"+k, "etcd-peers-ca-"+k)
+			if k != "events" && k != "main" {
+				keyNames = append(keyNames, "etcd-clients-ca-"+k)
+			}
+		}
+	}
+
+	for _, keyName := range keyNames {
 		keyset, err := keystore.FindKeyset(ctx, keyName)
 		if err != nil {
 			return nil, fmt.Errorf("getting keyset %q: %w", keyName, err)
@@ -465,6 +756,11 @@ func buildBootstrapData(ctx context.Context, clientset simple.Clientset, cluster
 
 	bootConfig.CloudProvider = "metal"
 
+	// Without a load balancer, hosts reach the control plane directly, with the internal names in /etc/hosts
+	if cluster.Spec.API.LoadBalancer == nil && len(bootConfig.APIServerIPs) == 0 {
+		bootConfig.APIServerIPs = wellknownAddresses[wellknownservices.KubeAPIServer]
+	}
+
 	// TODO: Should we / can we specify the node config hash?
 	// configData, err := utils.YamlMarshal(config)
 	// if err != nil {
@@ -487,6 +783,13 @@ func buildBootstrapData(ctx context.Context, clientset simple.Clientset, cluster
 			// 	return "", err
 			// }
 
+			// Control-plane hosts read the state store, which on bare metal is an S3-compatible store
+			if ig.HasAPIServer() {
+				for k, v := range model.StateStoreEnvironmentVariables() {
+					env[k] = v
+				}
+			}
+
 			// Sort keys to have a stable sequence of "export xx=xxx"" statements
 			var keys []string
 			for k := range env {
@@ -0,0 +1,144 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"k8s.io/kops/pkg/apis/kops"
+	"sigs.k8s.io/yaml"
+)
+
+// HostInventory is a declarative list of the bare-metal hosts of a cluster, by instance group.
+type HostInventory struct {
+	// InstanceGroups are the hosts to enroll, by instance group.
+	InstanceGroups []InventoryInstanceGroup `json:"instanceGroups"`
+}
+
+// InventoryInstanceGroup lists the hosts of an instance group.
+type InventoryInstanceGroup struct {
+	// Name is the name of the instance group.
+	Name string `json:"name"`
+	// SSHUser is the user to SSH as, for the hosts that don't set one. Defaults to the --ssh-user flag.
+	SSHUser string `json:"sshUser,omitempty"`
+	// SSHPort is the SSH port, for the hosts that don't set one. Defaults to the --ssh-port flag.
+	SSHPort int `json:"sshPort,omitempty"`
+	// Hosts are the hosts of the instance group.
+	Hosts []InventoryHost `json:"hosts"`
+}
+
+// InventoryHost is a host to enroll.
+type InventoryHost struct {
+	// Address is the IP address or hostname used to SSH to the host.
+	Address string `json:"address"`
+	// SSHUser overrides the SSH user of the instance group.
+	SSHUser string `json:"sshUser,omitempty"`
+	// SSHPort overrides the SSH port of the instance group.
+	SSHPort int `json:"sshPort,omitempty"`
+}
+
+// LoadHostInventory reads and validates a host inventory file.
+func LoadHostInventory(p string) (*HostInventory, error) {
+	b, err := os.ReadFile(p)
+	if err != nil {
+		return nil, fmt.Errorf("reading inventory %q: %w", p, err)
+	}
+	inventory := &HostInventory{}
+	if err := yaml.UnmarshalStrict(b, inventory); err != nil {
+		return nil, fmt.Errorf("parsing inventory %q: %w", p, err)
+	}
+	if err := inventory.validate(); err != nil {
+		return nil, fmt.Errorf("invalid inventory %q: %w", p, err)
+	}
+	return inventory, nil
+}
+
+func (i *HostInventory) validate() error {
+	if len(i.InstanceGroups) == 0 {
+		return fmt.Errorf("no instance groups")
+	}
+	groups := make(map[string]bool)
+	addresses := make(map[string]string)
+	for _, group := range i.InstanceGroups {
+		if group.Name == "" {
+			return fmt.Errorf("instance group without a name")
+		}
+		if groups[group.Name] {
+			return fmt.Errorf("instance group %q is listed more than once", group.Name)
+		}
+		groups[group.Name] = true
+		for _, host := range group.Hosts {
+			if host.Address == "" {
+				return fmt.Errorf("host without an address in instance group %q", group.Name)
+			}
+			if other, found := addresses[host.Address]; found {
+				return fmt.Errorf("host %q is listed in instance groups %q and %q", host.Address, other, group.Name)
+			}
+			addresses[host.Address] = group.Name
+		}
+	}
+	return nil
+}
+
+// sshOptions returns the SSH user and port of a host, falling back to those of its instance group and then to the defaults.
+func (g *InventoryInstanceGroup) sshOptions(host InventoryHost, defaultUser string, defaultPort int) (string, int) {
+	user := defaultUser
+	if g.SSHUser != "" {
+		user = g.SSHUser
+	}
+	if host.SSHUser != "" {
+		user = host.SSHUser
+	}
+	port := defaultPort
+	if g.SSHPort != 0 {
+		port = g.SSHPort
+	}
+	if host.SSHPort != 0 {
+		port = host.SSHPort
+	}
+	return user, port
+}
+
+// controlPlaneAddresses returns the addresses of the hosts of the instance groups that run kube-apiserver.
+func (i *HostInventory) controlPlaneAddresses(igs map[string]*kops.InstanceGroup) []string {
+	var addresses []string
+	for _, group := range i.InstanceGroups {
+		if !igs[group.Name].HasAPIServer() {
+			continue
+		}
+		for _, host := range group.Hosts {
+			addresses = append(addresses, host.Address)
+		}
+	}
+	return addresses
+}
+
+// enrollOrder returns the instance groups in the order their hosts are enrolled:
+// the groups that run kube-apiserver first, then the others, each in inventory order.
+func (i *HostInventory) enrollOrder(igs map[string]*kops.InstanceGroup) []*InventoryInstanceGroup {
+	var controlPlane, others []*InventoryInstanceGroup
+	for j := range i.InstanceGroups {
+		group := &i.InstanceGroups[j]
+		if igs[group.Name].HasAPIServer() {
+			controlPlane = append(controlPlane, group)
+		} else {
+			others = append(others, group)
+		}
+	}
+	return append(controlPlane, others...)
+}
@@ -0,0 +1,165 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"k8s.io/kops/pkg/apis/kops"
+)
+
+func TestLoadHostInventory(t *testing.T) {
+	grid := []struct {
+		Name          string
+		Input         string
+		ExpectedError string
+	}{
+		{
+			Name: "valid",
+			Input: `
+instanceGroups:
+- name: nodes-a
+  sshUser: ubuntu
+  hosts:
+  - address: 192.168.1.20
+  - address: 192.168.1.21
+    sshPort: 2222
+- name: nodes-b
+  hosts:
+  - address: 192.168.1.30
+`,
+		},
+		{
+			Name:          "empty",
+			Input:         `instanceGroups: []`,
+			ExpectedError: "no instance groups",
+		},
+		{
+			Name: "duplicate instance group",
+			Input: `
+instanceGroups:
+- name: nodes-a
+- name: nodes-a
+`,
+			ExpectedError: `instance group "nodes-a" is listed more than once`,
+		},
+		{
+			Name: "duplicate host",
+			Input: `
+instanceGroups:
+- name: nodes-a
+  hosts:
+  - address: 192.168.1.20
+- name: nodes-b
+  hosts:
+  - address: 192.168.1.20
+`,
+			ExpectedError: `host "192.168.1.20" is listed in instance groups "nodes-a" and "nodes-b"`,
+		},
+		{
+			Name: "host without address",
+			Input: `
+instanceGroups:
+- name: nodes-a
+  hosts:
+  - sshPort: 22
+`,
+			ExpectedError: `host without an address in instance group "nodes-a"`,
+		},
+		{
+			Name: "unknown field",
+			Input: `
+instanceGroups:
+- name: nodes-a
+  hosts:
+  - ip: 192.168.1.20
+`,
+			ExpectedError: `unknown field "ip"`,
+		},
+	}
+	for _, g := range grid {
+		t.Run(g.Name, func(t *testing.T) {
+			p := filepath.Join(t.TempDir(), "hosts.yaml")
+			if err := os.WriteFile(p, []byte(g.Input), 0644); err != nil {
+				t.Fatalf("error writing inventory: %v", err)
+			}
+			_, err := LoadHostInventory(p)
+			if g.ExpectedError == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), g.ExpectedError) {
+				t.Errorf("expected error containing %q, got %v", g.ExpectedError, err)
+			}
+		})
+	}
+}
+
+func TestInventorySSHOptions(t *testing.T) {
+	group := &InventoryInstanceGroup{
+		Name:    "nodes",
+		SSHUser: "ubuntu",
+	}
+
+	user, port := group.sshOptions(InventoryHost{Address: "192.168.1.20"}, "root", 22)
+	if user != "ubuntu" || port != 22 {
+		t.Errorf("unexpected ssh options %s:%d", user, port)
+	}
+
+	user, port = group.sshOptions(InventoryHost{Address: "192.168.1.21", SSHUser: "admin", SSHPort: 2222}, "root", 22)
+	if user != "admin" || port != 2222 {
+		t.Errorf("unexpected ssh options %s:%d", user, port)
+	}
+}
+
+func TestInventoryEnrollOrder(t *testing.T) {
+	inventory := &HostInventory{
+		InstanceGroups: []InventoryInstanceGroup{
+			{Name: "nodes-a", Hosts: []InventoryHost{{Address: "192.168.1.20"}}},
+			{Name: "control-plane", Hosts: []InventoryHost{{Address: "192.168.1.10"}}},
+			{Name: "nodes-b", Hosts: []InventoryHost{{Address: "192.168.1.30"}}},
+		},
+	}
+	igs := make(map[string]*kops.InstanceGroup)
+	for name, role := range map[string]kops.InstanceGroupRole{
+		"nodes-a":       kops.InstanceGroupRoleNode,
+		"control-plane": kops.InstanceGroupRoleControlPlane,
+		"nodes-b":       kops.InstanceGroupRoleNode,
+	} {
+		ig := &kops.InstanceGroup{}
+		ig.Name = name
+		ig.Spec.Role = role
+		igs[name] = ig
+	}
+
+	var names []string
+	for _, group := range inventory.enrollOrder(igs) {
+		names = append(names, group.Name)
+	}
+	if got, want := strings.Join(names, ","), "control-plane,nodes-a,nodes-b"; got != want {
+		t.Errorf("unexpected enroll order %q, expected %q", got, want)
+	}
+
+	if got, want := strings.Join(inventory.controlPlaneAddresses(igs), ","), "192.168.1.10"; got != want {
+		t.Errorf("unexpected control-plane addresses %q, expected %q", got, want)
+	}
+}
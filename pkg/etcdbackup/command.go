@@ -0,0 +1,89 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcdbackup
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+const (
+	// CommandFileName is the name of the files holding the commands of etcd-manager.
+	CommandFileName = "_command.json"
+	// ClusterSpecFileName is the name of the file, in the control directory, holding the spec of the etcd cluster.
+	ClusterSpecFileName = "etcd-cluster-spec"
+)
+
+// Command is a command for etcd-manager, which runs it when it next becomes the leader of the etcd cluster.
+type Command struct {
+	// Timestamp is when the command was created, in nanoseconds since the epoch.
+	Timestamp int64 `json:"timestamp,string"`
+	// RestoreBackup restores the etcd cluster from a backup.
+	RestoreBackup *RestoreBackupCommand `json:"restoreBackup,omitempty"`
+}
+
+// RestoreBackupCommand restores an etcd cluster from a backup.
+type RestoreBackupCommand struct {
+	// ClusterSpec is the spec of the restored etcd cluster.
+	ClusterSpec *ClusterSpec `json:"clusterSpec,omitempty"`
+	// Backup is the name of the backup to restore.
+	Backup string `json:"backup,omitempty"`
+}
+
+// ReadClusterSpec returns the spec of the etcd cluster written by kOps to the control directory,
+// or nil if there is none.
+func (s *Store) ReadClusterSpec(ctx context.Context) (*ClusterSpec, error) {
+	p := s.base.Join(ControlDir, ClusterSpecFileName)
+	data, err := p.ReadFile(ctx)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error reading %q: %w", p, err)
+	}
+	spec := &ClusterSpec{}
+	if err := json.Unmarshal(data, spec); err != nil {
+		return nil, fmt.Errorf("error parsing %q: %w", p, err)
+	}
+	return spec, nil
+}
+
+// AddRestoreCommand adds a command restoring the named backup, like "etcd-manager-ctl restore-backup".
+// The restore happens once etcd-manager restarts on the control plane nodes.
+func (s *Store) AddRestoreCommand(ctx context.Context, backup string, spec *ClusterSpec, now time.Time) (string, error) {
+	command := &Command{
+		Timestamp: now.UnixNano(),
+		RestoreBackup: &RestoreBackupCommand{
+			ClusterSpec: spec,
+			Backup:      backup,
+		},
+	}
+	data, err := json.Marshal(command)
+	if err != nil {
+		return "", fmt.Errorf("error serializing restore command: %w", err)
+	}
+
+	p := s.base.Join(ControlDir, now.UTC().Format(time.RFC3339Nano), CommandFileName)
+	if err := p.CreateFile(ctx, bytes.NewReader(data), nil); err != nil {
+		return "", fmt.Errorf("error writing restore command %q: %w", p, err)
+	}
+	return p.Path(), nil
+}
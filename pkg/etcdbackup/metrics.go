@@ -0,0 +1,111 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcdbackup
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Verification is the outcome of the verification of the backup of an etcd cluster.
+type Verification struct {
+	// EtcdCluster is the name of the etcd cluster.
+	EtcdCluster string
+	// Result is the result of the verification, if it succeeded.
+	Result *VerifyResult
+	// Err is the error of the verification, if it failed.
+	Err error
+}
+
+// WriteMetrics writes the outcome of verifications in the Prometheus text format.
+func WriteMetrics(w io.Writer, verifications []Verification, now time.Time) error {
+	var b bytes.Buffer
+	gauge := func(name, help string, value func(v *Verification) (float64, bool)) {
+		fmt.Fprintf(&b, "# HELP %s %s\n", name, help)
+		fmt.Fprintf(&b, "# TYPE %s gauge\n", name)
+		for i := range verifications {
+			v := &verifications[i]
+			if x, ok := value(v); ok {
+				fmt.Fprintf(&b, "%s{etcd_cluster=%q} %v\n", name, v.EtcdCluster, x)
+			}
+		}
+	}
+	// result returns a gauge reporting a value of successful verifications
+	result := func(value func(r *VerifyResult) (float64, bool)) func(v *Verification) (float64, bool) {
+		return func(v *Verification) (float64, bool) {
+			if v.Err != nil || v.Result == nil {
+				return 0, false
+			}
+			return value(v.Result)
+		}
+	}
+
+	gauge("kops_etcd_backup_verify_success", "Whether the latest etcd backup could be restored.", func(v *Verification) (float64, bool) {
+		if v.Err != nil {
+			return 0, true
+		}
+		return 1, true
+	})
+	gauge("kops_etcd_backup_verify_timestamp_seconds", "When the etcd backup was last verified.", func(v *Verification) (float64, bool) {
+		return float64(now.Unix()), true
+	})
+	gauge("kops_etcd_backup_timestamp_seconds", "When the verified etcd backup was taken.", result(func(r *VerifyResult) (float64, bool) {
+		return float64(r.Taken.Unix()), !r.Taken.IsZero()
+	}))
+	gauge("kops_etcd_backup_size_bytes", "Size of the uncompressed snapshot of the verified etcd backup.", result(func(r *VerifyResult) (float64, bool) {
+		return float64(r.Size), true
+	}))
+	gauge("kops_etcd_backup_keys", "Number of keys in the verified etcd backup.", result(func(r *VerifyResult) (float64, bool) {
+		return float64(r.Keys), true
+	}))
+	gauge("kops_etcd_backup_revision", "Revision of the verified etcd backup.", result(func(r *VerifyResult) (float64, bool) {
+		return float64(r.Revision), true
+	}))
+	gauge("kops_etcd_backup_verify_duration_seconds", "How long the verification of the etcd backup took.", result(func(r *VerifyResult) (float64, bool) {
+		return r.Duration.Seconds(), true
+	}))
+
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+// PushMetrics pushes metrics in the Prometheus text format to a Prometheus Pushgateway,
+// replacing the metrics previously pushed for the job and etcd cluster.
+func PushMetrics(ctx context.Context, pushgatewayURL string, job string, etcdCluster string, metrics []byte) error {
+	u := strings.TrimSuffix(pushgatewayURL, "/") + "/metrics/job/" + url.PathEscape(job) + "/etcd_cluster/" + url.PathEscape(etcdCluster)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, u, bytes.NewReader(metrics))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain; version=0.0.4")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error pushing metrics to %q: %w", pushgatewayURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("error pushing metrics to %q: %s: %s", pushgatewayURL, resp.Status, body)
+	}
+	return nil
+}
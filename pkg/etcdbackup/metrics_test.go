@@ -0,0 +1,92 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcdbackup
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteMetrics(t *testing.T) {
+	now := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	result := &VerifyResult{
+		Backup:   "2024-01-01T00:00:00Z-000001",
+		Taken:    time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		Size:     4096,
+		Keys:     1234,
+		Revision: 5678,
+		Duration: 3 * time.Second,
+	}
+
+	var b bytes.Buffer
+	verifications := []Verification{
+		{EtcdCluster: "main", Result: result},
+		{EtcdCluster: "events", Err: errors.New("corrupt")},
+	}
+	if err := WriteMetrics(&b, verifications, now); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, expected := range []string{
+		`kops_etcd_backup_verify_success{etcd_cluster="main"} 1`,
+		`kops_etcd_backup_verify_timestamp_seconds{etcd_cluster="main"} 1.7041536e+09`,
+		`kops_etcd_backup_timestamp_seconds{etcd_cluster="main"} 1.7040672e+09`,
+		`kops_etcd_backup_keys{etcd_cluster="main"} 1234`,
+		`kops_etcd_backup_revision{etcd_cluster="main"} 5678`,
+		`kops_etcd_backup_verify_duration_seconds{etcd_cluster="main"} 3`,
+		`kops_etcd_backup_verify_success{etcd_cluster="events"} 0`,
+	} {
+		if !strings.Contains(b.String(), expected+"\n") {
+			t.Errorf("expected metric %q in:\n%s", expected, b.String())
+		}
+	}
+
+	if strings.Contains(b.String(), `kops_etcd_backup_keys{etcd_cluster="events"}`) {
+		t.Errorf("unexpected keys metric for a failed verification:\n%s", b.String())
+	}
+	if n := strings.Count(b.String(), "# TYPE kops_etcd_backup_verify_success gauge"); n != 1 {
+		t.Errorf("expected a single metric family, got %d", n)
+	}
+}
+
+func TestPushMetrics(t *testing.T) {
+	var method, path string
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method = r.Method
+		path = r.URL.Path
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer server.Close()
+
+	metrics := []byte("kops_etcd_backup_verify_success{etcd_cluster=\"main\"} 1\n")
+	if err := PushMetrics(context.TODO(), server.URL+"/", "etcd-backup-verify", "main", metrics); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if method != http.MethodPut || path != "/metrics/job/etcd-backup-verify/etcd_cluster/main" {
+		t.Errorf("unexpected request %s %s", method, path)
+	}
+	if !bytes.Equal(body, metrics) {
+		t.Errorf("unexpected body %q", body)
+	}
+}
@@ -0,0 +1,178 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package etcdbackup reads the backups that etcd-manager writes to the backup store of an etcd cluster.
+package etcdbackup
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/util/pkg/vfs"
+)
+
+const (
+	// DataFileName is the name of the gzipped etcd snapshot in a backup.
+	DataFileName = "etcd.backup.gz"
+	// MetaFileName is the name of the file describing a backup.
+	MetaFileName = "_etcd_backup.meta"
+	// ControlDir is the directory of the backup store holding the commands of etcd-manager.
+	ControlDir = "control"
+)
+
+// Info describes a backup, as written by etcd-manager.
+type Info struct {
+	// EtcdVersion is the version of etcd that took the backup.
+	EtcdVersion string `json:"etcdVersion,omitempty"`
+	// Timestamp is when the backup was taken, in seconds since the epoch.
+	Timestamp json.Number `json:"timestamp,omitempty"`
+	// ClusterSpec is the spec of the etcd cluster when the backup was taken.
+	ClusterSpec *ClusterSpec `json:"clusterSpec,omitempty"`
+}
+
+// ClusterSpec is the spec of an etcd cluster, as used by etcd-manager.
+type ClusterSpec struct {
+	MemberCount int32  `json:"memberCount,omitempty"`
+	EtcdVersion string `json:"etcdVersion,omitempty"`
+}
+
+// Store is the backup store of an etcd cluster.
+type Store struct {
+	base vfs.Path
+}
+
+// NewStore returns the backup store at the given location.
+func NewStore(vfsContext *vfs.VFSContext, backupStore string) (*Store, error) {
+	base, err := vfsContext.BuildVfsPath(backupStore)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing backup store %q: %w", backupStore, err)
+	}
+	return &Store{base: base}, nil
+}
+
+// BackupStoreFor returns the backup store of the named etcd cluster, defaulting to the location used by kOps.
+func BackupStoreFor(cluster *kops.Cluster, configBase vfs.Path, etcdClusterName string) (string, error) {
+	for _, etcdCluster := range cluster.Spec.EtcdClusters {
+		if etcdCluster.Name != etcdClusterName {
+			continue
+		}
+		if etcdCluster.Backups != nil && etcdCluster.Backups.BackupStore != "" {
+			return etcdCluster.Backups.BackupStore, nil
+		}
+		return configBase.Join("backups", "etcd", etcdCluster.Name).Path(), nil
+	}
+	return "", fmt.Errorf("etcd cluster %q not found", etcdClusterName)
+}
+
+// Path returns the location of the backup store.
+func (s *Store) Path() string {
+	return s.base.Path()
+}
+
+// ListBackups returns the names of the backups in the store, oldest first.
+func (s *Store) ListBackups(ctx context.Context) ([]string, error) {
+	files, err := s.base.ReadTree(ctx)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error listing backups in %q: %w", s.base, err)
+	}
+
+	prefix := strings.TrimSuffix(s.base.Path(), "/") + "/"
+	var names []string
+	for _, f := range files {
+		rel := strings.TrimPrefix(f.Path(), prefix)
+		dir, file := path.Split(rel)
+		dir = strings.TrimSuffix(dir, "/")
+		if file != MetaFileName || dir == "" || strings.Contains(dir, "/") {
+			continue
+		}
+		names = append(names, dir)
+	}
+	// Backup names start with their timestamp
+	sort.Strings(names)
+	return names, nil
+}
+
+// LatestBackup returns the name of the most recent backup in the store.
+func (s *Store) LatestBackup(ctx context.Context) (string, error) {
+	names, err := s.ListBackups(ctx)
+	if err != nil {
+		return "", err
+	}
+	if len(names) == 0 {
+		return "", fmt.Errorf("no backups found in %q", s.base)
+	}
+	return names[len(names)-1], nil
+}
+
+// ReadInfo returns the description of the named backup.
+func (s *Store) ReadInfo(ctx context.Context, name string) (*Info, error) {
+	p := s.base.Join(name, MetaFileName)
+	data, err := p.ReadFile(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %q: %w", p, err)
+	}
+	info := &Info{}
+	if err := json.Unmarshal(data, info); err != nil {
+		return nil, fmt.Errorf("error parsing %q: %w", p, err)
+	}
+	return info, nil
+}
+
+// Download writes the etcd snapshot of the named backup, uncompressed, to the given file.
+func (s *Store) Download(ctx context.Context, name string, dest string) error {
+	p := s.base.Join(name, DataFileName)
+
+	compressed, err := os.CreateTemp("", "etcd-backup")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(compressed.Name())
+	defer compressed.Close()
+
+	if _, err := p.WriteTo(compressed); err != nil {
+		return fmt.Errorf("error downloading %q: %w", p, err)
+	}
+	if _, err := compressed.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	gz, err := gzip.NewReader(compressed)
+	if err != nil {
+		return fmt.Errorf("error decompressing %q: %w", p, err)
+	}
+	defer gz.Close()
+
+	f, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, gz); err != nil {
+		f.Close()
+		return fmt.Errorf("error decompressing %q: %w", p, err)
+	}
+	return f.Close()
+}
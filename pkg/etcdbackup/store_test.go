@@ -0,0 +1,165 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcdbackup
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"k8s.io/kops/util/pkg/vfs"
+)
+
+func TestStore(t *testing.T) {
+	ctx := context.TODO()
+
+	vfsContext := vfs.NewVFSContext()
+	vfsContext.ResetMemfsContext(true)
+	backupStore := "memfs://state/cluster.example.com/backups/etcd/main"
+	base, err := vfsContext.BuildVfsPath(backupStore)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	store, err := NewStore(vfsContext, backupStore)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := store.LatestBackup(ctx); err == nil {
+		t.Fatalf("expected an error for an empty backup store")
+	}
+
+	snapshot := []byte("snapshot data")
+	for _, name := range []string{"2024-01-02T00:00:00Z-000002", "2024-01-01T00:00:00Z-000001"} {
+		meta := []byte(`{"etcdVersion":"3.5.13","timestamp":"1704067200","clusterSpec":{"memberCount":3,"etcdVersion":"3.5.13"}}`)
+		if err := base.Join(name, MetaFileName).WriteFile(ctx, bytes.NewReader(meta), nil); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var data bytes.Buffer
+		gz := gzip.NewWriter(&data)
+		gz.Write(snapshot)
+		gz.Close()
+		if err := base.Join(name, DataFileName).WriteFile(ctx, bytes.NewReader(data.Bytes()), nil); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if err := base.Join(ControlDir, "etcd-cluster-spec").WriteFile(ctx, bytes.NewReader([]byte("{}")), nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	names, err := store.ListBackups(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []string{"2024-01-01T00:00:00Z-000001", "2024-01-02T00:00:00Z-000002"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Fatalf("unexpected backups: got %v, expected %v", names, expected)
+	}
+
+	latest, err := store.LatestBackup(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if latest != "2024-01-02T00:00:00Z-000002" {
+		t.Fatalf("unexpected latest backup %q", latest)
+	}
+
+	info, err := store.ReadInfo(ctx, latest)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if info.EtcdVersion != "3.5.13" || info.Timestamp != "1704067200" || info.ClusterSpec == nil || info.ClusterSpec.MemberCount != 3 {
+		t.Fatalf("unexpected backup info %+v", info)
+	}
+
+	dest := filepath.Join(t.TempDir(), "snapshot.db")
+	if err := store.Download(ctx, latest, dest); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	downloaded, err := os.ReadFile(dest)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !bytes.Equal(downloaded, snapshot) {
+		t.Fatalf("unexpected snapshot %q", downloaded)
+	}
+}
+
+func TestAddRestoreCommand(t *testing.T) {
+	ctx := context.TODO()
+
+	vfsContext := vfs.NewVFSContext()
+	vfsContext.ResetMemfsContext(true)
+	backupStore := "memfs://state/cluster.example.com/backups/etcd/main"
+	base, err := vfsContext.BuildVfsPath(backupStore)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	store, err := NewStore(vfsContext, backupStore)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	spec, err := store.ReadClusterSpec(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if spec != nil {
+		t.Fatalf("expected no cluster spec, got %+v", spec)
+	}
+	if err := base.Join(ControlDir, ClusterSpecFileName).WriteFile(ctx, bytes.NewReader([]byte(`{"memberCount":3,"etcdVersion":"3.5.13"}`)), nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	spec, err = store.ReadClusterSpec(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if spec == nil || spec.MemberCount != 3 || spec.EtcdVersion != "3.5.13" {
+		t.Fatalf("unexpected cluster spec %+v", spec)
+	}
+
+	now := time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC)
+	p, err := store.AddRestoreCommand(ctx, "2024-01-01T00:00:00Z-000001", spec, now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := backupStore + "/control/2024-01-02T03:04:05.000000006Z/_command.json"; p != expected {
+		t.Fatalf("unexpected command path %q, expected %q", p, expected)
+	}
+	data, err := base.Join(ControlDir, "2024-01-02T03:04:05.000000006Z", CommandFileName).ReadFile(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := `{"timestamp":"1704164645000000006","restoreBackup":{"clusterSpec":{"memberCount":3,"etcdVersion":"3.5.13"},"backup":"2024-01-01T00:00:00Z-000001"}}`
+	if string(data) != expected {
+		t.Fatalf("unexpected command %s, expected %s", data, expected)
+	}
+
+	// Commands are not mistaken for backups
+	names, err := store.ListBackups(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(names) != 0 {
+		t.Fatalf("unexpected backups %v", names)
+	}
+}
@@ -0,0 +1,243 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcdbackup
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+// VerifyResult is the outcome of restoring a backup into a throwaway etcd.
+type VerifyResult struct {
+	// Backup is the name of the verified backup.
+	Backup string `json:"backup"`
+	// EtcdVersion is the version of etcd that took the backup.
+	EtcdVersion string `json:"etcdVersion,omitempty"`
+	// Taken is when the backup was taken.
+	Taken time.Time `json:"taken,omitempty"`
+	// Size is the size of the uncompressed snapshot, in bytes.
+	Size int64 `json:"size"`
+	// Keys is the number of keys in the restored etcd.
+	Keys int64 `json:"keys"`
+	// Revision is the revision of the restored etcd.
+	Revision int64 `json:"revision"`
+	// Duration is how long the verification took.
+	Duration time.Duration `json:"duration"`
+}
+
+// Verifier restores backups into a throwaway local etcd.
+type Verifier struct {
+	// EtcdBinDir is the directory holding the etcd binaries; if empty, they are looked up in the PATH.
+	EtcdBinDir string
+	// StartTimeout is how long to wait for the throwaway etcd to serve requests.
+	StartTimeout time.Duration
+}
+
+func (v *Verifier) binary(name string) (string, error) {
+	if v.EtcdBinDir != "" {
+		p := filepath.Join(v.EtcdBinDir, name)
+		if _, err := os.Stat(p); err != nil {
+			return "", err
+		}
+		return p, nil
+	}
+	return exec.LookPath(name)
+}
+
+// Verify downloads the named backup, restores it into a throwaway etcd listening on localhost,
+// and reports the number of keys and the revision of the restored data.
+func (v *Verifier) Verify(ctx context.Context, store *Store, name string) (*VerifyResult, error) {
+	start := time.Now()
+
+	etcd, err := v.binary("etcd")
+	if err != nil {
+		return nil, fmt.Errorf("etcd binary not found: %w", err)
+	}
+	// etcdutl replaces "etcdctl snapshot restore" from etcd 3.5
+	restoreArgs := []string{"snapshot", "restore"}
+	restore, err := v.binary("etcdutl")
+	if err != nil {
+		restore, err = v.binary("etcdctl")
+		if err != nil {
+			return nil, fmt.Errorf("neither etcdutl nor etcdctl binaries were found: %w", err)
+		}
+	}
+
+	result := &VerifyResult{Backup: name}
+	info, err := store.ReadInfo(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	result.EtcdVersion = info.EtcdVersion
+	if seconds, err := info.Timestamp.Int64(); err == nil {
+		result.Taken = time.Unix(seconds, 0).UTC()
+	}
+
+	dir, err := os.MkdirTemp("", "etcd-verify")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	snapshot := filepath.Join(dir, "snapshot.db")
+	if err := store.Download(ctx, name, snapshot); err != nil {
+		return nil, err
+	}
+	stat, err := os.Stat(snapshot)
+	if err != nil {
+		return nil, err
+	}
+	result.Size = stat.Size()
+
+	clientPort, err := freePort()
+	if err != nil {
+		return nil, err
+	}
+	peerPort, err := freePort()
+	if err != nil {
+		return nil, err
+	}
+	clientURL := fmt.Sprintf("http://127.0.0.1:%d", clientPort)
+	peerURL := fmt.Sprintf("http://127.0.0.1:%d", peerPort)
+	dataDir := filepath.Join(dir, "data")
+
+	// Restoring checks the integrity hash of the snapshot
+	restoreArgs = append(restoreArgs, snapshot,
+		"--data-dir="+dataDir,
+		"--name=verify",
+		"--initial-cluster=verify="+peerURL,
+		"--initial-advertise-peer-urls="+peerURL,
+	)
+	cmd := exec.CommandContext(ctx, restore, restoreArgs...)
+	cmd.Env = append(os.Environ(), "ETCDCTL_API=3")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("error restoring backup %q: %w: %s", name, err, out)
+	}
+
+	var logs bytes.Buffer
+	etcdCmd := exec.CommandContext(ctx, etcd,
+		"--data-dir="+dataDir,
+		"--name=verify",
+		"--listen-client-urls="+clientURL,
+		"--advertise-client-urls="+clientURL,
+		"--listen-peer-urls="+peerURL,
+		"--initial-cluster=verify="+peerURL,
+		"--initial-advertise-peer-urls="+peerURL,
+	)
+	etcdCmd.Stdout = &logs
+	etcdCmd.Stderr = &logs
+	if err := etcdCmd.Start(); err != nil {
+		return nil, fmt.Errorf("error starting etcd: %w", err)
+	}
+	defer func() {
+		_ = etcdCmd.Process.Kill()
+		_ = etcdCmd.Wait()
+	}()
+
+	timeout := v.StartTimeout
+	if timeout == 0 {
+		timeout = time.Minute
+	}
+	deadline := time.Now().Add(timeout)
+	for {
+		keys, revision, err := countKeys(ctx, clientURL)
+		if err == nil {
+			result.Keys = keys
+			result.Revision = revision
+			break
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("restored etcd did not serve requests within %v: %w; etcd output: %s", timeout, err, logs.String())
+		}
+		klog.V(2).Infof("waiting for restored etcd: %v", err)
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(time.Second):
+		}
+	}
+
+	result.Duration = time.Since(start)
+	return result, nil
+}
+
+// countKeys returns the number of keys and the revision of an etcd, through its JSON gateway.
+func countKeys(ctx context.Context, clientURL string) (int64, int64, error) {
+	// A range from the null key to the null key covers all the keys
+	body := []byte(`{"key":"AA==","range_end":"AA==","count_only":true}`)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, clientURL+"/v3/kv/range", bytes.NewReader(body))
+	if err != nil {
+		return 0, 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, 0, fmt.Errorf("unexpected status %q counting keys", resp.Status)
+	}
+
+	// The gateway encodes int64 values as strings
+	var rangeResponse struct {
+		Header struct {
+			Revision json.Number `json:"revision"`
+		} `json:"header"`
+		Count json.Number `json:"count"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&rangeResponse); err != nil {
+		return 0, 0, fmt.Errorf("error parsing range response: %w", err)
+	}
+	keys, err := parseCount(rangeResponse.Count)
+	if err != nil {
+		return 0, 0, err
+	}
+	revision, err := parseCount(rangeResponse.Header.Revision)
+	if err != nil {
+		return 0, 0, err
+	}
+	return keys, revision, nil
+}
+
+// parseCount parses an int64 omitted by the gateway when it is zero.
+func parseCount(n json.Number) (int64, error) {
+	if n == "" {
+		return 0, nil
+	}
+	return strconv.ParseInt(string(n), 10, 64)
+}
+
+func freePort() (int, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port, nil
+}
@@ -98,6 +98,8 @@ var (
 	Metal = new("Metal", Bool(false))
 	// AWSSingleNodesInstanceGroup enables the creation of a single node instance group instead of one per availability zone.
 	AWSSingleNodesInstanceGroup = new("AWSSingleNodesInstanceGroup", Bool(false))
+	// KopsControllerMetrics enables the Prometheus metrics endpoint of kops-controller.
+	KopsControllerMetrics = new("KopsControllerMetrics", Bool(false))
 )
 
 // FeatureFlag defines a feature flag
@@ -32,6 +32,7 @@ import (
 	"time"
 
 	"k8s.io/klog/v2"
+	"k8s.io/kops/pkg/apis/nodeup"
 	"k8s.io/kops/pkg/bootstrap"
 	"k8s.io/kops/upup/pkg/fi"
 	"k8s.io/kops/upup/pkg/fi/cloudup"
@@ -40,6 +41,8 @@ import (
 type Client struct {
 	// Authenticator generates authentication credentials for requests.
 	Authenticator bootstrap.Authenticator
+	// Certificate is the client certificate presented to kops-controller, for requests not using an Authenticator.
+	Certificate *tls.Certificate
 	// CAs are the CA certificates for kops-controller.
 	CAs []byte
 
@@ -49,7 +52,27 @@ type Client struct {
 	httpClient *http.Client
 }
 
+// Query sends a bootstrap request to kops-controller.
 func (b *Client) Query(ctx context.Context, req any, resp any) error {
+	return b.query(ctx, "/bootstrap", req, resp)
+}
+
+// UpdateNode sends an in-place update request to kops-controller.
+func (b *Client) UpdateNode(ctx context.Context, req *nodeup.NodeUpdateRequest, resp *nodeup.NodeUpdateResponse) error {
+	return b.query(ctx, "/node-update", req, resp)
+}
+
+// VolumeKey requests the key of an encrypted volume from kops-controller.
+func (b *Client) VolumeKey(ctx context.Context, req *nodeup.VolumeKeyRequest, resp *nodeup.VolumeKeyResponse) error {
+	return b.query(ctx, "/volume-key", req, resp)
+}
+
+// RenewCertificates requests new certificates for the node from kops-controller, authenticated with the Certificate.
+func (b *Client) RenewCertificates(ctx context.Context, req *nodeup.CertificateRenewalRequest, resp *nodeup.CertificateRenewalResponse) error {
+	return b.query(ctx, "/renew-certificates", req, resp)
+}
+
+func (b *Client) query(ctx context.Context, requestPath string, req any, resp any) error {
 	if b.httpClient == nil {
 		certPool := x509.NewCertPool()
 		certPool.AppendCertsFromPEM(b.CAs)
@@ -60,6 +83,9 @@ func (b *Client) Query(ctx context.Context, req any, resp any) error {
 				MinVersion: tls.VersionTLS12,
 			},
 		}
+		if b.Certificate != nil {
+			transport.TLSClientConfig.Certificates = []tls.Certificate{*b.Certificate}
+		}
 
 		httpClient := &http.Client{
 			Timeout:   time.Duration(15) * time.Second,
@@ -84,19 +110,21 @@ func (b *Client) Query(ctx context.Context, req any, resp any) error {
 		return err
 	}
 
-	bootstrapURL := b.BaseURL
-	bootstrapURL.Path = path.Join(bootstrapURL.Path, "/bootstrap")
-	httpReq, err := http.NewRequestWithContext(ctx, "POST", bootstrapURL.String(), bytes.NewReader(reqBytes))
+	requestURL := b.BaseURL
+	requestURL.Path = path.Join(requestURL.Path, requestPath)
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", requestURL.String(), bytes.NewReader(reqBytes))
 	if err != nil {
 		return err
 	}
 	httpReq.Header.Set("Content-Type", "application/json")
 
-	token, err := b.Authenticator.CreateToken(reqBytes)
-	if err != nil {
-		return err
+	if b.Authenticator != nil {
+		token, err := b.Authenticator.CreateToken(reqBytes)
+		if err != nil {
+			return err
+		}
+		httpReq.Header.Set("Authorization", token)
 	}
-	httpReq.Header.Set("Authorization", token)
 
 	response, err := b.httpClient.Do(httpReq)
 	if err != nil {
@@ -33,6 +33,7 @@ type KubectlClusterWithName struct {
 type KubectlCluster struct {
 	Server                   string `json:"server,omitempty"`
 	CertificateAuthorityData []byte `json:"certificate-authority-data,omitempty"`
+	TLSServerName            string `json:"tls-server-name,omitempty"`
 }
 
 type KubectlContextWithName struct {
@@ -51,7 +52,9 @@ type KubectlUserWithName struct {
 }
 
 type KubectlUser struct {
+	ClientCertificate     string `json:"client-certificate,omitempty"`
 	ClientCertificateData []byte `json:"client-certificate-data,omitempty"`
+	ClientKey             string `json:"client-key,omitempty"`
 	ClientKeyData         []byte `json:"client-key-data,omitempty"`
 	Password              string `json:"password,omitempty"`
 	Username              string `json:"username,omitempty"`
@@ -20,11 +20,13 @@ import (
 	"context"
 	"crypto/x509/pkix"
 	"fmt"
+	"os"
 	"os/user"
 	"sort"
 	"time"
 
 	"k8s.io/klog/v2"
+	"k8s.io/kops/pkg/admincredentials"
 	"k8s.io/kops/pkg/apis/kops"
 	"k8s.io/kops/pkg/pki"
 	"k8s.io/kops/pkg/rbac"
@@ -33,7 +35,14 @@ import (
 
 const DefaultKubecfgAdminLifetime = 18 * time.Hour
 
-func BuildKubecfg(ctx context.Context, cluster *kops.Cluster, keyStore fi.KeystoreReader, secretStore fi.SecretStore, cloud fi.Cloud, admin time.Duration, configUser string, internal bool, kopsStateStore string, useKopsAuthenticationPlugin bool) (*KubeconfigBuilder, error) {
+// OIDCOptions configures a kubeconfig that authenticates with the OIDC issuer configured for the cluster,
+// through the kubelogin kubectl plugin.
+type OIDCOptions struct {
+	// ExtraScopes are the scopes requested from the issuer in addition to "openid", for example to obtain the groups claim.
+	ExtraScopes []string
+}
+
+func BuildKubecfg(ctx context.Context, cluster *kops.Cluster, keyStore fi.KeystoreReader, secretStore fi.SecretStore, cloud fi.Cloud, admin time.Duration, configUser string, internal bool, kopsStateStore string, useKopsAuthenticationPlugin bool, oidc *OIDCOptions) (*KubeconfigBuilder, error) {
 	clusterName := cluster.ObjectMeta.Name
 
 	var server string
@@ -117,11 +126,20 @@ func BuildKubecfg(ctx context.Context, cluster *kops.Cluster, keyStore fi.Keysto
 
 	if admin != 0 {
 		cn := "kubecfg"
+		issuedBy := ""
 		user, err := user.Current()
 		if err != nil || user == nil {
 			klog.Infof("unable to get user: %v", err)
 		} else {
 			cn += "-" + user.Name
+			issuedBy = user.Username
+		}
+
+		serial := pki.BuildPKISerial(time.Now().UnixNano())
+		groups := []string{rbac.SystemPrivilegedGroup}
+		if cluster.Spec.Authentication != nil && cluster.Spec.Authentication.AdminCredentialDenyList {
+			// Members of system:masters bypass authorization, so they could not be denied once revoked
+			groups = []string{rbac.KopsAdminsGroup, admincredentials.SerialGroup(serial.String())}
 		}
 
 		req := pki.IssueCertRequest{
@@ -129,9 +147,10 @@ func BuildKubecfg(ctx context.Context, cluster *kops.Cluster, keyStore fi.Keysto
 			Type:   "client",
 			Subject: pkix.Name{
 				CommonName:   cn,
-				Organization: []string{rbac.SystemPrivilegedGroup},
+				Organization: groups,
 			},
 			Validity: admin,
+			Serial:   serial,
 		}
 		cert, privateKey, _, err := pki.IssueCert(ctx, &req, fi.NewPKIKeystoreAdapter(keyStore))
 		if err != nil {
@@ -145,6 +164,20 @@ func BuildKubecfg(ctx context.Context, cluster *kops.Cluster, keyStore fi.Keysto
 		if err != nil {
 			return nil, err
 		}
+
+		hostname, err := os.Hostname()
+		if err != nil {
+			klog.Infof("unable to get hostname: %v", err)
+		}
+		b.AdminCredential = &admincredentials.AdminCredential{
+			Serial:     serial.String(),
+			CommonName: cn,
+			Groups:     groups,
+			IssuedBy:   issuedBy,
+			Hostname:   hostname,
+			IssuedAt:   time.Now().UTC(),
+			ExpiresAt:  cert.Certificate.NotAfter.UTC(),
+		}
 	}
 
 	if useKopsAuthenticationPlugin {
@@ -159,6 +192,20 @@ func BuildKubecfg(ctx context.Context, cluster *kops.Cluster, keyStore fi.Keysto
 		// If there's an existing client-cert / client-key, we need to clear it so it won't be used
 		b.ClientCert = nil
 		b.ClientKey = nil
+		b.AdminCredential = nil
+	}
+
+	if oidc != nil {
+		exec, err := buildOIDCAuthenticationExec(cluster, oidc)
+		if err != nil {
+			return nil, err
+		}
+		b.AuthenticationExec = exec
+
+		// As with the kOps authentication plugin, a client certificate would take precedence
+		b.ClientCert = nil
+		b.ClientKey = nil
+		b.AdminCredential = nil
 	}
 
 	b.Server = server
@@ -171,3 +218,26 @@ func BuildKubecfg(ctx context.Context, cluster *kops.Cluster, keyStore fi.Keysto
 
 	return b, nil
 }
+
+// buildOIDCAuthenticationExec builds the exec credential command fetching an ID token from the OIDC issuer of the cluster.
+func buildOIDCAuthenticationExec(cluster *kops.Cluster, oidc *OIDCOptions) ([]string, error) {
+	if cluster.Spec.Authentication == nil || cluster.Spec.Authentication.OIDC == nil {
+		return nil, fmt.Errorf("cluster %q does not configure OIDC authentication, set spec.authentication.oidc", cluster.ObjectMeta.Name)
+	}
+	spec := cluster.Spec.Authentication.OIDC
+	if fi.ValueOf(spec.IssuerURL) == "" || fi.ValueOf(spec.ClientID) == "" {
+		return nil, fmt.Errorf("cluster %q does not configure an OIDC issuer, set spec.authentication.oidc.issuerURL and spec.authentication.oidc.clientID", cluster.ObjectMeta.Name)
+	}
+
+	exec := []string{
+		"kubectl",
+		"oidc-login",
+		"get-token",
+		"--oidc-issuer-url=" + fi.ValueOf(spec.IssuerURL),
+		"--oidc-client-id=" + fi.ValueOf(spec.ClientID),
+	}
+	for _, scope := range oidc.ExtraScopes {
+		exec = append(exec, "--oidc-extra-scope="+scope)
+	}
+	return exec, nil
+}
@@ -29,6 +29,7 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"k8s.io/kops/pkg/apis/kops"
 	"k8s.io/kops/pkg/pki"
+	"k8s.io/kops/pkg/rbac"
 	"k8s.io/kops/upup/pkg/fi"
 	"k8s.io/kops/util/pkg/vfs"
 )
@@ -156,6 +157,7 @@ func TestBuildKubecfg(t *testing.T) {
 		user                        string
 		internal                    bool
 		useKopsAuthenticationPlugin bool
+		oidc                        *OIDCOptions
 	}
 
 	publicCluster := buildMinimalCluster("testcluster", "testcluster.test.com", false, false)
@@ -164,6 +166,13 @@ func TestBuildKubecfg(t *testing.T) {
 	certCluster := buildMinimalCluster("testcluster", "testcluster.test.com", true, false)
 	certNLBCluster := buildMinimalCluster("testcluster", "testcluster.test.com", true, true)
 	certGossipNLBCluster := buildMinimalCluster("testgossipcluster.k8s.local", "", true, true)
+	oidcCluster := buildMinimalCluster("testcluster", "testcluster.test.com", false, false)
+	oidcCluster.Spec.Authentication = &kops.AuthenticationSpec{
+		OIDC: &kops.OIDCAuthenticationSpec{
+			IssuerURL: fi.PtrTo("https://sso.example.com"),
+			ClientID:  fi.PtrTo("kubernetes"),
+		},
+	}
 
 	fakeStatus := fakeStatusCloud{
 		GetApiIngressStatusFn: func(cluster *kops.Cluster) ([]fi.ApiIngressStatus, error) {
@@ -320,6 +329,40 @@ func TestBuildKubecfg(t *testing.T) {
 			},
 			wantClientCert: false,
 		},
+		{
+			name: "Public DNS with OIDC",
+			args: args{
+				cluster: oidcCluster,
+				status:  fakeStatus,
+				admin:   DefaultKubecfgAdminLifetime,
+				oidc:    &OIDCOptions{ExtraScopes: []string{"groups"}},
+			},
+			want: &KubeconfigBuilder{
+				Context:       "testcluster",
+				Server:        "https://testcluster.test.com",
+				TLSServerName: "api.internal.testcluster",
+				CACerts:       []byte(nextCertificate + certData),
+				User:          "testcluster",
+				AuthenticationExec: []string{
+					"kubectl",
+					"oidc-login",
+					"get-token",
+					"--oidc-issuer-url=https://sso.example.com",
+					"--oidc-client-id=kubernetes",
+					"--oidc-extra-scope=groups",
+				},
+			},
+			wantClientCert: false,
+		},
+		{
+			name: "Public DNS with OIDC but no issuer",
+			args: args{
+				cluster: publicCluster,
+				status:  fakeStatus,
+				oidc:    &OIDCOptions{},
+			},
+			wantErr: true,
+		},
 		{
 			name: "Test Kube Config Data For internal DNS name with admin",
 			args: args{
@@ -401,7 +444,7 @@ func TestBuildKubecfg(t *testing.T) {
 				},
 			}
 
-			got, err := BuildKubecfg(ctx, tt.args.cluster, keyStore, tt.args.secretStore, tt.args.status, tt.args.admin, tt.args.user, tt.args.internal, kopsStateStore, tt.args.useKopsAuthenticationPlugin)
+			got, err := BuildKubecfg(ctx, tt.args.cluster, keyStore, tt.args.secretStore, tt.args.status, tt.args.admin, tt.args.user, tt.args.internal, kopsStateStore, tt.args.useKopsAuthenticationPlugin, tt.args.oidc)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("BuildKubecfg() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -413,8 +456,12 @@ func TestBuildKubecfg(t *testing.T) {
 				if got.ClientKey == nil {
 					t.Errorf("Expected ClientKey, got nil")
 				}
+				if got.AdminCredential == nil || got.AdminCredential.Serial == "" {
+					t.Errorf("Expected AdminCredential, got %v", got.AdminCredential)
+				}
 				tt.want.ClientCert = got.ClientCert
 				tt.want.ClientKey = got.ClientKey
+				tt.want.AdminCredential = got.AdminCredential
 			}
 			if diff := cmp.Diff(got, tt.want); diff != "" {
 				t.Errorf("BuildKubecfg() diff (+got, -want): %s", diff)
@@ -422,3 +469,42 @@ func TestBuildKubecfg(t *testing.T) {
 		})
 	}
 }
+
+func TestBuildKubecfgAdminCredentialDenyList(t *testing.T) {
+	originalPKIDefaultPrivateKeySize := pki.DefaultPrivateKeySize
+	pki.DefaultPrivateKeySize = 512
+	defer func() {
+		pki.DefaultPrivateKeySize = originalPKIDefaultPrivateKeySize
+	}()
+
+	ctx := context.TODO()
+
+	cluster := buildMinimalCluster("testcluster", "testcluster.test.com", false, false)
+	cluster.Spec.Authentication = &kops.AuthenticationSpec{AdminCredentialDenyList: true}
+
+	keyStore := fakeKeyStore{
+		FindKeysetFn: func(name string) (*fi.Keyset, error) {
+			return fakeKeyset(), nil
+		},
+	}
+
+	got, err := BuildKubecfg(ctx, cluster, keyStore, nil, fakeStatusCloud{}, DefaultKubecfgAdminLifetime, "", false, "memfs://example-state-store", false, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cert, err := pki.ParsePEMCertificate(got.ClientCert)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cert.Certificate.SerialNumber.String() != got.AdminCredential.Serial {
+		t.Errorf("expected serial %s, got %s", got.AdminCredential.Serial, cert.Certificate.SerialNumber)
+	}
+	expectedGroups := []string{rbac.KopsAdminsGroup, rbac.KopsAdminCredentialGroupPrefix + got.AdminCredential.Serial}
+	if diff := cmp.Diff(cert.Subject.Organization, expectedGroups); diff != "" {
+		t.Errorf("unexpected groups (+got, -want): %s", diff)
+	}
+	if diff := cmp.Diff(got.AdminCredential.Groups, expectedGroups); diff != "" {
+		t.Errorf("unexpected recorded groups (+got, -want): %s", diff)
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"k8s.io/client-go/tools/clientcmd"
 	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
 	"k8s.io/klog/v2"
+	"k8s.io/kops/pkg/admincredentials"
 )
 
 // KubeconfigBuilder builds a kubecfg file
@@ -42,6 +43,9 @@ type KubeconfigBuilder struct {
 	ClientKey  []byte
 
 	AuthenticationExec []string
+
+	// AdminCredential records the admin client certificate, if one was issued.
+	AdminCredential *admincredentials.AdminCredential
 }
 
 // Create new KubeconfigBuilder
@@ -123,13 +123,39 @@ func (b *BootstrapScript) kubeEnv(ig *kops.InstanceGroup, c *fi.CloudupContext)
 	if err != nil {
 		return nil, fmt.Errorf("error converting nodeup config to yaml: %v", err)
 	}
-	sum256 := sha256.Sum256(configData)
-	bootConfig.NodeupConfigHash = base64.StdEncoding.EncodeToString(sum256[:])
+	// With in-place updates, the nodes get the hash of their configuration from kops-controller instead,
+	// so that changes to the configuration don't change the user data and replace the instances.
+	if !config.InPlaceUpdates {
+		sum256 := sha256.Sum256(configData)
+		bootConfig.NodeupConfigHash = base64.StdEncoding.EncodeToString(sum256[:])
+	} else if len(config.KernelModules) > 0 || len(config.KernelBootParameters) > 0 {
+		kernelData, err := utils.YamlMarshal(map[string]interface{}{
+			"kernelModules":        config.KernelModules,
+			"kernelBootParameters": config.KernelBootParameters,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error converting kernel config to yaml: %v", err)
+		}
+		sum256 := sha256.Sum256(kernelData)
+		bootConfig.KernelConfigHash = base64.StdEncoding.EncodeToString(sum256[:])
+	}
 	b.nodeupConfig.Resource = fi.NewBytesResource(configData)
 
 	return bootConfig, nil
 }
 
+// StateStoreEnvironmentVariables returns the environment variables control-plane hosts need to read an S3-compatible state store.
+func StateStoreEnvironmentVariables() map[string]string {
+	env := make(map[string]string)
+	if os.Getenv("S3_ENDPOINT") != "" {
+		env["S3_ENDPOINT"] = os.Getenv("S3_ENDPOINT")
+		env["S3_REGION"] = os.Getenv("S3_REGION")
+		env["S3_ACCESS_KEY_ID"] = os.Getenv("S3_ACCESS_KEY_ID")
+		env["S3_SECRET_ACCESS_KEY"] = os.Getenv("S3_SECRET_ACCESS_KEY")
+	}
+	return env
+}
+
 func (b *BootstrapScript) buildEnvironmentVariables() (map[string]string, error) {
 	cluster := b.cluster
 
@@ -139,12 +165,9 @@ func (b *BootstrapScript) buildEnvironmentVariables() (map[string]string, error)
 		env["GOSSIP_DNS_CONN_LIMIT"] = os.Getenv("GOSSIP_DNS_CONN_LIMIT")
 	}
 
-	if os.Getenv("S3_ENDPOINT") != "" {
-		if b.ig.IsControlPlane() {
-			env["S3_ENDPOINT"] = os.Getenv("S3_ENDPOINT")
-			env["S3_REGION"] = os.Getenv("S3_REGION")
-			env["S3_ACCESS_KEY_ID"] = os.Getenv("S3_ACCESS_KEY_ID")
-			env["S3_SECRET_ACCESS_KEY"] = os.Getenv("S3_SECRET_ACCESS_KEY")
+	if b.ig.IsControlPlane() {
+		for k, v := range StateStoreEnvironmentVariables() {
+			env[k] = v
 		}
 	}
 
@@ -369,6 +392,14 @@ func (b *BootstrapScript) Run(c *fi.CloudupContext) error {
 			return nil, err
 		}
 
+		if b.ig.Spec.UserDataFormat == kops.UserDataFormatIgnition {
+			ignitionUserData, err := resources.IgnitionConfig(nodeupScript, fi.ValueOf(b.ig.Spec.CompressUserData))
+			if err != nil {
+				return nil, err
+			}
+			return []byte(ignitionUserData), nil
+		}
+
 		awsUserData, err := resources.AWSMultipartMIME(nodeupScript, b.ig)
 		if err != nil {
 			return nil, err
@@ -40,6 +40,7 @@ import (
 	"k8s.io/kops/upup/pkg/fi/cloudup/do"
 	"k8s.io/kops/upup/pkg/fi/cloudup/gce"
 	"k8s.io/kops/upup/pkg/fi/cloudup/hetzner"
+	"k8s.io/kops/upup/pkg/fi/cloudup/metal"
 	"k8s.io/kops/upup/pkg/fi/cloudup/openstack"
 	"k8s.io/kops/upup/pkg/fi/cloudup/scaleway"
 	"k8s.io/kops/upup/pkg/fi/fitasks"
@@ -208,7 +209,8 @@ spec:
     emptyDir: {}
 `
 
-const kopsUtilsImage = "registry.k8s.io/kops/kops-utils-cp:1.30.0-alpha.1"
+// KopsUtilsImage is the image of kops-utils-cp, used to copy binaries between containers.
+const KopsUtilsImage = "registry.k8s.io/kops/kops-utils-cp:1.30.0-alpha.1"
 
 // buildPod creates the pod spec, based on the EtcdClusterSpec
 func (b *EtcdManagerBuilder) buildPod(etcdCluster kops.EtcdClusterSpec, instanceGroupName string) (*v1.Pod, error) {
@@ -246,7 +248,7 @@ func (b *EtcdManagerBuilder) buildPod(etcdCluster kops.EtcdClusterSpec, instance
 		{
 			initContainer := v1.Container{
 				Name:    "kops-utils-cp",
-				Image:   kopsUtilsImage,
+				Image:   KopsUtilsImage,
 				Command: []string{"/ko-app/kops-utils-cp"},
 				Args: []string{
 					"--target-dir=/opt/kops-utils/",
@@ -291,7 +293,7 @@ func (b *EtcdManagerBuilder) buildPod(etcdCluster kops.EtcdClusterSpec, instance
 
 			initContainer := v1.Container{
 				Name:         "init-etcd-symlinks-" + strings.ReplaceAll(symlinkToVersion, ".", "-"),
-				Image:        kopsUtilsImage,
+				Image:        KopsUtilsImage,
 				Command:      []string{"/opt/kops-utils/kops-utils-cp"},
 				VolumeMounts: utilMounts,
 			}
@@ -525,6 +527,13 @@ func (b *EtcdManagerBuilder) buildPod(etcdCluster kops.EtcdClusterSpec, instance
 				fmt.Sprintf("%s=%s", scaleway.TagNameRolePrefix, scaleway.TagRoleControlPlane),
 			}
 			config.VolumeNameTag = fmt.Sprintf("%s=%s", scaleway.TagInstanceGroup, instanceGroupName)
+
+		case kops.CloudProviderMetal:
+			config.VolumeProvider = "external"
+
+			config.VolumeTag = []string{
+				metal.EtcdVolumeTag(b.Cluster.Name, etcdCluster.Name),
+			}
 		default:
 			return nil, fmt.Errorf("CloudProvider %q not supported with etcd-manager", b.Cluster.Spec.GetCloudProvider())
 		}
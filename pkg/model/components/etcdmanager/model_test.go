@@ -37,6 +37,7 @@ func Test_RunEtcdManagerBuilder(t *testing.T) {
 		"tests/interval",
 		"tests/proxy",
 		"tests/overwrite_settings",
+		"tests/metal",
 	}
 	for _, basedir := range tests {
 		basedir := basedir
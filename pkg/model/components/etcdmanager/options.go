@@ -49,6 +49,9 @@ func (b *EtcdManagerOptionsBuilder) BuildOptions(o interface{}) error {
 			base := clusterSpec.ConfigStore.Base
 			etcdCluster.Backups.BackupStore = urls.Join(base, "backups", "etcd", etcdCluster.Name)
 		}
+		if etcdCluster.Backups.Verification != nil && etcdCluster.Backups.Verification.Schedule == "" {
+			etcdCluster.Backups.Verification.Schedule = "0 3 * * *"
+		}
 
 		if !etcdVersionIsSupported(etcdCluster.Version) {
 			if featureflag.SkipEtcdVersionCheck.Enabled() {
@@ -105,3 +108,18 @@ func etcdVersionIsSupported(version string) bool {
 	}
 	return false
 }
+
+// EtcdImage returns the image holding the etcd binaries used for the given etcd version.
+func EtcdImage(version string) (string, error) {
+	version = strings.TrimPrefix(version, "v")
+	for _, etcdVersion := range etcdSupportedImages {
+		if etcdVersion.Version != version {
+			continue
+		}
+		if etcdVersion.SymlinkToVersion != "" {
+			return EtcdImage(etcdVersion.SymlinkToVersion)
+		}
+		return etcdVersion.Image, nil
+	}
+	return "", fmt.Errorf("etcd version %q is not supported", version)
+}
@@ -43,9 +43,10 @@ type templateFunctions struct {
 	Cluster *kops.Cluster
 }
 
-// KopsControllerConfig returns the yaml configuration for kops-controller
+// GossipServices returns the headless services whose endpoints the hosts controller publishes as internal names, for gossip clusters.
+// Bare-metal clusters without DNS also get them, so that hosts and pods reach the control plane without a load balancer.
 func (t *templateFunctions) GossipServices() ([]*corev1.Service, error) {
-	if !t.Cluster.UsesLegacyGossip() {
+	if !t.Cluster.UsesLegacyGossip() && !(featureflag.Metal.Enabled() && t.Cluster.UsesNoneDNS()) {
 		return nil, nil
 	}
 
@@ -37,6 +37,7 @@ import (
 
 	"github.com/blang/semver/v4"
 	utilnet "k8s.io/apimachinery/pkg/util/net"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/klog/v2"
 )
 
@@ -134,6 +135,37 @@ func (b *KopsModelContext) NodeInstanceGroups() []*kops.InstanceGroup {
 	return groups
 }
 
+// SandboxRuntimes returns the names of the sandboxed runtimes enabled on any instance group, sorted.
+func (b *KopsModelContext) SandboxRuntimes() []string {
+	runtimes := sets.New[string]()
+	for _, ig := range b.InstanceGroups {
+		runtimes.Insert(ig.Spec.Containerd.SandboxRuntimes()...)
+	}
+	return sets.List(runtimes)
+}
+
+// UsesInPlaceUpdates returns true if any instance group applies changes to the node configuration in place.
+func (b *KopsModelContext) UsesInPlaceUpdates() bool {
+	for _, ig := range b.InstanceGroups {
+		if ig.UsesInPlaceUpdates(b.Cluster) {
+			return true
+		}
+	}
+	return false
+}
+
+// UsesVolumeEncryptionKeys returns true if any instance group encrypts volumes with keys from kops-controller.
+func (b *KopsModelContext) UsesVolumeEncryptionKeys() bool {
+	for _, ig := range b.InstanceGroups {
+		for _, volumeMount := range ig.Spec.VolumeMounts {
+			if volumeMount.Encryption != nil && volumeMount.Encryption.KeySource == kops.VolumeEncryptionKeySourceKopsController {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // CloudTagsForInstanceGroup computes the tags to apply to instances in the specified InstanceGroup
 func (b *KopsModelContext) CloudTagsForInstanceGroup(ig *kops.InstanceGroup) (map[string]string, error) {
 	labels := b.CloudTags(b.AutoscalingGroupName(ig), false)
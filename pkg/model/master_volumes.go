@@ -110,6 +110,8 @@ func (b *MasterVolumeBuilder) Build(c *fi.CloudupModelBuilderContext) error {
 				b.addGCEVolume(c, prefix, volumeSize, zone, etcd, m, allMembers)
 			case kops.CloudProviderHetzner:
 				b.addHetznerVolume(c, name, volumeSize, zone, etcd, m, allMembers)
+			case kops.CloudProviderMetal:
+				// etcd keeps its data in a directory on the local disks of bare-metal hosts
 			case kops.CloudProviderOpenstack:
 				err = b.addOpenstackVolume(c, name, volumeSize, zone, etcd, m, allMembers)
 				if err != nil {
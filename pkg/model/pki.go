@@ -17,6 +17,7 @@ limitations under the License.
 package model
 
 import (
+	"k8s.io/kops/pkg/apis/nodeup"
 	"k8s.io/kops/pkg/tokens"
 	"k8s.io/kops/upup/pkg/fi"
 	"k8s.io/kops/upup/pkg/fi/fitasks"
@@ -68,6 +69,11 @@ func (b *PKIModelBuilder) Build(c *fi.CloudupModelBuilderContext) error {
 		c.AddTask(&fitasks.Secret{Name: fi.PtrTo(x), Lifecycle: b.Lifecycle})
 	}
 
+	// kops-controller derives the keys of encrypted volumes from this secret
+	if b.UsesVolumeEncryptionKeys() {
+		c.AddTask(&fitasks.Secret{Name: fi.PtrTo(nodeup.VolumeEncryptionSecretName), Lifecycle: b.Lifecycle})
+	}
+
 	{
 		mirrorPath, err := vfs.Context.BuildVfsPath(b.Cluster.Spec.ConfigStore.Secrets)
 		if err != nil {
@@ -0,0 +1,136 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"k8s.io/kops/pkg/systemd"
+)
+
+const (
+	// ignitionVersion is the version of the Ignition config spec, supported by Fedora CoreOS 36 and later
+	ignitionVersion = "3.3.0"
+
+	// ignitionBootstrapScriptPath is where the Ignition config writes the nodeup (bootstrap) script
+	ignitionBootstrapScriptPath = "/var/lib/kops/bootstrap/nodeup.sh"
+
+	// ignitionBootstrapDonePath marks that the bootstrap script has run; nodeup runs on later boots from its own unit
+	ignitionBootstrapDonePath = "/var/lib/kops/bootstrap/done"
+
+	// ignitionBootstrapServiceName is the name of the systemd unit that runs the bootstrap script
+	ignitionBootstrapServiceName = "kops-bootstrap.service"
+)
+
+type ignitionConfig struct {
+	Ignition ignitionMetadata `json:"ignition"`
+	Storage  ignitionStorage  `json:"storage"`
+	Systemd  ignitionSystemd  `json:"systemd"`
+}
+
+type ignitionMetadata struct {
+	Version string `json:"version"`
+}
+
+type ignitionStorage struct {
+	Files []ignitionFile `json:"files"`
+}
+
+type ignitionFile struct {
+	Path      string           `json:"path"`
+	Mode      int              `json:"mode"`
+	Overwrite bool             `json:"overwrite"`
+	Contents  ignitionContents `json:"contents"`
+}
+
+type ignitionContents struct {
+	Source      string `json:"source"`
+	Compression string `json:"compression,omitempty"`
+}
+
+type ignitionSystemd struct {
+	Units []ignitionUnit `json:"units"`
+}
+
+type ignitionUnit struct {
+	Name     string `json:"name"`
+	Enabled  bool   `json:"enabled"`
+	Contents string `json:"contents"`
+}
+
+// IgnitionConfig returns an Ignition config that writes the nodeup (bootstrap) script
+// and runs it from a systemd unit, for distributions that don't run shell user data, like Fedora CoreOS
+func IgnitionConfig(bootScript string, compress bool) (string, error) {
+	contents := ignitionContents{
+		Source: "data:;base64," + base64.StdEncoding.EncodeToString([]byte(bootScript)),
+	}
+	if compress {
+		data, err := gzipBase64(bootScript)
+		if err != nil {
+			return "", fmt.Errorf("error compressing bootstrap script: %w", err)
+		}
+		contents = ignitionContents{
+			Source:      "data:;base64," + data,
+			Compression: "gzip",
+		}
+	}
+
+	manifest := &systemd.Manifest{}
+	manifest.Set("Unit", "Description", "Bootstrap the node with kOps nodeup")
+	manifest.Set("Unit", "Documentation", "https://github.com/kubernetes/kops")
+	manifest.Set("Unit", "Wants", "network-online.target")
+	manifest.Set("Unit", "After", "network-online.target")
+	manifest.Set("Unit", "ConditionPathExists", "!"+ignitionBootstrapDonePath)
+	manifest.Set("Service", "Type", "oneshot")
+	manifest.Set("Service", "RemainAfterExit", "yes")
+	manifest.Set("Service", "ExecStart", "/bin/bash "+ignitionBootstrapScriptPath)
+	manifest.Set("Service", "ExecStartPost", "/usr/bin/touch "+ignitionBootstrapDonePath)
+	manifest.Set("Install", "WantedBy", "multi-user.target")
+
+	config := ignitionConfig{
+		Ignition: ignitionMetadata{
+			Version: ignitionVersion,
+		},
+		Storage: ignitionStorage{
+			Files: []ignitionFile{
+				{
+					Path:      ignitionBootstrapScriptPath,
+					Mode:      0o700,
+					Overwrite: true,
+					Contents:  contents,
+				},
+			},
+		},
+		Systemd: ignitionSystemd{
+			Units: []ignitionUnit{
+				{
+					Name:     ignitionBootstrapServiceName,
+					Enabled:  true,
+					Contents: manifest.Render(),
+				},
+			},
+		},
+	}
+
+	b, err := json.Marshal(config)
+	if err != nil {
+		return "", fmt.Errorf("error building Ignition config: %w", err)
+	}
+	return string(b), nil
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+)
+
+func Test_IgnitionConfig(t *testing.T) {
+	bootScript := "#!/bin/bash\necho hello\n"
+
+	for _, compress := range []bool{false, true} {
+		userData, err := IgnitionConfig(bootScript, compress)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		config := &ignitionConfig{}
+		if err := json.Unmarshal([]byte(userData), config); err != nil {
+			t.Fatalf("user data is not valid JSON: %v", err)
+		}
+		if config.Ignition.Version != ignitionVersion {
+			t.Errorf("unexpected version %q", config.Ignition.Version)
+		}
+
+		if len(config.Storage.Files) != 1 {
+			t.Fatalf("expected one file, got %d", len(config.Storage.Files))
+		}
+		file := config.Storage.Files[0]
+		if file.Path != ignitionBootstrapScriptPath {
+			t.Errorf("unexpected file path %q", file.Path)
+		}
+		data, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(file.Contents.Source, "data:;base64,"))
+		if err != nil {
+			t.Fatalf("error decoding contents: %v", err)
+		}
+		if compress {
+			if file.Contents.Compression != "gzip" {
+				t.Errorf("expected gzip compression, got %q", file.Contents.Compression)
+			}
+			gz, err := gzip.NewReader(bytes.NewReader(data))
+			if err != nil {
+				t.Fatalf("error decompressing contents: %v", err)
+			}
+			if data, err = io.ReadAll(gz); err != nil {
+				t.Fatalf("error decompressing contents: %v", err)
+			}
+		}
+		if string(data) != bootScript {
+			t.Errorf("unexpected contents %q", string(data))
+		}
+
+		if len(config.Systemd.Units) != 1 {
+			t.Fatalf("expected one unit, got %d", len(config.Systemd.Units))
+		}
+		unit := config.Systemd.Units[0]
+		if unit.Name != ignitionBootstrapServiceName || !unit.Enabled {
+			t.Errorf("unexpected unit %q (enabled=%v)", unit.Name, unit.Enabled)
+		}
+		if !strings.Contains(unit.Contents, "ExecStart=/bin/bash "+ignitionBootstrapScriptPath) {
+			t.Errorf("unit doesn't run the bootstrap script:\n%s", unit.Contents)
+		}
+	}
+}
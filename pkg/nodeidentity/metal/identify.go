@@ -0,0 +1,64 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metal
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/kops/pkg/apis/kops/v1alpha2"
+	"k8s.io/kops/pkg/nodeidentity"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// nodeIdentifier identifies a node from the Host it was enrolled as
+type nodeIdentifier struct {
+	client client.Client
+}
+
+// New creates and returns a nodeidentity.LegacyIdentifier for Nodes running on bare-metal hosts
+func New(client client.Client) (nodeidentity.LegacyIdentifier, error) {
+	return &nodeIdentifier{
+		client: client,
+	}, nil
+}
+
+// IdentifyNode returns the instance group of the Host a node was enrolled as.
+// Control-plane hosts are enrolled before there is an API to record them in, so they don't have a Host.
+func (i *nodeIdentifier) IdentifyNode(ctx context.Context, node *corev1.Node) (*nodeidentity.LegacyInfo, error) {
+	info := &nodeidentity.LegacyInfo{
+		InstanceID: node.Name,
+	}
+
+	host := &v1alpha2.Host{}
+	id := types.NamespacedName{
+		Namespace: "kops-system",
+		Name:      node.Name,
+	}
+	if err := i.client.Get(ctx, id, host); err != nil {
+		if apierrors.IsNotFound(err) {
+			return info, nil
+		}
+		return nil, fmt.Errorf("error getting host %v: %w", id, err)
+	}
+	info.InstanceGroup = host.Spec.InstanceGroup
+
+	return info, nil
+}
@@ -37,6 +37,7 @@ import (
 	"k8s.io/kops/pkg/wellknownservices"
 	"k8s.io/kops/upup/pkg/fi"
 	"k8s.io/kops/util/pkg/architectures"
+	"k8s.io/kops/util/pkg/hashing"
 	"k8s.io/kops/util/pkg/vfs"
 )
 
@@ -56,9 +57,10 @@ type nodeUpConfigBuilder struct {
 	protokubeAsset             map[architectures.Architecture][]*assets.MirroredAsset
 	channelsAsset              map[architectures.Architecture][]*assets.MirroredAsset
 	encryptionConfigSecretHash string
+	gossipKeyset               *nodeup.GossipKeyset
 }
 
-func NewNodeUpConfigBuilder(cluster *kops.Cluster, assetBuilder *assets.AssetBuilder, nodeAssets map[architectures.Architecture][]*assets.MirroredAsset, encryptionConfigSecretHash string) (model.NodeUpConfigBuilder, error) {
+func NewNodeUpConfigBuilder(cluster *kops.Cluster, assetBuilder *assets.AssetBuilder, nodeAssets map[architectures.Architecture][]*assets.MirroredAsset, encryptionConfigSecretHash string, gossipKeyset *nodeup.GossipKeyset) (model.NodeUpConfigBuilder, error) {
 	configBase, err := vfs.Context.BuildVfsPath(cluster.Spec.ConfigStore.Base)
 	if err != nil {
 		return nil, fmt.Errorf("error parsing configStore.base %q: %v", cluster.Spec.ConfigStore.Base, err)
@@ -202,6 +204,7 @@ func NewNodeUpConfigBuilder(cluster *kops.Cluster, assetBuilder *assets.AssetBui
 		protokubeAsset:             protokubeAsset,
 		channelsAsset:              channelsAsset,
 		encryptionConfigSecretHash: encryptionConfigSecretHash,
+		gossipKeyset:               gossipKeyset,
 	}
 
 	return &configBuilder, nil
@@ -226,6 +229,10 @@ func (n *nodeUpConfigBuilder) BuildConfig(ig *kops.InstanceGroup, wellKnownAddre
 
 	config, bootConfig := nodeup.NewConfig(cluster, ig)
 
+	if usesLegacyGossip {
+		config.GossipKeyset = n.gossipKeyset
+	}
+
 	config.Assets = make(map[architectures.Architecture][]string)
 	for _, arch := range architectures.GetSupported() {
 		config.Assets[arch] = []string{}
@@ -234,6 +241,10 @@ func (n *nodeUpConfigBuilder) BuildConfig(ig *kops.InstanceGroup, wellKnownAddre
 		}
 	}
 
+	if err := n.addSandboxRuntimeAssets(config); err != nil {
+		return nil, nil, err
+	}
+
 	if role != kops.InstanceGroupRoleBastion {
 		if err := loadCertificates(keysets, fi.CertificateIDCA, config, true); err != nil {
 			return nil, nil, err
@@ -346,7 +357,7 @@ func (n *nodeUpConfigBuilder) BuildConfig(ig *kops.InstanceGroup, wellKnownAddre
 			}
 		}
 
-	case kops.CloudProviderDO, kops.CloudProviderScaleway, kops.CloudProviderAzure:
+	case kops.CloudProviderDO, kops.CloudProviderScaleway, kops.CloudProviderAzure, kops.CloudProviderMetal:
 		// Use any IP address that is found (including public ones)
 		for _, additionalIP := range wellKnownAddresses[wellknownservices.KubeAPIServer] {
 			controlPlaneIPs = append(controlPlaneIPs, additionalIP)
@@ -443,9 +454,44 @@ func (n *nodeUpConfigBuilder) BuildConfig(ig *kops.InstanceGroup, wellKnownAddre
 	config.Packages = append(config.Packages, cluster.Spec.Packages...)
 	config.Packages = append(config.Packages, ig.Spec.Packages...)
 
+	prepulledImages, err := n.buildPrepulledImages(ig)
+	if err != nil {
+		return nil, nil, err
+	}
+	config.PrepulledImages = prepulledImages
+
 	return config, bootConfig, nil
 }
 
+// addSandboxRuntimeAssets adds the assets of the sandboxed runtimes enabled on the instance group
+func (n *nodeUpConfigBuilder) addSandboxRuntimeAssets(config *nodeup.Config) error {
+	containerd := config.ContainerdConfig
+	if containerd == nil || containerd.SkipInstall {
+		return nil
+	}
+
+	for _, arch := range architectures.GetSupported() {
+		if containerd.GVisor != nil && fi.ValueOf(containerd.GVisor.Enabled) {
+			fileAssets, err := wellknownassets.FindGVisorAssets(containerd.GVisor, n.assetBuilder, arch)
+			if err != nil {
+				return err
+			}
+			for _, a := range fileAssets {
+				config.Assets[arch] = append(config.Assets[arch], assets.BuildMirroredAsset(a).CompactString())
+			}
+		}
+		if containerd.Kata != nil && fi.ValueOf(containerd.Kata.Enabled) {
+			a, err := wellknownassets.FindKataAsset(containerd.Kata, n.assetBuilder, arch)
+			if err != nil {
+				return err
+			}
+			config.Assets[arch] = append(config.Assets[arch], assets.BuildMirroredAsset(a).CompactString())
+		}
+	}
+
+	return nil
+}
+
 func loadCertificates(keysets map[string]*fi.Keyset, name string, config *nodeup.Config, includeKeypairID bool) error {
 	keyset := keysets[name]
 	if keyset == nil {
@@ -465,6 +511,55 @@ func loadCertificates(keysets map[string]*fi.Keyset, name string, config *nodeup
 	return nil
 }
 
+// buildPrepulledImages returns the container images of the instance group to pull or load before the kubelet starts,
+// remapped to the assets repositories
+func (n *nodeUpConfigBuilder) buildPrepulledImages(ig *kops.InstanceGroup) ([]*nodeup.Image, error) {
+	var images []*nodeup.Image
+	for _, spec := range ig.Spec.PrepulledImages {
+		if spec.Source != "" {
+			source, err := url.Parse(spec.Source)
+			if err != nil {
+				return nil, fmt.Errorf("parsing source of image %q: %w", spec.Source, err)
+			}
+			hash, err := hashing.FromString(spec.Hash)
+			if err != nil {
+				return nil, fmt.Errorf("parsing hash of image %q: %w", spec.Source, err)
+			}
+			if n.assetBuilder != nil {
+				asset, err := n.assetBuilder.RemapFile(source, hash)
+				if err != nil {
+					return nil, err
+				}
+				source = asset.DownloadURL
+			}
+			images = append(images, &nodeup.Image{
+				Name:    spec.Image,
+				Sources: []string{source.String()},
+				Hash:    hash.Hex(),
+			})
+			continue
+		}
+
+		name := spec.Image
+		if spec.Digest != "" {
+			// The image is pinned before remapping, so that the digest is the one whose signature is verified
+			name, _, _ = strings.Cut(name, "@")
+			name += "@" + spec.Digest
+		}
+		if n.assetBuilder != nil {
+			remapped, err := n.assetBuilder.RemapImage(name)
+			if err != nil {
+				return nil, err
+			}
+			name = remapped
+		}
+		images = append(images, &nodeup.Image{
+			Name: name,
+		})
+	}
+	return images, nil
+}
+
 // buildWarmPoolImages returns a list of container images that should be pre-pulled during instance pre-initialization
 func (n *nodeUpConfigBuilder) buildWarmPoolImages(ig *kops.InstanceGroup) []string {
 	if ig == nil || ig.Spec.Role == kops.InstanceGroupRoleControlPlane {
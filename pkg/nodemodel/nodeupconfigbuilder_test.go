@@ -0,0 +1,109 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodemodel
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/apis/nodeup"
+	"k8s.io/kops/pkg/assets"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/util/pkg/vfs"
+)
+
+func TestBuildPrepulledImages(t *testing.T) {
+	const (
+		digest = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+		hash   = "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	)
+
+	ig := &kops.InstanceGroup{
+		Spec: kops.InstanceGroupSpec{
+			PrepulledImages: []kops.PrepulledImageSpec{
+				{
+					Image: "registry.example.com/ml/trainer:v1.2",
+				},
+				{
+					Image:  "registry.example.com/ml/model:v3",
+					Digest: digest,
+				},
+				{
+					Image:  "registry.example.com/ml/dataset:v7",
+					Source: "https://assets.example.com/images/dataset.tar.gz",
+					Hash:   hash,
+				},
+			},
+		},
+	}
+
+	grid := []struct {
+		name     string
+		assets   *kops.AssetsSpec
+		expected []*nodeup.Image
+	}{
+		{
+			name:   "no assets",
+			assets: &kops.AssetsSpec{},
+			expected: []*nodeup.Image{
+				{Name: "registry.example.com/ml/trainer:v1.2"},
+				{Name: "registry.example.com/ml/model:v3@" + digest},
+				{
+					Name:    "registry.example.com/ml/dataset:v7",
+					Sources: []string{"https://assets.example.com/images/dataset.tar.gz"},
+					Hash:    hash,
+				},
+			},
+		},
+		{
+			name: "assets repositories",
+			assets: &kops.AssetsSpec{
+				ContainerRegistry: fi.PtrTo("registry.mirror.example.org"),
+				FileRepository:    fi.PtrTo("https://files.mirror.example.org/"),
+			},
+			expected: []*nodeup.Image{
+				{Name: "registry.mirror.example.org/registry.example.com-ml-trainer:v1.2"},
+				{Name: "registry.mirror.example.org/registry.example.com-ml-model:v3@" + digest},
+				{
+					Name:    "registry.example.com/ml/dataset:v7",
+					Sources: []string{"https://files.mirror.example.org/images/dataset.tar.gz"},
+					Hash:    hash,
+				},
+			},
+		},
+	}
+
+	for _, g := range grid {
+		t.Run(g.name, func(t *testing.T) {
+			n := &nodeUpConfigBuilder{
+				assetBuilder: assets.NewAssetBuilder(vfs.Context, g.assets, "1.30.0", false),
+			}
+
+			images, err := n.buildPrepulledImages(ig)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(images, g.expected) {
+				for _, image := range images {
+					t.Logf("actual: %+v", image)
+				}
+				t.Fatalf("unexpected images")
+			}
+		})
+	}
+}
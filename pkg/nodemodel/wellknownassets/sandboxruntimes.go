@@ -0,0 +1,123 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package wellknownassets
+
+import (
+	"fmt"
+
+	"github.com/blang/semver/v4"
+
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/assets"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/util/pkg/architectures"
+)
+
+const (
+	// gVisor publishes the binaries individually, with sha512 hashes
+	gVisorReleaseUrl = "https://storage.googleapis.com/gvisor/releases/release/%s/%s/%s"
+	// Kata Containers publishes a static archive, meant to be extracted to /
+	kataReleaseUrl = "https://github.com/kata-containers/kata-containers/releases/download/%s/kata-static-%s-%s.tar.xz"
+)
+
+// gVisorBinaries are the gVisor binaries installed on the nodes
+var gVisorBinaries = []string{"runsc", "containerd-shim-runsc-v1"}
+
+// FindGVisorAssets returns the gVisor binaries for the architecture.
+func FindGVisorAssets(gvisor *kops.GVisorConfig, assetBuilder *assets.AssetBuilder, arch architectures.Architecture) ([]*assets.FileAsset, error) {
+	version := fi.ValueOf(gvisor.Version)
+	if version == "" {
+		return nil, fmt.Errorf("unable to find gVisor version")
+	}
+
+	var fileAssets []*assets.FileAsset
+	for _, binary := range gVisorBinaries {
+		u, err := findGVisorVersionUrl(arch, version, binary)
+		if err != nil {
+			return nil, err
+		}
+		asset, err := buildFileAsset(assetBuilder, u, "")
+		if err != nil {
+			return nil, err
+		}
+		fileAssets = append(fileAssets, asset)
+	}
+
+	return fileAssets, nil
+}
+
+func findGVisorVersionUrl(arch architectures.Architecture, version string, binary string) (string, error) {
+	var gVisorArch string
+	switch arch {
+	case architectures.ArchitectureAmd64:
+		gVisorArch = "x86_64"
+	case architectures.ArchitectureArm64:
+		gVisorArch = "aarch64"
+	default:
+		return "", fmt.Errorf("unknown arch: %q", arch)
+	}
+
+	return fmt.Sprintf(gVisorReleaseUrl, version, gVisorArch, binary), nil
+}
+
+// FindKataAsset returns the Kata Containers static archive for the architecture.
+func FindKataAsset(kata *kops.KataConfig, assetBuilder *assets.AssetBuilder, arch architectures.Architecture) (*assets.FileAsset, error) {
+	canonicalURL := ""
+	knownHash := ""
+
+	if kata.Packages != nil {
+		if arch == architectures.ArchitectureAmd64 && kata.Packages.UrlAmd64 != nil && kata.Packages.HashAmd64 != nil {
+			canonicalURL = fi.ValueOf(kata.Packages.UrlAmd64)
+			knownHash = fi.ValueOf(kata.Packages.HashAmd64)
+		}
+		if arch == architectures.ArchitectureArm64 && kata.Packages.UrlArm64 != nil && kata.Packages.HashArm64 != nil {
+			canonicalURL = fi.ValueOf(kata.Packages.UrlArm64)
+			knownHash = fi.ValueOf(kata.Packages.HashArm64)
+		}
+	}
+
+	if canonicalURL == "" {
+		version := fi.ValueOf(kata.Version)
+		if version == "" {
+			return nil, fmt.Errorf("unable to find Kata Containers version")
+		}
+		u, err := findKataVersionUrl(arch, version)
+		if err != nil {
+			return nil, err
+		}
+		canonicalURL = u
+	}
+
+	return buildFileAsset(assetBuilder, canonicalURL, knownHash)
+}
+
+func findKataVersionUrl(arch architectures.Architecture, version string) (string, error) {
+	sv, err := semver.ParseTolerant(version)
+	if err != nil {
+		return "", fmt.Errorf("unable to parse version string: %q", version)
+	}
+	if sv.Major < 3 {
+		return "", fmt.Errorf("unsupported Kata Containers version: %q", version)
+	}
+
+	switch arch {
+	case architectures.ArchitectureAmd64, architectures.ArchitectureArm64:
+		return fmt.Sprintf(kataReleaseUrl, version, version, arch), nil
+	default:
+		return "", fmt.Errorf("unknown arch: %q", arch)
+	}
+}
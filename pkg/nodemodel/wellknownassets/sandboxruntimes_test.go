@@ -0,0 +1,123 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package wellknownassets
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"k8s.io/kops/util/pkg/architectures"
+)
+
+func TestGVisorVersionUrl(t *testing.T) {
+	tests := []struct {
+		version string
+		arch    architectures.Architecture
+		binary  string
+		url     string
+		err     error
+	}{
+		{
+			arch:    "arm",
+			version: "20240401.0",
+			binary:  "runsc",
+			url:     "",
+			err:     fmt.Errorf("unknown arch: \"arm\""),
+		},
+		{
+			arch:    architectures.ArchitectureAmd64,
+			version: "20240401.0",
+			binary:  "runsc",
+			url:     "https://storage.googleapis.com/gvisor/releases/release/20240401.0/x86_64/runsc",
+			err:     nil,
+		},
+		{
+			arch:    architectures.ArchitectureArm64,
+			version: "20240401.0",
+			binary:  "containerd-shim-runsc-v1",
+			url:     "https://storage.googleapis.com/gvisor/releases/release/20240401.0/aarch64/containerd-shim-runsc-v1",
+			err:     nil,
+		},
+	}
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%s-%s-%s", test.arch, test.version, test.binary), func(t *testing.T) {
+			url, err := findGVisorVersionUrl(test.arch, test.version, test.binary)
+			if !reflect.DeepEqual(err, test.err) {
+				t.Errorf("actual error %q differs from expected error %q", err, test.err)
+				return
+			}
+			if url != test.url {
+				t.Errorf("actual url %q differs from expected url %q", url, test.url)
+				return
+			}
+		})
+	}
+}
+
+func TestKataVersionUrl(t *testing.T) {
+	tests := []struct {
+		version string
+		arch    architectures.Architecture
+		url     string
+		err     error
+	}{
+		{
+			arch:    "arm",
+			version: "3.5.0",
+			url:     "",
+			err:     fmt.Errorf("unknown arch: \"arm\""),
+		},
+		{
+			arch:    architectures.ArchitectureAmd64,
+			version: "",
+			url:     "",
+			err:     fmt.Errorf("unable to parse version string: \"\""),
+		},
+		{
+			arch:    architectures.ArchitectureAmd64,
+			version: "2.5.2",
+			url:     "",
+			err:     fmt.Errorf("unsupported Kata Containers version: \"2.5.2\""),
+		},
+		{
+			arch:    architectures.ArchitectureAmd64,
+			version: "3.5.0",
+			url:     "https://github.com/kata-containers/kata-containers/releases/download/3.5.0/kata-static-3.5.0-amd64.tar.xz",
+			err:     nil,
+		},
+		{
+			arch:    architectures.ArchitectureArm64,
+			version: "3.5.0",
+			url:     "https://github.com/kata-containers/kata-containers/releases/download/3.5.0/kata-static-3.5.0-arm64.tar.xz",
+			err:     nil,
+		},
+	}
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%s-%s", test.arch, test.version), func(t *testing.T) {
+			url, err := findKataVersionUrl(test.arch, test.version)
+			if !reflect.DeepEqual(err, test.err) {
+				t.Errorf("actual error %q differs from expected error %q", err, test.err)
+				return
+			}
+			if url != test.url {
+				t.Errorf("actual url %q differs from expected url %q", url, test.url)
+				return
+			}
+		})
+	}
+}
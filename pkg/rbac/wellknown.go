@@ -32,4 +32,13 @@ const (
 	KubeRouter            = "system:kube-router"
 	KubeControllerManager = "system:kube-controller-manager"
 	KubeScheduler         = "system:kube-scheduler"
+
+	// KopsAdminsGroup is the group of the admin credentials issued by kops when they can be revoked;
+	// it is bound to the cluster-admin role.
+	KopsAdminsGroup = "kops:admins"
+	// KopsAdminCredentialGroupPrefix prefixes the group identifying the serial of such an admin credential.
+	KopsAdminCredentialGroupPrefix = "kops:admin-credential:"
+	// KopsAdminCredentialReviewer is the identity kube-apiserver authenticates to kops-controller with
+	// to review the requests of admin credentials.
+	KopsAdminCredentialReviewer = "kops:admin-credential-reviewer"
 )
@@ -470,6 +470,15 @@ func guessSSHUser(image *ec2types.Image) string {
 		return "ubuntu"
 	case awsup.WellKnownAccountFlatcar:
 		return "core"
+	case awsup.WellKnownAccountRocky:
+		return "rocky"
+	case awsup.WellKnownAccountAlmaLinux:
+		return "ec2-user"
+	case awsup.WellKnownAccountFedora:
+		if strings.HasPrefix(strings.ToLower(aws.ToString(image.Name)), "fedora-coreos") {
+			return "core"
+		}
+		return "fedora"
 	}
 
 	name := aws.ToString(image.Name)
@@ -0,0 +1,115 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sizing
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/scaleway/scaleway-sdk-go/api/instance/v1"
+	"github.com/scaleway/scaleway-sdk-go/scw"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/cloudup/awsup"
+	"k8s.io/kops/upup/pkg/fi/cloudup/scaleway"
+	"k8s.io/kops/util/pkg/architectures"
+)
+
+// ListMachineTypes returns the machine types offered by the cloud provider, for the clouds that we can list them on.
+// It returns no machine types for the other clouds.
+// Machine types with GPUs and bare-metal machine types are left out, as they are not meant for the control plane.
+func ListMachineTypes(ctx context.Context, cloud fi.Cloud) ([]MachineType, error) {
+	switch c := cloud.(type) {
+	case awsup.AWSCloud:
+		return listAWSMachineTypes(ctx, c)
+	case scaleway.ScwCloud:
+		return listScalewayMachineTypes(c)
+	default:
+		return nil, nil
+	}
+}
+
+func listAWSMachineTypes(ctx context.Context, c awsup.AWSCloud) ([]MachineType, error) {
+	var machineTypes []MachineType
+	request := &ec2.DescribeInstanceTypesInput{
+		Filters: []ec2types.Filter{awsup.NewEC2Filter("current-generation", "true")},
+	}
+	paginator := ec2.NewDescribeInstanceTypesPaginator(c.EC2(), request)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("listing AWS instance types: %w", err)
+		}
+		for _, info := range page.InstanceTypes {
+			if info.GpuInfo != nil || aws.ToBool(info.BareMetal) || info.VCpuInfo == nil || info.MemoryInfo == nil || info.ProcessorInfo == nil {
+				continue
+			}
+			for _, arch := range info.ProcessorInfo.SupportedArchitectures {
+				var architecture architectures.Architecture
+				switch arch {
+				case ec2types.ArchitectureTypeX8664:
+					architecture = architectures.ArchitectureAmd64
+				case ec2types.ArchitectureTypeArm64:
+					architecture = architectures.ArchitectureArm64
+				default:
+					continue
+				}
+				machineTypes = append(machineTypes, MachineType{
+					Name:         string(info.InstanceType),
+					Architecture: string(architecture),
+					MilliCPU:     int64(aws.ToInt32(info.VCpuInfo.DefaultVCpus)) * 1000,
+					Memory:       aws.ToInt64(info.MemoryInfo.SizeInMiB) * 1024 * 1024,
+				})
+			}
+		}
+	}
+	return machineTypes, nil
+}
+
+func listScalewayMachineTypes(c scaleway.ScwCloud) ([]MachineType, error) {
+	response, err := c.InstanceService().ListServersTypes(&instance.ListServersTypesRequest{
+		Zone: scw.Zone(c.Zone()),
+	}, scw.WithAllPages())
+	if err != nil {
+		return nil, fmt.Errorf("listing Scaleway instance types: %w", err)
+	}
+
+	var machineTypes []MachineType
+	for name, serverType := range response.Servers {
+		if serverType == nil || serverType.Baremetal || (serverType.Gpu != nil && *serverType.Gpu != 0) {
+			continue
+		}
+		var architecture architectures.Architecture
+		switch serverType.Arch {
+		case instance.ArchX86_64:
+			architecture = architectures.ArchitectureAmd64
+		case instance.ArchArm64:
+			architecture = architectures.ArchitectureArm64
+		default:
+			continue
+		}
+		machineTypes = append(machineTypes, MachineType{
+			Name:         name,
+			Architecture: string(architecture),
+			MilliCPU:     int64(serverType.Ncpus) * 1000,
+			Memory:       int64(serverType.RAM),
+		})
+	}
+	return machineTypes, nil
+}
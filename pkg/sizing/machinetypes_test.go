@@ -0,0 +1,77 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sizing
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"k8s.io/kops/cloudmock/aws/mockec2"
+	"k8s.io/kops/upup/pkg/fi/cloudup/awsup"
+)
+
+func instanceTypeInfo(name string, arch ec2types.ArchitectureType, vCPUs int32, memoryMiB int64) ec2types.InstanceTypeInfo {
+	return ec2types.InstanceTypeInfo{
+		InstanceType:      ec2types.InstanceType(name),
+		CurrentGeneration: aws.Bool(true),
+		BareMetal:         aws.Bool(false),
+		ProcessorInfo:     &ec2types.ProcessorInfo{SupportedArchitectures: []ec2types.ArchitectureType{arch}},
+		VCpuInfo:          &ec2types.VCpuInfo{DefaultVCpus: aws.Int32(vCPUs)},
+		MemoryInfo:        &ec2types.MemoryInfo{SizeInMiB: aws.Int64(memoryMiB)},
+	}
+}
+
+func TestListAWSMachineTypes(t *testing.T) {
+	previousGeneration := instanceTypeInfo("m4.large", ec2types.ArchitectureTypeX8664, 2, 8192)
+	previousGeneration.CurrentGeneration = aws.Bool(false)
+	gpu := instanceTypeInfo("g5.xlarge", ec2types.ArchitectureTypeX8664, 4, 16384)
+	gpu.GpuInfo = &ec2types.GpuInfo{}
+	metal := instanceTypeInfo("m5.metal", ec2types.ArchitectureTypeX8664, 96, 393216)
+	metal.BareMetal = aws.Bool(true)
+
+	cloud := awsup.BuildMockAWSCloud("us-test-1", "a")
+	cloud.MockEC2 = &mockec2.MockEC2{
+		InstanceTypes: []ec2types.InstanceTypeInfo{
+			instanceTypeInfo("m5.large", ec2types.ArchitectureTypeX8664, 2, 8192),
+			instanceTypeInfo("m6g.xlarge", ec2types.ArchitectureTypeArm64, 4, 16384),
+			instanceTypeInfo("mac1.metal", ec2types.ArchitectureTypeX8664Mac, 12, 32768),
+			previousGeneration,
+			gpu,
+			metal,
+		},
+	}
+
+	machineTypes, err := ListMachineTypes(context.Background(), cloud)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sort.Slice(machineTypes, func(i, j int) bool {
+		return machineTypes[i].Name < machineTypes[j].Name
+	})
+
+	expected := []MachineType{
+		{Name: "m5.large", Architecture: "amd64", MilliCPU: 2000, Memory: 8 * gi},
+		{Name: "m6g.xlarge", Architecture: "arm64", MilliCPU: 4000, Memory: 16 * gi},
+	}
+	if !reflect.DeepEqual(machineTypes, expected) {
+		t.Errorf("unexpected machine types: got %+v, want %+v", machineTypes, expected)
+	}
+}
@@ -0,0 +1,364 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sizing
+
+import (
+	"sort"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/kops/pkg/apis/kops"
+)
+
+const (
+	// cpuHeadroom and memoryHeadroom are the ratios between the recommended requests and the peak usage.
+	cpuHeadroom    = 1.25
+	memoryHeadroom = 1.5
+
+	// cpuStep and memoryStep are the granularities of the recommended requests.
+	cpuStep    = 50
+	memoryStep = 64 * 1024 * 1024
+
+	// nodeUtilization is the share of the allocatable resources of a node the control plane should fit in.
+	nodeUtilization = 0.8
+)
+
+// ComponentRecommendation is the recommended requests of a control plane component.
+type ComponentRecommendation struct {
+	Component string
+
+	PeakCPU    resource.Quantity
+	PeakMemory resource.Quantity
+	// OOMKilled is true if the component ran out of memory on any node.
+	OOMKilled bool
+
+	// CurrentCPURequest and CurrentMemoryRequest are the requests set in the cluster spec, nil for the defaults.
+	CurrentCPURequest    *resource.Quantity
+	CurrentMemoryRequest *resource.Quantity
+
+	// RunningCPURequest and RunningMemoryRequest are the highest requests the component runs with, including defaults,
+	// nil if they are unknown.
+	RunningCPURequest    *resource.Quantity
+	RunningMemoryRequest *resource.Quantity
+
+	CPURequest    resource.Quantity
+	MemoryRequest resource.Quantity
+
+	// LowersCPURequest and LowersMemoryRequest are true if the recommended requests are lower than the current ones.
+	LowersCPURequest    bool
+	LowersMemoryRequest bool
+
+	// Configurable is true if the requests of the component can be set in the cluster spec.
+	Configurable bool
+}
+
+// InstanceGroupRecommendation is the recommended size of the nodes of a control plane instance group.
+type InstanceGroupRecommendation struct {
+	InstanceGroup string
+	MachineType   string
+
+	AllocatableCPU    resource.Quantity
+	AllocatableMemory resource.Quantity
+
+	// RequiredCPU and RequiredMemory are the resources the nodes should be able to allocate.
+	RequiredCPU    resource.Quantity
+	RequiredMemory resource.Quantity
+
+	// Fits is true if the current machine type is large enough.
+	Fits bool
+	// SuggestedMachineType is the smallest large enough machine type offered by the cloud provider,
+	// or, if the machine types of the cloud provider are unknown, among those of the control plane.
+	// It is empty if there is none.
+	SuggestedMachineType string
+}
+
+// MachineType is a machine type offered by the cloud provider.
+type MachineType struct {
+	Name string
+	// Architecture is the CPU architecture, like amd64 or arm64.
+	Architecture string
+
+	// MilliCPU is the CPU of the machine type, in millicores.
+	MilliCPU int64
+	// Memory is the memory of the machine type, in bytes.
+	Memory int64
+}
+
+// Recommendation is the recommended resources of the control plane of a cluster.
+type Recommendation struct {
+	Components     []*ComponentRecommendation
+	InstanceGroups []*InstanceGroupRecommendation
+}
+
+// Recommend recommends component requests and machine types from the observed usage of the control plane.
+// Machine types are suggested among machineTypes, the machine types offered by the cloud provider, if known.
+func Recommend(cluster *kops.Cluster, usage *Usage, machineTypes []MachineType) *Recommendation {
+	recommendation := &Recommendation{}
+
+	peaks := make(map[string]*ContainerUsage)
+	for _, containerUsage := range usage.Containers {
+		peak := peaks[containerUsage.Component]
+		if peak == nil {
+			peak = &ContainerUsage{Component: containerUsage.Component}
+			peaks[containerUsage.Component] = peak
+		}
+		peak.PeakMilliCPU = max(peak.PeakMilliCPU, containerUsage.PeakMilliCPU)
+		peak.PeakMemory = max(peak.PeakMemory, containerUsage.PeakMemory)
+		peak.OOMKilled = peak.OOMKilled || containerUsage.OOMKilled
+		peak.RequestMilliCPU = max(peak.RequestMilliCPU, containerUsage.RequestMilliCPU)
+		peak.RequestMemory = max(peak.RequestMemory, containerUsage.RequestMemory)
+	}
+
+	requests := make(map[string]*ComponentRecommendation)
+	for _, peak := range peaks {
+		r := &ComponentRecommendation{
+			Component:  peak.Component,
+			PeakCPU:    milliCPUQuantity(peak.PeakMilliCPU),
+			PeakMemory: memoryQuantity(peak.PeakMemory),
+			OOMKilled:  peak.OOMKilled,
+		}
+		r.CurrentCPURequest, r.CurrentMemoryRequest, r.Configurable = currentRequests(cluster, peak.Component)
+
+		milliCPU := roundUp(int64(float64(peak.PeakMilliCPU)*cpuHeadroom), cpuStep)
+		memory := roundUp(int64(float64(peak.PeakMemory)*memoryHeadroom), memoryStep)
+		if peak.OOMKilled && r.CurrentMemoryRequest != nil {
+			// The peak usage was reset by the restart, so grow from what did not fit.
+			memory = max(memory, roundUp(2*r.CurrentMemoryRequest.Value(), memoryStep))
+		}
+		r.CPURequest = milliCPUQuantity(milliCPU)
+		r.MemoryRequest = memoryQuantity(memory)
+
+		if peak.RequestMilliCPU != 0 {
+			q := milliCPUQuantity(peak.RequestMilliCPU)
+			r.RunningCPURequest = &q
+		}
+		if peak.RequestMemory != 0 {
+			q := memoryQuantity(peak.RequestMemory)
+			r.RunningMemoryRequest = &q
+		}
+		r.LowersCPURequest = lowers(r.CPURequest, r.CurrentCPURequest, r.RunningCPURequest)
+		r.LowersMemoryRequest = lowers(r.MemoryRequest, r.CurrentMemoryRequest, r.RunningMemoryRequest)
+
+		recommendation.Components = append(recommendation.Components, r)
+		requests[r.Component] = r
+	}
+	sort.Slice(recommendation.Components, func(i, j int) bool {
+		return recommendation.Components[i].Component < recommendation.Components[j].Component
+	})
+
+	// The nodes need to fit what they use today, plus the requests of the components above their usage.
+	required := make(map[string]*NodeUsage)
+	for _, nodeUsage := range usage.Nodes {
+		required[nodeUsage.Name] = &NodeUsage{
+			PeakMilliCPU: nodeUsage.PeakMilliCPU,
+			PeakMemory:   nodeUsage.PeakMemory,
+		}
+	}
+	for _, containerUsage := range usage.Containers {
+		r := required[containerUsage.Node]
+		if r == nil {
+			continue
+		}
+		request := requests[containerUsage.Component]
+		r.PeakMilliCPU += max(0, request.CPURequest.MilliValue()-containerUsage.PeakMilliCPU)
+		r.PeakMemory += max(0, request.MemoryRequest.Value()-containerUsage.PeakMemory)
+	}
+
+	instanceGroups := make(map[string]*InstanceGroupRecommendation)
+	for _, nodeUsage := range usage.Nodes {
+		ig := instanceGroups[nodeUsage.InstanceGroup]
+		if ig == nil {
+			ig = &InstanceGroupRecommendation{
+				InstanceGroup:     nodeUsage.InstanceGroup,
+				MachineType:       nodeUsage.MachineType,
+				AllocatableCPU:    milliCPUQuantity(nodeUsage.AllocatableMilliCPU),
+				AllocatableMemory: memoryQuantity(nodeUsage.AllocatableMemory),
+			}
+			instanceGroups[nodeUsage.InstanceGroup] = ig
+			recommendation.InstanceGroups = append(recommendation.InstanceGroups, ig)
+		}
+		r := required[nodeUsage.Name]
+		requiredMilliCPU := roundUp(int64(float64(r.PeakMilliCPU)/nodeUtilization), cpuStep)
+		requiredMemory := roundUp(int64(float64(r.PeakMemory)/nodeUtilization), memoryStep)
+		if requiredMilliCPU > ig.RequiredCPU.MilliValue() {
+			ig.RequiredCPU = milliCPUQuantity(requiredMilliCPU)
+		}
+		if requiredMemory > ig.RequiredMemory.Value() {
+			ig.RequiredMemory = memoryQuantity(requiredMemory)
+		}
+	}
+	sort.Slice(recommendation.InstanceGroups, func(i, j int) bool {
+		return recommendation.InstanceGroups[i].InstanceGroup < recommendation.InstanceGroups[j].InstanceGroup
+	})
+
+	for _, ig := range recommendation.InstanceGroups {
+		ig.Fits = ig.AllocatableCPU.Cmp(ig.RequiredCPU) >= 0 && ig.AllocatableMemory.Cmp(ig.RequiredMemory) >= 0
+		if ig.Fits {
+			continue
+		}
+		if len(machineTypes) != 0 {
+			ig.SuggestedMachineType = suggestMachineType(ig, usage.Nodes, machineTypes)
+			continue
+		}
+		var suggested *NodeUsage
+		for _, nodeUsage := range usage.Nodes {
+			if nodeUsage.MachineType == "" || nodeUsage.AllocatableMilliCPU < ig.RequiredCPU.MilliValue() || nodeUsage.AllocatableMemory < ig.RequiredMemory.Value() {
+				continue
+			}
+			if suggested == nil || nodeUsage.AllocatableMemory < suggested.AllocatableMemory ||
+				(nodeUsage.AllocatableMemory == suggested.AllocatableMemory && nodeUsage.AllocatableMilliCPU < suggested.AllocatableMilliCPU) {
+				suggested = nodeUsage
+			}
+		}
+		if suggested != nil {
+			ig.SuggestedMachineType = suggested.MachineType
+		}
+	}
+
+	return recommendation
+}
+
+// suggestMachineType returns the smallest machine type that fits the required resources of an instance group,
+// with the architecture of its nodes, or an empty string if none does.
+// Machine types can't allocate all their resources to pods, so the resources the nodes of the instance group
+// reserve for the system are taken off the capacity of the machine types.
+func suggestMachineType(ig *InstanceGroupRecommendation, nodes []*NodeUsage, machineTypes []MachineType) string {
+	var architecture string
+	var reservedMilliCPU, reservedMemory int64
+	for _, nodeUsage := range nodes {
+		if nodeUsage.InstanceGroup != ig.InstanceGroup {
+			continue
+		}
+		if nodeUsage.Architecture != "" {
+			architecture = nodeUsage.Architecture
+		}
+		if nodeUsage.CapacityMilliCPU != 0 {
+			reservedMilliCPU = max(reservedMilliCPU, nodeUsage.CapacityMilliCPU-nodeUsage.AllocatableMilliCPU)
+		}
+		if nodeUsage.CapacityMemory != 0 {
+			reservedMemory = max(reservedMemory, nodeUsage.CapacityMemory-nodeUsage.AllocatableMemory)
+		}
+	}
+
+	var suggested *MachineType
+	for i := range machineTypes {
+		machineType := &machineTypes[i]
+		if architecture != "" && machineType.Architecture != architecture {
+			continue
+		}
+		if machineType.MilliCPU-reservedMilliCPU < ig.RequiredCPU.MilliValue() || machineType.Memory-reservedMemory < ig.RequiredMemory.Value() {
+			continue
+		}
+		if suggested == nil || machineType.Memory < suggested.Memory ||
+			(machineType.Memory == suggested.Memory && machineType.MilliCPU < suggested.MilliCPU) ||
+			(machineType.Memory == suggested.Memory && machineType.MilliCPU == suggested.MilliCPU && machineType.Name < suggested.Name) {
+			suggested = machineType
+		}
+	}
+	if suggested == nil {
+		return ""
+	}
+	return suggested.Name
+}
+
+// Apply sets the recommended requests of the configurable components in the cluster spec.
+// Requests are only lowered if allowDecrease is true, as the usage was only sampled for a while.
+func (r *Recommendation) Apply(cluster *kops.Cluster, allowDecrease bool) {
+	for _, component := range r.Components {
+		if !component.Configurable {
+			continue
+		}
+		var cpuRequest, memoryRequest *resource.Quantity
+		if allowDecrease || !component.LowersCPURequest {
+			q := component.CPURequest.DeepCopy()
+			cpuRequest = &q
+		}
+		if allowDecrease || !component.LowersMemoryRequest {
+			q := component.MemoryRequest.DeepCopy()
+			memoryRequest = &q
+		}
+
+		if component.Component == ComponentKubeAPIServer {
+			if cpuRequest == nil && memoryRequest == nil {
+				continue
+			}
+			if cluster.Spec.KubeAPIServer == nil {
+				cluster.Spec.KubeAPIServer = &kops.KubeAPIServerConfig{}
+			}
+			c := cluster.Spec.KubeAPIServer
+			// Limits below the requests would be rejected.
+			if cpuRequest != nil {
+				c.CPURequest = cpuRequest
+				if c.CPULimit != nil && c.CPULimit.Cmp(*cpuRequest) < 0 {
+					cpuLimit := cpuRequest.DeepCopy()
+					c.CPULimit = &cpuLimit
+				}
+			}
+			if memoryRequest != nil {
+				c.MemoryRequest = memoryRequest
+				if c.MemoryLimit != nil && c.MemoryLimit.Cmp(*memoryRequest) < 0 {
+					memoryLimit := memoryRequest.DeepCopy()
+					c.MemoryLimit = &memoryLimit
+				}
+			}
+			continue
+		}
+
+		for i := range cluster.Spec.EtcdClusters {
+			etcdCluster := &cluster.Spec.EtcdClusters[i]
+			if EtcdManagerComponent(etcdCluster.Name) == component.Component {
+				if cpuRequest != nil {
+					q := cpuRequest.DeepCopy()
+					etcdCluster.CPURequest = &q
+				}
+				if memoryRequest != nil {
+					q := memoryRequest.DeepCopy()
+					etcdCluster.MemoryRequest = &q
+				}
+			}
+		}
+	}
+}
+
+// lowers returns whether a recommended request is lower than the current one,
+// which is the one set in the cluster spec or else the one the component runs with.
+func lowers(recommended resource.Quantity, current *resource.Quantity, running *resource.Quantity) bool {
+	if current == nil {
+		current = running
+	}
+	return current != nil && recommended.Cmp(*current) < 0
+}
+
+// currentRequests returns the requests of a component set in the cluster spec,
+// and whether they can be set at all.
+func currentRequests(cluster *kops.Cluster, component string) (*resource.Quantity, *resource.Quantity, bool) {
+	if component == ComponentKubeAPIServer {
+		if cluster.Spec.KubeAPIServer == nil {
+			return nil, nil, true
+		}
+		return cluster.Spec.KubeAPIServer.CPURequest, cluster.Spec.KubeAPIServer.MemoryRequest, true
+	}
+	for _, etcdCluster := range cluster.Spec.EtcdClusters {
+		if EtcdManagerComponent(etcdCluster.Name) == component {
+			return etcdCluster.CPURequest, etcdCluster.MemoryRequest, true
+		}
+	}
+	return nil, nil, false
+}
+
+func roundUp(value int64, step int64) int64 {
+	return (value + step - 1) / step * step
+}
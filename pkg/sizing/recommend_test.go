@@ -0,0 +1,169 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sizing
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/kops/pkg/apis/kops"
+)
+
+const (
+	mi = 1024 * 1024
+	gi = 1024 * mi
+)
+
+func TestRecommend(t *testing.T) {
+	memoryLimit := resource.MustParse("1Gi")
+	etcdMemoryRequest := resource.MustParse("512Mi")
+	cluster := &kops.Cluster{}
+	cluster.Spec.KubeAPIServer = &kops.KubeAPIServerConfig{MemoryLimit: &memoryLimit}
+	cluster.Spec.EtcdClusters = []kops.EtcdClusterSpec{
+		{Name: "main", MemoryRequest: &etcdMemoryRequest},
+		{Name: "events"},
+	}
+
+	usage := &Usage{
+		Containers: []*ContainerUsage{
+			{Component: ComponentKubeAPIServer, Node: "a", PeakMilliCPU: 800, PeakMemory: 3 * gi},
+			{Component: ComponentKubeAPIServer, Node: "b", PeakMilliCPU: 1000, PeakMemory: 2 * gi},
+			{Component: "etcd-manager-main", Node: "a", PeakMilliCPU: 300, PeakMemory: 200 * mi, OOMKilled: true},
+			{Component: "etcd-manager-events", Node: "a", PeakMilliCPU: 10, PeakMemory: 100 * mi, RequestMilliCPU: 200, RequestMemory: 100 * mi},
+			{Component: ComponentKubeScheduler, Node: "b", PeakMilliCPU: 20, PeakMemory: 50 * mi},
+		},
+		Nodes: []*NodeUsage{
+			{Name: "a", InstanceGroup: "control-plane-a", MachineType: "small", AllocatableMilliCPU: 2000, AllocatableMemory: 4 * gi, PeakMilliCPU: 1500, PeakMemory: 4 * gi},
+			{Name: "b", InstanceGroup: "control-plane-b", MachineType: "large", AllocatableMilliCPU: 8000, AllocatableMemory: 32 * gi, PeakMilliCPU: 1500, PeakMemory: 3 * gi},
+		},
+	}
+
+	recommendation := Recommend(cluster, usage, nil)
+
+	expectedComponents := []struct {
+		component     string
+		cpuRequest    string
+		memoryRequest string
+		configurable  bool
+		lowersCPU     bool
+	}{
+		// The CPU request is lower than the default one it runs with
+		{"etcd-manager-events", "50m", "192Mi", true, true},
+		// The OOM kill doubles the current memory request
+		{"etcd-manager-main", "400m", "1Gi", true, false},
+		{"kube-apiserver", "1250m", "4608Mi", true, false},
+		{"kube-scheduler", "50m", "128Mi", false, false},
+	}
+	if len(recommendation.Components) != len(expectedComponents) {
+		t.Fatalf("unexpected components %+v", recommendation.Components)
+	}
+	for i, expected := range expectedComponents {
+		actual := recommendation.Components[i]
+		if actual.Component != expected.component || actual.CPURequest.String() != expected.cpuRequest || actual.MemoryRequest.String() != expected.memoryRequest ||
+			actual.Configurable != expected.configurable || actual.LowersCPURequest != expected.lowersCPU || actual.LowersMemoryRequest {
+			t.Errorf("unexpected recommendation %s: cpu %s memory %s configurable %v lowers cpu %v memory %v, expected %+v",
+				actual.Component, actual.CPURequest.String(), actual.MemoryRequest.String(), actual.Configurable, actual.LowersCPURequest, actual.LowersMemoryRequest, expected)
+		}
+	}
+
+	expectedInstanceGroups := []struct {
+		instanceGroup        string
+		requiredCPU          string
+		requiredMemory       string
+		fits                 bool
+		suggestedMachineType string
+	}{
+		{"control-plane-a", "2650m", "8Gi", false, "large"},
+		{"control-plane-b", "2250m", "7Gi", true, ""},
+	}
+	if len(recommendation.InstanceGroups) != len(expectedInstanceGroups) {
+		t.Fatalf("unexpected instance groups %+v", recommendation.InstanceGroups)
+	}
+	for i, expected := range expectedInstanceGroups {
+		actual := recommendation.InstanceGroups[i]
+		if actual.InstanceGroup != expected.instanceGroup || actual.RequiredCPU.String() != expected.requiredCPU || actual.RequiredMemory.String() != expected.requiredMemory ||
+			actual.Fits != expected.fits || actual.SuggestedMachineType != expected.suggestedMachineType {
+			t.Errorf("unexpected recommendation %s: cpu %s memory %s fits %v suggested %q, expected %+v",
+				actual.InstanceGroup, actual.RequiredCPU.String(), actual.RequiredMemory.String(), actual.Fits, actual.SuggestedMachineType, expected)
+		}
+	}
+
+	// Requests are only lowered when allowed
+	lowered := cluster.DeepCopy()
+	recommendation.Apply(lowered, true)
+	if s := lowered.Spec.EtcdClusters[1].CPURequest.String(); s != "50m" {
+		t.Errorf("unexpected etcd events cpu request %s", s)
+	}
+
+	recommendation.Apply(cluster, false)
+	if s := cluster.Spec.KubeAPIServer.MemoryRequest.String(); s != "4608Mi" {
+		t.Errorf("unexpected kube-apiserver memory request %s", s)
+	}
+	if s := cluster.Spec.KubeAPIServer.MemoryLimit.String(); s != "4608Mi" {
+		t.Errorf("unexpected kube-apiserver memory limit %s", s)
+	}
+	if cluster.Spec.KubeAPIServer.CPULimit != nil {
+		t.Errorf("unexpected kube-apiserver cpu limit %s", cluster.Spec.KubeAPIServer.CPULimit.String())
+	}
+	if s := cluster.Spec.EtcdClusters[0].MemoryRequest.String(); s != "1Gi" {
+		t.Errorf("unexpected etcd main memory request %s", s)
+	}
+	if cluster.Spec.EtcdClusters[1].CPURequest != nil {
+		t.Errorf("unexpected etcd events cpu request %s", cluster.Spec.EtcdClusters[1].CPURequest.String())
+	}
+	if s := cluster.Spec.EtcdClusters[1].MemoryRequest.String(); s != "192Mi" {
+		t.Errorf("unexpected etcd events memory request %s", s)
+	}
+}
+
+func TestRecommendMachineTypes(t *testing.T) {
+	usage := &Usage{
+		Containers: []*ContainerUsage{
+			{Component: ComponentKubeAPIServer, Node: "a", PeakMilliCPU: 1600, PeakMemory: 3 * gi},
+		},
+		Nodes: []*NodeUsage{
+			{
+				Name: "a", InstanceGroup: "control-plane", MachineType: "m.small", Architecture: "arm64",
+				CapacityMilliCPU: 2000, CapacityMemory: 4 * gi, AllocatableMilliCPU: 1900, AllocatableMemory: 3 * gi,
+				PeakMilliCPU: 1600, PeakMemory: 3 * gi,
+			},
+		},
+	}
+
+	machineTypes := []MachineType{
+		{Name: "m.small", Architecture: "arm64", MilliCPU: 2000, Memory: 4 * gi},
+		// Large enough, but of another architecture
+		{Name: "x.medium", Architecture: "amd64", MilliCPU: 4000, Memory: 7 * gi},
+		// Only large enough if the nodes reserved nothing for the system
+		{Name: "m.medium", Architecture: "arm64", MilliCPU: 4000, Memory: 6 * gi},
+		{Name: "m.large", Architecture: "arm64", MilliCPU: 4000, Memory: 8 * gi},
+		{Name: "m.xlarge", Architecture: "arm64", MilliCPU: 8000, Memory: 32 * gi},
+	}
+
+	recommendation := Recommend(&kops.Cluster{}, usage, machineTypes)
+	if len(recommendation.InstanceGroups) != 1 {
+		t.Fatalf("unexpected instance groups %+v", recommendation.InstanceGroups)
+	}
+	ig := recommendation.InstanceGroups[0]
+	// The nodes reserve 100m/1Gi for the system
+	if ig.Fits || ig.RequiredCPU.String() != "2500m" || ig.RequiredMemory.String() != "5760Mi" {
+		t.Fatalf("unexpected recommendation: cpu %s memory %s fits %v", ig.RequiredCPU.String(), ig.RequiredMemory.String(), ig.Fits)
+	}
+	if ig.SuggestedMachineType != "m.large" {
+		t.Errorf("unexpected suggested machine type %q", ig.SuggestedMachineType)
+	}
+}
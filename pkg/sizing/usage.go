@@ -0,0 +1,267 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sizing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+	"k8s.io/kops/pkg/apis/kops"
+)
+
+const (
+	ComponentKubeAPIServer         = "kube-apiserver"
+	ComponentKubeControllerManager = "kube-controller-manager"
+	ComponentKubeScheduler         = "kube-scheduler"
+
+	// etcdManagerComponentPrefix prefixes the names of the etcd-manager components, like etcd-manager-main.
+	etcdManagerComponentPrefix = "etcd-manager-"
+)
+
+// EtcdManagerComponent returns the name of the component running the given etcd cluster.
+func EtcdManagerComponent(etcdCluster string) string {
+	return etcdManagerComponentPrefix + etcdCluster
+}
+
+// componentContainer returns the name of the container of the static pod of a component,
+// which is the container whose resources kOps configures.
+func componentContainer(component string) string {
+	if strings.HasPrefix(component, etcdManagerComponentPrefix) {
+		return "etcd-manager"
+	}
+	return component
+}
+
+// ContainerUsage is the usage of a control plane component on a node.
+type ContainerUsage struct {
+	Component string
+	Node      string
+
+	// PeakMilliCPU is the highest CPU usage observed, in millicores.
+	PeakMilliCPU int64
+	// PeakMemory is the highest memory working set observed, in bytes.
+	PeakMemory int64
+
+	// RequestMilliCPU and RequestMemory are the requests the container runs with, including defaults.
+	RequestMilliCPU int64
+	RequestMemory   int64
+
+	// OOMKilled is true if the container was last terminated for running out of memory.
+	OOMKilled bool
+	// Restarts is the number of times the container restarted.
+	Restarts int32
+}
+
+// NodeUsage is the usage of a control plane node.
+type NodeUsage struct {
+	Name          string
+	InstanceGroup string
+	MachineType   string
+	// Architecture is the CPU architecture of the node, like amd64 or arm64.
+	Architecture string
+
+	// CapacityMilliCPU is the CPU of the node, in millicores.
+	CapacityMilliCPU int64
+	// CapacityMemory is the memory of the node, in bytes.
+	CapacityMemory int64
+
+	// AllocatableMilliCPU is the CPU the node can allocate to pods, in millicores.
+	AllocatableMilliCPU int64
+	// AllocatableMemory is the memory the node can allocate to pods, in bytes.
+	AllocatableMemory int64
+
+	// PeakMilliCPU is the highest CPU usage of the node observed, in millicores.
+	PeakMilliCPU int64
+	// PeakMemory is the highest memory working set of the node observed, in bytes.
+	PeakMemory int64
+}
+
+// Usage is the usage of the control plane of a cluster.
+type Usage struct {
+	Containers []*ContainerUsage
+	Nodes      []*NodeUsage
+}
+
+// Collector samples the usage of the control plane reported by metrics-server,
+// itself reading it from the cAdvisor of the kubelets.
+type Collector struct {
+	Client kubernetes.Interface
+
+	// Samples is the number of times usage is sampled.
+	Samples int
+	// Interval is the time between samples.
+	Interval time.Duration
+}
+
+// metricsList is the subset of the PodMetricsList and NodeMetricsList of metrics.k8s.io that we read.
+type metricsList struct {
+	Items []struct {
+		Metadata struct {
+			Name string `json:"name"`
+		} `json:"metadata"`
+		Usage      corev1.ResourceList `json:"usage"`
+		Containers []struct {
+			Name  string              `json:"name"`
+			Usage corev1.ResourceList `json:"usage"`
+		} `json:"containers"`
+	} `json:"items"`
+}
+
+// Collect samples the usage of the control plane components and nodes, keeping the peaks.
+// The peaks are the highest of the point-in-time samples: metrics-server reports the CPU usage
+// averaged over its resolution and the current memory working set, so shorter spikes are missed.
+func (c *Collector) Collect(ctx context.Context) (*Usage, error) {
+	nodes, err := c.Client.CoreV1().Nodes().List(ctx, metav1.ListOptions{LabelSelector: "node-role.kubernetes.io/control-plane"})
+	if err != nil {
+		return nil, fmt.Errorf("error listing control plane nodes: %w", err)
+	}
+	if len(nodes.Items) == 0 {
+		return nil, fmt.Errorf("no control plane nodes found")
+	}
+
+	usage := &Usage{}
+	nodeUsages := make(map[string]*NodeUsage)
+	for i := range nodes.Items {
+		node := &nodes.Items[i]
+		nodeUsage := &NodeUsage{
+			Name:                node.Name,
+			InstanceGroup:       node.Labels[kops.NodeLabelInstanceGroup],
+			MachineType:         node.Labels[corev1.LabelInstanceTypeStable],
+			Architecture:        node.Labels[corev1.LabelArchStable],
+			CapacityMilliCPU:    node.Status.Capacity.Cpu().MilliValue(),
+			CapacityMemory:      node.Status.Capacity.Memory().Value(),
+			AllocatableMilliCPU: node.Status.Allocatable.Cpu().MilliValue(),
+			AllocatableMemory:   node.Status.Allocatable.Memory().Value(),
+		}
+		usage.Nodes = append(usage.Nodes, nodeUsage)
+		nodeUsages[node.Name] = nodeUsage
+	}
+
+	pods, err := c.Client.CoreV1().Pods("kube-system").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error listing kube-system pods: %w", err)
+	}
+	containerUsages := make(map[string]*ContainerUsage)
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		component := pod.Labels["k8s-app"]
+		switch {
+		case component == ComponentKubeAPIServer, component == ComponentKubeControllerManager, component == ComponentKubeScheduler:
+		case strings.HasPrefix(component, etcdManagerComponentPrefix):
+		default:
+			continue
+		}
+		if nodeUsages[pod.Spec.NodeName] == nil {
+			continue
+		}
+
+		containerUsage := &ContainerUsage{
+			Component: component,
+			Node:      pod.Spec.NodeName,
+		}
+		for _, container := range pod.Spec.Containers {
+			if container.Name != componentContainer(component) {
+				continue
+			}
+			containerUsage.RequestMilliCPU = container.Resources.Requests.Cpu().MilliValue()
+			containerUsage.RequestMemory = container.Resources.Requests.Memory().Value()
+		}
+		for _, status := range pod.Status.ContainerStatuses {
+			if status.Name != componentContainer(component) {
+				continue
+			}
+			containerUsage.Restarts = status.RestartCount
+			if terminated := status.LastTerminationState.Terminated; terminated != nil && terminated.Reason == "OOMKilled" {
+				containerUsage.OOMKilled = true
+			}
+		}
+		usage.Containers = append(usage.Containers, containerUsage)
+		containerUsages[pod.Name] = containerUsage
+	}
+
+	for sample := 0; sample < c.Samples; sample++ {
+		if sample != 0 {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(c.Interval):
+			}
+		}
+		klog.V(2).Infof("sampling control plane usage (%d/%d)", sample+1, c.Samples)
+
+		podMetrics, err := c.readMetrics(ctx, "/apis/metrics.k8s.io/v1beta1/namespaces/kube-system/pods")
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range podMetrics.Items {
+			containerUsage := containerUsages[item.Metadata.Name]
+			if containerUsage == nil {
+				continue
+			}
+			for _, container := range item.Containers {
+				if container.Name != componentContainer(containerUsage.Component) {
+					continue
+				}
+				containerUsage.PeakMilliCPU = max(containerUsage.PeakMilliCPU, container.Usage.Cpu().MilliValue())
+				containerUsage.PeakMemory = max(containerUsage.PeakMemory, container.Usage.Memory().Value())
+			}
+		}
+
+		nodeMetrics, err := c.readMetrics(ctx, "/apis/metrics.k8s.io/v1beta1/nodes")
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range nodeMetrics.Items {
+			nodeUsage := nodeUsages[item.Metadata.Name]
+			if nodeUsage == nil {
+				continue
+			}
+			nodeUsage.PeakMilliCPU = max(nodeUsage.PeakMilliCPU, item.Usage.Cpu().MilliValue())
+			nodeUsage.PeakMemory = max(nodeUsage.PeakMemory, item.Usage.Memory().Value())
+		}
+	}
+
+	return usage, nil
+}
+
+func (c *Collector) readMetrics(ctx context.Context, path string) (*metricsList, error) {
+	data, err := c.Client.CoreV1().RESTClient().Get().AbsPath(path).DoRaw(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error reading metrics from %s, is metrics-server running?: %w", path, err)
+	}
+	metrics := &metricsList{}
+	if err := json.Unmarshal(data, metrics); err != nil {
+		return nil, fmt.Errorf("error parsing metrics from %s: %w", path, err)
+	}
+	return metrics, nil
+}
+
+func milliCPUQuantity(milliCPU int64) resource.Quantity {
+	return *resource.NewMilliQuantity(milliCPU, resource.DecimalSI)
+}
+
+func memoryQuantity(bytes int64) resource.Quantity {
+	return *resource.NewQuantity(bytes, resource.BinarySI)
+}
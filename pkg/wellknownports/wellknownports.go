@@ -20,6 +20,9 @@ const (
 	// KubeAPIServer is the port where kube-apiserver listens.
 	KubeAPIServer = 443
 
+	// KopsControllerMetrics is the port where kops-controller serves Prometheus metrics, when enabled.
+	KopsControllerMetrics = 3986
+
 	// NodeupChallenge is the port where nodeup listens for challenges.
 	NodeupChallenge = 3987
 
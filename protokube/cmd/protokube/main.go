@@ -53,8 +53,8 @@ func main() {
 
 // run is responsible for running the protokube service controller
 func run() error {
-	var zones []string
-	var containerized, master, gossip bool
+	var zones, gossipSecretsAdditional []string
+	var containerized, master, gossip, gossipKeyset bool
 	var cloud, clusterID, dnsInternalSuffix, gossipSecret, gossipListen, gossipProtocol, gossipSecretSecondary, gossipListenSecondary, gossipProtocolSecondary string
 	var flagChannels string
 	var dnsUpdateInterval int
@@ -73,6 +73,8 @@ func run() error {
 	flag.StringVar(&gossipProtocolSecondary, "gossip-protocol-secondary", "memberlist", "mesh/memberlist")
 	flag.StringVar(&gossipListenSecondary, "gossip-listen-secondary", fmt.Sprintf("0.0.0.0:%d", wellknownports.ProtokubeGossipMemberlist), "address:port on which to bind for gossip")
 	flags.StringVar(&gossipSecretSecondary, "gossip-secret-secondary", gossipSecret, "Secret to use to secure gossip")
+	flags.StringSliceVar(&gossipSecretsAdditional, "gossip-secret-additional", gossipSecretsAdditional, "Additional secrets accepted on incoming gossip, while rotating the gossip secret")
+	flags.BoolVar(&gossipKeyset, "gossip-keyset", gossipKeyset, "Set if the gossip secrets are managed as a gossip keyset, which seals memberlist gossip")
 	flags.StringSliceVarP(&zones, "zone", "z", []string{}, "Configure permitted zones and their mappings")
 
 	bootstrapMasterNodeLabels := false
@@ -193,14 +195,14 @@ func run() error {
 		}
 
 		channelName := "dns"
-		gossipState, err := gossiputils.GetGossipState(gossipProtocol, gossipListen, channelName, gossipName, []byte(gossipSecret), gossipSeeds)
+		gossipState, err := gossiputils.GetGossipState(gossipProtocol, gossipListen, channelName, gossipName, gossiputils.Secrets(gossipProtocol, gossipKeyset, gossipSecret, gossipSecretsAdditional), gossipSeeds)
 		if err != nil {
 			klog.Errorf("error initializing gossip: %v", err)
 			os.Exit(1)
 		}
 
 		if gossipProtocolSecondary != "" {
-			secondaryGossipState, err := gossiputils.GetGossipState(gossipProtocolSecondary, gossipListenSecondary, channelName, gossipName, []byte(gossipSecretSecondary), gossipSeeds)
+			secondaryGossipState, err := gossiputils.GetGossipState(gossipProtocolSecondary, gossipListenSecondary, channelName, gossipName, gossiputils.Secrets(gossipProtocolSecondary, gossipKeyset, gossipSecretSecondary, gossipSecretsAdditional), gossipSeeds)
 			if err != nil {
 				klog.Errorf("error initializing secondary gossip: %v", err)
 				os.Exit(1)
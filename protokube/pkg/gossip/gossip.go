@@ -64,7 +64,9 @@ func (m *MultiGossipState) Start() error {
 	return <-errCh
 }
 
-type newGossipFunc func(listen, channelName, gossipName string, gossipSecret []byte, gossipSeeds SeedProvider) (GossipState, error)
+// newGossipFunc creates a GossipState.
+// The first of the gossipSecrets secures outgoing gossip, while any of them is accepted on incoming gossip.
+type newGossipFunc func(listen, channelName, gossipName string, gossipSecrets [][]byte, gossipSeeds SeedProvider) (GossipState, error)
 
 var (
 	gossipMap      = make(map[string]newGossipFunc)
@@ -81,7 +83,7 @@ func Register(name string, f newGossipFunc) {
 	gossipMap[name] = f
 }
 
-func GetGossipState(protocol, listen, channelName, gossipName string, gossipSecret []byte, gossipSeeds SeedProvider) (GossipState, error) {
+func GetGossipState(protocol, listen, channelName, gossipName string, gossipSecrets [][]byte, gossipSeeds SeedProvider) (GossipState, error) {
 	gossipMapMutex.Lock()
 	f, ok := gossipMap[protocol]
 	gossipMapMutex.Unlock()
@@ -89,5 +91,19 @@ func GetGossipState(protocol, listen, channelName, gossipName string, gossipSecr
 		return nil, fmt.Errorf("Unknown gossip protocol: %s", protocol)
 	}
 
-	return f(listen, channelName, gossipName, gossipSecret, gossipSeeds)
+	return f(listen, channelName, gossipName, gossipSecrets, gossipSeeds)
+}
+
+// Secrets returns the gossip secrets of a protocol, for a primary secret and the additional secrets accepted during a rotation.
+// memberlist gossip is only sealed once the secrets are managed as a gossip keyset: before that, instances gossip
+// plaintext state over memberlist even if a gossip secret is configured, and must keep doing so to stay compatible.
+func Secrets(protocol string, keyset bool, primary string, additional []string) [][]byte {
+	if protocol == "memberlist" && !keyset {
+		return nil
+	}
+	secrets := [][]byte{[]byte(primary)}
+	for _, secret := range additional {
+		secrets = append(secrets, []byte(secret))
+	}
+	return secrets
 }
@@ -0,0 +1,183 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package memberlist
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/hashicorp/memberlist"
+	"github.com/jacksontj/memberlistmesh/clusterpb"
+	"k8s.io/klog/v2"
+)
+
+// delegate gossips the state over memberlist.
+// Messages are framed as the parts of memberlistmesh, which earlier versions of kOps used,
+// so that instances that haven't been updated yet keep gossiping with us.
+type delegate struct {
+	channelName string
+	state       *state
+	broadcasts  *memberlist.TransmitLimitedQueue
+	metrics     *metrics
+
+	mutex sync.Mutex
+	// failedPeers holds the time that peers left or failed, by address, so that we try to reconnect to them
+	failedPeers map[string]time.Time
+}
+
+var _ memberlist.Delegate = &delegate{}
+var _ memberlist.EventDelegate = &delegate{}
+
+// NodeMeta is part of the memberlist.Delegate interface
+func (d *delegate) NodeMeta(limit int) []byte {
+	return []byte{}
+}
+
+// NotifyMsg is part of the memberlist.Delegate interface
+func (d *delegate) NotifyMsg(b []byte) {
+	d.metrics.messagesReceived.WithLabelValues(messageTypeUpdate).Inc()
+	d.metrics.messagesReceivedSize.WithLabelValues(messageTypeUpdate).Add(float64(len(b)))
+
+	var part clusterpb.Part
+	if err := proto.Unmarshal(b, &part); err != nil {
+		klog.Warningf("error decoding gossip message: %v", err)
+		return
+	}
+	if part.Key != d.channelName {
+		return
+	}
+	if err := d.state.Merge(part.Data); err != nil {
+		klog.Warningf("error merging gossip message: %v", err)
+	}
+}
+
+// GetBroadcasts is part of the memberlist.Delegate interface
+func (d *delegate) GetBroadcasts(overhead, limit int) [][]byte {
+	messages := d.broadcasts.GetBroadcasts(overhead, limit)
+	d.metrics.messagesSent.WithLabelValues(messageTypeUpdate).Add(float64(len(messages)))
+	for _, m := range messages {
+		d.metrics.messagesSentSize.WithLabelValues(messageTypeUpdate).Add(float64(len(m)))
+	}
+	return messages
+}
+
+// LocalState is part of the memberlist.Delegate interface
+func (d *delegate) LocalState(join bool) []byte {
+	data, err := d.state.MarshalBinary()
+	if err != nil {
+		klog.Warningf("error encoding gossip state: %v", err)
+		return nil
+	}
+	b, err := proto.Marshal(&clusterpb.FullState{
+		Parts: []clusterpb.Part{{Key: d.channelName, Data: data}},
+	})
+	if err != nil {
+		klog.Warningf("error encoding gossip state: %v", err)
+		return nil
+	}
+	d.metrics.messagesSent.WithLabelValues(messageTypeFullState).Inc()
+	d.metrics.messagesSentSize.WithLabelValues(messageTypeFullState).Add(float64(len(b)))
+	return b
+}
+
+// MergeRemoteState is part of the memberlist.Delegate interface
+func (d *delegate) MergeRemoteState(buf []byte, join bool) {
+	d.metrics.messagesReceived.WithLabelValues(messageTypeFullState).Inc()
+	d.metrics.messagesReceivedSize.WithLabelValues(messageTypeFullState).Add(float64(len(buf)))
+
+	var fullState clusterpb.FullState
+	if err := proto.Unmarshal(buf, &fullState); err != nil {
+		klog.Warningf("error decoding gossip state: %v", err)
+		return
+	}
+	for _, part := range fullState.Parts {
+		if part.Key != d.channelName {
+			continue
+		}
+		if err := d.state.Merge(part.Data); err != nil {
+			klog.Warningf("error merging gossip state: %v", err)
+		}
+	}
+}
+
+// NotifyJoin is part of the memberlist.EventDelegate interface
+func (d *delegate) NotifyJoin(node *memberlist.Node) {
+	klog.V(2).Infof("gossip peer joined: %s", node.Address())
+	d.metrics.peersJoined.Inc()
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	delete(d.failedPeers, node.Address())
+}
+
+// NotifyLeave is part of the memberlist.EventDelegate interface
+func (d *delegate) NotifyLeave(node *memberlist.Node) {
+	klog.V(2).Infof("gossip peer left: %s", node.Address())
+	d.metrics.peersLeft.Inc()
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.failedPeers[node.Address()] = time.Now()
+}
+
+// NotifyUpdate is part of the memberlist.EventDelegate interface
+func (d *delegate) NotifyUpdate(node *memberlist.Node) {
+	d.metrics.peersUpdated.Inc()
+}
+
+// numFailedPeers returns the number of peers that left or failed, and that we try to reconnect to.
+func (d *delegate) numFailedPeers() int {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return len(d.failedPeers)
+}
+
+// reconnectPeers returns the addresses of the peers that left or failed, forgetting those that left before the timeout.
+func (d *delegate) reconnectPeers(timeout time.Duration) []string {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	var addresses []string
+	for address, leaveTime := range d.failedPeers {
+		if time.Since(leaveTime) > timeout {
+			delete(d.failedPeers, address)
+			continue
+		}
+		addresses = append(addresses, address)
+	}
+	return addresses
+}
+
+// broadcast is a gossip message queued for broadcast
+type broadcast []byte
+
+var _ memberlist.Broadcast = broadcast(nil)
+
+// Invalidates is part of the memberlist.Broadcast interface
+func (b broadcast) Invalidates(other memberlist.Broadcast) bool {
+	return false
+}
+
+// Message is part of the memberlist.Broadcast interface
+func (b broadcast) Message() []byte {
+	return b
+}
+
+// Finished is part of the memberlist.Broadcast interface
+func (b broadcast) Finished() {
+}
@@ -17,36 +17,60 @@ limitations under the License.
 package memberlist
 
 import (
-	"context"
+	"crypto/rand"
 	"fmt"
 	"net"
 	"strconv"
 	"strings"
 	"time"
 
-	cluster "github.com/jacksontj/memberlistmesh"
+	"github.com/gogo/protobuf/proto"
+	"github.com/hashicorp/memberlist"
+	"github.com/jacksontj/memberlistmesh/clusterpb"
+	"github.com/oklog/ulid"
 	"github.com/prometheus/client_golang/prometheus"
 	"k8s.io/klog/v2"
 	"k8s.io/kops/protokube/pkg/gossip"
 )
 
 func init() {
-	gossip.Register("memberlist", func(listen, channelName, gossipName string, gossipSecret []byte, gossipSeeds gossip.SeedProvider) (gossip.GossipState, error) {
-		return NewMemberlistGossiper(listen, channelName, gossipName, gossipSecret, gossipSeeds)
+	gossip.Register("memberlist", func(listen, channelName, gossipName string, gossipSecrets [][]byte, gossipSeeds gossip.SeedProvider) (gossip.GossipState, error) {
+		return NewMemberlistGossiper(listen, channelName, gossipName, gossipSecrets, gossipSeeds)
 	})
 }
 
+// These are the settings that memberlistmesh used, which earlier versions of kOps gossiped with.
+const (
+	pushPullInterval  = 60 * time.Second
+	gossipInterval    = 200 * time.Millisecond
+	tcpTimeout        = 10 * time.Second
+	probeTimeout      = 500 * time.Millisecond
+	probeInterval     = 1 * time.Second
+	reconnectInterval = 10 * time.Second
+	reconnectTimeout  = 6 * time.Hour
+
+	maxGossipPacketSize = 1400
+)
+
 type MemberlistGossiper struct {
-	peer       *cluster.Peer
+	list       *memberlist.Memberlist
+	delegate   *delegate
 	seeds      gossip.SeedProvider
 	listenPort int
 
-	state *state
-	bcast func([]byte)
+	initialPeers []string
+	state        *state
+	metrics      *metrics
+}
+
+// NewMemberlistGossiper creates a MemberlistGossiper, registering its metrics with the default Prometheus registry.
+// Gossip is encrypted with the first of the secrets, and can be decrypted with any of them.
+func NewMemberlistGossiper(listen string, channelName string, nodeName string, secrets [][]byte, seeds gossip.SeedProvider) (*MemberlistGossiper, error) {
+	return newMemberlistGossiper(prometheus.DefaultRegisterer, listen, channelName, nodeName, secrets, seeds)
 }
 
-func NewMemberlistGossiper(listen string, channelName string, nodeName string, password []byte, seeds gossip.SeedProvider) (*MemberlistGossiper, error) {
-	_, portString, err := net.SplitHostPort(listen)
+func newMemberlistGossiper(reg prometheus.Registerer, listen string, channelName string, nodeName string, secrets [][]byte, seeds gossip.SeedProvider) (*MemberlistGossiper, error) {
+	host, portString, err := net.SplitHostPort(listen)
 	if err != nil {
 		return nil, fmt.Errorf("cannot parse -listen flag: %v", listen)
 	}
@@ -54,6 +78,9 @@ func NewMemberlistGossiper(listen string, channelName string, nodeName string, p
 	if err != nil {
 		return nil, fmt.Errorf("cannot parse -listen flag: %v", listen)
 	}
+	if host == "" {
+		host = "0.0.0.0"
+	}
 
 	initialPeers, err := seeds.GetSeeds()
 	if err != nil {
@@ -66,51 +93,101 @@ func NewMemberlistGossiper(listen string, channelName string, nodeName string, p
 		}
 	}
 
-	peer, err := cluster.Create(
-		prometheus.DefaultRegisterer,
-		listen,
-		"", //*clusterAdvertiseAddr,
-		initialPeers,
-		true,
-		cluster.DefaultPushPullInterval,
-		cluster.DefaultGossipInterval,
-		cluster.DefaultTcpTimeout,
-		cluster.DefaultProbeTimeout,
-		cluster.DefaultProbeInterval,
-	)
+	retransmit := len(initialPeers) / 2
+	if retransmit < 3 {
+		retransmit = 3
+	}
+
+	// Like memberlistmesh, we use random names, as several instances can gossip from the same host
+	name, err := ulid.New(ulid.Now(), rand.Reader)
 	if err != nil {
 		return nil, err
 	}
 
-	s := &state{}
+	g := &MemberlistGossiper{
+		seeds:        seeds,
+		listenPort:   port,
+		initialPeers: initialPeers,
+		state:        &state{},
+		metrics:      newMetrics(),
+	}
+	g.delegate = &delegate{
+		channelName: channelName,
+		state:       g.state,
+		metrics:     g.metrics,
+		broadcasts: &memberlist.TransmitLimitedQueue{
+			NumNodes:       func() int { return g.list.NumMembers() },
+			RetransmitMult: retransmit,
+		},
+		failedPeers: make(map[string]time.Time),
+	}
+
+	cfg := memberlist.DefaultLANConfig()
+	cfg.Name = name.String()
+	cfg.BindAddr = host
+	cfg.BindPort = port
+	cfg.Delegate = g.delegate
+	cfg.Events = g.delegate
+	cfg.GossipInterval = gossipInterval
+	cfg.PushPullInterval = pushPullInterval
+	cfg.TCPTimeout = tcpTimeout
+	cfg.ProbeTimeout = probeTimeout
+	cfg.ProbeInterval = probeInterval
+	cfg.LogOutput = &logWriter{}
+	cfg.GossipNodes = retransmit
+	cfg.UDPBufferSize = maxGossipPacketSize
+	if err := configureEncryption(cfg, secrets); err != nil {
+		return nil, err
+	}
+
+	list, err := memberlist.Create(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("creating memberlist: %w", err)
+	}
+	g.list = list
+
+	if err := g.metrics.register(reg, g); err != nil {
+		list.Shutdown()
+		return nil, fmt.Errorf("registering gossip metrics: %w", err)
+	}
 
-	return &MemberlistGossiper{
-		peer:       peer,
-		seeds:      seeds,
-		listenPort: port,
-		state:      s,
-		bcast:      peer.AddState(channelName, s, prometheus.DefaultRegisterer).Broadcast,
-	}, nil
+	return g, nil
 }
 
 func (g *MemberlistGossiper) Start() error {
-	if err := g.peer.Join(cluster.DefaultReconnectInterval, cluster.DefaultReconnectTimeout); err != nil {
-		return err
+	if n, err := g.list.Join(g.initialPeers); err != nil {
+		klog.Warningf("failed to join gossip mesh: %v", err)
+	} else {
+		klog.V(2).Infof("joined gossip mesh with %d peers", n)
 	}
-	ctx, cancel := context.WithTimeout(context.Background(), cluster.DefaultPushPullInterval)
 	defer func() {
-		cancel()
-		if err := g.peer.Leave(10 * time.Second); err != nil {
+		if err := g.list.Leave(10 * time.Second); err != nil {
 			klog.V(2).Infof("unable to leave gossip mesh: %v", err)
 		}
 	}()
 
-	g.peer.Settle(ctx, cluster.DefaultGossipInterval*10)
+	go g.runReconnect()
 	g.runSeeding()
 
 	return nil
 }
 
+// runReconnect tries to reconnect to the peers that left or failed, until they time out.
+func (g *MemberlistGossiper) runReconnect() {
+	for {
+		time.Sleep(reconnectInterval)
+
+		for _, address := range g.delegate.reconnectPeers(reconnectTimeout) {
+			if _, err := g.list.Join([]string{address}); err != nil {
+				g.metrics.failedReconnections.Inc()
+				klog.V(2).Infof("failed to reconnect to gossip peer %s: %v", address, err)
+				continue
+			}
+			g.metrics.reconnections.Inc()
+		}
+	}
+}
+
 func (g *MemberlistGossiper) runSeeding() {
 SEED_LOOP:
 	for {
@@ -128,13 +205,15 @@ SEED_LOOP:
 			if !strings.Contains(seed, ":") {
 				seed = seed + ":" + strconv.Itoa(g.listenPort)
 			}
-			if err := g.peer.AddPeer(seed); err != nil {
+			if _, err := g.list.Join([]string{seed}); err != nil {
+				g.metrics.failedRefreshJoins.Inc()
 				klog.Infof("error connecting to seeds: %v", err)
 				time.Sleep(1 * time.Minute)
 				continue SEED_LOOP
 			}
 		}
 
+		g.metrics.refreshJoins.Inc()
 		klog.V(2).Infof("Seeding successful")
 
 		// Reseed periodically, just in case of partitions
@@ -150,10 +229,39 @@ func (g *MemberlistGossiper) Snapshot() *gossip.GossipStateSnapshot {
 func (g *MemberlistGossiper) UpdateValues(removeKeys []string, putKeys map[string]string) error {
 	klog.V(2).Infof("UpdateValues: remove=%s, put=%s", removeKeys, putKeys)
 	g.state.updateValues(removeKeys, putKeys)
-	b, err := g.state.MarshalBinary()
+	data, err := g.state.MarshalBinary()
 	if err != nil {
 		return err
 	}
-	g.bcast(b)
+	b, err := proto.Marshal(&clusterpb.Part{Key: g.delegate.channelName, Data: data})
+	if err != nil {
+		return err
+	}
+
+	// Messages that don't fit in a gossip packet are sent to every peer over TCP
+	if len(b) > maxGossipPacketSize/2 {
+		go func() {
+			for _, node := range g.list.Members() {
+				if node.Name == g.list.LocalNode().Name {
+					continue
+				}
+				if err := g.list.SendReliable(node, b); err != nil {
+					g.metrics.oversizedMessagesFailed.Inc()
+					klog.V(2).Infof("failed to send gossip to %s: %v", node.Address(), err)
+					continue
+				}
+				g.metrics.oversizedMessagesSent.Inc()
+			}
+		}()
+		return nil
+	}
+	g.delegate.broadcasts.QueueBroadcast(broadcast(b))
 	return nil
 }
+
+type logWriter struct{}
+
+func (l *logWriter) Write(b []byte) (int, error) {
+	klog.V(2).Infof("memberlist %s", strings.TrimSpace(string(b)))
+	return len(b), nil
+}
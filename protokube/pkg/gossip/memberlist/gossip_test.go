@@ -0,0 +1,107 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package memberlist
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	cluster "github.com/jacksontj/memberlistmesh"
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/kops/protokube/pkg/gossip"
+)
+
+// TestGossipWithMemberlistmesh checks that we gossip with instances of earlier versions of kOps, which used memberlistmesh.
+func TestGossipWithMemberlistmesh(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	g, err := newMemberlistGossiper(registry, "127.0.0.1:0", "test", "new", nil, gossip.NewStaticSeedProvider(nil))
+	if err != nil {
+		t.Fatalf("unexpected error creating gossiper: %v", err)
+	}
+	defer g.list.Shutdown()
+
+	peer, err := cluster.Create(
+		prometheus.NewRegistry(),
+		"127.0.0.1:0",
+		"",
+		[]string{fmt.Sprintf("127.0.0.1:%d", g.list.LocalNode().Port)},
+		false,
+		cluster.DefaultPushPullInterval,
+		cluster.DefaultGossipInterval,
+		cluster.DefaultTcpTimeout,
+		cluster.DefaultProbeTimeout,
+		cluster.DefaultProbeInterval,
+	)
+	if err != nil {
+		t.Fatalf("unexpected error creating memberlistmesh peer: %v", err)
+	}
+	defer peer.Leave(time.Second)
+
+	old := &state{}
+	channel := peer.AddState("test", old, prometheus.NewRegistry())
+	if err := peer.Join(0, 0); err != nil {
+		t.Fatalf("unexpected error joining: %v", err)
+	}
+
+	old.updateValues(nil, map[string]string{"old": "1"})
+	b, err := old.MarshalBinary()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	channel.Broadcast(b)
+
+	if err := g.UpdateValues(nil, map[string]string{"new": "2"}); err != nil {
+		t.Fatalf("unexpected error updating values: %v", err)
+	}
+
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		if g.Snapshot().Values["old"] == "1" && old.snapshot().Values["new"] == "2" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("values were not gossiped: got %v and %v", g.Snapshot().Values, old.snapshot().Values)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	// The metrics keep the names that memberlistmesh registered
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("unexpected error gathering metrics: %v", err)
+	}
+	values := make(map[string]float64)
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			switch {
+			case metric.GetGauge() != nil:
+				values[family.GetName()] += metric.GetGauge().GetValue()
+			case metric.GetCounter() != nil:
+				values[family.GetName()] += metric.GetCounter().GetValue()
+			}
+		}
+	}
+	if values["memberlistmesh_cluster_members"] != 2 {
+		t.Errorf("expected 2 members, got %v", values["memberlistmesh_cluster_members"])
+	}
+	if values["memberlistmesh_cluster_messages_received_total"] == 0 {
+		t.Errorf("expected received messages to be counted")
+	}
+	if values["alertmanager_cluster_peers_joined_total"] == 0 {
+		t.Errorf("expected joined peers to be counted")
+	}
+}
@@ -0,0 +1,59 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package memberlist
+
+import (
+	"crypto/sha256"
+	"fmt"
+
+	"github.com/hashicorp/memberlist"
+)
+
+// configureEncryption sets up the memberlist keyring, so that gossip is encrypted with the primary secret and
+// can be decrypted with any of the secrets.
+// An empty secret stands for plaintext gossip, so that a secret can be introduced on a mesh that had none:
+// an empty primary secret keeps outgoing gossip plaintext, and an empty secret anywhere accepts plaintext gossip.
+func configureEncryption(cfg *memberlist.Config, secrets [][]byte) error {
+	var keys [][]byte
+	for _, secret := range secrets {
+		if len(secret) == 0 {
+			continue
+		}
+		// memberlist takes AES keys, so we derive a 32 byte key from the secret
+		key := sha256.Sum256(secret)
+		keys = append(keys, key[:])
+	}
+	if len(keys) == 0 {
+		cfg.Keyring = nil
+		return nil
+	}
+
+	keyring, err := memberlist.NewKeyring(keys, keys[0])
+	if err != nil {
+		return fmt.Errorf("creating gossip keyring: %w", err)
+	}
+	cfg.Keyring = keyring
+
+	cfg.GossipVerifyOutgoing = len(secrets[0]) != 0
+	cfg.GossipVerifyIncoming = true
+	for _, secret := range secrets {
+		if len(secret) == 0 {
+			cfg.GossipVerifyIncoming = false
+		}
+	}
+	return nil
+}
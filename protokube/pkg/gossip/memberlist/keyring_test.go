@@ -0,0 +1,105 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package memberlist
+
+import (
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/hashicorp/memberlist"
+	"k8s.io/kops/protokube/pkg/gossip"
+)
+
+// newTestMemberlist creates a memberlist on a local port, encrypted with the secrets.
+func newTestMemberlist(t *testing.T, name string, secrets [][]byte) *memberlist.Memberlist {
+	cfg := memberlist.DefaultLocalConfig()
+	cfg.Name = name
+	cfg.BindAddr = "127.0.0.1"
+	cfg.BindPort = 0
+	cfg.LogOutput = io.Discard
+	if err := configureEncryption(cfg, secrets); err != nil {
+		t.Fatalf("unexpected error configuring encryption: %v", err)
+	}
+	list, err := memberlist.Create(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error creating memberlist: %v", err)
+	}
+	t.Cleanup(func() {
+		list.Shutdown()
+	})
+	return list
+}
+
+// canGossip checks whether memberlists with the secrets can exchange their state with each other.
+func canGossip(t *testing.T, a, b [][]byte) bool {
+	listA := newTestMemberlist(t, "a", a)
+	listB := newTestMemberlist(t, "b", b)
+	address := fmt.Sprintf("127.0.0.1:%d", listA.LocalNode().Port)
+	_, err := listB.Join([]string{address})
+	return err == nil
+}
+
+func TestKeyring(t *testing.T) {
+	grid := []struct {
+		name string
+		a    [][]byte
+		b    [][]byte
+		ok   bool
+	}{
+		{name: "no secrets", a: nil, b: nil, ok: true},
+		{name: "same secret", a: [][]byte{[]byte("a")}, b: [][]byte{[]byte("a")}, ok: true},
+		{name: "different secret", a: [][]byte{[]byte("a")}, b: [][]byte{[]byte("b")}, ok: false},
+		{name: "secret added", a: [][]byte{[]byte("a")}, b: [][]byte{[]byte("a"), []byte("b")}, ok: true},
+		{name: "secret promoted", a: [][]byte{[]byte("b"), []byte("a")}, b: [][]byte{[]byte("a"), []byte("b")}, ok: true},
+		{name: "secret removed", a: [][]byte{[]byte("a"), []byte("b")}, b: [][]byte{[]byte("b")}, ok: false},
+		{name: "plaintext rejected", a: nil, b: [][]byte{[]byte("a")}, ok: false},
+		{name: "secret introduced", a: nil, b: [][]byte{nil, []byte("a")}, ok: true},
+		{name: "introduced secret promoted", a: [][]byte{nil, []byte("a")}, b: [][]byte{[]byte("a"), nil}, ok: true},
+		{name: "plaintext rejected once promoted", a: nil, b: [][]byte{[]byte("a"), nil}, ok: false},
+	}
+	for _, g := range grid {
+		t.Run(g.name, func(t *testing.T) {
+			if got := canGossip(t, g.a, g.b); got != g.ok {
+				t.Errorf("unexpected result gossiping: got %v, expected %v", got, g.ok)
+			}
+		})
+	}
+}
+
+// TestKeyringRotation checks that instances at adjacent steps of a gossip secret rotation, starting from
+// instances without a gossip keyset, can gossip with each other.
+func TestKeyringRotation(t *testing.T) {
+	steps := []struct {
+		name    string
+		secrets [][]byte
+	}{
+		{name: "no keyset", secrets: gossip.Secrets("memberlist", false, "spec", nil)},
+		{name: "created", secrets: gossip.Secrets("memberlist", true, "", []string{"new"})},
+		{name: "promoted", secrets: gossip.Secrets("memberlist", true, "new", []string{""})},
+		{name: "distrusted", secrets: gossip.Secrets("memberlist", true, "new", nil)},
+	}
+
+	for i := 0; i+1 < len(steps); i++ {
+		if !canGossip(t, steps[i].secrets, steps[i+1].secrets) {
+			t.Errorf("instances at steps %q and %q can't gossip with each other", steps[i].name, steps[i+1].name)
+		}
+	}
+	if canGossip(t, steps[0].secrets, steps[len(steps)-1].secrets) {
+		t.Errorf("unencrypted gossip accepted once the rotation is complete")
+	}
+}
@@ -0,0 +1,154 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package memberlist
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// The message types of the message metrics, as labeled by memberlistmesh.
+const (
+	messageTypeFullState = "full_state"
+	messageTypeUpdate    = "update"
+)
+
+// metrics are the Prometheus metrics of the gossiper.
+// They keep the names and labels that memberlistmesh registered, so that existing dashboards and alerts keep working.
+type metrics struct {
+	messagesReceived     *prometheus.CounterVec
+	messagesReceivedSize *prometheus.CounterVec
+	messagesSent         *prometheus.CounterVec
+	messagesSentSize     *prometheus.CounterVec
+
+	oversizedMessagesSent   prometheus.Counter
+	oversizedMessagesFailed prometheus.Counter
+
+	peersJoined         prometheus.Counter
+	peersLeft           prometheus.Counter
+	peersUpdated        prometheus.Counter
+	reconnections       prometheus.Counter
+	failedReconnections prometheus.Counter
+	refreshJoins        prometheus.Counter
+	failedRefreshJoins  prometheus.Counter
+}
+
+func newMetrics() *metrics {
+	m := &metrics{
+		messagesReceived: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "memberlistmesh_cluster_messages_received_total",
+			Help: "Total number of cluster messages received.",
+		}, []string{"msg_type"}),
+		messagesReceivedSize: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "memberlistmesh_cluster_messages_received_size_total",
+			Help: "Total size of cluster messages received.",
+		}, []string{"msg_type"}),
+		messagesSent: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "memberlistmesh_cluster_messages_sent_total",
+			Help: "Total number of cluster messages sent.",
+		}, []string{"msg_type"}),
+		messagesSentSize: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "memberlistmesh_cluster_messages_sent_size_total",
+			Help: "Total size of cluster messages sent.",
+		}, []string{"msg_type"}),
+		oversizedMessagesSent: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "memberlistmesh_oversized_gossip_message_sent_total",
+			Help: "Number of oversized gossip message sent.",
+		}),
+		oversizedMessagesFailed: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "memberlistmesh_oversized_gossip_message_failure_total",
+			Help: "Number of oversized gossip message sends that failed.",
+		}),
+		peersJoined: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "alertmanager_cluster_peers_joined_total",
+			Help: "A counter of the number of peers that have joined.",
+		}),
+		peersLeft: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "alertmanager_cluster_peers_left_total",
+			Help: "A counter of the number of peers that have left.",
+		}),
+		peersUpdated: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "alertmanager_cluster_peers_update_total",
+			Help: "A counter of the number of peers that have updated metadata.",
+		}),
+		reconnections: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "alertmanager_cluster_reconnections_total",
+			Help: "A counter of the number of cluster peer reconnections.",
+		}),
+		failedReconnections: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "alertmanager_cluster_reconnections_failed_total",
+			Help: "A counter of the number of failed cluster peer reconnection attempts.",
+		}),
+		refreshJoins: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "alertmanager_cluster_refresh_join_total",
+			Help: "A counter of the number of cluster peer joined via refresh.",
+		}),
+		failedRefreshJoins: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "alertmanager_cluster_refresh_join_failed_total",
+			Help: "A counter of the number of failed cluster peer joined attempts via refresh.",
+		}),
+	}
+
+	for _, messageType := range []string{messageTypeFullState, messageTypeUpdate} {
+		m.messagesReceived.WithLabelValues(messageType)
+		m.messagesReceivedSize.WithLabelValues(messageType)
+		m.messagesSent.WithLabelValues(messageType)
+		m.messagesSentSize.WithLabelValues(messageType)
+	}
+	return m
+}
+
+// register registers the metrics with reg, along with the gauges reporting the state of the gossiper.
+func (m *metrics) register(reg prometheus.Registerer, g *MemberlistGossiper) error {
+	members := prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "memberlistmesh_cluster_members",
+		Help: "Number indicating current number of members in cluster.",
+	}, func() float64 {
+		return float64(g.list.NumMembers())
+	})
+	healthScore := prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "memberlistmesh_cluster_health_score",
+		Help: "Health score of the cluster. Lower values are better and zero means 'totally healthy'.",
+	}, func() float64 {
+		return float64(g.list.GetHealthScore())
+	})
+	messagesQueued := prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "memberlistmesh_cluster_messages_queued",
+		Help: "Number of cluster messages which are queued.",
+	}, func() float64 {
+		return float64(g.delegate.broadcasts.NumQueued())
+	})
+	failedPeers := prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "alertmanager_cluster_failed_peers",
+		Help: "Number indicating the current number of failed peers in the cluster.",
+	}, func() float64 {
+		return float64(g.delegate.numFailedPeers())
+	})
+
+	collectors := []prometheus.Collector{
+		m.messagesReceived, m.messagesReceivedSize, m.messagesSent, m.messagesSentSize,
+		m.oversizedMessagesSent, m.oversizedMessagesFailed,
+		m.peersJoined, m.peersLeft, m.peersUpdated,
+		m.reconnections, m.failedReconnections, m.refreshJoins, m.failedRefreshJoins,
+		members, healthScore, messagesQueued, failedPeers,
+	}
+	for _, c := range collectors {
+		if err := reg.Register(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -29,8 +29,16 @@ import (
 )
 
 func init() {
-	gossip.Register("mesh", func(listen, channelName, gossipName string, gossipSecret []byte, gossipSeeds gossip.SeedProvider) (gossip.GossipState, error) {
-		return NewMeshGossiper(listen, channelName, gossipName, gossipSecret, gossipSeeds)
+	gossip.Register("mesh", func(listen, channelName, gossipName string, gossipSecrets [][]byte, gossipSeeds gossip.SeedProvider) (gossip.GossipState, error) {
+		var password []byte
+		if len(gossipSecrets) != 0 {
+			password = gossipSecrets[0]
+		}
+		if len(gossipSecrets) > 1 {
+			// mesh authenticates connections with a single password, so peers using a different secret would be unreachable
+			return nil, fmt.Errorf("mesh gossip only supports a single gossip secret, use memberlist to rotate the gossip secret")
+		}
+		return NewMeshGossiper(listen, channelName, gossipName, password, gossipSeeds)
 	})
 }
 
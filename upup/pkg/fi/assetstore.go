@@ -93,6 +93,9 @@ func (r *assetResource) GetSource() *Source {
 type AssetStore struct {
 	cacheDir string
 	assets   []*asset
+
+	// verifySignature, if set, checks the signature of each asset once downloaded.
+	verifySignature func(url string, hash *hashing.Hash) error
 }
 
 func NewAssetStore(cacheDir string) *AssetStore {
@@ -102,6 +105,12 @@ func NewAssetStore(cacheDir string) *AssetStore {
 	return a
 }
 
+// SetSignatureVerifier sets the function checking the signature of each asset added afterwards,
+// given the url the asset was downloaded from and its verified hash.
+func (a *AssetStore) SetSignatureVerifier(verifySignature func(url string, hash *hashing.Hash) error) {
+	a.verifySignature = verifySignature
+}
+
 func (a *AssetStore) FindMatches(expr *regexp.Regexp) map[string]Resource {
 	matches := make(map[string]Resource)
 
@@ -245,12 +254,14 @@ func (a *AssetStore) addURLs(urls []string, hash *hashing.Hash) error {
 	key := path.Base(primaryURL)
 	localFile := path.Join(a.cacheDir, hash.String()+"_"+utils.SanitizeString(key))
 
+	var downloadedURL string
 	for _, url := range urls {
 		_, err = DownloadURL(url, localFile, hash)
 		if err != nil {
 			klog.Warningf("error downloading url %q: %v", url, err)
 			continue
 		} else {
+			downloadedURL = url
 			break
 		}
 	}
@@ -258,6 +269,12 @@ func (a *AssetStore) addURLs(urls []string, hash *hashing.Hash) error {
 		return err
 	}
 
+	if a.verifySignature != nil {
+		if err := a.verifySignature(downloadedURL, hash); err != nil {
+			return fmt.Errorf("error verifying signature of %q: %w", downloadedURL, err)
+		}
+	}
+
 	assetPath := primaryURL
 	r := NewFileResource(localFile)
 
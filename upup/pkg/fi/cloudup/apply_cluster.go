@@ -33,6 +33,7 @@ import (
 	"k8s.io/kops/pkg/apis/kops/registry"
 	"k8s.io/kops/pkg/apis/kops/util"
 	"k8s.io/kops/pkg/apis/kops/validation"
+	"k8s.io/kops/pkg/apis/nodeup"
 	"k8s.io/kops/pkg/assets"
 	"k8s.io/kops/pkg/client/simple"
 	"k8s.io/kops/pkg/featureflag"
@@ -47,6 +48,7 @@ import (
 	"k8s.io/kops/pkg/model/gcemodel"
 	"k8s.io/kops/pkg/model/hetznermodel"
 	"k8s.io/kops/pkg/model/iam"
+	"k8s.io/kops/pkg/model/metalmodel"
 	"k8s.io/kops/pkg/model/openstackmodel"
 	"k8s.io/kops/pkg/model/scalewaymodel"
 	"k8s.io/kops/pkg/nodemodel"
@@ -59,6 +61,7 @@ import (
 	"k8s.io/kops/upup/pkg/fi/cloudup/do"
 	"k8s.io/kops/upup/pkg/fi/cloudup/gce"
 	"k8s.io/kops/upup/pkg/fi/cloudup/hetzner"
+	"k8s.io/kops/upup/pkg/fi/cloudup/metal"
 	"k8s.io/kops/upup/pkg/fi/cloudup/openstack"
 	"k8s.io/kops/upup/pkg/fi/cloudup/scaleway"
 	"k8s.io/kops/upup/pkg/fi/cloudup/terraform"
@@ -180,9 +183,9 @@ func (c *ApplyClusterCmd) Run(ctx context.Context) error {
 	}
 
 	for _, ig := range c.InstanceGroups {
-		// Try to guess the path for additional third party volume plugins in Flatcar
+		// Try to guess the path for additional third party volume plugins in Flatcar and Fedora CoreOS
 		image := strings.ToLower(ig.Spec.Image)
-		if strings.Contains(image, "flatcar") {
+		if strings.Contains(image, "flatcar") || strings.Contains(image, "fedora-coreos") {
 			if c.Cluster.Spec.Kubelet == nil {
 				c.Cluster.Spec.Kubelet = &kops.KubeletConfigSpec{}
 			}
@@ -365,6 +368,19 @@ func (c *ApplyClusterCmd) Run(ctx context.Context) error {
 		encryptionConfigSecretHash = base64.URLEncoding.EncodeToString(hashBytes[:])
 	}
 
+	var gossipKeyset *nodeup.GossipKeyset
+	if cluster.UsesLegacyGossip() {
+		gossipKeyset, err = loadGossipKeyset(secretStore)
+		if err != nil {
+			return err
+		}
+		if gossipKeyset != nil {
+			if err := nodeup.CheckGossipKeysetProtocols(cluster); err != nil {
+				return err
+			}
+		}
+	}
+
 	ciliumSpec := c.Cluster.Spec.Networking.Cilium
 	if ciliumSpec != nil && ciliumSpec.EnableEncryption && ciliumSpec.EncryptionType == kops.CiliumEncryptionTypeIPSec {
 		secret, err := secretStore.FindSecret("ciliumpassword")
@@ -481,6 +497,13 @@ func (c *ApplyClusterCmd) Run(ctx context.Context) error {
 			scwZone = scwCloud.Zone()
 		}
 
+	case kops.CloudProviderMetal:
+		{
+			if !featureflag.Metal.Enabled() {
+				return fmt.Errorf("bare-metal support is currently alpha, and is feature-gated.  export KOPS_FEATURE_FLAGS=Metal")
+			}
+		}
+
 	default:
 		return fmt.Errorf("unknown CloudProvider %q", cluster.Spec.GetCloudProvider())
 	}
@@ -500,7 +523,7 @@ func (c *ApplyClusterCmd) Run(ctx context.Context) error {
 		cloud:            cloud,
 	}
 
-	configBuilder, err := nodemodel.NewNodeUpConfigBuilder(cluster, assetBuilder, fileAssets.Assets, encryptionConfigSecretHash)
+	configBuilder, err := nodemodel.NewNodeUpConfigBuilder(cluster, assetBuilder, fileAssets.Assets, encryptionConfigSecretHash, gossipKeyset)
 	if err != nil {
 		return err
 	}
@@ -685,6 +708,11 @@ func (c *ApplyClusterCmd) Run(ctx context.Context) error {
 				&scalewaymodel.SSHKeyModelBuilder{ScwModelContext: scwModelContext, Lifecycle: securityLifecycle},
 			)
 
+		case kops.CloudProviderMetal:
+			l.Builders = append(l.Builders,
+				&metalmodel.HostsModelBuilder{KopsModelContext: modelContext, BootstrapScriptBuilder: bootstrapScriptBuilder, Lifecycle: clusterLifecycle},
+			)
+
 		default:
 			return fmt.Errorf("unknown cloudprovider %q", cluster.Spec.GetCloudProvider())
 		}
@@ -715,6 +743,8 @@ func (c *ApplyClusterCmd) Run(ctx context.Context) error {
 			target = azure.NewAzureAPITarget(cloud.(azure.AzureCloud))
 		case kops.CloudProviderScaleway:
 			target = scaleway.NewScwAPITarget(cloud.(scaleway.ScwCloud))
+		case kops.CloudProviderMetal:
+			target = metal.NewMetalAPITarget(cloud.(metal.MetalCloud))
 		default:
 			return fmt.Errorf("direct configuration not supported with CloudProvider:%q", cluster.Spec.GetCloudProvider())
 		}
@@ -778,6 +808,19 @@ func (c *ApplyClusterCmd) Run(ctx context.Context) error {
 		return fmt.Errorf("error building context: %v", err)
 	}
 
+	if !c.GetAssets {
+		verifier, err := assets.NewSignatureVerifierForCluster(cluster)
+		if err != nil {
+			return fmt.Errorf("error building signature verifier: %w", err)
+		}
+		if verifier != nil && verifier.Files {
+			klog.Infof("verifying signatures of file assets")
+			if err := verifier.VerifyFileAssets(c.Clientset.VFSContext(), assetBuilder.FileAssets); err != nil {
+				return fmt.Errorf("error verifying signatures of assets: %w", err)
+			}
+		}
+	}
+
 	var options fi.RunTasksOptions
 	if c.RunTasksOptions != nil {
 		options = *c.RunTasksOptions
@@ -1015,6 +1058,18 @@ func (c *ApplyClusterCmd) validateKubernetesVersion() error {
 	return nil
 }
 
+// loadGossipKeyset returns the secrets securing gossip DNS, or nil if they are not stored in the secret store.
+func loadGossipKeyset(secretStore fi.SecretStoreReader) (*nodeup.GossipKeyset, error) {
+	secret, err := secretStore.FindSecret(nodeup.GossipSecretName)
+	if err != nil {
+		return nil, fmt.Errorf("could not load the %s secret: %w", nodeup.GossipSecretName, err)
+	}
+	if secret == nil {
+		return nil, nil
+	}
+	return nodeup.ParseGossipKeyset(secret.Data)
+}
+
 // buildPermalink returns a link to our "permalink docs", to further explain an error message
 func buildPermalink(key, anchor string) string {
 	url := "https://github.com/kubernetes/kops/blob/master/permalinks/" + key + ".md"
@@ -105,10 +105,13 @@ const TagNameClusterOwnershipPrefix = "kubernetes.io/cluster/"
 const tagNameDetachedInstance = "kops.k8s.io/detached-from-asg"
 
 const (
+	WellKnownAccountAlmaLinux    = "764336703387"
 	WellKnownAccountAmazonLinux2 = "137112412989"
 	WellKnownAccountDebian       = "136693071363"
+	WellKnownAccountFedora       = "125523088429"
 	WellKnownAccountFlatcar      = "075585003325"
 	WellKnownAccountRedhat       = "309956199498"
+	WellKnownAccountRocky        = "792107900819"
 	WellKnownAccountUbuntu       = "099720109477"
 )
 
@@ -1953,16 +1956,22 @@ func resolveImage(ctx context.Context, ssmClient awsinterfaces.SSMAPI, ec2Client
 
 			// Check for well known owner aliases
 			switch owner {
+			case "almalinux":
+				owner = WellKnownAccountAlmaLinux
 			case "amazon", "amazon.com":
 				owner = WellKnownAccountAmazonLinux2
 			case "debian10":
 				owner = WellKnownAccountDebian
 			case "debian11":
 				owner = WellKnownAccountDebian
+			case "fedora":
+				owner = WellKnownAccountFedora
 			case "flatcar":
 				owner = WellKnownAccountFlatcar
 			case "redhat", "redhat.com":
 				owner = WellKnownAccountRedhat
+			case "rocky":
+				owner = WellKnownAccountRocky
 			case "ubuntu":
 				owner = WellKnownAccountUbuntu
 			}
@@ -425,6 +425,40 @@ func (b *BootstrapChannelBuilder) buildAddons(c *fi.CloudupModelBuilderContext)
 		}
 	}
 
+	if b.verifiesEtcdBackups() {
+		key := "etcd-backup-verification.addons.k8s.io"
+
+		{
+			location := key + "/k8s-1.23.yaml"
+			id := "k8s-1.23"
+
+			addon := addons.Add(&channelsapi.AddonSpec{
+				Name:     fi.PtrTo(key),
+				Selector: map[string]string{"k8s-addon": key},
+				Manifest: fi.PtrTo(location),
+				Id:       id,
+			})
+			addon.BuildPrune = true
+		}
+	}
+
+	if b.Cluster.Spec.Authentication != nil && b.Cluster.Spec.Authentication.AdminCredentialDenyList {
+		// Admin credentials aren't members of system:masters when they can be revoked, so they need a binding to cluster-admin
+		key := "admin-credentials.rbac.addons.k8s.io"
+
+		{
+			location := key + "/k8s-1.30.yaml"
+			id := "k8s-1.30"
+
+			addons.Add(&channelsapi.AddonSpec{
+				Name:     fi.PtrTo(key),
+				Selector: map[string]string{"k8s-addon": key},
+				Manifest: fi.PtrTo(location),
+				Id:       id,
+			})
+		}
+	}
+
 	if b.IsKubernetesLT("1.26") &&
 		(b.Cluster.Spec.GetCloudProvider() == kops.CloudProviderAWS ||
 			b.Cluster.Spec.GetCloudProvider() == kops.CloudProviderGCE) {
@@ -656,6 +690,23 @@ func (b *BootstrapChannelBuilder) buildAddons(c *fi.CloudupModelBuilderContext)
 		}
 	}
 
+	if len(b.KopsModelContext.SandboxRuntimes()) > 0 {
+		key := "sandbox-runtimes.addons.k8s.io"
+
+		{
+			location := key + "/k8s-1.20.yaml"
+			id := "k8s-1.20"
+
+			addon := addons.Add(&channelsapi.AddonSpec{
+				Name:     fi.PtrTo(key),
+				Selector: map[string]string{"k8s-addon": key},
+				Manifest: fi.PtrTo(location),
+				Id:       id,
+			})
+			addon.BuildPrune = true
+		}
+	}
+
 	if b.Cluster.Spec.CloudProvider.AWS != nil {
 		if b.Cluster.Spec.CloudProvider.AWS.LoadBalancerController != nil && fi.ValueOf(b.Cluster.Spec.CloudProvider.AWS.LoadBalancerController.Enabled) {
 
@@ -1245,3 +1296,13 @@ func (b *BootstrapChannelBuilder) hasExternalAddon(key string) bool {
 	}
 	return false
 }
+
+// verifiesEtcdBackups returns whether the backups of any etcd cluster are verified on a schedule.
+func (b *BootstrapChannelBuilder) verifiesEtcdBackups() bool {
+	for _, etcdCluster := range b.Cluster.Spec.EtcdClusters {
+		if etcdCluster.Backups != nil && etcdCluster.Backups.Verification != nil {
+			return true
+		}
+	}
+	return false
+}
@@ -174,6 +174,12 @@ func precreateDNS(ctx context.Context, cluster *kops.Cluster, cloud fi.Cloud) er
 		recordsMap[key] = record
 	}
 
+	// When dns-controller records ownership, it only updates the records we pre-create if we claim them for it
+	var registry *dns.TXTRegistry
+	if usesTXTOwnership(cluster) {
+		registry = &dns.TXTRegistry{OwnerID: txtOwnerID(cluster)}
+	}
+
 	changeset := rrs.StartChangeset()
 	// TODO: Add ChangeSet.IsEmpty() method
 	var created []recordKey
@@ -181,6 +187,7 @@ func precreateDNS(ctx context.Context, cluster *kops.Cluster, cloud fi.Cloud) er
 	for _, recordKey := range recordKeys {
 		recordKey.hostname = dns.EnsureDotSuffix(recordKey.hostname)
 		foundAddress := false
+		placeholderAddress := false
 		{
 			dnsRecord := recordsMap[string(recordKey.rrsType)+"::"+recordKey.hostname]
 			if dnsRecord != nil {
@@ -192,12 +199,17 @@ func precreateDNS(ctx context.Context, cluster *kops.Cluster, cloud fi.Cloud) er
 					klog.V(4).Infof("Found DNS record %s, but no records", recordKey)
 				}
 				foundAddress = true
+				placeholderAddress = len(rrdatas) == 1 && (rrdatas[0] == PlaceholderIP || rrdatas[0] == PlaceholderIPv6)
 			}
 		}
 
+		txtName := recordKey.hostname
+		if registry != nil {
+			txtName = registry.OwnershipName(recordKey.hostname, dns.RecordType(recordKey.rrsType))
+		}
 		foundTXT := false
 		{
-			dnsRecord := recordsMap["TXT::"+recordKey.hostname]
+			dnsRecord := recordsMap["TXT::"+txtName]
 			if dnsRecord != nil {
 				foundTXT = true
 			}
@@ -212,20 +224,26 @@ func precreateDNS(ctx context.Context, cluster *kops.Cluster, cloud fi.Cloud) er
 		}
 		klog.V(2).Infof("Pre-creating DNS record %s => %s", recordKey, ip)
 
+		ttl := int64(PlaceholderTTL)
+		if cloud.ProviderID() == kops.CloudProviderDO {
+			ttl = PlaceholderTTLDigitialOcean
+		}
 		if !foundAddress {
-			if cloud.ProviderID() == kops.CloudProviderDO {
-				changeset.Add(rrs.New(recordKey.hostname, []string{ip}, PlaceholderTTLDigitialOcean, recordKey.rrsType))
-			} else {
-				changeset.Add(rrs.New(recordKey.hostname, []string{ip}, PlaceholderTTL, recordKey.rrsType))
-			}
+			changeset.Add(rrs.New(recordKey.hostname, []string{ip}, ttl, recordKey.rrsType))
 		}
 		if !foundTXT {
-			if cluster.Spec.ExternalDNS != nil && cluster.Spec.ExternalDNS.Provider == kops.ExternalDNSProviderExternalDNS {
+			if registry != nil {
+				// We only claim records that are ours: the ones we create, and the placeholders created by earlier runs.
+				// Other existing records are only adopted by dns-controller with txtAdoptExisting.
+				if !foundAddress || placeholderAddress {
+					changeset.Add(registry.NewOwnershipRecord(rrs, recordKey.hostname, dns.RecordType(recordKey.rrsType), ttl))
+				}
+			} else if cluster.Spec.ExternalDNS != nil && cluster.Spec.ExternalDNS.Provider == kops.ExternalDNSProviderExternalDNS {
 				domain := recordKey.hostname
 				if ip == PlaceholderIPv6 {
 					domain = "aaaa-" + domain
 				}
-				changeset.Add(rrs.New(domain, []string{fmt.Sprintf("\"heritage=external-dns,external-dns/owner=kops-%s\"", cluster.ObjectMeta.Name)}, PlaceholderTTL, rrstype.TXT))
+				changeset.Add(rrs.New(domain, []string{fmt.Sprintf("\"heritage=external-dns,external-dns/owner=%s\"", txtOwnerID(cluster))}, PlaceholderTTL, rrstype.TXT))
 			}
 		}
 		created = append(created, recordKey)
@@ -244,6 +262,17 @@ func precreateDNS(ctx context.Context, cluster *kops.Cluster, cloud fi.Cloud) er
 	return nil
 }
 
+// usesTXTOwnership returns true if the dns-controller of the cluster records ownership of its records in TXT records
+func usesTXTOwnership(cluster *kops.Cluster) bool {
+	externalDNS := cluster.Spec.ExternalDNS
+	return externalDNS != nil && externalDNS.Provider != kops.ExternalDNSProviderExternalDNS && fi.ValueOf(externalDNS.TXTOwnership)
+}
+
+// txtOwnerID returns the owner id of the records of the cluster, for both dns-controller and external-dns
+func txtOwnerID(cluster *kops.Cluster) string {
+	return "kops-" + cluster.ObjectMeta.Name
+}
+
 // buildPrecreateDNSHostnames returns the hostnames we should precreate
 func buildPrecreateDNSHostnames(cluster *kops.Cluster) []recordKey {
 	var recordKeys []recordKey
@@ -17,12 +17,21 @@ limitations under the License.
 package cloudup
 
 import (
+	"context"
 	"reflect"
 	"sort"
 	"testing"
+	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
+	route53types "github.com/aws/aws-sdk-go-v2/service/route53/types"
+	"k8s.io/kops/cloudmock/aws/mockroute53"
+	"k8s.io/kops/dns-controller/pkg/dns"
+	"k8s.io/kops/dnsprovider/pkg/dnsprovider"
 	"k8s.io/kops/dnsprovider/pkg/dnsprovider/rrstype"
 	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/cloudup/awsup"
 )
 
 func TestPrecreateDNSNames(t *testing.T) {
@@ -158,3 +167,101 @@ func TestPrecreateDNSNames(t *testing.T) {
 		}
 	}
 }
+
+// TestPrecreateDNSWithTXTOwnership checks that dns-controller updates the records we pre-create when it records ownership
+func TestPrecreateDNSWithTXTOwnership(t *testing.T) {
+	ctx := context.Background()
+
+	route53 := &mockroute53.MockRoute53{}
+	route53.MockCreateZone(&route53types.HostedZone{
+		Id:   aws.String("/hostedzone/Z1"),
+		Name: aws.String("example.com."),
+	}, nil)
+	cloud := awsup.BuildMockAWSCloud("us-east-1", "a")
+	cloud.MockRoute53 = route53
+
+	cluster := &kops.Cluster{}
+	cluster.ObjectMeta.Name = "cluster1.example.com"
+	cluster.Spec.DNSZone = "example.com"
+	cluster.Spec.CloudProvider.AWS = &kops.AWSSpec{}
+	cluster.Spec.API.PublicName = "api.cluster1.example.com"
+	cluster.Spec.ExternalDNS = &kops.ExternalDNSConfig{
+		Provider:     kops.ExternalDNSProviderDNSController,
+		TXTOwnership: fi.PtrTo(true),
+	}
+
+	if err := precreateDNS(ctx, cluster, cloud); err != nil {
+		t.Fatalf("error pre-creating DNS records: %v", err)
+	}
+
+	provider, err := cloud.DNS()
+	if err != nil {
+		t.Fatalf("error building DNS provider: %v", err)
+	}
+	zoneRules, err := dns.ParseZoneRules([]string{"*"})
+	if err != nil {
+		t.Fatalf("error parsing zone rules: %v", err)
+	}
+	c, err := dns.NewDNSController([]dnsprovider.Interface{provider}, zoneRules, 1, &dns.TXTRegistry{OwnerID: txtOwnerID(cluster)})
+	if err != nil {
+		t.Fatalf("error building dns-controller: %v", err)
+	}
+	scope, err := c.CreateScope("test")
+	if err != nil {
+		t.Fatalf("error creating scope: %v", err)
+	}
+	scope.Replace("api", []dns.Record{
+		{RecordType: dns.RecordTypeA, FQDN: "api.cluster1.example.com.", Value: "198.51.100.1"},
+		{RecordType: dns.RecordTypeA, FQDN: "api.internal.cluster1.example.com.", Value: "10.0.0.1"},
+	})
+	scope.MarkReady()
+
+	go c.Run()
+	defer c.Stop()
+
+	want := map[string]string{
+		"api.cluster1.example.com. A":          "198.51.100.1",
+		"api.internal.cluster1.example.com. A": "10.0.0.1",
+	}
+	var got map[string]string
+	for deadline := time.Now().Add(30 * time.Second); time.Now().Before(deadline); time.Sleep(100 * time.Millisecond) {
+		got, err = listPrecreateTestRecords(provider)
+		if err != nil {
+			t.Fatalf("error listing records: %v", err)
+		}
+		if got["api.cluster1.example.com. A"] == want["api.cluster1.example.com. A"] && got["api.internal.cluster1.example.com. A"] == want["api.internal.cluster1.example.com. A"] {
+			break
+		}
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("dns-controller did not update the pre-created record %s: got %q, want %q", k, got[k], v)
+		}
+	}
+	for _, name := range []string{"a-api.cluster1.example.com.", "a-api.internal.cluster1.example.com."} {
+		if got[name+" TXT"] != `"heritage=external-dns,external-dns/owner=kops-cluster1.example.com"` {
+			t.Errorf("unexpected ownership record %s: %q", name, got[name+" TXT"])
+		}
+	}
+}
+
+// listPrecreateTestRecords returns the first value of each record of the example.com zone, keyed by name and type
+func listPrecreateTestRecords(provider dnsprovider.Interface) (map[string]string, error) {
+	zones, _ := provider.Zones()
+	zoneList, err := zones.List()
+	if err != nil {
+		return nil, err
+	}
+	rrsProvider, _ := zoneList[0].ResourceRecordSets()
+	rrs, err := rrsProvider.List()
+	if err != nil {
+		return nil, err
+	}
+	records := make(map[string]string)
+	for _, rr := range rrs {
+		if len(rr.Rrdatas()) != 0 {
+			records[rr.Name()+" "+string(rr.Type())] = rr.Rrdatas()[0]
+		}
+	}
+	return records, nil
+}
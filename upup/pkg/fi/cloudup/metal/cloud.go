@@ -0,0 +1,104 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metal
+
+import (
+	"errors"
+	"net"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/kops/dnsprovider/pkg/dnsprovider"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/cloudinstances"
+	"k8s.io/kops/upup/pkg/fi"
+)
+
+// MetalCloud is the "cloud" of bare-metal hosts, which are enrolled over SSH rather than created through an API.
+type MetalCloud interface {
+	fi.Cloud
+}
+
+// static compile time check to validate MetalCloud's fi.Cloud Interface.
+var _ fi.Cloud = &metalCloudImplementation{}
+
+type metalCloudImplementation struct{}
+
+// NewMetalCloud returns the cloud of bare-metal hosts.
+func NewMetalCloud() (MetalCloud, error) {
+	return &metalCloudImplementation{}, nil
+}
+
+// ProviderID returns the kOps API identifier for bare metal
+func (c *metalCloudImplementation) ProviderID() kops.CloudProviderID {
+	return kops.CloudProviderMetal
+}
+
+// DNS returns nil, bare-metal clusters don't publish DNS records
+func (c *metalCloudImplementation) DNS() (dnsprovider.Interface, error) {
+	return nil, nil
+}
+
+// FindVPCInfo is not implemented, bare-metal hosts are not in a VPC
+func (c *metalCloudImplementation) FindVPCInfo(id string) (*fi.VPCInfo, error) {
+	return nil, errors.New("bare metal does not support VPCs")
+}
+
+// DeleteInstance is not implemented, bare-metal hosts are not deleted by kOps
+func (c *metalCloudImplementation) DeleteInstance(instance *cloudinstances.CloudInstance) error {
+	return errors.New("bare-metal hosts cannot be deleted by kOps")
+}
+
+// DeregisterInstance does nothing, bare-metal hosts are not behind load balancers
+func (c *metalCloudImplementation) DeregisterInstance(instance *cloudinstances.CloudInstance) error {
+	return nil
+}
+
+// DeleteGroup is not implemented, bare-metal hosts are not deleted by kOps
+func (c *metalCloudImplementation) DeleteGroup(group *cloudinstances.CloudInstanceGroup) error {
+	return errors.New("bare-metal hosts cannot be deleted by kOps")
+}
+
+// DetachInstance is not implemented, bare-metal hosts are not in cloud groups
+func (c *metalCloudImplementation) DetachInstance(instance *cloudinstances.CloudInstance) error {
+	return errors.New("bare-metal hosts cannot be detached")
+}
+
+// GetCloudGroups returns no groups, as bare-metal hosts are not in cloud groups.
+// Rolling updates are not supported; hosts are reinstalled with `kops toolbox enroll --reinstall` instead.
+func (c *metalCloudImplementation) GetCloudGroups(cluster *kops.Cluster, instancegroups []*kops.InstanceGroup, warnUnmatched bool, nodes []v1.Node) (map[string]*cloudinstances.CloudInstanceGroup, error) {
+	return make(map[string]*cloudinstances.CloudInstanceGroup), nil
+}
+
+// Region returns "", the region concept does not apply to bare metal
+func (c *metalCloudImplementation) Region() string {
+	return ""
+}
+
+// FindClusterStatus was used before etcd-manager to check the etcd cluster status and prevent unsupported changes.
+func (c *metalCloudImplementation) FindClusterStatus(cluster *kops.Cluster) (*kops.ClusterStatus, error) {
+	return nil, nil
+}
+
+// GetApiIngressStatus returns the public name of the API when it is an IP address.
+// Without load balancers, that is the address of a control-plane host.
+func (c *metalCloudImplementation) GetApiIngressStatus(cluster *kops.Cluster) ([]fi.ApiIngressStatus, error) {
+	var ingresses []fi.ApiIngressStatus
+	if ip := net.ParseIP(cluster.Spec.API.PublicName); ip != nil {
+		ingresses = append(ingresses, fi.ApiIngressStatus{IP: ip.String()})
+	}
+	return ingresses, nil
+}
@@ -0,0 +1,33 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metal
+
+import "path/filepath"
+
+// EtcdVolumesDir is the directory of the local-disk volumes etcd-manager finds with its external volume provider.
+const EtcdVolumesDir = "/mnt/disks"
+
+// EtcdVolumeTag is the prefix of the names of the volumes of an etcd cluster.
+func EtcdVolumeTag(clusterName string, etcdClusterName string) string {
+	return clusterName + "--" + etcdClusterName + "--"
+}
+
+// EtcdVolumePath is the directory of the volume of an etcd cluster on the host of an instance group.
+// etcd-manager keeps its data in the "mnt" directory of the volume.
+func EtcdVolumePath(clusterName string, etcdClusterName string, instanceGroupName string) string {
+	return filepath.Join(EtcdVolumesDir, EtcdVolumeTag(clusterName, etcdClusterName)+instanceGroupName, "mnt")
+}
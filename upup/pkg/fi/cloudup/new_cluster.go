@@ -55,6 +55,14 @@ type NewClusterOptions struct {
 
 	// Authorization is the authorization mode to use. The options are "RBAC" (default) and "AlwaysAllow".
 	Authorization string
+	// OIDCIssuerURL is the URL of the OpenID issuer authenticating users of the cluster.
+	OIDCIssuerURL string
+	// OIDCClientID is the client ID of the cluster at the OpenID issuer.
+	OIDCClientID string
+	// OIDCUsernameClaim is the OpenID claim to use as the username.
+	OIDCUsernameClaim string
+	// OIDCGroupsClaims are the OpenID claims to use as the groups of the user.
+	OIDCGroupsClaims []string
 	// Channel is a channel location for initializing the cluster. It defaults to "stable".
 	Channel string
 	// ConfigBase is the location where we will store the configuration. It defaults to the state store.
@@ -238,6 +246,20 @@ func NewCluster(opt *NewClusterOptions, clientset simple.Clientset) (*NewCluster
 		return nil, fmt.Errorf("unknown authorization mode %q", opt.Authorization)
 	}
 
+	if opt.OIDCIssuerURL != "" {
+		oidc := &api.OIDCAuthenticationSpec{
+			IssuerURL:    fi.PtrTo(opt.OIDCIssuerURL),
+			ClientID:     fi.PtrTo(opt.OIDCClientID),
+			GroupsClaims: opt.OIDCGroupsClaims,
+		}
+		if opt.OIDCUsernameClaim != "" {
+			oidc.UsernameClaim = fi.PtrTo(opt.OIDCUsernameClaim)
+		}
+		cluster.Spec.Authentication = &api.AuthenticationSpec{OIDC: oidc}
+	} else if opt.OIDCClientID != "" || opt.OIDCUsernameClaim != "" || len(opt.OIDCGroupsClaims) != 0 {
+		return nil, fmt.Errorf("the OIDC issuer URL must be set to use OIDC authentication")
+	}
+
 	cluster.Spec.IAM = &api.IAMSpec{
 		AllowContainerRegistry: true,
 	}
@@ -328,6 +350,11 @@ func NewCluster(opt *NewClusterOptions, clientset simple.Clientset) (*NewCluster
 		cluster.Spec.CloudProvider.GCE = &api.GCESpec{}
 	case api.CloudProviderHetzner:
 		cluster.Spec.CloudProvider.Hetzner = &api.HetznerSpec{}
+	case api.CloudProviderMetal:
+		if !featureflag.Metal.Enabled() {
+			return nil, fmt.Errorf("bare-metal support requires the Metal feature flag to be enabled")
+		}
+		cluster.Spec.CloudProvider.Metal = &api.MetalSpec{}
 	case api.CloudProviderOpenstack:
 		cluster.Spec.CloudProvider.Openstack = &api.OpenstackSpec{
 			Router: &api.OpenstackRouter{
@@ -437,7 +464,8 @@ func NewCluster(opt *NewClusterOptions, clientset simple.Clientset) (*NewCluster
 	for _, instanceGroup := range instanceGroups {
 		g := instanceGroup
 		ig := g
-		if instanceGroup.Spec.Image == "" {
+		// Bare-metal hosts are installed before they are enrolled
+		if instanceGroup.Spec.Image == "" && cluster.Spec.GetCloudProvider() != api.CloudProviderMetal {
 			if opt.Image != "" {
 				instanceGroup.Spec.Image = opt.Image
 			} else {
@@ -1445,7 +1473,12 @@ func setupDNSTopology(opt *NewClusterOptions, cluster *api.Cluster) error {
 func setupAPI(opt *NewClusterOptions, cluster *api.Cluster) error {
 	// Populate the API access, so that it can be discoverable
 	klog.Infof("Cloud Provider ID: %q", cluster.Spec.GetCloudProvider())
-	if opt.APILoadBalancerType != "" || opt.APISSLCertificate != "" {
+	if cluster.Spec.GetCloudProvider() == api.CloudProviderMetal {
+		// There are no load balancers on bare metal; kube-apiserver is reached on the addresses of the control-plane hosts
+		if opt.APILoadBalancerType != "" || opt.APISSLCertificate != "" {
+			return fmt.Errorf("load balancers are not supported on bare metal")
+		}
+	} else if opt.APILoadBalancerType != "" || opt.APISSLCertificate != "" {
 		cluster.Spec.API.LoadBalancer = &api.LoadBalancerAccessSpec{}
 	} else {
 		switch opt.Topology {
@@ -132,7 +132,8 @@ func PopulateInstanceGroupSpec(cluster *kops.Cluster, input *kops.InstanceGroup,
 		}
 	}
 
-	if ig.Spec.Image == "" {
+	// Bare-metal hosts are installed before they are enrolled
+	if ig.Spec.Image == "" && cluster.Spec.GetCloudProvider() != kops.CloudProviderMetal {
 		architecture, err := MachineArchitecture(cloud, ig.Spec.MachineType)
 		if err != nil {
 			return nil, fmt.Errorf("unable to determine machine architecture for InstanceGroup %q: %v", ig.ObjectMeta.Name, err)
@@ -143,6 +144,11 @@ func PopulateInstanceGroupSpec(cluster *kops.Cluster, input *kops.InstanceGroup,
 		}
 	}
 
+	// Fedora CoreOS doesn't run shell user data
+	if ig.Spec.UserDataFormat == "" && strings.Contains(strings.ToLower(ig.Spec.Image), "fedora-coreos") {
+		ig.Spec.UserDataFormat = kops.UserDataFormatIgnition
+	}
+
 	if ig.Spec.Tenancy != "" && ig.Spec.Tenancy != "default" {
 		switch cluster.Spec.GetCloudProvider() {
 		case kops.CloudProviderAWS:
@@ -229,6 +235,34 @@ func PopulateInstanceGroupSpec(cluster *kops.Cluster, input *kops.InstanceGroup,
 		}
 	}
 
+	if ig.Spec.Containerd != nil {
+		if gvisor := ig.Spec.Containerd.GVisor; gvisor != nil && fi.ValueOf(gvisor.Enabled) && fi.ValueOf(gvisor.Version) == "" {
+			gvisor.Version = fi.PtrTo(kops.GVisorDefaultVersion)
+		}
+		if kata := ig.Spec.Containerd.Kata; kata != nil && fi.ValueOf(kata.Enabled) && fi.ValueOf(kata.Version) == "" {
+			kata.Version = fi.PtrTo(kops.KataDefaultVersion)
+		}
+	}
+
+	// Nodes with sandboxed runtimes are reserved for the pods using their RuntimeClass, which select them by label and tolerate the taint
+	if sandboxRuntimes := ig.Spec.Containerd.SandboxRuntimes(); len(sandboxRuntimes) > 0 {
+		if ig.Spec.NodeLabels == nil {
+			ig.Spec.NodeLabels = make(map[string]string)
+		}
+		for _, runtime := range sandboxRuntimes {
+			ig.Spec.NodeLabels[kops.SandboxRuntimeLabelPrefix+runtime] = "true"
+		}
+		hasSandboxTaint := false
+		for _, taint := range ig.Spec.Taints {
+			if strings.HasPrefix(taint, kops.SandboxRuntimeTaintKey) {
+				hasSandboxTaint = true
+			}
+		}
+		if !hasSandboxTaint {
+			ig.Spec.Taints = append(ig.Spec.Taints, kops.SandboxRuntimeTaintKey+":NoSchedule")
+		}
+	}
+
 	if ig.Spec.Manager == "" {
 		ig.Spec.Manager = kops.InstanceManagerCloudGroup
 	}
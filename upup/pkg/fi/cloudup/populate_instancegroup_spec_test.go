@@ -235,6 +235,83 @@ func TestPopulateInstanceGroup_AddTaints(t *testing.T) {
 	}
 }
 
+func TestPopulateInstanceGroup_SandboxRuntimes(t *testing.T) {
+	_, cluster := buildMinimalCluster()
+	input := buildMinimalNodeInstanceGroup()
+	input.Spec.Containerd = &kopsapi.ContainerdConfig{
+		GVisor: &kopsapi.GVisorConfig{Enabled: fi.PtrTo(true)},
+		Kata:   &kopsapi.KataConfig{Enabled: fi.PtrTo(true)},
+	}
+
+	channel := &kopsapi.Channel{}
+
+	cloud, err := BuildCloud(cluster)
+	if err != nil {
+		t.Fatalf("error from BuildCloud: %v", err)
+	}
+	output, err := PopulateInstanceGroupSpec(cluster, input, cloud, channel)
+	if err != nil {
+		t.Fatalf("error from PopulateInstanceGroupSpec: %v", err)
+	}
+	if len(output.Spec.Taints) != 1 || output.Spec.Taints[0] != "sandbox.kops.k8s.io/runtime:NoSchedule" {
+		t.Errorf("Expected only the sandbox taint, got %v", output.Spec.Taints)
+	}
+	for _, label := range []string{"sandbox.kops.k8s.io/gvisor", "sandbox.kops.k8s.io/kata"} {
+		if output.Spec.NodeLabels[label] != "true" {
+			t.Errorf("Expected node label %q, got %v", label, output.Spec.NodeLabels)
+		}
+	}
+	if fi.ValueOf(output.Spec.Containerd.GVisor.Version) != kopsapi.GVisorDefaultVersion {
+		t.Errorf("Expected default gVisor version, got %q", fi.ValueOf(output.Spec.Containerd.GVisor.Version))
+	}
+	if fi.ValueOf(output.Spec.Containerd.Kata.Version) != kopsapi.KataDefaultVersion {
+		t.Errorf("Expected default Kata version, got %q", fi.ValueOf(output.Spec.Containerd.Kata.Version))
+	}
+	if input.Spec.Containerd.GVisor.Version != nil {
+		t.Errorf("Expected input to be unchanged")
+	}
+}
+
+func TestPopulateInstanceGroup_UserDataFormat(t *testing.T) {
+	for _, test := range []struct {
+		image    string
+		format   kopsapi.UserDataFormat
+		expected kopsapi.UserDataFormat
+	}{
+		{
+			image: "099720109477/ubuntu/images/hvm-ssd-gp3/ubuntu-noble-24.04-amd64-server-20240607",
+		},
+		{
+			image:    "125523088429/fedora-coreos-40.20240616.3.0-x86_64",
+			expected: kopsapi.UserDataFormatIgnition,
+		},
+		{
+			image:    "my-fedora-coreos-image",
+			format:   kopsapi.UserDataFormatShellScript,
+			expected: kopsapi.UserDataFormatShellScript,
+		},
+	} {
+		t.Run(test.image, func(t *testing.T) {
+			_, cluster := buildMinimalCluster()
+			input := buildMinimalNodeInstanceGroup()
+			input.Spec.Image = test.image
+			input.Spec.UserDataFormat = test.format
+
+			cloud, err := BuildCloud(cluster)
+			if err != nil {
+				t.Fatalf("error from BuildCloud: %v", err)
+			}
+			output, err := PopulateInstanceGroupSpec(cluster, input, cloud, &kopsapi.Channel{})
+			if err != nil {
+				t.Fatalf("error from PopulateInstanceGroupSpec: %v", err)
+			}
+			if output.Spec.UserDataFormat != test.expected {
+				t.Errorf("Expected user data format %q, got %q", test.expected, output.Spec.UserDataFormat)
+			}
+		})
+	}
+}
+
 func expectErrorFromPopulateInstanceGroup(t *testing.T, cluster *kopsapi.Cluster, g *kopsapi.InstanceGroup, channel *kopsapi.Channel, message string) {
 	cloud, err := BuildCloud(cluster)
 	if err != nil {
@@ -52,11 +52,13 @@ import (
 	"k8s.io/kops/pkg/apis/kops"
 	apiModel "k8s.io/kops/pkg/apis/kops/model"
 	"k8s.io/kops/pkg/apis/kops/util"
+	"k8s.io/kops/pkg/apis/nodeup"
 	"k8s.io/kops/pkg/bootstrap/pkibootstrap"
 	"k8s.io/kops/pkg/featureflag"
 	"k8s.io/kops/pkg/flagbuilder"
 	"k8s.io/kops/pkg/kubemanifest"
 	"k8s.io/kops/pkg/model"
+	"k8s.io/kops/pkg/model/components/etcdmanager"
 	"k8s.io/kops/pkg/model/components/kopscontroller"
 	"k8s.io/kops/pkg/model/iam"
 	"k8s.io/kops/pkg/resources/spotinst"
@@ -80,6 +82,9 @@ type TemplateFunctions struct {
 	model.KopsModelContext
 
 	cloud fi.Cloud
+
+	// gossipKeyset holds the secrets securing gossip DNS, if stored in the secret store
+	gossipKeyset *nodeup.GossipKeyset
 }
 
 // AddTo defines the available functions we can use in our YAML models.
@@ -116,6 +121,13 @@ func (tf *TemplateFunctions) AddTo(dest template.FuncMap, secretStore fi.SecretS
 		return defaultValue
 	}
 
+	if cluster.UsesLegacyGossip() {
+		tf.gossipKeyset, err = loadGossipKeyset(secretStore)
+		if err != nil {
+			return err
+		}
+	}
+
 	dest["GetCloudProvider"] = cluster.Spec.GetCloudProvider
 	dest["GetInstanceGroup"] = tf.GetInstanceGroup
 	dest["GetNodeInstanceGroups"] = tf.GetNodeInstanceGroups
@@ -405,6 +417,11 @@ func (tf *TemplateFunctions) AddTo(dest template.FuncMap, secretStore fi.SecretS
 
 	dest["KopsFeatureEnabled"] = tf.kopsFeatureEnabled
 	dest["KopsVersion"] = func() string { return kopsroot.KOPS_RELEASE_VERSION }
+	dest["KopsUtilsImage"] = func() string { return etcdmanager.KopsUtilsImage }
+	dest["EtcdImage"] = etcdmanager.EtcdImage
+
+	dest["SandboxRuntimes"] = tf.KopsModelContext.SandboxRuntimes
+	dest["InPlaceUpdatesEnabled"] = tf.KopsModelContext.UsesInPlaceUpdates
 
 	dest["ContainerdSELinuxEnabled"] = func() bool {
 		if cluster.Spec.Containerd != nil {
@@ -563,6 +580,16 @@ func (tf *TemplateFunctions) DNSControllerArgv() ([]string, error) {
 		if cluster.Spec.ExternalDNS.WatchNamespace != "" {
 			argv = append(argv, fmt.Sprintf("--watch-namespace=%s", cluster.Spec.ExternalDNS.WatchNamespace))
 		}
+		if fi.ValueOf(cluster.Spec.ExternalDNS.WatchGatewayAPI) {
+			argv = append(argv, "--watch-gateway-api=true")
+		}
+		if fi.ValueOf(cluster.Spec.ExternalDNS.TXTOwnership) {
+			// We use the same owner id as we configure for external-dns, so that ownership is kept when switching providers
+			argv = append(argv, "--txt-owner-id="+txtOwnerID(cluster))
+			if fi.ValueOf(cluster.Spec.ExternalDNS.TXTAdoptExisting) {
+				argv = append(argv, "--txt-adopt-existing=true")
+			}
+		}
 	}
 
 	if cluster.UsesLegacyGossip() {
@@ -576,7 +603,7 @@ func (tf *TemplateFunctions) DNSControllerArgv() ([]string, error) {
 			if cluster.Spec.DNSControllerGossipConfig.Listen != nil {
 				argv = append(argv, "--gossip-listen="+*cluster.Spec.DNSControllerGossipConfig.Listen)
 			}
-			if cluster.Spec.DNSControllerGossipConfig.Secret != nil {
+			if tf.gossipKeyset == nil && cluster.Spec.DNSControllerGossipConfig.Secret != nil {
 				argv = append(argv, "--gossip-secret="+*cluster.Spec.DNSControllerGossipConfig.Secret)
 			}
 
@@ -593,7 +620,7 @@ func (tf *TemplateFunctions) DNSControllerArgv() ([]string, error) {
 				if cluster.Spec.DNSControllerGossipConfig.Secondary.Listen != nil {
 					argv = append(argv, "--gossip-listen-secondary="+*cluster.Spec.DNSControllerGossipConfig.Secondary.Listen)
 				}
-				if cluster.Spec.DNSControllerGossipConfig.Secondary.Secret != nil {
+				if tf.gossipKeyset == nil && cluster.Spec.DNSControllerGossipConfig.Secondary.Secret != nil {
 					argv = append(argv, "--gossip-secret-secondary="+*cluster.Spec.DNSControllerGossipConfig.Secondary.Secret)
 				}
 
@@ -611,6 +638,15 @@ func (tf *TemplateFunctions) DNSControllerArgv() ([]string, error) {
 			argv = append(argv, fmt.Sprintf("--gossip-listen-secondary=0.0.0.0:%d", wellknownports.DNSControllerGossipMemberlist))
 			argv = append(argv, fmt.Sprintf("--gossip-seed-secondary=127.0.0.1:%d", wellknownports.ProtokubeGossipMemberlist))
 		}
+
+		if tf.gossipKeyset != nil {
+			argv = append(argv, "--gossip-secret="+tf.gossipKeyset.Primary)
+			argv = append(argv, "--gossip-secret-secondary="+tf.gossipKeyset.Primary)
+			for _, secret := range tf.gossipKeyset.Secondary {
+				argv = append(argv, "--gossip-secret-additional="+secret)
+			}
+			argv = append(argv, "--gossip-keyset")
+		}
 	} else {
 		switch cluster.Spec.GetCloudProvider() {
 		case kops.CloudProviderAWS:
@@ -673,6 +709,10 @@ func (tf *TemplateFunctions) KopsControllerConfig() (string, error) {
 		config.CacheNodeidentityInfo = true
 	}
 
+	if featureflag.KopsControllerMetrics.Enabled() {
+		config.MetricsBindAddress = fmt.Sprintf(":%d", wellknownports.KopsControllerMetrics)
+	}
+
 	{
 		certNames := []string{"kubelet", "kubelet-server"}
 		signingCAs := []string{fi.CertificateIDCA}
@@ -701,6 +741,22 @@ func (tf *TemplateFunctions) KopsControllerConfig() (string, error) {
 			config.Server.PKI = &pkibootstrap.Options{}
 		}
 
+		if tf.KopsModelContext.UsesInPlaceUpdates() {
+			config.Server.InPlaceUpdates = true
+		}
+		if cluster.Spec.NodeAudit != nil && fi.ValueOf(cluster.Spec.NodeAudit.Enabled) {
+			config.Server.NodeAudit = true
+		}
+		if tf.KopsModelContext.UsesVolumeEncryptionKeys() {
+			config.Server.VolumeEncryptionKeys = true
+		}
+		if cluster.Spec.Authentication != nil && cluster.Spec.Authentication.AdminCredentialDenyList {
+			config.Server.AdminCredentialDenyList = true
+		}
+		if cluster.Spec.NodeCertificateRenewal != nil && fi.ValueOf(cluster.Spec.NodeCertificateRenewal.Enabled) {
+			config.Server.CertificateRenewal = true
+		}
+
 		switch cluster.Spec.GetCloudProvider() {
 		case kops.CloudProviderAWS:
 			nodesRoles := sets.String{}
@@ -760,6 +816,12 @@ func (tf *TemplateFunctions) KopsControllerConfig() (string, error) {
 				ClusterName: tf.ClusterName(),
 			}
 
+		case kops.CloudProviderMetal:
+			// Bare-metal hosts are verified with the keys they were enrolled with
+			if !featureflag.Metal.Enabled() {
+				return "", fmt.Errorf("bare-metal support is feature-gated, export KOPS_FEATURE_FLAGS=Metal")
+			}
+
 		default:
 			return "", fmt.Errorf("unsupported cloud provider %s", cluster.Spec.GetCloudProvider())
 		}
@@ -769,7 +831,8 @@ func (tf *TemplateFunctions) KopsControllerConfig() (string, error) {
 		config.EnableCloudIPAM = true
 	}
 
-	if cluster.UsesLegacyGossip() {
+	// Bare-metal hosts reach the control plane without DNS through the same internal names as gossip clusters
+	if cluster.UsesLegacyGossip() || (featureflag.Metal.Enabled() && cluster.UsesNoneDNS()) {
 		config.Discovery = &kopscontrollerconfig.DiscoveryOptions{
 			Enabled: true,
 		}
@@ -823,7 +886,7 @@ func (tf *TemplateFunctions) ExternalDNSArgv() ([]string, error) {
 	argv = append(argv, "--source=service")
 	argv = append(argv, "--compatibility=kops-dns-controller")
 	argv = append(argv, "--registry=txt")
-	argv = append(argv, "--txt-owner-id=kops-"+tf.ClusterName())
+	argv = append(argv, "--txt-owner-id="+txtOwnerID(tf.Cluster))
 	argv = append(argv, "--zone-id-filter="+tf.Cluster.Spec.DNSZone)
 	if externalDNS.WatchNamespace != "" {
 		argv = append(argv, "--namespace="+externalDNS.WatchNamespace)
@@ -136,6 +136,51 @@ func idForTask[T SubContext](taskMap map[string]Task[T], t Task[T]) string {
 	return "?"
 }
 
+// DryRunChange is a change that would be made by a task.
+type DryRunChange[T SubContext] struct {
+	// Task is the task, with its expected state.
+	Task Task[T]
+	// Created is true if the task would create the resource.
+	Created bool
+	// Fields are the fields that would be modified, if the resource exists.
+	Fields []DryRunFieldChange
+}
+
+// DryRunFieldChange is a field that would be modified by a task.
+type DryRunFieldChange struct {
+	FieldName   string
+	Description string
+}
+
+// ChangeList returns the changes that would be made, in a consistent order, with the modified fields.
+func (t *DryRunTarget[T]) ChangeList() ([]DryRunChange[T], error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	renders := make([]*render[T], len(t.changes))
+	copy(renders, t.changes)
+	sort.Sort(ByTaskKey[T](renders))
+
+	var changes []DryRunChange[T]
+	for _, r := range renders {
+		c := DryRunChange[T]{
+			Task:    r.e,
+			Created: r.aIsNil,
+		}
+		if !r.aIsNil {
+			changeList, err := buildChangeList(r.a, r.e, r.changes)
+			if err != nil {
+				return nil, err
+			}
+			for _, change := range changeList {
+				c.Fields = append(c.Fields, DryRunFieldChange{FieldName: change.FieldName, Description: change.Description})
+			}
+		}
+		changes = append(changes, c)
+	}
+	return changes, nil
+}
+
 func (t *DryRunTarget[T]) PrintReport(taskMap map[string]Task[T], out io.Writer) error {
 	b := &bytes.Buffer{}
 
@@ -448,6 +493,15 @@ func (t *DryRunTarget[T]) Changes() (map[string]Task[T], map[string]Task[T]) {
 	return creates, updates
 }
 
+// ChangedTasks returns the tasks which are going to be created or updated
+func (t *DryRunTarget[T]) ChangedTasks() []Task[T] {
+	var tasks []Task[T]
+	for _, r := range t.changes {
+		tasks = append(tasks, r.e)
+	}
+	return tasks
+}
+
 // HasChanges returns true iff any changes would have been made
 func (t *DryRunTarget[T]) HasChanges() bool {
 	return len(t.changes)+len(t.deletions) != 0
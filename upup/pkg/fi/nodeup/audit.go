@@ -0,0 +1,309 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeup
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/klog/v2"
+	"k8s.io/kops/nodeup/pkg/model"
+	"k8s.io/kops/pkg/apis/nodeup"
+	"k8s.io/kops/pkg/assets"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/nodeup/nodetasks"
+	"k8s.io/kops/util/pkg/vfs"
+)
+
+// maxDriftMessageItems is the number of differences listed in the message of the Node condition.
+const maxDriftMessageItems = 5
+
+// runAudit runs the tasks against a dry-run target, and reports the differences between the node and its configuration.
+func (c *NodeUpCommand) runAudit(ctx context.Context, out io.Writer, modelContext *model.NodeupModelContext, keyStore fi.KeystoreReader, taskMap map[string]fi.NodeupTask) error {
+	pruneBootstrapTasks(taskMap)
+
+	// Certificates issued on the node are different on every run
+	generated := findDependentTasks(taskMap, func(task fi.NodeupTask) bool {
+		_, ok := task.(*nodetasks.IssueCert)
+		return ok
+	})
+
+	assetBuilder := assets.NewAssetBuilder(vfs.Context, nil, modelContext.NodeupConfig.KubernetesVersion, false)
+	target := fi.NewNodeupDryRunTarget(assetBuilder, io.Discard)
+
+	context, err := fi.NewNodeupContext(ctx, target, keyStore, modelContext.BootConfig, modelContext.NodeupConfig, taskMap)
+	if err != nil {
+		return fmt.Errorf("error building context: %w", err)
+	}
+
+	var options fi.RunTasksOptions
+	options.InitDefaults()
+
+	if err := context.RunTasks(options); err != nil {
+		return fmt.Errorf("error running tasks: %w", err)
+	}
+
+	changes, err := target.ChangeList()
+	if err != nil {
+		return err
+	}
+
+	nodeName, err := modelContext.NodeName()
+	if err != nil {
+		return err
+	}
+
+	report := &nodeup.NodeAuditReport{
+		NodeName:      nodeName,
+		InstanceGroup: modelContext.BootConfig.InstanceGroupName,
+		Time:          metav1.Now(),
+	}
+
+	keys := make(map[fi.NodeupTask]string)
+	for k, task := range taskMap {
+		keys[task] = k
+	}
+	for _, change := range changes {
+		if generated[keys[change.Task]] {
+			continue
+		}
+		if difference := auditDifference(change); difference != nil {
+			report.Differences = append(report.Differences, *difference)
+		}
+	}
+
+	sysctlDifferences, err := auditSysctls(taskMap)
+	if err != nil {
+		return err
+	}
+	report.Differences = append(report.Differences, sysctlDifferences...)
+
+	report.Drift = len(report.Differences) != 0
+
+	b, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error serializing audit report: %w", err)
+	}
+	b = append(b, '\n')
+
+	if c.AuditOutput == "" || c.AuditOutput == "-" {
+		if _, err := out.Write(b); err != nil {
+			return err
+		}
+	} else {
+		if err := os.WriteFile(c.AuditOutput, b, 0o600); err != nil {
+			return fmt.Errorf("error writing audit report: %w", err)
+		}
+	}
+
+	if report.Drift {
+		klog.Infof("node configuration has drifted: %d differences", len(report.Differences))
+	} else {
+		klog.Infof("node configuration matches the cluster spec")
+	}
+
+	if c.AuditUpdateNode {
+		if err := updateDriftCondition(ctx, modelContext.KubeletKubeConfig(), report); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// auditDifference converts a change to a difference, for the kinds of tasks we audit.
+func auditDifference(change fi.DryRunChange[fi.NodeupSubContext]) *nodeup.NodeAuditDifference {
+	difference := &nodeup.NodeAuditDifference{
+		Missing: change.Created,
+	}
+
+	private := false
+	switch task := change.Task.(type) {
+	case *nodetasks.File:
+		difference.Kind = nodeup.NodeAuditKindFile
+		difference.Name = task.Path
+		private = isPrivateFile(task)
+	case *nodetasks.Package:
+		difference.Kind = nodeup.NodeAuditKindPackage
+		difference.Name = task.Name
+	case *nodetasks.Service:
+		difference.Kind = nodeup.NodeAuditKindService
+		difference.Name = task.Name
+	default:
+		return nil
+	}
+
+	for _, field := range change.Fields {
+		description := field.Description
+		if private && field.FieldName == "Contents" {
+			// Don't leak secrets into the report
+			description = "contents differ"
+		}
+		difference.Fields = append(difference.Fields, nodeup.NodeAuditField{
+			Field:       field.FieldName,
+			Description: description,
+		})
+	}
+	return difference
+}
+
+// isPrivateFile returns true if the file isn't readable by everyone, and may contain secrets.
+func isPrivateFile(f *nodetasks.File) bool {
+	if f.Mode == nil {
+		return false
+	}
+	mode, err := strconv.ParseUint(*f.Mode, 8, 32)
+	if err != nil {
+		return true
+	}
+	return mode&0o004 == 0
+}
+
+// auditSysctls compares the kernel parameters of the node with the sysctl files nodeup writes.
+func auditSysctls(taskMap map[string]fi.NodeupTask) ([]nodeup.NodeAuditDifference, error) {
+	var files []*nodetasks.File
+	for _, task := range taskMap {
+		if f, ok := task.(*nodetasks.File); ok && strings.HasPrefix(f.Path, "/etc/sysctl.d/") && f.Contents != nil {
+			files = append(files, f)
+		}
+	}
+	// Later files take precedence, as with sysctl --system
+	sort.Slice(files, func(i, j int) bool {
+		return path.Base(files[i].Path) < path.Base(files[j].Path)
+	})
+
+	expected := make(map[string]string)
+	for _, f := range files {
+		contents, err := fi.ResourceAsString(f.Contents)
+		if err != nil {
+			return nil, fmt.Errorf("error reading contents of %q: %w", f.Path, err)
+		}
+		for _, line := range strings.Split(contents, "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+				continue
+			}
+			key, value, found := strings.Cut(line, "=")
+			if !found {
+				continue
+			}
+			key = strings.TrimPrefix(strings.TrimSpace(key), "-")
+			expected[key] = strings.Join(strings.Fields(value), " ")
+		}
+	}
+
+	var keys []string
+	for key := range expected {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var differences []nodeup.NodeAuditDifference
+	for _, key := range keys {
+		b, err := os.ReadFile(path.Join("/proc/sys", strings.ReplaceAll(key, ".", "/")))
+		if err != nil {
+			klog.V(2).Infof("unable to read sysctl %q: %v", key, err)
+			continue
+		}
+		actual := strings.Join(strings.Fields(string(b)), " ")
+		if actual != expected[key] {
+			differences = append(differences, nodeup.NodeAuditDifference{
+				Kind: nodeup.NodeAuditKindSysctl,
+				Name: key,
+				Fields: []nodeup.NodeAuditField{
+					{Field: "Value", Description: fmt.Sprintf(" %s -> %s", actual, expected[key])},
+				},
+			})
+		}
+	}
+	return differences, nil
+}
+
+// updateDriftCondition sets the KopsConfigurationDrift condition of the Node, with the credentials of kubelet.
+func updateDriftCondition(ctx context.Context, kubeconfig string, report *nodeup.NodeAuditReport) error {
+	config, err := clientcmd.BuildConfigFromFlags("", kubeconfig)
+	if err != nil {
+		return fmt.Errorf("error loading kubeconfig %q: %w", kubeconfig, err)
+	}
+	client, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return fmt.Errorf("error building kubernetes client: %w", err)
+	}
+
+	node, err := client.CoreV1().Nodes().Get(ctx, report.NodeName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("error getting node %q: %w", report.NodeName, err)
+	}
+
+	condition := corev1.NodeCondition{
+		Type:               nodeup.NodeConfigurationDriftCondition,
+		Status:             corev1.ConditionFalse,
+		Reason:             "NoDrift",
+		Message:            "node configuration matches the cluster spec",
+		LastHeartbeatTime:  report.Time,
+		LastTransitionTime: report.Time,
+	}
+	if report.Drift {
+		condition.Status = corev1.ConditionTrue
+		condition.Reason = "ConfigurationDrift"
+		condition.Message = driftMessage(report)
+	}
+	for _, existing := range node.Status.Conditions {
+		if existing.Type == condition.Type && existing.Status == condition.Status {
+			condition.LastTransitionTime = existing.LastTransitionTime
+		}
+	}
+
+	patch := map[string]interface{}{
+		"status": map[string]interface{}{
+			"conditions": []corev1.NodeCondition{condition},
+		},
+	}
+	patchBytes, err := json.Marshal(patch)
+	if err != nil {
+		return fmt.Errorf("error building patch: %w", err)
+	}
+
+	if _, err := client.CoreV1().Nodes().PatchStatus(ctx, report.NodeName, patchBytes); err != nil {
+		return fmt.Errorf("error updating condition of node %q: %w", report.NodeName, err)
+	}
+	return nil
+}
+
+// driftMessage summarizes the differences for the Node condition.
+func driftMessage(report *nodeup.NodeAuditReport) string {
+	var items []string
+	for i, difference := range report.Differences {
+		if i == maxDriftMessageItems {
+			items = append(items, fmt.Sprintf("and %d more", len(report.Differences)-maxDriftMessageItems))
+			break
+		}
+		items = append(items, string(difference.Kind)+" "+difference.Name)
+	}
+	return fmt.Sprintf("%d items differ from the cluster spec: %s", len(report.Differences), strings.Join(items, ", "))
+}
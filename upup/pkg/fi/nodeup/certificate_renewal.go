@@ -0,0 +1,286 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeup
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"k8s.io/klog/v2"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/apis/nodeup"
+	"k8s.io/kops/pkg/kopscontrollerclient"
+	"k8s.io/kops/pkg/kubeconfig"
+	"k8s.io/kops/pkg/pki"
+	"k8s.io/kops/upup/pkg/fi/utils"
+)
+
+// renewedCertificate is a certificate of the node being renewed.
+type renewedCertificate struct {
+	nodeup.RenewedCertificate
+
+	// kubeconfig is the parsed kubeconfig, for certificates embedded in a kubeconfig.
+	kubeconfig *kubeconfig.KubectlConfig
+	// user is the user of the kubeconfig holding the certificate.
+	user *kubeconfig.KubectlUser
+
+	cert []byte
+	key  []byte
+}
+
+// runCertificateRenewal renews the certificates of the node issued by kops-controller, if they expire soon.
+// The request is authenticated with the current kubelet client certificate, so it must be renewed before it expires.
+func (c *NodeUpCommand) runCertificateRenewal(ctx context.Context) error {
+	b, err := os.ReadFile(nodeup.CertificateRenewalConfigPath)
+	if err != nil {
+		return fmt.Errorf("error reading certificate renewal config: %w", err)
+	}
+	config := &nodeup.CertificateRenewalConfig{}
+	if err := utils.YamlUnmarshal(b, config); err != nil {
+		return fmt.Errorf("error parsing certificate renewal config %q: %w", nodeup.CertificateRenewalConfigPath, err)
+	}
+
+	var certs []*renewedCertificate
+	for _, spec := range config.Certificates {
+		cert, err := loadRenewedCertificate(spec)
+		if err != nil {
+			return err
+		}
+		certs = append(certs, cert)
+	}
+
+	var kubelet *renewedCertificate
+	for _, cert := range certs {
+		if cert.Name == "kubelet" {
+			kubelet = cert
+		}
+	}
+	if kubelet == nil || kubelet.kubeconfig == nil {
+		return fmt.Errorf("no kubelet kubeconfig in certificate renewal config")
+	}
+
+	now := time.Now()
+	var due []*renewedCertificate
+	for _, cert := range certs {
+		certificate, err := pki.ParsePEMCertificate(cert.cert)
+		if err != nil {
+			return fmt.Errorf("error parsing %q certificate: %w", cert.Name, err)
+		}
+		notAfter := certificate.Certificate.NotAfter
+		if notAfter.Sub(now) > config.RenewBefore.Duration {
+			klog.V(2).Infof("certificate %q expires at %s, not renewing", cert.Name, notAfter.UTC().Format(time.RFC3339))
+			continue
+		}
+		klog.Infof("certificate %q expires at %s, renewing", cert.Name, notAfter.UTC().Format(time.RFC3339))
+		due = append(due, cert)
+	}
+	if len(due) == 0 {
+		klog.Infof("no certificate to renew")
+		return nil
+	}
+
+	clientCert, err := tls.X509KeyPair(kubelet.cert, kubelet.key)
+	if err != nil {
+		return fmt.Errorf("error loading kubelet client certificate: %w", err)
+	}
+	var cas []byte
+	for _, cluster := range kubelet.kubeconfig.Clusters {
+		cas = append(cas, cluster.Cluster.CertificateAuthorityData...)
+	}
+	serverURL, err := url.Parse(kopsControllerServer(config.ClusterName))
+	if err != nil {
+		return err
+	}
+	client := &kopscontrollerclient.Client{
+		Certificate: &clientCert,
+		CAs:         cas,
+		BaseURL:     *serverURL,
+	}
+
+	req := &nodeup.CertificateRenewalRequest{
+		APIVersion:   nodeup.BootstrapAPIVersion,
+		Certs:        map[string]string{},
+		CurrentCerts: map[string]string{},
+	}
+	keys := map[string]*pki.PrivateKey{}
+	for _, cert := range due {
+		key, err := pki.GeneratePrivateKey()
+		if err != nil {
+			return fmt.Errorf("generating private key: %w", err)
+		}
+		keys[cert.Name] = key
+
+		pkData, err := x509.MarshalPKIXPublicKey(key.Key.Public())
+		if err != nil {
+			return fmt.Errorf("marshalling public key: %w", err)
+		}
+		req.Certs[cert.Name] = string(pem.EncodeToMemory(&pem.Block{Type: "RSA PUBLIC KEY", Bytes: pkData}))
+		if cert.Name == "kubelet-server" {
+			req.CurrentCerts[cert.Name] = string(cert.cert)
+		}
+	}
+
+	resp := &nodeup.CertificateRenewalResponse{}
+	if err := client.RenewCertificates(ctx, req, resp); err != nil {
+		return fmt.Errorf("error renewing certificates: %w", err)
+	}
+
+	var services, containers []string
+	for _, cert := range due {
+		certString, ok := resp.Certs[cert.Name]
+		if !ok {
+			return fmt.Errorf("kops-controller did not return a %q certificate", cert.Name)
+		}
+		if _, err := pki.ParsePEMCertificate([]byte(certString)); err != nil {
+			return fmt.Errorf("parsing %q certificate: %w", cert.Name, err)
+		}
+		keyBytes, err := keys[cert.Name].AsBytes()
+		if err != nil {
+			return err
+		}
+
+		if err := cert.write([]byte(certString), keyBytes); err != nil {
+			return err
+		}
+		klog.Infof("renewed certificate %q", cert.Name)
+
+		for _, service := range cert.Services {
+			if !slices.Contains(services, service) {
+				services = append(services, service)
+			}
+		}
+		for _, container := range cert.Containers {
+			if !slices.Contains(containers, container) {
+				containers = append(containers, container)
+			}
+		}
+	}
+
+	if err := restartServices(services); err != nil {
+		return err
+	}
+	return stopContainers(config.Crictl, containers)
+}
+
+// loadRenewedCertificate reads the current certificate and key.
+func loadRenewedCertificate(spec nodeup.RenewedCertificate) (*renewedCertificate, error) {
+	cert := &renewedCertificate{RenewedCertificate: spec}
+	if spec.Kubeconfig != "" {
+		b, err := os.ReadFile(spec.Kubeconfig)
+		if err != nil {
+			return nil, fmt.Errorf("error reading kubeconfig for %q: %w", spec.Name, err)
+		}
+		cert.kubeconfig = &kubeconfig.KubectlConfig{}
+		if err := utils.YamlUnmarshal(b, cert.kubeconfig); err != nil {
+			return nil, fmt.Errorf("error parsing kubeconfig %q: %w", spec.Kubeconfig, err)
+		}
+		if len(cert.kubeconfig.Users) != 1 {
+			return nil, fmt.Errorf("expected a single user in kubeconfig %q, found %d", spec.Kubeconfig, len(cert.kubeconfig.Users))
+		}
+		cert.user = &cert.kubeconfig.Users[0].User
+		cert.cert = cert.user.ClientCertificateData
+		cert.key = cert.user.ClientKeyData
+		return cert, nil
+	}
+
+	var err error
+	cert.cert, err = os.ReadFile(spec.CertPath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %q certificate: %w", spec.Name, err)
+	}
+	cert.key, err = os.ReadFile(spec.KeyPath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %q key: %w", spec.Name, err)
+	}
+	return cert, nil
+}
+
+// write replaces the certificate and its key on disk.
+func (c *renewedCertificate) write(cert, key []byte) error {
+	if c.kubeconfig != nil {
+		c.user.ClientCertificateData = cert
+		c.user.ClientKeyData = key
+		b, err := kops.ToRawYaml(c.kubeconfig)
+		if err != nil {
+			return fmt.Errorf("error marshaling kubeconfig to yaml: %w", err)
+		}
+		return replaceFile(c.Kubeconfig, b)
+	}
+
+	// Write the key first: a service restarting between both writes fails to load a mismatched pair, and is restarted again
+	if err := replaceFile(c.KeyPath, key); err != nil {
+		return err
+	}
+	return replaceFile(c.CertPath, cert)
+}
+
+// replaceFile atomically replaces the contents of a file, keeping its mode.
+func replaceFile(p string, contents []byte) error {
+	stat, err := os.Stat(p)
+	if err != nil {
+		return fmt.Errorf("error getting info for %q: %w", p, err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(p), "."+filepath.Base(p)+".*")
+	if err != nil {
+		return fmt.Errorf("error creating temp file for %q: %w", p, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(contents); err != nil {
+		tmp.Close()
+		return fmt.Errorf("error writing %q: %w", tmp.Name(), err)
+	}
+	if err := tmp.Chmod(stat.Mode().Perm()); err != nil {
+		tmp.Close()
+		return fmt.Errorf("error setting mode of %q: %w", tmp.Name(), err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("error closing %q: %w", tmp.Name(), err)
+	}
+	if err := os.Rename(tmp.Name(), p); err != nil {
+		return fmt.Errorf("error replacing %q: %w", p, err)
+	}
+	return nil
+}
+
+// stopContainers stops the running containers with the given names, for the kubelet to restart them with the renewed certificates.
+func stopContainers(crictl string, names []string) error {
+	for _, name := range names {
+		output, err := exec.Command(crictl, "ps", "--quiet", "--name", "^"+name+"$").CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("error listing %q containers: %v\nOutput: %s", name, err, output)
+		}
+		for _, id := range strings.Fields(string(output)) {
+			klog.Infof("stopping container %q (%s)", name, id)
+			if output, err := exec.Command(crictl, "stop", id).CombinedOutput(); err != nil {
+				return fmt.Errorf("error stopping container %q: %v\nOutput: %s", id, err, output)
+			}
+		}
+	}
+	return nil
+}
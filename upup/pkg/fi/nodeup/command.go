@@ -66,6 +66,7 @@ import (
 	"k8s.io/kops/upup/pkg/fi/utils"
 	"k8s.io/kops/util/pkg/architectures"
 	"k8s.io/kops/util/pkg/distributions"
+	"k8s.io/kops/util/pkg/hashing"
 	"k8s.io/kops/util/pkg/vfs"
 )
 
@@ -77,6 +78,16 @@ type NodeUpCommand struct {
 	CacheDir       string
 	ConfigLocation string
 	Target         string
+	// InPlaceUpdate applies configuration changes to a running node, draining it if needed.
+	InPlaceUpdate bool
+	// Audit reports the differences between the node and its configuration, without changing the node.
+	Audit bool
+	// AuditOutput is the file the audit report is written to; "-" writes it to the output.
+	AuditOutput string
+	// AuditUpdateNode sets the KopsConfigurationDrift condition of the Node from the audit report.
+	AuditUpdateNode bool
+	// RenewCertificates renews the certificates issued by kops-controller that expire soon, instead of configuring the node.
+	RenewCertificates bool
 }
 
 // Run is responsible for perform the nodeup process
@@ -102,6 +113,19 @@ func (c *NodeUpCommand) Run(out io.Writer) error {
 		return fmt.Errorf("CacheDir is required")
 	}
 
+	if c.InPlaceUpdate && c.Target != "direct" {
+		return fmt.Errorf("in-place updates require the direct target")
+	}
+	if c.InPlaceUpdate && c.Audit {
+		return fmt.Errorf("cannot audit and update the node at the same time")
+	}
+	if c.RenewCertificates {
+		if c.InPlaceUpdate || c.Audit {
+			return fmt.Errorf("cannot renew certificates while auditing or updating the node")
+		}
+		return c.runCertificateRenewal(ctx)
+	}
+
 	region, err := getRegion(ctx, &bootConfig)
 	if err != nil {
 		return err
@@ -115,7 +139,22 @@ func (c *NodeUpCommand) Run(out io.Writer) error {
 	// If we're using a config server instead of vfs, nodeConfig will hold our configuration
 	var nodeConfig *nodeup.NodeConfig
 
-	if bootConfig.ConfigServer != nil && len(bootConfig.ConfigServer.Servers) > 0 {
+	// If we're updating in place, updater coordinates the update with kops-controller.
+	// Running nodes are not bootstrapped again, so it also serves their configuration for audits.
+	var updater *inPlaceUpdater
+
+	if bootConfig.ConfigServer != nil && len(bootConfig.ConfigServer.Servers) > 0 && (c.InPlaceUpdate || c.Audit) {
+		updater, err = newInPlaceUpdater(ctx, &bootConfig, region, bootConfig.ConfigServer.Servers, bootConfig.ConfigServer.CACertificates)
+		if err != nil {
+			return err
+		}
+		defer updater.Stop()
+
+		if err := updater.getConfig(ctx, true); err != nil {
+			return err
+		}
+		nodeConfig = updater.nodeConfig
+	} else if bootConfig.ConfigServer != nil && len(bootConfig.ConfigServer.Servers) > 0 {
 		response, err := getNodeConfigFromServers(ctx, &bootConfig, region)
 		if err != nil {
 			return fmt.Errorf("failed to get node config from server: %w", err)
@@ -155,7 +194,40 @@ func (c *NodeUpCommand) Run(out io.Writer) error {
 		return fmt.Errorf("no instance group defined in nodeup config")
 	}
 
-	if bootConfig.NodeupConfigHash != "" {
+	if c.InPlaceUpdate {
+		if !nodeupConfig.InPlaceUpdates {
+			klog.Infof("in-place updates are not enabled for instance group %q", bootConfig.InstanceGroupName)
+			return nil
+		}
+
+		if updater == nil {
+			updater, err = newInPlaceUpdater(ctx, &bootConfig, region, []string{kopsControllerServer(bootConfig.ClusterName)}, nodeupConfig.CAs[fi.CertificateIDCA])
+			if err != nil {
+				return err
+			}
+			defer updater.Stop()
+
+			if err := updater.getConfig(ctx, false); err != nil {
+				return err
+			}
+		}
+
+		// The hash from kops-controller ensures we read the same configuration that it serves
+		if want, got := updater.nodeupConfigHash, base64.StdEncoding.EncodeToString(nodeupConfigHash[:]); got != want {
+			return fmt.Errorf("nodeup config hash mismatch (was %q, expected %q)", got, want)
+		}
+
+		applied, err := readAppliedConfigHash(c.CacheDir)
+		if err != nil {
+			return err
+		}
+		if applied == updater.nodeupConfigHash {
+			klog.Infof("node configuration is up to date")
+			return nil
+		}
+		klog.Infof("updating node configuration in place (from %q to %q)", applied, updater.nodeupConfigHash)
+	} else if bootConfig.NodeupConfigHash != "" && !c.Audit {
+		// The audit compares the node with the current configuration, even if the instance should be replaced
 		if want, got := bootConfig.NodeupConfigHash, base64.StdEncoding.EncodeToString(nodeupConfigHash[:]); got != want {
 			return fmt.Errorf("nodeup config hash mismatch (was %q, expected %q)", got, want)
 		}
@@ -178,6 +250,15 @@ func (c *NodeUpCommand) Run(out io.Writer) error {
 
 	configAssets := nodeupConfig.Assets[architecture]
 	assetStore := fi.NewAssetStore(c.CacheDir)
+	if len(nodeupConfig.AssetSignaturePublicKeys) != 0 {
+		verifier, err := assets.NewSignatureVerifier(nodeupConfig.AssetSignaturePublicKeys)
+		if err != nil {
+			return fmt.Errorf("error building asset signature verifier: %v", err)
+		}
+		assetStore.SetSignatureVerifier(func(url string, hash *hashing.Hash) error {
+			return verifier.VerifyFile(vfs.Context, url, hash)
+		})
+	}
 	for _, asset := range configAssets {
 		err := assetStore.Add(asset)
 		if err != nil {
@@ -284,8 +365,10 @@ func (c *NodeUpCommand) Run(out io.Writer) error {
 		}
 	}
 
-	if err := loadKernelModules(modelContext); err != nil {
-		return err
+	if !c.Audit {
+		if err := loadKernelModules(modelContext); err != nil {
+			return err
+		}
 	}
 
 	loader := &Loader{}
@@ -299,6 +382,9 @@ func (c *NodeUpCommand) Run(out io.Writer) error {
 	loader.Builders = append(loader.Builders, &model.CloudConfigBuilder{NodeupModelContext: modelContext})
 	loader.Builders = append(loader.Builders, &model.FileAssetsBuilder{NodeupModelContext: modelContext})
 	loader.Builders = append(loader.Builders, &model.HookBuilder{NodeupModelContext: modelContext})
+	loader.Builders = append(loader.Builders, &model.InPlaceUpdateBuilder{NodeupModelContext: modelContext})
+	loader.Builders = append(loader.Builders, &model.NodeAuditBuilder{NodeupModelContext: modelContext})
+	loader.Builders = append(loader.Builders, &model.CertificateRenewalBuilder{NodeupModelContext: modelContext})
 	loader.Builders = append(loader.Builders, &model.KubeletBuilder{NodeupModelContext: modelContext})
 	loader.Builders = append(loader.Builders, &model.KubectlBuilder{NodeupModelContext: modelContext})
 	loader.Builders = append(loader.Builders, &model.LogrotateBuilder{NodeupModelContext: modelContext})
@@ -308,13 +394,16 @@ func (c *NodeUpCommand) Run(out io.Writer) error {
 	loader.Builders = append(loader.Builders, &model.SecretBuilder{NodeupModelContext: modelContext})
 	loader.Builders = append(loader.Builders, &model.FirewallBuilder{NodeupModelContext: modelContext})
 	loader.Builders = append(loader.Builders, &model.SysctlBuilder{NodeupModelContext: modelContext})
+	loader.Builders = append(loader.Builders, &model.KernelBuilder{NodeupModelContext: modelContext})
 	loader.Builders = append(loader.Builders, &model.KubeAPIServerBuilder{NodeupModelContext: modelContext})
 	loader.Builders = append(loader.Builders, &model.KubeControllerManagerBuilder{NodeupModelContext: modelContext})
 	loader.Builders = append(loader.Builders, &model.KubeSchedulerBuilder{NodeupModelContext: modelContext})
 	loader.Builders = append(loader.Builders, &model.EtcdManagerTLSBuilder{NodeupModelContext: modelContext})
+	loader.Builders = append(loader.Builders, &model.EtcdManagerVolumesBuilder{NodeupModelContext: modelContext})
 	loader.Builders = append(loader.Builders, &model.KubeProxyBuilder{NodeupModelContext: modelContext})
 	loader.Builders = append(loader.Builders, &model.KopsControllerBuilder{NodeupModelContext: modelContext})
 	loader.Builders = append(loader.Builders, &model.WarmPoolBuilder{NodeupModelContext: modelContext})
+	loader.Builders = append(loader.Builders, &model.PrepulledImagesBuilder{NodeupModelContext: modelContext})
 	loader.Builders = append(loader.Builders, &model.PrefixBuilder{NodeupModelContext: modelContext})
 	loader.Builders = append(loader.Builders, &model.NerdctlBuilder{NodeupModelContext: modelContext})
 	loader.Builders = append(loader.Builders, &model.CrictlBuilder{NodeupModelContext: modelContext})
@@ -339,6 +428,17 @@ func (c *NodeUpCommand) Run(out io.Writer) error {
 	}
 	// Protokube load image task is in ProtokubeBuilder
 
+	if c.Audit {
+		return c.runAudit(ctx, out, modelContext, keyStore, taskMap)
+	}
+
+	if updater != nil {
+		if err := c.runInPlaceUpdate(ctx, updater, keyStore, &bootConfig, &nodeupConfig, cloud, taskMap); err != nil {
+			return err
+		}
+		return nil
+	}
+
 	var target fi.NodeupTarget
 
 	switch c.Target {
@@ -372,6 +472,12 @@ func (c *NodeUpCommand) Run(out io.Writer) error {
 		klog.Exitf("error closing target: %v", err)
 	}
 
+	if c.Target == "direct" && nodeupConfig.InPlaceUpdates {
+		if err := writeAppliedConfigHash(c.CacheDir, base64.StdEncoding.EncodeToString(nodeupConfigHash[:])); err != nil {
+			return err
+		}
+	}
+
 	if nodeupConfig.EnableLifecycleHook {
 		if bootConfig.CloudProvider == api.CloudProviderAWS {
 			err := completeWarmingLifecycleAction(ctx, cloud.(awsup.AWSCloud), modelContext)
@@ -380,6 +486,15 @@ func (c *NodeUpCommand) Run(out io.Writer) error {
 			}
 		}
 	}
+
+	if c.Target == "direct" {
+		if _, err := os.Stat(model.RebootRequiredPath); err == nil {
+			klog.Infof("kernel configuration changed, rebooting node")
+			if err := exec.Command("systemctl", "reboot").Run(); err != nil {
+				return fmt.Errorf("error rebooting node: %w", err)
+			}
+		}
+	}
 	return nil
 }
 
@@ -619,8 +734,8 @@ func seedRNG(ctx context.Context, bootConfig *nodeup.BootConfig, region string)
 	return nil
 }
 
-// getNodeConfigFromServers queries kops-controllers for our node's configuration.
-func getNodeConfigFromServers(ctx context.Context, bootConfig *nodeup.BootConfig, region string) (*nodeup.BootstrapResponse, error) {
+// newAuthenticator builds the authenticator used by the node for its requests to kops-controller.
+func newAuthenticator(ctx context.Context, bootConfig *nodeup.BootConfig, region string) (bootstrap.Authenticator, error) {
 	var authenticator bootstrap.Authenticator
 
 	switch bootConfig.CloudProvider {
@@ -667,7 +782,7 @@ func getNodeConfigFromServers(ctx context.Context, bootConfig *nodeup.BootConfig
 		}
 		authenticator = a
 
-	case "metal":
+	case api.CloudProviderMetal:
 		a, err := pkibootstrap.NewAuthenticatorFromFile("/etc/kubernetes/kops/pki/machine/private.pem")
 		if err != nil {
 			return nil, err
@@ -678,6 +793,16 @@ func getNodeConfigFromServers(ctx context.Context, bootConfig *nodeup.BootConfig
 		return nil, fmt.Errorf("unsupported cloud provider for node configuration %s", bootConfig.CloudProvider)
 	}
 
+	return authenticator, nil
+}
+
+// getNodeConfigFromServers queries kops-controllers for our node's configuration.
+func getNodeConfigFromServers(ctx context.Context, bootConfig *nodeup.BootConfig, region string) (*nodeup.BootstrapResponse, error) {
+	authenticator, err := newAuthenticator(ctx, bootConfig, region)
+	if err != nil {
+		return nil, err
+	}
+
 	var challengeListener *bootstrap.ChallengeListener
 
 	if kopsmodel.UseChallengeCallback(bootConfig.CloudProvider) {
@@ -0,0 +1,433 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeup
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/multierr"
+	"k8s.io/klog/v2"
+	"k8s.io/kops/nodeup/pkg/model"
+	kopsmodel "k8s.io/kops/pkg/apis/kops/model"
+	"k8s.io/kops/pkg/apis/nodeup"
+	"k8s.io/kops/pkg/assets"
+	"k8s.io/kops/pkg/bootstrap"
+	"k8s.io/kops/pkg/kopscontrollerclient"
+	"k8s.io/kops/pkg/wellknownports"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/nodeup/local"
+	"k8s.io/kops/upup/pkg/fi/nodeup/nodetasks"
+	"k8s.io/kops/util/pkg/vfs"
+)
+
+const (
+	// appliedConfigHashFile records the hash of the last nodeup.Config applied on the node, in the cache directory.
+	appliedConfigHashFile = "nodeupconfig.hash"
+
+	// drainTimeout is how long we wait for kops-controller to drain the node.
+	drainTimeout = 15 * time.Minute
+	// drainPollInterval is how often we ask kops-controller if the node is drained.
+	drainPollInterval = 10 * time.Second
+)
+
+// restartedServices are the services that are restarted, in order, when the files they depend on change.
+// The node is drained before they are restarted.
+var restartedServices = []struct {
+	name  string
+	paths []string
+}{
+	{
+		name:  "containerd.service",
+		paths: []string{"/etc/containerd/", "/etc/sysconfig/containerd", "/usr/bin/containerd", "/usr/bin/ctr", "/usr/sbin/runc", "/usr/local/bin/containerd", "/usr/local/bin/ctr", "/usr/local/sbin/runc"},
+	},
+	{
+		name:  "kubelet.service",
+		paths: []string{"/var/lib/kubelet/", "/etc/sysconfig/kubelet", "/usr/local/bin/kubelet", "/opt/kubernetes/bin/kubelet", "/home/kubernetes/bin/kubelet"},
+	},
+}
+
+// inPlaceUpdater applies a new configuration on a running node, coordinating with kops-controller.
+type inPlaceUpdater struct {
+	client            *kopscontrollerclient.Client
+	servers           []url.URL
+	challengeListener *bootstrap.ChallengeListener
+
+	// nodeupConfigHash is the hash of the current configuration of the node, from kops-controller.
+	nodeupConfigHash string
+	// nodeConfig is the current configuration, for nodes without access to the state store.
+	nodeConfig *nodeup.NodeConfig
+
+	// drained is true once kops-controller was asked to drain the node.
+	drained bool
+}
+
+// newInPlaceUpdater builds an inPlaceUpdater that talks to the given kops-controller servers.
+func newInPlaceUpdater(ctx context.Context, bootConfig *nodeup.BootConfig, region string, servers []string, caCertificates string) (*inPlaceUpdater, error) {
+	authenticator, err := newAuthenticator(ctx, bootConfig, region)
+	if err != nil {
+		return nil, err
+	}
+
+	u := &inPlaceUpdater{
+		client: &kopscontrollerclient.Client{
+			Authenticator: authenticator,
+			CAs:           []byte(caCertificates),
+		},
+	}
+
+	for _, server := range servers {
+		serverURL, err := url.Parse(server)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse configuration server url %q: %w", server, err)
+		}
+		u.servers = append(u.servers, *serverURL)
+	}
+
+	if kopsmodel.UseChallengeCallback(bootConfig.CloudProvider) {
+		challengeServer, err := bootstrap.NewChallengeServer(bootConfig.ClusterName, []byte(caCertificates))
+		if err != nil {
+			return nil, err
+		}
+		listen := ":" + strconv.Itoa(wellknownports.NodeupChallenge)
+
+		l, err := challengeServer.NewListener(ctx, listen)
+		if err != nil {
+			return nil, fmt.Errorf("error starting challenge listener: %w", err)
+		}
+		u.challengeListener = l
+	}
+
+	return u, nil
+}
+
+// kopsControllerServer returns the address of kops-controller, for nodes that don't use it as their configuration server.
+func kopsControllerServer(clusterName string) string {
+	serverURL := url.URL{
+		Scheme: "https",
+		Host:   net.JoinHostPort("kops-controller.internal."+clusterName, strconv.Itoa(wellknownports.KopsControllerPort)),
+		Path:   "/",
+	}
+	return serverURL.String()
+}
+
+// Stop releases the resources of the updater.
+func (u *inPlaceUpdater) Stop() {
+	if u.challengeListener != nil {
+		u.challengeListener.Stop()
+	}
+}
+
+func (u *inPlaceUpdater) query(ctx context.Context, request *nodeup.NodeUpdateRequest, resp *nodeup.NodeUpdateResponse) error {
+	request.APIVersion = nodeup.BootstrapAPIVersion
+
+	var merr error
+	for _, server := range u.servers {
+		u.client.BaseURL = server
+
+		if u.challengeListener != nil {
+			request.Challenge = u.challengeListener.CreateChallenge()
+		}
+
+		if err := u.client.UpdateNode(ctx, request, resp); err != nil {
+			merr = multierr.Append(merr, err)
+			continue
+		}
+		return nil
+	}
+	return merr
+}
+
+// getConfig gets the hash of the current configuration from kops-controller, and the configuration itself if includeNodeConfig is set.
+func (u *inPlaceUpdater) getConfig(ctx context.Context, includeNodeConfig bool) error {
+	request := &nodeup.NodeUpdateRequest{
+		Action:            nodeup.NodeUpdateActionGetConfig,
+		IncludeNodeConfig: includeNodeConfig,
+	}
+
+	var resp nodeup.NodeUpdateResponse
+	if err := u.query(ctx, request, &resp); err != nil {
+		return fmt.Errorf("failed to get node config from kops-controller: %w", err)
+	}
+	if resp.NodeupConfigHash == "" {
+		return fmt.Errorf("kops-controller did not return the node config hash")
+	}
+	if includeNodeConfig && resp.NodeConfig == nil {
+		return fmt.Errorf("kops-controller did not return the node config")
+	}
+
+	u.nodeupConfigHash = resp.NodeupConfigHash
+	u.nodeConfig = resp.NodeConfig
+	return nil
+}
+
+// drain asks kops-controller to drain the node, until no pods are left to evict.
+func (u *inPlaceUpdater) drain(ctx context.Context) error {
+	klog.Infof("draining node")
+	u.drained = true
+
+	deadline := time.Now().Add(drainTimeout)
+	for {
+		request := &nodeup.NodeUpdateRequest{
+			Action: nodeup.NodeUpdateActionDrain,
+		}
+
+		var resp nodeup.NodeUpdateResponse
+		err := u.query(ctx, request, &resp)
+		if err == nil && resp.Drained {
+			klog.Infof("node is drained")
+			return nil
+		}
+		if err != nil {
+			klog.Warningf("error draining node: %v", err)
+		}
+
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timed out draining node")
+		}
+		time.Sleep(drainPollInterval)
+	}
+}
+
+// report records the result of the update on the Node object.
+func (u *inPlaceUpdater) report(ctx context.Context, updateErr error, uncordon bool) error {
+	status := &nodeup.NodeUpdateStatus{
+		NodeupConfigHash: u.nodeupConfigHash,
+		Succeeded:        updateErr == nil,
+		Uncordon:         uncordon && u.drained,
+	}
+	if updateErr != nil {
+		status.Message = updateErr.Error()
+	} else if _, err := os.Stat(model.RebootRequiredPath); err == nil {
+		// The kernel configuration is applied when the instance is replaced by the rolling update
+		status.Message = "node configuration updated, a reboot is required to apply the kernel configuration"
+	} else {
+		status.Message = "node configuration updated"
+	}
+
+	request := &nodeup.NodeUpdateRequest{
+		Action: nodeup.NodeUpdateActionReport,
+		Status: status,
+	}
+
+	var resp nodeup.NodeUpdateResponse
+	if err := u.query(ctx, request, &resp); err != nil {
+		return fmt.Errorf("failed to report node update to kops-controller: %w", err)
+	}
+	return nil
+}
+
+// runInPlaceUpdate applies the tasks on a running node, draining the node first if kubelet or containerd must be restarted.
+func (c *NodeUpCommand) runInPlaceUpdate(ctx context.Context, updater *inPlaceUpdater, keyStore fi.KeystoreReader, bootConfig *nodeup.BootConfig, nodeupConfig *nodeup.Config, cloud fi.Cloud, taskMap map[string]fi.NodeupTask) error {
+	pruneBootstrapTasks(taskMap)
+
+	services, err := updater.findRestartedServices(ctx, keyStore, bootConfig, nodeupConfig, taskMap)
+	if err != nil {
+		return c.reportInPlaceUpdate(ctx, updater, err, true)
+	}
+
+	if len(services) > 0 {
+		klog.Infof("update restarts %v", services)
+		if err := updater.drain(ctx); err != nil {
+			return c.reportInPlaceUpdate(ctx, updater, err, true)
+		}
+	}
+
+	target := &local.LocalTarget{
+		CacheDir: c.CacheDir,
+		Cloud:    cloud,
+	}
+
+	context, err := fi.NewNodeupContext(ctx, target, keyStore, bootConfig, nodeupConfig, taskMap)
+	if err != nil {
+		return c.reportInPlaceUpdate(ctx, updater, fmt.Errorf("error building context: %w", err), false)
+	}
+
+	var options fi.RunTasksOptions
+	options.InitDefaults()
+
+	if err := context.RunTasks(options); err != nil {
+		return c.reportInPlaceUpdate(ctx, updater, fmt.Errorf("error running tasks: %w", err), false)
+	}
+	if err := target.Finish(taskMap); err != nil {
+		return c.reportInPlaceUpdate(ctx, updater, fmt.Errorf("error closing target: %w", err), false)
+	}
+
+	if err := restartServices(services); err != nil {
+		return c.reportInPlaceUpdate(ctx, updater, err, false)
+	}
+
+	if err := writeAppliedConfigHash(c.CacheDir, updater.nodeupConfigHash); err != nil {
+		return c.reportInPlaceUpdate(ctx, updater, err, false)
+	}
+
+	return c.reportInPlaceUpdate(ctx, updater, nil, true)
+}
+
+// reportInPlaceUpdate reports the result of the update, returning the update error if any.
+// The node is only uncordoned if uncordon is set; a node that failed half-way through the update stays cordoned.
+func (c *NodeUpCommand) reportInPlaceUpdate(ctx context.Context, updater *inPlaceUpdater, updateErr error, uncordon bool) error {
+	if err := updater.report(ctx, updateErr, uncordon); err != nil {
+		if updateErr != nil {
+			klog.Warningf("%v", err)
+			return updateErr
+		}
+		return err
+	}
+	return updateErr
+}
+
+// findRestartedServices runs the tasks against a dry-run target, and returns the services whose files would change.
+func (u *inPlaceUpdater) findRestartedServices(ctx context.Context, keyStore fi.KeystoreReader, bootConfig *nodeup.BootConfig, nodeupConfig *nodeup.Config, taskMap map[string]fi.NodeupTask) ([]string, error) {
+	assetBuilder := assets.NewAssetBuilder(vfs.Context, nil, nodeupConfig.KubernetesVersion, false)
+	target := fi.NewNodeupDryRunTarget(assetBuilder, io.Discard)
+
+	context, err := fi.NewNodeupContext(ctx, target, keyStore, bootConfig, nodeupConfig, taskMap)
+	if err != nil {
+		return nil, fmt.Errorf("error building context: %w", err)
+	}
+
+	var options fi.RunTasksOptions
+	options.InitDefaults()
+
+	if err := context.RunTasks(options); err != nil {
+		return nil, fmt.Errorf("error finding changes: %w", err)
+	}
+
+	return findRestartedServices(target.ChangedTasks()), nil
+}
+
+// findRestartedServices returns the services that are restarted by the changed tasks.
+func findRestartedServices(changed []fi.NodeupTask) []string {
+	var services []string
+	for _, service := range restartedServices {
+		for _, task := range changed {
+			if changesService(task, service.name, service.paths) {
+				services = append(services, service.name)
+				break
+			}
+		}
+	}
+	return services
+}
+
+func changesService(task fi.NodeupTask, name string, paths []string) bool {
+	switch task := task.(type) {
+	case *nodetasks.Service:
+		return task.Name == name
+	case *nodetasks.File:
+		for _, p := range paths {
+			if strings.HasPrefix(task.Path, p) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// restartServices restarts the services, in order.
+func restartServices(services []string) error {
+	for _, service := range services {
+		klog.Infof("restarting service %q", service)
+		cmd := exec.Command("systemctl", "restart", service)
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("error restarting service %q: %v\nOutput: %s", service, err, output)
+		}
+	}
+	return nil
+}
+
+// pruneBootstrapTasks removes the tasks that request certificates from kops-controller, and the tasks that use them.
+// kops-controller only bootstraps nodes that are not registered yet, so running nodes keep their certificates.
+func pruneBootstrapTasks(taskMap map[string]fi.NodeupTask) {
+	pruned := findDependentTasks(taskMap, func(task fi.NodeupTask) bool {
+		_, ok := task.(*nodetasks.BootstrapClientTask)
+		return ok
+	})
+
+	for k := range pruned {
+		klog.V(2).Infof("skipping bootstrap task %q", k)
+		delete(taskMap, k)
+	}
+}
+
+// findDependentTasks returns the keys of the matching tasks, and of the tasks that depend on them.
+func findDependentTasks(taskMap map[string]fi.NodeupTask, match func(task fi.NodeupTask) bool) map[string]bool {
+	edges := fi.FindTaskDependencies(taskMap)
+
+	found := make(map[string]bool)
+	for k, task := range taskMap {
+		if match(task) {
+			found[k] = true
+		}
+	}
+
+	for changed := true; changed; {
+		changed = false
+		for k, dependencies := range edges {
+			if found[k] {
+				continue
+			}
+			// Services only depend on the files for ordering
+			if _, ok := taskMap[k].(*nodetasks.Service); ok {
+				continue
+			}
+			for _, dependency := range dependencies {
+				if found[dependency] {
+					found[k] = true
+					changed = true
+					break
+				}
+			}
+		}
+	}
+
+	return found
+}
+
+// readAppliedConfigHash returns the hash of the last configuration applied on the node, if known.
+func readAppliedConfigHash(cacheDir string) (string, error) {
+	b, err := os.ReadFile(filepath.Join(cacheDir, appliedConfigHashFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", fmt.Errorf("error reading applied config hash: %w", err)
+	}
+	return strings.TrimSpace(string(b)), nil
+}
+
+// writeAppliedConfigHash records the hash of the configuration applied on the node.
+func writeAppliedConfigHash(cacheDir string, hash string) error {
+	if err := os.MkdirAll(cacheDir, 0o755); err != nil {
+		return fmt.Errorf("error creating cache directory: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(cacheDir, appliedConfigHashFile), []byte(hash+"\n"), 0o644); err != nil {
+		return fmt.Errorf("error writing applied config hash: %w", err)
+	}
+	return nil
+}
@@ -39,6 +39,8 @@ type Archive struct {
 
 	// Source is the location for the archive
 	Source string `json:"source,omitempty"`
+	// Contents is the archive, when it has already been downloaded (e.g. as an asset), in which case it is used instead of Source
+	Contents fi.Resource `json:"-"`
 	// Hash is the source tar
 	Hash string `json:"hash,omitempty"`
 
@@ -142,16 +144,22 @@ func (_ *Archive) RenderLocal(t *local.LocalTarget, a, e, changes *Archive) erro
 			return fmt.Errorf("error creating directories %q: %v", localArchiveDir, err)
 		}
 
-		var hash *hashing.Hash
-		if e.Hash != "" {
-			parsed, err := hashing.FromString(e.Hash)
-			if err != nil {
-				return fmt.Errorf("error parsing hash: %v", err)
+		if e.Contents != nil {
+			if err := fi.WriteFile(localFile, e.Contents, 0o644, 0o755, "", ""); err != nil {
+				return fmt.Errorf("error writing archive %q: %v", e.Name, err)
+			}
+		} else {
+			var hash *hashing.Hash
+			if e.Hash != "" {
+				parsed, err := hashing.FromString(e.Hash)
+				if err != nil {
+					return fmt.Errorf("error parsing hash: %v", err)
+				}
+				hash = parsed
+			}
+			if _, err := fi.DownloadURL(e.Source, localFile, hash); err != nil {
+				return err
 			}
-			hash = parsed
-		}
-		if _, err := fi.DownloadURL(e.Source, localFile, hash); err != nil {
-			return err
 		}
 
 		if len(e.MapFiles) == 0 {
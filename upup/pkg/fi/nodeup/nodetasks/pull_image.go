@@ -28,6 +28,10 @@ import (
 // PullImageTask is responsible for pulling a docker image
 type PullImageTask struct {
 	Name string
+
+	// BeforeKubelet is set for images that must be available before the kubelet starts.
+	// Other images, like the ones pulled for the warm pool, don't hold up the kubelet.
+	BeforeKubelet bool
 }
 
 var (
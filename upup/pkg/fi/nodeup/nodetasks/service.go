@@ -40,6 +40,7 @@ const (
 	centosSystemdSystemPath      = "/usr/lib/systemd/system"
 	flatcarSystemdSystemPath     = "/etc/systemd/system"
 	containerosSystemdSystemPath = "/etc/systemd/system"
+	fcosSystemdSystemPath        = "/etc/systemd/system"
 
 	containerdService = "containerd.service"
 	dockerService     = "docker.service"
@@ -84,18 +85,24 @@ func (s *Service) GetDependencies(tasks map[string]fi.NodeupTask) []fi.NodeupTas
 	var deps []fi.NodeupTask
 	for _, v := range tasks {
 		// We assume that services depend on everything except for
-		// LoadImageTask or IssueCert. If there are any LoadImageTasks (e.g. we're
-		// launching a custom Kubernetes build), they all depend on
-		// the "docker.service" Service task.
+		// LoadImageTask, PullImageTask or IssueCert. If there are any LoadImageTasks
+		// or PullImageTasks (e.g. we're launching a custom Kubernetes build, or
+		// pre-pulling images), they all depend on the container runtime Service task.
+		// The kubelet only starts once the loaded images, and the pulled images that
+		// are marked BeforeKubelet, are available.
 		switch v := v.(type) {
 		case *Package, *UpdatePackages, *UserTask, *GroupTask, *Chattr, *BindMount, *Archive, *Prefix, *UpdateEtcHostsTask:
 			deps = append(deps, v)
-		case *Service, *PullImageTask, *IssueCert, *BootstrapClientTask, *KubeConfig:
+		case *Service, *IssueCert, *BootstrapClientTask, *KubeConfig:
 			// ignore
 		case *LoadImageTask:
 			if s.Name == kubeletService {
 				deps = append(deps, v)
 			}
+		case *PullImageTask:
+			if s.Name == kubeletService && v.BeforeKubelet {
+				deps = append(deps, v)
+			}
 		case *File:
 			if len(v.BeforeServices) > 0 {
 				for _, b := range v.BeforeServices {
@@ -178,6 +185,8 @@ func (_ *Service) systemdSystemPath() (string, error) {
 		return flatcarSystemdSystemPath, nil
 	} else if d == distributions.DistributionContainerOS {
 		return containerosSystemdSystemPath, nil
+	} else if d == distributions.DistributionFedoraCoreOS {
+		return fcosSystemdSystemPath, nil
 	} else {
 		return "", fmt.Errorf("unsupported systemd system")
 	}
@@ -37,6 +37,25 @@ func TestServiceTask_Deps(t *testing.T) {
 	}
 }
 
+func TestServiceTask_KubeletImageDeps(t *testing.T) {
+	s := &Service{Name: kubeletService}
+
+	tasks := make(map[string]fi.NodeupTask)
+	tasks["LoadImageTask1"] = &LoadImageTask{}
+	tasks["PullImageTask1"] = &PullImageTask{Name: "prepulled", BeforeKubelet: true}
+	tasks["PullImageTask2"] = &PullImageTask{Name: "warmpool"}
+
+	deps := s.GetDependencies(tasks)
+	if len(deps) != 2 {
+		t.Fatalf("unexpected deps.  expected the loaded and prepulled images, actual=%v", deps)
+	}
+	for _, dep := range deps {
+		if dep == tasks["PullImageTask2"] {
+			t.Fatalf("unexpected dependency of the kubelet on warm pool image: %v", dep)
+		}
+	}
+}
+
 type FakeTask struct{}
 
 func (t *FakeTask) Run(*fi.NodeupContext) error {
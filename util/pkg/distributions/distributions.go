@@ -49,8 +49,12 @@ var (
 	DistributionRhel8           = Distribution{packageFormat: "rpm", project: "rhel", id: "rhel8", version: 8}
 	DistributionRhel9           = Distribution{packageFormat: "rpm", project: "rhel", id: "rhel9", version: 9}
 	DistributionRocky8          = Distribution{packageFormat: "rpm", project: "rocky", id: "rocky8", version: 8}
+	DistributionRocky9          = Distribution{packageFormat: "rpm", project: "rocky", id: "rocky9", version: 9}
+	DistributionAlmaLinux8      = Distribution{packageFormat: "rpm", project: "almalinux", id: "almalinux8", version: 8}
+	DistributionAlmaLinux9      = Distribution{packageFormat: "rpm", project: "almalinux", id: "almalinux9", version: 9}
 	DistributionFlatcar         = Distribution{packageFormat: "", project: "flatcar", id: "flatcar", version: 0}
 	DistributionContainerOS     = Distribution{packageFormat: "", project: "containeros", id: "containeros", version: 0}
+	DistributionFedoraCoreOS    = Distribution{packageFormat: "", project: "fcos", id: "fcos", version: 0}
 )
 
 // IsDebianFamily returns true if this distribution uses deb packages and generally follows debian package names
@@ -68,6 +72,16 @@ func (d *Distribution) IsRHELFamily() bool {
 	return d.packageFormat == "rpm"
 }
 
+// IsEnterpriseLinux returns true if this distribution is RHEL or one of its rebuilds, which use dnf, SELinux and firewalld
+func (d *Distribution) IsEnterpriseLinux() bool {
+	switch d.project {
+	case "rhel", "rocky", "almalinux":
+		return true
+	default:
+		return false
+	}
+}
+
 // IsSystemd returns true if this distribution uses systemd
 func (d *Distribution) IsSystemd() bool {
 	return true
@@ -86,7 +100,9 @@ func (d *Distribution) DefaultUsers() ([]string, error) {
 		return []string{"ec2-user"}, nil
 	case "rocky":
 		return []string{"rocky"}, nil
-	case "flatcar":
+	case "almalinux":
+		return []string{"ec2-user", "almalinux"}, nil
+	case "flatcar", "fcos":
 		return []string{"core"}, nil
 	default:
 		return nil, fmt.Errorf("unknown distro %v", d)
@@ -39,6 +39,9 @@ func FindDistribution(rootfs string) (Distribution, error) {
 			if strings.HasPrefix(line, "VERSION_ID=") {
 				osRelease["VERSION_ID"] = strings.Trim(line[11:], "\"")
 			}
+			if strings.HasPrefix(line, "VARIANT_ID=") {
+				osRelease["VARIANT_ID"] = strings.Trim(line[11:], "\"")
+			}
 		}
 	} else {
 		return Distribution{}, fmt.Errorf("reading /etc/os-release: %v", err)
@@ -73,6 +76,10 @@ func FindDistribution(rootfs string) (Distribution, error) {
 	if strings.HasPrefix(distro, "flatcar-") {
 		return DistributionFlatcar, nil
 	}
+	// Fedora CoreOS shares the ID of Fedora, and sets VARIANT_ID
+	if osRelease["ID"] == "fedora" && osRelease["VARIANT_ID"] == "coreos" {
+		return DistributionFedoraCoreOS, nil
+	}
 	if strings.HasPrefix(distro, "rhel-8.") {
 		return DistributionRhel8, nil
 	}
@@ -82,6 +89,15 @@ func FindDistribution(rootfs string) (Distribution, error) {
 	if strings.HasPrefix(distro, "rocky-8.") {
 		return DistributionRocky8, nil
 	}
+	if strings.HasPrefix(distro, "rocky-9.") {
+		return DistributionRocky9, nil
+	}
+	if strings.HasPrefix(distro, "almalinux-8.") {
+		return DistributionAlmaLinux8, nil
+	}
+	if strings.HasPrefix(distro, "almalinux-9.") {
+		return DistributionAlmaLinux9, nil
+	}
 
 	// Some distros are not supported
 	klog.V(2).Infof("Contents of /etc/os-release:\n%s", osReleaseBytes)
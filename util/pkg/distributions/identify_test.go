@@ -29,6 +29,16 @@ func TestFindDistribution(t *testing.T) {
 		err      error
 		expected Distribution
 	}{
+		{
+			rootfs:   "almalinux8",
+			err:      nil,
+			expected: DistributionAlmaLinux8,
+		},
+		{
+			rootfs:   "almalinux9",
+			err:      nil,
+			expected: DistributionAlmaLinux9,
+		},
 		{
 			rootfs:   "amazonlinux2",
 			err:      nil,
@@ -84,6 +94,11 @@ func TestFindDistribution(t *testing.T) {
 			err:      nil,
 			expected: DistributionDebian12,
 		},
+		{
+			rootfs:   "fedoracoreos",
+			err:      nil,
+			expected: DistributionFedoraCoreOS,
+		},
 		{
 			rootfs:   "flatcar",
 			err:      nil,
@@ -109,6 +124,11 @@ func TestFindDistribution(t *testing.T) {
 			err:      nil,
 			expected: DistributionRocky8,
 		},
+		{
+			rootfs:   "rocky9",
+			err:      nil,
+			expected: DistributionRocky9,
+		},
 		{
 			rootfs:   "ubuntu1604",
 			err:      fmt.Errorf("unsupported distro: ubuntu-16.04"),